@@ -1,16 +1,96 @@
 from __future__ import annotations
 
-from fastapi import APIRouter, Depends, Request
+import asyncio
+from typing import TypeVar
 
+from fastapi import APIRouter, Body, Depends, HTTPException, Request
+from pydantic import BaseModel
+
+from app.api.auth import require_admin
 from app.core.concurrency import run_in_thread_limited
-from app.core.dependencies import get_analysis_service
+from app.core.correlation import use_correlation_id
+from app.core.dependencies import (
+    get_alert_group_service,
+    get_alert_storm_guard,
+    get_analysis_archiver,
+    get_analysis_service,
+    get_record_signer,
+    get_result_router,
+    get_suppression_service,
+)
+from app.core.k8s_clusters import UnknownCluster, resolve_cluster
+from app.core.k8s_credentials import ClusterCredentialError, validate_cluster_credentials
+from app.core.pipeline import STAGE_DELIVER
+from app.core.signing import RecordSigner
 from app.schemas.analysis import (
     AlertAnalysisRequest,
     AlertAnalysisResponse,
+    AlertGroupAnalysisRequest,
+    AlertGroupAnalysisResponse,
+    AlertmanagerValidationResponse,
+    AlertStorm,
+    AlertSuppression,
+    AnalysisFollowupRequest,
+    AnalysisFollowupResponse,
+    CrashLoopAnalysis,
+    DnsAnalysis,
+    EndpointReadiness,
+    EvictionAnalysis,
+    HpaAnalysis,
+    ImagePullAnalysis,
+    IncidentClosure,
     IncidentSummaryRequest,
     IncidentSummaryResponse,
+    JobFailureAnalysis,
+    NamespaceSnapshot,
+    NetworkPolicyAnalysis,
+    NodeHealth,
+    OomAnalysis,
+    PodDiagnostics,
+    ProbeAnalysis,
+    QuotaAnalysis,
+    RankedHypothesis,
+    RecordSignature,
+    RecordVerificationRequest,
+    RecordVerificationResponse,
+    ResourcePressure,
+    SchedulingAnalysis,
+    StorageAnalysis,
+    WorkloadOwner,
 )
-from app.services.analysis import AnalysisService
+from app.services.alert_validation import validate_alertmanager_payload
+from app.services.analysis import AnalysisNotFoundError, AnalysisService
+from app.services.archive import AnalysisArchiver
+from app.services.group_analysis import AlertGroupService
+from app.services.result_routing import ResultRouter
+from app.services.storm import AlertStormGuard
+from app.services.suppression import SuppressionService
+
+ResponseT = TypeVar("ResponseT", bound=BaseModel)
+ModelT = TypeVar("ModelT", bound=BaseModel)
+
+# Structured analyses the service puts into the context, by response field: the closure
+# verdict and one model per entry of the service's _CONTEXT_ANALYSES.
+_CONTEXT_MODELS: dict[str, type[BaseModel]] = {
+    "closure": IncidentClosure,
+    "pod_diagnostics": PodDiagnostics,
+    "node_health": NodeHealth,
+    "oom_analysis": OomAnalysis,
+    "crash_loop": CrashLoopAnalysis,
+    "image_pull": ImagePullAnalysis,
+    "scheduling": SchedulingAnalysis,
+    "probe_analysis": ProbeAnalysis,
+    "eviction_analysis": EvictionAnalysis,
+    "quota_analysis": QuotaAnalysis,
+    "namespace_snapshot": NamespaceSnapshot,
+    "storage_analysis": StorageAnalysis,
+    "hpa_analysis": HpaAnalysis,
+    "resource_pressure": ResourcePressure,
+    "network_policy_analysis": NetworkPolicyAnalysis,
+    "endpoint_readiness": EndpointReadiness,
+    "job_failure_analysis": JobFailureAnalysis,
+    "dns_analysis": DnsAnalysis,
+}
 
 router = APIRouter()
 
@@ -20,16 +100,39 @@ async def analyze_alert(
     http_request: Request,
     request: AlertAnalysisRequest,
     service: AnalysisService = Depends(get_analysis_service),  # noqa: B008
+    signer: RecordSigner | None = Depends(get_record_signer),  # noqa: B008
+    result_router: ResultRouter | None = Depends(get_result_router),  # noqa: B008
+    archiver: AnalysisArchiver | None = Depends(get_analysis_archiver),  # noqa: B008
+    storm_guard: AlertStormGuard | None = Depends(get_alert_storm_guard),  # noqa: B008
+    suppressions: SuppressionService | None = Depends(get_suppression_service),  # noqa: B008
 ) -> AlertAnalysisResponse:
-    analysis, summary, detail, context, artifacts = await run_in_thread_limited(
-        service.analyze, request, request=http_request
+    try:
+        resolve_cluster(request.cluster)
+        validate_cluster_credentials(request.cluster_credentials)
+    except (ClusterCredentialError, UnknownCluster) as exc:
+        raise HTTPException(status_code=400, detail=str(exc)) from exc
+    suppression = (
+        await asyncio.to_thread(suppressions.match, request) if suppressions is not None else None
     )
+    if suppression is not None:
+        return _sign_response(_suppressed_response(request, suppression), signer)
+    storm = storm_guard.admit(request) if storm_guard is not None else None
+    if storm is not None:
+        return _sign_response(_deferred_response(request, storm), signer)
+    try:
+        analysis, summary, detail, context, artifacts = await run_in_thread_limited(
+            service.analyze, request, request=http_request
+        )
+    except ClusterCredentialError as exc:
+        raise HTTPException(status_code=400, detail=str(exc)) from exc
     analysis_quality = _extract_optional_str(context, "analysis_quality")
     missing_data = _extract_optional_str_list(context, "missing_data")
     warnings = _extract_optional_str_list(context, "warnings")
     capabilities = _extract_optional_str_dict(context, "capabilities")
+    degraded = isinstance(context, dict) and context.get("degraded") is True
+    degraded_reason = _extract_optional_str(context, "degraded_reason")
     analysis_type = request.analysis_type or request.alert.status
-    return AlertAnalysisResponse(
+    response = AlertAnalysisResponse(
         status="ok",
         thread_ts=request.thread_ts,
         analysis=analysis,
@@ -40,9 +143,80 @@ async def analyze_alert(
         missing_data=missing_data,
         warnings=warnings,
         capabilities=capabilities,
+        degraded=degraded,
+        degraded_reason=degraded_reason,
+        time_boxed=isinstance(context, dict) and context.get("time_boxed") is True,
+        expected_disruption=(
+            isinstance(context, dict) and context.get("expected_disruption") is True
+        ),
+        analysis_id=_extract_optional_str(context, "analysis_id"),
+        correlation_id=_extract_optional_str(context, "correlation_id"),
+        owner_chain=_extract_model_list(context, "owner_chain", WorkloadOwner),
+        hypotheses=_extract_hypotheses(context),
+        **{key: _extract_model(context, key, model) for key, model in _CONTEXT_MODELS.items()},
         context=context,
         artifacts=artifacts,
     )
+    delivers = _pipeline_delivers(context)
+    if result_router is not None and delivers:
+        # The review sink's webhook names the analysis like the callbacks sent during it.
+        with use_correlation_id(response.correlation_id or ""):
+            routing = await asyncio.to_thread(
+                result_router.route, response.model_dump(mode="json")
+            )
+        response = response.model_copy(update={"routing": routing})
+    response = _sign_response(response, signer)
+    if archiver is not None and delivers:
+        archiver.submit(request, response.model_dump(mode="json"))
+    return response
+
+
+@router.post("/analyze/group", response_model=AlertGroupAnalysisResponse)
+async def analyze_alert_group(
+    http_request: Request,
+    request: AlertGroupAnalysisRequest,
+    service: AlertGroupService = Depends(get_alert_group_service),  # noqa: B008
+    signer: RecordSigner | None = Depends(get_record_signer),  # noqa: B008
+) -> AlertGroupAnalysisResponse:
+    """Analyze the alerts of one webhook group and answer with a single group summary."""
+    result = await run_in_thread_limited(service.analyze, request, request=http_request)
+    response = AlertGroupAnalysisResponse.model_validate(
+        {"status": "ok", "thread_ts": request.thread_ts, **result}
+    )
+    return _sign_response(response, signer)
+
+
+@router.post(
+    "/analyses/{analysis_id:path}/followup",
+    response_model=AnalysisFollowupResponse,
+    dependencies=[Depends(require_admin)],
+)
+async def follow_up_analysis(
+    http_request: Request,
+    analysis_id: str,
+    request: AnalysisFollowupRequest,
+    service: AnalysisService = Depends(get_analysis_service),  # noqa: B008
+) -> AnalysisFollowupResponse:
+    """Continue a previous analysis with a question asked in its Slack thread."""
+    try:
+        answer = await run_in_thread_limited(
+            service.follow_up, analysis_id, request, request=http_request
+        )
+    except ValueError as exc:
+        raise HTTPException(status_code=400, detail=str(exc)) from exc
+    except AnalysisNotFoundError as exc:
+        raise HTTPException(status_code=404, detail="analysis session not found") from exc
+    return AnalysisFollowupResponse(
+        status="ok", thread_ts=request.thread_ts, analysis_id=analysis_id.strip(), answer=answer
+    )
+
+
+@router.post("/analyze/alertmanager/validate", response_model=AlertmanagerValidationResponse)
+async def validate_alertmanager_webhook(
+    payload: object = Body(...),  # noqa: B008
+) -> AlertmanagerValidationResponse:
+    """Show how a webhook payload maps onto the analysis model without analyzing it."""
+    return AlertmanagerValidationResponse.model_validate(validate_alertmanager_payload(payload))
 
 
 @router.post("/summarize-incident", response_model=IncidentSummaryResponse)
@@ -50,12 +224,106 @@ async def summarize_incident(
     http_request: Request,
     request: IncidentSummaryRequest,
     service: AnalysisService = Depends(get_analysis_service),  # noqa: B008
+    signer: RecordSigner | None = Depends(get_record_signer),  # noqa: B008
 ) -> IncidentSummaryResponse:
     """Generate final RCA summary for a resolved incident."""
     title, summary, detail = await run_in_thread_limited(
         service.summarize_incident, request, request=http_request
     )
-    return IncidentSummaryResponse(status="ok", title=title, summary=summary, detail=detail)
+    response = IncidentSummaryResponse(status="ok", title=title, summary=summary, detail=detail)
+    return _sign_response(response, signer)
+
+
+@router.post(
+    "/analyses/verify",
+    response_model=RecordVerificationResponse,
+    dependencies=[Depends(require_admin)],
+)
+async def verify_analysis_record(
+    request: RecordVerificationRequest,
+    signer: RecordSigner | None = Depends(get_record_signer),  # noqa: B008
+) -> RecordVerificationResponse:
+    """Check that a stored analysis response is unmodified since it was generated."""
+    if signer is None:
+        raise HTTPException(status_code=400, detail="analysis signing is not configured")
+    record = {key: value for key, value in request.record.items() if key != "signature"}
+    valid = signer.verify(record, request.signature.model_dump())
+    return RecordVerificationResponse(valid=valid)
+
+
+def _sign_response(response: ResponseT, signer: RecordSigner | None) -> ResponseT:
+    if signer is None:
+        return response
+    record = response.model_dump(mode="json", exclude={"signature"})
+    return response.model_copy(update={"signature": RecordSignature(**signer.sign(record))})
+
+
+def _deferred_response(
+    request: AlertAnalysisRequest, storm: dict[str, object]
+) -> AlertAnalysisResponse:
+    where = "/".join(str(storm[key]) for key in ("cluster", "namespace") if storm.get(key))
+    summary = (
+        f"Alert storm ({storm['alerts_per_minute']} alerts/minute): analysis deferred; "
+        f"a summary of {where or 'the cluster'} follows"
+    )
+    return AlertAnalysisResponse(
+        status="deferred",
+        thread_ts=request.thread_ts,
+        analysis=summary,
+        analysis_summary=summary,
+        analysis_type=request.analysis_type or request.alert.status,
+        # The deferred_analysis report of this alert carries the same ID.
+        correlation_id=_extract_optional_str(storm, "correlation_id"),
+        storm=AlertStorm.model_validate(storm),
+    )
+
+
+def _suppressed_response(
+    request: AlertAnalysisRequest, suppression: dict[str, object]
+) -> AlertAnalysisResponse:
+    summary = (
+        f"Alert suppressed until {suppression['ends_at']} by suppression "
+        f"{suppression['suppression_id']}: {suppression['reason']}"
+    )
+    return AlertAnalysisResponse(
+        status="suppressed",
+        thread_ts=request.thread_ts,
+        analysis=summary,
+        analysis_summary=summary,
+        analysis_type=request.analysis_type or request.alert.status,
+        suppression=AlertSuppression.model_validate(suppression),
+    )
+
+
+def _extract_model(
+    context: dict[str, object] | None, key: str, model: type[ModelT]
+) -> ModelT | None:
+    if not isinstance(context, dict) or not isinstance(context.get(key), dict):
+        return None
+    return model.model_validate(context[key])
+
+
+def _extract_model_list(
+    context: dict[str, object] | None, key: str, model: type[ModelT]
+) -> list[ModelT] | None:
+    if not isinstance(context, dict) or not isinstance(context.get(key), list):
+        return None
+    items = [item for item in context[key] if isinstance(item, dict)]
+    return [model.model_validate(item) for item in items] or None
+
+
+def _extract_hypotheses(context: dict[str, object] | None) -> list[RankedHypothesis] | None:
+    if not isinstance(context, dict) or not isinstance(context.get("hypotheses"), list):
+        return None
+    return [RankedHypothesis.model_validate(item) for item in context["hypotheses"]]
+
+
+def _pipeline_delivers(context: dict[str, object] | None) -> bool:
+    """False when the alert's pipeline profile leaves out the ``deliver`` stage."""
+    pipeline = context.get("pipeline") if isinstance(context, dict) else None
+    if not isinstance(pipeline, dict) or not isinstance(pipeline.get("stages"), list):
+        return True
+    return STAGE_DELIVER in pipeline["stages"]
 
 
 def _extract_optional_str(context: dict[str, object] | None, key: str) -> str | None:
@@ -0,0 +1,61 @@
+from __future__ import annotations
+
+import asyncio
+import logging
+
+from fastapi import Depends, HTTPException, Request, WebSocket
+
+from app.core.auth import AuthenticationError, AuthorizationError, OIDCVerifier, Principal
+from app.core.dependencies import get_oidc_verifier
+
+logger = logging.getLogger(__name__)
+
+
+async def require_admin(
+    request: Request,
+    verifier: OIDCVerifier | None = Depends(get_oidc_verifier),  # noqa: B008
+) -> Principal | None:
+    """Guard admin endpoints with an OIDC bearer token; a no-op when OIDC is not configured."""
+    if verifier is None:
+        return None
+    scheme, _, token = request.headers.get("authorization", "").partition(" ")
+    if scheme.lower() != "bearer" or not token.strip():
+        raise HTTPException(
+            status_code=401,
+            detail="bearer token required",
+            headers={"WWW-Authenticate": "Bearer"},
+        )
+    try:
+        principal = await asyncio.to_thread(verifier.authenticate, token.strip())
+    except AuthenticationError as exc:
+        raise HTTPException(
+            status_code=401, detail=str(exc), headers={"WWW-Authenticate": "Bearer"}
+        ) from exc
+    except AuthorizationError as exc:
+        raise HTTPException(status_code=403, detail=str(exc)) from exc
+    logger.info("admin_request subject=%s path=%s", principal.subject, request.url.path)
+    return principal
+
+
+async def authenticate_websocket(
+    websocket: WebSocket, verifier: OIDCVerifier | None
+) -> Principal | None:
+    """``require_admin`` for a WebSocket handshake; a no-op when OIDC is not configured.
+
+    Browsers cannot set headers on a WebSocket, so the token may also come as the
+    ``access_token`` query parameter.
+
+    Raises:
+        AuthenticationError: without a valid bearer token.
+        AuthorizationError: when the token's groups are not allowed.
+    """
+    if verifier is None:
+        return None
+    scheme, _, token = websocket.headers.get("authorization", "").partition(" ")
+    if scheme.lower() != "bearer" or not token.strip():
+        token = websocket.query_params.get("access_token", "")
+    if not token.strip():
+        raise AuthenticationError("bearer token required")
+    principal = await asyncio.to_thread(verifier.authenticate, token.strip())
+    logger.info("admin_websocket subject=%s path=%s", principal.subject, websocket.url.path)
+    return principal
@@ -0,0 +1,135 @@
+from __future__ import annotations
+
+import asyncio
+from datetime import datetime
+
+from fastapi import APIRouter, Depends, HTTPException, Query
+from pydantic import BaseModel, Field
+
+from app.api.auth import require_admin
+from app.clients.analysis_store import AnalysisFilter, PostgresAnalysisStore
+from app.core.dependencies import get_analysis_store, get_backfill_service
+from app.services.analysis_comparison import compare_stored_analyses, describe_version
+from app.services.backfill import BackfillService
+
+router = APIRouter(tags=["backfill"], dependencies=[Depends(require_admin)])
+
+
+class BackfillRequest(BaseModel):
+    alertname: str | None = None
+    namespace: str | None = None
+    since: datetime | None = None
+    until: datetime | None = None
+    limit: int = Field(default=100, ge=1, le=1000)
+
+
+def _require_store(store: PostgresAnalysisStore | None) -> PostgresAnalysisStore:
+    if store is None:
+        raise HTTPException(status_code=400, detail="analysis history is not configured")
+    return store
+
+
+def _require_backfill(service: BackfillService | None) -> BackfillService:
+    if service is None:
+        raise HTTPException(status_code=400, detail="analysis history is not configured")
+    return service
+
+
+@router.post("/backfill", status_code=202)
+async def start_backfill(
+    payload: BackfillRequest,
+    service: BackfillService | None = Depends(get_backfill_service),  # noqa: B008
+) -> dict[str, object]:
+    """Re-analyze stored alerts matching the filter with the current pipeline version."""
+    backfill = _require_backfill(service)
+    analysis_filter = AnalysisFilter(
+        alertname=payload.alertname,
+        namespace=payload.namespace,
+        since=payload.since,
+        until=payload.until,
+    )
+    try:
+        return backfill.start(analysis_filter, limit=payload.limit)
+    except RuntimeError as exc:
+        raise HTTPException(status_code=409, detail=str(exc)) from exc
+
+
+@router.get("/backfill")
+async def list_backfill_jobs(
+    service: BackfillService | None = Depends(get_backfill_service),  # noqa: B008
+) -> dict[str, object]:
+    jobs = _require_backfill(service).jobs()
+    return {"count": len(jobs), "jobs": jobs}
+
+
+@router.get("/backfill/{job_id}")
+async def get_backfill_job(
+    job_id: str,
+    service: BackfillService | None = Depends(get_backfill_service),  # noqa: B008
+) -> dict[str, object]:
+    job = _require_backfill(service).job(job_id)
+    if job is None:
+        raise HTTPException(status_code=404, detail="backfill job not found")
+    return job
+
+
+@router.get("/analyses/history")
+async def list_analysis_versions(
+    session_key: str = Query(min_length=1),  # noqa: B008
+    limit: int = Query(default=20, ge=1, le=200),  # noqa: B008
+    store: PostgresAnalysisStore | None = Depends(get_analysis_store),  # noqa: B008
+) -> dict[str, object]:
+    """Stored results of one alert across pipeline versions, newest first."""
+    versions = await asyncio.to_thread(
+        _require_store(store).list_versions, session_key, limit=limit
+    )
+    return {"session_key": session_key, "count": len(versions), "versions": versions}
+
+
+@router.get("/analyses/{result_id}/versions")
+async def list_result_versions(
+    result_id: int,
+    limit: int = Query(default=20, ge=1, le=200),  # noqa: B008
+    store: PostgresAnalysisStore | None = Depends(get_analysis_store),  # noqa: B008
+) -> dict[str, object]:
+    """Every stored version of the alert behind *result_id*, newest first, with its conclusions."""
+    history = _require_store(store)
+    row = await _get_result(history, result_id)
+    session_key = str(row["session_key"])
+    versions = await asyncio.to_thread(history.list_versions, session_key, limit=limit)
+    return {
+        "result_id": result_id,
+        "session_key": session_key,
+        "count": len(versions),
+        "versions": [describe_version(version) for version in versions],
+    }
+
+
+@router.get("/analyses/{result_id}/diff")
+async def diff_result_versions(
+    result_id: int,
+    against: int | None = Query(default=None, ge=1),  # noqa: B008
+    store: PostgresAnalysisStore | None = Depends(get_analysis_store),  # noqa: B008
+) -> dict[str, object]:
+    """Structured conclusions of *result_id* compared with *against* or the previous version."""
+    history = _require_store(store)
+    target = await _get_result(history, result_id)
+    if against is not None:
+        base = await _get_result(history, against)
+        if base.get("session_key") != target.get("session_key"):
+            raise HTTPException(status_code=400, detail="results belong to different alerts")
+    else:
+        previous = await asyncio.to_thread(
+            history.list_versions, str(target["session_key"]), limit=1, before=result_id
+        )
+        if not previous:
+            raise HTTPException(status_code=404, detail="no previous version of this analysis")
+        base = previous[0]
+    return compare_stored_analyses(base, target)
+
+
+async def _get_result(store: PostgresAnalysisStore, result_id: int) -> dict[str, object]:
+    row = await asyncio.to_thread(store.get_result, result_id)
+    if row is None:
+        raise HTTPException(status_code=404, detail="analysis not found")
+    return row
@@ -0,0 +1,56 @@
+from __future__ import annotations
+
+from fastapi import APIRouter, Depends, HTTPException
+from pydantic import BaseModel, Field
+
+from app.api.auth import require_admin
+from app.core.dependencies import get_canary_rollout
+from app.services.canary import CanaryRollout
+
+router = APIRouter(tags=["canary"], dependencies=[Depends(require_admin)])
+
+
+class CanaryFeedbackRequest(BaseModel):
+    analysis_id: str = Field(min_length=1)
+    # 0 = wrong or useless, 1 = correct root cause
+    score: float = Field(ge=0.0, le=1.0)
+
+
+def _require_canary(canary: CanaryRollout | None) -> CanaryRollout:
+    if canary is None:
+        raise HTTPException(status_code=400, detail="canary rollout is not configured")
+    return canary
+
+
+@router.get("/canary")
+async def canary_status(
+    canary: CanaryRollout | None = Depends(get_canary_rollout),  # noqa: B008
+) -> dict[str, object]:
+    """Canary share, per-arm error rates and rollback state."""
+    return _require_canary(canary).status()
+
+
+@router.post("/canary/reset")
+async def reset_canary(
+    canary: CanaryRollout | None = Depends(get_canary_rollout),  # noqa: B008
+) -> dict[str, object]:
+    """Resume a rolled-back canary with fresh outcome windows."""
+    rollout = _require_canary(canary)
+    rollout.reset()
+    return rollout.status()
+
+
+@router.post("/canary/feedback")
+async def canary_feedback(
+    request: CanaryFeedbackRequest,
+    canary: CanaryRollout | None = Depends(get_canary_rollout),  # noqa: B008
+) -> dict[str, object]:
+    """Score an analysis of the rollout; a lower canary score can roll the canary back."""
+    rollout = _require_canary(canary)
+    arm = rollout.record_feedback(request.analysis_id, request.score)
+    if arm is None:
+        raise HTTPException(
+            status_code=404,
+            detail="analysis is unknown to the canary rollout of this replica or already scored",
+        )
+    return {"analysis_id": request.analysis_id, "arm": arm, **rollout.status()}
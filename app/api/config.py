@@ -3,9 +3,10 @@ from __future__ import annotations
 import logging
 import os
 
-from fastapi import APIRouter
+from fastapi import APIRouter, Depends
 from pydantic import BaseModel
 
+from app.api.auth import require_admin
 from app.core.dependencies import (
     get_analysis_engine,
     get_analysis_service,
@@ -15,7 +16,7 @@ from app.core.dependencies import (
 
 logger = logging.getLogger(__name__)
 
-router = APIRouter(tags=["config"])
+router = APIRouter(tags=["config"], dependencies=[Depends(require_admin)])
 
 
 class AIConfigUpdateRequest(BaseModel):
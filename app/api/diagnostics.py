@@ -0,0 +1,29 @@
+from __future__ import annotations
+
+import asyncio
+
+from fastapi import APIRouter, Depends, Query
+from fastapi.responses import PlainTextResponse
+
+from app.api.auth import require_admin
+from app.core.dependencies import get_diagnostics_service
+from app.services.diagnostics import DiagnosticsService
+
+router = APIRouter(tags=["diagnostics"], dependencies=[Depends(require_admin)])
+
+
+@router.get("/diagnostics")
+async def get_diagnostics(
+    namespace: str | None = Query(default=None),  # noqa: B008
+    service: DiagnosticsService = Depends(get_diagnostics_service),  # noqa: B008
+) -> dict[str, object]:
+    """Sanitized config, data-source probes, RBAC self-checks and LLM reachability."""
+    return await asyncio.to_thread(service.collect, namespace)
+
+
+@router.get("/diagnostics/rbac", response_class=PlainTextResponse)
+async def get_rbac_manifest(
+    service: DiagnosticsService = Depends(get_diagnostics_service),  # noqa: B008
+) -> PlainTextResponse:
+    """Least-privilege ClusterRole/Role YAML for the collectors the current config enables."""
+    return PlainTextResponse(service.rbac_manifest(), media_type="application/yaml")
@@ -0,0 +1,41 @@
+from __future__ import annotations
+
+import asyncio
+
+from fastapi import APIRouter, Depends, Query
+
+from app.api.auth import require_admin
+from app.core.dependencies import get_digest_service
+from app.services.digest import DigestService
+
+router = APIRouter(tags=["digest"], dependencies=[Depends(require_admin)])
+
+
+@router.get("/digest")
+async def preview_digest(
+    period_hours: int | None = Query(default=None, ge=1, le=24 * 31),  # noqa: B008
+    service: DigestService = Depends(get_digest_service),  # noqa: B008
+) -> dict[str, object]:
+    """Build the analysis digest for the period without delivering it."""
+    return await asyncio.to_thread(service.build, period_hours)
+
+
+@router.post("/digest/send")
+async def send_digest(
+    period_hours: int | None = Query(default=None, ge=1, le=24 * 31),  # noqa: B008
+    service: DigestService = Depends(get_digest_service),  # noqa: B008
+) -> dict[str, object]:
+    """Build the analysis digest and deliver it to the report webhook now."""
+    return await asyncio.to_thread(service.send, period_hours)
+
+
+@router.get("/alerts/noise")
+async def get_alert_noise(
+    period_hours: int | None = Query(default=None, ge=1, le=24 * 31),  # noqa: B008
+    min_occurrences: int = Query(default=3, ge=1),  # noqa: B008
+    service: DigestService = Depends(get_digest_service),  # noqa: B008
+) -> dict[str, object]:
+    """Score alert rules by actionability with threshold and `for:` tuning suggestions."""
+    return await asyncio.to_thread(
+        service.noise_report, period_hours, min_occurrences=min_occurrences
+    )
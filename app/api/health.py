@@ -1,6 +1,9 @@
 from __future__ import annotations
 
 from fastapi import APIRouter
+from fastapi.responses import JSONResponse
+
+from app.core.warmup import readiness
 
 router = APIRouter()
 
@@ -15,6 +18,12 @@ def healthz() -> dict[str, str]:
     return {"status": "ok"}
 
 
+@router.get("/readyz")
+def readyz() -> JSONResponse:
+    ready, state = readiness()
+    return JSONResponse(state, status_code=200 if ready else 503)
+
+
 @router.get("/")
 def root() -> dict[str, str]:
     return {"status": "ok", "message": "kube-rca-agent is running"}
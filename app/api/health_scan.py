@@ -0,0 +1,49 @@
+from __future__ import annotations
+
+import asyncio
+
+from fastapi import APIRouter, Depends, HTTPException
+from pydantic import BaseModel, Field
+
+from app.api.auth import require_admin
+from app.core.dependencies import get_health_scan_service
+from app.services.health_scan import HealthScanService
+
+router = APIRouter(tags=["health-scan"], dependencies=[Depends(require_admin)])
+
+
+class HealthScanFinding(BaseModel):
+    namespace: str
+    check: str
+    summary: str
+    evidence: dict[str, object] = Field(default_factory=dict)
+
+
+class HealthScanReport(BaseModel):
+    scanned_at: str
+    namespaces: list[str] = Field(default_factory=list)
+    finding_count: int = 0
+    findings: list[HealthScanFinding] = Field(default_factory=list)
+    delivery: dict[str, object] | None = None
+
+
+@router.post("/health-scan", response_model=HealthScanReport)
+async def run_health_scan(
+    service: HealthScanService = Depends(get_health_scan_service),  # noqa: B008
+) -> HealthScanReport:
+    """Run a proactive health scan over the configured namespaces now."""
+    if not service.enabled:
+        raise HTTPException(status_code=400, detail="no health scan namespaces configured")
+    report = await asyncio.to_thread(service.scan)
+    return HealthScanReport.model_validate(report)
+
+
+@router.get("/health-scan/latest", response_model=HealthScanReport)
+async def get_latest_health_scan(
+    service: HealthScanService = Depends(get_health_scan_service),  # noqa: B008
+) -> HealthScanReport:
+    """Return the most recent health scan report kept in memory."""
+    report = service.latest_report
+    if report is None:
+        raise HTTPException(status_code=404, detail="no health scan has run yet")
+    return HealthScanReport.model_validate(report)
@@ -0,0 +1,172 @@
+"""Interactive investigation sessions on a stored analysis, over a WebSocket.
+
+``/analyses/{analysis_id}/session`` continues the runtime session of a previous
+``/analyze`` run. Once connected, the server sends
+``{"type": "session", "analysis_id": ..., "available": ..., "tools": [...]}`` and
+then handles the client's JSON messages one at a time:
+
+- ``{"type": "question", "question": "..."}``: the answer streams as ``chunk``
+  events (masked, line by line) and ``tool_call`` events for the tools the agent
+  calls, followed by ``{"type": "answer", "answer": "..."}``.
+- ``{"type": "run_tool", "tool": "...", "arguments": {...}}``: runs one agent tool
+  directly, answered with ``{"type": "tool_result", "tool", "status", "output"}``.
+  The result joins the session history, so later questions can use it.
+- ``{"type": "ping"}``: answered with ``{"type": "pong"}``.
+
+Invalid messages get ``{"type": "error", "detail": ...}`` and the session stays
+open. Opening the session, questions and tool runs share the analysis
+concurrency limit; a message that finds no free slot within
+``_SLOT_WAIT_SECONDS`` is answered with an ``error`` event as well, so the
+client can retry it. If the session itself cannot be opened for that reason, the
+``error`` event is followed by a 1013 close. The socket is closed with 4400 for
+ids that are not an ``analysis_id``, 4404 when the session is no longer stored
+and 4401/4403 when OIDC rejects the token. Analyses that ran with caller
+credentials are closed with 4403: the socket cannot carry the caller's token,
+and the tools must not fall back to the agent's ServiceAccount.
+"""
+
+from __future__ import annotations
+
+import asyncio
+import json
+import logging
+from collections.abc import Callable
+
+from fastapi import APIRouter, Depends, WebSocket, WebSocketDisconnect
+
+from app.api.auth import authenticate_websocket
+from app.core.auth import AuthenticationError, AuthorizationError, OIDCVerifier
+from app.core.concurrency import ConcurrencyLimitExceeded, run_in_thread_limited
+from app.core.dependencies import get_analysis_service, get_oidc_verifier
+from app.core.k8s_credentials import ClusterCredentialError
+from app.services.analysis import AnalysisNotFoundError, AnalysisService
+
+logger = logging.getLogger(__name__)
+
+router = APIRouter()
+
+_CLOSE_INVALID_ID = 4400
+_CLOSE_UNAUTHENTICATED = 4401
+_CLOSE_FORBIDDEN = 4403
+_CLOSE_NOT_FOUND = 4404
+_CLOSE_TRY_AGAIN_LATER = 1013
+
+# How long a session message waits for an analysis slot before it is refused.
+_SLOT_WAIT_SECONDS = 30.0
+
+
+@router.websocket("/analyses/{analysis_id:path}/session")
+async def investigation_session(
+    websocket: WebSocket,
+    analysis_id: str,
+    service: AnalysisService = Depends(get_analysis_service),  # noqa: B008
+    verifier: OIDCVerifier | None = Depends(get_oidc_verifier),  # noqa: B008
+) -> None:
+    """Ask questions about a stored analysis, run tools and receive streamed answers."""
+    await websocket.accept()
+    try:
+        await authenticate_websocket(websocket, verifier)
+    except AuthenticationError as exc:
+        await websocket.close(code=_CLOSE_UNAUTHENTICATED, reason=str(exc))
+        return
+    except AuthorizationError as exc:
+        await websocket.close(code=_CLOSE_FORBIDDEN, reason=str(exc))
+        return
+    try:
+        session = await run_in_thread_limited(
+            service.open_investigation, analysis_id, wait=_SLOT_WAIT_SECONDS
+        )
+    except ConcurrencyLimitExceeded as exc:
+        await websocket.send_json({"type": "error", "detail": _busy_detail(exc)})
+        await websocket.close(code=_CLOSE_TRY_AGAIN_LATER, reason="agent is busy")
+        return
+    except ClusterCredentialError as exc:
+        await websocket.close(code=_CLOSE_FORBIDDEN, reason=str(exc))
+        return
+    except ValueError as exc:
+        await websocket.close(code=_CLOSE_INVALID_ID, reason=str(exc))
+        return
+    except AnalysisNotFoundError:
+        await websocket.close(code=_CLOSE_NOT_FOUND, reason="analysis session not found")
+        return
+    analysis_id = str(session["analysis_id"])
+    await websocket.send_json({"type": "session", **session})
+    logger.info("investigation_session_opened analysis_id=%s", analysis_id)
+
+    loop = asyncio.get_running_loop()
+
+    def send_from_thread(event: dict[str, object]) -> None:
+        asyncio.run_coroutine_threadsafe(websocket.send_json(event), loop).result()
+
+    try:
+        while True:
+            try:
+                message = json.loads(await websocket.receive_text())
+            except ValueError:
+                await websocket.send_json({"type": "error", "detail": "messages must be JSON"})
+                continue
+            await _handle_message(websocket, service, analysis_id, message, send_from_thread)
+    except WebSocketDisconnect:
+        logger.info("investigation_session_closed analysis_id=%s", analysis_id)
+
+
+async def _handle_message(
+    websocket: WebSocket,
+    service: AnalysisService,
+    analysis_id: str,
+    message: object,
+    send_from_thread: Callable[[dict[str, object]], None],
+) -> None:
+    kind = message.get("type") if isinstance(message, dict) else None
+    if kind == "ping":
+        await websocket.send_json({"type": "pong"})
+        return
+    if kind == "question":
+        question = str(message.get("question") or "").strip()  # type: ignore[union-attr]
+        if not question:
+            await websocket.send_json({"type": "error", "detail": "question must not be empty"})
+            return
+        try:
+            answer = await run_in_thread_limited(
+                service.investigate,
+                analysis_id,
+                question,
+                send_from_thread,
+                wait=_SLOT_WAIT_SECONDS,
+            )
+        except ConcurrencyLimitExceeded as exc:
+            await websocket.send_json({"type": "error", "detail": _busy_detail(exc)})
+            return
+        await websocket.send_json({"type": "answer", "answer": answer})
+        return
+    if kind == "run_tool":
+        tool = str(message.get("tool") or "").strip()  # type: ignore[union-attr]
+        arguments = message.get("arguments") or {}  # type: ignore[union-attr]
+        if not tool or not isinstance(arguments, dict):
+            await websocket.send_json(
+                {"type": "error", "detail": "run_tool needs a tool name and an arguments object"}
+            )
+            return
+        try:
+            result = await run_in_thread_limited(
+                service.run_investigation_tool,
+                analysis_id,
+                tool,
+                arguments,
+                wait=_SLOT_WAIT_SECONDS,
+            )
+        except ConcurrencyLimitExceeded as exc:
+            await websocket.send_json({"type": "error", "detail": _busy_detail(exc)})
+            return
+        except ValueError as exc:
+            await websocket.send_json({"type": "error", "detail": str(exc)})
+            return
+        await websocket.send_json({"type": "tool_result", **result})
+        return
+    await websocket.send_json(
+        {"type": "error", "detail": "type must be one of question, run_tool, ping"}
+    )
+
+
+def _busy_detail(exc: ConcurrencyLimitExceeded) -> str:
+    return f"{exc}; try again later"
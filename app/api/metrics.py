@@ -0,0 +1,19 @@
+from __future__ import annotations
+
+from fastapi import APIRouter, Depends
+from fastapi.responses import PlainTextResponse
+
+from app.core.dependencies import get_slo_tracker
+from app.core.slo import LatencySloTracker
+
+router = APIRouter(tags=["metrics"])
+
+
+@router.get("/metrics", response_class=PlainTextResponse)
+async def metrics(
+    tracker: LatencySloTracker = Depends(get_slo_tracker),  # noqa: B008
+) -> PlainTextResponse:
+    """Analysis latency SLO compliance in the Prometheus text exposition format."""
+    return PlainTextResponse(
+        tracker.render_prometheus(), media_type="text/plain; version=0.0.4; charset=utf-8"
+    )
@@ -0,0 +1,60 @@
+from __future__ import annotations
+
+import asyncio
+
+from fastapi import APIRouter, Depends, HTTPException
+from pydantic import BaseModel, Field
+
+from app.api.auth import require_admin
+from app.core.dependencies import get_oom_forecast_service
+from app.services.oom_forecast import OomForecastService
+
+router = APIRouter(tags=["oom-forecast"], dependencies=[Depends(require_admin)])
+
+
+class OomForecast(BaseModel):
+    namespace: str
+    pod: str
+    container: str
+    usage_bytes: int
+    limit_bytes: int
+    usage_ratio: float
+    growth_bytes_per_hour: int
+    hours_to_limit: float
+    summary: str
+
+
+class OomForecastReport(BaseModel):
+    scanned_at: str
+    namespaces: list[str] = Field(default_factory=list)
+    lookback_hours: int
+    horizon_hours: int
+    forecast_count: int = 0
+    forecasts: list[OomForecast] = Field(default_factory=list)
+    notified: int = 0
+    errors: list[str] = Field(default_factory=list)
+    delivery: dict[str, object] | None = None
+
+
+@router.post("/oom-forecast", response_model=OomForecastReport)
+async def run_oom_forecast(
+    service: OomForecastService | None = Depends(get_oom_forecast_service),  # noqa: B008
+) -> OomForecastReport:
+    """Forecast containers trending toward their memory limit now."""
+    if service is None:
+        raise HTTPException(
+            status_code=400, detail="OOM forecasts are disabled or Prometheus is not configured"
+        )
+    report = await asyncio.to_thread(service.scan)
+    return OomForecastReport.model_validate(report)
+
+
+@router.get("/oom-forecast/latest", response_model=OomForecastReport)
+async def get_latest_oom_forecast(
+    service: OomForecastService | None = Depends(get_oom_forecast_service),  # noqa: B008
+) -> OomForecastReport:
+    """Return the most recent OOM forecast kept in memory."""
+    report = service.latest_report if service is not None else None
+    if report is None:
+        raise HTTPException(status_code=404, detail="no OOM forecast has run yet")
+    return OomForecastReport.model_validate(report)
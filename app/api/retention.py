@@ -0,0 +1,78 @@
+from __future__ import annotations
+
+import asyncio
+from datetime import datetime
+
+from fastapi import APIRouter, Depends, HTTPException, Query
+from pydantic import BaseModel, Field
+
+from app.api.auth import require_admin
+from app.core.dependencies import get_retention_service
+from app.services.retention import RetentionService
+
+router = APIRouter(tags=["retention"], dependencies=[Depends(require_admin)])
+
+
+class RetentionPurgeRequest(BaseModel):
+    """Optional overrides; omitted fields use the configured retention windows."""
+
+    session_retention_days: int | None = Field(default=None, ge=1)
+    summary_retention_days: int | None = Field(default=None, ge=1)
+
+
+class RetentionPurgeResponse(BaseModel):
+    status: str = "ok"
+    sessions_deleted: int | None = None
+    summaries_deleted: int | None = None
+    shadow_results_deleted: int | None = None
+    analysis_results_deleted: int | None = None
+    archives_deleted: int | None = None
+    archived_events_deleted: int | None = None
+
+
+@router.post("/retention/purge", response_model=RetentionPurgeResponse)
+async def purge_expired_data(
+    request: RetentionPurgeRequest | None = None,
+    service: RetentionService = Depends(get_retention_service),  # noqa: B008
+) -> RetentionPurgeResponse:
+    """Delete session transcripts and summaries older than the retention windows."""
+    overrides = request or RetentionPurgeRequest()
+    result = await asyncio.to_thread(
+        service.purge,
+        overrides.session_retention_days,
+        overrides.summary_retention_days,
+    )
+    return RetentionPurgeResponse(**result)
+
+
+class AnalysisDeletionResponse(BaseModel):
+    status: str = "ok"
+    sessions_deleted: int | None = None
+    summaries_deleted: int | None = None
+    shadow_results_deleted: int | None = None
+    analysis_results_deleted: int | None = None
+    archives_deleted: int | None = None
+    artifacts_deleted: int | None = None
+    archived_events_deleted: int | None = None
+
+
+@router.delete("/analyses", response_model=AnalysisDeletionResponse)
+async def delete_analyses(
+    namespace: str | None = Query(default=None),  # noqa: B008
+    incident_id: str | None = Query(default=None),  # noqa: B008
+    since: datetime | None = Query(default=None),  # noqa: B008
+    until: datetime | None = Query(default=None),  # noqa: B008
+    service: RetentionService = Depends(get_retention_service),  # noqa: B008
+) -> AnalysisDeletionResponse:
+    """Hard-delete stored transcripts, evidence and summaries for data-deletion requests."""
+    try:
+        result = await asyncio.to_thread(
+            service.delete_analyses,
+            namespace=namespace,
+            incident_id=incident_id,
+            since=since,
+            until=until,
+        )
+    except ValueError as exc:
+        raise HTTPException(status_code=400, detail=str(exc)) from exc
+    return AnalysisDeletionResponse(**result)
@@ -0,0 +1,24 @@
+from __future__ import annotations
+
+import asyncio
+
+from fastapi import APIRouter, Depends, HTTPException, Query
+
+from app.api.auth import require_admin
+from app.clients.shadow_store import PostgresShadowStore
+from app.core.dependencies import get_shadow_store
+
+router = APIRouter(tags=["shadow"], dependencies=[Depends(require_admin)])
+
+
+@router.get("/shadow/results")
+async def list_shadow_results(
+    limit: int = Query(default=50, ge=1, le=500),  # noqa: B008
+    alertname: str | None = Query(default=None),  # noqa: B008
+    store: PostgresShadowStore | None = Depends(get_shadow_store),  # noqa: B008
+) -> dict[str, object]:
+    """Primary and shadow answers for recent alerts, newest first, for offline comparison."""
+    if store is None:
+        raise HTTPException(status_code=400, detail="shadow mode is not configured")
+    results = await asyncio.to_thread(store.list_results, limit=limit, alertname=alertname)
+    return {"count": len(results), "results": results}
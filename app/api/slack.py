@@ -0,0 +1,87 @@
+from __future__ import annotations
+
+import json
+import urllib.parse
+
+from fastapi import APIRouter, Depends, HTTPException, Request
+
+from app.api.auth import require_admin
+from app.core.auth import AuthenticationError, OIDCVerifier
+from app.core.concurrency import run_in_thread_limited
+from app.core.config import Settings
+from app.core.dependencies import get_oidc_verifier, get_settings, get_slack_interaction_service
+from app.core.slack_signing import verify_slack_signature
+from app.schemas.slack import SlackInteractionResponse
+from app.services.analysis import AnalysisNotFoundError
+from app.services.slack_interactions import SlackInteractionService
+
+router = APIRouter(tags=["slack"])
+
+
+async def verify_slack_request(
+    request: Request,
+    settings: Settings = Depends(get_settings),  # noqa: B008
+    verifier: OIDCVerifier | None = Depends(get_oidc_verifier),  # noqa: B008
+) -> None:
+    """Check Slack's signature with ``SLACK_SIGNING_SECRET``, else require an admin token.
+
+    The backend must forward Slack's raw body together with the
+    ``X-Slack-Signature`` and ``X-Slack-Request-Timestamp`` headers.
+    """
+    if not settings.slack_signing_secret:
+        await require_admin(request, verifier)
+        return
+    try:
+        verify_slack_signature(
+            settings.slack_signing_secret,
+            timestamp=request.headers.get("x-slack-request-timestamp", ""),
+            body=await request.body(),
+            signature=request.headers.get("x-slack-signature", ""),
+        )
+    except AuthenticationError as exc:
+        raise HTTPException(status_code=401, detail=str(exc)) from exc
+
+
+@router.post(
+    "/slack/interactions",
+    response_model=SlackInteractionResponse,
+    dependencies=[Depends(verify_slack_request)],
+    # The body is read raw so its Slack signature can be checked.
+    openapi_extra={
+        "requestBody": {
+            "required": True,
+            "content": {
+                "application/json": {"schema": {"type": "object"}},
+                "application/x-www-form-urlencoded": {"schema": {"type": "object"}},
+            },
+        }
+    },
+)
+async def handle_slack_interaction(
+    http_request: Request,
+    service: SlackInteractionService = Depends(get_slack_interaction_service),  # noqa: B008
+) -> SlackInteractionResponse:
+    """Run the action behind a Slack button or slash command forwarded by the backend."""
+    payload = await _read_payload(http_request)
+    try:
+        result = await run_in_thread_limited(service.handle, payload, request=http_request)
+    except ValueError as exc:
+        raise HTTPException(status_code=400, detail=str(exc)) from exc
+    except AnalysisNotFoundError as exc:
+        raise HTTPException(status_code=404, detail="analysis session not found") from exc
+    return SlackInteractionResponse.model_validate(result)
+
+
+async def _read_payload(request: Request) -> object:
+    """Slack's form-encoded body as a dict, or a JSON body as forwarded by the backend."""
+    body = await request.body()
+    content_type = request.headers.get("content-type", "")
+    if content_type.startswith("application/x-www-form-urlencoded"):
+        fields = urllib.parse.parse_qs(body.decode("utf-8"), keep_blank_values=True)
+        return {key: values[-1] for key, values in fields.items()}
+    try:
+        return json.loads(body)
+    except ValueError as exc:
+        raise HTTPException(
+            status_code=400, detail="body must be JSON or application/x-www-form-urlencoded"
+        ) from exc
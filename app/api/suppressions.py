@@ -0,0 +1,100 @@
+from __future__ import annotations
+
+import asyncio
+from datetime import datetime
+
+from fastapi import APIRouter, Depends, HTTPException, Query
+from pydantic import BaseModel, Field
+
+from app.api.auth import require_admin
+from app.core.auth import Principal
+from app.core.dependencies import get_suppression_service
+from app.services.suppression import SuppressionService
+
+router = APIRouter(tags=["suppressions"], dependencies=[Depends(require_admin)])
+
+
+class SuppressionMatcher(BaseModel):
+    name: str
+    value: str = ""
+    operator: str = Field(default="=", pattern=r"^(=|!=|=~|!~)$")
+
+
+class SuppressionCreateRequest(BaseModel):
+    matchers: list[SuppressionMatcher] = Field(min_length=1)
+    duration_minutes: int = Field(ge=1)
+    reason: str = Field(min_length=1)
+    starts_at: datetime | None = None
+    # Recorded as the creator when OIDC is off; the token subject wins otherwise.
+    created_by: str | None = None
+
+
+def _require_suppressions(service: SuppressionService | None) -> SuppressionService:
+    if service is None:
+        raise HTTPException(status_code=400, detail="alert suppressions are not configured")
+    return service
+
+
+@router.post("/suppressions", status_code=201)
+async def create_suppression(
+    request: SuppressionCreateRequest,
+    principal: Principal | None = Depends(require_admin),  # noqa: B008
+    service: SuppressionService | None = Depends(get_suppression_service),  # noqa: B008
+) -> dict[str, object]:
+    """Suppress the analysis of alerts matching all matchers for a time window."""
+    suppressions = _require_suppressions(service)
+    try:
+        return await asyncio.to_thread(
+            suppressions.create,
+            [matcher.model_dump() for matcher in request.matchers],
+            duration_minutes=request.duration_minutes,
+            reason=request.reason,
+            created_by=principal.subject if principal is not None else request.created_by,
+            starts_at=request.starts_at,
+        )
+    except ValueError as exc:
+        raise HTTPException(status_code=400, detail=str(exc)) from exc
+
+
+@router.get("/suppressions")
+async def list_suppressions(
+    include_expired: bool = Query(default=False),  # noqa: B008
+    limit: int = Query(default=100, ge=1, le=500),  # noqa: B008
+    service: SuppressionService | None = Depends(get_suppression_service),  # noqa: B008
+) -> dict[str, object]:
+    """Active suppressions, newest first; with ``include_expired`` the full audit history."""
+    items = await asyncio.to_thread(
+        _require_suppressions(service).list_suppressions,
+        include_expired=include_expired,
+        limit=limit,
+    )
+    return {"count": len(items), "suppressions": items}
+
+
+@router.get("/suppressions/{suppression_id}")
+async def get_suppression(
+    suppression_id: int,
+    service: SuppressionService | None = Depends(get_suppression_service),  # noqa: B008
+) -> dict[str, object]:
+    """One suppression with the alerts it most recently suppressed."""
+    suppression = await asyncio.to_thread(_require_suppressions(service).get, suppression_id)
+    if suppression is None:
+        raise HTTPException(status_code=404, detail="suppression not found")
+    return suppression
+
+
+@router.delete("/suppressions/{suppression_id}")
+async def expire_suppression(
+    suppression_id: int,
+    principal: Principal | None = Depends(require_admin),  # noqa: B008
+    service: SuppressionService | None = Depends(get_suppression_service),  # noqa: B008
+) -> dict[str, object]:
+    """End a suppression now; it stays listed with ``include_expired`` for auditing."""
+    suppression = await asyncio.to_thread(
+        _require_suppressions(service).expire,
+        suppression_id,
+        expired_by=principal.subject if principal is not None else None,
+    )
+    if suppression is None:
+        raise HTTPException(status_code=404, detail="no active suppression with this id")
+    return suppression
@@ -0,0 +1,112 @@
+"""Built-in read-only web UI over the stored analysis history.
+
+``GET /ui`` serves a single page and its script (no external assets) that
+lists recent analyses and shows one analysis with its evidence and timeline.
+The page itself holds no data; it reads ``/ui/api/analyses`` which, like the
+other admin endpoints, requires an OIDC bearer token when OIDC is configured.
+"""
+
+from __future__ import annotations
+
+import asyncio
+from datetime import datetime
+from functools import lru_cache
+from pathlib import Path
+
+from fastapi import APIRouter, Depends, HTTPException, Query
+from fastapi.responses import HTMLResponse, Response
+
+from app.api.auth import require_admin
+from app.clients.analysis_store import AnalysisFilter, PostgresAnalysisStore
+from app.core.config import Settings
+from app.core.dependencies import get_analysis_store, get_settings
+from app.services.analysis_view import describe_stored_analysis, summarize_stored_analysis
+
+_ASSETS_DIR = Path(__file__).resolve().parent.parent / "ui"
+# Inline styles only; scripts must come from the page's own origin.
+_CONTENT_SECURITY_POLICY = (
+    "default-src 'self'; style-src 'self' 'unsafe-inline'; img-src 'self' data:; "
+    "frame-ancestors 'none'; base-uri 'none'; form-action 'none'"
+)
+
+
+def _require_web_ui(settings: Settings = Depends(get_settings)) -> None:  # noqa: B008
+    if not settings.web_ui_enabled:
+        raise HTTPException(status_code=404, detail="web UI is disabled")
+
+
+router = APIRouter(tags=["ui"], dependencies=[Depends(_require_web_ui)])
+
+
+@lru_cache
+def _asset(name: str) -> str:
+    return (_ASSETS_DIR / name).read_text(encoding="utf-8")
+
+
+def _require_store(store: PostgresAnalysisStore | None) -> PostgresAnalysisStore:
+    if store is None:
+        raise HTTPException(status_code=400, detail="analysis history is not configured")
+    return store
+
+
+@router.get("/ui", response_class=HTMLResponse, include_in_schema=False)
+async def ui_page() -> HTMLResponse:
+    return HTMLResponse(
+        _asset("index.html"),
+        headers={
+            "Content-Security-Policy": _CONTENT_SECURITY_POLICY,
+            "X-Content-Type-Options": "nosniff",
+            "Cache-Control": "no-cache",
+        },
+    )
+
+
+@router.get("/ui/app.js", include_in_schema=False)
+async def ui_script() -> Response:
+    return Response(
+        _asset("app.js"),
+        media_type="text/javascript",
+        headers={"X-Content-Type-Options": "nosniff", "Cache-Control": "no-cache"},
+    )
+
+
+@router.get("/ui/api/analyses", dependencies=[Depends(require_admin)])
+async def list_ui_analyses(
+    alertname: str | None = None,
+    namespace: str | None = None,
+    since: datetime | None = None,
+    until: datetime | None = None,
+    correlation_id: str | None = None,
+    before: int | None = Query(default=None, ge=1),  # noqa: B008
+    limit: int = Query(default=50, ge=1, le=200),  # noqa: B008
+    store: PostgresAnalysisStore | None = Depends(get_analysis_store),  # noqa: B008
+) -> dict[str, object]:
+    """Recent stored analyses, newest first; pass the last ``result_id`` as *before* to page."""
+    analysis_filter = AnalysisFilter(
+        alertname=alertname or None,
+        namespace=namespace or None,
+        since=since,
+        until=until,
+        correlation_id=correlation_id or None,
+    )
+    rows = await asyncio.to_thread(
+        _require_store(store).list_recent, analysis_filter, limit=limit, before=before
+    )
+    analyses = [summarize_stored_analysis(row) for row in rows]
+    return {
+        "count": len(analyses),
+        "analyses": analyses,
+        "next_before": analyses[-1]["result_id"] if len(analyses) == limit else None,
+    }
+
+
+@router.get("/ui/api/analyses/{result_id}", dependencies=[Depends(require_admin)])
+async def get_ui_analysis(
+    result_id: int,
+    store: PostgresAnalysisStore | None = Depends(get_analysis_store),  # noqa: B008
+) -> dict[str, object]:
+    """One stored analysis with its text, evidence sections and incident timeline."""
+    row = await asyncio.to_thread(_require_store(store).get_result, result_id)
+    if row is None:
+        raise HTTPException(status_code=404, detail="analysis not found")
+    return describe_stored_analysis(row)
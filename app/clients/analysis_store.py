@@ -0,0 +1,344 @@
+from __future__ import annotations
+
+import json
+import logging
+from dataclasses import dataclass
+from datetime import datetime
+from typing import Any, Protocol, cast
+
+import psycopg
+from psycopg.errors import DuplicateTable, UniqueViolation
+from psycopg.rows import dict_row
+
+from app.core.encryption import FieldCipher
+from app.core.signing import RecordSigner
+
+
+@dataclass(frozen=True)
+class StoredAnalysis:
+    session_key: str
+    analysis_id: str | None
+    alertname: str | None
+    namespace: str | None
+    fingerprint: str | None
+    incident_id: str | None
+    alert_status: str
+    pipeline_version: str
+    source: str
+    request: dict[str, Any]
+    result: dict[str, Any]
+    backfill_job_id: str | None = None
+    correlation_id: str | None = None
+
+
+@dataclass(frozen=True)
+class AnalysisFilter:
+    alertname: str | None = None
+    namespace: str | None = None
+    since: datetime | None = None
+    until: datetime | None = None
+    correlation_id: str | None = None
+
+
+class AnalysisStore(Protocol):
+    def record(self, analysis: StoredAnalysis) -> int: ...
+
+    def list_requests(
+        self, analysis_filter: AnalysisFilter, *, limit: int
+    ) -> list[tuple[int, dict[str, Any]]]: ...
+
+    def list_versions(
+        self, session_key: str, *, limit: int = 20, before: int | None = None
+    ) -> list[dict[str, object]]: ...
+
+
+class PostgresAnalysisStore:
+    """History of analysis requests and results, one row per pipeline run.
+
+    Live analyses and backfill re-runs are stored side by side with the
+    pipeline version that produced them, so results can be compared across
+    versions. Request and result payloads are encrypted with the field cipher.
+    With a signer, each result is signed as it is written, so a stored row can
+    later be shown to be unmodified.
+    """
+
+    def __init__(
+        self, dsn: str, cipher: FieldCipher | None = None, signer: RecordSigner | None = None
+    ) -> None:
+        self._dsn = dsn
+        self._cipher = cipher
+        self._signer = signer
+        self._logger = logging.getLogger(__name__)
+        self._ensure_schema()
+
+    def _connect(self) -> psycopg.Connection:
+        return psycopg.connect(self._dsn, row_factory=dict_row)
+
+    def _ensure_schema(self) -> None:
+        statements = [
+            """
+            CREATE TABLE IF NOT EXISTS kube_rca_analyses (
+                result_id BIGSERIAL PRIMARY KEY,
+                session_key TEXT NOT NULL,
+                analysis_id TEXT,
+                alertname TEXT,
+                namespace TEXT,
+                fingerprint TEXT,
+                incident_id TEXT,
+                alert_status TEXT NOT NULL,
+                pipeline_version TEXT NOT NULL,
+                source TEXT NOT NULL,
+                backfill_job_id TEXT,
+                request TEXT NOT NULL,
+                result TEXT NOT NULL,
+                created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+            )
+            """,
+            """
+            CREATE INDEX IF NOT EXISTS kube_rca_analyses_lookup_idx
+            ON kube_rca_analyses(alertname, created_at)
+            """,
+            """
+            CREATE INDEX IF NOT EXISTS kube_rca_analyses_session_idx
+            ON kube_rca_analyses(session_key, result_id DESC)
+            """,
+            """
+            CREATE INDEX IF NOT EXISTS kube_rca_analyses_created_at_idx
+            ON kube_rca_analyses(created_at)
+            """,
+            # Incident correlation ID, shared with the agent's logs, spans and the callback.
+            """
+            ALTER TABLE kube_rca_analyses
+            ADD COLUMN IF NOT EXISTS correlation_id TEXT
+            """,
+            """
+            CREATE INDEX IF NOT EXISTS kube_rca_analyses_correlation_idx
+            ON kube_rca_analyses(correlation_id)
+            """,
+            # Signature of the stored result (plain JSON; it is an HMAC, not sensitive).
+            """
+            ALTER TABLE kube_rca_analyses
+            ADD COLUMN IF NOT EXISTS signature TEXT
+            """,
+        ]
+        try:
+            with self._connect() as conn:
+                with conn.cursor() as cur:
+                    for statement in statements:
+                        cur.execute(statement)
+        except (UniqueViolation, DuplicateTable) as exc:
+            self._logger.debug("Schema already exists, skipping creation: %s", exc)
+
+    def record(self, analysis: StoredAnalysis) -> int:
+        result = self._stored_form(analysis.result)
+        signature = json.dumps(self._signer.sign(result)) if self._signer is not None else None
+        with self._connect() as conn:
+            with conn.cursor() as cur:
+                cur.execute(
+                    """
+                    INSERT INTO kube_rca_analyses (
+                        session_key, analysis_id, alertname, namespace, fingerprint,
+                        incident_id, alert_status, pipeline_version, source,
+                        backfill_job_id, correlation_id, request, result, signature
+                    )
+                    VALUES (%s, %s, %s, %s, %s, %s, %s, %s, %s, %s, %s, %s, %s, %s)
+                    RETURNING result_id
+                    """,
+                    (
+                        analysis.session_key,
+                        analysis.analysis_id,
+                        analysis.alertname,
+                        analysis.namespace,
+                        analysis.fingerprint,
+                        analysis.incident_id,
+                        analysis.alert_status,
+                        analysis.pipeline_version,
+                        analysis.source,
+                        analysis.backfill_job_id,
+                        analysis.correlation_id,
+                        self._encode(analysis.request),
+                        self._encode(result),
+                        signature,
+                    ),
+                )
+                row = cur.fetchone()
+        return int(row["result_id"]) if row else 0
+
+    def list_requests(
+        self, analysis_filter: AnalysisFilter, *, limit: int
+    ) -> list[tuple[int, dict[str, Any]]]:
+        """Original requests of live analyses matching the filter, oldest first."""
+        conditions = ["source = 'live'"]
+        params: list[object] = []
+        if analysis_filter.alertname:
+            conditions.append("alertname = %s")
+            params.append(analysis_filter.alertname)
+        if analysis_filter.namespace:
+            conditions.append("namespace = %s")
+            params.append(analysis_filter.namespace)
+        if analysis_filter.since is not None:
+            conditions.append("created_at >= %s")
+            params.append(analysis_filter.since)
+        if analysis_filter.until is not None:
+            conditions.append("created_at < %s")
+            params.append(analysis_filter.until)
+        params.append(limit)
+        query = (
+            "SELECT result_id, request FROM kube_rca_analyses WHERE "
+            + " AND ".join(conditions)
+            + " ORDER BY result_id LIMIT %s"
+        )
+        with self._connect() as conn:
+            with conn.cursor() as cur:
+                cur.execute(query, tuple(params))
+                rows = cur.fetchall()
+        return [(int(row["result_id"]), self._decode(row["request"])) for row in rows]
+
+    def list_versions(
+        self, session_key: str, *, limit: int = 20, before: int | None = None
+    ) -> list[dict[str, object]]:
+        """Stored results for one alert session across pipeline versions, newest first.
+
+        With *before*, only versions older than that ``result_id`` are listed.
+        """
+        conditions = ["session_key = %s"]
+        params: list[object] = [session_key]
+        if before is not None:
+            conditions.append("result_id < %s")
+            params.append(before)
+        params.append(limit)
+        query = (
+            "SELECT result_id, session_key, analysis_id, correlation_id, alertname, namespace, "
+            "alert_status, pipeline_version, source, backfill_job_id, result, signature, "
+            "created_at FROM kube_rca_analyses WHERE "
+            + " AND ".join(conditions)
+            + " ORDER BY result_id DESC LIMIT %s"
+        )
+        with self._connect() as conn:
+            with conn.cursor() as cur:
+                cur.execute(query, tuple(params))
+                rows = cur.fetchall()
+        return [self._decode_row(row) for row in rows]
+
+    def list_recent(
+        self, analysis_filter: AnalysisFilter, *, limit: int, before: int | None = None
+    ) -> list[dict[str, object]]:
+        """Stored results matching the filter, newest first; *before* pages by ``result_id``."""
+        conditions: list[str] = []
+        params: list[object] = []
+        if analysis_filter.alertname:
+            conditions.append("alertname = %s")
+            params.append(analysis_filter.alertname)
+        if analysis_filter.namespace:
+            conditions.append("namespace = %s")
+            params.append(analysis_filter.namespace)
+        if analysis_filter.since is not None:
+            conditions.append("created_at >= %s")
+            params.append(analysis_filter.since)
+        if analysis_filter.until is not None:
+            conditions.append("created_at < %s")
+            params.append(analysis_filter.until)
+        if analysis_filter.correlation_id:
+            conditions.append("correlation_id = %s")
+            params.append(analysis_filter.correlation_id)
+        if before is not None:
+            conditions.append("result_id < %s")
+            params.append(before)
+        params.append(limit)
+        query = (
+            "SELECT result_id, session_key, analysis_id, correlation_id, alertname, namespace, "
+            "alert_status, pipeline_version, source, result, created_at FROM kube_rca_analyses"
+            + (" WHERE " + " AND ".join(conditions) if conditions else "")
+            + " ORDER BY result_id DESC LIMIT %s"
+        )
+        with self._connect() as conn:
+            with conn.cursor() as cur:
+                cur.execute(query, tuple(params))
+                rows = cur.fetchall()
+        return [self._decode_row(row) for row in rows]
+
+    def get_result(self, result_id: int) -> dict[str, object] | None:
+        """One stored run with its request and result, or ``None``."""
+        with self._connect() as conn:
+            with conn.cursor() as cur:
+                cur.execute(
+                    """
+                    SELECT result_id, session_key, analysis_id, correlation_id, alertname,
+                           namespace, fingerprint, incident_id, alert_status, pipeline_version,
+                           source, backfill_job_id, request, result, signature, created_at
+                    FROM kube_rca_analyses
+                    WHERE result_id = %s
+                    """,
+                    (result_id,),
+                )
+                row = cur.fetchone()
+        return self._decode_row(row) if row else None
+
+    def purge_older_than(self, older_than_days: int) -> int:
+        with self._connect() as conn:
+            with conn.cursor() as cur:
+                cur.execute(
+                    """
+                    DELETE FROM kube_rca_analyses
+                    WHERE created_at < NOW() - make_interval(days => %s)
+                    """,
+                    (older_than_days,),
+                )
+                return cur.rowcount
+
+    def delete_results(
+        self,
+        *,
+        namespace: str | None = None,
+        session_prefix: str | None = None,
+        since: datetime | None = None,
+        until: datetime | None = None,
+    ) -> int:
+        conditions: list[str] = []
+        params: list[object] = []
+        if namespace:
+            conditions.append("namespace = %s")
+            params.append(namespace)
+        if session_prefix:
+            conditions.append("starts_with(session_key, %s)")
+            params.append(session_prefix)
+        if since is not None:
+            conditions.append("created_at >= %s")
+            params.append(since)
+        if until is not None:
+            conditions.append("created_at < %s")
+            params.append(until)
+        if not conditions:
+            raise ValueError("at least one deletion filter is required")
+
+        query = "DELETE FROM kube_rca_analyses WHERE " + " AND ".join(conditions)
+        with self._connect() as conn:
+            with conn.cursor() as cur:
+                cur.execute(query, tuple(params))
+                return cur.rowcount
+
+    def _decode_row(self, row: dict[str, Any]) -> dict[str, object]:
+        item = dict(row)
+        for key in ("request", "result"):
+            if key in item:
+                item[key] = self._decode(item[key])
+        if isinstance(item.get("signature"), str):
+            item["signature"] = json.loads(str(item["signature"]))
+        created_at = item.get("created_at")
+        if isinstance(created_at, datetime):
+            item["created_at"] = created_at.isoformat()
+        return item
+
+    @staticmethod
+    def _stored_form(payload: dict[str, Any]) -> dict[str, Any]:
+        # What _decode returns later, so the signature covers the stored payload.
+        return cast(dict[str, Any], json.loads(json.dumps(payload, default=str)))
+
+    def _encode(self, payload: dict[str, Any]) -> str:
+        text = json.dumps(payload, ensure_ascii=False, default=str)
+        return self._cipher.encrypt_text(text) if self._cipher is not None else text
+
+    def _decode(self, stored: str) -> dict[str, Any]:
+        text = self._cipher.decrypt_text(stored) if self._cipher is not None else stored
+        payload = json.loads(text)
+        return payload if isinstance(payload, dict) else {}
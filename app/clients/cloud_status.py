@@ -0,0 +1,227 @@
+from __future__ import annotations
+
+import json
+import logging
+import threading
+import time
+import urllib.request
+import xml.etree.ElementTree as ET
+from datetime import timezone
+from email.utils import parsedate_to_datetime
+
+from app.core.config import Settings
+from app.core.egress import check_egress
+from app.core.tls import open_url
+
+_FEED_URLS = {
+    "aws": "https://status.aws.amazon.com",
+    "gcp": "https://status.cloud.google.com/incidents.json",
+    "azure": "https://azure.status.microsoft/en-us/status/feed/",
+}
+# Services a Kubernetes cluster depends on, as named on each provider's status page.
+DEFAULT_CLOUD_STATUS_SERVICES = {
+    "aws": ("ec2", "eks", "elasticloadbalancing"),
+    "gcp": (
+        "Google Compute Engine",
+        "Google Kubernetes Engine",
+        "Cloud Load Balancing",
+        "Persistent Disk",
+    ),
+    "azure": ("Virtual Machines", "Azure Kubernetes Service", "Load Balancer", "Storage"),
+}
+_AWS_RESOLVED_PREFIXES = ("[resolved]", "service is operating normally")
+
+
+class CloudStatusClient:
+    """Ongoing incidents from the public status page of the cluster's cloud provider.
+
+    AWS is read from the per-service, per-region Health Dashboard RSS feeds,
+    GCP from the Service Health ``incidents.json`` and Azure from the status
+    RSS feed. Incidents are normalized to provider, title, services, regions
+    and start/end time, and cached for ``CLOUD_STATUS_CACHE_SECONDS`` so a
+    burst of alerts fetches the feeds once.
+    """
+
+    def __init__(self, settings: Settings) -> None:
+        self._logger = logging.getLogger(__name__)
+        self._enabled = settings.cloud_status_enabled
+        self._provider = settings.cloud_status_provider
+        self._region = settings.cloud_status_region
+        self._services = settings.cloud_status_services or DEFAULT_CLOUD_STATUS_SERVICES.get(
+            self._provider, ()
+        )
+        feed_url = settings.cloud_status_url or _FEED_URLS.get(self._provider, "")
+        self._feed_url = feed_url.rstrip("/")
+        self._timeout_seconds = settings.cloud_status_timeout_seconds
+        self._cache_seconds = settings.cloud_status_cache_seconds
+        self._lock = threading.Lock()
+        self._cached: tuple[float, list[dict[str, object]], list[str]] | None = None
+
+    @property
+    def enabled(self) -> bool:
+        return self._enabled and self._provider in _FEED_URLS and bool(self._region)
+
+    @property
+    def provider(self) -> str:
+        return self._provider
+
+    @property
+    def region(self) -> str:
+        return self._region
+
+    @property
+    def services(self) -> tuple[str, ...]:
+        return self._services
+
+    def active_incidents(self) -> tuple[list[dict[str, object]], list[str]]:
+        """Incidents that have not ended, and errors of feeds that could not be read."""
+        with self._lock:
+            cached = self._cached
+            if cached is not None and time.monotonic() - cached[0] < self._cache_seconds:
+                return cached[1], cached[2]
+        errors: list[str] = []
+        if self._provider == "aws":
+            incidents = self._aws_incidents(errors)
+        elif self._provider == "gcp":
+            incidents = self._gcp_incidents(errors)
+        else:
+            incidents = self._azure_incidents(errors)
+        with self._lock:
+            self._cached = (time.monotonic(), incidents, errors)
+        return incidents, errors
+
+    def _aws_incidents(self, errors: list[str]) -> list[dict[str, object]]:
+        incidents: list[dict[str, object]] = []
+        for service in self._services:
+            url = f"{self._feed_url}/rss/{service}-{self._region}.rss"
+            items = _rss_items(self._fetch(url, errors))
+            # Items are newest first; an open incident is every update since the last resolution.
+            open_items = []
+            for item in items:
+                if item["title"].lower().startswith(_AWS_RESOLVED_PREFIXES):
+                    break
+                open_items.append(item)
+            if not open_items:
+                continue
+            incidents.append(
+                {
+                    "provider": "aws",
+                    "id": open_items[-1]["guid"] or open_items[-1]["link"],
+                    "title": open_items[0]["title"],
+                    "summary": open_items[0]["description"],
+                    "services": [service],
+                    "regions": [self._region],
+                    "started_at": open_items[-1]["published"],
+                    "ended_at": None,
+                    "url": open_items[0]["link"] or None,
+                }
+            )
+        return incidents
+
+    def _gcp_incidents(self, errors: list[str]) -> list[dict[str, object]]:
+        body = self._fetch(self._feed_url, errors)
+        if body is None:
+            return []
+        try:
+            payload = json.loads(body)
+        except ValueError:
+            errors.append(f"{self._feed_url}: invalid JSON")
+            return []
+        incidents: list[dict[str, object]] = []
+        for item in payload if isinstance(payload, list) else []:
+            if not isinstance(item, dict) or item.get("end"):
+                continue
+            locations = item.get("currently_affected_locations") or item.get(
+                "previously_affected_locations"
+            )
+            products = item.get("affected_products") or [{"title": item.get("service_name")}]
+            uri = item.get("uri")
+            incidents.append(
+                {
+                    "provider": "gcp",
+                    "id": item.get("id"),
+                    "title": item.get("external_desc"),
+                    "summary": _nested_str(item, "most_recent_update", "text"),
+                    "services": _field_values(products, "title"),
+                    "regions": _field_values(locations, "id"),
+                    "started_at": item.get("begin"),
+                    "ended_at": None,
+                    "url": f"https://status.cloud.google.com/{uri}" if uri else None,
+                }
+            )
+        return incidents
+
+    def _azure_incidents(self, errors: list[str]) -> list[dict[str, object]]:
+        # The Azure feed only lists active incidents, described in free text.
+        return [
+            {
+                "provider": "azure",
+                "id": item["guid"] or item["link"],
+                "title": item["title"],
+                "summary": item["description"],
+                "services": [],
+                "regions": [],
+                "started_at": item["published"],
+                "ended_at": None,
+                "url": item["link"] or None,
+            }
+            for item in _rss_items(self._fetch(self._feed_url, errors))
+        ]
+
+    def _fetch(self, url: str, errors: list[str]) -> str | None:
+        request = urllib.request.Request(url, headers={"User-Agent": "kube-rca-agent"})
+        try:
+            check_egress(url)
+            with open_url(request, timeout=self._timeout_seconds) as response:
+                return response.read().decode("utf-8", errors="replace")
+        except Exception as exc:  # noqa: BLE001
+            self._logger.warning("Failed to read cloud status feed %s: %s", url, exc)
+            errors.append(f"{url}: {exc}")
+            return None
+
+
+def _rss_items(body: str | None) -> list[dict[str, str]]:
+    if not body:
+        return []
+    try:
+        root = ET.fromstring(body)
+    except ET.ParseError:
+        return []
+    return [
+        {
+            "title": (item.findtext("title") or "").strip(),
+            "description": (item.findtext("description") or "").strip(),
+            "link": (item.findtext("link") or "").strip(),
+            "guid": (item.findtext("guid") or "").strip(),
+            "published": _rfc822_to_iso(item.findtext("pubDate")),
+        }
+        for item in root.iter("item")
+    ]
+
+
+def _rfc822_to_iso(value: str | None) -> str:
+    if not value:
+        return ""
+    try:
+        parsed = parsedate_to_datetime(value.strip())
+    except (TypeError, ValueError):
+        return ""
+    if parsed.tzinfo is None:
+        parsed = parsed.replace(tzinfo=timezone.utc)
+    return parsed.astimezone(timezone.utc).isoformat()
+
+
+def _field_values(value: object, key: str) -> list[str]:
+    if not isinstance(value, list):
+        return []
+    return [str(item[key]) for item in value if isinstance(item, dict) and item.get(key)]
+
+
+def _nested_str(payload: dict[str, object], *keys: str) -> str | None:
+    value: object = payload
+    for key in keys:
+        if not isinstance(value, dict):
+            return None
+        value = value.get(key)
+    return value if isinstance(value, str) else None
+
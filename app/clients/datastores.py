@@ -0,0 +1,351 @@
+from __future__ import annotations
+
+import logging
+import socket
+import ssl
+import time
+import urllib.parse
+from typing import Any, BinaryIO
+
+import psycopg
+
+from app.core.config import Settings
+from app.core.egress import check_egress
+from app.core.tls import client_ssl_context_for
+
+_SCHEMES = {
+    "postgres": "postgres",
+    "postgresql": "postgres",
+    "mysql": "mysql",
+    "redis": "redis",
+    "rediss": "redis",
+}
+_DEFAULT_PORTS = {"postgres": 5432, "mysql": 3306, "redis": 6379}
+
+
+class DatastoreError(RuntimeError):
+    """Raised when a datastore answers with an error."""
+
+
+class DatastoreHealthClient:
+    """Check reachability, connection saturation and replication lag of datastores.
+
+    Targets come from ``DATASTORE_TARGETS_JSON``: a map of service name to
+    datastore URLs (``postgresql://``, ``mysql://``, ``redis://`` or
+    ``rediss://``). MySQL checks need the ``datastore-mysql`` extra; Redis is
+    spoken directly over RESP.
+    """
+
+    def __init__(self, settings: Settings) -> None:
+        self._logger = logging.getLogger(__name__)
+        self._enabled = settings.datastore_health_enabled
+        self._targets = dict(settings.datastore_targets)
+        self._timeout_seconds = settings.datastore_timeout_seconds
+        self._saturation_percent = settings.datastore_saturation_threshold_percent
+        self._lag_seconds = settings.datastore_replication_lag_threshold_seconds
+
+    @property
+    def enabled(self) -> bool:
+        return self._enabled and bool(self._targets)
+
+    def check_service(self, service: str) -> dict[str, object]:
+        urls = self._targets.get(service)
+        if not urls:
+            return {
+                "service": service,
+                "error": "no datastores configured for service",
+                "configured_services": sorted(self._targets),
+            }
+        datastores = [self.check(url) for url in urls]
+        findings = [
+            str(finding)
+            for datastore in datastores
+            for finding in _list(datastore.get("findings"))
+        ]
+        return {
+            "service": service,
+            "datastores": datastores,
+            "healthy": not findings,
+            "findings": findings,
+        }
+
+    def check(self, url: str) -> dict[str, object]:
+        parsed = urllib.parse.urlparse(url)
+        kind = _SCHEMES.get(parsed.scheme.lower())
+        host = parsed.hostname or ""
+        try:
+            port = parsed.port or _DEFAULT_PORTS.get(kind or "", 0)
+        except ValueError:
+            port = 0
+        endpoint = f"{host}:{port}"
+        result: dict[str, object] = {"type": kind or parsed.scheme, "endpoint": endpoint}
+        if kind is None or not host:
+            result["error"] = "unsupported datastore URL"
+            return result
+
+        started = time.monotonic()
+        try:
+            check_egress(url)
+            if kind == "postgres":
+                metrics = self._check_postgres(url)
+            elif kind == "mysql":
+                metrics = self._check_mysql(parsed, host, port)
+            else:
+                metrics = self._check_redis(parsed, host, port)
+        except ImportError:
+            result["error"] = "mysql checks need the 'datastore-mysql' extra"
+            return result
+        except Exception as exc:  # noqa: BLE001 - drivers raise many exception types.
+            error = _redact(str(exc), parsed.password)
+            self._logger.warning("Datastore check failed for %s %s: %s", kind, endpoint, error)
+            result.update(
+                {
+                    "reachable": False,
+                    "error": error,
+                    "findings": [f"{kind} {endpoint}: unreachable ({error})"],
+                }
+            )
+            return result
+
+        result["reachable"] = True
+        result["latency_ms"] = round((time.monotonic() - started) * 1000, 1)
+        result.update(metrics)
+        result["findings"] = self._findings(f"{kind} {endpoint}", metrics)
+        return result
+
+    def _check_postgres(self, url: str) -> dict[str, object]:
+        with psycopg.connect(
+            url,
+            connect_timeout=self._timeout_seconds,
+            options=f"-c statement_timeout={self._timeout_seconds * 1000}",
+        ) as conn:
+            used, limit = conn.execute(
+                "SELECT count(*), current_setting('max_connections')::int FROM pg_stat_activity"
+            ).fetchone()
+            (in_recovery,) = conn.execute("SELECT pg_is_in_recovery()").fetchone()
+            if in_recovery:
+                (lag,) = conn.execute(
+                    "SELECT EXTRACT(EPOCH FROM now() - pg_last_xact_replay_timestamp())"
+                ).fetchone()
+                replication: dict[str, object] = {"role": "replica", "lag_seconds": _float(lag)}
+            else:
+                rows = conn.execute(
+                    "SELECT application_name, state, EXTRACT(EPOCH FROM replay_lag) "
+                    "FROM pg_stat_replication"
+                ).fetchall()
+                replicas = [
+                    {"name": name, "state": state, "lag_seconds": _float(lag)}
+                    for name, state, lag in rows
+                ]
+                replication = {
+                    "role": "primary",
+                    "replicas": replicas,
+                    "lag_seconds": _max_lag(replicas),
+                }
+        return {"connections": _connections(used, limit), "replication": replication}
+
+    def _check_mysql(
+        self, parsed: urllib.parse.ParseResult, host: str, port: int
+    ) -> dict[str, object]:
+        import pymysql  # type: ignore[import-not-found]
+
+        conn = pymysql.connect(
+            host=host,
+            port=port,
+            user=urllib.parse.unquote(parsed.username or ""),
+            password=urllib.parse.unquote(parsed.password or ""),
+            database=parsed.path.lstrip("/") or None,
+            connect_timeout=self._timeout_seconds,
+            read_timeout=self._timeout_seconds,
+            cursorclass=pymysql.cursors.DictCursor,
+        )
+        try:
+            with conn.cursor() as cursor:
+                cursor.execute("SHOW GLOBAL STATUS LIKE 'Threads_connected'")
+                used = (cursor.fetchone() or {}).get("Value")
+                cursor.execute("SELECT @@max_connections AS max_connections")
+                limit = (cursor.fetchone() or {}).get("max_connections")
+                try:
+                    cursor.execute("SHOW REPLICA STATUS")
+                except pymysql.err.MySQLError:
+                    # MySQL < 8.0.22 and MariaDB only know the old statement.
+                    cursor.execute("SHOW SLAVE STATUS")
+                status = cursor.fetchone()
+        finally:
+            conn.close()
+
+        if not status:
+            replication: dict[str, object] = {"role": "primary", "lag_seconds": None}
+        else:
+            io_running = status.get("Replica_IO_Running", status.get("Slave_IO_Running"))
+            sql_running = status.get("Replica_SQL_Running", status.get("Slave_SQL_Running"))
+            replication = {
+                "role": "replica",
+                "lag_seconds": _float(
+                    status.get("Seconds_Behind_Source", status.get("Seconds_Behind_Master"))
+                ),
+                "link_up": io_running == "Yes" and sql_running == "Yes",
+                "last_error": status.get("Last_Error") or None,
+            }
+        return {"connections": _connections(used, limit), "replication": replication}
+
+    def _check_redis(
+        self, parsed: urllib.parse.ParseResult, host: str, port: int
+    ) -> dict[str, object]:
+        with socket.create_connection((host, port), timeout=self._timeout_seconds) as raw:
+            sock: socket.socket = raw
+            if parsed.scheme.lower() == "rediss":
+                context = client_ssl_context_for(host) or ssl.create_default_context()
+                sock = context.wrap_socket(raw, server_hostname=host)
+            stream = sock.makefile("rwb")
+            password = urllib.parse.unquote(parsed.password or "")
+            if password:
+                username = urllib.parse.unquote(parsed.username or "")
+                _redis_command(stream, "AUTH", *([username] if username else []), password)
+            info = _parse_redis_info(str(_redis_command(stream, "INFO", "clients")))
+            info.update(_parse_redis_info(str(_redis_command(stream, "INFO", "replication"))))
+            info.update(_parse_redis_info(str(_redis_command(stream, "INFO", "stats"))))
+            limit: object = info.get("maxclients")
+            if limit is None:
+                try:
+                    reply = _redis_command(stream, "CONFIG", "GET", "maxclients")
+                    limit = reply[1] if isinstance(reply, list) and len(reply) > 1 else None
+                except DatastoreError:
+                    # CONFIG is often disabled on managed Redis.
+                    limit = None
+
+        connections = _connections(info.get("connected_clients"), limit)
+        connections["rejected_total"] = _int(info.get("rejected_connections"))
+        if info.get("role") == "slave":
+            link_up = info.get("master_link_status") == "up"
+            lag_key = "master_last_io_seconds_ago" if link_up else "master_link_down_since_seconds"
+            replication: dict[str, object] = {
+                "role": "replica",
+                "link_up": link_up,
+                "lag_seconds": _float(info.get(lag_key)),
+            }
+        else:
+            replicas = [
+                {
+                    "name": fields.get("ip"),
+                    "state": fields.get("state"),
+                    "lag_seconds": _float(fields.get("lag")),
+                }
+                for key, value in info.items()
+                if key.startswith("slave") and key[5:].isdigit()
+                for fields in [_parse_redis_fields(value)]
+            ]
+            replication = {
+                "role": "primary",
+                "replicas": replicas,
+                "lag_seconds": _max_lag(replicas),
+            }
+        return {"connections": connections, "replication": replication}
+
+    def _findings(self, label: str, metrics: dict[str, object]) -> list[str]:
+        findings: list[str] = []
+        connections = metrics.get("connections")
+        if isinstance(connections, dict):
+            percent = connections.get("percent")
+            if isinstance(percent, float) and percent >= self._saturation_percent:
+                findings.append(
+                    f"{label}: {connections['used']}/{connections['max']} connections "
+                    f"in use ({percent}%)"
+                )
+            rejected = connections.get("rejected_total")
+            if isinstance(rejected, int) and rejected > 0:
+                findings.append(f"{label}: {rejected} connection(s) rejected since start")
+        replication = metrics.get("replication")
+        if isinstance(replication, dict):
+            if replication.get("link_up") is False:
+                findings.append(f"{label}: replication from the primary is stopped")
+            lag = replication.get("lag_seconds")
+            if isinstance(lag, float) and lag >= self._lag_seconds:
+                findings.append(f"{label}: replication lag {lag:g}s")
+        return findings
+
+
+def _redis_command(stream: BinaryIO, *args: str) -> Any:
+    payload = f"*{len(args)}\r\n".encode()
+    for arg in args:
+        encoded = arg.encode("utf-8")
+        payload += b"$" + str(len(encoded)).encode() + b"\r\n" + encoded + b"\r\n"
+    stream.write(payload)
+    stream.flush()
+    return _redis_reply(stream)
+
+
+def _redis_reply(stream: BinaryIO) -> Any:
+    line = stream.readline()
+    if not line:
+        raise DatastoreError("connection closed by redis")
+    kind, body = line[:1], line[1:].rstrip(b"\r\n").decode("utf-8", "replace")
+    if kind == b"+":
+        return body
+    if kind == b"-":
+        raise DatastoreError(body)
+    if kind == b":":
+        return int(body)
+    if kind == b"$":
+        if int(body) < 0:
+            return None
+        data = stream.read(int(body) + 2)
+        return data[:-2].decode("utf-8", "replace")
+    if kind == b"*":
+        return [_redis_reply(stream) for _ in range(max(int(body), 0))]
+    raise DatastoreError(f"unexpected redis reply: {line[:40]!r}")
+
+
+def _parse_redis_info(text: str) -> dict[str, str]:
+    info: dict[str, str] = {}
+    for line in text.splitlines():
+        if not line or line.startswith("#") or ":" not in line:
+            continue
+        key, _, value = line.partition(":")
+        info[key.strip()] = value.strip()
+    return info
+
+
+def _parse_redis_fields(value: str) -> dict[str, str]:
+    return dict(item.split("=", 1) for item in value.split(",") if "=" in item)
+
+
+def _connections(used: object, limit: object) -> dict[str, Any]:
+    used_count = _int(used)
+    max_count = _int(limit)
+    percent = None
+    if used_count is not None and max_count:
+        percent = round(used_count * 100 / max_count, 1)
+    return {"used": used_count, "max": max_count, "percent": percent}
+
+
+def _max_lag(replicas: list[dict[str, object]]) -> float | None:
+    lags = [lag for item in replicas if isinstance(lag := item.get("lag_seconds"), float)]
+    return max(lags) if lags else None
+
+
+def _int(value: object) -> int | None:
+    try:
+        return int(str(value))
+    except (TypeError, ValueError):
+        return None
+
+
+def _float(value: object) -> float | None:
+    if value is None:
+        return None
+    try:
+        return round(float(str(value)), 3)
+    except ValueError:
+        return None
+
+
+def _list(value: object) -> list[object]:
+    return value if isinstance(value, list) else []
+
+
+def _redact(message: str, password: str | None) -> str:
+    if password:
+        message = message.replace(password, "***")
+        message = message.replace(urllib.parse.unquote(password), "***")
+    return message
@@ -0,0 +1,280 @@
+from __future__ import annotations
+
+import functools
+import ipaddress
+import logging
+import socket
+import ssl
+import time
+import urllib.error
+import urllib.parse
+import urllib.request
+from collections.abc import Callable
+from datetime import datetime, timezone
+from http.client import HTTPConnection, HTTPResponse, HTTPSConnection
+from typing import Any
+
+from app.core.config import Settings
+from app.core.egress import EgressDenied, check_egress
+
+_MAX_BODY_BYTES = 64 * 1024
+_USER_AGENT = "kube-rca-agent/endpoint-probe"
+
+_Resolver = Callable[[str, int], str]
+
+
+class BlockedAddress(ValueError):
+    """Raised when a probed host resolves to an address that is not probed."""
+
+
+class EndpointProbeClient:
+    """Synthetic HTTP(S) check of a service's external URL.
+
+    Sends one unauthenticated GET and reports the status code, latency and,
+    for ``https://``, whether the certificate chain and hostname verify and
+    when the certificate expires. ``impact`` is ``confirmed`` when users
+    cannot be served (unreachable, 5xx, invalid certificate), ``degraded``
+    when the endpoint is slow and ``none`` otherwise. The agent's client
+    certificate is never presented.
+
+    The URL comes from alert and Service annotations, so every connection,
+    including each redirect, first resolves its host and refuses loopback,
+    link-local, private and reserved addresses outside
+    ``ENDPOINT_PROBE_ALLOWED_NETWORKS_JSON``. It then connects to exactly the
+    address that was checked, so a second DNS answer cannot rebind it.
+    """
+
+    def __init__(self, settings: Settings) -> None:
+        self._logger = logging.getLogger(__name__)
+        self._enabled = settings.endpoint_probe_enabled
+        self._timeout_seconds = settings.endpoint_probe_timeout_seconds
+        self._slow_ms = settings.endpoint_probe_slow_threshold_ms
+        self._expiry_warning_days = settings.endpoint_probe_tls_expiry_warning_days
+        self._allowed_networks = tuple(
+            ipaddress.ip_network(network) for network in settings.endpoint_probe_allowed_networks
+        )
+
+    @property
+    def enabled(self) -> bool:
+        return self._enabled
+
+    def probe(self, url: str) -> dict[str, object]:
+        url = url.strip()
+        parsed = urllib.parse.urlparse(url)
+        if parsed.scheme not in ("http", "https") or not parsed.hostname:
+            return {"url": url, "error": "only absolute http:// and https:// URLs are probed"}
+        try:
+            check_egress(url)
+            port = parsed.port or (443 if parsed.scheme == "https" else 80)
+        except (EgressDenied, ValueError) as exc:
+            return {"url": url, "error": str(exc)}
+
+        try:
+            tls = self._check_tls(parsed.hostname, port) if parsed.scheme == "https" else None
+            # Still measure status and latency behind an invalid certificate.
+            http = self._request(url, verify=tls is None or tls.get("valid") is not False)
+        except BlockedAddress as exc:
+            self._logger.warning("Endpoint probe of %s refused: %s", url, exc)
+            return {"url": url, "error": str(exc)}
+        impact, findings = self._assess(http, tls)
+        return {"url": url, "http": http, "tls": tls, "impact": impact, "findings": findings}
+
+    def _check_tls(self, host: str, port: int) -> dict[str, object]:
+        context = ssl.create_default_context()
+        try:
+            with (
+                socket.create_connection(
+                    (self._resolve(host, port), port), timeout=self._timeout_seconds
+                ) as sock,
+                context.wrap_socket(sock, server_hostname=host) as tls_sock,
+            ):
+                cert = tls_sock.getpeercert() or {}
+                protocol = tls_sock.version()
+        except ssl.SSLCertVerificationError as exc:
+            return {"valid": False, "error": exc.verify_message or str(exc)}
+        except ssl.SSLError as exc:
+            return {"valid": False, "error": str(exc)}
+        except OSError as exc:
+            # Connection problems are reported by the HTTP request.
+            return {"valid": None, "error": str(exc)}
+
+        not_after = cert.get("notAfter")
+        expires = (
+            datetime.fromtimestamp(ssl.cert_time_to_seconds(not_after), tz=timezone.utc)
+            if isinstance(not_after, str)
+            else None
+        )
+        return {
+            "valid": True,
+            "protocol": protocol,
+            "subject": _name_field(cert.get("subject"), "commonName"),
+            "issuer": _name_field(cert.get("issuer"), "organizationName")
+            or _name_field(cert.get("issuer"), "commonName"),
+            "not_after": expires.isoformat() if expires else None,
+            "days_remaining": (
+                (expires - datetime.now(timezone.utc)).days if expires is not None else None
+            ),
+        }
+
+    def _request(self, url: str, *, verify: bool) -> dict[str, object]:
+        context = ssl.create_default_context()
+        if not verify:
+            context.check_hostname = False
+            context.verify_mode = ssl.CERT_NONE
+        request = urllib.request.Request(url, headers={"User-Agent": _USER_AGENT})
+        # No proxy: the connection must go to the address that was checked.
+        opener = urllib.request.build_opener(
+            urllib.request.ProxyHandler({}),
+            _PinnedHTTPHandler(self._resolve),
+            _PinnedHTTPSHandler(self._resolve, context=context),
+            _EgressCheckedRedirectHandler(),
+        )
+        started = time.perf_counter()
+        try:
+            with opener.open(request, timeout=self._timeout_seconds) as response:
+                response.read(_MAX_BODY_BYTES)
+                status, final_url = response.status, response.geturl()
+        except urllib.error.HTTPError as exc:
+            status, final_url = exc.code, exc.geturl()
+        except BlockedAddress:
+            raise
+        except Exception as exc:  # noqa: BLE001
+            reason = getattr(exc, "reason", exc)
+            self._logger.warning("Endpoint probe of %s failed: %s", url, reason)
+            return {
+                "status_code": None,
+                "latency_ms": _elapsed_ms(started),
+                "error": str(reason),
+            }
+        return {
+            "status_code": status,
+            "latency_ms": _elapsed_ms(started),
+            "final_url": final_url if final_url != url else None,
+        }
+
+    def _resolve(self, host: str, port: int) -> str:
+        """The address to connect to for *host*, refusing internal targets."""
+        addresses = [
+            str(sockaddr[0])
+            for *_, sockaddr in socket.getaddrinfo(host, port, type=socket.SOCK_STREAM)
+        ]
+        if not addresses:
+            raise OSError(f"{host} did not resolve")
+        for address in addresses:
+            ip = ipaddress.ip_address(address)
+            if isinstance(ip, ipaddress.IPv6Address) and ip.ipv4_mapped is not None:
+                ip = ip.ipv4_mapped
+            internal = (
+                ip.is_loopback
+                or ip.is_link_local
+                or ip.is_private
+                or ip.is_reserved
+                or ip.is_multicast
+                or ip.is_unspecified
+            )
+            # Every answer is checked, so the address picked below cannot be an internal one.
+            if internal and not any(ip in network for network in self._allowed_networks):
+                target = host if host == str(ip) else f"{host} ({ip})"
+                raise BlockedAddress(
+                    f"{target} is an internal address and is not probed "
+                    "(add its network to ENDPOINT_PROBE_ALLOWED_NETWORKS_JSON)"
+                )
+        return addresses[0]
+
+    def _assess(
+        self, http: dict[str, object], tls: dict[str, object] | None
+    ) -> tuple[str, list[str]]:
+        confirmed: list[str] = []
+        degraded: list[str] = []
+        notes: list[str] = []
+        status = http.get("status_code")
+        if http.get("error"):
+            confirmed.append(f"endpoint unreachable: {http['error']}")
+        elif isinstance(status, int) and status >= 500:
+            confirmed.append(f"endpoint returned HTTP {status}")
+        elif isinstance(status, int) and status >= 400:
+            notes.append(f"endpoint returned HTTP {status} (may be expected without credentials)")
+        if tls is not None and tls.get("valid") is False:
+            confirmed.append(f"TLS certificate is not valid: {tls.get('error')}")
+        days_remaining = tls.get("days_remaining") if tls else None
+        if isinstance(days_remaining, int) and days_remaining < self._expiry_warning_days:
+            notes.append(f"TLS certificate expires in {days_remaining} day(s)")
+        latency = http.get("latency_ms")
+        if not confirmed and isinstance(latency, float) and latency >= self._slow_ms:
+            degraded.append(f"endpoint answered in {latency} ms (threshold {self._slow_ms} ms)")
+        impact = "confirmed" if confirmed else "degraded" if degraded else "none"
+        return impact, [*confirmed, *degraded, *notes]
+
+
+class _EgressCheckedRedirectHandler(urllib.request.HTTPRedirectHandler):
+    """Follow redirects only to hosts the egress allowlist permits."""
+
+    def redirect_request(  # type: ignore[no-untyped-def]
+        self, req, fp, code, msg, headers, newurl
+    ):
+        check_egress(newurl)
+        return super().redirect_request(req, fp, code, msg, headers, newurl)
+
+
+class _PinnedHTTPConnection(HTTPConnection):
+    """HTTP connection to the checked address of its host."""
+
+    def __init__(self, *args: Any, resolve: _Resolver, **kwargs: Any) -> None:
+        super().__init__(*args, **kwargs)
+        self._resolve = resolve
+
+    def connect(self) -> None:
+        self.sock = socket.create_connection(
+            (self._resolve(self.host, self.port), self.port), self.timeout, self.source_address
+        )
+
+
+class _PinnedHTTPSConnection(HTTPSConnection):
+    """HTTPS connection to the checked address; SNI and verification use the host name."""
+
+    def __init__(self, *args: Any, resolve: _Resolver, **kwargs: Any) -> None:
+        super().__init__(*args, **kwargs)
+        self._resolve = resolve
+
+    def connect(self) -> None:
+        sock = socket.create_connection(
+            (self._resolve(self.host, self.port), self.port), self.timeout, self.source_address
+        )
+        self.sock = self._context.wrap_socket(sock, server_hostname=self.host)
+
+
+class _PinnedHTTPHandler(urllib.request.HTTPHandler):
+    def __init__(self, resolve: _Resolver) -> None:
+        super().__init__()
+        self._resolve = resolve
+
+    def http_open(self, req: urllib.request.Request) -> HTTPResponse:
+        return self.do_open(functools.partial(_PinnedHTTPConnection, resolve=self._resolve), req)
+
+
+class _PinnedHTTPSHandler(urllib.request.HTTPSHandler):
+    def __init__(self, resolve: _Resolver, *, context: ssl.SSLContext) -> None:
+        super().__init__(context=context)
+        self._resolve = resolve
+
+    def https_open(self, req: urllib.request.Request) -> HTTPResponse:
+        return self.do_open(
+            functools.partial(_PinnedHTTPSConnection, resolve=self._resolve),
+            req,
+            context=self._context,  # type: ignore[attr-defined]
+        )
+
+
+def _elapsed_ms(started: float) -> float:
+    return round((time.perf_counter() - started) * 1000, 1)
+
+
+def _name_field(name: object, key: str) -> str | None:
+    # getpeercert() names are tuples of RDNs, each a tuple of (key, value) pairs.
+    if not isinstance(name, tuple):
+        return None
+    for rdn in name:
+        for item in rdn:
+            if isinstance(item, tuple) and len(item) == 2 and item[0] == key:
+                return str(item[1])
+    return None
@@ -0,0 +1,233 @@
+from __future__ import annotations
+
+import json
+import logging
+from datetime import datetime
+from typing import Protocol
+
+import psycopg
+from psycopg.errors import DuplicateTable, UniqueViolation
+from psycopg.rows import dict_row
+
+from app.core.encryption import FieldCipher
+from app.models.k8s import PodEventSummary
+
+
+class EventArchive(Protocol):
+    def record(self, events: list[tuple[str, PodEventSummary]]) -> int: ...
+
+    def list_events(
+        self,
+        namespace: str,
+        *,
+        involved_name: str | None = None,
+        involved_name_prefix: str | None = None,
+        since: datetime | None = None,
+        until: datetime | None = None,
+        limit: int = 50,
+    ) -> list[PodEventSummary]: ...
+
+
+class PostgresEventArchive:
+    """Cluster events kept after Kubernetes garbage-collects them (one row per event uid).
+
+    Updates of an event (a higher count, a newer lastTimestamp) overwrite its
+    row. Messages are encrypted with the field cipher.
+    """
+
+    def __init__(self, dsn: str, cipher: FieldCipher | None = None) -> None:
+        self._dsn = dsn
+        self._cipher = cipher
+        self._logger = logging.getLogger(__name__)
+        self._ensure_schema()
+
+    def _connect(self) -> psycopg.Connection:
+        return psycopg.connect(self._dsn, row_factory=dict_row)
+
+    def _ensure_schema(self) -> None:
+        statements = [
+            """
+            CREATE TABLE IF NOT EXISTS kube_rca_events (
+                event_uid TEXT PRIMARY KEY,
+                namespace TEXT,
+                involved_kind TEXT,
+                involved_name TEXT,
+                involved_object TEXT,
+                type TEXT,
+                reason TEXT,
+                message TEXT,
+                count INTEGER,
+                first_timestamp TIMESTAMPTZ,
+                last_timestamp TIMESTAMPTZ,
+                updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+            )
+            """,
+            """
+            CREATE INDEX IF NOT EXISTS kube_rca_events_involved_idx
+            ON kube_rca_events(namespace, involved_name, last_timestamp DESC)
+            """,
+            """
+            CREATE INDEX IF NOT EXISTS kube_rca_events_updated_at_idx
+            ON kube_rca_events(updated_at)
+            """,
+        ]
+        try:
+            with self._connect() as conn:
+                with conn.cursor() as cur:
+                    for statement in statements:
+                        cur.execute(statement)
+        except (UniqueViolation, DuplicateTable) as exc:
+            self._logger.debug("Schema already exists, skipping creation: %s", exc)
+
+    def record(self, events: list[tuple[str, PodEventSummary]]) -> int:
+        if not events:
+            return 0
+        rows = []
+        for uid, event in events:
+            involved = event.involved_object or {}
+            message = event.message
+            if self._cipher is not None and message is not None:
+                message = self._cipher.encrypt_text(message)
+            rows.append(
+                (
+                    uid,
+                    involved.get("namespace"),
+                    involved.get("kind"),
+                    involved.get("name"),
+                    json.dumps(involved) if event.involved_object else None,
+                    event.type,
+                    event.reason,
+                    message,
+                    event.count,
+                    _parse_time(event.first_timestamp),
+                    _parse_time(event.last_timestamp),
+                )
+            )
+        with self._connect() as conn:
+            with conn.cursor() as cur:
+                cur.executemany(
+                    """
+                    INSERT INTO kube_rca_events (
+                        event_uid, namespace, involved_kind, involved_name, involved_object,
+                        type, reason, message, count, first_timestamp, last_timestamp
+                    )
+                    VALUES (%s, %s, %s, %s, %s, %s, %s, %s, %s, %s, %s)
+                    ON CONFLICT (event_uid) DO UPDATE SET
+                        message = EXCLUDED.message,
+                        count = EXCLUDED.count,
+                        last_timestamp = EXCLUDED.last_timestamp,
+                        updated_at = NOW()
+                    """,
+                    rows,
+                )
+        return len(rows)
+
+    def list_events(
+        self,
+        namespace: str,
+        *,
+        involved_name: str | None = None,
+        involved_name_prefix: str | None = None,
+        since: datetime | None = None,
+        until: datetime | None = None,
+        limit: int = 50,
+    ) -> list[PodEventSummary]:
+        """Archived events of a namespace overlapping ``[since, until]``, newest first."""
+        conditions = ["namespace = %s"]
+        params: list[object] = [namespace]
+        if involved_name:
+            conditions.append("involved_name = %s")
+            params.append(involved_name)
+        elif involved_name_prefix:
+            conditions.append("starts_with(involved_name, %s)")
+            params.append(involved_name_prefix)
+        if since is not None:
+            conditions.append("COALESCE(last_timestamp, first_timestamp, updated_at) >= %s")
+            params.append(since)
+        if until is not None:
+            conditions.append("COALESCE(first_timestamp, last_timestamp, updated_at) <= %s")
+            params.append(until)
+        query = (
+            "SELECT * FROM kube_rca_events WHERE "
+            + " AND ".join(conditions)
+            + " ORDER BY last_timestamp DESC NULLS LAST LIMIT %s"
+        )
+        params.append(limit)
+        with self._connect() as conn:
+            with conn.cursor() as cur:
+                cur.execute(query, tuple(params))
+                rows = cur.fetchall()
+        events: list[PodEventSummary] = []
+        for row in rows:
+            message = row.get("message")
+            if self._cipher is not None and message is not None:
+                message = self._cipher.decrypt_text(message)
+            involved = row.get("involved_object")
+            events.append(
+                PodEventSummary(
+                    type=row.get("type"),
+                    reason=row.get("reason"),
+                    message=message,
+                    count=row.get("count"),
+                    first_timestamp=_format_time(row.get("first_timestamp")),
+                    last_timestamp=_format_time(row.get("last_timestamp")),
+                    involved_object=json.loads(involved) if involved else None,
+                )
+            )
+        return events
+
+    def delete_events(
+        self,
+        *,
+        namespace: str | None = None,
+        since: datetime | None = None,
+        until: datetime | None = None,
+    ) -> int:
+        """Hard-delete archived events of *namespace* last seen in ``[since, until)``."""
+        conditions: list[str] = []
+        params: list[object] = []
+        if namespace:
+            conditions.append("namespace = %s")
+            params.append(namespace)
+        if since is not None:
+            conditions.append("COALESCE(last_timestamp, updated_at) >= %s")
+            params.append(since)
+        if until is not None:
+            conditions.append("COALESCE(last_timestamp, updated_at) < %s")
+            params.append(until)
+        if not conditions:
+            raise ValueError("at least one deletion filter is required")
+
+        query = "DELETE FROM kube_rca_events WHERE " + " AND ".join(conditions)
+        with self._connect() as conn:
+            with conn.cursor() as cur:
+                cur.execute(query, tuple(params))
+                return cur.rowcount
+
+    def purge_older_than(self, older_than_days: int) -> int:
+        with self._connect() as conn:
+            with conn.cursor() as cur:
+                cur.execute(
+                    """
+                    DELETE FROM kube_rca_events
+                    WHERE COALESCE(last_timestamp, updated_at)
+                        < NOW() - make_interval(days => %s)
+                    """,
+                    (older_than_days,),
+                )
+                return cur.rowcount
+
+
+def _parse_time(value: str | None) -> datetime | None:
+    if not value:
+        return None
+    try:
+        return datetime.fromisoformat(value.replace("Z", "+00:00"))
+    except ValueError:
+        return None
+
+
+def _format_time(value: object) -> str | None:
+    if isinstance(value, datetime):
+        return value.isoformat()
+    return str(value) if value else None
@@ -0,0 +1,323 @@
+from __future__ import annotations
+
+import json
+import logging
+import urllib.error
+import urllib.parse
+import urllib.request
+
+from app.core.config import Settings
+from app.core.egress import check_egress
+from app.core.tls import open_url
+
+_MAX_PIPELINES = 5
+_FAILED_CONCLUSIONS = frozenset({"failure", "cancelled", "timed_out", "startup_failure"})
+# GitLab pipeline status -> GitHub-style conclusion.
+_GITLAB_CONCLUSIONS = {"success": "success", "failed": "failure", "canceled": "cancelled"}
+
+
+class GitHostingClient:
+    """Compare commits and read CI runs of a repository on GitHub or GitLab.
+
+    The repository is taken from the image's ``org.opencontainers.image.source``
+    URL: ``github.com`` (or the host of ``GITHUB_API_URL``) uses the GitHub
+    compare API, any host containing ``gitlab`` the GitLab repository compare
+    API. Self-hosted instances are matched by the host of their API URL.
+    """
+
+    def __init__(self, settings: Settings) -> None:
+        self._logger = logging.getLogger(__name__)
+        self._enabled = settings.git_correlation_enabled
+        self._github_api_url = settings.github_api_url.strip().rstrip("/")
+        self._github_token = settings.github_token.strip()
+        self._gitlab_api_url = settings.gitlab_api_url.strip().rstrip("/")
+        self._gitlab_token = settings.gitlab_token.strip()
+        self._timeout_seconds = settings.git_http_timeout_seconds
+        self._max_commits = settings.git_compare_max_commits
+
+    @property
+    def enabled(self) -> bool:
+        return self._enabled
+
+    def compare(self, source_url: str, base: str, head: str) -> dict[str, object]:
+        """Commits and changed files between *base* and *head* (exclusive of base)."""
+        provider, project = self._resolve_project(source_url)
+        if provider is None:
+            return {"error": f"unsupported source repository: {source_url}"}
+        if provider == "github":
+            return self._compare_github(project, base, head)
+        return self._compare_gitlab(project, base, head)
+
+    def pipeline_status(self, source_url: str, sha: str) -> dict[str, object]:
+        """CI runs (GitHub Actions workflow runs or GitLab pipelines) for commit *sha*.
+
+        ``partially_failed`` is set when a run failed or was cancelled, or when
+        jobs failed or were skipped, e.g. a migration stage that never ran.
+        """
+        provider, project = self._resolve_project(source_url)
+        if provider is None:
+            return {"error": f"unsupported source repository: {source_url}"}
+        if provider == "github":
+            runs, error = self._github_runs(project, sha)
+        else:
+            runs, error = self._gitlab_pipelines(project, sha)
+        if error is not None:
+            return {"provider": provider, "repository": project, "sha": sha, **error}
+        failed_runs = [run for run in runs if run.get("conclusion") in _FAILED_CONCLUSIONS]
+        return {
+            "provider": provider,
+            "repository": project,
+            "sha": sha,
+            "run_count": len(runs),
+            "runs": runs,
+            "partially_failed": bool(failed_runs)
+            or any(run.get("failed_jobs") or run.get("skipped_jobs") for run in runs),
+            "skipped_migrations": [
+                job
+                for run in runs
+                for job in _str_list(run.get("skipped_jobs"))
+                if "migrat" in job.lower()
+            ],
+        }
+
+    def _github_runs(
+        self, project: str, sha: str
+    ) -> tuple[list[dict[str, object]], dict[str, object] | None]:
+        headers = {"Accept": "application/vnd.github+json"}
+        if self._github_token:
+            headers["Authorization"] = f"Bearer {self._github_token}"
+        params = urllib.parse.urlencode({"head_sha": sha, "per_page": str(_MAX_PIPELINES)})
+        payload, error = self._get_json(
+            f"{self._github_api_url}/repos/{project}/actions/runs?{params}", headers
+        )
+        if payload is None:
+            return [], error
+        runs: list[dict[str, object]] = []
+        for item in _dict_items(payload.get("workflow_runs"))[:_MAX_PIPELINES]:
+            jobs_payload, _ = self._get_json(
+                f"{self._github_api_url}/repos/{project}/actions/runs/{item.get('id')}"
+                "/jobs?per_page=100",
+                headers,
+            )
+            jobs = _dict_items(jobs_payload.get("jobs")) if jobs_payload else []
+            runs.append(
+                {
+                    "id": item.get("id"),
+                    "name": item.get("name"),
+                    "event": item.get("event"),
+                    "status": item.get("status"),
+                    "conclusion": item.get("conclusion"),
+                    "url": item.get("html_url"),
+                    "failed_jobs": _job_names(jobs, "conclusion", _FAILED_CONCLUSIONS),
+                    "skipped_jobs": _job_names(jobs, "conclusion", {"skipped"}),
+                }
+            )
+        return runs, None
+
+    def _gitlab_pipelines(
+        self, project: str, sha: str
+    ) -> tuple[list[dict[str, object]], dict[str, object] | None]:
+        headers = {"Accept": "application/json"}
+        if self._gitlab_token:
+            headers["PRIVATE-TOKEN"] = self._gitlab_token
+        base = f"{self._gitlab_api_url}/projects/{urllib.parse.quote(project, safe='')}"
+        params = urllib.parse.urlencode({"sha": sha, "per_page": str(_MAX_PIPELINES)})
+        payload, error = self._get_json_list(f"{base}/pipelines?{params}", headers)
+        if payload is None:
+            return [], error
+        runs: list[dict[str, object]] = []
+        for item in payload[:_MAX_PIPELINES]:
+            jobs, _ = self._get_json_list(
+                f"{base}/pipelines/{item.get('id')}/jobs?per_page=100", headers
+            )
+            status = item.get("status")
+            runs.append(
+                {
+                    "id": item.get("id"),
+                    "name": item.get("ref"),
+                    "event": item.get("source"),
+                    "status": status,
+                    "conclusion": _GITLAB_CONCLUSIONS.get(str(status), status),
+                    "url": item.get("web_url"),
+                    "failed_jobs": _job_names(jobs or [], "status", {"failed", "canceled"}),
+                    "skipped_jobs": _job_names(jobs or [], "status", {"skipped"}),
+                }
+            )
+        return runs, None
+
+    def _resolve_project(self, source_url: str) -> tuple[str | None, str]:
+        url = source_url.strip().removesuffix(".git")
+        if url.startswith("git@"):
+            url = "https://" + url[4:].replace(":", "/", 1)
+        parsed = urllib.parse.urlparse(url)
+        project = parsed.path.strip("/")
+        host = parsed.netloc.lower()
+        if not host or project.count("/") < 1:
+            return None, project
+        github_host = urllib.parse.urlparse(self._github_api_url).netloc.lower()
+        if host == "github.com" or (github_host and host == github_host):
+            return "github", "/".join(project.split("/")[:2])
+        gitlab_host = urllib.parse.urlparse(self._gitlab_api_url).netloc.lower()
+        if "gitlab" in host or (gitlab_host and host == gitlab_host):
+            return "gitlab", project
+        return None, project
+
+    def _compare_github(self, project: str, base: str, head: str) -> dict[str, object]:
+        url = f"{self._github_api_url}/repos/{project}/compare/{base}...{head}"
+        headers = {"Accept": "application/vnd.github+json"}
+        if self._github_token:
+            headers["Authorization"] = f"Bearer {self._github_token}"
+        payload, error = self._get_json(url, headers)
+        if payload is None:
+            return {"provider": "github", "repository": project, **(error or {})}
+
+        commits = [
+            {
+                "sha": str(item.get("sha") or "")[:12],
+                "message": _first_line(_nested(item, "commit", "message")),
+                "author": _nested(item, "commit", "author", "name"),
+                "date": _nested(item, "commit", "author", "date"),
+            }
+            for item in _dict_items(payload.get("commits"))
+        ]
+        files = [
+            {
+                "path": item.get("filename"),
+                "status": item.get("status"),
+                "additions": item.get("additions"),
+                "deletions": item.get("deletions"),
+            }
+            for item in _dict_items(payload.get("files"))
+        ]
+        return self._result(
+            "github", project, base, head, commits, files, payload.get("html_url")
+        )
+
+    def _compare_gitlab(self, project: str, base: str, head: str) -> dict[str, object]:
+        project_id = urllib.parse.quote(project, safe="")
+        params = urllib.parse.urlencode({"from": base, "to": head, "straight": "false"})
+        url = f"{self._gitlab_api_url}/projects/{project_id}/repository/compare?{params}"
+        headers = {"Accept": "application/json"}
+        if self._gitlab_token:
+            headers["PRIVATE-TOKEN"] = self._gitlab_token
+        payload, error = self._get_json(url, headers)
+        if payload is None:
+            return {"provider": "gitlab", "repository": project, **(error or {})}
+
+        commits = [
+            {
+                "sha": str(item.get("id") or "")[:12],
+                "message": _first_line(item.get("title") or item.get("message")),
+                "author": item.get("author_name"),
+                "date": item.get("created_at"),
+            }
+            for item in _dict_items(payload.get("commits"))
+        ]
+        files = [
+            {"path": item.get("new_path") or item.get("old_path"), "status": _gitlab_status(item)}
+            for item in _dict_items(payload.get("diffs"))
+        ]
+        return self._result(
+            "gitlab", project, base, head, commits, files, payload.get("web_url")
+        )
+
+    def _result(
+        self,
+        provider: str,
+        project: str,
+        base: str,
+        head: str,
+        commits: list[dict[str, object]],
+        files: list[dict[str, object]],
+        compare_url: object,
+    ) -> dict[str, object]:
+        result: dict[str, object] = {
+            "provider": provider,
+            "repository": project,
+            "base": base,
+            "head": head,
+            "commit_count": len(commits),
+            "commits": commits[-self._max_commits :],
+            "file_count": len(files),
+            "files": files,
+        }
+        if isinstance(compare_url, str) and compare_url:
+            result["compare_url"] = compare_url
+        return result
+
+    def _get_json(
+        self, url: str, headers: dict[str, str]
+    ) -> tuple[dict[str, object] | None, dict[str, object] | None]:
+        payload, error = self._request(url, headers)
+        if error is not None:
+            return None, error
+        if not isinstance(payload, dict):
+            return None, {"error": "unexpected payload type"}
+        return payload, None
+
+    def _get_json_list(
+        self, url: str, headers: dict[str, str]
+    ) -> tuple[list[dict[str, object]] | None, dict[str, object] | None]:
+        payload, error = self._request(url, headers)
+        if error is not None:
+            return None, error
+        if not isinstance(payload, list):
+            return None, {"error": "unexpected payload type"}
+        return _dict_items(payload), None
+
+    def _request(
+        self, url: str, headers: dict[str, str]
+    ) -> tuple[object, dict[str, object] | None]:
+        request = urllib.request.Request(url, headers=headers)
+        try:
+            check_egress(url)
+            with open_url(request, timeout=self._timeout_seconds) as response:
+                payload = json.loads(response.read().decode("utf-8"))
+        except urllib.error.HTTPError as exc:
+            self._logger.warning("Git hosting HTTP error %s for %s", exc.code, url)
+            return None, {"status_code": exc.code, "error": str(exc.reason)}
+        except Exception as exc:  # noqa: BLE001
+            self._logger.warning("Failed to query git hosting API: %s", exc)
+            return None, {"error": str(exc)}
+        return payload, None
+
+
+def _gitlab_status(diff: dict[str, object]) -> str:
+    for flag, status in (
+        ("new_file", "added"),
+        ("deleted_file", "removed"),
+        ("renamed_file", "renamed"),
+    ):
+        if diff.get(flag):
+            return status
+    return "modified"
+
+
+def _job_names(
+    jobs: list[dict[str, object]], field: str, states: set[str] | frozenset[str]
+) -> list[str]:
+    return [str(job.get("name")) for job in jobs if job.get(field) in states]
+
+
+def _str_list(value: object) -> list[str]:
+    return [item for item in value if isinstance(item, str)] if isinstance(value, list) else []
+
+
+def _dict_items(value: object) -> list[dict[str, object]]:
+    if not isinstance(value, list):
+        return []
+    return [item for item in value if isinstance(item, dict)]
+
+
+def _nested(payload: dict[str, object], *keys: str) -> object:
+    value: object = payload
+    for key in keys:
+        if not isinstance(value, dict):
+            return None
+        value = value.get(key)
+    return value
+
+
+def _first_line(value: object) -> str:
+    lines = str(value or "").strip().splitlines()
+    return lines[0] if lines else ""
@@ -1,11 +1,24 @@
 from __future__ import annotations
 
+import base64
+import gzip
+import json
 import logging
-from collections.abc import Iterable
+import re
+import socket
+from collections.abc import Iterable, Iterator, Sequence
+from datetime import datetime, timedelta, timezone
+from typing import Any
 
-from kubernetes import client, config
+from kubernetes import client, config, watch
 from kubernetes.config.config_exception import ConfigException
 
+from app.clients.k8s_api_removals import find_removed_apis, manifest_resources
+from app.core.chaos import wrap_with_faults
+from app.core.deadline import DeadlineExceeded, bounded_timeout
+from app.core.k8s_credentials import wrap_with_credentials
+from app.core.k8s_scope import wrap_with_scope
+from app.core.rbac import ANALYSIS_PERMISSIONS, Permission
 from app.models.k8s import (
     AnalysisTarget,
     K8sContext,
@@ -17,16 +30,51 @@ from app.models.k8s import (
 
 
 class KubernetesClient:
-    def __init__(self, timeout_seconds: int, event_limit: int, log_tail_lines: int) -> None:
+    def __init__(
+        self,
+        timeout_seconds: int,
+        event_limit: int,
+        log_tail_lines: int,
+        *,
+        log_since_seconds: int = 0,
+        helm_release_scan: bool = False,
+    ) -> None:
         self._logger = logging.getLogger(__name__)
         self._timeout_seconds = timeout_seconds
         self._event_limit = event_limit
         self._log_tail_lines = log_tail_lines
-        self._core_api = self._build_client()
-        self._apps_api = client.AppsV1Api() if self._core_api else None
-        self._batch_api = client.BatchV1Api() if self._core_api else None
-        self._custom_api = client.CustomObjectsApi() if self._core_api else None
-        self._events_api = client.EventsV1Api() if self._core_api else None
+        self._log_since_seconds = log_since_seconds
+        self._helm_release_scan = helm_release_scan
+        core_api = self._build_client()
+        self._core_api = _wrap_api(core_api, "")
+        self._apps_api = _wrap_api(client.AppsV1Api() if core_api else None, "apps")
+        self._batch_api = _wrap_api(client.BatchV1Api() if core_api else None, "batch")
+        # Custom object calls carry their group.
+        self._custom_api = _wrap_api(client.CustomObjectsApi() if core_api else None, None)
+        self._events_api = _wrap_api(
+            client.EventsV1Api() if core_api else None, "events.k8s.io"
+        )
+        self._version_api = _wrap_api(client.VersionApi() if core_api else None, None)
+        self._authorization_api = _wrap_api(
+            client.AuthorizationV1Api() if core_api else None, "authorization.k8s.io"
+        )
+        self._storage_api = _wrap_api(
+            client.StorageV1Api() if core_api else None, "storage.k8s.io"
+        )
+        self._autoscaling_api = _wrap_api(
+            client.AutoscalingV2Api() if core_api else None, "autoscaling"
+        )
+        self._networking_api = _wrap_api(
+            client.NetworkingV1Api() if core_api else None, "networking.k8s.io"
+        )
+        self._discovery_api = _wrap_api(
+            client.DiscoveryV1Api() if core_api else None, "discovery.k8s.io"
+        )
+
+    def _call_timeout(self) -> float:
+        # Under a latency SLO no request may outlive the analysis deadline. Once it has
+        # passed this raises DeadlineExceeded, which every handler below re-raises.
+        return bounded_timeout(self._timeout_seconds)
 
     def collect_context(
         self,
@@ -75,6 +123,7 @@ class KubernetesClient:
         current_logs: list[PodLogSnippet] = []
         previous_logs: list[PodLogSnippet] = []
         pod_spec: dict[str, object] | None = None
+        owner_chain: list[dict[str, object]] = []
 
         if pod_name:
             pod = self._read_pod(namespace, pod_name, warnings)
@@ -85,13 +134,20 @@ class KubernetesClient:
                 pod,
                 container=None,
                 tail_lines=None,
-                since_seconds=None,
+                since_seconds=self._log_since_seconds or None,
             )
             previous_logs = self._get_previous_logs(namespace, pod, warnings)
             pod_spec = self._summarize_pod_spec(pod) if pod else None
+            owner_chain = self._owner_chain(namespace, pod, warnings) if pod else []
+            if len(owner_chain) > 1 and not workload:
+                # Analyzers work on the controlling workload when the alert only names a pod.
+                workload = str(owner_chain[-1]["name"] or "") or None
         else:
             hint = self._build_pod_discovery_hint(namespace, workload, service_name)
             warnings.append(f"pod_name missing from alert labels.{hint}")
+            # Without a pod, namespace events (FailedScheduling, BackOff, ...)
+            # are the best signal left for the alerting workload.
+            events = self.list_namespace_events(namespace)
 
         return K8sContext(
             namespace=namespace,
@@ -104,6 +160,7 @@ class KubernetesClient:
             target=target,
             current_logs=current_logs,
             pod_spec=pod_spec,
+            owner_chain=owner_chain,
         )
 
     def get_pod_status(self, namespace: str, pod_name: str) -> PodStatusSnapshot | None:
@@ -149,15 +206,215 @@ class KubernetesClient:
             return None
         return self._summarize_pod_spec(pod)
 
+    def get_pod_images(self, namespace: str, pod_name: str) -> dict[str, object] | None:
+        """List the pod's container images with the digests the kubelet actually pulled.
+
+        Build annotations carried on the pod (``org.opencontainers.image.*``,
+        often copied from the image by the CI pipeline) and the
+        ``app.kubernetes.io/version`` label are included as a registry-free
+        fallback for the code version.
+        """
+        if self._core_api is None:
+            return None
+        pod = self._read_pod(namespace, pod_name, [])
+        if pod is None:
+            return None
+        statuses = (
+            [*(pod.status.init_container_statuses or []), *(pod.status.container_statuses or [])]
+            if pod.status
+            else []
+        )
+        image_ids = {status.name: status.image_id for status in statuses}
+        containers = [
+            {
+                "name": container.name,
+                "init": init,
+                "image": container.image,
+                "image_id": image_ids.get(container.name) or None,
+            }
+            for init, specs in ((True, pod.spec.init_containers), (False, pod.spec.containers))
+            for container in specs or []
+        ]
+        annotations = pod.metadata.annotations or {} if pod.metadata else {}
+        labels = pod.metadata.labels or {} if pod.metadata else {}
+        build = {
+            key: value
+            for key, value in annotations.items()
+            if key.startswith(_BUILD_ANNOTATION_PREFIXES)
+        }
+        if labels.get("app.kubernetes.io/version"):
+            build["app.kubernetes.io/version"] = labels["app.kubernetes.io/version"]
+        return {"namespace": namespace, "pod": pod_name, "containers": containers, "build": build}
+
+    def get_pod_deployment(self, namespace: str, pod_name: str) -> str | None:
+        """Name of the Deployment that owns a pod (through its ReplicaSet), if any."""
+        if self._apps_api is None:
+            return None
+        pod = self._read_pod(namespace, pod_name, [])
+        if pod is None or pod.metadata is None:
+            return None
+        owner_ref = self._select_owner_reference(pod.metadata.owner_references or [])
+        if owner_ref is None:
+            return None
+        owner_ref = self._resolve_owner_reference(namespace, owner_ref)
+        return owner_ref.name if owner_ref.kind == "Deployment" else None
+
+    def get_owner_chain(self, namespace: str, pod_name: str) -> list[dict[str, object]] | None:
+        """The pod and its controllers up to the top-level workload, pod first."""
+        if self._core_api is None:
+            return None
+        pod = self._read_pod(namespace, pod_name, [])
+        if pod is None:
+            return None
+        return self._owner_chain(namespace, pod, [])
+
+    def _owner_chain(
+        self, namespace: str, pod: client.V1Pod, warnings: list[str]
+    ) -> list[dict[str, object]]:
+        """Follow controller ownerReferences: Pod -> ReplicaSet -> Deployment, Rollout, ..."""
+        metadata = pod.metadata
+        chain: list[dict[str, object]] = [
+            {
+                "kind": "Pod",
+                "name": getattr(metadata, "name", None),
+                "api_version": "v1",
+                "uid": getattr(metadata, "uid", None),
+                "controller": False,
+            }
+        ]
+        owners = [
+            _owner_reference_dict(item)
+            for item in getattr(metadata, "owner_references", None) or []
+        ]
+        while len(chain) <= _OWNER_CHAIN_DEPTH:
+            owner = next((item for item in owners if item["controller"]), None) or (
+                owners[0] if owners else None
+            )
+            if owner is None or any(
+                (link["kind"], link["name"]) == (owner["kind"], owner["name"]) for link in chain
+            ):
+                break
+            chain.append(owner)
+            owners_of_owner = self._read_owner_references(namespace, owner)
+            if owners_of_owner is None:
+                warnings.append(
+                    f"failed to read {str(owner['kind']).lower()} {namespace}/{owner['name']}"
+                )
+                break
+            owners = owners_of_owner
+        return chain
+
+    def _read_owner_references(
+        self, namespace: str, owner: dict[str, object]
+    ) -> list[dict[str, object]] | None:
+        """ownerReferences of a pod's owner; None when it cannot be read."""
+        kind, name = str(owner["kind"]), str(owner["name"])
+        api_version = str(owner.get("api_version") or "")
+        if kind == "Node" or "/" not in api_version:
+            # Mirror pods of static pods are owned by their Node; core kinds end the chain.
+            return []
+        api: Any = None
+        method: str | None = None
+        if kind in _APPS_OWNER_READERS and api_version.startswith("apps/"):
+            api, method = self._apps_api, _APPS_OWNER_READERS[kind]
+        elif kind in _BATCH_OWNER_READERS and api_version.startswith("batch/"):
+            api, method = self._batch_api, _BATCH_OWNER_READERS[kind]
+        if method is not None:
+            if api is None:
+                return None
+            try:
+                resource = getattr(api, method)(
+                    name=name, namespace=namespace, _request_timeout=self._call_timeout()
+                )
+            except DeadlineExceeded:
+                raise
+            except Exception as exc:  # noqa: BLE001
+                self._logger.warning("Failed to read %s %s/%s: %s", kind, namespace, name, exc)
+                return None
+            return [
+                _owner_reference_dict(item)
+                for item in getattr(resource.metadata, "owner_references", None) or []
+            ]
+        # Argo Rollouts and operator-managed workloads are custom resources.
+        payload = self._read_object_ref(
+            {"apiVersion": api_version, "kind": kind, "name": name}, namespace=namespace
+        )
+        if payload is None:
+            return None
+        metadata = payload.get("metadata")
+        references = metadata.get("ownerReferences") if isinstance(metadata, dict) else None
+        return [_owner_reference_dict(item) for item in references or [] if isinstance(item, dict)]
+
+    def get_deployment_revisions(
+        self, namespace: str, deployment: str, *, limit: int = 2
+    ) -> list[dict[str, object]] | None:
+        """Latest ReplicaSet revisions of a Deployment, newest first, with their images.
+
+        Each revision carries the pod template's container images and its
+        ``org.opencontainers.image.*`` annotations, so the deployed code
+        version before and after a rollout can be compared.
+        """
+        if self._apps_api is None:
+            return None
+        try:
+            replica_sets = self._apps_api.list_namespaced_replica_set(
+                namespace=namespace, _request_timeout=self._call_timeout()
+            ).items
+        except DeadlineExceeded:
+            raise
+        except Exception as exc:  # noqa: BLE001
+            self._logger.warning("Failed to list ReplicaSets in %s: %s", namespace, exc)
+            return None
+
+        revisions: list[tuple[int, dict[str, object]]] = []
+        for replica_set in replica_sets:
+            metadata = replica_set.metadata
+            owners = metadata.owner_references or [] if metadata else []
+            if not any(
+                owner.kind == "Deployment" and owner.name == deployment for owner in owners
+            ):
+                continue
+            annotations = metadata.annotations or {}
+            try:
+                revision = int(annotations.get("deployment.kubernetes.io/revision", ""))
+            except ValueError:
+                continue
+            template = replica_set.spec.template if replica_set.spec else None
+            template_annotations = (
+                template.metadata.annotations or {} if template and template.metadata else {}
+            )
+            revisions.append(
+                (
+                    revision,
+                    {
+                        "revision": revision,
+                        "replica_set": metadata.name,
+                        "created": self._to_iso(metadata.creation_timestamp),
+                        "containers": [
+                            {"name": container.name, "image": container.image}
+                            for container in (template.spec.containers if template else None)
+                            or []
+                        ],
+                        "build": {
+                            key: value
+                            for key, value in template_annotations.items()
+                            if key.startswith(_BUILD_ANNOTATION_PREFIXES)
+                        },
+                    },
+                )
+            )
+        revisions.sort(key=lambda item: item[0], reverse=True)
+        return [item for _, item in revisions[: max(1, limit)]]
+
     def list_namespace_events(self, namespace: str) -> list[PodEventSummary]:
         if self._core_api is None and self._events_api is None:
             return []
         v1_events, v1_error = self._list_namespace_events_v1(namespace)
         if v1_events:
-            return v1_events
+            return _prioritize_events(v1_events)
         core_events, core_error = self._list_namespace_events_core(namespace)
         if core_events:
-            return core_events
+            return _prioritize_events(core_events)
         return self._event_warnings([v1_error, core_error])
 
     def list_cluster_events(self) -> list[PodEventSummary]:
@@ -165,12 +422,34 @@ class KubernetesClient:
             return []
         v1_events, v1_error = self._list_cluster_events_v1()
         if v1_events:
-            return v1_events
+            return _prioritize_events(v1_events)
         core_events, core_error = self._list_cluster_events_core()
         if core_events:
-            return core_events
+            return _prioritize_events(core_events)
         return self._event_warnings([v1_error, core_error])
 
+    def watch_cluster_events(self, timeout_seconds: int) -> Iterator[tuple[str, PodEventSummary]]:
+        """Core Events added or updated anywhere in the cluster, until *timeout_seconds* pass.
+
+        Yields each event's uid with its summary. A new watch starts with the
+        events the API server still holds, so reconnecting re-delivers them.
+        """
+        if self._core_api is None:
+            return
+        stream = watch.Watch().stream(
+            self._core_api.list_event_for_all_namespaces,
+            timeout_seconds=timeout_seconds,
+            _request_timeout=timeout_seconds + self._timeout_seconds,
+        )
+        for change in stream:
+            if change.get("type") not in ("ADDED", "MODIFIED"):
+                continue
+            event = change.get("object")
+            uid = getattr(getattr(event, "metadata", None), "uid", None)
+            if not uid:
+                continue
+            yield uid, self._to_event_summary(event)
+
     def get_pod_logs(
         self,
         namespace: str,
@@ -208,8 +487,10 @@ class KubernetesClient:
                 deployment = self._apps_api.read_namespaced_deployment(
                     name=owner_ref.name,
                     namespace=namespace,
-                    _request_timeout=self._timeout_seconds,
+                    _request_timeout=self._call_timeout(),
                 )
+            except DeadlineExceeded:
+                raise
             except Exception as exc:  # noqa: BLE001
                 self._logger.warning(
                     "Failed to read Deployment %s/%s: %s", namespace, owner_ref.name, exc
@@ -221,8 +502,10 @@ class KubernetesClient:
                 replica_set = self._apps_api.read_namespaced_replica_set(
                     name=owner_ref.name,
                     namespace=namespace,
-                    _request_timeout=self._timeout_seconds,
+                    _request_timeout=self._call_timeout(),
                 )
+            except DeadlineExceeded:
+                raise
             except Exception as exc:  # noqa: BLE001
                 self._logger.warning(
                     "Failed to read ReplicaSet %s/%s: %s", namespace, owner_ref.name, exc
@@ -234,8 +517,10 @@ class KubernetesClient:
                 stateful_set = self._apps_api.read_namespaced_stateful_set(
                     name=owner_ref.name,
                     namespace=namespace,
-                    _request_timeout=self._timeout_seconds,
+                    _request_timeout=self._call_timeout(),
                 )
+            except DeadlineExceeded:
+                raise
             except Exception as exc:  # noqa: BLE001
                 self._logger.warning(
                     "Failed to read StatefulSet %s/%s: %s", namespace, owner_ref.name, exc
@@ -247,8 +532,10 @@ class KubernetesClient:
                 daemon_set = self._apps_api.read_namespaced_daemon_set(
                     name=owner_ref.name,
                     namespace=namespace,
-                    _request_timeout=self._timeout_seconds,
+                    _request_timeout=self._call_timeout(),
                 )
+            except DeadlineExceeded:
+                raise
             except Exception as exc:  # noqa: BLE001
                 self._logger.warning(
                     "Failed to read DaemonSet %s/%s: %s", namespace, owner_ref.name, exc
@@ -260,8 +547,10 @@ class KubernetesClient:
                 job = self._batch_api.read_namespaced_job(
                     name=owner_ref.name,
                     namespace=namespace,
-                    _request_timeout=self._timeout_seconds,
+                    _request_timeout=self._call_timeout(),
                 )
+            except DeadlineExceeded:
+                raise
             except Exception as exc:  # noqa: BLE001
                 self._logger.warning("Failed to read Job %s/%s: %s", namespace, owner_ref.name, exc)
                 return None
@@ -271,8 +560,10 @@ class KubernetesClient:
                 cron_job = self._batch_api.read_namespaced_cron_job(
                     name=owner_ref.name,
                     namespace=namespace,
-                    _request_timeout=self._timeout_seconds,
+                    _request_timeout=self._call_timeout(),
                 )
+            except DeadlineExceeded:
+                raise
             except Exception as exc:  # noqa: BLE001
                 self._logger.warning(
                     "Failed to read CronJob %s/%s: %s", namespace, owner_ref.name, exc
@@ -289,8 +580,10 @@ class KubernetesClient:
             daemon_set = self._apps_api.read_namespaced_daemon_set(
                 name=name,
                 namespace=namespace,
-                _request_timeout=self._timeout_seconds,
+                _request_timeout=self._call_timeout(),
             )
+        except DeadlineExceeded:
+            raise
         except Exception as exc:  # noqa: BLE001
             self._logger.warning("Failed to read DaemonSet %s/%s: %s", namespace, name, exc)
             return None
@@ -308,8 +601,10 @@ class KubernetesClient:
         try:
             node = self._core_api.read_node(
                 name=node_name,
-                _request_timeout=self._timeout_seconds,
+                _request_timeout=self._call_timeout(),
             )
+        except DeadlineExceeded:
+            raise
         except Exception as exc:  # noqa: BLE001
             self._logger.warning("Failed to read node %s: %s", node_name, exc)
             return None
@@ -338,6 +633,257 @@ class KubernetesClient:
             "conditions": conditions,
         }
 
+    def get_cluster_upgrade_status(self, lookback_minutes: int = 180) -> dict[str, object]:
+        """Detect in-progress or recent control-plane/node upgrades.
+
+        Reports kubelet version skew against the API server, cordoned
+        (draining) nodes, nodes created within the lookback window (surge or
+        replacement nodes) and recent node lifecycle events, so disruption
+        alerts can be attributed to an upgrade when the timing matches.
+        """
+        if self._core_api is None:
+            return {"error": "kubernetes client is not configured"}
+        window_start = datetime.now(timezone.utc) - timedelta(minutes=max(0, lookback_minutes))
+        control_plane_version = self._read_server_version()
+        try:
+            nodes = self._core_api.list_node(_request_timeout=self._call_timeout()).items
+        except DeadlineExceeded:
+            raise
+        except Exception as exc:  # noqa: BLE001
+            self._logger.warning("Failed to list nodes: %s", exc)
+            return {"error": f"node list failed: {exc}"}
+
+        control_plane_minor = _minor_version(control_plane_version)
+        version_counts: dict[str, int] = {}
+        skewed: list[dict[str, object]] = []
+        cordoned: list[dict[str, object]] = []
+        recent: list[dict[str, object]] = []
+        for node in nodes:
+            name = node.metadata.name if node.metadata else None
+            node_info = node.status.node_info if node.status else None
+            kubelet_version = node_info.kubelet_version if node_info else None
+            version_counts[str(kubelet_version)] = version_counts.get(str(kubelet_version), 0) + 1
+            kubelet_minor = _minor_version(kubelet_version)
+            if control_plane_minor is not None and kubelet_minor is not None:
+                skew = control_plane_minor - kubelet_minor
+                if skew < 0 or skew > _MAX_KUBELET_MINOR_SKEW:
+                    skewed.append(
+                        {"node": name, "kubelet_version": kubelet_version, "minor_skew": skew}
+                    )
+            if node.spec and node.spec.unschedulable:
+                cordoned.append(
+                    {
+                        "node": name,
+                        "kubelet_version": kubelet_version,
+                        "taints": [taint.key for taint in node.spec.taints or []],
+                    }
+                )
+            created = node.metadata.creation_timestamp if node.metadata else None
+            if isinstance(created, datetime) and created >= window_start:
+                recent.append(
+                    {
+                        "node": name,
+                        "created": self._to_iso(created),
+                        "kubelet_version": kubelet_version,
+                    }
+                )
+
+        node_events = self._list_node_lifecycle_events(window_start)
+        findings: list[str] = []
+        if len(version_counts) > 1:
+            versions = ", ".join(f"{version} x{count}" for version, count in version_counts.items())
+            findings.append(f"nodes run mixed kubelet versions ({versions}): rolling node upgrade")
+        if cordoned:
+            findings.append(f"{len(cordoned)} node(s) cordoned: drain in progress")
+        if recent:
+            findings.append(
+                f"{len(recent)} node(s) created in the last {lookback_minutes} minutes: "
+                "surge or replacement nodes"
+            )
+        for item in skewed:
+            direction = "newer than" if int(item["minor_skew"]) < 0 else "too far behind"
+            findings.append(
+                f"kubelet {item['kubelet_version']} on {item['node']} is {direction} "
+                f"the API server {control_plane_version} (unsupported skew)"
+            )
+
+        return {
+            "control_plane_version": control_plane_version,
+            "node_count": len(nodes),
+            "kubelet_versions": version_counts,
+            "version_skew": skewed,
+            "cordoned_nodes": cordoned,
+            "recently_created_nodes": recent,
+            "node_events": node_events,
+            "upgrade_in_progress": len(version_counts) > 1 or bool(cordoned),
+            "findings": findings,
+        }
+
+    def get_node_maintenance_status(self, node_name: str) -> dict[str, object] | None:
+        """Report planned-maintenance signals for a node.
+
+        Signals are the cordon flag, drain/termination taints set by the
+        cluster autoscaler, Karpenter and the cloud termination handlers, the
+        AKS ``VMEventScheduled`` condition, and the deletion of the Cluster API
+        Machine backing the node. Returns None when the node cannot be read.
+        """
+        if self._core_api is None:
+            return None
+        try:
+            node = self._core_api.read_node(name=node_name, _request_timeout=self._call_timeout())
+        except DeadlineExceeded:
+            raise
+        except Exception as exc:  # noqa: BLE001
+            self._logger.warning("Failed to read node %s: %s", node_name, exc)
+            return None
+
+        signals: list[str] = []
+        cordoned = bool(node.spec and node.spec.unschedulable)
+        if cordoned:
+            signals.append("node is cordoned (unschedulable)")
+        for taint in (node.spec.taints if node.spec else None) or []:
+            reason = _MAINTENANCE_TAINTS.get(taint.key)
+            if reason:
+                signals.append(f"taint {taint.key}: {reason}")
+        for condition in (node.status.conditions if node.status else None) or []:
+            if condition.type in _MAINTENANCE_CONDITIONS and condition.status == "True":
+                detail = f" ({condition.message})" if condition.message else ""
+                signals.append(f"condition {condition.type}: cloud maintenance scheduled{detail}")
+
+        annotations = (node.metadata.annotations if node.metadata else None) or {}
+        machine: dict[str, object] | None = None
+        machine_name = annotations.get("cluster.x-k8s.io/machine")
+        if machine_name:
+            machine_namespace = annotations.get("cluster.x-k8s.io/cluster-namespace") or "default"
+            obj = self._read_custom_object(
+                "cluster.x-k8s.io",
+                "v1beta1",
+                "machines",
+                machine_name,
+                namespace=machine_namespace,
+            )
+            if obj is not None:
+                metadata = obj.get("metadata") if isinstance(obj.get("metadata"), dict) else {}
+                status = obj.get("status") if isinstance(obj.get("status"), dict) else {}
+                machine = {
+                    "name": machine_name,
+                    "namespace": machine_namespace,
+                    "phase": status.get("phase"),
+                    "deletion_timestamp": metadata.get("deletionTimestamp"),
+                }
+                if machine["deletion_timestamp"] or machine["phase"] == "Deleting":
+                    signals.append(f"Cluster API machine {machine_name} is being deleted")
+
+        return {
+            "node": node_name,
+            "in_maintenance": bool(signals),
+            "cordoned": cordoned,
+            "signals": signals,
+            "machine": machine,
+        }
+
+    def find_removed_api_usage(self, namespace: str) -> dict[str, object]:
+        """Find deprecated or removed API versions used by a namespace's Helm releases.
+
+        Deployed Helm release manifests are checked against known API removals
+        for the cluster version, and namespace events are scanned for the
+        "no matches for kind" errors that removed APIs produce. Helm keeps
+        releases in Secrets, and RBAC cannot limit ``list secrets`` to them, so
+        the manifests are only read with ``helm_release_scan``.
+        """
+        if self._core_api is None:
+            return {"error": "kubernetes client is not configured"}
+        cluster_version = self._read_server_version()
+        cluster_minor = _minor_version(cluster_version)
+
+        releases: list[dict[str, object]] = []
+        removed_in_use = False
+        secrets: list[client.V1Secret] = []
+        if self._helm_release_scan:
+            try:
+                secrets = self._core_api.list_namespaced_secret(
+                    namespace=namespace,
+                    label_selector="owner=helm,status=deployed",
+                    _request_timeout=self._call_timeout(),
+                ).items
+            except DeadlineExceeded:
+                raise
+            except Exception as exc:  # noqa: BLE001
+                self._logger.warning("Failed to list Helm releases in %s: %s", namespace, exc)
+        for secret in secrets:
+            release = _decode_helm_release((secret.data or {}).get("release"))
+            if release is None:
+                continue
+            manifest = release.get("manifest")
+            matches = find_removed_apis(
+                manifest_resources(manifest if isinstance(manifest, str) else ""), cluster_minor
+            )
+            if matches:
+                removed_in_use |= any(match["status"] == "removed" for match in matches)
+                chart = release.get("chart")
+                chart_metadata = chart.get("metadata") if isinstance(chart, dict) else None
+                releases.append(
+                    {
+                        "release": release.get("name"),
+                        "revision": release.get("version"),
+                        "chart": (
+                            f"{chart_metadata.get('name')}-{chart_metadata.get('version')}"
+                            if isinstance(chart_metadata, dict)
+                            else None
+                        ),
+                        "apis": matches,
+                    }
+                )
+
+        api_errors = [
+            event.to_dict()
+            for event in self.list_namespace_events(namespace)
+            if any(marker in (event.message or "") for marker in _REMOVED_API_EVENT_MARKERS)
+        ]
+        return {
+            "cluster_version": cluster_version,
+            "helm_releases": releases if self._helm_release_scan else None,
+            "api_error_events": api_errors,
+            "removed_in_use": removed_in_use,
+        }
+
+    def warm_up(self) -> dict[str, object]:
+        """Open the API server connection ahead of the first analysis."""
+        return {"server_version": self._read_server_version()}
+
+    def _read_server_version(self) -> str | None:
+        if self._version_api is None:
+            return None
+        try:
+            return self._version_api.get_code(_request_timeout=self._call_timeout()).git_version
+        except DeadlineExceeded:
+            raise
+        except Exception as exc:  # noqa: BLE001
+            self._logger.warning("Failed to read API server version: %s", exc)
+            return None
+
+    def _list_node_lifecycle_events(self, window_start: datetime) -> list[dict[str, object]]:
+        try:
+            response = self._core_api.list_event_for_all_namespaces(
+                field_selector="involvedObject.kind=Node",
+                limit=_NODE_EVENT_LIMIT,
+                _request_timeout=self._call_timeout(),
+            )
+        except DeadlineExceeded:
+            raise
+        except Exception as exc:  # noqa: BLE001
+            self._logger.warning("Failed to list node events: %s", exc)
+            return []
+        events: list[dict[str, object]] = []
+        for item in response.items:
+            if item.reason not in _NODE_LIFECYCLE_REASONS:
+                continue
+            timestamp = item.last_timestamp or item.event_time or item.first_timestamp
+            if isinstance(timestamp, datetime) and timestamp < window_start:
+                continue
+            events.append(self._to_event_summary(item).to_dict())
+        return events
+
     def get_pod_metrics(self, namespace: str, pod_name: str) -> dict[str, object] | None:
         if self._custom_api is None:
             return None
@@ -348,8 +894,10 @@ class KubernetesClient:
                 namespace=namespace,
                 plural="pods",
                 name=pod_name,
-                _request_timeout=self._timeout_seconds,
+                _request_timeout=self._call_timeout(),
             )
+        except DeadlineExceeded:
+            raise
         except Exception as exc:  # noqa: BLE001
             self._logger.warning(
                 "Failed to read pod metrics for %s/%s: %s",
@@ -357,126 +905,1732 @@ class KubernetesClient:
                 pod_name,
                 exc,
             )
-            return None
-        return self._summarize_pod_metrics(response)
+            return None
+        return self._summarize_pod_metrics(response)
+
+    def get_node_metrics(self, node_name: str) -> dict[str, object] | None:
+        if self._custom_api is None:
+            return None
+        try:
+            response = self._custom_api.get_cluster_custom_object(
+                group="metrics.k8s.io",
+                version="v1beta1",
+                plural="nodes",
+                name=node_name,
+                _request_timeout=self._call_timeout(),
+            )
+        except DeadlineExceeded:
+            raise
+        except Exception as exc:  # noqa: BLE001
+            self._logger.warning("Failed to read node metrics for %s: %s", node_name, exc)
+            return None
+        return self._summarize_node_metrics(response)
+
+    def get_node_resource_usage(self, node_name: str) -> dict[str, object] | None:
+        """Node usage from metrics.k8s.io with the node's allocatable CPU and memory."""
+        metrics = self.get_node_metrics(node_name)
+        if metrics is None:
+            return None
+        allocatable: dict[str, object] | None = None
+        if self._core_api is not None:
+            try:
+                node = self._core_api.read_node(
+                    name=node_name, _request_timeout=self._call_timeout()
+                )
+            except DeadlineExceeded:
+                raise
+            except Exception as exc:  # noqa: BLE001
+                self._logger.warning("Failed to read node %s: %s", node_name, exc)
+            else:
+                allocatable = node.status.allocatable if node.status else None
+        return {**metrics, "allocatable": allocatable}
+
+    def get_hpa_status(
+        self,
+        namespace: str,
+        *,
+        workload: str | None = None,
+        pod_name: str | None = None,
+    ) -> dict[str, object] | None:
+        """HorizontalPodAutoscaler scaling *workload* or the pod's owner, with its events."""
+        if self._autoscaling_api is None:
+            return None
+        try:
+            response = self._autoscaling_api.list_namespaced_horizontal_pod_autoscaler(
+                namespace=namespace,
+                _request_timeout=self._call_timeout(),
+            )
+        except DeadlineExceeded:
+            raise
+        except Exception as exc:  # noqa: BLE001
+            self._logger.warning("Failed to list HPAs in %s: %s", namespace, exc)
+            return None
+        autoscalers = list(response.items or [])
+        if not autoscalers:
+            return None
+        targets = {workload} if workload else set()
+        pod = self._read_pod(namespace, pod_name, []) if pod_name else None
+        if pod is not None and pod.metadata is not None:
+            owner_ref = self._select_owner_reference(pod.metadata.owner_references or [])
+            if owner_ref is not None:
+                targets.add(self._resolve_owner_reference(namespace, owner_ref).name)
+        for autoscaler in autoscalers:
+            target_ref = autoscaler.spec.scale_target_ref if autoscaler.spec else None
+            if target_ref is not None and target_ref.name in targets:
+                return self._summarize_hpa(namespace, autoscaler)
+        return None
+
+    def get_volume_claim_status(
+        self, namespace: str, claim_name: str
+    ) -> dict[str, object] | None:
+        """PVC binding with its PersistentVolume, StorageClass, attachments and events."""
+        if self._core_api is None:
+            return None
+        try:
+            claim = self._core_api.read_namespaced_persistent_volume_claim(
+                name=claim_name,
+                namespace=namespace,
+                _request_timeout=self._call_timeout(),
+            )
+        except DeadlineExceeded:
+            raise
+        except Exception as exc:  # noqa: BLE001
+            self._logger.warning(
+                "Failed to read persistentvolumeclaim %s/%s: %s", namespace, claim_name, exc
+            )
+            return None
+        spec = claim.spec
+        status = claim.status
+        annotations = (claim.metadata.annotations if claim.metadata else None) or {}
+        requests = (spec.resources.requests if spec and spec.resources else None) or {}
+        volume_name = spec.volume_name if spec else None
+        storage_class_name = spec.storage_class_name if spec else None
+        return {
+            "name": claim_name,
+            "namespace": namespace,
+            "phase": status.phase if status else None,
+            "storage_class": storage_class_name,
+            "access_modes": list(spec.access_modes or []) if spec else [],
+            "volume_mode": spec.volume_mode if spec else None,
+            "requested": requests.get("storage"),
+            "capacity": ((status.capacity if status else None) or {}).get("storage"),
+            "volume_name": volume_name,
+            "selected_node": annotations.get("volume.kubernetes.io/selected-node"),
+            "conditions": [
+                {
+                    "type": condition.type,
+                    "status": condition.status,
+                    "reason": condition.reason,
+                    "message": condition.message,
+                }
+                for condition in (status.conditions if status else None) or []
+            ],
+            "persistent_volume": self._read_persistent_volume(volume_name),
+            "storage_class_detail": self._read_storage_class(storage_class_name),
+            "attachments": self._list_volume_attachments(volume_name),
+            "events": [
+                event.to_dict()
+                for event in self._list_pod_events(
+                    namespace, claim_name, [], kind="PersistentVolumeClaim"
+                )
+            ],
+        }
+
+    def get_volume_stats(self, node_name: str) -> list[dict[str, object]] | None:
+        """Per-PVC filesystem usage from the kubelet stats summary of *node_name*."""
+        if self._core_api is None:
+            return None
+        try:
+            response = self._core_api.connect_get_node_proxy_with_path(
+                name=node_name,
+                path="stats/summary",
+                _preload_content=False,
+                _request_timeout=self._call_timeout(),
+            )
+            payload = json.loads(response.data)
+        except DeadlineExceeded:
+            raise
+        except Exception as exc:  # noqa: BLE001
+            self._logger.warning("Failed to read kubelet stats of node %s: %s", node_name, exc)
+            return None
+        stats: list[dict[str, object]] = []
+        for pod in payload.get("pods") or []:
+            for volume in pod.get("volume") or []:
+                ref = volume.get("pvcRef")
+                if not isinstance(ref, dict):
+                    continue
+                stats.append(
+                    {
+                        "namespace": ref.get("namespace"),
+                        "claim": ref.get("name"),
+                        "node": node_name,
+                        "used_bytes": volume.get("usedBytes"),
+                        "capacity_bytes": volume.get("capacityBytes"),
+                        "available_bytes": volume.get("availableBytes"),
+                        "inodes_used": volume.get("inodesUsed"),
+                        "inodes": volume.get("inodes"),
+                    }
+                )
+        return stats
+
+    def get_network_connectivity(
+        self,
+        namespace: str,
+        pod_name: str,
+        *,
+        destination_namespace: str,
+        destination_service: str,
+    ) -> dict[str, object] | None:
+        """NetworkPolicies, pod and namespace labels on both ends of a pod-to-service path."""
+        if self._networking_api is None or self._core_api is None:
+            return None
+        source_policies = self._list_network_policies(namespace)
+        if source_policies is None:
+            return None
+        if destination_namespace == namespace:
+            destination_policies: list[dict[str, object]] | None = source_policies
+        else:
+            destination_policies = self._list_network_policies(destination_namespace)
+        pod = self._read_pod(namespace, pod_name, [])
+        return {
+            "source": {
+                "namespace": namespace,
+                "pod": pod_name,
+                "labels": dict(pod.metadata.labels or {}) if pod and pod.metadata else None,
+                "namespace_labels": self._read_namespace_labels(namespace),
+                "policies": source_policies,
+            },
+            "destination": {
+                "namespace": destination_namespace,
+                **self._read_service_selector(destination_namespace, destination_service),
+                "namespace_labels": self._read_namespace_labels(destination_namespace),
+                "policies": destination_policies,
+            },
+        }
+
+    def _list_network_policies(self, namespace: str) -> list[dict[str, object]] | None:
+        try:
+            response = self._networking_api.list_namespaced_network_policy(
+                namespace=namespace,
+                _request_timeout=self._call_timeout(),
+            )
+        except DeadlineExceeded:
+            raise
+        except Exception as exc:  # noqa: BLE001
+            self._logger.warning("Failed to list networkpolicies in %s: %s", namespace, exc)
+            return None
+        policies: list[dict[str, object]] = []
+        for item in response.items or []:
+            spec = item.spec
+            if spec is None:
+                continue
+            ingress = [
+                {
+                    "from": [_summarize_policy_peer(peer) for peer in rule._from or []],
+                    "ports": [_summarize_policy_port(port) for port in rule.ports or []],
+                }
+                for rule in spec.ingress or []
+            ]
+            egress = [
+                {
+                    "to": [_summarize_policy_peer(peer) for peer in rule.to or []],
+                    "ports": [_summarize_policy_port(port) for port in rule.ports or []],
+                }
+                for rule in spec.egress or []
+            ]
+            # Without policyTypes a policy always restricts ingress, and egress when it
+            # has egress rules.
+            policy_types = list(spec.policy_types or []) or (
+                ["Ingress", "Egress"] if spec.egress else ["Ingress"]
+            )
+            policies.append(
+                {
+                    "name": item.metadata.name if item.metadata else None,
+                    "namespace": namespace,
+                    "pod_selector": _summarize_label_selector(spec.pod_selector) or {},
+                    "policy_types": policy_types,
+                    "ingress": ingress,
+                    "egress": egress,
+                }
+            )
+        return policies
+
+    def _read_namespace_labels(self, namespace: str) -> dict[str, str]:
+        # Since 1.22 every namespace carries kubernetes.io/metadata.name, even unreadable ones.
+        labels = {"kubernetes.io/metadata.name": namespace}
+        try:
+            item = self._core_api.read_namespace(
+                name=namespace, _request_timeout=self._call_timeout()
+            )
+        except DeadlineExceeded:
+            raise
+        except Exception as exc:  # noqa: BLE001
+            self._logger.warning("Failed to read namespace %s: %s", namespace, exc)
+            return labels
+        return {**labels, **((item.metadata.labels if item.metadata else None) or {})}
+
+    def _read_service_selector(self, namespace: str, name: str) -> dict[str, object]:
+        try:
+            service = self._core_api.read_namespaced_service(
+                name=name, namespace=namespace, _request_timeout=self._call_timeout()
+            )
+        except DeadlineExceeded:
+            raise
+        except Exception as exc:  # noqa: BLE001
+            self._logger.warning("Failed to read service %s/%s: %s", namespace, name, exc)
+            return {"service": name, "found": False, "selector": None, "ports": []}
+        spec = service.spec
+        return {
+            "service": name,
+            "found": True,
+            "selector": dict(spec.selector or {}) if spec else None,
+            "ports": [
+                {
+                    "name": port.name,
+                    "port": port.port,
+                    "target_port": port.target_port or port.port,
+                    "protocol": port.protocol or "TCP",
+                }
+                for port in (spec.ports if spec else None) or []
+            ],
+        }
+
+    def get_service_endpoints(self, namespace: str, service: str) -> dict[str, object] | None:
+        """EndpointSlices of *service* and the readiness of the pods its selector matches.
+
+        When the selector matches no pod, ``near_misses`` lists pods carrying the
+        selector's keys with other values. Not-ready pods get their latest
+        readiness probe failures from events.
+        """
+        if self._core_api is None or self._discovery_api is None:
+            return None
+        summary: dict[str, object] = {
+            "namespace": namespace,
+            **self._read_service_selector(namespace, service),
+            "endpoints": [],
+            "pods": [],
+            "near_misses": [],
+        }
+        if not summary["found"]:
+            return summary
+        try:
+            response = self._discovery_api.list_namespaced_endpoint_slice(
+                namespace=namespace,
+                label_selector=f"kubernetes.io/service-name={service}",
+                _request_timeout=self._call_timeout(),
+            )
+        except DeadlineExceeded:
+            raise
+        except Exception as exc:  # noqa: BLE001
+            self._logger.warning(
+                "Failed to list endpointslices of %s/%s: %s", namespace, service, exc
+            )
+            return None
+        summary["endpoints"] = [
+            _summarize_endpoint(endpoint)
+            for item in response.items or []
+            for endpoint in item.endpoints or []
+        ]
+        selector = summary.get("selector")
+        if not isinstance(selector, dict) or not selector:
+            return summary
+        pods = self._list_selected_pods(
+            namespace, ",".join(f"{key}={value}" for key, value in selector.items())
+        )
+        summary["pods"] = [self._summarize_pod_readiness(pod) for pod in pods[:_ENDPOINT_POD_LIMIT]]
+        for pod in [item for item in summary["pods"] if not item["ready"]][:_PROBE_EVENT_PODS]:
+            events = self._list_pod_events(namespace, str(pod["name"]), [])
+            pod["probe_failures"] = [
+                event.message
+                for event in events
+                if event.reason == "Unhealthy" and (event.message or "").startswith("Readiness")
+            ][:3]
+        if not pods:
+            candidates = self._list_selected_pods(namespace, ",".join(selector))
+            summary["near_misses"] = [
+                {
+                    "name": pod.metadata.name,
+                    "mismatched": {
+                        key: (pod.metadata.labels or {}).get(key)
+                        for key, value in selector.items()
+                        if (pod.metadata.labels or {}).get(key) != value
+                    },
+                }
+                for pod in candidates[:_NEAR_MISS_LIMIT]
+                if pod.metadata
+            ]
+        return summary
+
+    def get_cluster_dns_status(
+        self, namespace: str, service: str, *, since_seconds: int
+    ) -> dict[str, object] | None:
+        """Endpoints of the cluster DNS Service, its pods and their recent log errors.
+
+        The last *since_seconds* of log of the first DNS pods are scanned for
+        SERVFAIL answers and ``[ERROR]`` lines of the ``errors`` plugin; upstream
+        (``forward`` plugin) timeouts are counted separately.
+        """
+        summary = self.get_service_endpoints(namespace, service)
+        if summary is None or not summary.get("found"):
+            return summary
+        pods = summary.get("pods")
+        summary["logs"] = [
+            self._scan_dns_log(namespace, pod, since_seconds)
+            for pod in (pods if isinstance(pods, list) else [])[:_DNS_LOG_PODS]
+        ]
+        return summary
+
+    def _scan_dns_log(
+        self, namespace: str, pod: dict[str, Any], since_seconds: int
+    ) -> dict[str, object]:
+        names = [str(container.get("name")) for container in pod.get("containers") or []]
+        container = next((name for name in names if name in _DNS_CONTAINERS), None)
+        container = container or (names[0] if names else None)
+        result: dict[str, object] = {"pod": pod.get("name"), "container": container}
+        kwargs: dict[str, Any] = {}
+        if container:
+            kwargs["container"] = container
+        try:
+            logs = self._core_api.read_namespaced_pod_log(
+                name=pod.get("name"),
+                namespace=namespace,
+                since_seconds=since_seconds,
+                tail_lines=_COMPONENT_LOG_SCAN_LINES,
+                _request_timeout=self._call_timeout(),
+                **kwargs,
+            )
+        except DeadlineExceeded:
+            raise
+        except Exception as exc:  # noqa: BLE001
+            self._logger.warning("Failed to read DNS logs of %s: %s", pod.get("name"), exc)
+            result["error"] = str(exc)
+            return result
+        lines = (logs or "").splitlines()
+        servfail = errors = upstream_timeouts = 0
+        samples: list[str] = []
+        for line in lines:
+            is_servfail = "SERVFAIL" in line
+            is_error = "[ERROR]" in line
+            servfail += is_servfail
+            errors += is_error
+            upstream_timeouts += is_error and "timeout" in line.lower()
+            if (is_servfail or is_error) and len(samples) < _DNS_LOG_SAMPLES:
+                samples.append(line.strip())
+        result.update(
+            lines=len(lines),
+            servfail=servfail,
+            errors=errors,
+            upstream_timeouts=upstream_timeouts,
+            samples=samples,
+        )
+        return result
+
+    def _list_selected_pods(self, namespace: str, label_selector: str) -> list[client.V1Pod]:
+        try:
+            response = self._core_api.list_namespaced_pod(
+                namespace=namespace,
+                label_selector=label_selector,
+                _request_timeout=self._call_timeout(),
+            )
+        except DeadlineExceeded:
+            raise
+        except Exception as exc:  # noqa: BLE001
+            self._logger.warning("Failed to list pods %s in %s: %s", label_selector, namespace, exc)
+            return []
+        return list(response.items or [])
+
+    def _summarize_pod_readiness(self, pod: client.V1Pod) -> dict[str, object]:
+        status = pod.status
+        conditions = (status.conditions if status else None) or []
+        ready_condition = next((item for item in conditions if item.type == "Ready"), None)
+        statuses = {
+            item.name: item for item in (status.container_statuses if status else None) or []
+        }
+        containers: list[dict[str, object]] = []
+        for container in (pod.spec.containers if pod.spec else None) or []:
+            container_status = statuses.get(container.name)
+            containers.append(
+                {
+                    "name": container.name,
+                    "ready": container_status.ready if container_status else False,
+                    "restart_count": container_status.restart_count if container_status else 0,
+                    "state": self._extract_container_state(
+                        container_status.state if container_status else None
+                    ),
+                    "ports": [
+                        {"name": port.name, "port": port.container_port}
+                        for port in container.ports or []
+                    ],
+                    "readiness_probe": _summarize_probe(container.readiness_probe),
+                }
+            )
+        return {
+            "name": pod.metadata.name if pod.metadata else None,
+            "phase": status.phase if status else None,
+            "ready": bool(ready_condition and ready_condition.status == "True"),
+            "ready_message": ready_condition.message if ready_condition else None,
+            "terminating": bool(pod.metadata and pod.metadata.deletion_timestamp),
+            "node": pod.spec.node_name if pod.spec else None,
+            "containers": containers,
+        }
+
+    def get_job_run(
+        self, namespace: str, *, job: str | None = None, cron_job: str | None = None
+    ) -> dict[str, object] | None:
+        """Spec, conditions, pods and last logs of a Job, or of the latest Job of a CronJob.
+
+        For a CronJob, ``recent_runs`` lists its newest Jobs. ``found`` is false
+        when the Job no longer exists (e.g. removed by ``ttlSecondsAfterFinished``)
+        or the CronJob has no Jobs left.
+        """
+        if self._batch_api is None or self._core_api is None or not (job or cron_job):
+            return None
+        summary: dict[str, object] = {
+            "namespace": namespace,
+            "job": job,
+            "cron_job": cron_job,
+            "found": False,
+            "recent_runs": [],
+        }
+        if cron_job:
+            try:
+                jobs = self._batch_api.list_namespaced_job(
+                    namespace=namespace, _request_timeout=self._call_timeout()
+                ).items
+            except DeadlineExceeded:
+                raise
+            except Exception as exc:  # noqa: BLE001
+                self._logger.warning("Failed to list Jobs in %s: %s", namespace, exc)
+                return None
+            owned = [
+                item
+                for item in jobs or []
+                if item.metadata
+                and any(
+                    owner.kind == "CronJob" and owner.name == cron_job
+                    for owner in item.metadata.owner_references or []
+                )
+            ]
+            owned.sort(
+                key=lambda item: self._to_iso(item.metadata.creation_timestamp) or "", reverse=True
+            )
+            summary["recent_runs"] = [
+                self._summarize_job_run(item) for item in owned[:_CRON_JOB_RUN_LIMIT]
+            ]
+            if not owned:
+                return summary
+            latest = owned[0]
+        else:
+            try:
+                latest = self._batch_api.read_namespaced_job(
+                    name=job, namespace=namespace, _request_timeout=self._call_timeout()
+                )
+            except DeadlineExceeded:
+                raise
+            except Exception as exc:  # noqa: BLE001
+                if getattr(exc, "status", None) == 404:
+                    return summary
+                self._logger.warning("Failed to read Job %s/%s: %s", namespace, job, exc)
+                return None
+            owner = self._select_owner_reference(latest.metadata.owner_references or [])
+            if owner is not None and owner.kind == "CronJob":
+                summary["cron_job"] = owner.name
+        name = str(latest.metadata.name)
+        spec = latest.spec
+        summary.update(
+            job=name,
+            found=True,
+            spec={
+                "backoff_limit": spec.backoff_limit if spec else None,
+                "active_deadline_seconds": spec.active_deadline_seconds if spec else None,
+                "completions": spec.completions if spec else None,
+                "parallelism": spec.parallelism if spec else None,
+                "pod_failure_policy": bool(spec and spec.pod_failure_policy),
+            },
+            status=self._summarize_job_run(latest),
+        )
+        pods = sorted(
+            self._list_selected_pods(namespace, f"job-name={name}"),
+            key=lambda pod: self._to_iso(pod.metadata.creation_timestamp) or "",
+            reverse=True,
+        )[:_JOB_POD_LIMIT]
+        summaries = [self._summarize_job_pod(pod) for pod in pods]
+        summary["pods"] = summaries
+        failed_pod = next((pod for pod, item in zip(pods, summaries) if item["failed"]), None)
+        logs: list[PodLogSnippet] = []
+        if failed_pod is not None:
+            logs = self._get_current_logs(
+                namespace, failed_pod, container=None, tail_lines=None, since_seconds=None
+            )
+            statuses = (failed_pod.status.container_statuses if failed_pod.status else None) or []
+            if any(item.restart_count for item in statuses):
+                logs = [*self._get_previous_logs(namespace, failed_pod, []), *logs]
+        summary["logs"] = [snippet.to_dict() for snippet in logs]
+        summary["events"] = [
+            event.to_dict() for event in self._list_pod_events(namespace, name, [], kind="Job")
+        ]
+        return summary
+
+    def _summarize_job_run(self, job: client.V1Job) -> dict[str, object]:
+        status = job.status
+        return {
+            "name": job.metadata.name if job.metadata else None,
+            "start_time": self._to_iso(status.start_time) if status else None,
+            "completion_time": self._to_iso(status.completion_time) if status else None,
+            "active": (status.active or 0) if status else 0,
+            "succeeded": (status.succeeded or 0) if status else 0,
+            "failed": (status.failed or 0) if status else 0,
+            "conditions": self._summarize_conditions(status.conditions if status else None),
+        }
+
+    def _summarize_job_pod(self, pod: client.V1Pod) -> dict[str, object]:
+        status = pod.status
+        statuses = [
+            *((status.init_container_statuses if status else None) or []),
+            *((status.container_statuses if status else None) or []),
+        ]
+        containers = [
+            {
+                "name": item.name,
+                "image": item.image,
+                "restart_count": item.restart_count or 0,
+                "state": self._extract_container_state(item.state),
+                "last_state": self._extract_container_state(item.last_state),
+            }
+            for item in statuses
+        ]
+        phase = status.phase if status else None
+        return {
+            "name": pod.metadata.name if pod.metadata else None,
+            "phase": phase,
+            "reason": status.reason if status else None,
+            "message": status.message if status else None,
+            "node": pod.spec.node_name if pod.spec else None,
+            "start_time": self._to_iso(status.start_time) if status else None,
+            "failed": phase == "Failed" or any(_container_failed(item) for item in containers),
+            "containers": containers,
+        }
+
+    def _summarize_hpa(
+        self, namespace: str, autoscaler: client.V2HorizontalPodAutoscaler
+    ) -> dict[str, object]:
+        spec = autoscaler.spec
+        status = autoscaler.status
+        name = autoscaler.metadata.name if autoscaler.metadata else None
+        return {
+            "name": name,
+            "namespace": namespace,
+            "target": {"kind": spec.scale_target_ref.kind, "name": spec.scale_target_ref.name},
+            "min_replicas": spec.min_replicas,
+            "max_replicas": spec.max_replicas,
+            "current_replicas": status.current_replicas if status else None,
+            "desired_replicas": status.desired_replicas if status else None,
+            "last_scale_time": self._to_iso(status.last_scale_time) if status else None,
+            "metrics": [metric.to_dict() for metric in spec.metrics or []],
+            "current_metrics": [
+                metric.to_dict() for metric in (status.current_metrics if status else None) or []
+            ],
+            "conditions": [
+                {
+                    "type": condition.type,
+                    "status": condition.status,
+                    "reason": condition.reason,
+                    "message": condition.message,
+                }
+                for condition in (status.conditions if status else None) or []
+            ],
+            "events": [
+                event.to_dict()
+                for event in self._list_pod_events(
+                    namespace, str(name), [], kind="HorizontalPodAutoscaler"
+                )
+            ],
+        }
+
+    def _read_persistent_volume(self, name: str | None) -> dict[str, object] | None:
+        if not name:
+            return None
+        try:
+            volume = self._core_api.read_persistent_volume(
+                name=name, _request_timeout=self._call_timeout()
+            )
+        except DeadlineExceeded:
+            raise
+        except Exception as exc:  # noqa: BLE001
+            self._logger.warning("Failed to read persistentvolume %s: %s", name, exc)
+            return {"name": name, "found": False}
+        spec = volume.spec
+        csi = spec.csi if spec else None
+        return {
+            "name": name,
+            "found": True,
+            "phase": volume.status.phase if volume.status else None,
+            "reason": volume.status.reason if volume.status else None,
+            "message": volume.status.message if volume.status else None,
+            "capacity": ((spec.capacity if spec else None) or {}).get("storage"),
+            "reclaim_policy": spec.persistent_volume_reclaim_policy if spec else None,
+            "csi_driver": csi.driver if csi else None,
+            "volume_handle": csi.volume_handle if csi else None,
+        }
+
+    def _read_storage_class(self, name: str | None) -> dict[str, object] | None:
+        if not name or self._storage_api is None:
+            return None
+        try:
+            storage_class = self._storage_api.read_storage_class(
+                name=name, _request_timeout=self._call_timeout()
+            )
+        except DeadlineExceeded:
+            raise
+        except Exception as exc:  # noqa: BLE001
+            self._logger.warning("Failed to read storageclass %s: %s", name, exc)
+            return {"name": name, "found": False}
+        return {
+            "name": name,
+            "found": True,
+            "provisioner": storage_class.provisioner,
+            "volume_binding_mode": storage_class.volume_binding_mode,
+            "allow_volume_expansion": storage_class.allow_volume_expansion,
+            "reclaim_policy": storage_class.reclaim_policy,
+        }
+
+    def _list_volume_attachments(self, volume_name: str | None) -> list[dict[str, object]]:
+        if not volume_name or self._storage_api is None:
+            return []
+        try:
+            response = self._storage_api.list_volume_attachment(
+                _request_timeout=self._call_timeout()
+            )
+        except DeadlineExceeded:
+            raise
+        except Exception as exc:  # noqa: BLE001
+            self._logger.warning("Failed to list volumeattachments: %s", exc)
+            return []
+        attachments: list[dict[str, object]] = []
+        for item in response.items:
+            source = item.spec.source if item.spec else None
+            if source is None or source.persistent_volume_name != volume_name:
+                continue
+            status = item.status
+            attach_error = status.attach_error if status else None
+            detach_error = status.detach_error if status else None
+            attachments.append(
+                {
+                    "name": item.metadata.name if item.metadata else None,
+                    "node": item.spec.node_name,
+                    "attacher": item.spec.attacher,
+                    "attached": bool(status.attached) if status else False,
+                    "attach_error": attach_error.message if attach_error else None,
+                    "detach_error": detach_error.message if detach_error else None,
+                }
+            )
+        return attachments
+
+    def get_manifest(
+        self,
+        namespace: str,
+        api_version: str,
+        resource: str,
+        name: str,
+    ) -> dict[str, object] | None:
+        """Fetch a namespaced manifest for either core (v1) or custom resources."""
+        parsed = self._parse_api_version(api_version)
+        normalized_resource = resource.strip().lower()
+        if parsed is None or not normalized_resource:
+            return None
+        group, version = parsed
+        if group is None:
+            return self._get_core_manifest(namespace, version, normalized_resource, name)
+        return self._get_custom_manifest(
+            group=group,
+            version=version,
+            namespace=namespace,
+            plural=normalized_resource,
+            name=name,
+        )
+
+    def list_manifests(
+        self,
+        namespace: str,
+        api_version: str,
+        resource: str,
+        label_selector: str | None = None,
+        limit: int = 20,
+    ) -> list[dict[str, object]]:
+        """List namespaced manifests for either core (v1) or custom resources."""
+        parsed = self._parse_api_version(api_version)
+        normalized_resource = resource.strip().lower()
+        if parsed is None or not normalized_resource:
+            return []
+        group, version = parsed
+        if group is None:
+            return self._list_core_manifests(
+                namespace,
+                version,
+                normalized_resource,
+                label_selector=label_selector,
+                limit=limit,
+            )
+        return self._list_custom_manifests(
+            group=group,
+            version=version,
+            namespace=namespace,
+            plural=normalized_resource,
+            label_selector=label_selector,
+            limit=limit,
+        )
+
+    def list_services_by_label(
+        self, label_selector: str, namespaces: list[str] | None = None
+    ) -> list[client.V1Service]:
+        if self._core_api is None or not label_selector:
+            return []
+        try:
+            if namespaces:
+                services: list[client.V1Service] = []
+                for namespace in namespaces:
+                    response = self._core_api.list_namespaced_service(
+                        namespace=namespace,
+                        label_selector=label_selector,
+                        _request_timeout=self._call_timeout(),
+                    )
+                    services.extend(response.items)
+                return services
+            response = self._core_api.list_service_for_all_namespaces(
+                label_selector=label_selector,
+                _request_timeout=self._call_timeout(),
+            )
+            return response.items
+        except DeadlineExceeded:
+            raise
+        except Exception as exc:  # noqa: BLE001
+            self._logger.warning("Failed to list services for selector %s: %s", label_selector, exc)
+            return []
+
+    def list_pods_in_namespace(
+        self, namespace: str, label_selector: str | None = None
+    ) -> list[PodSummary]:
+        """List pods in a namespace with optional label filtering."""
+        if self._core_api is None:
+            return []
+        try:
+            if label_selector:
+                response = self._core_api.list_namespaced_pod(
+                    namespace=namespace,
+                    label_selector=label_selector,
+                    _request_timeout=self._call_timeout(),
+                )
+            else:
+                response = self._core_api.list_namespaced_pod(
+                    namespace=namespace,
+                    _request_timeout=self._call_timeout(),
+                )
+            return [self._to_pod_summary(pod) for pod in response.items]
+        except DeadlineExceeded:
+            raise
+        except Exception as exc:  # noqa: BLE001
+            self._logger.warning("Failed to list pods in namespace %s: %s", namespace, exc)
+            return []
+
+    def describe_api_server(self) -> dict[str, object]:
+        """Report whether the API server answers, with its version."""
+        if self._core_api is None:
+            return {"configured": False, "reachable": False}
+        version = self._read_server_version()
+        return {"configured": True, "reachable": version is not None, "version": version}
+
+    def check_permissions(
+        self,
+        namespace: str | None = None,
+        permissions: Sequence[Permission] = ANALYSIS_PERMISSIONS,
+    ) -> list[dict[str, object]]:
+        """Ask the API server (SelfSubjectAccessReview) whether the agent has *permissions*."""
+        if self._authorization_api is None:
+            return []
+        results: list[dict[str, object]] = []
+        for permission in permissions:
+            scope = None if permission.cluster_scoped else permission.namespace or namespace
+            attributes = client.V1ResourceAttributes(
+                verb=permission.verb,
+                group=permission.group,
+                resource=permission.resource,
+                subresource=permission.subresource,
+                namespace=scope,
+            )
+            review = client.V1SelfSubjectAccessReview(
+                spec=client.V1SelfSubjectAccessReviewSpec(resource_attributes=attributes)
+            )
+            check: dict[str, object] = {
+                "collector": permission.collector,
+                "verb": permission.verb,
+                "resource": permission.rule_resource,
+                "group": permission.group or "core",
+                "namespace": None if permission.cluster_scoped else scope or "*",
+                "optional": permission.optional,
+            }
+            try:
+                response = self._authorization_api.create_self_subject_access_review(
+                    body=review, _request_timeout=self._call_timeout()
+                )
+            except DeadlineExceeded:
+                raise
+            except Exception as exc:  # noqa: BLE001
+                self._logger.warning(
+                    "Failed to review %s %s access: %s",
+                    permission.verb,
+                    permission.rule_resource,
+                    exc,
+                )
+                check.update(allowed=None, error=str(exc))
+            else:
+                status = response.status
+                check.update(
+                    allowed=bool(status.allowed) if status else None,
+                    reason=(status.reason or None) if status else None,
+                )
+            results.append(check)
+        return results
+
+    def list_unhealthy_pods(
+        self, namespace: str, *, pending_minutes: int = 10
+    ) -> list[dict[str, object]]:
+        """Return pods stuck Pending longer than *pending_minutes* or crash looping."""
+        if self._core_api is None:
+            return []
+        try:
+            pods = self._core_api.list_namespaced_pod(
+                namespace=namespace, _request_timeout=self._call_timeout()
+            ).items
+        except DeadlineExceeded:
+            raise
+        except Exception as exc:  # noqa: BLE001
+            self._logger.warning("Failed to list pods in namespace %s: %s", namespace, exc)
+            return []
+        pending_cutoff = datetime.now(timezone.utc) - timedelta(minutes=pending_minutes)
+        issues: list[dict[str, object]] = []
+        for pod in pods:
+            metadata = pod.metadata
+            status = pod.status
+            name = metadata.name if metadata else None
+            created = metadata.creation_timestamp if metadata else None
+            if (
+                status is not None
+                and status.phase == "Pending"
+                and isinstance(created, datetime)
+                and created <= pending_cutoff
+            ):
+                condition = next(
+                    (c for c in status.conditions or [] if c.type == "PodScheduled"), None
+                )
+                issues.append(
+                    {
+                        "pod": name,
+                        "issue": "pending",
+                        "reason": condition.reason if condition else status.reason,
+                        "message": condition.message if condition else status.message,
+                        "since": self._to_iso(created),
+                    }
+                )
+                continue
+            for container_status in (status.container_statuses or []) if status else []:
+                waiting = container_status.state.waiting if container_status.state else None
+                if waiting is not None and waiting.reason == "CrashLoopBackOff":
+                    issues.append(
+                        {
+                            "pod": name,
+                            "issue": "crash_loop",
+                            "container": container_status.name,
+                            "restart_count": container_status.restart_count,
+                            "message": waiting.message,
+                        }
+                    )
+        return issues
+
+    def list_certificates(self, namespace: str) -> list[dict[str, object]]:
+        """Summarize cert-manager Certificates (expiry, renewal and readiness)."""
+        certificates: list[dict[str, object]] = []
+        for item in self._list_custom_objects(
+            "cert-manager.io", "v1", "certificates", namespace=namespace
+        ):
+            spec = item.get("spec")
+            status = item.get("status")
+            status = status if isinstance(status, dict) else {}
+            ready = next(
+                (c for c in _custom_conditions(item) if c.get("type") == "Ready"), None
+            )
+            certificates.append(
+                {
+                    "name": _metadata_field(item, "name"),
+                    "secret_name": spec.get("secretName") if isinstance(spec, dict) else None,
+                    "not_after": status.get("notAfter"),
+                    "renewal_time": status.get("renewalTime"),
+                    "ready": ready.get("status") if ready else None,
+                    "reason": ready.get("reason") if ready else None,
+                    "message": ready.get("message") if ready else None,
+                }
+            )
+        return certificates
+
+    def list_resource_quota_usage(self, namespace: str) -> list[dict[str, object]]:
+        """Return used/hard per ResourceQuota resource with the usage ratio."""
+        if self._core_api is None:
+            return []
+        try:
+            quotas = self._core_api.list_namespaced_resource_quota(
+                namespace=namespace, _request_timeout=self._call_timeout()
+            ).items
+        except DeadlineExceeded:
+            raise
+        except Exception as exc:  # noqa: BLE001
+            self._logger.warning("Failed to list resource quotas in %s: %s", namespace, exc)
+            return []
+        usage: list[dict[str, object]] = []
+        for quota in quotas:
+            hard = (quota.status.hard if quota.status else None) or {}
+            used = (quota.status.used if quota.status else None) or {}
+            for resource, hard_value in sorted(hard.items()):
+                used_value = used.get(resource, "0")
+                hard_amount = parse_quantity(str(hard_value))
+                used_amount = parse_quantity(str(used_value))
+                ratio = (
+                    round(used_amount / hard_amount, 3)
+                    if hard_amount is not None and used_amount is not None and hard_amount > 0
+                    else None
+                )
+                usage.append(
+                    {
+                        "quota": quota.metadata.name if quota.metadata else None,
+                        "resource": resource,
+                        "used": used_value,
+                        "hard": hard_value,
+                        "ratio": ratio,
+                    }
+                )
+        return usage
+
+    def get_namespace_quotas(self, namespace: str) -> dict[str, object] | None:
+        """ResourceQuota usage and LimitRange constraints that admission applies to new pods."""
+        if self._core_api is None:
+            return None
+        try:
+            limit_ranges = self._core_api.list_namespaced_limit_range(
+                namespace=namespace, _request_timeout=self._call_timeout()
+            ).items
+        except DeadlineExceeded:
+            raise
+        except Exception as exc:  # noqa: BLE001
+            self._logger.warning("Failed to list limit ranges in %s: %s", namespace, exc)
+            limit_ranges = []
+        return {
+            "namespace": namespace,
+            "quotas": self.list_resource_quota_usage(namespace),
+            "limit_ranges": [
+                {
+                    "name": item.metadata.name if item.metadata else None,
+                    "limits": [
+                        {
+                            "type": limit.type,
+                            "max": limit.max,
+                            "min": limit.min,
+                            "default": limit.default,
+                            "default_request": limit.default_request,
+                            "max_limit_request_ratio": limit.max_limit_request_ratio,
+                        }
+                        for limit in (item.spec.limits if item.spec else None) or []
+                    ],
+                }
+                for item in limit_ranges
+            ],
+        }
+
+    def get_namespace_snapshot(
+        self, namespace: str, *, max_workloads: int, max_pods: int
+    ) -> dict[str, object] | None:
+        """Replica health of the namespace's workloads and its failing pods, unhealthy first.
+
+        Everything is listed; only the first *max_workloads* workloads and
+        *max_pods* failing pods are kept, with the totals.
+        """
+        if self._core_api is None:
+            return None
+        try:
+            pods = self._core_api.list_namespaced_pod(
+                namespace=namespace, _request_timeout=self._call_timeout()
+            ).items or []
+        except DeadlineExceeded:
+            raise
+        except Exception as exc:  # noqa: BLE001
+            self._logger.warning("Failed to list pods in namespace %s: %s", namespace, exc)
+            return None
+        workloads: list[dict[str, object]] = []
+        warnings: list[str] = []
+        for kind, method in (
+            ("Deployment", "list_namespaced_deployment"),
+            ("StatefulSet", "list_namespaced_stateful_set"),
+            ("DaemonSet", "list_namespaced_daemon_set"),
+        ):
+            if self._apps_api is None:
+                break
+            try:
+                items = getattr(self._apps_api, method)(
+                    namespace=namespace, _request_timeout=self._call_timeout()
+                ).items or []
+            except DeadlineExceeded:
+                raise
+            except Exception as exc:  # noqa: BLE001
+                self._logger.warning("Failed to list %ss in %s: %s", kind, namespace, exc)
+                warnings.append(f"failed to list {kind.lower()}s in {namespace}")
+                continue
+            workloads.extend(_workload_replicas(kind, item) for item in items)
+        workloads.sort(
+            key=lambda item: (bool(item["healthy"]), str(item["kind"]), str(item["name"]))
+        )
+        failing = sorted(
+            (
+                summary
+                for summary in (_failing_pod(pod) for pod in pods)
+                if summary is not None
+            ),
+            key=lambda item: str(item["name"]),
+        )
+        return {
+            "namespace": namespace,
+            "workloads": workloads[: max(0, max_workloads)],
+            "workload_count": len(workloads),
+            "unhealthy_workload_count": sum(1 for item in workloads if not item["healthy"]),
+            "failing_pods": failing[: max(0, max_pods)],
+            "pod_count": len(pods),
+            "failing_pod_count": len(failing),
+            "warnings": warnings,
+        }
+
+    def list_rca_configs(self, namespace: str) -> list[dict[str, object]]:
+        """RCAConfig resources of *namespace*; none when the CRD is not installed."""
+        return self._list_custom_objects(
+            "kube-rca.io", "v1alpha1", "rcaconfigs", namespace=namespace
+        )
+
+    def get_crossplane_resource_tree(
+        self,
+        api_version: str,
+        resource: str,
+        name: str,
+        namespace: str | None = None,
+    ) -> dict[str, object] | None:
+        """Follow a Crossplane claim or composite down to its managed resources.
+
+        A namespace means the root is a claim (or a Crossplane v2 namespaced
+        composite); without one the root is read as a cluster-scoped composite.
+        Every node carries its Ready/Synced conditions so provisioning failures
+        at the cloud provider can be attributed.
+        """
+        parsed = self._parse_api_version(api_version)
+        if parsed is None or parsed[0] is None or not resource.strip():
+            return None
+        group, version = parsed
+        root = self._read_custom_object(
+            group, version, resource.strip().lower(), name, namespace=namespace or None
+        )
+        if root is None:
+            return None
+
+        warnings: list[str] = []
+        composite: dict[str, object] | None = None
+        composite_ref = _crossplane_spec_field(root, "resourceRef")
+        if isinstance(composite_ref, dict):
+            composite = self._read_object_ref(composite_ref, namespace=None)
+            if composite is None:
+                warnings.append(f"composite {composite_ref.get('name')} could not be read")
+        else:
+            # The root is already a composite.
+            composite = root
+
+        managed: list[dict[str, object]] = []
+        if composite is not None:
+            refs = _crossplane_spec_field(composite, "resourceRefs")
+            composite_namespace = _metadata_field(composite, "namespace")
+            for ref in (refs if isinstance(refs, list) else [])[:_CROSSPLANE_MAX_MANAGED]:
+                if not isinstance(ref, dict):
+                    continue
+                item = self._read_object_ref(ref, namespace=composite_namespace)
+                if item is None:
+                    warnings.append(f"{ref.get('kind')} {ref.get('name')} could not be read")
+                    continue
+                managed.append(self._summarize_custom_resource(item))
+
+        root_summary = self._summarize_custom_resource(root)
+        composite_summary = (
+            self._summarize_custom_resource(composite)
+            if composite is not None and composite is not root
+            else None
+        )
+        nodes = [root_summary, *([composite_summary] if composite_summary else []), *managed]
+        result: dict[str, object] = {
+            "root": root_summary,
+            "composite": composite_summary,
+            "managed_resources": managed,
+            "unhealthy": [_unhealthy_view(node) for node in nodes if not _crossplane_healthy(node)],
+        }
+        if warnings:
+            result["warnings"] = warnings
+        return result
+
+    def get_keda_scaling_status(
+        self, namespace: str, workload: str | None = None
+    ) -> list[dict[str, object]]:
+        """Summarize KEDA ScaledObjects in a namespace, optionally for one scale target.
+
+        Each entry reports readiness/activity conditions, per-trigger health,
+        whether referenced TriggerAuthentications exist and the current external
+        metric values, with human-readable findings for scaling problems.
+        """
+        scaled_objects = self._list_custom_objects(
+            "keda.sh", "v1alpha1", "scaledobjects", namespace=namespace
+        )
+        summaries: list[dict[str, object]] = []
+        for scaled_object in scaled_objects:
+            spec = scaled_object.get("spec")
+            spec = spec if isinstance(spec, dict) else {}
+            target = spec.get("scaleTargetRef")
+            target_name = target.get("name") if isinstance(target, dict) else None
+            if workload and target_name != workload:
+                continue
+            summaries.append(self._summarize_scaled_object(namespace, scaled_object))
+            if len(summaries) >= _KEDA_MAX_SCALED_OBJECTS:
+                break
+        return summaries
+
+    def _summarize_scaled_object(
+        self, namespace: str, scaled_object: dict[str, object]
+    ) -> dict[str, object]:
+        name = _metadata_field(scaled_object, "name") or ""
+        spec = scaled_object.get("spec")
+        spec = spec if isinstance(spec, dict) else {}
+        status = scaled_object.get("status")
+        status = status if isinstance(status, dict) else {}
+        condition_list = _custom_conditions(scaled_object)
+        conditions = {str(condition.get("type")): condition for condition in condition_list}
+        findings: list[str] = []
+
+        ready = conditions.get("Ready")
+        if ready is not None and ready.get("status") != "True":
+            detail = f"{ready.get('reason')} {ready.get('message') or ''}".strip()
+            findings.append(f"ScaledObject not ready: {detail}")
+        if conditions.get("Active", {}).get("status") == "False":
+            findings.append("no trigger is active; workload is held at minReplicaCount")
+        if conditions.get("Fallback", {}).get("status") == "True":
+            findings.append("fallback replicas in use because scalers keep failing")
+        metadata = scaled_object.get("metadata")
+        annotations = metadata.get("annotations") if isinstance(metadata, dict) else None
+        if isinstance(annotations, dict) and any(
+            key.startswith("autoscaling.keda.sh/paused") for key in annotations
+        ):
+            findings.append("scaling is paused by annotation")
+
+        health = status.get("health")
+        health = health if isinstance(health, dict) else {}
+        for metric_name, metric_health in health.items():
+            if isinstance(metric_health, dict) and metric_health.get("status") == "Failing":
+                findings.append(
+                    f"trigger {metric_name} failing "
+                    f"({metric_health.get('numberOfFailures')} consecutive failures)"
+                )
+
+        triggers: list[dict[str, object]] = []
+        raw_triggers = spec.get("triggers")
+        for trigger in raw_triggers if isinstance(raw_triggers, list) else []:
+            if not isinstance(trigger, dict):
+                continue
+            auth_ref = trigger.get("authenticationRef")
+            trigger_summary: dict[str, object] = {
+                "type": trigger.get("type"),
+                "name": trigger.get("name"),
+                "metadata": trigger.get("metadata"),
+            }
+            if isinstance(auth_ref, dict) and auth_ref.get("name"):
+                auth_kind = str(auth_ref.get("kind") or "TriggerAuthentication")
+                auth_found = self._read_custom_object(
+                    "keda.sh",
+                    "v1alpha1",
+                    _kind_to_plural(auth_kind),
+                    str(auth_ref["name"]),
+                    namespace=namespace if auth_kind == "TriggerAuthentication" else None,
+                )
+                trigger_summary["authentication_ref"] = {
+                    "kind": auth_kind,
+                    "name": auth_ref["name"],
+                    "found": auth_found is not None,
+                }
+                if auth_found is None:
+                    findings.append(
+                        f"{auth_kind} {auth_ref['name']} referenced by trigger "
+                        f"{trigger.get('type')} was not found"
+                    )
+            triggers.append(trigger_summary)
+
+        metric_names = status.get("externalMetricNames")
+        external_metrics = [
+            self._read_external_metric(namespace, str(metric_name), name)
+            for metric_name in (metric_names if isinstance(metric_names, list) else [])
+        ]
+
+        target = spec.get("scaleTargetRef")
+        return {
+            "name": name,
+            "scale_target": target,
+            "min_replica_count": spec.get("minReplicaCount"),
+            "max_replica_count": spec.get("maxReplicaCount"),
+            "hpa_name": status.get("hpaName"),
+            "last_active_time": status.get("lastActiveTime"),
+            "conditions": condition_list,
+            "triggers": triggers,
+            "health": health,
+            "external_metrics": external_metrics,
+            "findings": findings,
+        }
+
+    def _read_external_metric(
+        self, namespace: str, metric_name: str, scaled_object: str
+    ) -> dict[str, object]:
+        if self._custom_api is None:
+            return {"metric": metric_name, "error": "kubernetes client is not configured"}
+        try:
+            response = self._custom_api.list_namespaced_custom_object(
+                group="external.metrics.k8s.io",
+                version="v1beta1",
+                namespace=namespace,
+                plural=metric_name,
+                label_selector=f"scaledobject.keda.sh/name={scaled_object}",
+                _request_timeout=self._call_timeout(),
+            )
+        except DeadlineExceeded:
+            raise
+        except Exception as exc:  # noqa: BLE001
+            # Scaler errors surface here too (e.g. broker unreachable, auth denied).
+            return {"metric": metric_name, "error": str(exc)}
+        items = response.get("items") if isinstance(response, dict) else None
+        values = [
+            item.get("value")
+            for item in (items if isinstance(items, list) else [])
+            if isinstance(item, dict)
+        ]
+        return {"metric": metric_name, "values": values}
+
+    def get_knative_service_status(self, namespace: str, name: str) -> dict[str, object] | None:
+        """Summarize a Knative Service, its revisions and their autoscaling state.
+
+        Reports why the latest revision is not ready, which revisions are
+        scaled to zero, and whether requests are buffered by the activator
+        (SKS in Proxy mode), which explains cold-start latency.
+        """
+        service = self._read_custom_object(
+            "serving.knative.dev", "v1", "services", name, namespace=namespace
+        )
+        if service is None:
+            return None
+        status = service.get("status")
+        status = status if isinstance(status, dict) else {}
+        latest_created = status.get("latestCreatedRevisionName")
+        latest_ready = status.get("latestReadyRevisionName")
+        findings: list[str] = []
+        for condition in _custom_conditions(service):
+            if condition.get("status") != "True":
+                findings.append(
+                    f"Service condition {condition.get('type')}={condition.get('status')}: "
+                    f"{condition.get('reason')} {condition.get('message') or ''}".strip()
+                )
+        if latest_created and latest_created != latest_ready:
+            findings.append(
+                f"latest revision {latest_created} is not ready; "
+                f"traffic still served by {latest_ready or 'no revision'}"
+            )
+
+        revisions: list[dict[str, object]] = []
+        for revision in self._list_custom_objects(
+            "serving.knative.dev",
+            "v1",
+            "revisions",
+            namespace=namespace,
+            label_selector=f"serving.knative.dev/service={name}",
+        )[:_KNATIVE_MAX_REVISIONS]:
+            summary, revision_findings = self._summarize_knative_revision(namespace, revision)
+            revisions.append(summary)
+            findings.extend(revision_findings)
+
+        return {
+            "name": name,
+            "namespace": namespace,
+            "url": status.get("url"),
+            "conditions": _custom_conditions(service),
+            "latest_created_revision": latest_created,
+            "latest_ready_revision": latest_ready,
+            "traffic": status.get("traffic"),
+            "revisions": revisions,
+            "findings": findings,
+        }
+
+    def _summarize_knative_revision(
+        self, namespace: str, revision: dict[str, object]
+    ) -> tuple[dict[str, object], list[str]]:
+        name = _metadata_field(revision, "name") or ""
+        spec = revision.get("spec")
+        spec = spec if isinstance(spec, dict) else {}
+        status = revision.get("status")
+        status = status if isinstance(status, dict) else {}
+        metadata = revision.get("metadata")
+        annotations = metadata.get("annotations") if isinstance(metadata, dict) else None
+        annotations = annotations if isinstance(annotations, dict) else {}
+        conditions = _custom_conditions(revision)
+        findings: list[str] = []
+        for condition in conditions:
+            # Active=False only means scaled to zero; it is reported separately below.
+            if condition.get("type") != "Active" and condition.get("status") == "False":
+                findings.append(
+                    f"revision {name} {condition.get('type')}=False: "
+                    f"{condition.get('reason')} {condition.get('message') or ''}".strip()
+                )
+
+        pod_autoscaler = self._read_custom_object(
+            "autoscaling.internal.knative.dev",
+            "v1alpha1",
+            "podautoscalers",
+            name,
+            namespace=namespace,
+        )
+        pa_status = pod_autoscaler.get("status") if pod_autoscaler else None
+        pa_status = pa_status if isinstance(pa_status, dict) else {}
+        serverless_service = self._read_custom_object(
+            "networking.internal.knative.dev",
+            "v1alpha1",
+            "serverlessservices",
+            name,
+            namespace=namespace,
+        )
+        sks_spec = serverless_service.get("spec") if serverless_service else None
+        sks_mode = sks_spec.get("mode") if isinstance(sks_spec, dict) else None
+
+        min_scale = annotations.get("autoscaling.knative.dev/min-scale") or annotations.get(
+            "autoscaling.knative.dev/minScale"
+        )
+        actual_replicas = status.get("actualReplicas", pa_status.get("actualScale"))
+        if actual_replicas == 0:
+            finding = f"revision {name} is scaled to zero; the first request waits for a cold start"
+            if not min_scale:
+                finding += " (set autoscaling.knative.dev/min-scale to keep pods warm)"
+            findings.append(finding)
+        if sks_mode == "Proxy":
+            findings.append(f"revision {name} traffic is routed through the activator (queueing)")
+
+        summary = {
+            "name": name,
+            "conditions": conditions,
+            "actual_replicas": actual_replicas,
+            "desired_replicas": status.get("desiredReplicas", pa_status.get("desiredScale")),
+            "min_scale": min_scale,
+            "max_scale": annotations.get("autoscaling.knative.dev/max-scale")
+            or annotations.get("autoscaling.knative.dev/maxScale"),
+            "container_concurrency": spec.get("containerConcurrency"),
+            "sks_mode": sks_mode,
+        }
+        return summary, findings
+
+    def get_velero_operation_status(
+        self,
+        name: str | None = None,
+        *,
+        operation: str = "backup",
+        velero_namespace: str = "velero",
+    ) -> dict[str, object]:
+        """Summarize a Velero Backup/Restore, or the most recent failed ones.
+
+        Includes per-volume failures (PodVolumeBackup/Restore, CSI DataUpload/
+        DataDownload) and error lines from the Velero server and plugin logs.
+        """
+        if operation not in _VELERO_OPERATIONS:
+            return {"error": f"operation must be one of {sorted(_VELERO_OPERATIONS)}"}
+        plural, volume_plural, data_plural, name_label = _VELERO_OPERATIONS[operation]
+
+        if name:
+            item = self._read_custom_object(
+                "velero.io", "v1", plural, name, namespace=velero_namespace
+            )
+            items = [item] if item is not None else []
+        else:
+            items = [
+                item
+                for item in self._list_custom_objects(
+                    "velero.io", "v1", plural, namespace=velero_namespace
+                )
+                if _velero_status(item).get("phase") in _VELERO_FAILED_PHASES
+            ]
+            items.sort(key=lambda item: str(_velero_status(item).get("startTimestamp") or ""))
+            items = items[-_VELERO_MAX_OPERATIONS:][::-1]
+
+        operations: list[dict[str, object]] = []
+        for item in items:
+            item_name = _metadata_field(item, "name") or ""
+            status = _velero_status(item)
+            spec = item.get("spec")
+            spec = spec if isinstance(spec, dict) else {}
+            selector = f"{name_label}={item_name}"
+            failed_volumes = [
+                _velero_volume_failure(volume)
+                for volume in self._list_custom_objects(
+                    "velero.io",
+                    "v1",
+                    volume_plural,
+                    namespace=velero_namespace,
+                    label_selector=selector,
+                )
+                + self._list_custom_objects(
+                    "velero.io",
+                    "v2alpha1",
+                    data_plural,
+                    namespace=velero_namespace,
+                    label_selector=selector,
+                )
+                if _velero_status(volume).get("phase") in _VELERO_FAILED_PHASES
+            ]
+            operations.append(
+                {
+                    "name": item_name,
+                    "phase": status.get("phase"),
+                    "errors": status.get("errors"),
+                    "warnings": status.get("warnings"),
+                    "failure_reason": status.get("failureReason"),
+                    "validation_errors": status.get("validationErrors"),
+                    "started": status.get("startTimestamp"),
+                    "completed": status.get("completionTimestamp"),
+                    "storage_location": spec.get("storageLocation"),
+                    "backup_name": spec.get("backupName"),
+                    "included_namespaces": spec.get("includedNamespaces"),
+                    "failed_volumes": failed_volumes,
+                }
+            )
 
-    def get_node_metrics(self, node_name: str) -> dict[str, object] | None:
-        if self._custom_api is None:
-            return None
-        try:
-            response = self._custom_api.get_cluster_custom_object(
-                group="metrics.k8s.io",
-                version="v1beta1",
-                plural="nodes",
-                name=node_name,
-                _request_timeout=self._timeout_seconds,
+        names = [str(op["name"]) for op in operations]
+        return {
+            "operation": operation,
+            "namespace": velero_namespace,
+            "operations": operations,
+            "server_error_logs": self._velero_error_logs(velero_namespace, names),
+        }
+
+    def get_external_dns_status(
+        self,
+        hostname: str | None = None,
+        *,
+        external_dns_namespace: str = "external-dns",
+    ) -> dict[str, object]:
+        """Classify external-dns controller errors and check a record resolves.
+
+        Provider errors are grouped into rate limiting, permission denials and
+        other errors. When *hostname* is given, log lines are narrowed to it
+        and the name is resolved to confirm the record exists at the provider.
+        """
+        lines = self._read_component_logs(external_dns_namespace, _EXTERNAL_DNS_SELECTORS)
+        categories: dict[str, list[str]] = {category: [] for category in _EXTERNAL_DNS_PATTERNS}
+        categories["other_errors"] = []
+        for line in lines:
+            lowered = line.lower()
+            if "level=error" not in lowered and "level=warning" not in lowered:
+                continue
+            # Provider throttling and auth failures are reported for whole batches,
+            # so they are kept even when they do not mention the hostname.
+            category = next(
+                (
+                    name
+                    for name, markers in _EXTERNAL_DNS_PATTERNS.items()
+                    if any(marker in lowered for marker in markers)
+                ),
+                None,
             )
-        except Exception as exc:  # noqa: BLE001
-            self._logger.warning("Failed to read node metrics for %s: %s", node_name, exc)
-            return None
-        return self._summarize_node_metrics(response)
+            if category is None:
+                if hostname and hostname.lower() not in lowered:
+                    continue
+                category = "other_errors"
+            categories[category].append(line)
+
+        result: dict[str, object] = {
+            "namespace": external_dns_namespace,
+            "log_lines_scanned": len(lines),
+            "error_counts": {name: len(items) for name, items in categories.items()},
+            "errors": {
+                name: items[-_EXTERNAL_DNS_MAX_LINES:]
+                for name, items in categories.items()
+                if items
+            },
+        }
+        if not lines:
+            result["warning"] = "external-dns pod or logs not found"
+        if hostname:
+            result["record"] = _resolve_record(hostname)
+        return result
+
+    def _velero_error_logs(self, velero_namespace: str, names: list[str]) -> list[str]:
+        """Return error lines from the Velero server pod, which also hosts plugins."""
+        lines = [
+            line
+            for line in self._read_component_logs(
+                velero_namespace, _VELERO_SERVER_SELECTORS, container="velero"
+            )
+            if "level=error" in line and (not names or any(name in line for name in names))
+        ]
+        return lines[-_VELERO_MAX_ERROR_LINES:]
 
-    def get_manifest(
+    def _read_component_logs(
         self,
         namespace: str,
-        api_version: str,
-        resource: str,
-        name: str,
+        label_selectors: Iterable[str],
+        *,
+        container: str | None = None,
+    ) -> list[str]:
+        """Read the log tail of the first pod matching any of *label_selectors*.
+
+        Used for add-on controllers whose errors only show up in their own logs;
+        the tail is intentionally longer than K8S_LOG_TAIL_LINES because the
+        lines are filtered before being returned to the model.
+        """
+        if self._core_api is None:
+            return []
+        for selector in label_selectors:
+            pods = self.list_pods_in_namespace(namespace, label_selector=selector)
+            if not pods:
+                continue
+            kwargs: dict[str, object] = {
+                "name": pods[0].name,
+                "namespace": namespace,
+                "tail_lines": _COMPONENT_LOG_SCAN_LINES,
+                "_request_timeout": self._timeout_seconds,
+            }
+            if container:
+                kwargs["container"] = container
+            try:
+                logs = self._core_api.read_namespaced_pod_log(**kwargs)
+            except DeadlineExceeded:
+                raise
+            except Exception as exc:  # noqa: BLE001
+                self._logger.warning(
+                    "Failed to read logs for %s/%s: %s", namespace, pods[0].name, exc
+                )
+                return []
+            return (logs or "").splitlines()
+        return []
+
+    def _read_object_ref(
+        self, ref: dict[str, object], *, namespace: str | None
     ) -> dict[str, object] | None:
-        """Fetch a namespaced manifest for either core (v1) or custom resources."""
-        parsed = self._parse_api_version(api_version)
-        normalized_resource = resource.strip().lower()
-        if parsed is None or not normalized_resource:
+        parsed = self._parse_api_version(str(ref.get("apiVersion") or ""))
+        kind = str(ref.get("kind") or "")
+        ref_name = str(ref.get("name") or "")
+        if parsed is None or parsed[0] is None or not kind or not ref_name:
             return None
         group, version = parsed
-        if group is None:
-            return self._get_core_manifest(namespace, version, normalized_resource, name)
-        return self._get_custom_manifest(
-            group=group,
-            version=version,
-            namespace=namespace,
-            plural=normalized_resource,
-            name=name,
+        ref_namespace = ref.get("namespace")
+        return self._read_custom_object(
+            group,
+            version,
+            _kind_to_plural(kind),
+            ref_name,
+            namespace=str(ref_namespace) if ref_namespace else namespace,
         )
 
-    def list_manifests(
+    def _read_custom_object(
         self,
-        namespace: str,
-        api_version: str,
-        resource: str,
-        label_selector: str | None = None,
-        limit: int = 20,
-    ) -> list[dict[str, object]]:
-        """List namespaced manifests for either core (v1) or custom resources."""
-        parsed = self._parse_api_version(api_version)
-        normalized_resource = resource.strip().lower()
-        if parsed is None or not normalized_resource:
-            return []
-        group, version = parsed
-        if group is None:
-            return self._list_core_manifests(
-                namespace,
-                version,
-                normalized_resource,
-                label_selector=label_selector,
-                limit=limit,
-            )
-        return self._list_custom_manifests(
-            group=group,
-            version=version,
-            namespace=namespace,
-            plural=normalized_resource,
-            label_selector=label_selector,
-            limit=limit,
-        )
-
-    def list_services_by_label(
-        self, label_selector: str, namespaces: list[str] | None = None
-    ) -> list[client.V1Service]:
-        if self._core_api is None or not label_selector:
-            return []
+        group: str,
+        version: str,
+        plural: str,
+        name: str,
+        *,
+        namespace: str | None = None,
+    ) -> dict[str, object] | None:
+        """Read a custom object including its status (namespaced or cluster-scoped)."""
+        if self._custom_api is None:
+            return None
         try:
-            if namespaces:
-                services: list[client.V1Service] = []
-                for namespace in namespaces:
-                    response = self._core_api.list_namespaced_service(
-                        namespace=namespace,
-                        label_selector=label_selector,
-                        _request_timeout=self._timeout_seconds,
-                    )
-                    services.extend(response.items)
-                return services
-            response = self._core_api.list_service_for_all_namespaces(
-                label_selector=label_selector,
-                _request_timeout=self._timeout_seconds,
-            )
-            return response.items
+            if namespace:
+                response = self._custom_api.get_namespaced_custom_object(
+                    group=group,
+                    version=version,
+                    namespace=namespace,
+                    plural=plural,
+                    name=name,
+                    _request_timeout=self._call_timeout(),
+                )
+            else:
+                response = self._custom_api.get_cluster_custom_object(
+                    group=group,
+                    version=version,
+                    plural=plural,
+                    name=name,
+                    _request_timeout=self._call_timeout(),
+                )
+        except DeadlineExceeded:
+            raise
         except Exception as exc:  # noqa: BLE001
-            self._logger.warning("Failed to list services for selector %s: %s", label_selector, exc)
-            return []
+            self._logger.warning(
+                "Failed to read custom resource %s/%s %s/%s: %s",
+                group,
+                plural,
+                namespace or "-",
+                name,
+                exc,
+            )
+            return None
+        return response if isinstance(response, dict) else None
 
-    def list_pods_in_namespace(
-        self, namespace: str, label_selector: str | None = None
-    ) -> list[PodSummary]:
-        """List pods in a namespace with optional label filtering."""
-        if self._core_api is None:
+    def _list_custom_objects(
+        self,
+        group: str,
+        version: str,
+        plural: str,
+        *,
+        namespace: str | None = None,
+        label_selector: str | None = None,
+    ) -> list[dict[str, object]]:
+        """List custom objects including their status."""
+        if self._custom_api is None:
             return []
+        kwargs: dict[str, object] = {
+            "group": group,
+            "version": version,
+            "plural": plural,
+            "_request_timeout": self._timeout_seconds,
+        }
+        if label_selector:
+            kwargs["label_selector"] = label_selector
         try:
-            if label_selector:
-                response = self._core_api.list_namespaced_pod(
-                    namespace=namespace,
-                    label_selector=label_selector,
-                    _request_timeout=self._timeout_seconds,
+            if namespace:
+                response = self._custom_api.list_namespaced_custom_object(
+                    namespace=namespace, **kwargs
                 )
             else:
-                response = self._core_api.list_namespaced_pod(
-                    namespace=namespace,
-                    _request_timeout=self._timeout_seconds,
-                )
-            return [self._to_pod_summary(pod) for pod in response.items]
+                response = self._custom_api.list_cluster_custom_object(**kwargs)
+        except DeadlineExceeded:
+            raise
         except Exception as exc:  # noqa: BLE001
-            self._logger.warning("Failed to list pods in namespace %s: %s", namespace, exc)
+            self._logger.warning(
+                "Failed to list custom resources %s/%s in %s: %s",
+                group,
+                plural,
+                namespace or "cluster",
+                exc,
+            )
+            return []
+        items = response.get("items") if isinstance(response, dict) else None
+        if not isinstance(items, list):
             return []
+        return [item for item in items if isinstance(item, dict)]
+
+    @staticmethod
+    def _summarize_custom_resource(payload: dict[str, object]) -> dict[str, object]:
+        return {
+            "api_version": payload.get("apiVersion"),
+            "kind": payload.get("kind"),
+            "name": _metadata_field(payload, "name"),
+            "namespace": _metadata_field(payload, "namespace"),
+            "conditions": _custom_conditions(payload),
+        }
 
     def _to_pod_summary(self, pod: client.V1Pod) -> PodSummary:
         """Convert V1Pod to PodSummary."""
@@ -526,6 +2680,8 @@ class KubernetesClient:
             if limit > 0:
                 kwargs["limit"] = limit
             response = self._custom_api.list_namespaced_custom_object(**kwargs)
+        except DeadlineExceeded:
+            raise
         except Exception as exc:  # noqa: BLE001
             self._logger.warning(
                 "Failed to list custom resources %s/%s in namespace %s: %s",
@@ -565,8 +2721,10 @@ class KubernetesClient:
                 namespace=namespace,
                 plural=plural,
                 name=name,
-                _request_timeout=self._timeout_seconds,
+                _request_timeout=self._call_timeout(),
             )
+        except DeadlineExceeded:
+            raise
         except Exception as exc:  # noqa: BLE001
             self._logger.warning(
                 "Failed to get custom resource %s/%s %s/%s: %s",
@@ -608,8 +2766,10 @@ class KubernetesClient:
                 response_type="object",
                 _return_http_data_only=False,
                 _preload_content=True,
-                _request_timeout=self._timeout_seconds,
+                _request_timeout=self._call_timeout(),
             )
+        except DeadlineExceeded:
+            raise
         except Exception as exc:  # noqa: BLE001
             self._logger.warning(
                 "Failed to get core resource %s/%s %s/%s: %s",
@@ -649,9 +2809,11 @@ class KubernetesClient:
                 response_type="object",
                 _return_http_data_only=False,
                 _preload_content=True,
-                _request_timeout=self._timeout_seconds,
+                _request_timeout=self._call_timeout(),
                 query_params=query_params,
             )
+        except DeadlineExceeded:
+            raise
         except Exception as exc:  # noqa: BLE001
             self._logger.warning(
                 "Failed to list core resources %s/%s in namespace %s: %s",
@@ -733,8 +2895,10 @@ class KubernetesClient:
             return self._core_api.read_namespaced_pod(
                 name=pod_name,
                 namespace=namespace,
-                _request_timeout=self._timeout_seconds,
+                _request_timeout=self._call_timeout(),
             )
+        except DeadlineExceeded:
+            raise
         except Exception as exc:  # noqa: BLE001 - Kubernetes client raises many exception types.
             self._logger.warning("Failed to read pod %s/%s: %s", namespace, pod_name, exc)
             warnings.append("failed to read pod status")
@@ -745,21 +2909,25 @@ class KubernetesClient:
         namespace: str,
         pod_name: str,
         warnings: list[str],
+        *,
+        kind: str = "Pod",
     ) -> list[PodEventSummary]:
-        field_selector = f"involvedObject.kind=Pod,involvedObject.name={pod_name}"
+        field_selector = f"involvedObject.kind={kind},involvedObject.name={pod_name}"
         try:
             response = self._core_api.list_namespaced_event(
                 namespace=namespace,
                 field_selector=field_selector,
                 limit=self._event_limit,
-                _request_timeout=self._timeout_seconds,
+                _request_timeout=self._call_timeout(),
             )
+        except DeadlineExceeded:
+            raise
         except Exception as exc:  # noqa: BLE001
             self._logger.warning("Failed to list events for %s/%s: %s", namespace, pod_name, exc)
             warnings.append("failed to list events")
             return []
 
-        return [self._to_event_summary(item) for item in response.items]
+        return _prioritize_events([self._to_event_summary(item) for item in response.items])
 
     def _list_namespace_events_core(
         self, namespace: str
@@ -770,8 +2938,10 @@ class KubernetesClient:
             response = self._core_api.list_namespaced_event(
                 namespace=namespace,
                 limit=self._event_limit,
-                _request_timeout=self._timeout_seconds,
+                _request_timeout=self._call_timeout(),
             )
+        except DeadlineExceeded:
+            raise
         except Exception as exc:  # noqa: BLE001
             self._logger.warning("Failed to list core events for %s: %s", namespace, exc)
             return [], f"core events query failed: {exc}"
@@ -784,12 +2954,14 @@ class KubernetesClient:
             raw = self._events_api.list_namespaced_event(
                 namespace=namespace,
                 limit=self._event_limit,
-                _request_timeout=self._timeout_seconds,
+                _request_timeout=self._call_timeout(),
                 _preload_content=False,
             )
             import json as _json
 
             data = _json.loads(raw.data)
+        except DeadlineExceeded:
+            raise
         except Exception as exc:  # noqa: BLE001
             self._logger.warning("Failed to list events.k8s.io for %s: %s", namespace, exc)
             return [], f"events.k8s.io query failed: {exc}"
@@ -797,6 +2969,8 @@ class KubernetesClient:
         for item in data.get("items", []):
             try:
                 summaries.append(self._to_event_summary_v1_raw(item))
+            except DeadlineExceeded:
+                raise
             except Exception:  # noqa: BLE001
                 continue
         return summaries, None
@@ -807,8 +2981,10 @@ class KubernetesClient:
         try:
             response = self._core_api.list_event_for_all_namespaces(
                 limit=self._event_limit,
-                _request_timeout=self._timeout_seconds,
+                _request_timeout=self._call_timeout(),
             )
+        except DeadlineExceeded:
+            raise
         except Exception as exc:  # noqa: BLE001
             self._logger.warning("Failed to list core cluster events: %s", exc)
             return [], f"core cluster events query failed: {exc}"
@@ -820,8 +2996,10 @@ class KubernetesClient:
         try:
             response = self._events_api.list_event_for_all_namespaces(
                 limit=self._event_limit,
-                _request_timeout=self._timeout_seconds,
+                _request_timeout=self._call_timeout(),
             )
+        except DeadlineExceeded:
+            raise
         except Exception as exc:  # noqa: BLE001
             self._logger.warning("Failed to list events.k8s.io cluster events: %s", exc)
             return [], f"events.k8s.io cluster events query failed: {exc}"
@@ -850,12 +3028,24 @@ class KubernetesClient:
         pod: client.V1Pod | None,
         warnings: list[str],
     ) -> list[PodLogSnippet]:
+        """Logs of the last terminated instance of each restarted container.
+
+        When the kubelet no longer has them (rotated, or the container was
+        garbage collected) or they are empty, the current container's logs are
+        returned instead, with ``previous=False`` and a note.
+        """
         if pod is None:
             return []
 
-        container_names = [container.name for container in pod.spec.containers or []]
+        status = getattr(pod, "status", None)
+        statuses = (status.container_statuses if status else None) or []
+        restarts = {item.name: item.restart_count for item in statuses}
         snippets: list[PodLogSnippet] = []
-        for container_name in container_names:
+        for container in pod.spec.containers or []:
+            container_name = container.name
+            # Without a restart there is no previous container to read.
+            if restarts.get(container_name, 1) == 0:
+                continue
             try:
                 logs = self._core_api.read_namespaced_pod_log(
                     name=pod.metadata.name,
@@ -864,32 +3054,57 @@ class KubernetesClient:
                     previous=True,
                     tail_lines=self._log_tail_lines,
                     timestamps=True,
-                    _request_timeout=self._timeout_seconds,
-                )
-                snippets.append(
-                    PodLogSnippet(
-                        container=container_name,
-                        previous=True,
-                        logs=logs.splitlines() if logs else [],
-                    )
+                    _request_timeout=self._call_timeout(),
                 )
+            except DeadlineExceeded:
+                raise
             except Exception as exc:  # noqa: BLE001
-                self._logger.warning(
-                    "Failed to read previous logs for %s/%s (%s): %s",
-                    namespace,
-                    pod.metadata.name,
-                    container_name,
-                    exc,
-                )
-                warnings.append(f"failed to read previous logs for container {container_name}")
+                if getattr(exc, "status", None) not in _PREVIOUS_LOGS_GONE_STATUSES:
+                    self._logger.warning(
+                        "Failed to read previous logs for %s/%s (%s): %s",
+                        namespace,
+                        pod.metadata.name,
+                        container_name,
+                        exc,
+                    )
+                    warnings.append(
+                        f"failed to read previous logs for container {container_name}"
+                    )
+                    snippets.append(
+                        PodLogSnippet(
+                            container=container_name,
+                            previous=True,
+                            logs=[],
+                            error="failed to read previous logs",
+                        )
+                    )
+                    continue
+                logs = ""
+            if logs:
                 snippets.append(
                     PodLogSnippet(
                         container=container_name,
                         previous=True,
-                        logs=[],
-                        error="failed to read previous logs",
+                        logs=logs.splitlines(),
                     )
                 )
+                continue
+            warnings.append(
+                f"previous logs of container {container_name} are empty or rotated; "
+                "using the current container logs"
+            )
+            [fallback] = self._get_current_logs(
+                namespace, pod, container=container_name, tail_lines=None, since_seconds=None
+            )
+            snippets.append(
+                PodLogSnippet(
+                    container=container_name,
+                    previous=False,
+                    logs=fallback.logs,
+                    error=fallback.error,
+                    note=_PREVIOUS_LOGS_GONE_NOTE,
+                )
+            )
         return snippets
 
     def _get_current_logs(
@@ -923,7 +3138,7 @@ class KubernetesClient:
                     tail_lines=effective_tail_lines,
                     since_seconds=since_seconds,
                     timestamps=True,
-                    _request_timeout=self._timeout_seconds,
+                    _request_timeout=self._call_timeout(),
                 )
                 snippets.append(
                     PodLogSnippet(
@@ -932,6 +3147,8 @@ class KubernetesClient:
                         logs=logs.splitlines() if logs else [],
                     )
                 )
+            except DeadlineExceeded:
+                raise
             except Exception as exc:  # noqa: BLE001
                 self._logger.warning(
                     "Failed to read logs for %s/%s (%s): %s",
@@ -1053,8 +3270,10 @@ class KubernetesClient:
                 replica_set = self._apps_api.read_namespaced_replica_set(
                     name=owner_reference.name,
                     namespace=namespace,
-                    _request_timeout=self._timeout_seconds,
+                    _request_timeout=self._call_timeout(),
                 )
+            except DeadlineExceeded:
+                raise
             except Exception as exc:  # noqa: BLE001
                 self._logger.warning(
                     "Failed to read ReplicaSet %s/%s: %s",
@@ -1073,8 +3292,10 @@ class KubernetesClient:
                 job = self._batch_api.read_namespaced_job(
                     name=owner_reference.name,
                     namespace=namespace,
-                    _request_timeout=self._timeout_seconds,
+                    _request_timeout=self._call_timeout(),
                 )
+            except DeadlineExceeded:
+                raise
             except Exception as exc:  # noqa: BLE001
                 self._logger.warning(
                     "Failed to read Job %s/%s: %s",
@@ -1252,17 +3473,6 @@ class KubernetesClient:
             }
             for condition in status.conditions or []
         ]
-        container_statuses = [
-            {
-                "name": container_status.name,
-                "ready": container_status.ready,
-                "restart_count": container_status.restart_count,
-                "state": self._extract_container_state(container_status.state),
-                "last_state": self._extract_container_state(container_status.last_state),
-            }
-            for container_status in status.container_statuses or []
-        ]
-
         return PodStatusSnapshot(
             phase=status.phase or "",
             node_name=pod.spec.node_name,
@@ -1270,13 +3480,30 @@ class KubernetesClient:
             reason=status.reason,
             message=status.message,
             conditions=conditions,
-            container_statuses=container_statuses,
+            container_statuses=self._extract_container_statuses(status.container_statuses),
+            init_container_statuses=self._extract_container_statuses(
+                getattr(status, "init_container_statuses", None)
+            ),
         )
 
+    def _extract_container_statuses(
+        self, statuses: list[client.V1ContainerStatus] | None
+    ) -> list[dict[str, object]]:
+        return [
+            {
+                "name": container_status.name,
+                "ready": container_status.ready,
+                "restart_count": container_status.restart_count,
+                "state": self._extract_container_state(container_status.state),
+                "last_state": self._extract_container_state(container_status.last_state),
+            }
+            for container_status in statuses or []
+        ]
+
     def _extract_container_state(
         self,
         state: client.V1ContainerState | None,
-    ) -> dict[str, str] | None:
+    ) -> dict[str, str | None] | None:
         if state is None:
             return None
         if state.waiting:
@@ -1291,6 +3518,7 @@ class KubernetesClient:
                 "reason": state.terminated.reason,
                 "message": state.terminated.message,
                 "exit_code": str(state.terminated.exit_code),
+                "finished_at": self._to_iso(getattr(state.terminated, "finished_at", None)),
             }
         if state.running:
             return {
@@ -1419,6 +3647,353 @@ def resolve_alert_target(labels: dict[str, str]) -> AnalysisTarget:
     )
 
 
+def _wrap_api(api: Any, group: str | None) -> Any:
+    # The access scope is checked before chaos faults are injected; caller
+    # credentials replace the API client underneath both.
+    return wrap_with_scope(wrap_with_faults(wrap_with_credentials(api), "k8s"), group)
+
+
+_COMPONENT_LOG_SCAN_LINES = 1000
+# The kubelet answers 400 when the terminated container (and its logs) is gone.
+_PREVIOUS_LOGS_GONE_STATUSES = frozenset({400, 404})
+_PREVIOUS_LOGS_GONE_NOTE = "previous container logs empty or rotated by the kubelet"
+_ENDPOINT_POD_LIMIT = 50
+_JOB_POD_LIMIT = 20
+_CRON_JOB_RUN_LIMIT = 5
+_NEAR_MISS_LIMIT = 10
+# Readiness probe failures are read from the events of the first not-ready pods only.
+_PROBE_EVENT_PODS = 3
+_DNS_LOG_PODS = 3
+_DNS_LOG_SAMPLES = 5
+# Containers serving DNS in CoreDNS and legacy kube-dns pods.
+_DNS_CONTAINERS = ("coredns", "kubedns", "dnsmasq")
+_REMOVED_API_EVENT_MARKERS = (
+    "no matches for kind",
+    "the server could not find the requested resource",
+)
+# Kubelets may be up to three minor versions older than the API server, never newer.
+_MAX_KUBELET_MINOR_SKEW = 3
+_NODE_EVENT_LIMIT = 200
+_NODE_LIFECYCLE_REASONS = frozenset(
+    {
+        "NodeNotSchedulable",
+        "NodeSchedulable",
+        "NodeNotReady",
+        "NodeReady",
+        "RegisteredNode",
+        "RemovingNode",
+        "DeletingNode",
+        "Rebooted",
+        "NodeUpgrade",
+        "Upgrade",
+        "Drain",
+        "ScaleDown",
+        "TerminatingNode",
+    }
+)
+# Taints set on nodes that are being drained or replaced on purpose.
+_MAINTENANCE_TAINTS = {
+    "ToBeDeletedByClusterAutoscaler": "scale-down by the cluster autoscaler",
+    "DeletionCandidateOfClusterAutoscaler": "scale-down candidate of the cluster autoscaler",
+    "karpenter.sh/disrupted": "Karpenter disruption (consolidation, drift or expiry)",
+    "karpenter.sh/disruption": "Karpenter disruption (consolidation, drift or expiry)",
+    "aws-node-termination-handler/scheduled-maintenance": "AWS scheduled maintenance event",
+    "aws-node-termination-handler/spot-itn": "AWS spot interruption notice",
+    "aws-node-termination-handler/asg-lifecycle-termination": "AWS ASG lifecycle termination",
+    "aws-node-termination-handler/rebalance-recommendation": "AWS rebalance recommendation",
+    "cloud.google.com/impending-node-termination": "GCE impending node termination",
+}
+_MAINTENANCE_CONDITIONS = frozenset({"VMEventScheduled"})
+_BUILD_ANNOTATION_PREFIXES = ("org.opencontainers.image.", "org.label-schema.")
+_CROSSPLANE_MAX_MANAGED = 50
+_CROSSPLANE_HEALTH_CONDITIONS = frozenset({"Ready", "Synced"})
+_KEDA_MAX_SCALED_OBJECTS = 10
+_KNATIVE_MAX_REVISIONS = 5
+# operation -> (plural, pod volume plural, CSI data mover plural, name label)
+_VELERO_OPERATIONS = {
+    "backup": ("backups", "podvolumebackups", "datauploads", "velero.io/backup-name"),
+    "restore": ("restores", "podvolumerestores", "datadownloads", "velero.io/restore-name"),
+}
+_VELERO_FAILED_PHASES = frozenset({"Failed", "PartiallyFailed", "FailedValidation"})
+_VELERO_SERVER_SELECTORS = ("component=velero", "app.kubernetes.io/name=velero")
+_VELERO_MAX_OPERATIONS = 5
+_VELERO_MAX_ERROR_LINES = 20
+_EXTERNAL_DNS_SELECTORS = ("app.kubernetes.io/name=external-dns", "app=external-dns")
+_EXTERNAL_DNS_PATTERNS = {
+    "rate_limited": (
+        "throttl",
+        "rate exceeded",
+        "rate limit",
+        "toomanyrequests",
+        "status code: 429",
+    ),
+    "permission_denied": (
+        "accessdenied",
+        "access denied",
+        "forbidden",
+        "not authorized",
+        "unauthorized",
+        "permission",
+        "status code: 403",
+    ),
+}
+_EXTERNAL_DNS_MAX_LINES = 10
+
+
+def _container_failed(container: dict[str, object]) -> bool:
+    """Whether a Job pod container waits on an error or exited non-zero (now or last time)."""
+    for key in ("state", "last_state"):
+        state = container.get(key)
+        if not isinstance(state, dict):
+            continue
+        if state.get("type") == "terminated" and state.get("exit_code") not in (None, "0"):
+            return True
+        if key == "state" and state.get("type") == "waiting":
+            return state.get("reason") not in (None, "ContainerCreating", "PodInitializing")
+    return False
+
+
+def _prioritize_events(events: list[PodEventSummary]) -> list[PodEventSummary]:
+    """Warning events first, each group newest first by lastTimestamp."""
+    newest_first = sorted(
+        events, key=lambda event: event.last_timestamp or event.first_timestamp or "", reverse=True
+    )
+    return sorted(newest_first, key=lambda event: event.type != "Warning")
+
+
+def _metadata_field(payload: dict[str, object], key: str) -> str | None:
+    metadata = payload.get("metadata")
+    value = metadata.get(key) if isinstance(metadata, dict) else None
+    return str(value) if value else None
+
+
+def _custom_conditions(payload: dict[str, object]) -> list[dict[str, object]]:
+    status = payload.get("status")
+    conditions = status.get("conditions") if isinstance(status, dict) else None
+    return [
+        {
+            "type": condition.get("type"),
+            "status": condition.get("status"),
+            "reason": condition.get("reason"),
+            "message": condition.get("message"),
+            "last_transition_time": condition.get("lastTransitionTime"),
+        }
+        for condition in (conditions if isinstance(conditions, list) else [])
+        if isinstance(condition, dict)
+    ]
+
+
+def _crossplane_spec_field(payload: dict[str, object], key: str) -> object:
+    spec = payload.get("spec")
+    if not isinstance(spec, dict):
+        return None
+    if key in spec:
+        return spec[key]
+    # Crossplane v2 moves machinery fields under spec.crossplane.
+    nested = spec.get("crossplane")
+    return nested.get(key) if isinstance(nested, dict) else None
+
+
+def _crossplane_healthy(node: dict[str, object]) -> bool:
+    conditions = node.get("conditions")
+    if not isinstance(conditions, list):
+        return True
+    return all(
+        condition.get("status") == "True"
+        for condition in conditions
+        if isinstance(condition, dict) and condition.get("type") in _CROSSPLANE_HEALTH_CONDITIONS
+    )
+
+
+def _unhealthy_view(node: dict[str, object]) -> dict[str, object]:
+    conditions = node.get("conditions")
+    return {
+        "kind": node.get("kind"),
+        "name": node.get("name"),
+        "namespace": node.get("namespace"),
+        "failing_conditions": [
+            condition
+            for condition in (conditions if isinstance(conditions, list) else [])
+            if isinstance(condition, dict) and condition.get("status") != "True"
+        ],
+    }
+
+
+def _velero_status(payload: dict[str, object]) -> dict[str, object]:
+    status = payload.get("status")
+    return status if isinstance(status, dict) else {}
+
+
+def _velero_volume_failure(payload: dict[str, object]) -> dict[str, object]:
+    spec = payload.get("spec")
+    spec = spec if isinstance(spec, dict) else {}
+    pod = spec.get("pod")
+    status = _velero_status(payload)
+    return {
+        "kind": payload.get("kind"),
+        "name": _metadata_field(payload, "name"),
+        "pod": f"{pod.get('namespace')}/{pod.get('name')}" if isinstance(pod, dict) else None,
+        "volume": spec.get("volume") or spec.get("sourcePVC"),
+        "phase": status.get("phase"),
+        "message": status.get("message"),
+    }
+
+
+def _resolve_record(hostname: str) -> dict[str, object]:
+    try:
+        infos = socket.getaddrinfo(hostname, None)
+    except OSError as exc:
+        return {"hostname": hostname, "resolved": False, "error": str(exc)}
+    addresses = sorted({str(info[4][0]) for info in infos})
+    return {"hostname": hostname, "resolved": True, "addresses": addresses}
+
+
+def _minor_version(version: str | None) -> int | None:
+    match = re.match(r"v?(\d+)\.(\d+)", version or "")
+    if match is None or match.group(1) != "1":
+        return None
+    return int(match.group(2))
+
+
+def _decode_helm_release(encoded: str | None) -> dict[str, object] | None:
+    # Secret data is base64 on the wire; Helm itself stores base64(gzip(json)).
+    if not encoded:
+        return None
+    try:
+        payload = base64.b64decode(base64.b64decode(encoded))
+        if payload[:2] == b"\x1f\x8b":
+            payload = gzip.decompress(payload)
+        release = json.loads(payload)
+    except (ValueError, OSError):
+        return None
+    return release if isinstance(release, dict) else None
+
+
+_QUANTITY_SUFFIXES = {
+    "Ki": 2**10,
+    "Mi": 2**20,
+    "Gi": 2**30,
+    "Ti": 2**40,
+    "Pi": 2**50,
+    "Ei": 2**60,
+    "n": 1e-9,
+    "u": 1e-6,
+    "m": 1e-3,
+    "k": 1e3,
+    "M": 1e6,
+    "G": 1e9,
+    "T": 1e12,
+    "P": 1e15,
+    "E": 1e18,
+}
+_QUANTITY_RE = re.compile(r"^([+-]?[0-9.]+(?:[eE][+-]?[0-9]+)?)([a-zA-Z]*)$")
+
+
+def parse_quantity(value: str) -> float | None:
+    """Parse a Kubernetes resource quantity (e.g. '500m', '2Gi', '10') to a float."""
+    match = _QUANTITY_RE.match(value.strip())
+    if match is None:
+        return None
+    number, suffix = match.groups()
+    if suffix and suffix not in _QUANTITY_SUFFIXES:
+        return None
+    try:
+        return float(number) * _QUANTITY_SUFFIXES.get(suffix, 1)
+    except ValueError:
+        return None
+
+
+_OWNER_CHAIN_DEPTH = 5
+_APPS_OWNER_READERS = {
+    "ReplicaSet": "read_namespaced_replica_set",
+    "Deployment": "read_namespaced_deployment",
+    "StatefulSet": "read_namespaced_stateful_set",
+    "DaemonSet": "read_namespaced_daemon_set",
+}
+_BATCH_OWNER_READERS = {"Job": "read_namespaced_job", "CronJob": "read_namespaced_cron_job"}
+
+
+def _workload_replicas(kind: str, workload: Any) -> dict[str, object]:
+    status = workload.status
+    if kind == "DaemonSet":
+        desired = status.desired_number_scheduled if status else None
+        ready = status.number_ready if status else None
+        available = status.number_available if status else None
+        updated = status.updated_number_scheduled if status else None
+    else:
+        desired = workload.spec.replicas if workload.spec else None
+        ready = status.ready_replicas if status else None
+        available = status.available_replicas if status else None
+        updated = status.updated_replicas if status else None
+    desired = 1 if desired is None and kind != "DaemonSet" else desired or 0
+    return {
+        "kind": kind,
+        "name": workload.metadata.name if workload.metadata else None,
+        "desired": desired,
+        "ready": ready or 0,
+        "available": available or 0,
+        "updated": updated or 0,
+        "healthy": (ready or 0) >= desired and (available or 0) >= desired,
+    }
+
+
+def _failing_pod(pod: Any) -> dict[str, object] | None:
+    """Summary of a pod that is not running all its containers ready; ``None`` otherwise."""
+    status = pod.status
+    phase = status.phase if status else None
+    if phase == "Succeeded":
+        return None
+    statuses = (status.container_statuses if status else None) or []
+    reasons: list[str] = []
+    for item in statuses:
+        state = item.state
+        if state is not None and state.waiting is not None and state.waiting.reason:
+            reasons.append(f"{item.name}: {state.waiting.reason}")
+        elif state is not None and state.terminated is not None and state.terminated.reason:
+            reasons.append(f"{item.name}: {state.terminated.reason}")
+    ready = sum(1 for item in statuses if item.ready)
+    if phase == "Running" and not reasons and statuses and ready == len(statuses):
+        return None
+    return {
+        "name": pod.metadata.name if pod.metadata else None,
+        "phase": phase,
+        "reason": status.reason if status else None,
+        "ready": f"{ready}/{len(statuses)}",
+        "restarts": sum(item.restart_count or 0 for item in statuses),
+        "container_reasons": reasons,
+        "node": pod.spec.node_name if pod.spec else None,
+    }
+
+
+def _owner_reference_dict(reference: object) -> dict[str, object]:
+    # Typed clients return V1OwnerReference, custom objects plain camelCase dicts.
+    if isinstance(reference, dict):
+        return {
+            "kind": reference.get("kind"),
+            "name": reference.get("name"),
+            "api_version": reference.get("apiVersion"),
+            "uid": reference.get("uid"),
+            "controller": reference.get("controller") is True,
+        }
+    return {
+        "kind": getattr(reference, "kind", None),
+        "name": getattr(reference, "name", None),
+        "api_version": getattr(reference, "api_version", None),
+        "uid": getattr(reference, "uid", None),
+        "controller": getattr(reference, "controller", None) is True,
+    }
+
+
+def _kind_to_plural(kind: str) -> str:
+    # Custom resources almost always use the lowercase English plural of the kind.
+    lowered = kind.lower()
+    if lowered.endswith("y") and lowered[-2:-1] not in "aeiou":
+        return f"{lowered[:-1]}ies"
+    if lowered.endswith(("s", "x", "ch", "sh")):
+        return f"{lowered}es"
+    return f"{lowered}s"
+
+
 _SENTINEL_VALUES = frozenset({"unknown", "none", ""})
 
 
@@ -1428,3 +4003,66 @@ def _first_label_value(labels: dict[str, str], keys: Iterable[str]) -> str | Non
         if value and value.lower() not in _SENTINEL_VALUES:
             return value
     return None
+
+
+def _summarize_label_selector(
+    selector: client.V1LabelSelector | None,
+) -> dict[str, object] | None:
+    if selector is None:
+        return None
+    return {
+        "match_labels": dict(selector.match_labels or {}),
+        "match_expressions": [
+            {"key": item.key, "operator": item.operator, "values": list(item.values or [])}
+            for item in selector.match_expressions or []
+        ],
+    }
+
+
+def _summarize_policy_peer(peer: client.V1NetworkPolicyPeer) -> dict[str, object]:
+    ip_block = peer.ip_block
+    return {
+        "pod_selector": _summarize_label_selector(peer.pod_selector),
+        "namespace_selector": _summarize_label_selector(peer.namespace_selector),
+        "ip_block": (
+            {"cidr": ip_block.cidr, "except": list(ip_block._except or [])} if ip_block else None
+        ),
+    }
+
+
+def _summarize_policy_port(port: client.V1NetworkPolicyPort) -> dict[str, object]:
+    return {"protocol": port.protocol or "TCP", "port": port.port, "end_port": port.end_port}
+
+
+def _summarize_endpoint(endpoint: client.V1Endpoint) -> dict[str, object]:
+    conditions = endpoint.conditions
+    target = endpoint.target_ref
+    return {
+        "addresses": list(endpoint.addresses or []),
+        # A nil ready condition means ready, see the EndpointConditions API.
+        "ready": conditions.ready is not False if conditions else True,
+        "serving": conditions.serving if conditions else None,
+        "terminating": bool(conditions.terminating) if conditions else False,
+        "pod": target.name if target and target.kind == "Pod" else None,
+        "node": endpoint.node_name,
+        "zone": endpoint.zone,
+    }
+
+
+def _summarize_probe(probe: client.V1Probe | None) -> dict[str, object] | None:
+    if probe is None:
+        return None
+    summary: dict[str, object] = {
+        "period_seconds": probe.period_seconds,
+        "timeout_seconds": probe.timeout_seconds,
+        "failure_threshold": probe.failure_threshold,
+    }
+    if probe.http_get is not None:
+        summary.update(type="httpGet", path=probe.http_get.path, port=probe.http_get.port)
+    elif probe.tcp_socket is not None:
+        summary.update(type="tcpSocket", port=probe.tcp_socket.port)
+    elif getattr(probe, "grpc", None) is not None:
+        summary.update(type="grpc", port=probe.grpc.port)
+    elif probe._exec is not None:
+        summary.update(type="exec", command=list(probe._exec.command or []))
+    return summary
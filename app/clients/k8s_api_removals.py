@@ -0,0 +1,116 @@
+"""Known Kubernetes API removals used to flag manifests that no longer apply.
+
+Sourced from the upstream deprecated API migration guide. Only removals are
+listed; ``removed_in`` is the Kubernetes minor version (1.x) that stopped
+serving the API.
+"""
+
+from __future__ import annotations
+
+import re
+from dataclasses import dataclass
+
+
+@dataclass(frozen=True)
+class ApiRemoval:
+    api_version: str
+    kind: str
+    removed_in: int
+    replacement: str | None
+
+
+_APPS_KINDS = ("Deployment", "DaemonSet", "ReplicaSet", "StatefulSet")
+
+API_REMOVALS: tuple[ApiRemoval, ...] = (
+    *(ApiRemoval("extensions/v1beta1", kind, 16, "apps/v1") for kind in _APPS_KINDS),
+    *(ApiRemoval("apps/v1beta1", kind, 16, "apps/v1") for kind in _APPS_KINDS),
+    *(ApiRemoval("apps/v1beta2", kind, 16, "apps/v1") for kind in _APPS_KINDS),
+    ApiRemoval("extensions/v1beta1", "NetworkPolicy", 16, "networking.k8s.io/v1"),
+    ApiRemoval("extensions/v1beta1", "PodSecurityPolicy", 16, "policy/v1beta1"),
+    ApiRemoval("extensions/v1beta1", "Ingress", 22, "networking.k8s.io/v1"),
+    ApiRemoval("networking.k8s.io/v1beta1", "Ingress", 22, "networking.k8s.io/v1"),
+    ApiRemoval("networking.k8s.io/v1beta1", "IngressClass", 22, "networking.k8s.io/v1"),
+    *(
+        ApiRemoval("rbac.authorization.k8s.io/v1beta1", kind, 22, "rbac.authorization.k8s.io/v1")
+        for kind in ("ClusterRole", "ClusterRoleBinding", "Role", "RoleBinding")
+    ),
+    ApiRemoval(
+        "apiextensions.k8s.io/v1beta1", "CustomResourceDefinition", 22, "apiextensions.k8s.io/v1"
+    ),
+    *(
+        ApiRemoval(
+            "admissionregistration.k8s.io/v1beta1", kind, 22, "admissionregistration.k8s.io/v1"
+        )
+        for kind in ("MutatingWebhookConfiguration", "ValidatingWebhookConfiguration")
+    ),
+    ApiRemoval("apiregistration.k8s.io/v1beta1", "APIService", 22, "apiregistration.k8s.io/v1"),
+    ApiRemoval(
+        "certificates.k8s.io/v1beta1", "CertificateSigningRequest", 22, "certificates.k8s.io/v1"
+    ),
+    ApiRemoval("coordination.k8s.io/v1beta1", "Lease", 22, "coordination.k8s.io/v1"),
+    *(
+        ApiRemoval("storage.k8s.io/v1beta1", kind, 22, "storage.k8s.io/v1")
+        for kind in ("CSIDriver", "CSINode", "StorageClass", "VolumeAttachment")
+    ),
+    ApiRemoval("batch/v1beta1", "CronJob", 25, "batch/v1"),
+    ApiRemoval("discovery.k8s.io/v1beta1", "EndpointSlice", 25, "discovery.k8s.io/v1"),
+    ApiRemoval("events.k8s.io/v1beta1", "Event", 25, "events.k8s.io/v1"),
+    ApiRemoval("autoscaling/v2beta1", "HorizontalPodAutoscaler", 25, "autoscaling/v2"),
+    ApiRemoval("policy/v1beta1", "PodDisruptionBudget", 25, "policy/v1"),
+    # No replacement API: migrate to Pod Security Admission.
+    ApiRemoval("policy/v1beta1", "PodSecurityPolicy", 25, None),
+    ApiRemoval("node.k8s.io/v1beta1", "RuntimeClass", 25, "node.k8s.io/v1"),
+    ApiRemoval("autoscaling/v2beta2", "HorizontalPodAutoscaler", 26, "autoscaling/v2"),
+    *(
+        ApiRemoval(f"flowcontrol.apiserver.k8s.io/{version}", kind, removed_in, replacement)
+        for version, removed_in, replacement in (
+            ("v1beta1", 26, "flowcontrol.apiserver.k8s.io/v1beta3"),
+            ("v1beta2", 29, "flowcontrol.apiserver.k8s.io/v1"),
+            ("v1beta3", 32, "flowcontrol.apiserver.k8s.io/v1"),
+        )
+        for kind in ("FlowSchema", "PriorityLevelConfiguration")
+    ),
+    ApiRemoval("storage.k8s.io/v1beta1", "CSIStorageCapacity", 27, "storage.k8s.io/v1"),
+)
+
+_REMOVALS_BY_KEY = {(item.api_version, item.kind): item for item in API_REMOVALS}
+_API_VERSION_RE = re.compile(r"^apiVersion:\s*['\"]?([\w./-]+)['\"]?\s*$", re.MULTILINE)
+_KIND_RE = re.compile(r"^kind:\s*['\"]?(\w+)['\"]?\s*$", re.MULTILINE)
+
+
+def find_removed_apis(
+    resources: list[tuple[str, str]], cluster_minor: int | None
+) -> list[dict[str, object]]:
+    """Match (apiVersion, kind) pairs against known removals.
+
+    ``status`` is ``removed`` when the cluster no longer serves the API and
+    ``deprecated`` when it will be removed in a later version. With an unknown
+    cluster version every match is reported as ``deprecated``.
+    """
+    matches: list[dict[str, object]] = []
+    for api_version, kind in sorted(set(resources)):
+        removal = _REMOVALS_BY_KEY.get((api_version, kind))
+        if removal is None:
+            continue
+        removed = cluster_minor is not None and cluster_minor >= removal.removed_in
+        matches.append(
+            {
+                "api_version": api_version,
+                "kind": kind,
+                "status": "removed" if removed else "deprecated",
+                "removed_in": f"1.{removal.removed_in}",
+                "replacement": removal.replacement,
+            }
+        )
+    return matches
+
+
+def manifest_resources(manifest: str) -> list[tuple[str, str]]:
+    """Extract top-level (apiVersion, kind) pairs from a multi-document YAML string."""
+    resources: list[tuple[str, str]] = []
+    for document in re.split(r"^---\s*$", manifest, flags=re.MULTILINE):
+        api_version = _API_VERSION_RE.search(document)
+        kind = _KIND_RE.search(document)
+        if api_version and kind:
+            resources.append((api_version.group(1), kind.group(1)))
+    return resources
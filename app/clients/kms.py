@@ -0,0 +1,84 @@
+"""Key management services that wrap the data keys of encryption at rest."""
+
+from __future__ import annotations
+
+import base64
+from typing import Any
+
+from app.core.egress import check_egress
+from app.core.encryption import DataKeyWrapper
+from app.core.secret_sources import vault_api
+
+
+class AwsKmsKeyWrapper:
+    """AWS KMS key via boto3.
+
+    Credentials come from the default boto3 chain (IRSA, instance profile,
+    ``AWS_*`` variables); the role needs ``kms:Encrypt`` and ``kms:Decrypt``.
+    """
+
+    def __init__(self, key_id: str, *, endpoint_url: str = "") -> None:
+        import boto3  # type: ignore[import-not-found]
+
+        self._key_id = key_id
+        self._client: Any = boto3.client("kms", endpoint_url=endpoint_url or None)
+
+    def wrap(self, data_key: bytes) -> bytes:
+        check_egress(self._client.meta.endpoint_url)
+        response = self._client.encrypt(KeyId=self._key_id, Plaintext=data_key)
+        return bytes(response["CiphertextBlob"])
+
+    def unwrap(self, wrapped: bytes) -> bytes:
+        check_egress(self._client.meta.endpoint_url)
+        response = self._client.decrypt(KeyId=self._key_id, CiphertextBlob=wrapped)
+        return bytes(response["Plaintext"])
+
+
+class VaultTransitKeyWrapper:
+    """Key of the Vault transit engine, with the Vault login of ``vault:`` references."""
+
+    def __init__(self, key_name: str, *, mount: str = "transit") -> None:
+        self._key_name = key_name
+        self._mount = mount.strip().strip("/") or "transit"
+
+    def wrap(self, data_key: bytes) -> bytes:
+        payload = vault_api(
+            "ENCRYPTION_KMS_KEY_ID",
+            f"{self._mount}/encrypt/{self._key_name}",
+            {"plaintext": base64.b64encode(data_key).decode("ascii")},
+        )
+        return _transit_value(payload, "ciphertext").encode("ascii")
+
+    def unwrap(self, wrapped: bytes) -> bytes:
+        payload = vault_api(
+            "ENCRYPTION_KMS_KEY_ID",
+            f"{self._mount}/decrypt/{self._key_name}",
+            {"ciphertext": wrapped.decode("ascii")},
+        )
+        return base64.b64decode(_transit_value(payload, "plaintext"))
+
+
+def _transit_value(payload: dict[str, object], key: str) -> str:
+    data = payload.get("data")
+    value = data.get(key) if isinstance(data, dict) else None
+    if not isinstance(value, str) or not value:
+        raise ValueError(f"vault transit returned no {key}")
+    return value
+
+
+def build_key_wrapper(
+    provider: str, key_id: str, *, endpoint_url: str = "", vault_mount: str = "transit"
+) -> DataKeyWrapper | None:
+    """KMS for envelope encryption (``aws`` or ``vault``), ``None`` without a provider."""
+    if not provider:
+        return None
+    if not key_id:
+        raise ValueError("ENCRYPTION_KMS_KEY_ID is required with ENCRYPTION_KMS_PROVIDER")
+    if provider == "aws":
+        try:
+            return AwsKmsKeyWrapper(key_id, endpoint_url=endpoint_url)
+        except ImportError as exc:
+            raise ValueError(f"the aws KMS provider needs the 'archive-s3' extra: {exc}") from exc
+    if provider == "vault":
+        return VaultTransitKeyWrapper(key_id, mount=vault_mount)
+    raise ValueError(f"unknown ENCRYPTION_KMS_PROVIDER {provider!r} (expected aws or vault)")
@@ -0,0 +1,101 @@
+from __future__ import annotations
+
+import logging
+import math
+import re
+import urllib.request
+from dataclasses import dataclass
+from datetime import datetime
+
+from app.core.config import Settings
+from app.core.egress import check_egress
+from app.core.tls import open_url
+
+_SAMPLE_RE = re.compile(r"^([a-zA-Z_:][a-zA-Z0-9_:]*)(?:\{(.*)\})?\s+(\S+)")
+_LABEL_RE = re.compile(r'([a-zA-Z_][a-zA-Z0-9_]*)="((?:[^"\\]|\\.)*)"')
+_LABEL_UNESCAPES = {"\\\\": "\\", '\\"': '"', "\\n": "\n"}
+
+
+@dataclass(frozen=True)
+class SeriesSelector:
+    """Series named one of ``names`` whose labels equal ``labels``."""
+
+    names: tuple[str, ...]
+    labels: tuple[tuple[str, str], ...]
+
+    def matches(self, name: str, labels: dict[str, str]) -> bool:
+        return name in self.names and all(labels.get(key) == value for key, value in self.labels)
+
+
+@dataclass(frozen=True)
+class StateSample:
+    name: str
+    labels: dict[str, str]
+    value: float
+
+
+class KubeStateMetricsClient:
+    """Scrapes the kube-state-metrics ``/metrics`` endpoint directly.
+
+    Used when Prometheus does not scrape kube-state-metrics (or is not
+    configured); the samples are the current object state, so the ``at``
+    argument of :meth:`series` is ignored.
+    """
+
+    source = "kube-state-metrics"
+
+    def __init__(self, settings: Settings) -> None:
+        self._logger = logging.getLogger(__name__)
+        self._url = settings.kube_state_metrics_url.strip()
+        self._timeout_seconds = settings.kube_state_metrics_timeout_seconds
+
+    @property
+    def enabled(self) -> bool:
+        return bool(self._url)
+
+    def series(
+        self, selectors: list[SeriesSelector], *, at: datetime | None = None
+    ) -> list[StateSample]:
+        """Samples of the scrape matching any of *selectors*.
+
+        Raises ``OSError`` when the endpoint cannot be scraped.
+        """
+        wanted = {name for selector in selectors for name in selector.names}
+        request = urllib.request.Request(
+            self._url, headers={"Accept": "text/plain", "User-Agent": "kube-rca-agent"}
+        )
+        check_egress(self._url)
+        with open_url(request, timeout=self._timeout_seconds) as response:
+            body = response.read().decode("utf-8", errors="replace")
+        samples: list[StateSample] = []
+        for line in body.splitlines():
+            if not line or line.startswith("#"):
+                continue
+            name = line.split("{", 1)[0].split(" ", 1)[0]
+            if name not in wanted:
+                continue
+            sample = parse_sample(line)
+            if sample is not None and any(
+                selector.matches(sample.name, sample.labels) for selector in selectors
+            ):
+                samples.append(sample)
+        return samples
+
+
+def parse_sample(line: str) -> StateSample | None:
+    """One sample line of the Prometheus text exposition format."""
+    match = _SAMPLE_RE.match(line.strip())
+    if match is None:
+        return None
+    name, raw_labels, raw_value = match.groups()
+    try:
+        value = float(raw_value)
+    except ValueError:
+        return None
+    if math.isnan(value):
+        return None
+    labels = {
+        key: re.sub(r"\\[\\\"n]", lambda item: _LABEL_UNESCAPES[item.group(0)], raw)
+        for key, raw in _LABEL_RE.findall(raw_labels or "")
+    }
+    return StateSample(name=name, labels=labels, value=value)
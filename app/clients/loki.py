@@ -7,7 +7,10 @@ import urllib.parse
 import urllib.request
 from dataclasses import dataclass
 
+from app.core.chaos import maybe_inject_fault
 from app.core.config import Settings
+from app.core.egress import check_egress
+from app.core.tls import open_url
 
 
 @dataclass(frozen=True)
@@ -129,7 +132,9 @@ class LokiClient:
 
         request = urllib.request.Request(url, headers=headers)
         try:
-            with urllib.request.urlopen(request, timeout=self._timeout_seconds) as response:
+            check_egress(url)
+            maybe_inject_fault("loki")
+            with open_url(request, timeout=self._timeout_seconds) as response:
                 payload = response.read()
         except urllib.error.HTTPError as exc:
             body = exc.read().decode("utf-8", errors="replace")
@@ -0,0 +1,208 @@
+from __future__ import annotations
+
+from collections.abc import Callable
+from datetime import datetime, timedelta, timezone
+from typing import Any, Protocol
+
+from app.core.egress import check_egress
+
+_GCS_ENDPOINT = "https://storage.googleapis.com/"
+
+
+class ObjectStore(Protocol):
+    def put(self, key: str, body: bytes, content_type: str) -> None: ...
+
+    def delete_matching(self, prefix: str, matches: Callable[[str, datetime], bool]) -> int:
+        """Delete the objects under *prefix* for which ``matches(key, last_modified)`` holds."""
+        ...
+
+
+class SignedObjectStore(ObjectStore, Protocol):
+    def signed_url(self, key: str, expires_in_seconds: int) -> str:
+        """Time-limited GET URL of *key* that needs no credentials."""
+        ...
+
+
+class S3ObjectStore:
+    """Amazon S3 or an S3-compatible endpoint (MinIO, Ceph RGW) via boto3.
+
+    Credentials come from the default boto3 chain (IRSA, instance profile,
+    ``AWS_*`` variables).
+    """
+
+    def __init__(self, bucket: str, *, endpoint_url: str = "", storage_class: str = "") -> None:
+        import boto3  # type: ignore[import-not-found]
+
+        self._bucket = bucket
+        self._storage_class = storage_class
+        self._client: Any = boto3.client("s3", endpoint_url=endpoint_url or None)
+
+    def put(self, key: str, body: bytes, content_type: str) -> None:
+        check_egress(self._client.meta.endpoint_url)
+        options: dict[str, object] = {}
+        if self._storage_class:
+            options["StorageClass"] = self._storage_class
+        self._client.put_object(
+            Bucket=self._bucket, Key=key, Body=body, ContentType=content_type, **options
+        )
+
+    def signed_url(self, key: str, expires_in_seconds: int) -> str:
+        # SigV4 presigned URLs are valid for at most seven days.
+        return str(
+            self._client.generate_presigned_url(
+                "get_object",
+                Params={"Bucket": self._bucket, "Key": key},
+                ExpiresIn=min(expires_in_seconds, 7 * 24 * 3600),
+            )
+        )
+
+    def delete_matching(self, prefix: str, matches: Callable[[str, datetime], bool]) -> int:
+        check_egress(self._client.meta.endpoint_url)
+        deleted = 0
+        paginator = self._client.get_paginator("list_objects_v2")
+        for page in paginator.paginate(Bucket=self._bucket, Prefix=prefix):
+            expired = [
+                {"Key": item["Key"]}
+                for item in page.get("Contents", [])
+                if matches(item["Key"], item["LastModified"])
+            ]
+            # list_objects_v2 pages hold at most 1000 keys, the delete_objects limit.
+            if expired:
+                self._client.delete_objects(
+                    Bucket=self._bucket, Delete={"Objects": expired, "Quiet": True}
+                )
+                deleted += len(expired)
+        return deleted
+
+
+class GCSObjectStore:
+    """Google Cloud Storage; credentials come from Application Default Credentials."""
+
+    def __init__(self, bucket: str, *, storage_class: str = "") -> None:
+        from google.cloud import storage  # type: ignore[import-not-found]
+
+        self._client: Any = storage.Client()
+        self._bucket: Any = self._client.bucket(bucket)
+        self._storage_class = storage_class
+
+    def put(self, key: str, body: bytes, content_type: str) -> None:
+        check_egress(_GCS_ENDPOINT)
+        blob = self._bucket.blob(key)
+        if self._storage_class:
+            blob.storage_class = self._storage_class
+        blob.upload_from_string(body, content_type=content_type)
+
+    def signed_url(self, key: str, expires_in_seconds: int) -> str:
+        # V4 signing needs a service account key or the IAM signBlob permission.
+        return str(
+            self._bucket.blob(key).generate_signed_url(
+                version="v4",
+                expiration=timedelta(seconds=min(expires_in_seconds, 7 * 24 * 3600)),
+                method="GET",
+            )
+        )
+
+    def delete_matching(self, prefix: str, matches: Callable[[str, datetime], bool]) -> int:
+        check_egress(_GCS_ENDPOINT)
+        deleted = 0
+        for blob in self._client.list_blobs(self._bucket, prefix=prefix):
+            if blob.time_created is not None and matches(blob.name, blob.time_created):
+                blob.delete()
+                deleted += 1
+        return deleted
+
+
+class AzureBlobObjectStore:
+    """Azure Blob Storage; credentials come from ``DefaultAzureCredential``."""
+
+    def __init__(self, container: str, *, account_url: str, storage_class: str = "") -> None:
+        from azure.identity import DefaultAzureCredential  # type: ignore[import-not-found]
+        from azure.storage.blob import BlobServiceClient  # type: ignore[import-not-found]
+
+        self._account_url = account_url
+        self._storage_class = storage_class
+        self._service: Any = BlobServiceClient(account_url, credential=DefaultAzureCredential())
+        self._container: Any = self._service.get_container_client(container)
+
+    def put(self, key: str, body: bytes, content_type: str) -> None:
+        from azure.storage.blob import ContentSettings  # type: ignore[import-not-found]
+
+        check_egress(self._account_url)
+        self._container.upload_blob(
+            key,
+            body,
+            overwrite=True,
+            content_settings=ContentSettings(content_type=content_type),
+            standard_blob_tier=self._storage_class or None,
+        )
+
+    def signed_url(self, key: str, expires_in_seconds: int) -> str:
+        from azure.storage.blob import (  # type: ignore[import-not-found]
+            BlobSasPermissions,
+            generate_blob_sas,
+        )
+
+        check_egress(self._account_url)
+        now = datetime.now(timezone.utc)
+        expiry = now + timedelta(seconds=expires_in_seconds)
+        # A user delegation SAS, signed with the Entra ID identity instead of an account key.
+        delegation_key = self._service.get_user_delegation_key(now, expiry)
+        sas = generate_blob_sas(
+            account_name=self._service.account_name,
+            container_name=self._container.container_name,
+            blob_name=key,
+            user_delegation_key=delegation_key,
+            permission=BlobSasPermissions(read=True),
+            expiry=expiry,
+        )
+        return f"{self._container.get_blob_client(key).url}?{sas}"
+
+    def delete_matching(self, prefix: str, matches: Callable[[str, datetime], bool]) -> int:
+        check_egress(self._account_url)
+        deleted = 0
+        for blob in self._container.list_blobs(name_starts_with=prefix):
+            if matches(blob.name, blob.last_modified):
+                self._container.delete_blob(blob.name)
+                deleted += 1
+        return deleted
+
+
+_BACKEND_EXTRAS = {"s3": "archive-s3", "gcs": "archive-gcs", "azure": "archive-azure"}
+
+
+def build_object_store(
+    backend: str,
+    bucket: str,
+    *,
+    endpoint_url: str = "",
+    storage_class: str = "",
+    setting_prefix: str = "ARCHIVE",
+) -> SignedObjectStore:
+    """Create the client for *backend* (``s3``, ``gcs`` or ``azure``).
+
+    SDKs are optional extras (``pip install '.[archive-s3]'``); a ValueError
+    names the missing extra or setting, e.g. ``ARCHIVE_BUCKET`` for the
+    default *setting_prefix*.
+    """
+    label = setting_prefix.lower().replace("_", " ")
+    if backend not in _BACKEND_EXTRAS:
+        raise ValueError(f"unknown {label} backend {backend!r} (expected s3, gcs or azure)")
+    if not bucket:
+        raise ValueError(f"{setting_prefix}_BUCKET is required for the {label}")
+    try:
+        if backend == "s3":
+            return S3ObjectStore(bucket, endpoint_url=endpoint_url, storage_class=storage_class)
+        if backend == "gcs":
+            return GCSObjectStore(bucket, storage_class=storage_class)
+        if not endpoint_url:
+            raise ValueError(
+                f"{setting_prefix}_ENDPOINT_URL must be the storage account URL for the azure "
+                "backend"
+            )
+        return AzureBlobObjectStore(
+            bucket, account_url=endpoint_url, storage_class=storage_class
+        )
+    except ImportError as exc:
+        raise ValueError(
+            f"{label} backend {backend!r} needs the '{_BACKEND_EXTRAS[backend]}' extra: {exc}"
+        ) from exc
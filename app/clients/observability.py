@@ -0,0 +1,285 @@
+from __future__ import annotations
+
+import json
+import logging
+import re
+import time
+import urllib.error
+import urllib.parse
+import urllib.request
+from datetime import datetime, timezone
+from typing import Protocol
+
+from app.core.config import Settings
+from app.core.egress import check_egress
+from app.core.tls import open_url
+
+DEFAULT_HONEYCOMB_URL = "https://api.honeycomb.io"
+_DEFAULT_RANGE_SECONDS = 3600
+_POLL_INTERVAL_SECONDS = 1.0
+_CALCULATION_RE = re.compile(r"^([A-Z][A-Z0-9_]*)(?:\((.+)\))?$")
+_FILTER_RE = re.compile(
+    r"^(\S+)\s+(=|!=|>=|<=|>|<|starts-with|does-not-start-with|contains|does-not-contain"
+    r"|exists|does-not-exist|in|not-in)(?:\s+(.+))?$"
+)
+_VALUELESS_FILTER_OPS = frozenset({"exists", "does-not-exist"})
+
+
+class EventQuerySource(Protocol):
+    """A backend that answers high-cardinality event/trace queries."""
+
+    @property
+    def provider(self) -> str: ...
+
+    def run_query(self, dataset: str, spec: dict[str, object]) -> dict[str, object]: ...
+
+
+class ObservabilityClient:
+    """High-cardinality event and span queries against Honeycomb or an OTLP backend.
+
+    Queries are expressed once, in Honeycomb's query spec (calculations,
+    filters, breakdowns, time range), and run by the ``OBSERVABILITY_PROVIDER``
+    source: ``honeycomb`` through the Query Data API, ``http`` by posting the
+    spec to ``OBSERVABILITY_URL`` for backends that ingest OTLP and expose
+    their own query API behind a small adapter.
+    """
+
+    def __init__(self, settings: Settings) -> None:
+        self._logger = logging.getLogger(__name__)
+        self._dataset = settings.observability_dataset or "__all__"
+        self._service_field = settings.observability_service_field or "service.name"
+        self._max_results = max(1, settings.observability_max_results)
+        self._source = _build_source(settings)
+
+    @property
+    def enabled(self) -> bool:
+        return self._source is not None
+
+    @property
+    def provider(self) -> str:
+        return self._source.provider if self._source is not None else ""
+
+    def query(
+        self,
+        *,
+        service_name: str | None = None,
+        calculations: list[str] | None = None,
+        filters: list[str] | None = None,
+        breakdowns: list[str] | None = None,
+        start: str | None = None,
+        end: str | None = None,
+        dataset: str | None = None,
+        limit: int | None = None,
+    ) -> dict[str, object]:
+        dataset = (dataset or "").strip() or self._dataset
+        if self._source is None:
+            return {"warning": "observability backend not configured"}
+        try:
+            spec = build_query_spec(
+                calculations=calculations,
+                filters=[
+                    *([f"{self._service_field} = {service_name}"] if service_name else []),
+                    *(filters or []),
+                ],
+                breakdowns=breakdowns,
+                start=start,
+                end=end,
+                limit=min(limit or self._max_results, self._max_results),
+            )
+        except ValueError as exc:
+            return {"error": str(exc), "dataset": dataset}
+        try:
+            result = self._source.run_query(dataset, spec)
+        except urllib.error.HTTPError as exc:
+            self._logger.warning("%s query HTTP error %s", self._source.provider, exc.code)
+            return {
+                "error": f"{self._source.provider} query failed",
+                "status_code": exc.code,
+                "reason": str(exc.reason),
+                "dataset": dataset,
+                "query": spec,
+            }
+        except Exception as exc:  # noqa: BLE001
+            self._logger.warning("%s query failed: %s", self._source.provider, exc)
+            return {
+                "error": f"{self._source.provider} query failed",
+                "reason": str(exc),
+                "dataset": dataset,
+                "query": spec,
+            }
+        rows = result.get("results") if isinstance(result.get("results"), list) else []
+        return {
+            "provider": self._source.provider,
+            "dataset": dataset,
+            "query": spec,
+            "complete": result.get("complete", True),
+            "result_count": len(rows),
+            "results": rows[: self._max_results],
+            "query_url": result.get("query_url"),
+        }
+
+
+class HoneycombSource:
+    """Honeycomb Query Data API: create the query, then poll its result."""
+
+    provider = "honeycomb"
+
+    def __init__(self, base_url: str, api_key: str, *, timeout_seconds: int) -> None:
+        self._base_url = base_url.rstrip("/")
+        self._api_key = api_key
+        self._timeout_seconds = timeout_seconds
+
+    def run_query(self, dataset: str, spec: dict[str, object]) -> dict[str, object]:
+        slug = urllib.parse.quote(dataset, safe="")
+        query = self._request("POST", f"/1/queries/{slug}", spec)
+        result = self._request(
+            "POST",
+            f"/1/query_results/{slug}",
+            {"query_id": query.get("id"), "disable_series": True, "limit": spec.get("limit")},
+        )
+        deadline = time.monotonic() + self._timeout_seconds
+        while not result.get("complete") and time.monotonic() < deadline:
+            time.sleep(_POLL_INTERVAL_SECONDS)
+            result = self._request("GET", f"/1/query_results/{slug}/{result.get('id')}")
+        data = result.get("data") if isinstance(result.get("data"), dict) else {}
+        rows = data.get("results") if isinstance(data.get("results"), list) else []
+        links = result.get("links") if isinstance(result.get("links"), dict) else {}
+        return {
+            "complete": bool(result.get("complete")),
+            "results": [row.get("data", row) if isinstance(row, dict) else row for row in rows],
+            "query_url": links.get("query_url"),
+        }
+
+    def _request(
+        self, method: str, path: str, body: dict[str, object] | None = None
+    ) -> dict[str, object]:
+        url = f"{self._base_url}{path}"
+        request = urllib.request.Request(
+            url,
+            data=json.dumps(body).encode("utf-8") if body is not None else None,
+            headers={"X-Honeycomb-Team": self._api_key, "Content-Type": "application/json"},
+            method=method,
+        )
+        check_egress(url)
+        with open_url(request, timeout=self._timeout_seconds) as response:
+            payload = json.loads(response.read().decode("utf-8"))
+        if not isinstance(payload, dict):
+            raise ValueError("unexpected honeycomb payload type")
+        return payload
+
+
+class HttpQuerySource:
+    """Posts ``{"dataset", "query"}`` to a query adapter in front of an OTLP backend.
+
+    The adapter answers ``{"results": [...], "complete": bool, "query_url": str}``.
+    """
+
+    provider = "http"
+
+    def __init__(self, url: str, api_key: str, *, timeout_seconds: int) -> None:
+        self._url = url
+        self._api_key = api_key
+        self._timeout_seconds = timeout_seconds
+
+    def run_query(self, dataset: str, spec: dict[str, object]) -> dict[str, object]:
+        headers = {"Content-Type": "application/json"}
+        if self._api_key:
+            headers["Authorization"] = f"Bearer {self._api_key}"
+        request = urllib.request.Request(
+            self._url,
+            data=json.dumps({"dataset": dataset, "query": spec}).encode("utf-8"),
+            headers=headers,
+            method="POST",
+        )
+        check_egress(self._url)
+        with open_url(request, timeout=self._timeout_seconds) as response:
+            payload = json.loads(response.read().decode("utf-8"))
+        if not isinstance(payload, dict):
+            raise ValueError("unexpected query adapter payload type")
+        return payload
+
+
+def _build_source(settings: Settings) -> EventQuerySource | None:
+    provider = settings.observability_provider
+    api_key = settings.observability_api_key.strip()
+    url = settings.observability_url.strip()
+    timeout_seconds = settings.observability_timeout_seconds
+    if provider == "honeycomb" and api_key:
+        return HoneycombSource(
+            url or DEFAULT_HONEYCOMB_URL, api_key, timeout_seconds=timeout_seconds
+        )
+    if provider == "http" and url:
+        return HttpQuerySource(url, api_key, timeout_seconds=timeout_seconds)
+    return None
+
+
+def build_query_spec(
+    *,
+    calculations: list[str] | None,
+    filters: list[str] | None,
+    breakdowns: list[str] | None,
+    start: str | None,
+    end: str | None,
+    limit: int,
+) -> dict[str, object]:
+    """Honeycomb query spec from ``P99(duration_ms)`` and ``field op value`` strings."""
+    spec: dict[str, object] = {
+        "calculations": [_parse_calculation(item) for item in calculations or ["COUNT"]],
+        "filters": [_parse_filter(item) for item in filters or []],
+        "filter_combination": "AND",
+        "breakdowns": [item.strip() for item in breakdowns or [] if item.strip()],
+        "limit": limit,
+    }
+    calculation = spec["calculations"][0]  # type: ignore[index]
+    spec["orders"] = [{**calculation, "order": "descending"}]
+    start_time, end_time = _parse_time(start, "start"), _parse_time(end, "end")
+    if start_time is not None:
+        spec["start_time"] = start_time
+        spec["end_time"] = end_time or int(time.time())
+    elif end_time is not None:
+        spec["end_time"] = end_time
+        spec["time_range"] = _DEFAULT_RANGE_SECONDS
+    else:
+        spec["time_range"] = _DEFAULT_RANGE_SECONDS
+    return spec
+
+
+def _parse_calculation(text: str) -> dict[str, object]:
+    match = _CALCULATION_RE.match(text.strip())
+    if match is None:
+        raise ValueError(f"invalid calculation {text!r}; use e.g. COUNT or P99(duration_ms)")
+    op, column = match.groups()
+    if op not in {"COUNT", "CONCURRENCY"} and column is None:
+        raise ValueError(f"calculation {op} needs a column, e.g. {op}(duration_ms)")
+    return {"op": op, "column": column.strip()} if column else {"op": op}
+
+
+def _parse_filter(text: str) -> dict[str, object]:
+    match = _FILTER_RE.match(text.strip())
+    if match is None:
+        raise ValueError(f"invalid filter {text!r}; use 'field op value', e.g. 'error = true'")
+    column, op, raw = match.groups()
+    if op in _VALUELESS_FILTER_OPS:
+        return {"column": column, "op": op}
+    if raw is None:
+        raise ValueError(f"filter {text!r} needs a value")
+    try:
+        value: object = json.loads(raw)
+    except ValueError:
+        value = raw.strip()
+    return {"column": column, "op": op, "value": value}
+
+
+def _parse_time(value: str | None, name: str) -> int | None:
+    if not value or not value.strip():
+        return None
+    text = value.strip()
+    if text.isdigit():
+        return int(text)
+    try:
+        parsed = datetime.fromisoformat(text.replace("Z", "+00:00"))
+    except ValueError as exc:
+        raise ValueError(f"{name} must be RFC3339 or a Unix timestamp") from exc
+    if parsed.tzinfo is None:
+        parsed = parsed.replace(tzinfo=timezone.utc)
+    return int(parsed.timestamp())
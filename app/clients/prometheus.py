@@ -3,10 +3,12 @@ from __future__ import annotations
 import json
 import logging
 import urllib.parse
-import urllib.request
 from dataclasses import dataclass
 
+from app.core.chaos import maybe_inject_fault
 from app.core.config import Settings
+from app.core.egress import check_egress
+from app.core.tls import open_url
 
 
 @dataclass(frozen=True)
@@ -51,7 +53,9 @@ class PrometheusClient:
         url = f"{endpoint.base_url}/api/v1/label/__name__/values"
 
         try:
-            with urllib.request.urlopen(url, timeout=self._timeout_seconds) as response:
+            check_egress(url)
+            maybe_inject_fault("prometheus")
+            with open_url(url, timeout=self._timeout_seconds) as response:
                 payload = response.read()
         except Exception as exc:  # noqa: BLE001
             self._logger.warning("Failed to list Prometheus metrics: %s", exc)
@@ -98,7 +102,9 @@ class PrometheusClient:
         url = f"{endpoint.base_url}/api/v1/query?{urllib.parse.urlencode(params)}"
 
         try:
-            with urllib.request.urlopen(url, timeout=self._timeout_seconds) as response:
+            check_egress(url)
+            maybe_inject_fault("prometheus")
+            with open_url(url, timeout=self._timeout_seconds) as response:
                 payload = response.read()
         except Exception as exc:  # noqa: BLE001
             self._logger.warning("Failed to query Prometheus: %s", exc)
@@ -153,7 +159,9 @@ class PrometheusClient:
         url = f"{endpoint.base_url}/api/v1/query_range?{urllib.parse.urlencode(params)}"
 
         try:
-            with urllib.request.urlopen(url, timeout=self._timeout_seconds) as response:
+            check_egress(url)
+            maybe_inject_fault("prometheus")
+            with open_url(url, timeout=self._timeout_seconds) as response:
                 payload = response.read()
         except Exception as exc:  # noqa: BLE001
             self._logger.warning("Failed to query_range Prometheus: %s", exc)
@@ -0,0 +1,303 @@
+from __future__ import annotations
+
+import base64
+import json
+import logging
+import re
+import urllib.error
+import urllib.parse
+import urllib.request
+
+from app.core.config import Settings
+from app.core.egress import check_egress
+from app.core.tls import open_url
+
+_DOCKER_HUB_HOST = "registry-1.docker.io"
+_MANIFEST_ACCEPT = ", ".join(
+    [
+        "application/vnd.oci.image.index.v1+json",
+        "application/vnd.oci.image.manifest.v1+json",
+        "application/vnd.docker.distribution.manifest.list.v2+json",
+        "application/vnd.docker.distribution.manifest.v2+json",
+    ]
+)
+_INDEX_MEDIA_TYPES = frozenset(
+    {
+        "application/vnd.oci.image.index.v1+json",
+        "application/vnd.docker.distribution.manifest.list.v2+json",
+    }
+)
+# OCI image labels that identify the build (the analysis needs revision and source).
+_BUILD_LABELS = {
+    "revision": ("org.opencontainers.image.revision", "org.label-schema.vcs-ref"),
+    "source": ("org.opencontainers.image.source", "org.label-schema.vcs-url"),
+    "created": ("org.opencontainers.image.created", "org.label-schema.build-date"),
+    "version": ("org.opencontainers.image.version", "org.label-schema.version"),
+}
+_SBOM_ARTIFACT_MARKERS = ("spdx", "cyclonedx", "in-toto", "sbom", "provenance")
+_AUTH_PARAM_RE = re.compile(r'(\w+)="([^"]*)"')
+
+
+class RegistryError(RuntimeError):
+    """A registry request failed or returned an unexpected payload."""
+
+
+class RegistryClient:
+    """Read image build metadata from OCI registries (Distribution API v2).
+
+    Resolves the running digest to its image config and reports the OCI
+    build labels (git revision, source repository, build time). With SBOM
+    lookup enabled, attestation references are listed from the image index
+    (BuildKit attestation manifests) and the OCI referrers API.
+    """
+
+    def __init__(self, settings: Settings) -> None:
+        self._logger = logging.getLogger(__name__)
+        self._enabled = settings.image_metadata_enabled
+        self._sbom_enabled = settings.image_metadata_sbom_enabled
+        self._timeout_seconds = settings.registry_http_timeout_seconds
+        self._credentials = dict(settings.registry_credentials)
+        self._tokens: dict[tuple[str, str], str] = {}
+
+    @property
+    def enabled(self) -> bool:
+        return self._enabled
+
+    def describe_image(self, image: str, image_id: str | None = None) -> dict[str, object]:
+        """Build metadata for *image*, pinned to the digest in ``image_id`` when known."""
+        host, repository, reference = parse_image_reference(image)
+        digest = _digest_from_image_id(image_id) or (
+            reference if reference.startswith("sha256:") else None
+        )
+        result: dict[str, object] = {
+            "image": image,
+            "registry": host,
+            "repository": repository,
+            "digest": digest,
+        }
+        try:
+            manifest, manifest_digest = self._get_manifest(host, repository, digest or reference)
+            attestations: list[dict[str, object]] = []
+            if manifest.get("mediaType") in _INDEX_MEDIA_TYPES or "manifests" in manifest:
+                attestations = _index_attestations(manifest)
+                platform_digest = _select_platform_manifest(manifest)
+                if platform_digest is None:
+                    return {**result, "error": "no linux image manifest in the index"}
+                manifest, _ = self._get_manifest(host, repository, platform_digest)
+            config_digest = _nested_str(manifest, "config", "digest")
+            if config_digest is None:
+                return {**result, "error": "image manifest has no config"}
+            config = self._get_json(host, repository, f"/blobs/{config_digest}")
+        except RegistryError as exc:
+            self._logger.warning("Failed to read image metadata for %s: %s", image, exc)
+            return {**result, "error": str(exc)}
+
+        result["digest"] = digest or manifest_digest
+        image_config = config.get("config")
+        labels = image_config.get("Labels") if isinstance(image_config, dict) else None
+        build = _build_metadata(labels if isinstance(labels, dict) else {})
+        if isinstance(config.get("created"), str):
+            build.setdefault("created", config["created"])
+        result["build"] = build
+        if commit_url := _commit_url(build.get("source"), build.get("revision")):
+            result["commit_url"] = commit_url
+        if self._sbom_enabled:
+            result["attestations"] = attestations + self._list_referrers(
+                host, repository, str(result["digest"] or "")
+            )
+        return result
+
+    def _list_referrers(self, host: str, repository: str, digest: str) -> list[dict[str, object]]:
+        if not digest:
+            return []
+        try:
+            payload = self._get_json(host, repository, f"/referrers/{digest}")
+        except RegistryError as exc:
+            # Registries without the referrers API answer 404; no attestations then.
+            self._logger.info("No referrers for %s@%s: %s", repository, digest, exc)
+            return []
+        references: list[dict[str, object]] = []
+        for item in _manifest_entries(payload):
+            artifact_type = str(item.get("artifactType") or "")
+            if any(marker in artifact_type.lower() for marker in _SBOM_ARTIFACT_MARKERS):
+                references.append(
+                    {
+                        "source": "referrers",
+                        "artifact_type": artifact_type,
+                        "digest": item.get("digest"),
+                    }
+                )
+        return references
+
+    def _get_manifest(
+        self, host: str, repository: str, reference: str
+    ) -> tuple[dict[str, object], str | None]:
+        body, headers = self._request(
+            host, repository, f"/manifests/{reference}", _MANIFEST_ACCEPT
+        )
+        return _load_json_object(body), headers.get("Docker-Content-Digest")
+
+    def _get_json(self, host: str, repository: str, path: str) -> dict[str, object]:
+        body, _ = self._request(host, repository, path, "application/json")
+        return _load_json_object(body)
+
+    def _request(
+        self, host: str, repository: str, path: str, accept: str
+    ) -> tuple[bytes, dict[str, str]]:
+        url = f"https://{host}/v2/{repository}{path}"
+        token = self._tokens.get((host, repository))
+        for attempt in range(2):
+            headers = {"Accept": accept}
+            if token:
+                headers["Authorization"] = f"Bearer {token}"
+            elif host in self._credentials:
+                headers["Authorization"] = f"Basic {_basic_auth(self._credentials[host])}"
+            request = urllib.request.Request(url, headers=headers)
+            try:
+                check_egress(url)
+                with open_url(request, timeout=self._timeout_seconds) as response:
+                    return response.read(), dict(response.headers.items())
+            except urllib.error.HTTPError as exc:
+                challenge = exc.headers.get("WWW-Authenticate", "") if exc.headers else ""
+                if exc.code == 401 and attempt == 0 and challenge.lower().startswith("bearer"):
+                    token = self._fetch_token(host, repository, challenge)
+                    continue
+                raise RegistryError(f"registry HTTP error {exc.code} for {path}") from exc
+            except Exception as exc:  # noqa: BLE001
+                raise RegistryError(f"registry request failed: {exc}") from exc
+        raise RegistryError(f"registry authorization failed for {repository}")
+
+    def _fetch_token(self, host: str, repository: str, challenge: str) -> str:
+        params = dict(_AUTH_PARAM_RE.findall(challenge))
+        realm = params.pop("realm", "")
+        if not realm:
+            raise RegistryError("registry bearer challenge has no realm")
+        params.setdefault("scope", f"repository:{repository}:pull")
+        url = f"{realm}?{urllib.parse.urlencode(params)}"
+        headers: dict[str, str] = {}
+        if host in self._credentials:
+            headers["Authorization"] = f"Basic {_basic_auth(self._credentials[host])}"
+        try:
+            check_egress(url)
+            with open_url(
+                urllib.request.Request(url, headers=headers), timeout=self._timeout_seconds
+            ) as response:
+                payload = _load_json_object(response.read())
+        except Exception as exc:  # noqa: BLE001
+            raise RegistryError(f"registry token request failed: {exc}") from exc
+        token = payload.get("token") or payload.get("access_token")
+        if not isinstance(token, str) or not token:
+            raise RegistryError("registry token response has no token")
+        self._tokens[(host, repository)] = token
+        return token
+
+
+def parse_image_reference(image: str) -> tuple[str, str, str]:
+    """Split an image reference into (registry host, repository, tag or digest)."""
+    name, reference = image, "latest"
+    if "@" in name:
+        name, reference = name.split("@", 1)
+    elif ":" in name.rsplit("/", 1)[-1]:
+        name, reference = name.rsplit(":", 1)
+    first, _, rest = name.partition("/")
+    if rest and ("." in first or ":" in first or first == "localhost"):
+        host, repository = first, rest
+    else:
+        host, repository = _DOCKER_HUB_HOST, name
+    if host in {"docker.io", "index.docker.io"}:
+        host = _DOCKER_HUB_HOST
+    if host == _DOCKER_HUB_HOST and "/" not in repository:
+        repository = f"library/{repository}"
+    return host, repository, reference
+
+
+def _digest_from_image_id(image_id: str | None) -> str | None:
+    if not image_id or "sha256:" not in image_id:
+        return None
+    return "sha256:" + image_id.rsplit("sha256:", 1)[1]
+
+
+def _select_platform_manifest(index: dict[str, object]) -> str | None:
+    images = [item for item in _manifest_entries(index) if not _is_attestation(item)]
+    for item in images:
+        platform = item.get("platform")
+        if isinstance(platform, dict) and (
+            platform.get("os"),
+            platform.get("architecture"),
+        ) == ("linux", "amd64"):
+            return str(item.get("digest"))
+    return str(images[0].get("digest")) if images else None
+
+
+def _index_attestations(index: dict[str, object]) -> list[dict[str, object]]:
+    return [
+        {
+            "source": "image_index",
+            "artifact_type": "attestation-manifest",
+            "digest": item.get("digest"),
+        }
+        for item in _manifest_entries(index)
+        if _is_attestation(item)
+    ]
+
+
+def _manifest_entries(payload: dict[str, object]) -> list[dict[str, object]]:
+    manifests = payload.get("manifests")
+    if not isinstance(manifests, list):
+        return []
+    return [item for item in manifests if isinstance(item, dict)]
+
+
+def _is_attestation(item: dict[str, object]) -> bool:
+    annotations = item.get("annotations")
+    return (
+        isinstance(annotations, dict)
+        and annotations.get("vnd.docker.reference.type") == "attestation-manifest"
+    )
+
+
+def _build_metadata(labels: dict[str, object]) -> dict[str, object]:
+    build: dict[str, object] = {}
+    for field, keys in _BUILD_LABELS.items():
+        for key in keys:
+            value = labels.get(key)
+            if isinstance(value, str) and value:
+                build[field] = value
+                break
+    return build
+
+
+def _commit_url(source: object, revision: object) -> str | None:
+    """Link to the commit for GitHub/GitLab/Bitbucket style source URLs."""
+    if not isinstance(source, str) or not isinstance(revision, str) or not revision:
+        return None
+    base = source.strip().rstrip("/").removesuffix(".git")
+    if base.startswith("git@"):
+        base = "https://" + base[4:].replace(":", "/", 1)
+    if not base.startswith("https://"):
+        return None
+    separator = "/-/commit/" if "gitlab" in urllib.parse.urlparse(base).netloc else "/commit/"
+    return f"{base}{separator}{revision}"
+
+
+def _nested_str(payload: dict[str, object], *keys: str) -> str | None:
+    value: object = payload
+    for key in keys:
+        if not isinstance(value, dict):
+            return None
+        value = value.get(key)
+    return value if isinstance(value, str) and value else None
+
+
+def _load_json_object(body: bytes) -> dict[str, object]:
+    try:
+        payload = json.loads(body.decode("utf-8"))
+    except (UnicodeDecodeError, json.JSONDecodeError) as exc:
+        raise RegistryError("registry returned invalid JSON") from exc
+    if not isinstance(payload, dict):
+        raise RegistryError("registry returned an unexpected payload")
+    return payload
+
+
+def _basic_auth(credential: str) -> str:
+    return base64.b64encode(credential.encode("utf-8")).decode("ascii")
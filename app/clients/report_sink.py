@@ -0,0 +1,66 @@
+from __future__ import annotations
+
+import json
+import logging
+import urllib.error
+import urllib.request
+from typing import Protocol
+
+from app.core.correlation import CORRELATION_HEADER, current_correlation_id
+from app.core.egress import check_egress
+from app.core.payload_log import log_callback_payload
+from app.core.tls import open_url
+
+
+class ReportSink(Protocol):
+    def send(self, report_type: str, report: dict[str, object]) -> dict[str, object]: ...
+
+
+class WebhookReportSink:
+    """POST scheduled reports (health scans, digests) as JSON to a webhook.
+
+    Analyses are returned to the caller synchronously; reports produced
+    without a request need somewhere to go, typically the kube-rca backend
+    or a Slack-compatible relay.
+    """
+
+    def __init__(self, url: str, *, timeout_seconds: int = 10) -> None:
+        self._logger = logging.getLogger(__name__)
+        self._url = url.strip()
+        self._timeout_seconds = timeout_seconds
+
+    def send(self, report_type: str, report: dict[str, object]) -> dict[str, object]:
+        body = json.dumps({"type": report_type, "report": report}).encode("utf-8")
+        result = self._post(report_type, body)
+        log_callback_payload(report_type, body, result)
+        return result
+
+    def _post(self, report_type: str, body: bytes) -> dict[str, object]:
+        headers = {"Content-Type": "application/json"}
+        # Callbacks sent during an analysis (closures, routed results) name it.
+        correlation_id = current_correlation_id()
+        if correlation_id:
+            headers[CORRELATION_HEADER] = correlation_id
+        request = urllib.request.Request(
+            self._url,
+            data=body,
+            headers=headers,
+            method="POST",
+        )
+        try:
+            check_egress(self._url)
+            with open_url(request, timeout=self._timeout_seconds) as response:
+                status = response.status
+        except urllib.error.HTTPError as exc:
+            self._logger.warning("Report webhook returned HTTP %s for %s", exc.code, report_type)
+            return {"delivered": False, "status_code": exc.code, "reason": str(exc.reason)}
+        except Exception as exc:  # noqa: BLE001
+            self._logger.warning("Failed to deliver %s report: %s", report_type, exc)
+            return {"delivered": False, "reason": str(exc)}
+        return {"delivered": True, "status_code": status}
+
+
+def build_report_sink(url: str, *, timeout_seconds: int = 10) -> ReportSink | None:
+    if not url.strip():
+        return None
+    return WebhookReportSink(url, timeout_seconds=timeout_seconds)
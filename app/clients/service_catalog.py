@@ -0,0 +1,189 @@
+from __future__ import annotations
+
+import json
+import logging
+import threading
+import time
+import urllib.error
+import urllib.parse
+import urllib.request
+
+from app.core.config import Settings
+from app.core.egress import check_egress
+from app.core.tls import open_url
+
+SERVICE_CATALOG_PROVIDERS = ("backstage", "http")
+# Dotted paths into a CMDB lookup response, per normalized field.
+DEFAULT_SERVICE_CATALOG_FIELDS = {
+    "tier": "tier",
+    "owner": "owner",
+    "description": "description",
+    "dependencies": "dependencies",
+    "runbooks": "runbooks",
+}
+_RUNBOOK_MARKERS = ("runbook", "playbook")
+
+
+class ServiceCatalogClient:
+    """Business metadata of the alerted service from Backstage or a CMDB.
+
+    ``backstage`` reads the Component entity of the service from the catalog
+    API; ``http`` fetches ``SERVICE_CATALOG_URL`` with ``{service}`` and
+    ``{namespace}`` filled in and maps the JSON response with
+    ``SERVICE_CATALOG_FIELDS_JSON``. Both are normalized to tier, owner,
+    lifecycle, system, description, dependencies and runbooks, and cached
+    per service for ``SERVICE_CATALOG_CACHE_SECONDS``.
+    """
+
+    def __init__(self, settings: Settings) -> None:
+        self._logger = logging.getLogger(__name__)
+        self._provider = settings.service_catalog_provider
+        self._url = settings.service_catalog_url.strip()
+        self._token = settings.service_catalog_token.strip()
+        self._entity_namespace = settings.service_catalog_entity_namespace or "default"
+        self._fields = dict(settings.service_catalog_fields) or DEFAULT_SERVICE_CATALOG_FIELDS
+        self._service_labels = settings.service_catalog_service_labels
+        self._timeout_seconds = settings.service_catalog_timeout_seconds
+        self._cache_seconds = settings.service_catalog_cache_seconds
+        self._lock = threading.Lock()
+        self._cache: dict[tuple[str, str], tuple[float, dict[str, object] | None]] = {}
+
+    @property
+    def enabled(self) -> bool:
+        return self._provider in SERVICE_CATALOG_PROVIDERS and bool(self._url)
+
+    @property
+    def provider(self) -> str:
+        return self._provider
+
+    @property
+    def service_labels(self) -> tuple[str, ...]:
+        return self._service_labels
+
+    def lookup(self, service: str, namespace: str | None = None) -> dict[str, object] | None:
+        """Catalog entry of *service*, ``None`` when the catalog does not know it.
+
+        Raises ``OSError`` or ``ValueError`` when the catalog cannot be read.
+        """
+        key = (service, namespace or "")
+        with self._lock:
+            cached = self._cache.get(key)
+            if cached is not None and time.monotonic() - cached[0] < self._cache_seconds:
+                return cached[1]
+        if self._provider == "backstage":
+            entry = self._lookup_backstage(service)
+        else:
+            entry = self._lookup_http(service, namespace or "")
+        with self._lock:
+            self._cache[key] = (time.monotonic(), entry)
+        return entry
+
+    def _lookup_backstage(self, service: str) -> dict[str, object] | None:
+        namespace = urllib.parse.quote(self._entity_namespace, safe="")
+        name = urllib.parse.quote(service, safe="")
+        url = f"{self._url.rstrip('/')}/api/catalog/entities/by-name/component/{namespace}/{name}"
+        entity = self._fetch(url)
+        if entity is None:
+            return None
+        metadata = _as_dict(entity.get("metadata"))
+        spec = _as_dict(entity.get("spec"))
+        labels = _as_dict(metadata.get("labels"))
+        annotations = _as_dict(metadata.get("annotations"))
+        links = metadata.get("links") if isinstance(metadata.get("links"), list) else []
+        runbooks = [
+            {"title": link.get("title") or None, "url": link.get("url")}
+            for link in links
+            if isinstance(link, dict)
+            and link.get("url")
+            and _mentions_runbook(f"{link.get('title') or ''} {link.get('type') or ''}")
+        ]
+        runbooks.extend(
+            {"title": key, "url": value}
+            for key, value in annotations.items()
+            if _mentions_runbook(key) and isinstance(value, str) and value.startswith("http")
+        )
+        return {
+            "source": "backstage",
+            "service": metadata.get("name") or service,
+            "tier": spec.get("tier") or labels.get("tier"),
+            "owner": spec.get("owner"),
+            "lifecycle": spec.get("lifecycle"),
+            "system": spec.get("system"),
+            "description": metadata.get("description"),
+            "dependencies": _string_list(spec.get("dependsOn")),
+            "runbooks": runbooks,
+        }
+
+    def _lookup_http(self, service: str, namespace: str) -> dict[str, object] | None:
+        url = self._url.replace("{service}", urllib.parse.quote(service, safe="")).replace(
+            "{namespace}", urllib.parse.quote(namespace, safe="")
+        )
+        payload = self._fetch(url)
+        if payload is None:
+            return None
+        fields = {name: _dig(payload, path) for name, path in self._fields.items()}
+        return {
+            "source": "http",
+            "service": service,
+            "tier": fields.get("tier"),
+            "owner": fields.get("owner"),
+            "lifecycle": fields.get("lifecycle"),
+            "system": fields.get("system"),
+            "description": fields.get("description"),
+            "dependencies": _string_list(fields.get("dependencies")),
+            "runbooks": _runbook_links(fields.get("runbooks")),
+        }
+
+    def _fetch(self, url: str) -> dict[str, object] | None:
+        headers = {"Accept": "application/json", "User-Agent": "kube-rca-agent"}
+        if self._token:
+            headers["Authorization"] = f"Bearer {self._token}"
+        request = urllib.request.Request(url, headers=headers)
+        check_egress(url)
+        try:
+            with open_url(request, timeout=self._timeout_seconds) as response:
+                payload = json.loads(response.read().decode("utf-8"))
+        except urllib.error.HTTPError as exc:
+            if exc.code == 404:
+                return None
+            self._logger.warning("Service catalog HTTP error %s for %s", exc.code, url)
+            raise
+        if not isinstance(payload, dict):
+            raise ValueError("unexpected service catalog payload type")
+        return payload
+
+
+def _as_dict(value: object) -> dict[str, object]:
+    return value if isinstance(value, dict) else {}
+
+
+def _string_list(value: object) -> list[str]:
+    if isinstance(value, str):
+        return [value]
+    if not isinstance(value, list):
+        return []
+    return [str(item.get("name") if isinstance(item, dict) else item) for item in value if item]
+
+
+def _runbook_links(value: object) -> list[dict[str, object]]:
+    items = [value] if isinstance(value, str) else value if isinstance(value, list) else []
+    return [
+        {"title": item.get("title"), "url": item.get("url")}
+        if isinstance(item, dict)
+        else {"title": None, "url": str(item)}
+        for item in items
+        if item
+    ]
+
+
+def _mentions_runbook(text: str) -> bool:
+    return any(marker in text.lower() for marker in _RUNBOOK_MARKERS)
+
+
+def _dig(payload: object, path: str) -> object:
+    value = payload
+    for part in path.split("."):
+        if not isinstance(value, dict):
+            return None
+        value = value.get(part)
+    return value
@@ -3,6 +3,7 @@ from __future__ import annotations
 import logging
 from collections.abc import Iterator
 from contextlib import contextmanager
+from datetime import datetime
 from typing import Any
 
 import psycopg
@@ -13,13 +14,27 @@ from strands.session import SessionRepository
 from strands.types.exceptions import SessionException
 from strands.types.session import Session, SessionAgent, SessionMessage
 
+from app.core.encryption import FieldCipher
+
 
 class PostgresSessionRepository(SessionRepository):
-    def __init__(self, dsn: str) -> None:
+    def __init__(self, dsn: str, cipher: FieldCipher | None = None) -> None:
         self._dsn = dsn
+        self._cipher = cipher
         self._logger = logging.getLogger(__name__)
         self._ensure_schema()
 
+    def _encode(self, payload: dict[str, Any]) -> Jsonb:
+        # Agent state and messages hold LLM transcripts and tool results (logs, evidence).
+        if self._cipher is None:
+            return Jsonb(payload)
+        return Jsonb(self._cipher.encrypt_json(payload))
+
+    def _decode(self, data: Any) -> Any:
+        if self._cipher is None:
+            return data
+        return self._cipher.decrypt_json(data)
+
     def _connect(self) -> psycopg.Connection:
         return psycopg.connect(self._dsn, row_factory=dict_row)
 
@@ -56,6 +71,29 @@ class PostgresSessionRepository(SessionRepository):
             CREATE INDEX IF NOT EXISTS strands_messages_lookup_idx
             ON strands_messages(session_id, agent_id, message_id)
             """,
+            # Last activity timestamp used by the retention janitor.
+            """
+            ALTER TABLE strands_sessions
+            ADD COLUMN IF NOT EXISTS updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+            """,
+            """
+            CREATE INDEX IF NOT EXISTS strands_sessions_updated_at_idx
+            ON strands_sessions(updated_at)
+            """,
+            # Kubernetes access the analysis ran with, enforced on follow-ups.
+            """
+            ALTER TABLE strands_sessions
+            ADD COLUMN IF NOT EXISTS access_scope JSONB
+            """,
+            # Alert namespace of the session, matched by data-deletion requests.
+            """
+            ALTER TABLE strands_sessions
+            ADD COLUMN IF NOT EXISTS namespace TEXT
+            """,
+            """
+            CREATE INDEX IF NOT EXISTS strands_sessions_namespace_idx
+            ON strands_sessions(namespace)
+            """,
         ]
 
         try:
@@ -109,6 +147,39 @@ class PostgresSessionRepository(SessionRepository):
             return None
         return Session.from_dict(row["data"])
 
+    def write_access_scope(self, session_id: str, scope: dict[str, object]) -> None:
+        with self._connect() as conn:
+            with conn.cursor() as cur:
+                cur.execute(
+                    "UPDATE strands_sessions SET access_scope = %s WHERE session_id = %s",
+                    (Jsonb(scope), session_id),
+                )
+
+    def write_session_namespace(self, session_key: str, namespace: str) -> int:
+        """Record *namespace* on the untagged sessions of alert *session_key* (runs, branches)."""
+        with self._connect() as conn:
+            with conn.cursor() as cur:
+                cur.execute(
+                    """
+                    UPDATE strands_sessions SET namespace = %s
+                    WHERE starts_with(session_id, %s) AND namespace IS NULL
+                    """,
+                    (namespace, f"{session_key}:"),
+                )
+                return cur.rowcount
+
+    def read_access_scope(self, session_id: str) -> dict[str, object] | None:
+        with self._connect() as conn:
+            with conn.cursor() as cur:
+                cur.execute(
+                    "SELECT access_scope FROM strands_sessions WHERE session_id = %s",
+                    (session_id,),
+                )
+                row = cur.fetchone()
+        if not row or not isinstance(row["access_scope"], dict):
+            return None
+        return row["access_scope"]
+
     def read_conversation_manager_name(self, session_id: str) -> str | None:
         """Return stored conversation manager class name for a session, if present."""
         with self._connect() as conn:
@@ -125,7 +196,7 @@ class PostgresSessionRepository(SessionRepository):
                 row = cur.fetchone()
         if not row:
             return None
-        data = row["data"]
+        data = self._decode(row["data"])
         if not isinstance(data, dict):
             return None
         manager_state = data.get("conversation_manager_state")
@@ -142,6 +213,83 @@ class PostgresSessionRepository(SessionRepository):
                     (session_id,),
                 )
 
+    def purge_inactive_sessions(self, older_than_days: int) -> int:
+        """Delete whole sessions (agents/messages cascade) idle for *older_than_days*.
+
+        Sessions are purged as a unit because trimming individual messages would
+        leave the stored conversation manager state inconsistent.
+        """
+        with self._connect() as conn:
+            with conn.cursor() as cur:
+                cur.execute(
+                    """
+                    DELETE FROM strands_sessions
+                    WHERE updated_at < NOW() - make_interval(days => %s)
+                    """,
+                    (older_than_days,),
+                )
+                return cur.rowcount
+
+    def delete_sessions(
+        self,
+        *,
+        namespace: str | None = None,
+        session_keys: list[str] | None = None,
+        session_prefix: str | None = None,
+        since: datetime | None = None,
+        until: datetime | None = None,
+    ) -> int:
+        """Hard-delete matching sessions with their agents and messages.
+
+        *namespace* matches the sessions tagged with it and the sessions started
+        from them (``<run>:shadow``, hypothesis branches). *session_keys* match a
+        summary key and every ``<key>:run:<suffix>`` session created from it;
+        with a namespace they only add the untagged sessions of older releases.
+        """
+        conditions: list[str] = []
+        params: list[object] = []
+        key_match = "regexp_replace(session_id, ':run:[0-9a-f]+$', '') = ANY(%s)"
+        if namespace:
+            namespace_match = [
+                "namespace = %s",
+                """EXISTS (
+                    SELECT 1 FROM strands_sessions parent
+                    WHERE parent.namespace = %s
+                    AND starts_with(strands_sessions.session_id, parent.session_id || ':')
+                )""",
+            ]
+            params.extend([namespace, namespace])
+            if session_keys:
+                namespace_match.append(f"(namespace IS NULL AND {key_match})")
+                params.append(session_keys)
+            conditions.append("(" + " OR ".join(namespace_match) + ")")
+        elif session_keys is not None:
+            conditions.append(key_match)
+            params.append(session_keys)
+        if session_prefix:
+            conditions.append("starts_with(session_id, %s)")
+            params.append(session_prefix)
+        if since is not None:
+            conditions.append("updated_at >= %s")
+            params.append(since)
+        if until is not None:
+            conditions.append("updated_at < %s")
+            params.append(until)
+        if not conditions:
+            raise ValueError("at least one deletion filter is required")
+
+        query = "DELETE FROM strands_sessions WHERE " + " AND ".join(conditions)
+        with self._connect() as conn:
+            with conn.cursor() as cur:
+                cur.execute(query, tuple(params))
+                return cur.rowcount
+
+    def _touch_session(self, cur: psycopg.Cursor, session_id: str) -> None:
+        cur.execute(
+            "UPDATE strands_sessions SET updated_at = NOW() WHERE session_id = %s",
+            (session_id,),
+        )
+
     def create_agent(self, session_id: str, session_agent: SessionAgent, **kwargs: Any) -> None:
         try:
             with self._connect() as conn:
@@ -151,7 +299,7 @@ class PostgresSessionRepository(SessionRepository):
                         INSERT INTO strands_agents (session_id, agent_id, data)
                         VALUES (%s, %s, %s)
                         """,
-                        (session_id, session_agent.agent_id, Jsonb(session_agent.to_dict())),
+                        (session_id, session_agent.agent_id, self._encode(session_agent.to_dict())),
                     )
         except UniqueViolation as exc:
             raise SessionException(
@@ -171,7 +319,7 @@ class PostgresSessionRepository(SessionRepository):
                 row = cur.fetchone()
         if not row:
             return None
-        return SessionAgent.from_dict(row["data"])
+        return SessionAgent.from_dict(self._decode(row["data"]))
 
     def update_agent(self, session_id: str, session_agent: SessionAgent, **kwargs: Any) -> None:
         with self._connect() as conn:
@@ -182,7 +330,7 @@ class PostgresSessionRepository(SessionRepository):
                     SET data = %s
                     WHERE session_id = %s AND agent_id = %s
                     """,
-                    (Jsonb(session_agent.to_dict()), session_id, session_agent.agent_id),
+                    (self._encode(session_agent.to_dict()), session_id, session_agent.agent_id),
                 )
                 if cur.rowcount == 0:
                     raise SessionException(
@@ -204,9 +352,10 @@ class PostgresSessionRepository(SessionRepository):
                         session_id,
                         agent_id,
                         session_message.message_id,
-                        Jsonb(session_message.to_dict()),
+                        self._encode(session_message.to_dict()),
                     ),
                 )
+                self._touch_session(cur, session_id)
 
     def read_message(
         self, session_id: str, agent_id: str, message_id: int, **kwargs: Any
@@ -223,7 +372,7 @@ class PostgresSessionRepository(SessionRepository):
                 row = cur.fetchone()
         if not row:
             return None
-        return SessionMessage.from_dict(row["data"])
+        return SessionMessage.from_dict(self._decode(row["data"]))
 
     def update_message(
         self, session_id: str, agent_id: str, session_message: SessionMessage, **kwargs: Any
@@ -237,7 +386,7 @@ class PostgresSessionRepository(SessionRepository):
                     WHERE session_id = %s AND agent_id = %s AND message_id = %s
                     """,
                     (
-                        Jsonb(session_message.to_dict()),
+                        self._encode(session_message.to_dict()),
                         session_id,
                         agent_id,
                         session_message.message_id,
@@ -271,4 +420,4 @@ class PostgresSessionRepository(SessionRepository):
             with conn.cursor() as cur:
                 cur.execute(query, params)
                 rows = cur.fetchall()
-        return [SessionMessage.from_dict(row["data"]) for row in rows]
+        return [SessionMessage.from_dict(self._decode(row["data"])) for row in rows]
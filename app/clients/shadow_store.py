@@ -0,0 +1,184 @@
+from __future__ import annotations
+
+import logging
+from dataclasses import dataclass
+from datetime import datetime
+from typing import Protocol
+
+import psycopg
+from psycopg.errors import DuplicateTable, UniqueViolation
+from psycopg.rows import dict_row
+
+from app.core.encryption import FieldCipher
+
+
+@dataclass(frozen=True)
+class ShadowResult:
+    analysis_id: str
+    session_key: str
+    alertname: str | None
+    namespace: str | None
+    primary_model: str
+    shadow_model: str
+    primary_analysis: str
+    shadow_analysis: str
+    shadow_error: str | None
+    primary_ms: float
+    shadow_ms: float
+
+
+class ShadowStore(Protocol):
+    def record(self, result: ShadowResult) -> None: ...
+
+    def list_results(
+        self, *, limit: int = 50, alertname: str | None = None
+    ) -> list[dict[str, object]]: ...
+
+
+class PostgresShadowStore:
+    """Primary and shadow analysis outputs of the same alert, stored side by side."""
+
+    def __init__(self, dsn: str, cipher: FieldCipher | None = None) -> None:
+        self._dsn = dsn
+        self._cipher = cipher
+        self._logger = logging.getLogger(__name__)
+        self._ensure_schema()
+
+    def _connect(self) -> psycopg.Connection:
+        return psycopg.connect(self._dsn, row_factory=dict_row)
+
+    def _ensure_schema(self) -> None:
+        statements = [
+            """
+            CREATE TABLE IF NOT EXISTS kube_rca_shadow_results (
+                result_id BIGSERIAL PRIMARY KEY,
+                analysis_id TEXT NOT NULL,
+                session_key TEXT NOT NULL,
+                alertname TEXT,
+                namespace TEXT,
+                primary_model TEXT NOT NULL,
+                shadow_model TEXT NOT NULL,
+                primary_analysis TEXT NOT NULL,
+                shadow_analysis TEXT NOT NULL,
+                shadow_error TEXT,
+                primary_ms DOUBLE PRECISION NOT NULL,
+                shadow_ms DOUBLE PRECISION NOT NULL,
+                created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+            )
+            """,
+            """
+            CREATE INDEX IF NOT EXISTS kube_rca_shadow_results_created_at_idx
+            ON kube_rca_shadow_results(created_at)
+            """,
+            """
+            CREATE INDEX IF NOT EXISTS kube_rca_shadow_results_alertname_idx
+            ON kube_rca_shadow_results(alertname, result_id DESC)
+            """,
+        ]
+        try:
+            with self._connect() as conn:
+                with conn.cursor() as cur:
+                    for statement in statements:
+                        cur.execute(statement)
+        except (UniqueViolation, DuplicateTable) as exc:
+            self._logger.debug("Schema already exists, skipping creation: %s", exc)
+
+    def record(self, result: ShadowResult) -> None:
+        primary, shadow = result.primary_analysis, result.shadow_analysis
+        if self._cipher is not None:
+            primary = self._cipher.encrypt_text(primary)
+            shadow = self._cipher.encrypt_text(shadow)
+        with self._connect() as conn:
+            with conn.cursor() as cur:
+                cur.execute(
+                    """
+                    INSERT INTO kube_rca_shadow_results (
+                        analysis_id, session_key, alertname, namespace,
+                        primary_model, shadow_model, primary_analysis, shadow_analysis,
+                        shadow_error, primary_ms, shadow_ms
+                    )
+                    VALUES (%s, %s, %s, %s, %s, %s, %s, %s, %s, %s, %s)
+                    """,
+                    (
+                        result.analysis_id,
+                        result.session_key,
+                        result.alertname,
+                        result.namespace,
+                        result.primary_model,
+                        result.shadow_model,
+                        primary,
+                        shadow,
+                        result.shadow_error,
+                        result.primary_ms,
+                        result.shadow_ms,
+                    ),
+                )
+
+    def list_results(
+        self, *, limit: int = 50, alertname: str | None = None
+    ) -> list[dict[str, object]]:
+        query = "SELECT * FROM kube_rca_shadow_results"
+        params: list[object] = []
+        if alertname:
+            query += " WHERE alertname = %s"
+            params.append(alertname)
+        query += " ORDER BY result_id DESC LIMIT %s"
+        params.append(limit)
+        with self._connect() as conn:
+            with conn.cursor() as cur:
+                cur.execute(query, tuple(params))
+                rows = cur.fetchall()
+        results: list[dict[str, object]] = []
+        for row in rows:
+            item = dict(row)
+            if self._cipher is not None:
+                item["primary_analysis"] = self._cipher.decrypt_text(item["primary_analysis"])
+                item["shadow_analysis"] = self._cipher.decrypt_text(item["shadow_analysis"])
+            created_at = item.get("created_at")
+            if isinstance(created_at, datetime):
+                item["created_at"] = created_at.isoformat()
+            results.append(item)
+        return results
+
+    def purge_older_than(self, older_than_days: int) -> int:
+        with self._connect() as conn:
+            with conn.cursor() as cur:
+                cur.execute(
+                    """
+                    DELETE FROM kube_rca_shadow_results
+                    WHERE created_at < NOW() - make_interval(days => %s)
+                    """,
+                    (older_than_days,),
+                )
+                return cur.rowcount
+
+    def delete_results(
+        self,
+        *,
+        namespace: str | None = None,
+        session_prefix: str | None = None,
+        since: datetime | None = None,
+        until: datetime | None = None,
+    ) -> int:
+        conditions: list[str] = []
+        params: list[object] = []
+        if namespace:
+            conditions.append("namespace = %s")
+            params.append(namespace)
+        if session_prefix:
+            conditions.append("starts_with(session_key, %s)")
+            params.append(session_prefix)
+        if since is not None:
+            conditions.append("created_at >= %s")
+            params.append(since)
+        if until is not None:
+            conditions.append("created_at < %s")
+            params.append(until)
+        if not conditions:
+            raise ValueError("at least one deletion filter is required")
+
+        query = "DELETE FROM kube_rca_shadow_results WHERE " + " AND ".join(conditions)
+        with self._connect() as conn:
+            with conn.cursor() as cur:
+                cur.execute(query, tuple(params))
+                return cur.rowcount
@@ -9,6 +9,7 @@ import re
 import time
 from collections import OrderedDict
 from collections.abc import Callable
+from contextvars import ContextVar
 from dataclasses import dataclass
 from datetime import datetime, timezone
 from threading import Lock
@@ -16,6 +17,7 @@ from typing import Any, Protocol
 
 from strands import Agent, tool
 from strands.handlers.callback_handler import null_callback_handler
+from strands.hooks import BeforeModelCallEvent, BeforeToolCallEvent, HookProvider, HookRegistry
 from strands.session import RepositorySessionManager
 from strands.types.content import Messages
 from tenacity import (
@@ -39,17 +41,31 @@ except ImportError:  # pragma: no cover
     _HTTPX_TRANSPORT_ERRORS = ()
 
 from app.clients.conversation_manager import SafeSlidingWindowConversationManager
+from app.clients.datastores import DatastoreHealthClient
+from app.clients.endpoint_probe import EndpointProbeClient
 from app.clients.k8s import KubernetesClient
 from app.clients.llm_providers import ModelConfig, create_model
 from app.clients.loki import LokiClient
+from app.clients.observability import ObservabilityClient
 from app.clients.prometheus import PrometheusClient
+from app.clients.registry import RegistryClient
 from app.clients.session_repository import PostgresSessionRepository
 from app.clients.tempo import TempoClient, build_traceql_query
+from app.clients.terraform import TerraformCloudClient
+from app.core.chaos import maybe_inject_fault
 from app.core.config import Settings
+from app.core.correlation import CORRELATION_TRACE_ATTRIBUTE, current_correlation_id
+from app.core.deadline import DeadlineExceeded, check_deadline, remaining_seconds
+from app.core.encryption import FieldCipher
+from app.core.evidence_budget import budget_range_result
 from app.core.masking import Masker, RegexMasker
 
 logger = logging.getLogger(__name__)
 
+# Set by ``stream_analyze`` for the calling thread; agents report their stream to it.
+_stream_handler: ContextVar[Callable[..., None] | None] = ContextVar(
+    "strands_stream_handler", default=None
+)
 _PROM_DURATION_RE = re.compile(r"^(?P<value>\d+)(?P<unit>ms|s|m|h|d|w|y)$")
 _QUERY_PREVIEW_LIMIT = 120
 _HASH_LENGTH = 12
@@ -486,6 +502,31 @@ def _iter_exception_chain(exc: BaseException) -> list[BaseException]:
     return errors
 
 
+class _DeadlineHook(HookProvider):
+    """Stops the agent loop once the latency SLO deadline of the analysis has passed."""
+
+    def register_hooks(self, registry: HookRegistry, **kwargs: Any) -> None:
+        registry.add_callback(BeforeModelCallEvent, self._before_model_call)
+        registry.add_callback(BeforeToolCallEvent, self._before_tool_call)
+
+    def _before_model_call(self, event: BeforeModelCallEvent) -> None:
+        # Raising here ends the event loop; a tool error only goes back to the model.
+        check_deadline()
+
+    def _before_tool_call(self, event: BeforeToolCallEvent) -> None:
+        remaining = remaining_seconds()
+        if remaining is not None and remaining <= 0:
+            event.cancel_tool = "analysis deadline passed"
+
+
+def _deadline_exceeded(exc: BaseException) -> DeadlineExceeded | None:
+    """The DeadlineExceeded in the chain of *exc*, which Strands may have wrapped."""
+    for err in _iter_exception_chain(exc):
+        if isinstance(err, DeadlineExceeded):
+            return err
+    return None
+
+
 def _is_invalid_conversation_manager_state(exc: BaseException) -> bool:
     """Return True when Strands session restore rejects conversation manager state."""
     for err in _iter_exception_chain(exc):
@@ -496,6 +537,8 @@ def _is_invalid_conversation_manager_state(exc: BaseException) -> bool:
 
 def _is_retryable(exc: BaseException) -> bool:
     """Return True for transient server errors (5xx, 429) that warrant a retry."""
+    if _deadline_exceeded(exc) is not None:
+        return False
     # httpx transport-level errors (connection dropped mid-stream)
     if _HTTPX_TRANSPORT_ERRORS and isinstance(exc, _HTTPX_TRANSPORT_ERRORS):
         return True
@@ -640,11 +683,38 @@ class _AgentCacheEntry:
     last_access: float
 
 
+class _StreamCallback:
+    """Strands callback handler forwarding text deltas and new tool calls to *on_event*."""
+
+    def __init__(self, on_event: Callable[[dict[str, object]], None]) -> None:
+        self._on_event = on_event
+        self._tool_use_ids: set[str] = set()
+
+    def __call__(self, **kwargs: Any) -> None:
+        text = kwargs.get("data")
+        if isinstance(text, str) and text:
+            self._on_event({"type": "chunk", "text": text})
+        tool_use = kwargs.get("current_tool_use")
+        if isinstance(tool_use, dict) and tool_use.get("name"):
+            tool_use_id = str(tool_use.get("toolUseId") or tool_use["name"])
+            if tool_use_id not in self._tool_use_ids:
+                self._tool_use_ids.add(tool_use_id)
+                self._on_event({"type": "tool_call", "tool": str(tool_use["name"])})
+
+
 class AnalysisEngine(Protocol):
     def analyze(self, prompt: str, incident_id: str | None = None) -> str:
         raise NotImplementedError
 
 
+class CodeChangeSource(Protocol):
+    def correlate(self, namespace: str, deployment: str) -> dict[str, object]: ...
+
+
+class KafkaLagSource(Protocol):
+    def analyze(self, consumer_group: str, topic: str | None = None) -> dict[str, object]: ...
+
+
 class StrandsAnalysisEngine:
     def __init__(
         self,
@@ -655,6 +725,14 @@ class StrandsAnalysisEngine:
         loki_client: LokiClient | None = None,
         masker: Masker | None = None,
         model_config: ModelConfig | None = None,
+        cipher: FieldCipher | None = None,
+        terraform_client: TerraformCloudClient | None = None,
+        registry_client: RegistryClient | None = None,
+        code_changes: CodeChangeSource | None = None,
+        datastore_client: DatastoreHealthClient | None = None,
+        kafka_lag: KafkaLagSource | None = None,
+        endpoint_probe: EndpointProbeClient | None = None,
+        observability: ObservabilityClient | None = None,
     ) -> None:
         if not settings.session_store_dsn:
             raise ValueError(
@@ -665,13 +743,24 @@ class StrandsAnalysisEngine:
         self._model_config = model_config
         self._masker = masker or RegexMasker()
         self._tools = _build_tools(
-            k8s_client, prometheus_client, tempo_client, loki_client, self._masker
+            k8s_client,
+            prometheus_client,
+            tempo_client,
+            loki_client,
+            self._masker,
+            terraform_client=terraform_client,
+            registry_client=registry_client,
+            code_changes=code_changes,
+            datastore_client=datastore_client,
+            kafka_lag=kafka_lag,
+            endpoint_probe=endpoint_probe,
+            observability=observability,
         )
         self._cache_lock = Lock()
         self._agent_cache: OrderedDict[str, _AgentCacheEntry] = OrderedDict()
         self._cache_size = max(settings.agent_cache_size, 1)
         self._cache_ttl_seconds = settings.agent_cache_ttl_seconds
-        self._session_repo = PostgresSessionRepository(settings.session_store_dsn)
+        self._session_repo = PostgresSessionRepository(settings.session_store_dsn, cipher=cipher)
 
         # LLM retry settings
         self._retry_max_attempts = max(1, settings.llm_retry_max_attempts)
@@ -735,6 +824,47 @@ class StrandsAnalysisEngine:
             )
             return result
 
+    def stream_analyze(
+        self,
+        prompt: str,
+        incident_id: str | None,
+        on_event: Callable[[dict[str, object]], None],
+    ) -> str:
+        """Like ``analyze``, reporting text deltas and tool calls to *on_event* as they occur.
+
+        Events are ``{"type": "chunk", "text": ...}`` and ``{"type": "tool_call", "tool": ...}``.
+        A retried or recovered LLM call streams its answer again from the start.
+        """
+        token = _stream_handler.set(_StreamCallback(on_event))
+        try:
+            return self.analyze(prompt, incident_id)
+        finally:
+            _stream_handler.reset(token)
+
+    def tool_names(self) -> list[str]:
+        return [str(getattr(item, "tool_name", item)) for item in self._tools]
+
+    def warm_up(self) -> None:
+        """Import the provider SDK and build its client once, ahead of the first analysis."""
+        self._create_model()
+
+    def run_tool(
+        self, tool_name: str, arguments: dict[str, object], incident_id: str | None = None
+    ) -> dict[str, object]:
+        """Run one tool directly in the session; the call and its result join its history.
+
+        Raises:
+            ValueError: when *tool_name* is not one of the agent's tools.
+        """
+        if tool_name not in self.tool_names():
+            raise ValueError(f"unknown tool {tool_name!r}")
+        session_id = self._resolve_session_id(incident_id)
+        entry = self._get_cache_entry(session_id)
+        with self._session_repo.session_lock(session_id):
+            with entry.lock:
+                result = getattr(entry.agent.tool, tool_name)(**arguments)
+        return dict(result) if isinstance(result, dict) else {"status": "success", "content": []}
+
     def _recover_turn_order(self, prompt: str, session_id: str) -> str:
         """Reset session and retry when Gemini turn-order sanitization fails."""
         logger.warning(
@@ -813,11 +943,24 @@ class StrandsAnalysisEngine:
             before_sleep=_before_retry,
         )
         def _call() -> str:
-            return str(agent(prompt))
+            # No new attempt once the latency SLO deadline of the analysis has passed.
+            check_deadline()
+            maybe_inject_fault("llm")
+            handler = _stream_handler.get()
+            if handler is None:
+                return str(agent(prompt))
+            agent.callback_handler = handler
+            try:
+                return str(agent(prompt))
+            finally:
+                agent.callback_handler = null_callback_handler
 
         try:
             return _call()
         except Exception as exc:
+            deadline_exceeded = _deadline_exceeded(exc)
+            if deadline_exceeded is not None and deadline_exceeded is not exc:
+                raise deadline_exceeded from None
             if _is_gemini_invalid_turn_order(exc):
                 logger.warning("Gemini turn-order error detected, sanitizing messages and retrying")
                 _sanitize_message_order(agent.messages)
@@ -870,12 +1013,19 @@ class StrandsAnalysisEngine:
             session_repository=self._session_repo,
         )
         conversation_manager = SafeSlidingWindowConversationManager(window_size=40)
+        # Each analysis builds its own agent, so its spans carry its correlation ID.
+        correlation_id = current_correlation_id()
+        trace_attributes = (
+            {CORRELATION_TRACE_ATTRIBUTE: correlation_id} if correlation_id else None
+        )
         return Agent(
             model=model,
             tools=self._tools,
             callback_handler=null_callback_handler,
             session_manager=session_manager,
             conversation_manager=conversation_manager,
+            trace_attributes=trace_attributes,
+            hooks=[_DeadlineHook()],
         )
 
     def _create_model(self) -> object:
@@ -902,6 +1052,13 @@ def _build_tools(
     tempo_client: TempoClient | None,
     loki_client: LokiClient | None,
     masker: Masker,
+    terraform_client: TerraformCloudClient | None = None,
+    registry_client: RegistryClient | None = None,
+    code_changes: CodeChangeSource | None = None,
+    datastore_client: DatastoreHealthClient | None = None,
+    kafka_lag: KafkaLagSource | None = None,
+    endpoint_probe: EndpointProbeClient | None = None,
+    observability: ObservabilityClient | None = None,
 ) -> list[object]:
     def _mask(data: Any) -> Any:
         return masker.mask_object(data)
@@ -1091,7 +1248,8 @@ def _build_tools(
         """
         if prometheus_client is None:
             return _mask({"warning": "prometheus client not configured"})
-        return _mask(prometheus_client.query_range(query, start=start, end=end, step=step))
+        result = prometheus_client.query_range(query, start=start, end=end, step=step)
+        return _mask(budget_range_result(result))
 
     @_logged_tool()
     def discover_tempo() -> dict[str, object]:
@@ -1152,6 +1310,56 @@ def _build_tools(
         pods = k8s_client.list_pods_in_namespace(namespace, label_selector=label_selector)
         return _mask([pod.to_dict() for pod in pods])
 
+    @_logged_tool()
+    def get_cluster_upgrade_status(lookback_minutes: int = 180) -> dict[str, object]:
+        """Check whether a control-plane or node upgrade is in progress or just happened.
+
+        Use this for disruption alerts (pods evicted/rescheduled, NodeNotReady,
+        PodDisruptionBudget violations) affecting several workloads at once.
+        Reports API server vs kubelet version skew, cordoned nodes, nodes
+        created in the lookback window (surge nodes) and node lifecycle events.
+        Compare event times with the alert's startsAt before attributing the
+        disruption to the upgrade.
+
+        Args:
+            lookback_minutes: Window for recently created nodes and node events.
+        """
+        return _mask(k8s_client.get_cluster_upgrade_status(lookback_minutes=lookback_minutes))
+
+    @_logged_tool()
+    def get_node_maintenance_status(node_name: str) -> dict[str, object]:
+        """Check whether a node is under planned maintenance.
+
+        Use this for pod disruption or NodeNotReady alerts on a single node.
+        Reports cordon status, drain/termination taints (cluster autoscaler,
+        Karpenter, AWS/GCE termination handlers), the AKS VMEventScheduled
+        condition and Cluster API machine deletion. Disruption on a node in
+        maintenance is expected, not a failure.
+
+        Args:
+            node_name: Node the affected pod runs on.
+        """
+        status = k8s_client.get_node_maintenance_status(node_name)
+        if status is None:
+            return _mask({"warning": "node not found"})
+        return _mask(status)
+
+    @_logged_tool()
+    def find_removed_api_usage(namespace: str) -> dict[str, object]:
+        """Check a namespace's Helm releases for deprecated or removed API versions.
+
+        Use this when alerts coincide with API errors, failed Helm upgrades or
+        'no matches for kind' events, typically after a cluster upgrade. Each
+        match lists the API status for the cluster version ('removed' or
+        'deprecated'), the version that removes it and the migration target.
+        Controllers calling removed APIs directly show up in the Prometheus
+        metric apiserver_requested_deprecated_apis.
+
+        Args:
+            namespace: Namespace of the affected workload.
+        """
+        return _mask(k8s_client.find_removed_api_usage(namespace))
+
     @_logged_tool(arg_formatter=_manifest_summary)
     def get_manifest(
         namespace: str,
@@ -1194,6 +1402,119 @@ def _build_tools(
             )
         )
 
+    @_logged_tool(arg_formatter=_manifest_summary)
+    def get_crossplane_resource_tree(
+        api_version: str,
+        resource: str,
+        name: str,
+        namespace: str | None = None,
+    ) -> dict[str, object]:
+        """Inspect a Crossplane claim/composite and its managed resources.
+
+        Use this when the workload consumes cloud resources provisioned by
+        Crossplane (e.g. a database claim whose connection secret the pod mounts).
+        Returns Ready/Synced conditions for the claim, composite and every
+        managed resource, plus an 'unhealthy' list with the failing conditions.
+
+        Args:
+            api_version: Claim or composite apiVersion (e.g. 'database.example.org/v1alpha1').
+            resource: Plural resource name (e.g. 'postgresqlinstances').
+            name: Resource name.
+            namespace: Claim namespace. Omit for cluster-scoped composites.
+        """
+        tree = k8s_client.get_crossplane_resource_tree(
+            api_version=api_version,
+            resource=resource,
+            name=name,
+            namespace=namespace,
+        )
+        if tree is None:
+            return _mask({"warning": "crossplane resource not found or unsupported"})
+        return _mask(tree)
+
+    @_logged_tool(result_formatter=_default_result_summary)
+    def get_keda_scaling_status(
+        namespace: str, workload: str | None = None
+    ) -> list[dict[str, object]]:
+        """Diagnose KEDA ScaledObjects that scale a workload.
+
+        Use this when the workload is autoscaled by KEDA and did not scale,
+        scaled too late, or flapped. Reports Ready/Active/Fallback conditions,
+        per-trigger failure counts, missing TriggerAuthentications and the
+        current external metric values (or the scaler error when the metric
+        cannot be read).
+
+        Args:
+            namespace: Namespace of the ScaledObjects.
+            workload: scaleTargetRef name (e.g. Deployment name). Omit to list all.
+        """
+        return _mask(k8s_client.get_keda_scaling_status(namespace, workload=workload))
+
+    @_logged_tool(arg_formatter=_namespace_name_summary)
+    def get_knative_service_status(namespace: str, name: str) -> dict[str, object]:
+        """Diagnose a Knative Service and its Revisions.
+
+        Use this for serverless workloads (pods labelled serving.knative.dev/service)
+        instead of Deployment-based reasoning. Reports why the latest revision
+        is not ready, traffic split, revisions scaled to zero (cold starts) and
+        whether requests are queued by the activator.
+
+        Args:
+            namespace: Namespace of the Knative Service.
+            name: Knative Service name.
+        """
+        status = k8s_client.get_knative_service_status(namespace, name)
+        if status is None:
+            return _mask({"warning": "knative service not found or knative not installed"})
+        return _mask(status)
+
+    @_logged_tool()
+    def get_velero_operation_status(
+        name: str | None = None,
+        operation: str = "backup",
+        velero_namespace: str = "velero",
+    ) -> dict[str, object]:
+        """Diagnose Velero backup or restore failures.
+
+        Use this for backup-related alerts. Returns phase, error/warning counts,
+        failure reason and validation errors, the pod volumes (file-system or CSI
+        data mover) that failed with their messages, and error lines from the
+        Velero server/plugin logs.
+
+        Args:
+            name: Backup or Restore name. Omit to inspect the most recent failures.
+            operation: 'backup' or 'restore'.
+            velero_namespace: Namespace where Velero is installed.
+        """
+        return _mask(
+            k8s_client.get_velero_operation_status(
+                name, operation=operation, velero_namespace=velero_namespace
+            )
+        )
+
+    @_logged_tool()
+    def get_external_dns_status(
+        hostname: str | None = None,
+        external_dns_namespace: str = "external-dns",
+    ) -> dict[str, object]:
+        """Diagnose external-dns record synchronization.
+
+        Use this for DNS-record alerts or when an Ingress/Service hostname does
+        not resolve. Groups external-dns error lines into rate_limited,
+        permission_denied and other_errors, and resolves the hostname to check
+        whether the record actually exists at the DNS provider.
+
+        Args:
+            hostname: Expected DNS name (e.g. from the
+                      external-dns.alpha.kubernetes.io/hostname annotation).
+            external_dns_namespace: Namespace where external-dns runs.
+        """
+        return _mask(
+            k8s_client.get_external_dns_status(
+                hostname, external_dns_namespace=external_dns_namespace
+            )
+        )
+
     @_logged_tool(
         arg_formatter=_namespace_selector_limit_summary,
         result_formatter=_default_result_summary,
@@ -1270,8 +1591,16 @@ def _build_tools(
         get_node_status,
         get_pod_metrics,
         get_node_metrics,
+        get_cluster_upgrade_status,
+        get_node_maintenance_status,
+        find_removed_api_usage,
         get_manifest,
         list_manifests,
+        get_crossplane_resource_tree,
+        get_keda_scaling_status,
+        get_knative_service_status,
+        get_velero_operation_status,
+        get_external_dns_status,
         list_virtual_services,
         list_destination_rules,
         list_service_entries,
@@ -1397,4 +1726,184 @@ def _build_tools(
                 query_loki_range,
             ]
         )
+
+    # --- Infrastructure change history ---
+
+    @_logged_tool(result_formatter=_default_result_summary)
+    def list_infrastructure_changes(
+        start: str | None = None,
+        end: str | None = None,
+    ) -> dict[str, object]:
+        """List Terraform Cloud runs in the configured workspaces around the incident.
+
+        Use this to check whether node pools, load balancers, DNS or other
+        infrastructure outside Kubernetes changed shortly before the alert.
+
+        Args:
+            start: Window start (RFC3339 or Unix timestamp). Defaults to
+                   TERRAFORM_LOOKBACK_MINUTES before end.
+            end: Window end (RFC3339 or Unix timestamp). Defaults to now.
+        """
+        if terraform_client is None:
+            return _mask({"warning": "terraform cloud not configured"})
+        return _mask(terraform_client.list_runs(start=start, end=end))
+
+    @_logged_tool(arg_formatter=_pod_lookup_summary)
+    def get_workload_image_metadata(namespace: str, pod_name: str) -> dict[str, object]:
+        """Identify the exact code version running in a pod's containers.
+
+        Use this when a deploy or code change is a suspected cause. Reports
+        each container's image and pulled digest, the OCI build labels from
+        the registry (git revision, source repository, build time, version),
+        a commit link when the source is a GitHub/GitLab URL, and SBOM or
+        provenance attestation references when enabled.
+
+        Args:
+            namespace: Namespace of the pod.
+            pod_name: Pod name.
+        """
+        images = k8s_client.get_pod_images(namespace, pod_name)
+        if images is None:
+            return _mask({"warning": "pod not found"})
+        if registry_client is not None:
+            containers = images.get("containers")
+            for container in containers if isinstance(containers, list) else []:
+                image = container.get("image")
+                if isinstance(image, str) and image:
+                    container["registry"] = registry_client.describe_image(
+                        image, container.get("image_id")
+                    )
+        return _mask(images)
+
+    @_logged_tool()
+    def get_rollout_code_changes(namespace: str, deployment: str) -> dict[str, object]:
+        """List the commits shipped by a Deployment's latest rollout.
+
+        Use this when the alert started shortly after a rollout. Compares the
+        git revisions of the current and previous ReplicaSet (from image
+        labels or pod template annotations) on GitHub/GitLab and flags changed
+        dependency manifests, configuration files and database migrations.
+        When CI checks are enabled, also reports whether the deployed commit
+        came from a pipeline with failed or skipped jobs (e.g. migrations).
+        Cite suspicious changes with their commit, not as proven causes.
+
+        Args:
+            namespace: Namespace of the Deployment.
+            deployment: Deployment name.
+        """
+        if code_changes is None:
+            return _mask({"warning": "git correlation not configured"})
+        return _mask(code_changes.correlate(namespace, deployment))
+
+    @_logged_tool()
+    def check_datastore_health(service: str) -> dict[str, object]:
+        """Check the databases and caches a service depends on.
+
+        Use this when application errors point at a datastore (connection
+        refused or timeouts, too many connections, stale reads). Reports
+        reachability, connection saturation and replication lag of the
+        Postgres, MySQL and Redis instances configured for the service.
+
+        Args:
+            service: Service name from DATASTORE_TARGETS_JSON, usually the
+                     alert's service or workload label.
+        """
+        if datastore_client is None:
+            return _mask({"warning": "datastore health checks not configured"})
+        return _mask(datastore_client.check_service(service))
+
+    @_logged_tool()
+    def analyze_kafka_consumer_lag(
+        consumer_group: str, topic: str | None = None
+    ) -> dict[str, object]:
+        """Find the lagging partitions of a Kafka consumer group and the bottleneck.
+
+        Use this for alerts with ``consumergroup``/``topic`` labels or consumer
+        lag symptoms. Reports per-partition lag and its growth, produce vs.
+        consume rates and group members, and flags under-replicated or
+        leaderless partitions. ``bottleneck`` is brokers, consumers,
+        partition_skew, none or unknown.
+
+        Args:
+            consumer_group: Kafka consumer group name.
+            topic: Optional topic to limit the analysis to.
+        """
+        if kafka_lag is None:
+            return _mask({"warning": "kafka lag analysis not configured"})
+        return _mask(kafka_lag.analyze(consumer_group, topic))
+
+    @_logged_tool()
+    def probe_external_endpoint(url: str) -> dict[str, object]:
+        """Probe a service's external URL to confirm or rule out user-facing impact.
+
+        Use the URL from the alert annotations (e.g. ``external_url``,
+        ``url``) or the Service/Ingress annotations and host. Sends one GET
+        and reports the status code, latency and TLS certificate validity
+        and expiry. ``impact`` is confirmed, degraded or none.
+
+        Args:
+            url: Absolute http:// or https:// URL of the endpoint.
+        """
+        if endpoint_probe is None:
+            return _mask({"warning": "endpoint probes not configured"})
+        return _mask(endpoint_probe.probe(url))
+
+    @_logged_tool()
+    def query_observability_events(
+        service_name: str | None = None,
+        calculations: list[str] | None = None,
+        filters: list[str] | None = None,
+        breakdowns: list[str] | None = None,
+        start: str | None = None,
+        end: str | None = None,
+        dataset: str | None = None,
+        limit: int = 20,
+    ) -> dict[str, object]:
+        """Query high-cardinality trace spans and events (Honeycomb or an OTLP backend).
+
+        Use this for services instrumented with OpenTelemetry to break errors
+        or latency down by any attribute (customer, endpoint, version, region)
+        that Prometheus labels do not carry.
+
+        Args:
+            service_name: Limit to spans of this service (service.name).
+            calculations: e.g. ["COUNT", "P99(duration_ms)", "COUNT_DISTINCT(trace.trace_id)"].
+                          Defaults to ["COUNT"]; results are ordered by the first one.
+            filters: "field op value" strings joined with AND, e.g.
+                     ["http.status_code >= 500", "error = true", "db.system exists"].
+            breakdowns: Attributes to group by, e.g. ["http.route", "k8s.pod.name"].
+            start: Start time (RFC3339 or Unix timestamp); default is the last hour.
+            end: End time (RFC3339 or Unix timestamp).
+            dataset: Dataset to query (OBSERVABILITY_DATASET by default).
+            limit: Max result rows.
+        """
+        if observability is None:
+            return _mask({"warning": "observability backend not configured"})
+        return _mask(
+            observability.query(
+                service_name=service_name,
+                calculations=calculations,
+                filters=filters,
+                breakdowns=breakdowns,
+                start=start,
+                end=end,
+                dataset=dataset,
+                limit=limit,
+            )
+        )
+
+    if terraform_client is not None:
+        tools.append(list_infrastructure_changes)
+    if code_changes is not None:
+        tools.append(get_rollout_code_changes)
+    if registry_client is not None:
+        tools.append(get_workload_image_metadata)
+    if datastore_client is not None:
+        tools.append(check_datastore_health)
+    if kafka_lag is not None:
+        tools.append(analyze_kafka_consumer_lag)
+    if endpoint_probe is not None:
+        tools.append(probe_external_endpoint)
+    if observability is not None:
+        tools.append(query_observability_events)
     return tools
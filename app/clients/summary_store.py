@@ -1,24 +1,30 @@
 from __future__ import annotations
 
 import logging
+from datetime import datetime
 from typing import Protocol
 
 import psycopg
 from psycopg.errors import DuplicateTable, UniqueViolation
 from psycopg.rows import dict_row
 
+from app.core.encryption import FieldCipher
+
 
 class SummaryStore(Protocol):
     def list_summaries(self, session_id: str, limit: int) -> list[str]:
         raise NotImplementedError
 
-    def append_summary(self, session_id: str, summary: str, max_items: int) -> None:
+    def append_summary(
+        self, session_id: str, summary: str, max_items: int, namespace: str | None = None
+    ) -> None:
         raise NotImplementedError
 
 
 class PostgresSummaryStore(SummaryStore):
-    def __init__(self, dsn: str) -> None:
+    def __init__(self, dsn: str, cipher: FieldCipher | None = None) -> None:
         self._dsn = dsn
+        self._cipher = cipher
         self._logger = logging.getLogger(__name__)
         self._ensure_schema()
 
@@ -39,6 +45,19 @@ class PostgresSummaryStore(SummaryStore):
             CREATE INDEX IF NOT EXISTS kube_rca_session_summaries_lookup_idx
             ON kube_rca_session_summaries(session_id, summary_id DESC)
             """,
+            """
+            CREATE INDEX IF NOT EXISTS kube_rca_session_summaries_created_at_idx
+            ON kube_rca_session_summaries(created_at)
+            """,
+            # Alert namespace, used to scope deletion requests.
+            """
+            ALTER TABLE kube_rca_session_summaries
+            ADD COLUMN IF NOT EXISTS namespace TEXT
+            """,
+            """
+            CREATE INDEX IF NOT EXISTS kube_rca_session_summaries_namespace_idx
+            ON kube_rca_session_summaries(namespace)
+            """,
         ]
         try:
             with self._connect() as conn:
@@ -62,20 +81,26 @@ class PostgresSummaryStore(SummaryStore):
                 cur.execute(query, (session_id, limit))
                 rows = cur.fetchall()
         summaries = [row["summary"] for row in rows]
+        if self._cipher is not None:
+            summaries = [self._cipher.decrypt_text(summary) for summary in summaries]
         return list(reversed(summaries))
 
-    def append_summary(self, session_id: str, summary: str, max_items: int) -> None:
+    def append_summary(
+        self, session_id: str, summary: str, max_items: int, namespace: str | None = None
+    ) -> None:
         if max_items <= 0:
             return
+        if self._cipher is not None:
+            summary = self._cipher.encrypt_text(summary)
         try:
             with self._connect() as conn:
                 with conn.cursor() as cur:
                     cur.execute(
                         """
-                        INSERT INTO kube_rca_session_summaries (session_id, summary)
-                        VALUES (%s, %s)
+                        INSERT INTO kube_rca_session_summaries (session_id, summary, namespace)
+                        VALUES (%s, %s, %s)
                         """,
-                        (session_id, summary),
+                        (session_id, summary, namespace),
                     )
                     cur.execute(
                         """
@@ -93,3 +118,52 @@ class PostgresSummaryStore(SummaryStore):
                     )
         except Exception as exc:  # noqa: BLE001
             self._logger.warning("Failed to append session summary: %s", exc)
+
+    def purge_older_than(self, older_than_days: int) -> int:
+        with self._connect() as conn:
+            with conn.cursor() as cur:
+                cur.execute(
+                    """
+                    DELETE FROM kube_rca_session_summaries
+                    WHERE created_at < NOW() - make_interval(days => %s)
+                    """,
+                    (older_than_days,),
+                )
+                return cur.rowcount
+
+    def delete_summaries(
+        self,
+        *,
+        namespace: str | None = None,
+        session_prefix: str | None = None,
+        since: datetime | None = None,
+        until: datetime | None = None,
+    ) -> list[str]:
+        """Hard-delete matching summaries and return the session key of each deleted row."""
+        conditions: list[str] = []
+        params: list[object] = []
+        if namespace:
+            conditions.append("namespace = %s")
+            params.append(namespace)
+        if session_prefix:
+            conditions.append("starts_with(session_id, %s)")
+            params.append(session_prefix)
+        if since is not None:
+            conditions.append("created_at >= %s")
+            params.append(since)
+        if until is not None:
+            conditions.append("created_at < %s")
+            params.append(until)
+        if not conditions:
+            raise ValueError("at least one deletion filter is required")
+
+        query = (
+            "DELETE FROM kube_rca_session_summaries WHERE "
+            + " AND ".join(conditions)
+            + " RETURNING session_id"
+        )
+        with self._connect() as conn:
+            with conn.cursor() as cur:
+                cur.execute(query, tuple(params))
+                rows = cur.fetchall()
+        return [row["session_id"] for row in rows]
@@ -0,0 +1,260 @@
+from __future__ import annotations
+
+import json
+import logging
+from dataclasses import asdict, dataclass
+from datetime import datetime
+from typing import Any, Protocol
+
+import psycopg
+from psycopg.errors import DuplicateTable, UniqueViolation
+from psycopg.rows import dict_row
+
+
+@dataclass(frozen=True)
+class Suppression:
+    suppression_id: int
+    matchers: list[dict[str, str]]
+    reason: str
+    created_by: str | None
+    created_at: datetime
+    starts_at: datetime
+    ends_at: datetime
+    expired_at: datetime | None = None
+    expired_by: str | None = None
+    hits: int = 0
+    last_hit_at: datetime | None = None
+
+    def active(self, at: datetime) -> bool:
+        return self.expired_at is None and self.starts_at <= at < self.ends_at
+
+    def to_dict(self) -> dict[str, object]:
+        return {
+            key: value.isoformat() if isinstance(value, datetime) else value
+            for key, value in asdict(self).items()
+        }
+
+
+class SuppressionStore(Protocol):
+    def create(
+        self,
+        matchers: list[dict[str, str]],
+        *,
+        reason: str,
+        created_by: str | None,
+        starts_at: datetime,
+        ends_at: datetime,
+    ) -> Suppression: ...
+
+    def get(self, suppression_id: int) -> Suppression | None: ...
+
+    def list_suppressions(
+        self, *, active_at: datetime | None = None, limit: int = 100
+    ) -> list[Suppression]: ...
+
+    def expire(
+        self, suppression_id: int, *, expired_by: str | None, at: datetime
+    ) -> Suppression | None: ...
+
+    def record_hit(
+        self,
+        suppression_id: int,
+        *,
+        alertname: str | None,
+        namespace: str | None,
+        fingerprint: str | None,
+        at: datetime,
+    ) -> None: ...
+
+    def list_hits(self, suppression_id: int, *, limit: int = 50) -> list[dict[str, object]]: ...
+
+
+class PostgresSuppressionStore:
+    """Suppression windows and an audit trail of the alerts they suppressed.
+
+    Expired or deleted suppressions are kept with who ended them, so the list
+    of past windows stays auditable; every suppressed alert adds a hit row.
+    """
+
+    def __init__(self, dsn: str) -> None:
+        self._dsn = dsn
+        self._logger = logging.getLogger(__name__)
+        self._ensure_schema()
+
+    def _connect(self) -> psycopg.Connection:
+        return psycopg.connect(self._dsn, row_factory=dict_row)
+
+    def _ensure_schema(self) -> None:
+        statements = [
+            """
+            CREATE TABLE IF NOT EXISTS kube_rca_suppressions (
+                suppression_id BIGSERIAL PRIMARY KEY,
+                matchers TEXT NOT NULL,
+                reason TEXT NOT NULL,
+                created_by TEXT,
+                created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+                starts_at TIMESTAMPTZ NOT NULL,
+                ends_at TIMESTAMPTZ NOT NULL,
+                expired_at TIMESTAMPTZ,
+                expired_by TEXT
+            )
+            """,
+            """
+            CREATE INDEX IF NOT EXISTS kube_rca_suppressions_ends_at_idx
+            ON kube_rca_suppressions(ends_at)
+            """,
+            """
+            CREATE TABLE IF NOT EXISTS kube_rca_suppression_hits (
+                hit_id BIGSERIAL PRIMARY KEY,
+                suppression_id BIGINT NOT NULL
+                    REFERENCES kube_rca_suppressions(suppression_id) ON DELETE CASCADE,
+                alertname TEXT,
+                namespace TEXT,
+                fingerprint TEXT,
+                suppressed_at TIMESTAMPTZ NOT NULL
+            )
+            """,
+            """
+            CREATE INDEX IF NOT EXISTS kube_rca_suppression_hits_suppression_idx
+            ON kube_rca_suppression_hits(suppression_id, hit_id DESC)
+            """,
+        ]
+        try:
+            with self._connect() as conn:
+                with conn.cursor() as cur:
+                    for statement in statements:
+                        cur.execute(statement)
+        except (UniqueViolation, DuplicateTable) as exc:
+            self._logger.debug("Schema already exists, skipping creation: %s", exc)
+
+    def create(
+        self,
+        matchers: list[dict[str, str]],
+        *,
+        reason: str,
+        created_by: str | None,
+        starts_at: datetime,
+        ends_at: datetime,
+    ) -> Suppression:
+        with self._connect() as conn:
+            with conn.cursor() as cur:
+                cur.execute(
+                    """
+                    INSERT INTO kube_rca_suppressions (
+                        matchers, reason, created_by, starts_at, ends_at
+                    )
+                    VALUES (%s, %s, %s, %s, %s)
+                    RETURNING *
+                    """,
+                    (json.dumps(matchers), reason, created_by, starts_at, ends_at),
+                )
+                row = cur.fetchone()
+        return _suppression({**row, "hits": 0, "last_hit_at": None})
+
+    def get(self, suppression_id: int) -> Suppression | None:
+        rows = self._select("WHERE s.suppression_id = %s", (suppression_id,))
+        return rows[0] if rows else None
+
+    def list_suppressions(
+        self, *, active_at: datetime | None = None, limit: int = 100
+    ) -> list[Suppression]:
+        """Newest first; only the windows active at *active_at* when it is given."""
+        if active_at is None:
+            return self._select("", (), limit=limit)
+        return self._select(
+            "WHERE s.expired_at IS NULL AND s.starts_at <= %s AND s.ends_at > %s",
+            (active_at, active_at),
+            limit=limit,
+        )
+
+    def expire(
+        self, suppression_id: int, *, expired_by: str | None, at: datetime
+    ) -> Suppression | None:
+        """End a suppression now; ``None`` when it does not exist or already ended."""
+        with self._connect() as conn:
+            with conn.cursor() as cur:
+                cur.execute(
+                    """
+                    UPDATE kube_rca_suppressions
+                    SET expired_at = %s, expired_by = %s
+                    WHERE suppression_id = %s AND expired_at IS NULL AND ends_at > %s
+                    """,
+                    (at, expired_by, suppression_id, at),
+                )
+                if cur.rowcount == 0:
+                    return None
+        return self.get(suppression_id)
+
+    def record_hit(
+        self,
+        suppression_id: int,
+        *,
+        alertname: str | None,
+        namespace: str | None,
+        fingerprint: str | None,
+        at: datetime,
+    ) -> None:
+        with self._connect() as conn:
+            with conn.cursor() as cur:
+                cur.execute(
+                    """
+                    INSERT INTO kube_rca_suppression_hits (
+                        suppression_id, alertname, namespace, fingerprint, suppressed_at
+                    )
+                    VALUES (%s, %s, %s, %s, %s)
+                    """,
+                    (suppression_id, alertname, namespace, fingerprint, at),
+                )
+
+    def list_hits(self, suppression_id: int, *, limit: int = 50) -> list[dict[str, object]]:
+        with self._connect() as conn:
+            with conn.cursor() as cur:
+                cur.execute(
+                    """
+                    SELECT alertname, namespace, fingerprint, suppressed_at
+                    FROM kube_rca_suppression_hits
+                    WHERE suppression_id = %s
+                    ORDER BY hit_id DESC
+                    LIMIT %s
+                    """,
+                    (suppression_id, limit),
+                )
+                rows = cur.fetchall()
+        return [
+            {**row, "suppressed_at": row["suppressed_at"].isoformat()}
+            if isinstance(row.get("suppressed_at"), datetime)
+            else dict(row)
+            for row in rows
+        ]
+
+    def _select(
+        self, where: str, params: tuple[object, ...], *, limit: int = 1
+    ) -> list[Suppression]:
+        query = (
+            "SELECT s.*, COUNT(h.hit_id) AS hits, MAX(h.suppressed_at) AS last_hit_at "
+            "FROM kube_rca_suppressions s "
+            "LEFT JOIN kube_rca_suppression_hits h USING (suppression_id) "
+            + where
+            + " GROUP BY s.suppression_id ORDER BY s.suppression_id DESC LIMIT %s"
+        )
+        with self._connect() as conn:
+            with conn.cursor() as cur:
+                cur.execute(query, (*params, limit))
+                rows = cur.fetchall()
+        return [_suppression(row) for row in rows]
+
+
+def _suppression(row: dict[str, Any]) -> Suppression:
+    return Suppression(
+        suppression_id=int(row["suppression_id"]),
+        matchers=json.loads(row["matchers"]),
+        reason=row["reason"],
+        created_by=row.get("created_by"),
+        created_at=row["created_at"],
+        starts_at=row["starts_at"],
+        ends_at=row["ends_at"],
+        expired_at=row.get("expired_at"),
+        expired_by=row.get("expired_by"),
+        hits=int(row.get("hits") or 0),
+        last_hit_at=row.get("last_hit_at"),
+    )
@@ -8,7 +8,10 @@ import urllib.request
 from dataclasses import dataclass
 from datetime import datetime, timezone
 
+from app.core.chaos import maybe_inject_fault
 from app.core.config import Settings
+from app.core.egress import check_egress
+from app.core.tls import open_url
 
 
 @dataclass(frozen=True)
@@ -155,7 +158,9 @@ class TempoClient:
 
         request = urllib.request.Request(url, headers=headers)
         try:
-            with urllib.request.urlopen(request, timeout=self._timeout_seconds) as response:
+            check_egress(url)
+            maybe_inject_fault("tempo")
+            with open_url(request, timeout=self._timeout_seconds) as response:
                 payload = response.read()
         except urllib.error.HTTPError as exc:
             body = exc.read().decode("utf-8", errors="replace")
@@ -0,0 +1,172 @@
+from __future__ import annotations
+
+import json
+import logging
+import urllib.error
+import urllib.parse
+import urllib.request
+from datetime import datetime, timedelta, timezone
+
+from app.core.config import Settings
+from app.core.egress import check_egress
+from app.core.tls import open_url
+
+_RUN_PAGE_SIZE = 20
+# Runs in these states changed (or are about to change) real infrastructure.
+_APPLY_STATUSES = {"applying", "applied", "apply_queued", "errored"}
+
+
+class TerraformCloudClient:
+    """Read run history from Terraform Cloud / Terraform Enterprise workspaces.
+
+    Used to correlate alerts with infrastructure changes made outside
+    Kubernetes (node pools, load balancers, DNS).
+    """
+
+    def __init__(self, settings: Settings) -> None:
+        self._logger = logging.getLogger(__name__)
+        self._base_url = settings.terraform_cloud_url.strip().rstrip("/")
+        self._token = settings.terraform_cloud_token.strip()
+        self._workspace_ids = [item for item in settings.terraform_workspace_ids if item]
+        self._timeout_seconds = settings.terraform_http_timeout_seconds
+        self._lookback_minutes = settings.terraform_lookback_minutes
+
+    @property
+    def enabled(self) -> bool:
+        return bool(self._base_url and self._token and self._workspace_ids)
+
+    def list_runs(self, start: str | None = None, end: str | None = None) -> dict[str, object]:
+        """Return runs that were created or applied within [start, end]."""
+        if not self.enabled:
+            return {"warning": "terraform cloud not configured"}
+
+        window_end = _parse_time(end) or datetime.now(timezone.utc)
+        window_start = _parse_time(start) or window_end - timedelta(minutes=self._lookback_minutes)
+
+        runs: list[dict[str, object]] = []
+        errors: list[dict[str, object]] = []
+        for workspace_id in self._workspace_ids:
+            payload, error = self._request_runs(workspace_id)
+            if error is not None:
+                errors.append(error)
+                continue
+            for run in _extract_runs(payload, workspace_id):
+                timestamp = _parse_time(str(run.get("applied_at") or run.get("created_at") or ""))
+                if timestamp is not None and window_start <= timestamp <= window_end:
+                    runs.append(run)
+
+        runs.sort(key=lambda item: str(item.get("applied_at") or item.get("created_at") or ""))
+        result: dict[str, object] = {
+            "window": {"start": window_start.isoformat(), "end": window_end.isoformat()},
+            "run_count": len(runs),
+            "applied_run_count": sum(1 for run in runs if run.get("status") in _APPLY_STATUSES),
+            "runs": runs,
+        }
+        if errors:
+            result["errors"] = errors
+        return result
+
+    def _request_runs(
+        self, workspace_id: str
+    ) -> tuple[dict[str, object] | None, dict[str, object] | None]:
+        workspace_path = urllib.parse.quote(workspace_id, safe="")
+        params = urllib.parse.urlencode({"page[size]": str(_RUN_PAGE_SIZE), "include": "plan"})
+        url = f"{self._base_url}/api/v2/workspaces/{workspace_path}/runs?{params}"
+        request = urllib.request.Request(
+            url,
+            headers={
+                "Accept": "application/vnd.api+json",
+                "Authorization": f"Bearer {self._token}",
+            },
+        )
+        try:
+            check_egress(url)
+            with open_url(request, timeout=self._timeout_seconds) as response:
+                payload = json.loads(response.read().decode("utf-8"))
+        except urllib.error.HTTPError as exc:
+            self._logger.warning("Terraform Cloud HTTP error %s for %s", exc.code, workspace_id)
+            return None, {
+                "workspace_id": workspace_id,
+                "status_code": exc.code,
+                "reason": str(exc.reason),
+            }
+        except Exception as exc:  # noqa: BLE001
+            self._logger.warning("Failed to query Terraform Cloud runs: %s", exc)
+            return None, {"workspace_id": workspace_id, "reason": str(exc)}
+
+        if not isinstance(payload, dict):
+            return None, {"workspace_id": workspace_id, "reason": "unexpected payload type"}
+        return payload, None
+
+
+def _extract_runs(payload: dict[str, object], workspace_id: str) -> list[dict[str, object]]:
+    plans: dict[str, dict[str, object]] = {}
+    included = payload.get("included")
+    if isinstance(included, list):
+        for item in included:
+            if isinstance(item, dict) and item.get("type") == "plans":
+                attributes = item.get("attributes")
+                if isinstance(attributes, dict):
+                    plans[str(item.get("id"))] = attributes
+
+    data = payload.get("data")
+    if not isinstance(data, list):
+        return []
+    runs: list[dict[str, object]] = []
+    for item in data:
+        if not isinstance(item, dict):
+            continue
+        attributes = item.get("attributes")
+        if not isinstance(attributes, dict):
+            continue
+        timestamps = attributes.get("status-timestamps")
+        if not isinstance(timestamps, dict):
+            timestamps = {}
+        run: dict[str, object] = {
+            "workspace_id": workspace_id,
+            "run_id": item.get("id"),
+            "status": attributes.get("status"),
+            "message": attributes.get("message"),
+            "source": attributes.get("source"),
+            "is_destroy": attributes.get("is-destroy"),
+            "created_at": attributes.get("created-at"),
+            "applied_at": timestamps.get("applied-at"),
+        }
+        plan_id = _relationship_id(item, "plan")
+        plan = plans.get(plan_id or "")
+        if plan is not None:
+            run["resource_changes"] = {
+                "add": plan.get("resource-additions"),
+                "change": plan.get("resource-changes"),
+                "destroy": plan.get("resource-destructions"),
+            }
+        runs.append(run)
+    return runs
+
+
+def _relationship_id(item: dict[str, object], name: str) -> str | None:
+    relationships = item.get("relationships")
+    if not isinstance(relationships, dict):
+        return None
+    relation = relationships.get(name)
+    data = relation.get("data") if isinstance(relation, dict) else None
+    if isinstance(data, dict) and data.get("id"):
+        return str(data["id"])
+    return None
+
+
+def _parse_time(value: str | None) -> datetime | None:
+    if not value:
+        return None
+    raw = value.strip()
+    try:
+        return datetime.fromtimestamp(float(raw), tz=timezone.utc)
+    except ValueError:
+        pass
+    try:
+        parsed = datetime.fromisoformat(raw.replace("Z", "+00:00"))
+    except ValueError:
+        return None
+    if parsed.tzinfo is None:
+        return parsed.replace(tzinfo=timezone.utc)
+    return parsed
@@ -0,0 +1,131 @@
+from __future__ import annotations
+
+import json
+import urllib.request
+from collections.abc import Sequence
+from dataclasses import dataclass
+from typing import Any, Protocol
+
+import jwt
+
+from app.core.egress import check_egress
+
+_ALLOWED_ALGORITHMS = ["RS256", "RS384", "RS512", "ES256", "ES384", "PS256"]
+_DISCOVERY_TIMEOUT_SECONDS = 5
+
+
+class _SigningKeyResolver(Protocol):
+    def get_signing_key_from_jwt(self, token: str) -> Any: ...
+
+
+class AuthenticationError(Exception):
+    """Token is missing, malformed, expired or not issued for this agent (HTTP 401)."""
+
+
+class AuthorizationError(Exception):
+    """Token is valid but the caller is not in an allowed group (HTTP 403)."""
+
+
+@dataclass(frozen=True)
+class Principal:
+    subject: str
+    groups: tuple[str, ...]
+
+
+class OIDCVerifier:
+    """Validate OIDC bearer tokens against the issuer's JWKS.
+
+    The JWKS URL is discovered from ``<issuer>/.well-known/openid-configuration``
+    unless given explicitly. When ``allowed_groups`` is empty any valid token
+    is authorized.
+    """
+
+    def __init__(
+        self,
+        issuer: str,
+        audience: str,
+        *,
+        jwks_url: str = "",
+        groups_claim: str = "groups",
+        allowed_groups: Sequence[str] = (),
+        key_resolver: _SigningKeyResolver | None = None,
+    ) -> None:
+        # Compared exactly: some providers (e.g. Auth0) issue ``iss`` with a trailing slash.
+        self._issuer = issuer
+        self._audience = audience
+        self._jwks_url = jwks_url
+        self._groups_claim = groups_claim
+        self._allowed_groups = frozenset(allowed_groups)
+        self._key_resolver = key_resolver
+
+    def authenticate(self, token: str) -> Principal:
+        try:
+            signing_key = self._resolver().get_signing_key_from_jwt(token)
+            claims = jwt.decode(
+                token,
+                signing_key.key,
+                algorithms=_ALLOWED_ALGORITHMS,
+                audience=self._audience,
+                issuer=self._issuer,
+                options={"require": ["exp", "iss", "aud", "sub"]},
+            )
+        except (jwt.PyJWTError, ValueError) as exc:
+            raise AuthenticationError(f"invalid token: {exc}") from exc
+
+        principal = Principal(subject=str(claims["sub"]), groups=self._extract_groups(claims))
+        if self._allowed_groups and not self._allowed_groups.intersection(principal.groups):
+            raise AuthorizationError(f"subject {principal.subject} is not in an allowed group")
+        return principal
+
+    def _extract_groups(self, claims: dict[str, Any]) -> tuple[str, ...]:
+        value: Any = claims
+        # Nested claims such as "realm_access.roles" (Keycloak).
+        for part in self._groups_claim.split("."):
+            value = value.get(part) if isinstance(value, dict) else None
+        if isinstance(value, str):
+            return (value,)
+        if isinstance(value, list):
+            return tuple(item for item in value if isinstance(item, str))
+        return ()
+
+    def _resolver(self) -> _SigningKeyResolver:
+        if self._key_resolver is None:
+            jwks_url = self._jwks_url or _discover_jwks_url(self._issuer)
+            check_egress(jwks_url)
+            self._key_resolver = jwt.PyJWKClient(jwks_url, cache_keys=True)
+        return self._key_resolver
+
+
+def _discover_jwks_url(issuer: str) -> str:
+    url = f"{issuer.rstrip('/')}/.well-known/openid-configuration"
+    check_egress(url)
+    try:
+        with urllib.request.urlopen(url, timeout=_DISCOVERY_TIMEOUT_SECONDS) as response:
+            document = json.loads(response.read().decode("utf-8"))
+    except (OSError, json.JSONDecodeError) as exc:
+        raise ValueError(f"OIDC discovery failed for {issuer}: {exc}") from exc
+    jwks_uri = document.get("jwks_uri") if isinstance(document, dict) else None
+    if not isinstance(jwks_uri, str) or not jwks_uri:
+        raise ValueError(f"OIDC discovery document of {issuer} has no jwks_uri")
+    return jwks_uri
+
+
+def build_oidc_verifier(
+    issuer: str,
+    audience: str,
+    *,
+    jwks_url: str = "",
+    groups_claim: str = "groups",
+    allowed_groups: Sequence[str] = (),
+) -> OIDCVerifier | None:
+    if not issuer:
+        return None
+    if not audience:
+        raise ValueError("OIDC_AUDIENCE is required when OIDC_ISSUER_URL is set")
+    return OIDCVerifier(
+        issuer,
+        audience,
+        jwks_url=jwks_url,
+        groups_claim=groups_claim,
+        allowed_groups=allowed_groups,
+    )
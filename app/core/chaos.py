@@ -0,0 +1,112 @@
+from __future__ import annotations
+
+import functools
+import logging
+import random
+import time
+from collections.abc import Iterable
+from dataclasses import dataclass
+from typing import Any
+
+logger = logging.getLogger(__name__)
+
+FAULT_TARGETS = frozenset({"k8s", "prometheus", "loki", "tempo", "llm"})
+
+
+class InjectedFault(Exception):
+    """Artificial failure raised in chaos mode.
+
+    ``status_code`` is 503 so the LLM retry policy treats it as transient.
+    """
+
+    status_code = 503
+
+
+@dataclass(frozen=True)
+class FaultInjector:
+    targets: frozenset[str]
+    error_rate: float = 0.0
+    latency_ms: int = 0
+    latency_jitter_ms: int = 0
+
+    def applies_to(self, target: str) -> bool:
+        return not self.targets or target in self.targets
+
+    def inject(self, target: str) -> None:
+        if not self.applies_to(target):
+            return
+        delay_ms = self.latency_ms
+        if self.latency_jitter_ms > 0:
+            delay_ms += random.randint(0, self.latency_jitter_ms)
+        if delay_ms > 0:
+            time.sleep(delay_ms / 1000)
+        if self.error_rate > 0 and random.random() < self.error_rate:
+            logger.warning("chaos_fault_injected target=%s", target)
+            raise InjectedFault(f"chaos: injected {target} failure")
+
+
+_injector: FaultInjector | None = None
+
+
+def init_fault_injection(
+    enabled: bool,
+    targets: Iterable[str] = (),
+    error_rate: float = 0.0,
+    latency_ms: int = 0,
+    latency_jitter_ms: int = 0,
+) -> None:
+    """Configure the process-wide fault injector (disabled unless *enabled*)."""
+    global _injector  # noqa: PLW0603
+    if not enabled:
+        _injector = None
+        return
+
+    normalized = frozenset(target.strip().lower() for target in targets if target.strip())
+    unknown = normalized - FAULT_TARGETS
+    if unknown:
+        raise ValueError(f"CHAOS_TARGETS contains unknown targets: {sorted(unknown)}")
+    _injector = FaultInjector(
+        targets=normalized,
+        error_rate=min(max(error_rate, 0.0), 1.0),
+        latency_ms=max(0, latency_ms),
+        latency_jitter_ms=max(0, latency_jitter_ms),
+    )
+    logger.warning(
+        "Chaos mode enabled (targets=%s, error_rate=%.2f, latency_ms=%d, jitter_ms=%d)",
+        ",".join(sorted(normalized)) or "all",
+        _injector.error_rate,
+        _injector.latency_ms,
+        _injector.latency_jitter_ms,
+    )
+
+
+def maybe_inject_fault(target: str) -> None:
+    """Sleep and/or raise InjectedFault for *target* when chaos mode is enabled."""
+    if _injector is not None:
+        _injector.inject(target)
+
+
+def wrap_with_faults(api: Any, target: str) -> Any:
+    """Wrap an API client so each method call passes through the fault injector."""
+    if api is None or _injector is None or not _injector.applies_to(target):
+        return api
+    return _FaultInjectingProxy(api, target)
+
+
+class _FaultInjectingProxy:
+    def __init__(self, wrapped: Any, target: str) -> None:
+        self._wrapped = wrapped
+        self._target = target
+
+    def __getattr__(self, name: str) -> Any:
+        attr = getattr(self._wrapped, name)
+        if name.startswith("_") or not callable(attr):
+            return attr
+
+        # Keep the docstring: kubernetes.watch reads the return type from it.
+        @functools.wraps(attr)
+        def _call(*args: Any, **kwargs: Any) -> Any:
+            maybe_inject_fault(self._target)
+            return attr(*args, **kwargs)
+
+        return _call
@@ -0,0 +1,119 @@
+from __future__ import annotations
+
+import json
+import logging
+import zlib
+from collections.abc import Awaitable, Callable, MutableMapping
+from typing import Any
+
+Scope = MutableMapping[str, Any]
+Message = MutableMapping[str, Any]
+Receive = Callable[[], Awaitable[Message]]
+Send = Callable[[Message], Awaitable[None]]
+ASGIApp = Callable[[Scope, Receive, Send], Awaitable[None]]
+
+logger = logging.getLogger(__name__)
+
+_GZIP_WBITS = 16 + zlib.MAX_WBITS
+
+
+class RequestBodyTooLarge(ValueError):
+    pass
+
+
+def decompress_gzip(payload: bytes, max_bytes: int) -> bytes:
+    """Decompress a gzip payload, refusing output larger than *max_bytes*."""
+    decompressor = zlib.decompressobj(_GZIP_WBITS)
+    body = decompressor.decompress(payload, max_bytes + 1)
+    if len(body) > max_bytes or decompressor.unconsumed_tail:
+        raise RequestBodyTooLarge(f"decompressed body exceeds {max_bytes} bytes")
+    body += decompressor.flush()
+    if len(body) > max_bytes:
+        raise RequestBodyTooLarge(f"decompressed body exceeds {max_bytes} bytes")
+    if not decompressor.eof:
+        raise zlib.error("incomplete gzip stream")
+    return body
+
+
+class GzipRequestMiddleware:
+    """Transparently decode request bodies sent with ``Content-Encoding: gzip``.
+
+    Response compression is handled separately by Starlette's GZipMiddleware.
+    """
+
+    def __init__(self, app: ASGIApp, max_body_bytes: int) -> None:
+        self._app = app
+        self._max_body_bytes = max_body_bytes
+
+    async def __call__(self, scope: Scope, receive: Receive, send: Send) -> None:
+        if scope["type"] != "http" or not _is_gzip_encoded(scope):
+            await self._app(scope, receive, send)
+            return
+
+        chunks: list[bytes] = []
+        received = 0
+        while True:
+            message = await receive()
+            if message["type"] == "http.disconnect":
+                return
+            chunk = message.get("body", b"")
+            received += len(chunk)
+            if received > self._max_body_bytes:
+                await _send_error(send, 413, "request body too large")
+                return
+            chunks.append(chunk)
+            if not message.get("more_body", False):
+                break
+
+        try:
+            body = decompress_gzip(b"".join(chunks), self._max_body_bytes)
+        except RequestBodyTooLarge:
+            await _send_error(send, 413, "request body too large")
+            return
+        except zlib.error as exc:
+            logger.warning("Rejected invalid gzip request body: %s", exc)
+            await _send_error(send, 400, "invalid gzip request body")
+            return
+
+        headers = [
+            (key, value)
+            for key, value in scope.get("headers", [])
+            if key.lower() not in (b"content-encoding", b"content-length")
+        ]
+        headers.append((b"content-length", str(len(body)).encode("latin-1")))
+        scope = dict(scope)
+        scope["headers"] = headers
+
+        body_sent = False
+
+        async def receive_decoded() -> Message:
+            nonlocal body_sent
+            if body_sent:
+                return await receive()
+            body_sent = True
+            return {"type": "http.request", "body": body, "more_body": False}
+
+        await self._app(scope, receive_decoded, send)
+
+
+def _is_gzip_encoded(scope: Scope) -> bool:
+    for key, value in scope.get("headers", []):
+        if key.lower() == b"content-encoding":
+            encodings = [item.strip() for item in value.decode("latin-1").lower().split(",")]
+            return "gzip" in encodings
+    return False
+
+
+async def _send_error(send: Send, status: int, detail: str) -> None:
+    payload = json.dumps({"detail": detail}).encode("utf-8")
+    await send(
+        {
+            "type": "http.response.start",
+            "status": status,
+            "headers": [
+                (b"content-type", b"application/json"),
+                (b"content-length", str(len(payload)).encode("latin-1")),
+            ],
+        }
+    )
+    await send({"type": "http.response.body", "body": payload})
@@ -7,17 +7,66 @@ from typing import Any, TypeVar
 
 from fastapi import Request
 
+from app.core.memory import MemoryPressureMonitor, scale_limit
+
 T = TypeVar("T")
 
 _semaphore: asyncio.Semaphore | None = None
+_max_concurrent = 1
+_active = 0
+_memory_monitor: MemoryPressureMonitor | None = None
 
 logger = logging.getLogger(__name__)
 
 
-def init_concurrency(max_concurrent: int) -> None:
-    """Initialize the global concurrency limiter for analysis requests."""
-    global _semaphore  # noqa: PLW0603
-    _semaphore = asyncio.Semaphore(max(1, max_concurrent))
+class ConcurrencyLimitExceeded(RuntimeError):
+    """No analysis slot freed up within the caller's wait limit."""
+
+
+def init_concurrency(
+    max_concurrent: int, memory_monitor: MemoryPressureMonitor | None = None
+) -> None:
+    """Initialize the global concurrency limiter for analysis requests.
+
+    When *memory_monitor* is provided, the effective limit shrinks as memory
+    pressure rises (down to a single analysis at critical pressure).
+    """
+    global _semaphore, _max_concurrent, _active, _memory_monitor  # noqa: PLW0603
+    _max_concurrent = max(1, max_concurrent)
+    _semaphore = asyncio.Semaphore(_max_concurrent)
+    _active = 0
+    _memory_monitor = memory_monitor
+
+
+def effective_concurrency_limit() -> int:
+    if _memory_monitor is None or not _memory_monitor.enabled:
+        return _max_concurrent
+    return scale_limit(_max_concurrent, _memory_monitor.scale())
+
+
+async def _wait_for_memory_slot() -> None:
+    """Block while the number of running analyses exceeds the memory-aware limit."""
+    logged = False
+    while _active >= effective_concurrency_limit():
+        if not logged:
+            logger.warning(
+                "memory_pressure — throttling analyses (active=%d, limit=%d)",
+                _active,
+                effective_concurrency_limit(),
+            )
+            logged = True
+        await asyncio.sleep(0.5)
+
+
+async def _acquire_slot() -> None:
+    """Take a semaphore slot, then wait for the memory-aware limit to admit it."""
+    assert _semaphore is not None
+    await _semaphore.acquire()
+    try:
+        await _wait_for_memory_slot()
+    except BaseException:
+        _semaphore.release()
+        raise
 
 
 async def _wait_for_disconnect(request: Request) -> None:
@@ -27,17 +76,30 @@ async def _wait_for_disconnect(request: Request) -> None:
 
 
 async def run_in_thread_limited(
-    func: Callable[..., T], *args: Any, request: Request | None = None
+    func: Callable[..., T],
+    *args: Any,
+    request: Request | None = None,
+    wait: float | None = None,
 ) -> T:
     """Run a blocking function in a thread, limited by the global semaphore.
 
     If *request* is provided, monitors for client disconnection and releases
-    the semaphore early so other analyses can proceed.
+    the semaphore early so other analyses can proceed. With *wait*, gives up
+    with :class:`ConcurrencyLimitExceeded` when no slot frees up within that
+    many seconds instead of queueing indefinitely.
     """
     if _semaphore is None:
         return await asyncio.to_thread(func, *args)
 
-    async with _semaphore:
+    global _active  # noqa: PLW0603
+    try:
+        await asyncio.wait_for(_acquire_slot(), wait)
+    except asyncio.TimeoutError:
+        raise ConcurrencyLimitExceeded(
+            f"all {effective_concurrency_limit()} analysis slots are busy"
+        ) from None
+    _active += 1
+    try:
         task = asyncio.ensure_future(asyncio.to_thread(func, *args))
         if request is None:
             return await task
@@ -55,3 +117,6 @@ async def run_in_thread_limited(
 
         disconnect.cancel()
         return task.result()
+    finally:
+        _active -= 1
+        _semaphore.release()
@@ -1,13 +1,39 @@
 from __future__ import annotations
 
+import ipaddress
 import json
 import os
 import re
-from dataclasses import dataclass
+import urllib.parse
+from dataclasses import dataclass, field, fields
+from typing import Any
+
+from app.core.pipeline import parse_pipeline_profiles
+from app.core.secret_sources import get_secret_env
 
 DEFAULT_GEMINI_MODEL_ID = "gemini-3-flash-preview"
 DEFAULT_ANTHROPIC_MAX_TOKENS = 4096
 DEFAULT_AI_PROVIDER = "gemini"
+DEFAULT_MAINTENANCE_DISRUPTION_ALERTS = (
+    "KubePodNotReady",
+    "KubeDeploymentReplicasMismatch",
+    "KubeStatefulSetReplicasMismatch",
+    "KubeDaemonSetRolloutStuck",
+    "KubeDaemonSetMisScheduled",
+    "KubeDaemonSetNotScheduled",
+    "KubeNodeNotReady",
+    "KubeNodeUnreachable",
+    "KubeletDown",
+    "KubePdbNotEnoughHealthyPods",
+)
+
+# Field metadata naming the environment variable a secret setting is read from.
+_SECRET_ENV = "secret_env"
+
+
+def _secret(env_name: str, **kwargs: Any) -> Any:
+    """Field read with ``get_secret_env(env_name)``: redacted and watched for rotation."""
+    return field(metadata={_SECRET_ENV: env_name}, **kwargs)
 
 
 def _get_int_env(name: str, default: int) -> int:
@@ -47,6 +73,18 @@ def _get_float_env(name: str, default: float) -> float:
         return default
 
 
+def _get_choice_env(name: str, default: str, choices: tuple[str, ...]) -> str:
+    value = os.getenv(name, default).strip().lower() or default
+    if value not in choices:
+        raise ValueError(f"{name} must be one of {', '.join(choices)} (got {value!r})")
+    return value
+
+
+def _url_hosts(*urls: str) -> tuple[str, ...]:
+    hosts = (urllib.parse.urlsplit(url.strip()).hostname for url in urls if url.strip())
+    return tuple(dict.fromkeys(host for host in hosts if host))
+
+
 def _get_string_list_json_env(name: str) -> list[str]:
     value = os.getenv(name, "").strip()
     if not value:
@@ -70,6 +108,133 @@ def _get_string_list_json_env(name: str) -> list[str]:
     return patterns
 
 
+def _get_network_list_json_env(name: str) -> tuple[str, ...]:
+    networks: list[str] = []
+    for idx, item in enumerate(_get_string_list_json_env(name)):
+        try:
+            networks.append(str(ipaddress.ip_network(item, strict=False)))
+        except ValueError as exc:
+            raise ValueError(f"{name}[{idx}] must be an IP address or CIDR") from exc
+    return tuple(networks)
+
+
+def _get_seconds_map_json_env(name: str) -> tuple[tuple[str, float], ...]:
+    value = os.getenv(name, "").strip()
+    if not value:
+        return ()
+
+    try:
+        parsed = json.loads(value)
+    except json.JSONDecodeError as exc:
+        raise ValueError(f"{name} must be a valid JSON object of seconds") from exc
+
+    if not isinstance(parsed, dict):
+        raise ValueError(f"{name} must be a valid JSON object of seconds")
+
+    targets: list[tuple[str, float]] = []
+    for key, seconds in parsed.items():
+        if isinstance(seconds, bool) or not isinstance(seconds, int | float) or seconds <= 0:
+            raise ValueError(f"{name}.{key} must be a positive number of seconds")
+        if key.strip():
+            targets.append((key.strip(), float(seconds)))
+    return tuple(targets)
+
+
+def _parse_registry_credentials(value: str) -> tuple[tuple[str, str], ...]:
+    value = value.strip()
+    if not value:
+        return ()
+
+    try:
+        parsed = json.loads(value)
+    except json.JSONDecodeError as exc:
+        raise ValueError("REGISTRY_CREDENTIALS_JSON must be a valid JSON object") from exc
+
+    if not isinstance(parsed, dict):
+        raise ValueError("REGISTRY_CREDENTIALS_JSON must be a valid JSON object")
+
+    credentials: list[tuple[str, str]] = []
+    for host, credential in parsed.items():
+        if not isinstance(credential, str) or ":" not in credential:
+            raise ValueError(f"REGISTRY_CREDENTIALS_JSON.{host} must be 'username:password'")
+        if host.strip():
+            credentials.append((host.strip(), credential))
+    return tuple(credentials)
+
+
+def _parse_k8s_clusters(value: str) -> tuple[tuple[str, str, str], ...]:
+    value = value.strip()
+    if not value:
+        return ()
+
+    try:
+        parsed = json.loads(value)
+    except json.JSONDecodeError as exc:
+        raise ValueError("K8S_CLUSTERS_JSON must be a valid JSON object") from exc
+
+    if not isinstance(parsed, dict):
+        raise ValueError("K8S_CLUSTERS_JSON must be a valid JSON object")
+
+    clusters: list[tuple[str, str, str]] = []
+    for name, cluster in parsed.items():
+        kubeconfig = cluster.get("kubeconfig") if isinstance(cluster, dict) else None
+        if not isinstance(kubeconfig, str) or not kubeconfig.strip():
+            raise ValueError(f"K8S_CLUSTERS_JSON.{name}.kubeconfig must be a file path")
+        context = cluster.get("context") or ""
+        if not isinstance(context, str):
+            raise ValueError(f"K8S_CLUSTERS_JSON.{name}.context must be a string")
+        if name.strip():
+            clusters.append((name.strip(), kubeconfig.strip(), context.strip()))
+    return tuple(clusters)
+
+
+def _parse_service_catalog_fields(value: str) -> tuple[tuple[str, str], ...]:
+    value = value.strip()
+    if not value:
+        return ()
+
+    try:
+        parsed = json.loads(value)
+    except json.JSONDecodeError as exc:
+        raise ValueError("SERVICE_CATALOG_FIELDS_JSON must be a valid JSON object") from exc
+
+    if not isinstance(parsed, dict):
+        raise ValueError("SERVICE_CATALOG_FIELDS_JSON must be a valid JSON object")
+
+    fields: list[tuple[str, str]] = []
+    for name, path in parsed.items():
+        if not isinstance(path, str) or not path.strip():
+            raise ValueError(f"SERVICE_CATALOG_FIELDS_JSON.{name} must be a dotted field path")
+        if name.strip():
+            fields.append((name.strip(), path.strip()))
+    return tuple(fields)
+
+
+def _parse_datastore_targets(value: str) -> tuple[tuple[str, tuple[str, ...]], ...]:
+    value = value.strip()
+    if not value:
+        return ()
+
+    try:
+        parsed = json.loads(value)
+    except json.JSONDecodeError as exc:
+        raise ValueError("DATASTORE_TARGETS_JSON must be a valid JSON object") from exc
+
+    if not isinstance(parsed, dict):
+        raise ValueError("DATASTORE_TARGETS_JSON must be a valid JSON object")
+
+    targets: list[tuple[str, tuple[str, ...]]] = []
+    for service, urls in parsed.items():
+        if isinstance(urls, str):
+            urls = [urls]
+        if not isinstance(urls, list) or not all(isinstance(url, str) for url in urls):
+            raise ValueError(f"DATASTORE_TARGETS_JSON.{service} must be a list of URLs")
+        cleaned = tuple(url.strip() for url in urls if url.strip())
+        if service.strip() and cleaned:
+            targets.append((service.strip(), cleaned))
+    return tuple(targets)
+
+
 def _validate_regex_list(patterns: list[str], name: str) -> None:
     for idx, pattern in enumerate(patterns):
         try:
@@ -84,19 +249,19 @@ class Settings:
     log_level: str
     # AI Provider settings
     ai_provider: str  # gemini, openai, anthropic
-    gemini_api_key: str
+    gemini_api_key: str = _secret("GEMINI_API_KEY")
     gemini_model_id: str
     openai_model_id: str
     anthropic_model_id: str
     anthropic_max_tokens: int
-    openai_api_key: str
-    anthropic_api_key: str
+    openai_api_key: str = _secret("OPENAI_API_KEY")
+    anthropic_api_key: str = _secret("ANTHROPIC_API_KEY")
     # Session DB settings
     session_db_host: str
     session_db_port: int
     session_db_name: str
     session_db_user: str
-    session_db_password: str
+    session_db_password: str = _secret("SESSION_DB_PASSWORD")
     agent_cache_size: int
     agent_cache_ttl_seconds: int
     k8s_api_timeout_seconds: int
@@ -128,6 +293,261 @@ class Settings:
     llm_retry_total_timeout: float = 180.0
     # Concurrency
     max_concurrent_analyses: int = 5
+    # Cold-start prewarming before /readyz reports ready
+    prewarm_enabled: bool = True
+    prewarm_timeout_seconds: int = 120
+    # Memory-aware throttling
+    memory_throttling_enabled: bool = True
+    memory_limit_bytes: int = 0
+    memory_soft_limit_ratio: float = 0.75
+    memory_hard_limit_ratio: float = 0.9
+    # HTTP compression
+    gzip_minimum_size: int = 1024
+    gzip_max_request_bytes: int = 32 * 1024 * 1024
+    # Sampled payload logging (debug sink)
+    payload_log_sample_rate: float = 0.0
+    payload_log_file: str = ""
+    payload_log_max_bytes: int = 65536
+    # Continuous profiling (Pyroscope)
+    pyroscope_server_address: str = ""
+    pyroscope_application_name: str = "kube-rca-agent"
+    pyroscope_sample_rate: int = 100
+    pyroscope_tags: str = ""
+    pyroscope_auth_token: str = _secret("PYROSCOPE_AUTH_TOKEN", default="")
+    pyroscope_tenant_id: str = ""
+    # Fault injection (chaos mode) — never enable in production
+    chaos_enabled: bool = False
+    chaos_targets: tuple[str, ...] = ()
+    chaos_error_rate: float = 0.0
+    chaos_latency_ms: int = 0
+    chaos_latency_jitter_ms: int = 0
+    # Secret rotation (0 = disabled)
+    secrets_refresh_interval_seconds: int = 0
+    # Encryption at rest for stored sessions/summaries (first key encrypts)
+    encryption_keys: tuple[str, ...] = _secret("ENCRYPTION_KEYS", default=())
+    # KMS wrapping the data keys of envelope encryption ("aws" or "vault"; "" = local keys)
+    encryption_kms_provider: str = ""
+    encryption_kms_key_id: str = ""
+    encryption_kms_endpoint_url: str = ""
+    encryption_kms_vault_mount: str = "transit"
+    # HMAC keys for signing analysis records (first key signs)
+    analysis_signing_keys: tuple[str, ...] = _secret("ANALYSIS_SIGNING_KEYS", default=())
+    # Data retention (0 = keep forever)
+    session_retention_days: int = 0
+    summary_retention_days: int = 0
+    retention_janitor_interval_seconds: int = 3600
+    # Outbound host allowlist (empty = unrestricted)
+    egress_allowed_hosts: tuple[str, ...] = ()
+    # Writable runtime directory (emptyDir-friendly for read-only root filesystems)
+    data_dir: str = "/tmp/kube-rca-agent"
+    # OIDC authentication for admin endpoints (empty issuer = disabled)
+    oidc_issuer_url: str = ""
+    oidc_audience: str = ""
+    oidc_jwks_url: str = ""
+    oidc_groups_claim: str = "groups"
+    oidc_admin_groups: tuple[str, ...] = ()
+    # Slack app signing secret; /slack/interactions falls back to OIDC without it
+    slack_signing_secret: str = _secret("SLACK_SIGNING_SECRET", default="")
+    # Client mTLS for data sources (e.g. SPIFFE SVID files from spiffe-helper)
+    client_tls_cert_file: str = ""
+    client_tls_key_file: str = ""
+    client_tls_ca_file: str = ""
+    # Hosts the client certificate is presented to (".suffix" matches subdomains)
+    client_tls_hosts: tuple[str, ...] = ()
+    # Require a FIPS-enabled OpenSSL at startup
+    fips_mode: bool = False
+    # Terraform Cloud / Enterprise run history (empty workspaces = disabled)
+    terraform_cloud_url: str = "https://app.terraform.io"
+    terraform_cloud_token: str = _secret("TERRAFORM_CLOUD_TOKEN", default="")
+    terraform_workspace_ids: tuple[str, ...] = ()
+    terraform_http_timeout_seconds: int = 10
+    terraform_lookback_minutes: int = 120
+    # Proactive namespace health scans (empty namespaces = disabled)
+    health_scan_namespaces: tuple[str, ...] = ()
+    health_scan_interval_seconds: int = 3600
+    health_scan_pending_minutes: int = 10
+    health_scan_cert_expiry_days: int = 14
+    health_scan_quota_threshold: float = 0.9
+    # Proactive OOM forecasts from Prometheus memory trends (empty namespaces = all)
+    oom_forecast_enabled: bool = False
+    oom_forecast_namespaces: tuple[str, ...] = ()
+    oom_forecast_interval_seconds: int = 3600
+    oom_forecast_lookback_hours: int = 6
+    oom_forecast_horizon_hours: int = 24
+    oom_forecast_min_usage_ratio: float = 0.5
+    oom_forecast_notify_cooldown_hours: int = 12
+    # Webhook that receives scheduled reports (empty = keep in memory only)
+    report_webhook_url: str = ""
+    report_webhook_timeout_seconds: int = 10
+    # Periodic analysis digest (0 hours = disabled)
+    digest_period_hours: int = 0
+    digest_max_records: int = 5000
+    # JSON file with prompt instructions and rule overrides (empty = disabled)
+    analysis_overrides_file: str = ""
+    analysis_overrides_reload_seconds: int = 30
+    # Review sink for analyses below a quality threshold (empty URL = disabled)
+    review_webhook_url: str = ""
+    review_min_analysis_quality: str = "medium"
+    # Target analysis duration in seconds by alertname, "severity:<sev>" or "default"
+    analysis_slo_targets: tuple[tuple[str, float], ...] = ()
+    # Shadow analysis with a second provider/model (empty provider = disabled)
+    shadow_ai_provider: str = ""
+    shadow_model_id: str = ""
+    shadow_max_concurrency: int = 1
+    # Canary rollout of a model and/or prompt (0 percent = disabled)
+    canary_percent: int = 0
+    canary_ai_provider: str = ""
+    canary_model_id: str = ""
+    canary_prompt_instructions: str | None = None
+    canary_min_samples: int = 20
+    canary_max_error_rate_delta: float = 0.1
+    canary_min_feedback: int = 10
+    canary_max_score_drop: float = 0.1
+    # Stored analysis history for backfills and version comparison
+    analysis_history_enabled: bool = False
+    analysis_pipeline_version: str = ""
+    # Read-only web UI over the stored analysis history
+    web_ui_enabled: bool = False
+    # Archive of watched cluster events in the session store (disabled by default)
+    event_archive_enabled: bool = False
+    event_archive_retention_days: int = 7
+    event_archive_watch_seconds: int = 300
+    event_archive_lookback_minutes: int = 60
+    # Analysis archive export to object storage (empty backend = disabled)
+    archive_backend: str = ""
+    archive_bucket: str = ""
+    archive_prefix: str = "kube-rca/analyses"
+    archive_endpoint_url: str = ""
+    archive_storage_class: str = ""
+    archive_retention_days: int = 0
+    # Large artifacts uploaded to object storage and referenced by signed URL (empty = inline)
+    artifact_store_backend: str = ""
+    artifact_store_bucket: str = ""
+    artifact_store_prefix: str = "kube-rca/artifacts"
+    artifact_store_endpoint_url: str = ""
+    artifact_inline_max_bytes: int = 65536
+    artifact_url_ttl_seconds: int = 86400
+    # Closure of open analyses when their alert resolves
+    incident_closure_enabled: bool = False
+    incident_closure_validation: bool = False
+    # Alerts of one webhook group analyzed individually before the group summary
+    group_analysis_max_alerts: int = 10
+    # Disruption alerts on nodes under planned maintenance labeled as expected
+    maintenance_awareness_enabled: bool = False
+    maintenance_disruption_alerts: tuple[str, ...] = DEFAULT_MAINTENANCE_DISRUPTION_ALERTS
+    # Image build metadata (OCI labels, SBOM attestations) from container registries
+    image_metadata_enabled: bool = False
+    image_metadata_sbom_enabled: bool = False
+    registry_http_timeout_seconds: int = 10
+    registry_credentials: tuple[tuple[str, str], ...] = _secret(
+        "REGISTRY_CREDENTIALS_JSON", default=()
+    )
+    # Window of current container logs collected for the analysis (0 = tail only)
+    k8s_log_since_seconds: int = 0
+    # Read Helm release Secrets for find_removed_api_usage (needs list on secrets)
+    helm_release_scan_enabled: bool = False
+    # ServiceAccount bound by the generated RBAC manifest (GET /diagnostics/rbac)
+    k8s_service_account: str = "kube-rca-agent"
+    k8s_service_account_namespace: str = "kube-rca"
+    # Kubernetes access scope of the collectors (empty = unrestricted)
+    k8s_allowed_namespaces: tuple[str, ...] = ()
+    k8s_allowed_api_groups: tuple[str, ...] = ()
+    k8s_allowed_verbs: tuple[str, ...] = ()
+    k8s_cluster_scoped_access: bool = True
+    # Skip calls the startup RBAC self-check found denied
+    k8s_least_privilege: bool = False
+    # Caller bearer tokens on analysis requests instead of the agent's ServiceAccount
+    k8s_credential_passthrough: bool = False
+    k8s_credential_vault_prefix: str = ""
+    # Remote clusters (name, kubeconfig, context) selected by the request or `cluster` label
+    k8s_cluster_name: str = ""
+    k8s_clusters: tuple[tuple[str, str, str], ...] = ()
+    # Commits between deployed revisions from GitHub/GitLab
+    git_correlation_enabled: bool = False
+    github_api_url: str = "https://api.github.com"
+    github_token: str = _secret("GITHUB_TOKEN", default="")
+    gitlab_api_url: str = "https://gitlab.com/api/v4"
+    gitlab_token: str = _secret("GITLAB_TOKEN", default="")
+    git_http_timeout_seconds: int = 10
+    git_compare_max_commits: int = 50
+    # CI runs of the deployed commit (GitHub Actions / GitLab CI)
+    ci_pipeline_checks_enabled: bool = False
+    # Postgres/MySQL/Redis health checks per service
+    datastore_health_enabled: bool = False
+    datastore_targets: tuple[tuple[str, tuple[str, ...]], ...] = _secret(
+        "DATASTORE_TARGETS_JSON", default=()
+    )
+    datastore_timeout_seconds: int = 5
+    datastore_saturation_threshold_percent: int = 80
+    datastore_replication_lag_threshold_seconds: int = 30
+    # Kafka consumer lag from kafka-exporter metrics in Prometheus
+    kafka_lag_analysis_enabled: bool = False
+    kafka_lag_threshold: int = 1000
+    kafka_lag_window_minutes: int = 10
+    # Deployment rollouts within this window before the alert are flagged (0 = disabled)
+    rollout_correlation_window_minutes: int = 30
+    # Cluster DNS Service checked for DNS-failure alerts
+    cluster_dns_namespace: str = "kube-system"
+    cluster_dns_service: str = "kube-dns"
+    cluster_dns_log_lookback_minutes: int = 15
+    # Synthetic HTTP(S) checks of external service URLs
+    endpoint_probe_enabled: bool = False
+    endpoint_probe_timeout_seconds: int = 10
+    endpoint_probe_slow_threshold_ms: int = 2000
+    endpoint_probe_tls_expiry_warning_days: int = 14
+    # Private, loopback, link-local and reserved networks that may still be probed
+    endpoint_probe_allowed_networks: tuple[str, ...] = ()
+    # Ongoing incidents on the cloud provider's status page (aws, gcp or azure)
+    cloud_status_enabled: bool = False
+    cloud_status_provider: str = ""
+    cloud_status_region: str = ""
+    cloud_status_services: tuple[str, ...] = ()
+    cloud_status_url: str = ""
+    cloud_status_timeout_seconds: int = 10
+    cloud_status_cache_seconds: int = 300
+    # Service metadata from a service catalog (backstage) or CMDB lookup (http)
+    service_catalog_provider: str = ""
+    service_catalog_url: str = ""
+    service_catalog_token: str = _secret("SERVICE_CATALOG_TOKEN", default="")
+    service_catalog_entity_namespace: str = "default"
+    service_catalog_fields: tuple[tuple[str, str], ...] = ()
+    service_catalog_service_labels: tuple[str, ...] = ()
+    service_catalog_timeout_seconds: int = 5
+    service_catalog_cache_seconds: int = 300
+    # Team settings from RCAConfig custom resources in the alert's namespace
+    namespace_rca_config_enabled: bool = False
+    # Namespace-wide snapshot (workloads, failing pods, events) for alerts without a pod
+    namespace_snapshot_enabled: bool = False
+    namespace_snapshot_max_workloads: int = 20
+    namespace_snapshot_max_pods: int = 20
+    namespace_snapshot_max_events: int = 20
+    # Object-state snapshot of the alerted pod/workload from kube-state-metrics
+    # (through Prometheus, or scraped from kube_state_metrics_url)
+    kube_state_metrics_enabled: bool = False
+    kube_state_metrics_url: str = ""
+    kube_state_metrics_timeout_seconds: int = 10
+    # High-cardinality event/span queries (honeycomb, or http for an OTLP backend adapter)
+    observability_provider: str = ""
+    observability_url: str = ""
+    observability_api_key: str = _secret("OBSERVABILITY_API_KEY", default="")
+    observability_dataset: str = "__all__"
+    observability_service_field: str = "service.name"
+    observability_timeout_seconds: int = 30
+    observability_max_results: int = 100
+    # Alert storm detection (0 alerts/minute = disabled)
+    alert_storm_alerts_per_minute: int = 0
+    alert_storm_quiet_seconds: int = 120
+    alert_storm_summary_interval_seconds: int = 60
+    alert_storm_max_deferred: int = 500
+    # Suppression windows answered with "suppressed" instead of analyzed (needs the session store)
+    suppressions_enabled: bool = False
+    suppression_max_duration_minutes: int = 4320
+    # Parallel investigation of config change, capacity and dependency hypotheses
+    hypothesis_investigation_enabled: bool = False
+    hypothesis_max_parallel: int = 3
+    hypothesis_timeout_seconds: int = 120
+    # Ordered analysis stages per routing profile (alertname, severity:<severity>, default)
+    analysis_pipelines: tuple[tuple[str, tuple[str, ...]], ...] = ()
 
     @property
     def session_store_dsn(self) -> str:
@@ -139,6 +559,15 @@ class Settings:
         )
 
 
+# Environment variables of the secret settings and the fields they fill.
+SECRET_SETTING_NAMES = tuple(
+    item.metadata[_SECRET_ENV] for item in fields(Settings) if _SECRET_ENV in item.metadata
+)
+SECRET_SETTING_FIELDS = frozenset(
+    item.name for item in fields(Settings) if _SECRET_ENV in item.metadata
+)
+
+
 def load_settings() -> Settings:
     # Resolve AI provider and model
     ai_provider = os.getenv("AI_PROVIDER", DEFAULT_AI_PROVIDER).lower()
@@ -150,21 +579,21 @@ def load_settings() -> Settings:
         log_level=os.getenv("LOG_LEVEL", "info"),
         # AI Provider settings
         ai_provider=ai_provider,
-        gemini_api_key=os.getenv("GEMINI_API_KEY", ""),
+        gemini_api_key=get_secret_env("GEMINI_API_KEY"),
         gemini_model_id=os.getenv("GEMINI_MODEL_ID", DEFAULT_GEMINI_MODEL_ID),
         openai_model_id=os.getenv("OPENAI_MODEL_ID", "").strip(),
         anthropic_model_id=os.getenv("ANTHROPIC_MODEL_ID", "").strip(),
         anthropic_max_tokens=_get_positive_int_env(
             "ANTHROPIC_MAX_TOKENS", DEFAULT_ANTHROPIC_MAX_TOKENS
         ),
-        openai_api_key=os.getenv("OPENAI_API_KEY", ""),
-        anthropic_api_key=os.getenv("ANTHROPIC_API_KEY", ""),
+        openai_api_key=get_secret_env("OPENAI_API_KEY"),
+        anthropic_api_key=get_secret_env("ANTHROPIC_API_KEY"),
         # Session DB settings
         session_db_host=os.getenv("SESSION_DB_HOST", ""),
         session_db_port=_get_int_env("SESSION_DB_PORT", 5432),
         session_db_name=os.getenv("SESSION_DB_NAME", ""),
         session_db_user=os.getenv("SESSION_DB_USER", ""),
-        session_db_password=os.getenv("SESSION_DB_PASSWORD", ""),
+        session_db_password=get_secret_env("SESSION_DB_PASSWORD"),
         agent_cache_size=_get_non_negative_int_env("AGENT_CACHE_SIZE", 128),
         agent_cache_ttl_seconds=_get_non_negative_int_env("AGENT_CACHE_TTL_SECONDS", 0),
         k8s_api_timeout_seconds=_get_int_env("K8S_API_TIMEOUT_SECONDS", 5),
@@ -200,4 +629,354 @@ def load_settings() -> Settings:
         llm_retry_total_timeout=_get_float_env("LLM_RETRY_TOTAL_TIMEOUT", 180.0),
         # Concurrency
         max_concurrent_analyses=_get_int_env("MAX_CONCURRENT_ANALYSES", 5),
+        # Cold-start prewarming
+        prewarm_enabled=os.getenv("PREWARM_ENABLED", "true").lower() != "false",
+        prewarm_timeout_seconds=_get_positive_int_env("PREWARM_TIMEOUT_SECONDS", 120),
+        # Memory-aware throttling
+        memory_throttling_enabled=(
+            os.getenv("MEMORY_THROTTLING_ENABLED", "true").lower() != "false"
+        ),
+        memory_limit_bytes=_get_non_negative_int_env("MEMORY_LIMIT_BYTES", 0),
+        memory_soft_limit_ratio=_get_float_env("MEMORY_SOFT_LIMIT_RATIO", 0.75),
+        memory_hard_limit_ratio=_get_float_env("MEMORY_HARD_LIMIT_RATIO", 0.9),
+        # HTTP compression
+        gzip_minimum_size=_get_non_negative_int_env("GZIP_MINIMUM_SIZE", 1024),
+        gzip_max_request_bytes=_get_positive_int_env(
+            "GZIP_MAX_REQUEST_BYTES", 32 * 1024 * 1024
+        ),
+        # Sampled payload logging
+        payload_log_sample_rate=_get_float_env("PAYLOAD_LOG_SAMPLE_RATE", 0.0),
+        payload_log_file=os.getenv("PAYLOAD_LOG_FILE", "").strip(),
+        payload_log_max_bytes=_get_positive_int_env("PAYLOAD_LOG_MAX_BYTES", 65536),
+        # Continuous profiling (Pyroscope)
+        pyroscope_server_address=os.getenv("PYROSCOPE_SERVER_ADDRESS", "").strip(),
+        pyroscope_application_name=(
+            os.getenv("PYROSCOPE_APPLICATION_NAME", "").strip() or "kube-rca-agent"
+        ),
+        pyroscope_sample_rate=_get_positive_int_env("PYROSCOPE_SAMPLE_RATE", 100),
+        pyroscope_tags=os.getenv("PYROSCOPE_TAGS", "").strip(),
+        pyroscope_auth_token=get_secret_env("PYROSCOPE_AUTH_TOKEN").strip(),
+        pyroscope_tenant_id=os.getenv("PYROSCOPE_TENANT_ID", "").strip(),
+        # Fault injection (chaos mode)
+        chaos_enabled=os.getenv("CHAOS_ENABLED", "false").lower() == "true",
+        chaos_targets=tuple(_get_string_list_json_env("CHAOS_TARGETS_JSON")),
+        chaos_error_rate=_get_float_env("CHAOS_ERROR_RATE", 0.0),
+        chaos_latency_ms=_get_non_negative_int_env("CHAOS_LATENCY_MS", 0),
+        chaos_latency_jitter_ms=_get_non_negative_int_env("CHAOS_LATENCY_JITTER_MS", 0),
+        # Secret rotation
+        secrets_refresh_interval_seconds=_get_non_negative_int_env(
+            "SECRETS_REFRESH_INTERVAL_SECONDS", 0
+        ),
+        # Encryption at rest
+        encryption_keys=tuple(
+            key.strip() for key in get_secret_env("ENCRYPTION_KEYS").split(",") if key.strip()
+        ),
+        encryption_kms_provider=os.getenv("ENCRYPTION_KMS_PROVIDER", "").strip().lower(),
+        encryption_kms_key_id=os.getenv("ENCRYPTION_KMS_KEY_ID", "").strip(),
+        encryption_kms_endpoint_url=os.getenv("ENCRYPTION_KMS_ENDPOINT_URL", "").strip(),
+        encryption_kms_vault_mount=os.getenv("ENCRYPTION_KMS_VAULT_MOUNT", "transit").strip(),
+        # Tamper-evident analysis records
+        analysis_signing_keys=tuple(
+            key.strip() for key in get_secret_env("ANALYSIS_SIGNING_KEYS").split(",") if key.strip()
+        ),
+        # Data retention
+        session_retention_days=_get_non_negative_int_env("SESSION_RETENTION_DAYS", 0),
+        summary_retention_days=_get_non_negative_int_env("SUMMARY_RETENTION_DAYS", 0),
+        retention_janitor_interval_seconds=_get_positive_int_env(
+            "RETENTION_JANITOR_INTERVAL_SECONDS", 3600
+        ),
+        # Egress allowlist
+        egress_allowed_hosts=tuple(_get_string_list_json_env("EGRESS_ALLOWED_HOSTS_JSON")),
+        # Writable paths
+        data_dir=os.getenv("DATA_DIR", "/tmp/kube-rca-agent").strip(),
+        # OIDC authentication
+        oidc_issuer_url=os.getenv("OIDC_ISSUER_URL", "").strip(),
+        oidc_audience=os.getenv("OIDC_AUDIENCE", "").strip(),
+        oidc_jwks_url=os.getenv("OIDC_JWKS_URL", "").strip(),
+        oidc_groups_claim=os.getenv("OIDC_GROUPS_CLAIM", "groups").strip() or "groups",
+        oidc_admin_groups=tuple(_get_string_list_json_env("OIDC_ADMIN_GROUPS_JSON")),
+        slack_signing_secret=get_secret_env("SLACK_SIGNING_SECRET").strip(),
+        # Client mTLS
+        client_tls_cert_file=os.getenv("CLIENT_TLS_CERT_FILE", "").strip(),
+        client_tls_key_file=os.getenv("CLIENT_TLS_KEY_FILE", "").strip(),
+        client_tls_ca_file=os.getenv("CLIENT_TLS_CA_FILE", "").strip(),
+        client_tls_hosts=tuple(_get_string_list_json_env("CLIENT_TLS_HOSTS_JSON"))
+        or _url_hosts(
+            os.getenv("PROMETHEUS_URL", ""), os.getenv("LOKI_URL", ""), os.getenv("TEMPO_URL", "")
+        ),
+        # FIPS mode
+        fips_mode=os.getenv("FIPS_MODE", "false").lower() == "true",
+        # Terraform Cloud
+        terraform_cloud_url=os.getenv("TERRAFORM_CLOUD_URL", "https://app.terraform.io").strip(),
+        terraform_cloud_token=get_secret_env("TERRAFORM_CLOUD_TOKEN").strip(),
+        terraform_workspace_ids=tuple(_get_string_list_json_env("TERRAFORM_WORKSPACE_IDS_JSON")),
+        terraform_http_timeout_seconds=_get_positive_int_env("TERRAFORM_HTTP_TIMEOUT_SECONDS", 10),
+        terraform_lookback_minutes=_get_non_negative_int_env("TERRAFORM_LOOKBACK_MINUTES", 120),
+        # Proactive health scans
+        health_scan_namespaces=tuple(_get_string_list_json_env("HEALTH_SCAN_NAMESPACES_JSON")),
+        health_scan_interval_seconds=_get_positive_int_env("HEALTH_SCAN_INTERVAL_SECONDS", 3600),
+        health_scan_pending_minutes=_get_positive_int_env("HEALTH_SCAN_PENDING_MINUTES", 10),
+        health_scan_cert_expiry_days=_get_non_negative_int_env("HEALTH_SCAN_CERT_EXPIRY_DAYS", 14),
+        health_scan_quota_threshold=_get_float_env("HEALTH_SCAN_QUOTA_THRESHOLD", 0.9),
+        # Proactive OOM forecasts
+        oom_forecast_enabled=os.getenv("OOM_FORECAST_ENABLED", "false").lower() == "true",
+        oom_forecast_namespaces=tuple(_get_string_list_json_env("OOM_FORECAST_NAMESPACES_JSON")),
+        oom_forecast_interval_seconds=_get_positive_int_env("OOM_FORECAST_INTERVAL_SECONDS", 3600),
+        oom_forecast_lookback_hours=_get_positive_int_env("OOM_FORECAST_LOOKBACK_HOURS", 6),
+        oom_forecast_horizon_hours=_get_positive_int_env("OOM_FORECAST_HORIZON_HOURS", 24),
+        oom_forecast_min_usage_ratio=_get_float_env("OOM_FORECAST_MIN_USAGE_RATIO", 0.5),
+        oom_forecast_notify_cooldown_hours=_get_non_negative_int_env(
+            "OOM_FORECAST_NOTIFY_COOLDOWN_HOURS", 12
+        ),
+        # Scheduled report delivery
+        report_webhook_url=os.getenv("REPORT_WEBHOOK_URL", "").strip(),
+        report_webhook_timeout_seconds=_get_positive_int_env("REPORT_WEBHOOK_TIMEOUT_SECONDS", 10),
+        # Periodic analysis digest
+        digest_period_hours=_get_non_negative_int_env("DIGEST_PERIOD_HOURS", 0),
+        digest_max_records=_get_positive_int_env("DIGEST_MAX_RECORDS", 5000),
+        # Runtime analysis overrides
+        analysis_overrides_file=os.getenv("ANALYSIS_OVERRIDES_FILE", "").strip(),
+        analysis_overrides_reload_seconds=_get_positive_int_env(
+            "ANALYSIS_OVERRIDES_RELOAD_SECONDS", 30
+        ),
+        # Confidence-based result routing
+        review_webhook_url=os.getenv("REVIEW_WEBHOOK_URL", "").strip(),
+        review_min_analysis_quality=_get_choice_env(
+            "REVIEW_MIN_ANALYSIS_QUALITY", "medium", ("low", "medium", "high")
+        ),
+        # Per-alert-type latency SLOs
+        analysis_slo_targets=_get_seconds_map_json_env("ANALYSIS_SLO_TARGETS_JSON"),
+        # Shadow analysis
+        shadow_ai_provider=os.getenv("SHADOW_AI_PROVIDER", "").strip().lower(),
+        shadow_model_id=os.getenv("SHADOW_MODEL_ID", "").strip(),
+        shadow_max_concurrency=_get_positive_int_env("SHADOW_MAX_CONCURRENCY", 1),
+        # Canary rollout
+        canary_percent=min(_get_non_negative_int_env("CANARY_PERCENT", 0), 100),
+        canary_ai_provider=os.getenv("CANARY_AI_PROVIDER", "").strip().lower(),
+        canary_model_id=os.getenv("CANARY_MODEL_ID", "").strip(),
+        canary_prompt_instructions=os.getenv("CANARY_PROMPT_INSTRUCTIONS"),
+        canary_min_samples=_get_positive_int_env("CANARY_MIN_SAMPLES", 20),
+        canary_max_error_rate_delta=_get_float_env("CANARY_MAX_ERROR_RATE_DELTA", 0.1),
+        canary_min_feedback=_get_positive_int_env("CANARY_MIN_FEEDBACK", 10),
+        canary_max_score_drop=_get_float_env("CANARY_MAX_SCORE_DROP", 0.1),
+        # Analysis history
+        analysis_history_enabled=os.getenv("ANALYSIS_HISTORY_ENABLED", "false").lower() == "true",
+        analysis_pipeline_version=os.getenv("ANALYSIS_PIPELINE_VERSION", "").strip(),
+        web_ui_enabled=os.getenv("WEB_UI_ENABLED", "false").lower() == "true",
+        # Event archive
+        event_archive_enabled=os.getenv("EVENT_ARCHIVE_ENABLED", "false").lower() == "true",
+        event_archive_retention_days=_get_non_negative_int_env("EVENT_ARCHIVE_RETENTION_DAYS", 7),
+        event_archive_watch_seconds=_get_positive_int_env("EVENT_ARCHIVE_WATCH_SECONDS", 300),
+        event_archive_lookback_minutes=_get_non_negative_int_env(
+            "EVENT_ARCHIVE_LOOKBACK_MINUTES", 60
+        ),
+        # Analysis archive export
+        archive_backend=os.getenv("ARCHIVE_BACKEND", "").strip().lower(),
+        archive_bucket=os.getenv("ARCHIVE_BUCKET", "").strip(),
+        archive_prefix=os.getenv("ARCHIVE_PREFIX", "kube-rca/analyses").strip(),
+        archive_endpoint_url=os.getenv("ARCHIVE_ENDPOINT_URL", "").strip(),
+        archive_storage_class=os.getenv("ARCHIVE_STORAGE_CLASS", "").strip(),
+        archive_retention_days=_get_non_negative_int_env("ARCHIVE_RETENTION_DAYS", 0),
+        # Artifact store
+        artifact_store_backend=os.getenv("ARTIFACT_STORE_BACKEND", "").strip().lower(),
+        artifact_store_bucket=os.getenv("ARTIFACT_STORE_BUCKET", "").strip(),
+        artifact_store_prefix=os.getenv("ARTIFACT_STORE_PREFIX", "kube-rca/artifacts").strip(),
+        artifact_store_endpoint_url=os.getenv("ARTIFACT_STORE_ENDPOINT_URL", "").strip(),
+        artifact_inline_max_bytes=_get_non_negative_int_env("ARTIFACT_INLINE_MAX_BYTES", 65536),
+        artifact_url_ttl_seconds=_get_positive_int_env("ARTIFACT_URL_TTL_SECONDS", 86400),
+        # Incident closure
+        incident_closure_enabled=(
+            os.getenv("INCIDENT_CLOSURE_ENABLED", "false").lower() == "true"
+        ),
+        incident_closure_validation=(
+            os.getenv("INCIDENT_CLOSURE_VALIDATION", "false").lower() == "true"
+        ),
+        # Group analysis
+        group_analysis_max_alerts=_get_positive_int_env("GROUP_ANALYSIS_MAX_ALERTS", 10),
+        # Node maintenance awareness
+        maintenance_awareness_enabled=(
+            os.getenv("MAINTENANCE_AWARENESS_ENABLED", "false").lower() == "true"
+        ),
+        maintenance_disruption_alerts=tuple(
+            _get_string_list_json_env("MAINTENANCE_DISRUPTION_ALERTS_JSON")
+            or DEFAULT_MAINTENANCE_DISRUPTION_ALERTS
+        ),
+        # Image build metadata
+        image_metadata_enabled=(
+            os.getenv("IMAGE_METADATA_ENABLED", "false").lower() == "true"
+        ),
+        image_metadata_sbom_enabled=(
+            os.getenv("IMAGE_METADATA_SBOM_ENABLED", "false").lower() == "true"
+        ),
+        registry_http_timeout_seconds=_get_positive_int_env("REGISTRY_HTTP_TIMEOUT_SECONDS", 10),
+        registry_credentials=_parse_registry_credentials(
+            get_secret_env("REGISTRY_CREDENTIALS_JSON")
+        ),
+        # Kubernetes log window
+        k8s_log_since_seconds=_get_non_negative_int_env("K8S_LOG_SINCE_SECONDS", 0),
+        helm_release_scan_enabled=(
+            os.getenv("HELM_RELEASE_SCAN_ENABLED", "false").lower() == "true"
+        ),
+        k8s_service_account=os.getenv("K8S_SERVICE_ACCOUNT", "").strip() or "kube-rca-agent",
+        k8s_service_account_namespace=(
+            os.getenv("K8S_SERVICE_ACCOUNT_NAMESPACE", "").strip() or "kube-rca"
+        ),
+        # Kubernetes access scope
+        k8s_allowed_namespaces=tuple(_get_string_list_json_env("K8S_ALLOWED_NAMESPACES_JSON")),
+        k8s_allowed_api_groups=tuple(
+            item.lower() for item in _get_string_list_json_env("K8S_ALLOWED_API_GROUPS_JSON")
+        ),
+        k8s_allowed_verbs=tuple(
+            item.lower() for item in _get_string_list_json_env("K8S_ALLOWED_VERBS_JSON")
+        ),
+        k8s_cluster_scoped_access=(
+            os.getenv("K8S_CLUSTER_SCOPED_ACCESS", "true").lower() == "true"
+        ),
+        k8s_least_privilege=os.getenv("K8S_LEAST_PRIVILEGE", "false").lower() == "true",
+        k8s_credential_passthrough=(
+            os.getenv("K8S_CREDENTIAL_PASSTHROUGH", "false").lower() == "true"
+        ),
+        k8s_credential_vault_prefix=os.getenv("K8S_CREDENTIAL_VAULT_PREFIX", "").strip(),
+        k8s_cluster_name=os.getenv("K8S_CLUSTER_NAME", "").strip(),
+        k8s_clusters=_parse_k8s_clusters(os.getenv("K8S_CLUSTERS_JSON", "")),
+        # Git commit correlation
+        git_correlation_enabled=(
+            os.getenv("GIT_CORRELATION_ENABLED", "false").lower() == "true"
+        ),
+        github_api_url=os.getenv("GITHUB_API_URL", "https://api.github.com").strip(),
+        github_token=get_secret_env("GITHUB_TOKEN").strip(),
+        gitlab_api_url=os.getenv("GITLAB_API_URL", "https://gitlab.com/api/v4").strip(),
+        gitlab_token=get_secret_env("GITLAB_TOKEN").strip(),
+        git_http_timeout_seconds=_get_positive_int_env("GIT_HTTP_TIMEOUT_SECONDS", 10),
+        git_compare_max_commits=_get_positive_int_env("GIT_COMPARE_MAX_COMMITS", 50),
+        # CI pipeline correlation
+        ci_pipeline_checks_enabled=(
+            os.getenv("CI_PIPELINE_CHECKS_ENABLED", "false").lower() == "true"
+        ),
+        # Datastore health checks
+        datastore_health_enabled=(
+            os.getenv("DATASTORE_HEALTH_ENABLED", "false").lower() == "true"
+        ),
+        datastore_targets=_parse_datastore_targets(get_secret_env("DATASTORE_TARGETS_JSON")),
+        datastore_timeout_seconds=_get_positive_int_env("DATASTORE_TIMEOUT_SECONDS", 5),
+        datastore_saturation_threshold_percent=_get_positive_int_env(
+            "DATASTORE_SATURATION_THRESHOLD_PERCENT", 80
+        ),
+        datastore_replication_lag_threshold_seconds=_get_positive_int_env(
+            "DATASTORE_REPLICATION_LAG_THRESHOLD_SECONDS", 30
+        ),
+        # Kafka consumer lag analysis
+        kafka_lag_analysis_enabled=(
+            os.getenv("KAFKA_LAG_ANALYSIS_ENABLED", "false").lower() == "true"
+        ),
+        kafka_lag_threshold=_get_positive_int_env("KAFKA_LAG_THRESHOLD", 1000),
+        kafka_lag_window_minutes=_get_positive_int_env("KAFKA_LAG_WINDOW_MINUTES", 10),
+        # Deployment rollout correlation
+        rollout_correlation_window_minutes=_get_non_negative_int_env(
+            "ROLLOUT_CORRELATION_WINDOW_MINUTES", 30
+        ),
+        # Cluster DNS diagnostic
+        cluster_dns_namespace=os.getenv("CLUSTER_DNS_NAMESPACE", "").strip() or "kube-system",
+        cluster_dns_service=os.getenv("CLUSTER_DNS_SERVICE", "").strip() or "kube-dns",
+        cluster_dns_log_lookback_minutes=_get_positive_int_env(
+            "CLUSTER_DNS_LOG_LOOKBACK_MINUTES", 15
+        ),
+        # External endpoint probes
+        endpoint_probe_enabled=(
+            os.getenv("ENDPOINT_PROBE_ENABLED", "false").lower() == "true"
+        ),
+        endpoint_probe_timeout_seconds=_get_positive_int_env("ENDPOINT_PROBE_TIMEOUT_SECONDS", 10),
+        endpoint_probe_slow_threshold_ms=_get_positive_int_env(
+            "ENDPOINT_PROBE_SLOW_THRESHOLD_MS", 2000
+        ),
+        endpoint_probe_tls_expiry_warning_days=_get_non_negative_int_env(
+            "ENDPOINT_PROBE_TLS_EXPIRY_WARNING_DAYS", 14
+        ),
+        endpoint_probe_allowed_networks=_get_network_list_json_env(
+            "ENDPOINT_PROBE_ALLOWED_NETWORKS_JSON"
+        ),
+        # Cloud provider status correlation
+        cloud_status_enabled=(
+            os.getenv("CLOUD_STATUS_ENABLED", "false").lower() == "true"
+        ),
+        cloud_status_provider=os.getenv("CLOUD_STATUS_PROVIDER", "").strip().lower(),
+        cloud_status_region=os.getenv("CLOUD_STATUS_REGION", "").strip().lower(),
+        cloud_status_services=tuple(_get_string_list_json_env("CLOUD_STATUS_SERVICES_JSON")),
+        cloud_status_url=os.getenv("CLOUD_STATUS_URL", "").strip(),
+        cloud_status_timeout_seconds=_get_positive_int_env("CLOUD_STATUS_TIMEOUT_SECONDS", 10),
+        cloud_status_cache_seconds=_get_non_negative_int_env("CLOUD_STATUS_CACHE_SECONDS", 300),
+        # Service catalog enrichment
+        service_catalog_provider=os.getenv("SERVICE_CATALOG_PROVIDER", "").strip().lower(),
+        service_catalog_url=os.getenv("SERVICE_CATALOG_URL", "").strip(),
+        service_catalog_token=get_secret_env("SERVICE_CATALOG_TOKEN").strip(),
+        service_catalog_entity_namespace=(
+            os.getenv("SERVICE_CATALOG_ENTITY_NAMESPACE", "").strip() or "default"
+        ),
+        service_catalog_fields=_parse_service_catalog_fields(
+            os.getenv("SERVICE_CATALOG_FIELDS_JSON", "")
+        ),
+        service_catalog_service_labels=tuple(
+            _get_string_list_json_env("SERVICE_CATALOG_SERVICE_LABELS_JSON")
+        ),
+        service_catalog_timeout_seconds=_get_positive_int_env(
+            "SERVICE_CATALOG_TIMEOUT_SECONDS", 5
+        ),
+        service_catalog_cache_seconds=_get_non_negative_int_env(
+            "SERVICE_CATALOG_CACHE_SECONDS", 300
+        ),
+        namespace_rca_config_enabled=(
+            os.getenv("NAMESPACE_RCA_CONFIG_ENABLED", "false").lower() == "true"
+        ),
+        namespace_snapshot_enabled=(
+            os.getenv("NAMESPACE_SNAPSHOT_ENABLED", "false").lower() == "true"
+        ),
+        namespace_snapshot_max_workloads=_get_non_negative_int_env(
+            "NAMESPACE_SNAPSHOT_MAX_WORKLOADS", 20
+        ),
+        namespace_snapshot_max_pods=_get_non_negative_int_env("NAMESPACE_SNAPSHOT_MAX_PODS", 20),
+        namespace_snapshot_max_events=_get_non_negative_int_env(
+            "NAMESPACE_SNAPSHOT_MAX_EVENTS", 20
+        ),
+        # kube-state-metrics enrichment
+        kube_state_metrics_enabled=(
+            os.getenv("KUBE_STATE_METRICS_ENABLED", "false").lower() == "true"
+        ),
+        kube_state_metrics_url=os.getenv("KUBE_STATE_METRICS_URL", "").strip(),
+        kube_state_metrics_timeout_seconds=_get_positive_int_env(
+            "KUBE_STATE_METRICS_TIMEOUT_SECONDS", 10
+        ),
+        # Observability event queries
+        observability_provider=os.getenv("OBSERVABILITY_PROVIDER", "").strip().lower(),
+        observability_url=os.getenv("OBSERVABILITY_URL", "").strip(),
+        observability_api_key=get_secret_env("OBSERVABILITY_API_KEY").strip(),
+        observability_dataset=os.getenv("OBSERVABILITY_DATASET", "").strip() or "__all__",
+        observability_service_field=(
+            os.getenv("OBSERVABILITY_SERVICE_FIELD", "").strip() or "service.name"
+        ),
+        observability_timeout_seconds=_get_positive_int_env("OBSERVABILITY_TIMEOUT_SECONDS", 30),
+        observability_max_results=_get_positive_int_env("OBSERVABILITY_MAX_RESULTS", 100),
+        # Alert storm detection
+        alert_storm_alerts_per_minute=_get_non_negative_int_env(
+            "ALERT_STORM_ALERTS_PER_MINUTE", 0
+        ),
+        alert_storm_quiet_seconds=_get_positive_int_env("ALERT_STORM_QUIET_SECONDS", 120),
+        alert_storm_summary_interval_seconds=_get_positive_int_env(
+            "ALERT_STORM_SUMMARY_INTERVAL_SECONDS", 60
+        ),
+        alert_storm_max_deferred=_get_positive_int_env("ALERT_STORM_MAX_DEFERRED", 500),
+        # Alert suppression windows
+        suppressions_enabled=os.getenv("SUPPRESSIONS_ENABLED", "false").lower() == "true",
+        suppression_max_duration_minutes=_get_positive_int_env(
+            "SUPPRESSION_MAX_DURATION_MINUTES", 4320
+        ),
+        # Multi-hypothesis investigation
+        hypothesis_investigation_enabled=(
+            os.getenv("HYPOTHESIS_INVESTIGATION_ENABLED", "false").lower() == "true"
+        ),
+        hypothesis_max_parallel=_get_positive_int_env("HYPOTHESIS_MAX_PARALLEL", 3),
+        hypothesis_timeout_seconds=_get_positive_int_env("HYPOTHESIS_TIMEOUT_SECONDS", 120),
+        # Analysis pipeline profiles
+        analysis_pipelines=parse_pipeline_profiles(
+            os.getenv("ANALYSIS_PIPELINES_JSON", ""), "ANALYSIS_PIPELINES_JSON"
+        ),
     )
@@ -0,0 +1,53 @@
+"""Incident correlation ID of the analysis being run.
+
+Every analysis gets one ID, taken from the request when the caller already
+has one (the backend's Slack thread) and generated otherwise. It is kept in a
+context variable for the duration of the analysis, so log lines (through
+``CorrelationIdFilter``), engine trace spans, stored records and callbacks
+all carry the same identifier, and it is returned to the caller with the
+result.
+"""
+
+from __future__ import annotations
+
+import logging
+import re
+import uuid
+from collections.abc import Iterator
+from contextlib import contextmanager
+from contextvars import ContextVar
+
+# Trace span attribute of the correlation ID.
+CORRELATION_TRACE_ATTRIBUTE = "kube_rca.correlation_id"
+# Header of outgoing callbacks.
+CORRELATION_HEADER = "X-Correlation-ID"
+
+_VALID_ID = re.compile(r"^[A-Za-z0-9][A-Za-z0-9._:-]{0,127}$")
+_current: ContextVar[str | None] = ContextVar("correlation_id", default=None)
+
+
+def resolve_correlation_id(requested: str | None) -> str:
+    """The caller's correlation ID when it is usable, else a new one."""
+    value = (requested or "").strip()
+    return value if _VALID_ID.match(value) else uuid.uuid4().hex
+
+
+@contextmanager
+def use_correlation_id(correlation_id: str) -> Iterator[None]:
+    reset = _current.set(correlation_id)
+    try:
+        yield
+    finally:
+        _current.reset(reset)
+
+
+def current_correlation_id() -> str | None:
+    return _current.get()
+
+
+class CorrelationIdFilter(logging.Filter):
+    """Add ``correlation_id`` to every record (``-`` outside an analysis)."""
+
+    def filter(self, record: logging.LogRecord) -> bool:
+        record.correlation_id = _current.get() or "-"
+        return True
@@ -0,0 +1,51 @@
+"""Latency SLO deadline of the analysis being run.
+
+An analysis with a latency SLO runs under ``use_deadline``. The deadline is
+kept in a context variable, so it follows the analysis into tool calls and
+hypothesis branches, and the clients bound their own request timeouts by it
+(``bounded_timeout``) instead of the analysis being abandoned on a
+background thread. Once it has passed, new Kubernetes and HTTP requests and
+LLM retries fail fast with ``DeadlineExceeded``.
+"""
+
+from __future__ import annotations
+
+import time
+from collections.abc import Iterator
+from contextlib import contextmanager
+from contextvars import ContextVar
+
+_current: ContextVar[float | None] = ContextVar("analysis_deadline", default=None)
+
+
+class DeadlineExceeded(TimeoutError):
+    """The latency SLO deadline of the current analysis has passed."""
+
+
+@contextmanager
+def use_deadline(deadline: float | None) -> Iterator[None]:
+    """Run the current context under *deadline* (a ``time.perf_counter()`` value)."""
+    reset = _current.set(deadline)
+    try:
+        yield
+    finally:
+        _current.reset(reset)
+
+
+def remaining_seconds() -> float | None:
+    """Seconds left before the deadline, ``None`` without one."""
+    deadline = _current.get()
+    return None if deadline is None else deadline - time.perf_counter()
+
+
+def check_deadline() -> None:
+    remaining = remaining_seconds()
+    if remaining is not None and remaining <= 0:
+        raise DeadlineExceeded("analysis deadline passed")
+
+
+def bounded_timeout(timeout: float) -> float:
+    """*timeout*, shortened to the time left before the deadline."""
+    check_deadline()
+    remaining = remaining_seconds()
+    return timeout if remaining is None else min(timeout, remaining)
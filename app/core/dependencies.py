@@ -1,19 +1,66 @@
 from __future__ import annotations
 
+import dataclasses
 import logging
 from functools import lru_cache
 
+from app.clients.analysis_store import PostgresAnalysisStore
+from app.clients.cloud_status import CloudStatusClient
+from app.clients.datastores import DatastoreHealthClient
+from app.clients.endpoint_probe import EndpointProbeClient
+from app.clients.event_archive import PostgresEventArchive
+from app.clients.git_hosting import GitHostingClient
 from app.clients.k8s import KubernetesClient
+from app.clients.kms import build_key_wrapper
+from app.clients.kube_state_metrics import KubeStateMetricsClient
 from app.clients.llm_providers import get_provider_config
 from app.clients.loki import LokiClient
+from app.clients.object_storage import build_object_store
+from app.clients.observability import ObservabilityClient
 from app.clients.prometheus import PrometheusClient
+from app.clients.registry import RegistryClient
+from app.clients.report_sink import ReportSink, build_report_sink
+from app.clients.service_catalog import ServiceCatalogClient
+from app.clients.session_repository import PostgresSessionRepository
+from app.clients.shadow_store import PostgresShadowStore
 from app.clients.strands_agent import AnalysisEngine, StrandsAnalysisEngine
 from app.clients.summary_store import PostgresSummaryStore, SummaryStore
+from app.clients.suppression_store import PostgresSuppressionStore
 from app.clients.tempo import TempoClient
+from app.clients.terraform import TerraformCloudClient
+from app.core.auth import OIDCVerifier, build_oidc_verifier
 from app.core.config import Settings, load_settings
+from app.core.egress import LLM_PROVIDER_HOSTS, is_host_allowed
+from app.core.encryption import FieldCipher, build_field_cipher
 from app.core.masking import BuiltinRedactor, ChainedMasker, Masker, build_masker
+from app.core.memory import MemoryPressureMonitor
+from app.core.pipeline import PipelineRegistry
+from app.core.signing import RecordSigner, build_record_signer
+from app.core.slo import LatencySloTracker
+from app.core.warmup import WarmupStep
 from app.services.analysis import AnalysisService
+from app.services.archive import AnalysisArchiver
+from app.services.artifact_store import ArtifactOffloader
+from app.services.backfill import BackfillService
+from app.services.canary import CanaryRollout
 from app.services.chat import ChatService
+from app.services.closure import IncidentClosureTracker
+from app.services.code_changes import CodeChangeCorrelator
+from app.services.diagnostics import DiagnosticsService
+from app.services.digest import AnalysisLedger, DigestService
+from app.services.event_archive import EventArchiver
+from app.services.group_analysis import AlertGroupService
+from app.services.health_scan import HealthScanService
+from app.services.hypotheses import HypothesisInvestigator
+from app.services.kafka_lag import KafkaLagAnalyzer
+from app.services.kube_state import KubeStateCollector, PrometheusStateSource
+from app.services.oom_forecast import OomForecastService
+from app.services.result_routing import ResultRouter
+from app.services.retention import RetentionService
+from app.services.shadow import ShadowAnalysisRunner
+from app.services.slack_interactions import SlackInteractionService
+from app.services.storm import AlertStormGuard
+from app.services.suppression import SuppressionService
 
 logger = logging.getLogger(__name__)
 
@@ -23,6 +70,50 @@ def get_settings() -> Settings:
     return load_settings()
 
 
+@lru_cache
+def get_field_cipher() -> FieldCipher | None:
+    settings = get_settings()
+    key_wrapper = build_key_wrapper(
+        settings.encryption_kms_provider,
+        settings.encryption_kms_key_id,
+        endpoint_url=settings.encryption_kms_endpoint_url,
+        vault_mount=settings.encryption_kms_vault_mount,
+    )
+    return build_field_cipher(settings.encryption_keys, key_wrapper=key_wrapper)
+
+
+@lru_cache
+def get_record_signer() -> RecordSigner | None:
+    return build_record_signer(get_settings().analysis_signing_keys)
+
+
+@lru_cache
+def get_oidc_verifier() -> OIDCVerifier | None:
+    settings = get_settings()
+    return build_oidc_verifier(
+        settings.oidc_issuer_url,
+        settings.oidc_audience,
+        jwks_url=settings.oidc_jwks_url,
+        groups_claim=settings.oidc_groups_claim,
+        allowed_groups=settings.oidc_admin_groups,
+    )
+
+
+@lru_cache
+def get_memory_monitor() -> MemoryPressureMonitor | None:
+    settings = get_settings()
+    if not settings.memory_throttling_enabled:
+        return None
+    monitor = MemoryPressureMonitor(
+        limit_bytes=settings.memory_limit_bytes,
+        soft_ratio=settings.memory_soft_limit_ratio,
+        hard_ratio=settings.memory_hard_limit_ratio,
+    )
+    if not monitor.enabled:
+        return None
+    return monitor
+
+
 @lru_cache
 def get_k8s_client() -> KubernetesClient:
     settings = get_settings()
@@ -36,6 +127,8 @@ def get_k8s_client() -> KubernetesClient:
         timeout_seconds=settings.k8s_api_timeout_seconds,
         event_limit=event_limit,
         log_tail_lines=log_tail_lines,
+        log_since_seconds=settings.k8s_log_since_seconds,
+        helm_release_scan=settings.helm_release_scan_enabled,
     )
 
 
@@ -69,13 +162,134 @@ def get_loki_client() -> LokiClient | None:
 
 
 @lru_cache
-def get_analysis_engine() -> AnalysisEngine | None:
+def get_terraform_client() -> TerraformCloudClient | None:
+    settings = get_settings()
+    client = TerraformCloudClient(settings)
+    if not client.enabled:
+        return None
+    return client
+
+
+@lru_cache
+def get_registry_client() -> RegistryClient | None:
+    client = RegistryClient(get_settings())
+    if not client.enabled:
+        return None
+    return client
+
+
+@lru_cache
+def get_datastore_client() -> DatastoreHealthClient | None:
+    client = DatastoreHealthClient(get_settings())
+    if not client.enabled:
+        return None
+    return client
+
+
+@lru_cache
+def get_endpoint_probe_client() -> EndpointProbeClient | None:
+    client = EndpointProbeClient(get_settings())
+    if not client.enabled:
+        return None
+    return client
+
+
+@lru_cache
+def get_cloud_status_client() -> CloudStatusClient | None:
+    client = CloudStatusClient(get_settings())
+    if not client.enabled:
+        return None
+    return client
+
+
+@lru_cache
+def get_observability_client() -> ObservabilityClient | None:
+    client = ObservabilityClient(get_settings())
+    if not client.enabled:
+        return None
+    return client
+
+
+@lru_cache
+def get_service_catalog_client() -> ServiceCatalogClient | None:
+    client = ServiceCatalogClient(get_settings())
+    if not client.enabled:
+        return None
+    return client
+
+
+@lru_cache
+def get_kube_state_collector() -> KubeStateCollector | None:
     settings = get_settings()
+    if not settings.kube_state_metrics_enabled:
+        return None
+    client = KubeStateMetricsClient(settings)
+    if client.enabled:
+        return KubeStateCollector(client)
+    prometheus_client = get_prometheus_client()
+    if prometheus_client is None:
+        return None
+    return KubeStateCollector(PrometheusStateSource(prometheus_client))
+
+
+@lru_cache
+def get_kafka_lag_analyzer() -> KafkaLagAnalyzer | None:
+    settings = get_settings()
+    prometheus_client = get_prometheus_client()
+    if not settings.kafka_lag_analysis_enabled or prometheus_client is None:
+        return None
+    return KafkaLagAnalyzer(
+        prometheus_client,
+        lag_threshold=settings.kafka_lag_threshold,
+        window_minutes=settings.kafka_lag_window_minutes,
+    )
+
 
+@lru_cache
+def get_code_change_correlator() -> CodeChangeCorrelator | None:
+    client = GitHostingClient(get_settings())
+    if not client.enabled:
+        return None
+    return CodeChangeCorrelator(
+        get_k8s_client(),
+        client,
+        get_registry_client(),
+        pipeline_checks=get_settings().ci_pipeline_checks_enabled,
+    )
+
+
+@lru_cache
+def get_analysis_engine() -> AnalysisEngine | None:
+    return _build_analysis_engine(get_settings())
+
+
+def build_warmup_steps() -> list[WarmupStep]:
+    """Cold-start work done before readiness flips true, in dependency order."""
+    steps = [
+        WarmupStep("clients", lambda: (get_analysis_service(), get_chat_service())),
+        WarmupStep("kubernetes", lambda: get_k8s_client().warm_up()),
+    ]
+    engine = get_analysis_engine()
+    if isinstance(engine, StrandsAnalysisEngine):
+        steps.append(WarmupStep("llm_client", engine.warm_up))
+    steps.append(WarmupStep("prompt", lambda: get_analysis_service().warm_up()))
+    return steps
+
+
+def _build_analysis_engine(settings: Settings, label: str = "Analysis") -> AnalysisEngine | None:
     # Use multi-provider factory to get model configuration
     model_config = get_provider_config(settings)
     if model_config is None:
-        logger.warning("No valid AI provider configured. Analysis engine disabled.")
+        logger.warning("No valid AI provider configured. %s engine disabled.", label)
+        return None
+
+    provider_host = LLM_PROVIDER_HOSTS.get(model_config.provider.value)
+    if provider_host and not is_host_allowed(provider_host):
+        logger.error(
+            "LLM host %s is not in EGRESS_ALLOWED_HOSTS_JSON. %s engine disabled.",
+            provider_host,
+            label,
+        )
         return None
 
     return StrandsAnalysisEngine(
@@ -86,15 +300,161 @@ def get_analysis_engine() -> AnalysisEngine | None:
         get_loki_client(),
         masker=get_masker(),
         model_config=model_config,
+        cipher=get_field_cipher(),
+        terraform_client=get_terraform_client(),
+        registry_client=get_registry_client(),
+        code_changes=get_code_change_correlator(),
+        datastore_client=get_datastore_client(),
+        kafka_lag=get_kafka_lag_analyzer(),
+        endpoint_probe=get_endpoint_probe_client(),
+        observability=get_observability_client(),
+    )
+
+
+@lru_cache
+def get_closure_tracker() -> IncidentClosureTracker | None:
+    if not get_settings().incident_closure_enabled:
+        return None
+    return IncidentClosureTracker(sink=get_report_sink())
+
+
+@lru_cache
+def get_analysis_store() -> PostgresAnalysisStore | None:
+    settings = get_settings()
+    if not settings.analysis_history_enabled or not settings.session_store_dsn:
+        return None
+    return PostgresAnalysisStore(
+        settings.session_store_dsn, cipher=get_field_cipher(), signer=get_record_signer()
+    )
+
+
+@lru_cache
+def get_event_archive() -> PostgresEventArchive | None:
+    settings = get_settings()
+    if not settings.event_archive_enabled or not settings.session_store_dsn:
+        return None
+    return PostgresEventArchive(settings.session_store_dsn, cipher=get_field_cipher())
+
+
+@lru_cache
+def get_event_archiver() -> EventArchiver | None:
+    archive = get_event_archive()
+    if archive is None:
+        return None
+    return EventArchiver(
+        get_k8s_client(), archive, watch_seconds=get_settings().event_archive_watch_seconds
+    )
+
+
+@lru_cache
+def get_shadow_store() -> PostgresShadowStore | None:
+    settings = get_settings()
+    if not settings.shadow_ai_provider or not settings.session_store_dsn:
+        return None
+    return PostgresShadowStore(settings.session_store_dsn, cipher=get_field_cipher())
+
+
+@lru_cache
+def get_shadow_runner() -> ShadowAnalysisRunner | None:
+    settings = get_settings()
+    store = get_shadow_store()
+    if store is None:
+        return None
+    engine, model = _build_alternate_engine(
+        settings.shadow_ai_provider, settings.shadow_model_id, label="Shadow analysis"
+    )
+    if engine is None:
+        return None
+    return ShadowAnalysisRunner(
+        engine,
+        store,
+        primary_model=_describe_model(settings) or "none",
+        shadow_model=model or "",
+        masker=get_masker(),
+        max_concurrency=settings.shadow_max_concurrency,
+    )
+
+
+@lru_cache
+def get_canary_rollout() -> CanaryRollout | None:
+    settings = get_settings()
+    if settings.canary_percent <= 0:
+        return None
+    engine: AnalysisEngine | None = None
+    model: str | None = None
+    if settings.canary_ai_provider or settings.canary_model_id:
+        engine, model = _build_alternate_engine(
+            settings.canary_ai_provider or settings.ai_provider,
+            settings.canary_model_id,
+            label="Canary analysis",
+        )
+        if engine is None:
+            return None
+    if engine is None and settings.canary_prompt_instructions is None:
+        logger.warning("CANARY_PERCENT is set without a canary model or prompt; canary disabled.")
+        return None
+    return CanaryRollout(
+        settings.canary_percent,
+        engine=engine,
+        model=model,
+        prompt_instructions=settings.canary_prompt_instructions,
+        min_samples=settings.canary_min_samples,
+        max_error_rate_delta=settings.canary_max_error_rate_delta,
+        min_feedback=settings.canary_min_feedback,
+        max_score_drop=settings.canary_max_score_drop,
+    )
+
+
+@lru_cache
+def get_pipeline_registry() -> PipelineRegistry | None:
+    settings = get_settings()
+    if not settings.analysis_pipelines:
+        return None
+    return PipelineRegistry(settings.analysis_pipelines)
+
+
+@lru_cache
+def get_hypothesis_investigator() -> HypothesisInvestigator | None:
+    settings = get_settings()
+    if not settings.hypothesis_investigation_enabled:
+        return None
+    return HypothesisInvestigator(
+        max_parallel=settings.hypothesis_max_parallel,
+        timeout_seconds=settings.hypothesis_timeout_seconds,
     )
 
 
+def _build_alternate_engine(
+    provider: str, model_id: str, *, label: str
+) -> tuple[AnalysisEngine | None, str | None]:
+    """Engine for a second provider/model, sharing tools and storage with the primary one."""
+    settings = get_settings()
+    overrides: dict[str, str] = {"ai_provider": provider.lower()}
+    if model_id and provider.lower() in {"gemini", "openai", "anthropic"}:
+        overrides[f"{provider.lower()}_model_id"] = model_id
+    alternate = dataclasses.replace(settings, **overrides)
+    return _build_analysis_engine(alternate, label=label), _describe_model(alternate)
+
+
+def _describe_model(settings: Settings) -> str | None:
+    config = get_provider_config(settings)
+    return f"{config.provider.value}/{config.model_id}" if config else None
+
+
 @lru_cache
 def get_summary_store() -> SummaryStore | None:
     settings = get_settings()
     if not settings.session_store_dsn:
         return None
-    return PostgresSummaryStore(settings.session_store_dsn)
+    return PostgresSummaryStore(settings.session_store_dsn, cipher=get_field_cipher())
+
+
+@lru_cache
+def get_session_repository() -> PostgresSessionRepository | None:
+    settings = get_settings()
+    if not settings.session_store_dsn:
+        return None
+    return PostgresSessionRepository(settings.session_store_dsn, cipher=get_field_cipher())
 
 
 @lru_cache
@@ -114,6 +474,11 @@ def get_chat_service() -> ChatService:
     )
 
 
+@lru_cache
+def get_slo_tracker() -> LatencySloTracker:
+    return LatencySloTracker(get_settings().analysis_slo_targets)
+
+
 @lru_cache
 def get_analysis_service() -> AnalysisService:
     prometheus_client = get_prometheus_client()
@@ -136,4 +501,259 @@ def get_analysis_service() -> AnalysisService:
         prompt_token_budget=settings.prompt_token_budget,
         prompt_max_log_lines=settings.prompt_max_log_lines,
         prompt_max_events=settings.prompt_max_events,
+        memory_monitor=get_memory_monitor(),
+        infra_changes_enabled=get_terraform_client() is not None,
+        image_metadata_enabled=get_registry_client() is not None,
+        code_changes_enabled=get_code_change_correlator() is not None,
+        datastore_health_enabled=get_datastore_client() is not None,
+        kafka_lag_enabled=get_kafka_lag_analyzer() is not None,
+        endpoint_probe_enabled=get_endpoint_probe_client() is not None,
+        observability_enabled=get_observability_client() is not None,
+        ledger=get_analysis_ledger(),
+        session_repository=get_session_repository(),
+        slo_tracker=get_slo_tracker(),
+        shadow_runner=get_shadow_runner(),
+        canary=get_canary_rollout(),
+        analysis_store=get_analysis_store(),
+        pipeline_version=(
+            settings.analysis_pipeline_version or _describe_model(settings) or "rules-only"
+        ),
+        closure_tracker=get_closure_tracker(),
+        closure_validation=settings.incident_closure_validation,
+        maintenance_awareness=settings.maintenance_awareness_enabled,
+        maintenance_disruption_alerts=settings.maintenance_disruption_alerts,
+        rollout_correlation_window_minutes=settings.rollout_correlation_window_minutes,
+        cluster_dns_namespace=settings.cluster_dns_namespace,
+        cluster_dns_service=settings.cluster_dns_service,
+        cluster_dns_log_lookback_minutes=settings.cluster_dns_log_lookback_minutes,
+        cloud_status=get_cloud_status_client(),
+        service_catalog=get_service_catalog_client(),
+        kube_state=get_kube_state_collector(),
+        hypothesis_investigator=get_hypothesis_investigator(),
+        pipelines=get_pipeline_registry(),
+        event_archive=get_event_archive(),
+        event_archive_lookback_minutes=settings.event_archive_lookback_minutes,
+        artifact_offloader=get_artifact_offloader(),
+        namespace_config_enabled=settings.namespace_rca_config_enabled,
+        namespace_snapshot_enabled=settings.namespace_snapshot_enabled,
+        namespace_snapshot_max_workloads=settings.namespace_snapshot_max_workloads,
+        namespace_snapshot_max_pods=settings.namespace_snapshot_max_pods,
+        namespace_snapshot_max_events=settings.namespace_snapshot_max_events,
+    )
+
+
+@lru_cache
+def get_retention_service() -> RetentionService:
+    settings = get_settings()
+    summary_store = get_summary_store()
+    return RetentionService(
+        get_session_repository(),
+        summary_store if isinstance(summary_store, PostgresSummaryStore) else None,
+        session_retention_days=settings.session_retention_days,
+        summary_retention_days=settings.summary_retention_days,
+        shadow_store=get_shadow_store(),
+        analysis_store=get_analysis_store(),
+        archive=get_analysis_archiver(),
+        archive_retention_days=settings.archive_retention_days,
+        event_archive=get_event_archive(),
+        event_archive_retention_days=settings.event_archive_retention_days,
+        artifact_store=get_artifact_offloader(),
+    )
+
+
+@lru_cache
+def get_analysis_archiver() -> AnalysisArchiver | None:
+    settings = get_settings()
+    if not settings.archive_backend:
+        return None
+    try:
+        store = build_object_store(
+            settings.archive_backend,
+            settings.archive_bucket,
+            endpoint_url=settings.archive_endpoint_url,
+            storage_class=settings.archive_storage_class,
+        )
+    except ValueError as exc:
+        logger.warning("Analysis archive export disabled: %s", exc)
+        return None
+    return AnalysisArchiver(store, prefix=settings.archive_prefix, masker=get_masker())
+
+
+@lru_cache
+def get_artifact_offloader() -> ArtifactOffloader | None:
+    settings = get_settings()
+    if not settings.artifact_store_backend:
+        return None
+    try:
+        store = build_object_store(
+            settings.artifact_store_backend,
+            settings.artifact_store_bucket,
+            endpoint_url=settings.artifact_store_endpoint_url,
+            setting_prefix="ARTIFACT_STORE",
+        )
+    except ValueError as exc:
+        logger.warning("Artifact store disabled, artifacts stay inline: %s", exc)
+        return None
+    return ArtifactOffloader(
+        store,
+        prefix=settings.artifact_store_prefix,
+        inline_max_bytes=settings.artifact_inline_max_bytes,
+        url_ttl_seconds=settings.artifact_url_ttl_seconds,
+    )
+
+
+@lru_cache
+def get_report_sink() -> ReportSink | None:
+    settings = get_settings()
+    return build_report_sink(
+        settings.report_webhook_url, timeout_seconds=settings.report_webhook_timeout_seconds
+    )
+
+
+@lru_cache
+def get_result_router() -> ResultRouter | None:
+    settings = get_settings()
+    sink = build_report_sink(
+        settings.review_webhook_url, timeout_seconds=settings.report_webhook_timeout_seconds
+    )
+    if sink is None:
+        return None
+    return ResultRouter(sink, min_quality=settings.review_min_analysis_quality)
+
+
+@lru_cache
+def get_health_scan_service() -> HealthScanService:
+    settings = get_settings()
+    return HealthScanService(
+        get_k8s_client(),
+        settings.health_scan_namespaces,
+        sink=get_report_sink(),
+        pending_minutes=settings.health_scan_pending_minutes,
+        cert_expiry_days=settings.health_scan_cert_expiry_days,
+        quota_threshold=settings.health_scan_quota_threshold,
+    )
+
+
+@lru_cache
+def get_oom_forecast_service() -> OomForecastService | None:
+    settings = get_settings()
+    prometheus_client = get_prometheus_client()
+    if not settings.oom_forecast_enabled:
+        return None
+    if prometheus_client is None:
+        logger.warning("OOM_FORECAST_ENABLED is set but Prometheus is not configured")
+        return None
+    return OomForecastService(
+        prometheus_client,
+        settings.oom_forecast_namespaces,
+        sink=get_report_sink(),
+        lookback_hours=settings.oom_forecast_lookback_hours,
+        horizon_hours=settings.oom_forecast_horizon_hours,
+        min_usage_ratio=settings.oom_forecast_min_usage_ratio,
+        notify_cooldown_hours=settings.oom_forecast_notify_cooldown_hours,
+    )
+
+
+@lru_cache
+def get_analysis_ledger() -> AnalysisLedger:
+    # Not cleared on secret rotation so the digest keeps its history.
+    return AnalysisLedger(get_settings().digest_max_records)
+
+
+@lru_cache
+def get_digest_service() -> DigestService:
+    settings = get_settings()
+    return DigestService(
+        get_analysis_ledger(),
+        sink=get_report_sink(),
+        period_hours=settings.digest_period_hours,
+    )
+
+
+def get_diagnostics_service() -> DiagnosticsService:
+    return DiagnosticsService(
+        get_settings(),
+        get_k8s_client(),
+        prometheus_client=get_prometheus_client(),
+        loki_client=get_loki_client(),
+        tempo_client=get_tempo_client(),
+    )
+
+
+@lru_cache
+def get_backfill_service() -> BackfillService | None:
+    store = get_analysis_store()
+    if store is None:
+        return None
+    return BackfillService(store, get_analysis_service())
+
+
+@lru_cache
+def get_suppression_service() -> SuppressionService | None:
+    settings = get_settings()
+    if not settings.suppressions_enabled:
+        return None
+    if not settings.session_store_dsn:
+        logger.warning("Alert suppressions disabled: the session store is not configured")
+        return None
+    return SuppressionService(
+        PostgresSuppressionStore(settings.session_store_dsn),
+        max_duration_minutes=settings.suppression_max_duration_minutes,
+    )
+
+
+@lru_cache
+def get_alert_storm_guard() -> AlertStormGuard | None:
+    settings = get_settings()
+    sink = get_report_sink()
+    if settings.alert_storm_alerts_per_minute <= 0:
+        return None
+    if sink is None:
+        logger.warning("Alert storm detection disabled: REPORT_WEBHOOK_URL is not set")
+        return None
+    return AlertStormGuard(
+        get_analysis_service(),
+        sink,
+        alerts_per_minute=settings.alert_storm_alerts_per_minute,
+        quiet_seconds=settings.alert_storm_quiet_seconds,
+        max_deferred=settings.alert_storm_max_deferred,
+        masker=get_masker(),
     )
+
+
+def get_alert_group_service() -> AlertGroupService:
+    return AlertGroupService(
+        get_analysis_service(),
+        get_analysis_engine(),
+        masker=get_masker(),
+        max_alerts=get_settings().group_analysis_max_alerts,
+    )
+
+
+def get_slack_interaction_service() -> SlackInteractionService:
+    return SlackInteractionService(get_analysis_service())
+
+
+def reset_secret_dependencies() -> None:
+    """Drop cached settings and every client built from secret values."""
+    get_settings.cache_clear()
+    get_field_cipher.cache_clear()
+    get_record_signer.cache_clear()
+    get_terraform_client.cache_clear()
+    get_service_catalog_client.cache_clear()
+    get_observability_client.cache_clear()
+    get_registry_client.cache_clear()
+    get_code_change_correlator.cache_clear()
+    get_datastore_client.cache_clear()
+    get_analysis_engine.cache_clear()
+    get_analysis_store.cache_clear()
+    get_shadow_store.cache_clear()
+    get_event_archive.cache_clear()
+    get_event_archiver.cache_clear()
+    get_shadow_runner.cache_clear()
+    get_canary_rollout.cache_clear()
+    get_summary_store.cache_clear()
+    get_session_repository.cache_clear()
+    get_retention_service.cache_clear()
+    get_analysis_service.cache_clear()
+    get_chat_service.cache_clear()
@@ -0,0 +1,77 @@
+from __future__ import annotations
+
+import logging
+import urllib.parse
+from collections.abc import Iterable
+from dataclasses import dataclass
+
+logger = logging.getLogger(__name__)
+
+# Default API hosts of the LLM provider SDKs (they do not go through urllib).
+LLM_PROVIDER_HOSTS = {
+    "gemini": "generativelanguage.googleapis.com",
+    "openai": "api.openai.com",
+    "anthropic": "api.anthropic.com",
+}
+
+
+class EgressDenied(ValueError):
+    """Raised when an outbound call targets a host outside EGRESS_ALLOWED_HOSTS_JSON."""
+
+
+@dataclass(frozen=True)
+class EgressPolicy:
+    """Host allowlist; entries are ``host``, ``host:port`` or ``*.domain`` patterns."""
+
+    allowed: frozenset[str]
+
+    def allows_host(self, host: str, port: int | None = None) -> bool:
+        host = host.strip().lower().rstrip(".")
+        if not host:
+            return False
+        for pattern in self.allowed:
+            pattern_host, _, pattern_port = pattern.rpartition(":")
+            if not pattern_host or not pattern_port.isdigit():
+                pattern_host, pattern_port = pattern, ""
+            if pattern_port and (port is None or int(pattern_port) != port):
+                continue
+            if pattern_host.startswith("*."):
+                if host.endswith(pattern_host[1:]):
+                    return True
+            elif host == pattern_host:
+                return True
+        return False
+
+    def allows_url(self, url: str) -> bool:
+        parsed = urllib.parse.urlparse(url)
+        try:
+            port = parsed.port
+        except ValueError:
+            return False
+        return self.allows_host(parsed.hostname or "", port)
+
+
+_policy: EgressPolicy | None = None
+
+
+def init_egress_policy(allowed_hosts: Iterable[str]) -> None:
+    """Configure the process-wide egress allowlist (empty = allow every host)."""
+    global _policy  # noqa: PLW0603
+    normalized = frozenset(host.strip().lower() for host in allowed_hosts if host.strip())
+    if not normalized:
+        _policy = None
+        return
+    _policy = EgressPolicy(allowed=normalized)
+    logger.info("Egress allowlist enabled (hosts=%s)", ",".join(sorted(normalized)))
+
+
+def check_egress(url: str) -> None:
+    """Raise EgressDenied if *url* points outside the configured allowlist."""
+    if _policy is not None and not _policy.allows_url(url):
+        host = urllib.parse.urlparse(url).netloc or url
+        logger.warning("egress_denied host=%s", host)
+        raise EgressDenied(f"egress to {host} is not in EGRESS_ALLOWED_HOSTS_JSON")
+
+
+def is_host_allowed(host: str) -> bool:
+    return _policy is None or _policy.allows_host(host)
@@ -0,0 +1,153 @@
+from __future__ import annotations
+
+import base64
+import json
+import threading
+from collections.abc import Sequence
+from typing import Protocol
+
+from cryptography.fernet import Fernet, InvalidToken, MultiFernet
+
+_ENVELOPE_KEY = "__enc__"
+_ENVELOPE_VERSION = "fernet-v1"
+_TEXT_PREFIX = "enc:fernet-v1:"
+_KMS_ENVELOPE_VERSION = "kms-v1"
+_KMS_TEXT_PREFIX = "enc:kms-v1:"
+# Unwrapped data keys kept in memory; each replica start writes with a new one.
+_MAX_CACHED_DATA_KEYS = 256
+
+
+class DataKeyWrapper(Protocol):
+    """Key management service that wraps the data keys of envelope encryption."""
+
+    def wrap(self, data_key: bytes) -> bytes: ...
+
+    def unwrap(self, wrapped: bytes) -> bytes: ...
+
+
+class FieldCipher:
+    """Encrypt stored session payloads and summaries with Fernet keys.
+
+    The first key encrypts; all keys are tried on decrypt so keys can be
+    rotated by prepending a new key. Values written before encryption was
+    enabled are returned unchanged.
+
+    With a *key_wrapper* (KMS), values are envelope-encrypted instead: each
+    cipher generates a Fernet data key, stores it wrapped by the KMS next to
+    the ciphertext, and only the KMS can unwrap it. The local keys then only
+    decrypt values written before the KMS was configured.
+    """
+
+    def __init__(
+        self, keys: Sequence[str], *, key_wrapper: DataKeyWrapper | None = None
+    ) -> None:
+        if not keys and key_wrapper is None:
+            raise ValueError("ENCRYPTION_KEYS must contain at least one key")
+        try:
+            fernets = [Fernet(key.encode("utf-8")) for key in keys]
+        except ValueError as exc:
+            raise ValueError(
+                "ENCRYPTION_KEYS must be url-safe base64-encoded 32-byte Fernet keys"
+            ) from exc
+        self._fernet = MultiFernet(fernets) if fernets else None
+        self._key_wrapper = key_wrapper
+        self._data_keys: dict[str, Fernet] = {}
+        self._data_keys_lock = threading.Lock()
+        self._wrapped_key = ""
+        if key_wrapper is not None:
+            data_key = Fernet.generate_key()
+            try:
+                wrapped = key_wrapper.wrap(data_key)
+            except Exception as exc:  # noqa: BLE001
+                raise ValueError(f"data key could not be wrapped by the KMS: {exc}") from exc
+            self._wrapped_key = base64.urlsafe_b64encode(wrapped).decode("ascii")
+            self._data_keys[self._wrapped_key] = Fernet(data_key)
+
+    def encrypt_json(self, payload: dict[str, object]) -> dict[str, object]:
+        plaintext = json.dumps(payload).encode("utf-8")
+        if self._key_wrapper is not None:
+            token = self._data_keys[self._wrapped_key].encrypt(plaintext).decode("ascii")
+            return {_ENVELOPE_KEY: _KMS_ENVELOPE_VERSION, "dk": self._wrapped_key, "ct": token}
+        token = self._local_fernet().encrypt(plaintext).decode("ascii")
+        return {_ENVELOPE_KEY: _ENVELOPE_VERSION, "ct": token}
+
+    def decrypt_json(self, stored: object) -> object:
+        if not isinstance(stored, dict):
+            return stored
+        version = stored.get(_ENVELOPE_KEY)
+        if version not in (_ENVELOPE_VERSION, _KMS_ENVELOPE_VERSION):
+            return stored
+        token = stored.get("ct")
+        if not isinstance(token, str):
+            raise ValueError("encrypted payload is missing ciphertext")
+        if version == _ENVELOPE_VERSION:
+            return json.loads(self._decrypt(token))
+        wrapped = stored.get("dk")
+        if not isinstance(wrapped, str):
+            raise ValueError("encrypted payload is missing its wrapped data key")
+        return json.loads(self._decrypt_envelope(wrapped, token))
+
+    def encrypt_text(self, value: str) -> str:
+        plaintext = value.encode("utf-8")
+        if self._key_wrapper is not None:
+            token = self._data_keys[self._wrapped_key].encrypt(plaintext).decode("ascii")
+            # Base64url never contains ".", so it separates the key from the token.
+            return f"{_KMS_TEXT_PREFIX}{self._wrapped_key}.{token}"
+        token = self._local_fernet().encrypt(plaintext).decode("ascii")
+        return f"{_TEXT_PREFIX}{token}"
+
+    def decrypt_text(self, stored: str) -> str:
+        if stored.startswith(_KMS_TEXT_PREFIX):
+            wrapped, sep, token = stored[len(_KMS_TEXT_PREFIX) :].partition(".")
+            if not sep:
+                raise ValueError("encrypted value is missing its wrapped data key")
+            return self._decrypt_envelope(wrapped, token)
+        if not stored.startswith(_TEXT_PREFIX):
+            return stored
+        return self._decrypt(stored[len(_TEXT_PREFIX) :])
+
+    def _local_fernet(self) -> MultiFernet:
+        if self._fernet is None:
+            raise ValueError("stored payload was encrypted with ENCRYPTION_KEYS, which are not set")
+        return self._fernet
+
+    def _decrypt(self, token: str) -> str:
+        try:
+            return self._local_fernet().decrypt(token.encode("ascii")).decode("utf-8")
+        except InvalidToken as exc:
+            raise ValueError("stored payload could not be decrypted with ENCRYPTION_KEYS") from exc
+
+    def _decrypt_envelope(self, wrapped: str, token: str) -> str:
+        try:
+            return self._data_key(wrapped).decrypt(token.encode("ascii")).decode("utf-8")
+        except InvalidToken as exc:
+            raise ValueError("stored payload could not be decrypted with its data key") from exc
+
+    def _data_key(self, wrapped: str) -> Fernet:
+        with self._data_keys_lock:
+            cached = self._data_keys.get(wrapped)
+        if cached is not None:
+            return cached
+        if self._key_wrapper is None:
+            raise ValueError(
+                "stored payload was envelope-encrypted, but ENCRYPTION_KMS_PROVIDER is not set"
+            )
+        try:
+            data_key = Fernet(self._key_wrapper.unwrap(base64.urlsafe_b64decode(wrapped)))
+        except Exception as exc:  # noqa: BLE001
+            raise ValueError(f"data key could not be unwrapped by the KMS: {exc}") from exc
+        with self._data_keys_lock:
+            if len(self._data_keys) >= _MAX_CACHED_DATA_KEYS:
+                # Keep the key this replica writes with, drop the oldest other one.
+                oldest = next(key for key in self._data_keys if key != self._wrapped_key)
+                del self._data_keys[oldest]
+            self._data_keys[wrapped] = data_key
+        return data_key
+
+
+def build_field_cipher(
+    keys: Sequence[str], *, key_wrapper: DataKeyWrapper | None = None
+) -> FieldCipher | None:
+    if not keys and key_wrapper is None:
+        return None
+    return FieldCipher(keys, key_wrapper=key_wrapper)
@@ -0,0 +1,231 @@
+"""Score collected evidence and keep the most telling parts within a budget.
+
+Head/tail truncation drops the line that explains an incident as soon as a
+few routine lines follow it. Instead, log lines are scored (fatal/error
+lines over warnings over the rest, stack frames count with the error they
+belong to), repeats collapse into one ``[xN]`` line, and the best-scored
+lines are kept in their original order, always including the last lines
+before the cut. Gaps are marked with ``... N line(s) omitted ...``.
+Events are de-duplicated and Warning events with high counts win; range
+query series are ranked by how far they stray from their own median.
+"""
+
+from __future__ import annotations
+
+import re
+import statistics
+
+# Container logs are read with timestamps=true.
+_LOG_TIMESTAMP_RE = re.compile(
+    r"^\d{4}-\d{2}-\d{2}T\d{2}:\d{2}:\d{2}(?:\.\d+)?(?:Z|[+-]\d{2}:\d{2})\s"
+)
+_ERROR_RE = re.compile(
+    r"\b(fatal|panic|exception|traceback|error|critical|failed|failure|refused|"
+    r"timed? ?out|denied|oom|killed)\b|(?-i:\w(Error|Exception))\b|level=(error|fatal|crit)|"
+    r"^[EF]\d{4} ",
+    re.I,
+)
+_WARNING_RE = re.compile(
+    r"\b(warn|warning|retry|retrying|unavailable|back-?off|deprecated)\b|level=warn|^W\d{4} ",
+    re.I,
+)
+_FRAME_RE = re.compile(r"^(\s+|\tat |Caused by:|goroutine \d+)")
+_SCORE_ERROR = 3
+_SCORE_WARNING = 2
+_SCORE_OTHER = 1
+
+DEFAULT_MAX_SERIES = 20
+DEFAULT_MAX_SERIES_POINTS = 120
+
+
+def strip_log_timestamp(line: str) -> str:
+    return _LOG_TIMESTAMP_RE.sub("", line, count=1)
+
+
+def score_log_line(line: str) -> int:
+    text = strip_log_timestamp(line)
+    if _ERROR_RE.search(text):
+        return _SCORE_ERROR
+    if _WARNING_RE.search(text):
+        return _SCORE_WARNING
+    return _SCORE_OTHER
+
+
+def select_log_lines(lines: list[str], limit: int) -> list[str]:
+    """At most *limit* lines (plus gap markers), the best-scored ones first."""
+    if limit <= 0:
+        return []
+    collapsed = _collapse_repeats(lines)
+    if len(collapsed) <= limit:
+        return collapsed
+
+    scores: list[int] = []
+    for index, line in enumerate(collapsed):
+        score = score_log_line(line)
+        # A stack frame is as important as the error line it belongs to.
+        if index and _FRAME_RE.match(strip_log_timestamp(line)):
+            score = max(score, scores[-1])
+        scores.append(score)
+
+    tail = max(1, limit // 5)
+    keep = set(range(len(collapsed) - tail, len(collapsed)))
+    # Ties go to the most recent line.
+    ranked = sorted(range(len(collapsed) - tail), key=lambda index: (-scores[index], -index))
+    keep.update(ranked[: limit - tail])
+    return _with_gap_markers(collapsed, keep)
+
+
+def select_events(events: list[dict[str, object]], limit: int) -> list[dict[str, object]]:
+    """Unique events, Warning and frequent ones first, in their original order."""
+    if limit <= 0:
+        return []
+    unique: dict[tuple[object, object, object], dict[str, object]] = {}
+    for event in events:
+        key = (event.get("type"), event.get("reason"), event.get("message"))
+        existing = unique.get(key)
+        if existing is None:
+            unique[key] = dict(event)
+            continue
+        existing["count"] = _count(existing) + _count(event)
+        if event.get("last_timestamp"):
+            existing["last_timestamp"] = event["last_timestamp"]
+    merged = list(unique.values())
+    if len(merged) <= limit:
+        return merged
+    ranked = sorted(
+        range(len(merged)),
+        key=lambda index: (merged[index].get("type") != "Warning", -_count(merged[index]), index),
+    )
+    keep = set(ranked[:limit])
+    return [event for index, event in enumerate(merged) if index in keep]
+
+
+def budget_range_result(
+    result: dict[str, object],
+    *,
+    max_series: int = DEFAULT_MAX_SERIES,
+    max_points: int = DEFAULT_MAX_SERIES_POINTS,
+) -> dict[str, object]:
+    """Keep the most anomalous series of a Prometheus range query, each downsampled.
+
+    Works on ``PrometheusClient.query_range`` output; anything else is
+    returned unchanged. ``budget`` records what was dropped.
+    """
+    payload = result.get("data")
+    if not isinstance(payload, dict) or not isinstance(payload.get("data"), dict):
+        return result
+    data = payload["data"]
+    series = data.get("result")
+    if not isinstance(series, list):
+        return result
+    entries = [entry for entry in series if isinstance(entry, dict)]
+    too_long = any(len(_values(entry)) > max_points for entry in entries)
+    if len(entries) <= max_series and not too_long:
+        return result
+
+    ranked = sorted(entries, key=_anomaly_score, reverse=True)[:max_series]
+    kept = [
+        {**entry, "values": _downsample(_values(entry), max_points)} for entry in ranked
+    ]
+    return {
+        **result,
+        "data": {**payload, "data": {**data, "result": kept}},
+        "budget": {
+            "series_total": len(entries),
+            "series_kept": len(kept),
+            "max_points": max_points,
+            "selection": "most anomalous (max deviation from median)",
+        },
+    }
+
+
+def _collapse_repeats(lines: list[str]) -> list[str]:
+    """Keep the last occurrence of repeated lines (timestamps ignored), marked ``[xN]``."""
+    counts: dict[str, int] = {}
+    last_index: dict[str, int] = {}
+    for index, line in enumerate(lines):
+        key = strip_log_timestamp(line)
+        counts[key] = counts.get(key, 0) + 1
+        last_index[key] = index
+    collapsed: list[str] = []
+    for index, line in enumerate(lines):
+        key = strip_log_timestamp(line)
+        if last_index[key] != index:
+            continue
+        collapsed.append(f"{line} [x{counts[key]}]" if counts[key] > 1 else line)
+    return collapsed
+
+
+def _with_gap_markers(lines: list[str], keep: set[int]) -> list[str]:
+    selected: list[str] = []
+    skipped = 0
+    for index, line in enumerate(lines):
+        if index not in keep:
+            skipped += 1
+            continue
+        if skipped:
+            selected.append(f"... {skipped} line(s) omitted ...")
+            skipped = 0
+        selected.append(line)
+    if skipped:
+        selected.append(f"... {skipped} line(s) omitted ...")
+    return selected
+
+
+def _values(entry: dict[str, object]) -> list[list[object]]:
+    values = entry.get("values")
+    if not isinstance(values, list):
+        return []
+    return [item for item in values if isinstance(item, list) and len(item) == 2]
+
+
+def _numbers(entry: dict[str, object]) -> list[float]:
+    return [number for _, value in _values(entry) if (number := _float(value)) is not None]
+
+
+def _anomaly_score(entry: dict[str, object]) -> float:
+    numbers = _numbers(entry)
+    if not numbers:
+        return 0.0
+    median = statistics.median(numbers)
+    deviation = max(abs(number - median) for number in numbers)
+    # Relative to the series' own level, so small and large series compare.
+    return deviation / max(abs(median), 1e-9)
+
+
+def _downsample(values: list[list[object]], max_points: int) -> list[list[object]]:
+    """Every bucket keeps its most extreme point, so spikes survive."""
+    if len(values) <= max_points or max_points <= 0:
+        return values
+    bucket_size = len(values) / max_points
+    numbers = [_float(value) for _, value in values]
+    finite = [number for number in numbers if number is not None]
+    median = statistics.median(finite) if finite else 0.0
+    sampled: list[list[object]] = []
+    for bucket in range(max_points):
+        start = int(bucket * bucket_size)
+        end = max(start + 1, int((bucket + 1) * bucket_size))
+        best = max(
+            range(start, min(end, len(values))),
+            key=lambda index: abs(_or(numbers[index], median) - median),
+        )
+        sampled.append(values[best])
+    return sampled
+
+
+def _or(number: float | None, default: float) -> float:
+    return default if number is None else number
+
+
+def _float(value: object) -> float | None:
+    try:
+        number = float(str(value))
+    except ValueError:
+        return None
+    # NaN is the only value not equal to itself.
+    return number if number == number else None
+
+
+def _count(event: dict[str, object]) -> int:
+    count = event.get("count")
+    return count if isinstance(count, int) and count > 0 else 1
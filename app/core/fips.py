@@ -0,0 +1,45 @@
+"""FIPS 140 mode: refuse to start unless crypto runs on a FIPS-enabled OpenSSL.
+
+The agent only uses FIPS-approved algorithms (SHA-256, HMAC-SHA256, AES via
+Fernet, RSA/ECDSA/RSASSA-PSS for OIDC tokens, TLS 1.2+). Whether those run in a
+validated module depends on the OpenSSL build, so FIPS mode verifies it at
+startup instead of trusting the image.
+"""
+
+from __future__ import annotations
+
+import hashlib
+import logging
+import ssl
+
+logger = logging.getLogger(__name__)
+
+
+def openssl_fips_enabled() -> bool:
+    try:
+        from cryptography.hazmat.backends.openssl.backend import backend
+    except ImportError:
+        return False
+    return bool(getattr(backend, "_fips_enabled", False))
+
+
+def _md5_blocked() -> bool:
+    try:
+        hashlib.md5(b"fips-probe")
+    except ValueError:
+        return True
+    return False
+
+
+def enforce_fips_mode(enabled: bool) -> None:
+    """Raise RuntimeError when FIPS mode is requested but not provided by OpenSSL."""
+    if not enabled:
+        return
+    problems: list[str] = []
+    if not openssl_fips_enabled():
+        problems.append("cryptography is not using a FIPS-enabled OpenSSL provider")
+    if not _md5_blocked():
+        problems.append("hashlib allows MD5 (OpenSSL default properties are not fips=yes)")
+    if problems:
+        raise RuntimeError("FIPS_MODE=true but " + "; ".join(problems))
+    logger.info("FIPS mode verified (%s)", ssl.OPENSSL_VERSION)
@@ -0,0 +1,106 @@
+"""Remote clusters an analysis can target, as named kubeconfig contexts.
+
+``K8S_CLUSTERS_JSON`` maps cluster names to a kubeconfig file (and context);
+``K8S_CLUSTER_NAME`` names the agent's own cluster, reached through the
+in-cluster config or the default kubeconfig. ``use_cluster`` selects the
+cluster of the current analysis (it follows threads like the caller
+credentials), and the API objects wrapped with ``wrap_with_credentials`` send
+their calls to that cluster's API server.
+"""
+
+from __future__ import annotations
+
+import logging
+import threading
+from collections.abc import Iterable, Iterator
+from contextlib import contextmanager
+from contextvars import ContextVar
+from dataclasses import dataclass
+from typing import Any
+
+from kubernetes import config
+
+logger = logging.getLogger(__name__)
+
+
+@dataclass(frozen=True)
+class RemoteCluster:
+    name: str
+    kubeconfig: str
+    context: str | None = None
+
+
+class UnknownCluster(ValueError):
+    """Raised when a request names a cluster that is not configured."""
+
+
+_clusters: dict[str, RemoteCluster] = {}
+_local_name = ""
+_clients: dict[str, Any] = {}
+_clients_lock = threading.Lock()
+_current: ContextVar[str | None] = ContextVar("k8s_cluster", default=None)
+
+
+def init_clusters(clusters: Iterable[tuple[str, str, str]], *, local_name: str = "") -> None:
+    """Configure the remote clusters as (name, kubeconfig path, context) entries."""
+    global _local_name  # noqa: PLW0603
+    with _clients_lock:
+        for api_client in _clients.values():
+            api_client.close()
+        _clients.clear()
+    _clusters.clear()
+    _local_name = local_name.strip()
+    for name, kubeconfig, context in clusters:
+        _clusters[name] = RemoteCluster(name, kubeconfig, context or None)
+    if _clusters:
+        logger.info(
+            "Multi-cluster analysis enabled (local=%s, remote=%s)",
+            _local_name or "unnamed",
+            ",".join(sorted(_clusters)),
+        )
+
+
+def clusters_configured() -> bool:
+    return bool(_clusters)
+
+
+def resolve_cluster(name: str | None) -> str | None:
+    """The remote cluster *name* selects; ``None`` for the agent's own cluster."""
+    name = (name or "").strip()
+    if not name or not _clusters or name == _local_name:
+        return None
+    if name not in _clusters:
+        raise UnknownCluster(f"cluster {name} is not configured in K8S_CLUSTERS_JSON")
+    return name
+
+
+@contextmanager
+def use_cluster(name: str | None) -> Iterator[None]:
+    """Send the Kubernetes calls of the current context to remote cluster *name*."""
+    if name is None:
+        yield
+        return
+    resolve_cluster(name)
+    reset = _current.set(name)
+    try:
+        yield
+    finally:
+        _current.reset(reset)
+
+
+def current_cluster() -> str | None:
+    return _current.get()
+
+
+def cluster_api_client() -> Any | None:
+    """API client of the selected remote cluster, ``None`` for the agent's own cluster."""
+    name = _current.get()
+    if name is None:
+        return None
+    with _clients_lock:
+        if name not in _clients:
+            cluster = _clusters[name]
+            _clients[name] = config.new_client_from_config(
+                config_file=cluster.kubeconfig, context=cluster.context, persist_config=False
+            )
+        return _clients[name]
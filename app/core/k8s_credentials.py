@@ -0,0 +1,188 @@
+"""Per-request Kubernetes credentials (bearer token pass-through).
+
+With ``K8S_CREDENTIAL_PASSTHROUGH=true`` an analysis request may carry a
+short-lived bearer token, or a ``vault:<path>#<key>`` reference to one under
+``K8S_CREDENTIAL_VAULT_PREFIX``. ``use_cluster_token`` makes the token current
+for the analysis (it follows ``asyncio.to_thread`` and the agent's tool
+threads), and the API objects wrapped with ``wrap_with_credentials`` send
+their calls with it instead of the agent's ServiceAccount, so the analysis
+sees exactly what the caller may read. The same objects send the calls to the
+remote cluster ``use_cluster`` selected (see ``app.core.k8s_clusters``).
+"""
+
+from __future__ import annotations
+
+import copy
+import functools
+import logging
+import threading
+from collections.abc import Iterator
+from contextlib import contextmanager
+from contextvars import ContextVar
+from typing import Any, Protocol
+
+from kubernetes import client
+from pydantic import SecretStr
+
+from app.core.k8s_clusters import cluster_api_client, clusters_configured
+from app.core.secret_sources import read_vault_secret
+
+logger = logging.getLogger(__name__)
+
+_VAULT_PREFIX = "vault:"
+
+_enabled = False
+_vault_prefix = ""
+
+
+class _RequestCredentials(Protocol):
+    token: SecretStr | None
+    token_ref: str | None
+
+
+class ClusterCredentialError(ValueError):
+    """Raised for request credentials that are not accepted."""
+
+
+class _CallerCredentials:
+    def __init__(self, token: str) -> None:
+        self._token = token
+        self._lock = threading.Lock()
+        self._api_client: Any = None
+
+    @property
+    def api_client(self) -> Any:
+        with self._lock:
+            if self._api_client is None:
+                # The token is sent to the selected cluster.
+                cluster = cluster_api_client()
+                configuration = (
+                    copy.deepcopy(cluster.configuration)
+                    if cluster is not None
+                    else client.Configuration.get_default_copy()
+                )
+                configuration.api_key = {"authorization": self._token}
+                configuration.api_key_prefix = {"authorization": "Bearer"}
+                # The in-cluster hook would put the ServiceAccount token back.
+                configuration.refresh_api_key_hook = None
+                configuration.cert_file = None
+                configuration.key_file = None
+                configuration.username = None
+                configuration.password = None
+                self._api_client = client.ApiClient(configuration)
+            return self._api_client
+
+    def close(self) -> None:
+        with self._lock:
+            if self._api_client is not None:
+                self._api_client.close()
+                self._api_client = None
+
+
+_credentials: ContextVar[_CallerCredentials | None] = ContextVar(
+    "k8s_caller_credentials", default=None
+)
+
+
+def init_credential_passthrough(enabled: bool, *, vault_prefix: str = "") -> None:
+    """Accept caller credentials on analysis requests (before clients are constructed)."""
+    global _enabled, _vault_prefix  # noqa: PLW0603
+    _enabled = enabled
+    _vault_prefix = vault_prefix.strip().strip("/")
+    if enabled:
+        logger.info(
+            "Kubernetes credential pass-through enabled (vault references: %s)",
+            _vault_prefix or "disabled",
+        )
+
+
+def validate_cluster_credentials(credentials: _RequestCredentials | None) -> None:
+    """Reject credentials the agent does not accept, without resolving references."""
+    if credentials is None:
+        return
+    if not _enabled:
+        raise ClusterCredentialError(
+            "cluster_credentials are not accepted (K8S_CREDENTIAL_PASSTHROUGH is disabled)"
+        )
+    token = credentials.token.get_secret_value().strip() if credentials.token else ""
+    reference = (credentials.token_ref or "").strip()
+    if bool(token) == bool(reference):
+        raise ClusterCredentialError("cluster_credentials need exactly one of token or token_ref")
+    if not reference:
+        return
+    if not reference.startswith(_VAULT_PREFIX):
+        raise ClusterCredentialError(
+            "cluster_credentials.token_ref must look like vault:<path>#<key>"
+        )
+    path = reference[len(_VAULT_PREFIX) :].partition("#")[0].strip().strip("/")
+    if not _vault_prefix or not (path == _vault_prefix or path.startswith(f"{_vault_prefix}/")):
+        raise ClusterCredentialError(
+            "cluster_credentials.token_ref is outside K8S_CREDENTIAL_VAULT_PREFIX"
+        )
+
+
+def resolve_cluster_token(credentials: _RequestCredentials | None) -> str | None:
+    """The caller's bearer token, ``None`` when the request carries no credentials."""
+    validate_cluster_credentials(credentials)
+    if credentials is None:
+        return None
+    if credentials.token is not None and credentials.token.get_secret_value().strip():
+        return credentials.token.get_secret_value().strip()
+    reference = str(credentials.token_ref).strip()[len(_VAULT_PREFIX) :]
+    try:
+        return read_vault_secret("cluster_credentials.token_ref", reference).strip()
+    except (OSError, ValueError) as exc:
+        raise ClusterCredentialError(str(exc)) from exc
+
+
+@contextmanager
+def use_cluster_token(token: str | None) -> Iterator[None]:
+    """Send the Kubernetes calls of the current context with *token* (no-op for ``None``)."""
+    if not token:
+        yield
+        return
+    credentials = _CallerCredentials(token)
+    reset = _credentials.set(credentials)
+    try:
+        yield
+    finally:
+        _credentials.reset(reset)
+        credentials.close()
+
+
+def caller_credentials_active() -> bool:
+    return _credentials.get() is not None
+
+
+def wrap_with_credentials(api: Any) -> Any:
+    """Wrap a Kubernetes API object so calls use the caller's token and selected cluster."""
+    if api is None or not (_enabled or clusters_configured()):
+        return api
+    return _CredentialedApiProxy(api)
+
+
+def _current_api_client() -> Any | None:
+    credentials = _credentials.get()
+    return credentials.api_client if credentials is not None else cluster_api_client()
+
+
+class _CredentialedApiProxy:
+    def __init__(self, wrapped: Any) -> None:
+        self._wrapped = wrapped
+
+    def __getattr__(self, name: str) -> Any:
+        if name == "api_client":
+            return _current_api_client() or self._wrapped.api_client
+        attr = getattr(self._wrapped, name)
+        if name.startswith("_") or not callable(attr):
+            return attr
+
+        # Keep the docstring: kubernetes.watch reads the return type from it.
+        @functools.wraps(attr)
+        def _call(*args: Any, **kwargs: Any) -> Any:
+            api_client = _current_api_client()
+            if api_client is None:
+                return attr(*args, **kwargs)
+            return getattr(type(self._wrapped)(api_client), name)(*args, **kwargs)
+
+        return _call
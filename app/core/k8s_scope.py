@@ -0,0 +1,259 @@
+"""Kubernetes access scope of the collectors (namespaces, API groups, verbs).
+
+Every Kubernetes API object of ``KubernetesClient`` is wrapped with
+``wrap_with_scope``; reads outside the configured scope raise
+``AccessDenied`` before a request is sent, and the collector degrades the
+same way as on a 403. In least-privilege mode the permissions the startup
+RBAC self-check found denied are skipped too, so a tightly scoped
+ServiceAccount yields warnings instead of failed requests mid-analysis.
+"""
+
+from __future__ import annotations
+
+import functools
+import logging
+from collections.abc import Iterable
+from dataclasses import dataclass, replace
+from typing import Any
+
+from app.core.rbac import Permission
+
+logger = logging.getLogger(__name__)
+
+_VERB_PREFIXES = (("connect_get_", "get"), ("read_", "get"), ("list_", "list"))
+_SUBRESOURCES = frozenset({"log", "proxy", "status", "scale", "binding", "eviction"})
+
+
+class AccessDenied(PermissionError):
+    """Raised when a Kubernetes call is outside the configured access scope."""
+
+
+@dataclass(frozen=True)
+class AccessScope:
+    """Allowed namespaces, API groups (``core`` for the core group) and verbs; empty = all."""
+
+    namespaces: frozenset[str] = frozenset()
+    api_groups: frozenset[str] = frozenset()
+    verbs: frozenset[str] = frozenset()
+    cluster_scoped: bool = True
+    least_privilege: bool = False
+    # (verb, group, resource, namespace) the startup self-check found denied.
+    denied: frozenset[tuple[str, str, str, str | None]] = frozenset()
+
+    def describe(self) -> dict[str, object]:
+        return {
+            "namespaces": sorted(self.namespaces),
+            "api_groups": sorted(self.api_groups),
+            "verbs": sorted(self.verbs),
+            "cluster_scoped": self.cluster_scoped,
+            "least_privilege": self.least_privilege,
+            "skipped_calls": [
+                f"{verb} {resource}{'.' + group if group else ''}"
+                + f" {_describe_namespace(namespace)}"
+                for verb, group, resource, namespace in sorted(
+                    self.denied, key=lambda item: tuple(part or "" for part in item)
+                )
+            ],
+        }
+
+    def denial(self, verb: str, group: str, resource: str, namespace: str | None) -> str | None:
+        """Why the call is outside the scope, or ``None`` when it is allowed.
+
+        *namespace* is ``None`` for cluster-scoped resources and ``*`` for
+        reads across all namespaces.
+        """
+        reason = self.configured_denial(verb, group, namespace)
+        if reason is None and (verb, group, resource, namespace) in self.denied:
+            reason = "denied by the startup RBAC self-check (K8S_LEAST_PRIVILEGE=true)"
+        return reason
+
+    def configured_denial(self, verb: str, group: str, namespace: str | None) -> str | None:
+        reason = self.kind_denial(verb, group)
+        if reason is not None:
+            return reason
+        if namespace == "*" and self.namespaces:
+            return "reads across all namespaces are outside K8S_ALLOWED_NAMESPACES_JSON"
+        if namespace in (None, "*") and not self.cluster_scoped:
+            return "cluster-scoped access is disabled (K8S_CLUSTER_SCOPED_ACCESS=false)"
+        if namespace not in (None, "*") and self.namespaces and namespace not in self.namespaces:
+            return f"namespace {namespace} is not in K8S_ALLOWED_NAMESPACES_JSON"
+        return None
+
+    def kind_denial(self, verb: str, group: str) -> str | None:
+        if self.verbs and verb not in self.verbs:
+            return f"verb {verb} is not in K8S_ALLOWED_VERBS_JSON"
+        if self.api_groups and (group or "core") not in self.api_groups:
+            return f"API group {group or 'core'} is not in K8S_ALLOWED_API_GROUPS_JSON"
+        return None
+
+
+_scope: AccessScope | None = None
+
+
+def init_access_scope(
+    namespaces: Iterable[str] = (),
+    api_groups: Iterable[str] = (),
+    verbs: Iterable[str] = (),
+    *,
+    cluster_scoped: bool = True,
+    least_privilege: bool = False,
+) -> None:
+    """Configure the process-wide access scope (unrestricted unless something is set)."""
+    global _scope  # noqa: PLW0603
+    scope = AccessScope(
+        namespaces=frozenset(item.strip() for item in namespaces if item.strip()),
+        api_groups=frozenset(item.strip().lower() for item in api_groups if item.strip()),
+        verbs=frozenset(item.strip().lower() for item in verbs if item.strip()),
+        cluster_scoped=cluster_scoped,
+        least_privilege=least_privilege,
+    )
+    if scope == AccessScope():
+        _scope = None
+        return
+    _scope = scope
+    logger.info(
+        "Kubernetes access scope enabled (namespaces=%s, api_groups=%s, verbs=%s, "
+        "cluster_scoped=%s, least_privilege=%s)",
+        ",".join(sorted(scope.namespaces)) or "all",
+        ",".join(sorted(scope.api_groups)) or "all",
+        ",".join(sorted(scope.verbs)) or "all",
+        scope.cluster_scoped,
+        scope.least_privilege,
+    )
+
+
+def current_access_scope() -> AccessScope:
+    return _scope or AccessScope()
+
+
+def scope_permissions(permissions: Iterable[Permission], scope: AccessScope) -> list[Permission]:
+    """*permissions* inside *scope*; reads of any namespace become one per allowed namespace."""
+    scoped: list[Permission] = []
+    for permission in permissions:
+        if scope.kind_denial(permission.verb, permission.group) is not None:
+            continue
+        if permission.cluster_scoped:
+            if scope.cluster_scoped:
+                scoped.append(permission)
+        elif permission.namespace:
+            if scope.configured_denial(permission.verb, permission.group, permission.namespace):
+                continue
+            scoped.append(permission)
+        elif scope.namespaces:
+            scoped.extend(
+                replace(permission, namespace=namespace) for namespace in sorted(scope.namespaces)
+            )
+        else:
+            # Any namespace: a ClusterRole, bound per namespace without cluster-scoped access.
+            scoped.append(permission)
+    return scoped
+
+
+def record_denied_permissions(checks: Iterable[dict[str, object]]) -> int:
+    """Skip the calls of denied self-check results from now on (least-privilege mode only).
+
+    The scope is frozen: a new one is built and swapped in, so concurrent
+    readers see either the old or the new denied set, never a set mid-update.
+    """
+    global _scope  # noqa: PLW0603
+    scope = _scope
+    if scope is None or not scope.least_privilege:
+        return 0
+    denied: list[tuple[str, str, str, str | None]] = []
+    for check in checks:
+        if check.get("allowed") is not False:
+            continue
+        group = str(check.get("group") or "core")
+        namespace = check.get("namespace")
+        denied.append(
+            (
+                str(check["verb"]),
+                "" if group == "core" else group,
+                str(check["resource"]),
+                None if namespace is None else str(namespace),
+            )
+        )
+    _scope = replace(scope, denied=scope.denied | frozenset(denied))
+    return len(denied)
+
+
+def wrap_with_scope(api: Any, group: str | None) -> Any:
+    """Wrap a Kubernetes API object so reads are checked against the access scope.
+
+    *group* is the API group of the object's resources (``""`` for core);
+    ``None`` takes it from the ``group`` argument (custom objects).
+    """
+    if api is None or _scope is None:
+        return api
+    return _ScopedApiProxy(api, group)
+
+
+def describe_call(
+    name: str, group: str | None, kwargs: dict[str, Any]
+) -> tuple[str, str, str, str | None] | None:
+    """(verb, group, resource, namespace) of a Kubernetes client read method, else ``None``.
+
+    The namespace is ``None`` for cluster-scoped reads and ``*`` for ``*_for_all_namespaces``.
+    """
+    namespace = kwargs.get("namespace")
+    if name.endswith("_custom_object") and name.startswith(("get_", "list_")):
+        verb = "list" if name.startswith("list_") else "get"
+        group, resource = str(kwargs.get("group") or ""), str(kwargs.get("plural") or "")
+        return ("watch" if kwargs.get("watch") else verb), group, resource, namespace
+    prefix, verb = next(
+        ((prefix, verb) for prefix, verb in _VERB_PREFIXES if name.startswith(prefix)),
+        ("", None),
+    )
+    if verb is None:
+        return None
+    kind = name[len(prefix) :].removeprefix("namespaced_").removesuffix("_with_path")
+    if kind.endswith("_for_all_namespaces"):
+        kind, namespace = kind.removesuffix("_for_all_namespaces"), "*"
+    parts = kind.split("_")
+    subresource = parts.pop() if len(parts) > 1 and parts[-1] in _SUBRESOURCES else None
+    resource = _plural("".join(parts))
+    if subresource:
+        resource = f"{resource}/{subresource}"
+    return ("watch" if kwargs.get("watch") else verb), group or "", resource, namespace
+
+
+def _describe_namespace(namespace: str | None) -> str:
+    if namespace is None:
+        return "cluster-wide"
+    return "in all namespaces" if namespace == "*" else f"in {namespace}"
+
+
+def _plural(kind: str) -> str:
+    if kind.endswith("y"):
+        return kind[:-1] + "ies"
+    if kind.endswith("s"):
+        return kind + "es"
+    return kind + "s"
+
+
+class _ScopedApiProxy:
+    def __init__(self, wrapped: Any, group: str | None) -> None:
+        self._wrapped = wrapped
+        self._group = group
+
+    def __getattr__(self, name: str) -> Any:
+        attr = getattr(self._wrapped, name)
+        if name.startswith("_") or not callable(attr):
+            return attr
+
+        # Keep the docstring: kubernetes.watch reads the return type from it.
+        @functools.wraps(attr)
+        def _call(*args: Any, **kwargs: Any) -> Any:
+            call = describe_call(name, self._group, kwargs)
+            if call is not None and _scope is not None:
+                reason = _scope.denial(*call)
+                if reason is not None:
+                    verb, group, resource, namespace = call
+                    logger.debug("k8s_access_denied %s %s/%s %s", verb, group, resource, namespace)
+                    raise AccessDenied(
+                        f"{verb} {resource}{'.' + group if group else ''} "
+                        f"{_describe_namespace(namespace)}: {reason}"
+                    )
+            return attr(*args, **kwargs)
+
+        return _call
@@ -3,6 +3,8 @@ from __future__ import annotations
 import logging
 from collections.abc import Iterable
 
+from app.core.correlation import CorrelationIdFilter
+
 
 class _HealthCheckFilter(logging.Filter):
     def __init__(self, paths: Iterable[str]) -> None:
@@ -27,7 +29,7 @@ def _extract_path(record: logging.LogRecord) -> str | None:
     if path:
         return path.split("?", 1)[0]
     message = record.getMessage()
-    for candidate in ("/healthz", "/ping", "/openapi.json", "/"):
+    for candidate in ("/healthz", "/readyz", "/ping", "/openapi.json", "/"):
         if f" {candidate} " in message or f'"{candidate} ' in message:
             return candidate
     return None
@@ -36,7 +38,12 @@ def _extract_path(record: logging.LogRecord) -> str | None:
 def configure_logging(level: str) -> None:
     logging.basicConfig(
         level=level.upper(),
-        format="%(asctime)s %(levelname)s %(name)s %(message)s",
+        format="%(asctime)s %(levelname)s %(name)s correlation_id=%(correlation_id)s %(message)s",
     )
+    # On the handlers, so records of every logger get the attribute the format needs.
+    for handler in logging.getLogger().handlers:
+        handler.addFilter(CorrelationIdFilter())
     access_logger = logging.getLogger("uvicorn.access")
-    access_logger.addFilter(_HealthCheckFilter({"/healthz", "/ping", "/openapi.json", "/"}))
+    access_logger.addFilter(
+        _HealthCheckFilter({"/healthz", "/readyz", "/ping", "/openapi.json", "/"})
+    )
@@ -0,0 +1,122 @@
+from __future__ import annotations
+
+import logging
+import os
+from pathlib import Path
+
+logger = logging.getLogger(__name__)
+
+MEMORY_LEVEL_NORMAL = "normal"
+MEMORY_LEVEL_ELEVATED = "elevated"
+MEMORY_LEVEL_CRITICAL = "critical"
+
+# cgroup v1 reports "no limit" as a very large page-aligned number.
+_CGROUP_V1_UNLIMITED_THRESHOLD = 1 << 60
+
+_LEVEL_SCALE = {
+    MEMORY_LEVEL_NORMAL: 1.0,
+    MEMORY_LEVEL_ELEVATED: 0.5,
+    MEMORY_LEVEL_CRITICAL: 0.25,
+}
+
+
+class MemoryPressureMonitor:
+    """Track container memory usage against its limit.
+
+    Usage and limit are read from cgroup v2 (``memory.current``/``memory.max``)
+    with a cgroup v1 fallback. An explicit ``limit_bytes`` overrides the cgroup
+    limit, which is useful when running outside a container.
+    """
+
+    def __init__(
+        self,
+        limit_bytes: int = 0,
+        soft_ratio: float = 0.75,
+        hard_ratio: float = 0.9,
+        cgroup_root: str = "/sys/fs/cgroup",
+    ) -> None:
+        self._cgroup_root = Path(cgroup_root)
+        self._soft_ratio = min(max(soft_ratio, 0.0), 1.0)
+        self._hard_ratio = min(max(hard_ratio, self._soft_ratio), 1.0)
+        self._limit_bytes = limit_bytes if limit_bytes > 0 else self._read_cgroup_limit()
+        if self._limit_bytes:
+            logger.info(
+                "Memory throttling enabled (limit=%d bytes, soft=%.2f, hard=%.2f)",
+                self._limit_bytes,
+                self._soft_ratio,
+                self._hard_ratio,
+            )
+
+    @property
+    def enabled(self) -> bool:
+        return self._limit_bytes > 0
+
+    @property
+    def limit_bytes(self) -> int:
+        return self._limit_bytes
+
+    def usage_bytes(self) -> int | None:
+        for name in ("memory.current", "memory/memory.usage_in_bytes"):
+            value = _read_int(self._cgroup_root / name)
+            if value is not None:
+                return value
+        return _read_process_rss()
+
+    def pressure(self) -> float:
+        if not self.enabled:
+            return 0.0
+        usage = self.usage_bytes()
+        if usage is None:
+            return 0.0
+        return usage / self._limit_bytes
+
+    def level(self) -> str:
+        pressure = self.pressure()
+        if pressure >= self._hard_ratio:
+            return MEMORY_LEVEL_CRITICAL
+        if pressure >= self._soft_ratio:
+            return MEMORY_LEVEL_ELEVATED
+        return MEMORY_LEVEL_NORMAL
+
+    def scale(self) -> float:
+        """Return the multiplier applied to concurrency and evidence limits."""
+        return _LEVEL_SCALE[self.level()]
+
+    def _read_cgroup_limit(self) -> int:
+        v2_path = self._cgroup_root / "memory.max"
+        try:
+            raw = v2_path.read_text(encoding="utf-8").strip()
+        except OSError:
+            raw = ""
+        if raw:
+            if raw == "max":
+                return 0
+            try:
+                return int(raw)
+            except ValueError:
+                return 0
+        v1_limit = _read_int(self._cgroup_root / "memory" / "memory.limit_in_bytes")
+        if v1_limit is None or v1_limit >= _CGROUP_V1_UNLIMITED_THRESHOLD:
+            return 0
+        return v1_limit
+
+
+def scale_limit(value: int, scale: float) -> int:
+    if value <= 0 or scale >= 1.0:
+        return value
+    return max(1, int(value * scale))
+
+
+def _read_int(path: Path) -> int | None:
+    try:
+        return int(path.read_text(encoding="utf-8").strip())
+    except (OSError, ValueError):
+        return None
+
+
+def _read_process_rss() -> int | None:
+    try:
+        fields = Path("/proc/self/statm").read_text(encoding="utf-8").split()
+        return int(fields[1]) * os.sysconf("SC_PAGE_SIZE")
+    except (OSError, ValueError, IndexError):
+        return None
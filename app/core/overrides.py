@@ -0,0 +1,133 @@
+"""Runtime analysis overrides loaded from a JSON file (typically a mounted ConfigMap).
+
+The file is re-read when its mtime changes, so prompt instructions and
+rule-analyzer tuning apply without a restart. A file that fails to parse keeps
+the previously loaded overrides in effect and is reported by ``/diagnostics``.
+
+Format::
+
+    {
+      "prompt_instructions": "Our Redis pods are expected to restart nightly.",
+      "disabled_rules": ["probe_failure"],
+      "rule_severities": {"non_zero_exit": "critical"}
+    }
+"""
+
+from __future__ import annotations
+
+import asyncio
+import json
+import logging
+import os
+import threading
+from dataclasses import dataclass, field
+from datetime import datetime, timezone
+
+logger = logging.getLogger(__name__)
+
+_SEVERITIES = frozenset({"critical", "warning", "info"})
+_KNOWN_KEYS = frozenset({"prompt_instructions", "disabled_rules", "rule_severities"})
+
+
+@dataclass(frozen=True)
+class AnalysisOverrides:
+    prompt_instructions: str = ""
+    disabled_rules: frozenset[str] = frozenset()
+    rule_severities: dict[str, str] = field(default_factory=dict)
+
+
+_lock = threading.Lock()
+_path = ""
+_overrides = AnalysisOverrides()
+_mtime: float | None = None
+_loaded_at: datetime | None = None
+_last_error: str | None = None
+
+
+def init_analysis_overrides(path: str) -> None:
+    """Configure the overrides file and load it once; an empty path disables overrides."""
+    global _path, _overrides, _mtime, _loaded_at, _last_error  # noqa: PLW0603
+    with _lock:
+        _path = path.strip()
+        _overrides = AnalysisOverrides()
+        _mtime = None
+        _loaded_at = None
+        _last_error = None
+    reload_analysis_overrides()
+
+
+def current_overrides() -> AnalysisOverrides:
+    return _overrides
+
+
+def reload_analysis_overrides() -> bool:
+    """Re-read the file when it changed; return True when new overrides were applied."""
+    global _overrides, _mtime, _loaded_at, _last_error  # noqa: PLW0603
+    if not _path:
+        return False
+    with _lock:
+        try:
+            mtime = os.stat(_path).st_mtime
+        except OSError as exc:
+            _last_error = f"cannot stat {_path}: {exc.strerror or exc}"
+            return False
+        if mtime == _mtime:
+            return False
+        _mtime = mtime
+        try:
+            with open(_path, encoding="utf-8") as handle:
+                overrides = parse_analysis_overrides(json.load(handle))
+        except (OSError, ValueError) as exc:
+            _last_error = str(exc)
+            logger.warning("Keeping previous analysis overrides; %s is invalid: %s", _path, exc)
+            return False
+        _overrides = overrides
+        _loaded_at = datetime.now(timezone.utc)
+        _last_error = None
+    logger.info("Analysis overrides reloaded from %s", _path)
+    return True
+
+
+def overrides_status() -> dict[str, object]:
+    overrides = _overrides
+    return {
+        "path": _path or None,
+        "loaded_at": _loaded_at.isoformat() if _loaded_at else None,
+        "last_error": _last_error,
+        "prompt_instructions": bool(overrides.prompt_instructions),
+        "disabled_rules": sorted(overrides.disabled_rules),
+        "rule_severities": dict(overrides.rule_severities),
+    }
+
+
+def parse_analysis_overrides(payload: object) -> AnalysisOverrides:
+    if not isinstance(payload, dict):
+        raise ValueError("overrides must be a JSON object")
+    unknown = sorted(set(payload) - _KNOWN_KEYS)
+    if unknown:
+        raise ValueError(f"unknown override keys: {', '.join(unknown)}")
+
+    instructions = payload.get("prompt_instructions", "")
+    if not isinstance(instructions, str):
+        raise ValueError("prompt_instructions must be a string")
+    disabled = payload.get("disabled_rules", [])
+    if not isinstance(disabled, list) or not all(isinstance(item, str) for item in disabled):
+        raise ValueError("disabled_rules must be an array of rule names")
+    severities = payload.get("rule_severities", {})
+    if not isinstance(severities, dict):
+        raise ValueError("rule_severities must be an object")
+    for rule, severity in severities.items():
+        if severity not in _SEVERITIES:
+            raise ValueError(f"rule_severities.{rule} must be one of {sorted(_SEVERITIES)}")
+    return AnalysisOverrides(
+        prompt_instructions=instructions.strip(),
+        disabled_rules=frozenset(disabled),
+        rule_severities=dict(severities),
+    )
+
+
+async def watch_analysis_overrides(interval_seconds: int) -> None:
+    """Poll the overrides file every *interval_seconds* until cancelled."""
+    while True:
+        await asyncio.sleep(interval_seconds)
+        await asyncio.to_thread(reload_analysis_overrides)
@@ -0,0 +1,38 @@
+from __future__ import annotations
+
+import logging
+import os
+import tempfile
+from pathlib import Path
+
+logger = logging.getLogger(__name__)
+
+
+def configure_data_dir(data_dir: str) -> Path | None:
+    """Route every runtime write under *data_dir*.
+
+    The agent itself keeps no files, but libraries do: the kubernetes client
+    writes kubeconfig certificates to temp files and SDKs cache under
+    ``XDG_CACHE_HOME`` (``~/.cache`` by default). ``TMPDIR`` and ``tempfile``
+    are pointed at ``<data_dir>/tmp`` and, unless already set,
+    ``XDG_CACHE_HOME`` at ``<data_dir>/cache``, so the container can run with
+    ``readOnlyRootFilesystem`` and an emptyDir mount. ``HOME`` is left alone;
+    tools that write elsewhere under it need their own writable mount.
+    """
+    if not data_dir:
+        return None
+    root = Path(data_dir)
+    tmp_dir = root / "tmp"
+    cache_dir = root / "cache"
+    try:
+        tmp_dir.mkdir(parents=True, exist_ok=True)
+        cache_dir.mkdir(parents=True, exist_ok=True)
+    except OSError as exc:
+        logger.warning("DATA_DIR %s is not writable, keeping default paths: %s", root, exc)
+        return None
+
+    os.environ["TMPDIR"] = str(tmp_dir)
+    os.environ.setdefault("XDG_CACHE_HOME", str(cache_dir))
+    tempfile.tempdir = str(tmp_dir)
+    logger.info("Runtime data directory: %s", root)
+    return root
@@ -0,0 +1,202 @@
+"""Sampled logging of full request and callback payloads to a dedicated sink.
+
+With ``PAYLOAD_LOG_SAMPLE_RATE`` above 0, that share of HTTP requests (with
+their responses) and of report webhook callbacks is written as one JSON line
+each to ``PAYLOAD_LOG_FILE`` (stderr when empty) through the
+``kube_rca.payloads`` logger, which does not propagate to the application
+log. Payloads pass the built-in redaction and the configured masking first,
+and are cut at ``PAYLOAD_LOG_MAX_BYTES``.
+"""
+
+from __future__ import annotations
+
+import json
+import logging
+import logging.handlers
+import random
+import re
+import sys
+import time
+from typing import Any
+
+from app.core.compression import ASGIApp, Message, Receive, Scope, Send
+from app.core.masking import BuiltinRedactor, Masker, RegexMasker
+
+logger = logging.getLogger(__name__)
+
+PAYLOAD_LOGGER_NAME = "kube_rca.payloads"
+
+_SKIPPED_PATHS = frozenset({"/", "/healthz", "/readyz", "/ping", "/metrics", "/openapi.json"})
+# Secret-looking JSON fields of payloads that were cut and cannot be parsed.
+_JSON_SECRET_FIELD_RE = re.compile(
+    r'("[\w-]*(?:token|secret|password|authorization|api_?key|credential)[\w-]*"\s*:\s*)'
+    r'"[^"]*"?',
+    re.IGNORECASE,
+)
+_REDACTED = '"<redacted>"'
+
+
+class PayloadLogger:
+    def __init__(
+        self,
+        sample_rate: float,
+        *,
+        max_bytes: int,
+        masker: Masker | None = None,
+        sink: logging.Logger | None = None,
+    ) -> None:
+        self._sample_rate = min(max(sample_rate, 0.0), 1.0)
+        self._max_bytes = max(1, max_bytes)
+        self._masker = masker or RegexMasker()
+        self._redactor = BuiltinRedactor()
+        self._sink = sink or logging.getLogger(PAYLOAD_LOGGER_NAME)
+
+    def sampled(self) -> bool:
+        return random.random() < self._sample_rate
+
+    def body(self) -> _BoundedBody:
+        return _BoundedBody(self._max_bytes)
+
+    def mask_text(self, text: str) -> str:
+        return self._masker.mask_text(self._redactor.redact_text(text))
+
+    def record(self, kind: str, payloads: dict[str, _BoundedBody], **fields: object) -> None:
+        """Write one sampled exchange; *payloads* are the bodies by role (request, response)."""
+        entry: dict[str, object] = {"kind": kind, **fields}
+        entry.update({role: self._payload(body) for role, body in payloads.items()})
+        self._sink.info(json.dumps(entry, ensure_ascii=False, default=str))
+
+    def _payload(self, body: _BoundedBody) -> dict[str, object]:
+        truncated = body.size > self._max_bytes
+        text = body.value()[: self._max_bytes].decode("utf-8", errors="replace")
+        content: Any
+        try:
+            content = self._mask(json.loads(text)) if text and not truncated else None
+        except ValueError:
+            content = None
+        if content is None and text:
+            content = self.mask_text(_JSON_SECRET_FIELD_RE.sub(rf"\1{_REDACTED}", text))
+        return {"bytes": body.size, "truncated": truncated, "body": content}
+
+    def _mask(self, value: object) -> object:
+        return self._masker.mask_object(self._redactor.redact_object(value))
+
+
+_payload_logger: PayloadLogger | None = None
+
+
+def init_payload_logging(
+    sample_rate: float, *, path: str = "", max_bytes: int = 65536, masker: Masker | None = None
+) -> None:
+    """Configure the process-wide payload logger (disabled when *sample_rate* is 0)."""
+    global _payload_logger  # noqa: PLW0603
+    sink = logging.getLogger(PAYLOAD_LOGGER_NAME)
+    for handler in list(sink.handlers):
+        sink.removeHandler(handler)
+        handler.close()
+    if sample_rate <= 0:
+        _payload_logger = None
+        return
+    handler: logging.Handler = (
+        logging.handlers.WatchedFileHandler(path, encoding="utf-8")
+        if path
+        else logging.StreamHandler(sys.stderr)
+    )
+    handler.setFormatter(logging.Formatter("%(message)s"))
+    sink.addHandler(handler)
+    sink.setLevel(logging.INFO)
+    sink.propagate = False
+    _payload_logger = PayloadLogger(sample_rate, max_bytes=max_bytes, masker=masker, sink=sink)
+    logger.info(
+        "Payload logging enabled (sample_rate=%.3f, sink=%s, max_bytes=%d)",
+        min(sample_rate, 1.0),
+        path or "stderr",
+        max_bytes,
+    )
+
+
+def log_callback_payload(report_type: str, body: bytes, result: dict[str, object]) -> None:
+    """Sample a report webhook delivery. The URL is left out: it may carry a credential."""
+    payload_logger = _payload_logger
+    if payload_logger is None or not payload_logger.sampled():
+        return
+    request = payload_logger.body()
+    request.append(body)
+    payload_logger.record(
+        "callback",
+        {"request": request},
+        report_type=report_type,
+        delivered=result.get("delivered"),
+        status=result.get("status_code"),
+    )
+
+
+class PayloadLoggingMiddleware:
+    """Log sampled HTTP requests with their responses to the payload sink.
+
+    Added inside the gzip middlewares, so bodies are logged uncompressed.
+    """
+
+    def __init__(self, app: ASGIApp) -> None:
+        self._app = app
+
+    async def __call__(self, scope: Scope, receive: Receive, send: Send) -> None:
+        payload_logger = _payload_logger
+        if (
+            payload_logger is None
+            or scope["type"] != "http"
+            or scope.get("path") in _SKIPPED_PATHS
+            or not payload_logger.sampled()
+        ):
+            await self._app(scope, receive, send)
+            return
+
+        request = payload_logger.body()
+        response = payload_logger.body()
+        status: list[int] = []
+
+        async def receive_logged() -> Message:
+            message = await receive()
+            if message["type"] == "http.request":
+                request.append(message.get("body", b""))
+            return message
+
+        async def send_logged(message: Message) -> None:
+            if message["type"] == "http.response.start":
+                status.append(int(message["status"]))
+            elif message["type"] == "http.response.body":
+                response.append(message.get("body", b""))
+            await send(message)
+
+        started = time.perf_counter()
+        try:
+            await self._app(scope, receive_logged, send_logged)
+        finally:
+            query = scope.get("query_string", b"").decode("latin-1")
+            payload_logger.record(
+                "http",
+                {"request": request, "response": response},
+                method=scope.get("method"),
+                path=scope.get("path"),
+                query=payload_logger.mask_text(query) if query else None,
+                status=status[0] if status else None,
+                duration_ms=round((time.perf_counter() - started) * 1000, 1),
+            )
+
+
+class _BoundedBody:
+    # Keeps one byte past the limit, so the payload is known to be cut.
+    def __init__(self, max_bytes: int) -> None:
+        self._limit = max_bytes + 1
+        self._chunks: list[bytes] = []
+        self._kept = 0
+        self.size = 0
+
+    def append(self, chunk: bytes) -> None:
+        self.size += len(chunk)
+        if self._kept < self._limit:
+            self._chunks.append(chunk[: self._limit - self._kept])
+            self._kept += len(self._chunks[-1])
+
+    def value(self) -> bytes:
+        return b"".join(self._chunks)
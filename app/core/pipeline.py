@@ -0,0 +1,134 @@
+"""Analysis pipeline stages declared per routing profile.
+
+``ANALYSIS_PIPELINES_JSON`` maps a profile key to the ordered stages an alert
+analysis runs. Keys match like latency SLOs: the alertname first, then
+``severity:<severity>``, then ``default``::
+
+    {
+      "default": ["collect", "correlate", "rules", "llm", "format", "deliver"],
+      "severity:info": ["collect", "rules", "format", "deliver"],
+      "Watchdog": ["collect", "format"]
+    }
+
+Stages:
+
+- ``collect``: pod, workload, node, metrics, volume, HPA and trace context. Required, first.
+- ``correlate``: recent rollouts, cloud provider incidents and node maintenance.
+- ``rules``: the rule-based analyzers, which back up an LLM failure (degraded result).
+- ``llm``: the LLM analysis (and parallel hypotheses). Skipped, the result is rule-based.
+- ``format``: summary/detail split and evidence artifacts. Skipped, the analysis is
+  returned unsplit as the summary.
+- ``deliver``: summary history, analysis store, incident closure, result routing and archive.
+
+Omitted stages are skipped. Order matters where a later stage consumes an
+earlier one: ``correlate`` or ``rules`` before ``llm`` put their results in the
+prompt; ``correlate`` after ``llm`` only enriches the returned context (and no
+longer short-circuits planned node maintenance). ``format`` must follow
+``llm`` and ``rules``, and ``deliver`` comes last. Alerts matching no profile
+run ``DEFAULT_STAGES``, where rules follow the LLM as a fallback only.
+"""
+
+from __future__ import annotations
+
+import json
+from collections.abc import Mapping
+from dataclasses import dataclass
+
+STAGE_COLLECT = "collect"
+STAGE_CORRELATE = "correlate"
+STAGE_RULES = "rules"
+STAGE_LLM = "llm"
+STAGE_FORMAT = "format"
+STAGE_DELIVER = "deliver"
+STAGES = (STAGE_COLLECT, STAGE_CORRELATE, STAGE_RULES, STAGE_LLM, STAGE_FORMAT, STAGE_DELIVER)
+# Without a definition, rules only back the LLM up (degraded mode), as before profiles existed.
+DEFAULT_STAGES = (
+    STAGE_COLLECT,
+    STAGE_CORRELATE,
+    STAGE_LLM,
+    STAGE_RULES,
+    STAGE_FORMAT,
+    STAGE_DELIVER,
+)
+DEFAULT_PROFILE = "default"
+_SEVERITY_KEY_PREFIX = "severity:"
+
+
+@dataclass(frozen=True)
+class AnalysisPipeline:
+    profile: str
+    stages: tuple[str, ...]
+
+    def runs(self, stage: str) -> bool:
+        return stage in self.stages
+
+    def runs_before(self, stage: str, other: str) -> bool:
+        """True when *stage* runs and *other* is skipped or runs after it."""
+        if stage not in self.stages:
+            return False
+        return other not in self.stages or self.stages.index(stage) < self.stages.index(other)
+
+    def to_dict(self) -> dict[str, object]:
+        return {"profile": self.profile, "stages": list(self.stages)}
+
+
+DEFAULT_PIPELINE = AnalysisPipeline(DEFAULT_PROFILE, DEFAULT_STAGES)
+
+
+class PipelineRegistry:
+    """Stages per routing profile; alertname, then ``severity:<severity>``, then ``default``."""
+
+    def __init__(self, profiles: tuple[tuple[str, tuple[str, ...]], ...] = ()) -> None:
+        self._profiles = {key: AnalysisPipeline(key, stages) for key, stages in profiles}
+
+    def resolve(self, labels: Mapping[str, str]) -> AnalysisPipeline:
+        severity = (labels.get("severity") or "").strip().lower()
+        for key in (
+            (labels.get("alertname") or "").strip(),
+            f"{_SEVERITY_KEY_PREFIX}{severity}" if severity else "",
+            DEFAULT_PROFILE,
+        ):
+            if key and key in self._profiles:
+                return self._profiles[key]
+        return DEFAULT_PIPELINE
+
+
+def parse_pipeline_profiles(value: str, name: str) -> tuple[tuple[str, tuple[str, ...]], ...]:
+    value = value.strip()
+    if not value:
+        return ()
+
+    try:
+        parsed = json.loads(value)
+    except json.JSONDecodeError as exc:
+        raise ValueError(f"{name} must be a valid JSON object of stage lists") from exc
+
+    if not isinstance(parsed, dict):
+        raise ValueError(f"{name} must be a valid JSON object of stage lists")
+
+    profiles: list[tuple[str, tuple[str, ...]]] = []
+    for key, stages in parsed.items():
+        if not isinstance(stages, list) or not all(isinstance(stage, str) for stage in stages):
+            raise ValueError(f"{name}.{key} must be an array of stage names")
+        cleaned = tuple(stage.strip().lower() for stage in stages)
+        _validate_stages(cleaned, f"{name}.{key}")
+        if key.strip():
+            profiles.append((key.strip(), cleaned))
+    return tuple(profiles)
+
+
+def _validate_stages(stages: tuple[str, ...], name: str) -> None:
+    unknown = sorted(set(stages) - set(STAGES))
+    if unknown:
+        raise ValueError(f"{name} has unknown stages {unknown}; known stages: {list(STAGES)}")
+    if len(set(stages)) != len(stages):
+        raise ValueError(f"{name} lists a stage more than once")
+    if not stages or stages[0] != STAGE_COLLECT:
+        raise ValueError(f"{name} must start with {STAGE_COLLECT!r}")
+    position = {stage: index for index, stage in enumerate(stages)}
+    if STAGE_FORMAT in position:
+        for stage in (STAGE_RULES, STAGE_LLM):
+            if stage in position and position[stage] > position[STAGE_FORMAT]:
+                raise ValueError(f"{name}: {stage!r} must run before {STAGE_FORMAT!r}")
+    if STAGE_DELIVER in position and position[STAGE_DELIVER] != len(stages) - 1:
+        raise ValueError(f"{name}: {STAGE_DELIVER!r} must be the last stage")
@@ -0,0 +1,64 @@
+from __future__ import annotations
+
+import logging
+import os
+
+from app.core.config import Settings
+
+logger = logging.getLogger(__name__)
+
+
+def parse_profiling_tags(raw: str) -> dict[str, str]:
+    """Parse ``key=value,key2=value2`` into a tag dict, skipping malformed entries."""
+    tags: dict[str, str] = {}
+    for item in raw.split(","):
+        key, sep, value = item.partition("=")
+        key = key.strip()
+        value = value.strip()
+        if sep and key and value:
+            tags[key] = value
+    return tags
+
+
+def configure_profiling(settings: Settings) -> bool:
+    """Start the Pyroscope continuous profiler when a server address is configured.
+
+    The ``pyroscope-io`` package is optional (``pip install '.[profiling]'``);
+    profiling is skipped with a warning when it is not installed.
+    """
+    if not settings.pyroscope_server_address:
+        return False
+    try:
+        import pyroscope  # type: ignore[import-not-found]
+    except ImportError:
+        logger.warning(
+            "PYROSCOPE_SERVER_ADDRESS is set but pyroscope-io is not installed; "
+            "continuous profiling disabled"
+        )
+        return False
+
+    tags = parse_profiling_tags(settings.pyroscope_tags)
+    tags.setdefault("hostname", os.getenv("HOSTNAME", "unknown"))
+    options: dict[str, object] = {
+        "application_name": settings.pyroscope_application_name,
+        "server_address": settings.pyroscope_server_address,
+        "sample_rate": settings.pyroscope_sample_rate,
+        "tags": tags,
+    }
+    if settings.pyroscope_auth_token:
+        options["auth_token"] = settings.pyroscope_auth_token
+    if settings.pyroscope_tenant_id:
+        options["tenant_id"] = settings.pyroscope_tenant_id
+
+    try:
+        pyroscope.configure(**options)
+    except Exception as exc:  # noqa: BLE001
+        logger.warning("Failed to start Pyroscope profiler: %s", exc)
+        return False
+
+    logger.info(
+        "Continuous profiling enabled (server=%s, application=%s)",
+        settings.pyroscope_server_address,
+        settings.pyroscope_application_name,
+    )
+    return True
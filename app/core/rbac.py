@@ -0,0 +1,299 @@
+"""Kubernetes permissions of the enabled collectors and the least-privilege RBAC for them.
+
+``required_permissions`` lists what the current settings make the agent read;
+``KubernetesClient.check_permissions`` reviews each entry with a
+SelfSubjectAccessReview on startup and in ``GET /diagnostics``.
+``render_rbac_manifest`` turns the same list into the minimal ClusterRole
+(plus a Role per namespace that only one collector reads) and bindings for
+the agent's ServiceAccount.
+"""
+
+from __future__ import annotations
+
+from collections.abc import Iterable, Sequence
+from dataclasses import dataclass
+
+from app.core.config import Settings
+
+
+@dataclass(frozen=True)
+class Permission:
+    collector: str
+    verb: str
+    group: str
+    resource: str
+    subresource: str | None = None
+    cluster_scoped: bool = False
+    # Set when the collector reads a single namespace (granted by a Role there).
+    namespace: str | None = None
+    # Add-on resources, only needed when the add-on is installed.
+    optional: bool = False
+
+    @property
+    def rule_resource(self) -> str:
+        return f"{self.resource}/{self.subresource}" if self.subresource else self.resource
+
+
+def _grants(
+    collector: str,
+    group: str,
+    resources: dict[str, tuple[str, ...]],
+    *,
+    cluster_scoped: bool = False,
+    optional: bool = False,
+) -> tuple[Permission, ...]:
+    permissions: list[Permission] = []
+    for resource, verbs in resources.items():
+        name, _, subresource = resource.partition("/")
+        permissions.extend(
+            Permission(
+                collector,
+                verb,
+                group,
+                name,
+                subresource or None,
+                cluster_scoped=cluster_scoped,
+                optional=optional,
+            )
+            for verb in verbs
+        )
+    return tuple(permissions)
+
+
+# Context collection, the rule analyzers and the analysis engine's tools.
+ANALYSIS_PERMISSIONS: tuple[Permission, ...] = (
+    *_grants(
+        "analysis",
+        "",
+        {
+            "pods": ("get", "list"),
+            "pods/log": ("get",),
+            "events": ("list",),
+            "services": ("get", "list"),
+            "persistentvolumeclaims": ("get",),
+            "resourcequotas": ("list",),
+            "limitranges": ("list",),
+        },
+    ),
+    *_grants(
+        "analysis",
+        "apps",
+        {
+            "deployments": ("get", "list"),
+            "replicasets": ("get", "list"),
+            "statefulsets": ("get",),
+            "daemonsets": ("get",),
+        },
+    ),
+    *_grants("analysis", "batch", {"jobs": ("get", "list"), "cronjobs": ("get",)}),
+    *_grants("analysis", "autoscaling", {"horizontalpodautoscalers": ("list",)}),
+    *_grants("analysis", "networking.k8s.io", {"networkpolicies": ("list",)}),
+    *_grants("analysis", "discovery.k8s.io", {"endpointslices": ("list",)}),
+    *_grants("analysis", "metrics.k8s.io", {"pods": ("get",)}),
+    *_grants(
+        "analysis",
+        "",
+        {
+            "namespaces": ("get",),
+            "nodes": ("get", "list"),
+            "nodes/proxy": ("get",),
+            "persistentvolumes": ("get",),
+        },
+        cluster_scoped=True,
+    ),
+    *_grants("analysis", "metrics.k8s.io", {"nodes": ("get",)}, cluster_scoped=True),
+    *_grants(
+        "analysis",
+        "storage.k8s.io",
+        {"storageclasses": ("get",), "volumeattachments": ("list",)},
+        cluster_scoped=True,
+    ),
+    *_grants(
+        "analysis",
+        "apiextensions.k8s.io",
+        {"customresourcedefinitions": ("list",)},
+        cluster_scoped=True,
+    ),
+    *_grants(
+        "keda",
+        "keda.sh",
+        {"scaledobjects": ("list",), "triggerauthentications": ("get",)},
+        optional=True,
+    ),
+    *_grants(
+        "keda",
+        "keda.sh",
+        {"clustertriggerauthentications": ("get",)},
+        cluster_scoped=True,
+        optional=True,
+    ),
+    *_grants("keda", "external.metrics.k8s.io", {"*": ("list",)}, optional=True),
+    *_grants(
+        "knative",
+        "serving.knative.dev",
+        {"services": ("get",), "revisions": ("list",)},
+        optional=True,
+    ),
+    *_grants(
+        "knative", "autoscaling.internal.knative.dev", {"podautoscalers": ("get",)}, optional=True
+    ),
+    *_grants(
+        "knative",
+        "networking.internal.knative.dev",
+        {"serverlessservices": ("get",)},
+        optional=True,
+    ),
+    *_grants("argo_rollouts", "argoproj.io", {"rollouts": ("get",)}, optional=True),
+    *_grants(
+        "velero",
+        "velero.io",
+        {
+            "backups": ("get", "list"),
+            "restores": ("get", "list"),
+            "podvolumebackups": ("list",),
+            "podvolumerestores": ("list",),
+            "datauploads": ("list",),
+            "datadownloads": ("list",),
+        },
+        optional=True,
+    ),
+)
+
+
+def required_permissions(settings: Settings) -> list[Permission]:
+    """Permissions of the collectors *settings* enable, analysis permissions first."""
+    permissions = list(ANALYSIS_PERMISSIONS)
+    if settings.event_archive_enabled:
+        permissions.extend(
+            _grants("event_archive", "", {"events": ("list", "watch")}, cluster_scoped=True)
+        )
+    if settings.helm_release_scan_enabled:
+        permissions.extend(_grants("helm_releases", "", {"secrets": ("list",)}))
+    if settings.namespace_rca_config_enabled:
+        permissions.extend(_grants("rca_config", "kube-rca.io", {"rcaconfigs": ("list",)}))
+    if settings.namespace_snapshot_enabled:
+        permissions.extend(
+            _grants(
+                "namespace_snapshot",
+                "apps",
+                {"deployments": ("list",), "statefulsets": ("list",), "daemonsets": ("list",)},
+            )
+        )
+    for namespace in settings.health_scan_namespaces:
+        permissions.extend(
+            Permission(
+                "health_scan", verb, group, resource, namespace=namespace, optional=optional
+            )
+            for verb, group, resource, optional in (
+                ("list", "", "pods", False),
+                ("list", "", "resourcequotas", False),
+                ("list", "cert-manager.io", "certificates", True),
+            )
+        )
+    return permissions
+
+
+def render_rbac_manifest(
+    permissions: Sequence[Permission], *, service_account: str, namespace: str
+) -> str:
+    """Minimal ClusterRole/Role and binding YAML granting *permissions* to the ServiceAccount."""
+    cluster_rules = _rules(item for item in permissions if item.namespace is None)
+    granted = {
+        (item.group, item.rule_resource, item.verb)
+        for item in permissions
+        if item.namespace is None
+    }
+    collectors = ", ".join(dict.fromkeys(item.collector for item in permissions))
+    documents: list[str] = []
+    # Namespace-restricted access scopes need no ClusterRole at all.
+    if cluster_rules:
+        documents.append(_role_document("ClusterRole", service_account, None, cluster_rules))
+        documents.append(
+            _binding_document(
+                "ClusterRoleBinding",
+                "ClusterRole",
+                service_account,
+                None,
+                service_account,
+                namespace,
+            )
+        )
+    role_namespaces = dict.fromkeys(item.namespace for item in permissions if item.namespace)
+    for role_namespace in role_namespaces:
+        rules = _rules(
+            item
+            for item in permissions
+            if item.namespace == role_namespace
+            and (item.group, item.rule_resource, item.verb) not in granted
+        )
+        if not rules:
+            continue
+        documents.append(_role_document("Role", service_account, role_namespace, rules))
+        documents.append(
+            _binding_document(
+                "RoleBinding", "Role", service_account, role_namespace, service_account, namespace
+            )
+        )
+    header = f"# Least-privilege RBAC generated by kube-rca-agent for: {collectors}\n"
+    return header + "---\n".join(documents)
+
+
+def _rules(permissions: Iterable[Permission]) -> list[tuple[str, list[str], list[str]]]:
+    # Verbs per resource, then resources with the same verbs of a group in one rule.
+    verbs: dict[tuple[str, str], list[str]] = {}
+    for item in permissions:
+        resource_verbs = verbs.setdefault((item.group, item.rule_resource), [])
+        if item.verb not in resource_verbs:
+            resource_verbs.append(item.verb)
+    grouped: dict[tuple[str, tuple[str, ...]], list[str]] = {}
+    for (group, resource), resource_verbs in verbs.items():
+        grouped.setdefault((group, tuple(sorted(resource_verbs))), []).append(resource)
+    return [
+        (group, resources, list(rule_verbs)) for (group, rule_verbs), resources in grouped.items()
+    ]
+
+
+def _role_document(
+    kind: str, name: str, namespace: str | None, rules: list[tuple[str, list[str], list[str]]]
+) -> str:
+    lines = ["apiVersion: rbac.authorization.k8s.io/v1", f"kind: {kind}", "metadata:"]
+    lines.append(f"  name: {name}")
+    if namespace:
+        lines.append(f"  namespace: {namespace}")
+    lines.append("rules:")
+    for group, resources, verbs in rules:
+        lines.append(f"  - apiGroups: {_flow_list([group])}")
+        lines.append(f"    resources: {_flow_list(resources)}")
+        lines.append(f"    verbs: {_flow_list(verbs)}")
+    return "\n".join(lines) + "\n"
+
+
+def _binding_document(
+    kind: str,
+    role_kind: str,
+    name: str,
+    namespace: str | None,
+    service_account: str,
+    service_account_namespace: str,
+) -> str:
+    lines = ["apiVersion: rbac.authorization.k8s.io/v1", f"kind: {kind}", "metadata:"]
+    lines.append(f"  name: {name}")
+    if namespace:
+        lines.append(f"  namespace: {namespace}")
+    lines.extend(
+        [
+            "roleRef:",
+            "  apiGroup: rbac.authorization.k8s.io",
+            f"  kind: {role_kind}",
+            f"  name: {name}",
+            "subjects:",
+            "  - kind: ServiceAccount",
+            f"    name: {service_account}",
+            f"    namespace: {service_account_namespace}",
+        ]
+    )
+    return "\n".join(lines) + "\n"
+
+
+def _flow_list(values: list[str]) -> str:
+    return "[" + ", ".join(f'"{value}"' for value in values) + "]"
@@ -0,0 +1,187 @@
+"""Resolve secret settings from mounted files or HashiCorp Vault.
+
+For a secret setting ``NAME`` the value is resolved in this order:
+
+1. ``NAME_FILE``: path to a file holding the value (Kubernetes Secret volume,
+   Vault Agent injector or the Secrets Store CSI driver for AWS Secrets Manager).
+2. ``NAME=vault:<kv-v2 api path>#<key>``: read from Vault KV v2, for example
+   ``vault:secret/data/kube-rca#gemini_api_key``.
+3. ``NAME``: plain environment variable.
+"""
+
+from __future__ import annotations
+
+import asyncio
+import hashlib
+import json
+import logging
+import os
+import urllib.error
+import urllib.request
+from collections.abc import Callable, Iterable
+from pathlib import Path
+from threading import Lock
+
+logger = logging.getLogger(__name__)
+
+_VAULT_PREFIX = "vault:"
+_DEFAULT_SA_TOKEN_PATH = "/var/run/secrets/kubernetes.io/serviceaccount/token"
+_VAULT_TIMEOUT_SECONDS = 5
+
+_vault_token_lock = Lock()
+_vault_token: str | None = None
+
+
+def get_secret_env(name: str, default: str = "") -> str:
+    file_path = os.getenv(f"{name}_FILE", "").strip()
+    if file_path:
+        try:
+            return Path(file_path).read_text(encoding="utf-8").strip()
+        except OSError as exc:
+            raise ValueError(f"{name}_FILE could not be read: {file_path}") from exc
+
+    value = os.getenv(name, default)
+    if value.startswith(_VAULT_PREFIX):
+        return read_vault_secret(name, value[len(_VAULT_PREFIX) :])
+    return value
+
+
+def secret_fingerprint(names: Iterable[str]) -> str:
+    """Hash of the resolved values of the secrets *names*, used to detect rotation."""
+    digest = hashlib.sha256()
+    for name in names:
+        digest.update(name.encode("utf-8"))
+        digest.update(b"\0")
+        digest.update(get_secret_env(name).encode("utf-8"))
+        digest.update(b"\0")
+    return digest.hexdigest()
+
+
+def read_vault_secret(name: str, reference: str) -> str:
+    """Value at ``<path>#<key>`` in Vault; *name* labels the errors."""
+    path, sep, key = reference.partition("#")
+    path = path.strip().strip("/")
+    key = key.strip()
+    if not sep or not path or not key:
+        raise ValueError(f"{name} vault reference must look like vault:<path>#<key>")
+
+    vault_addr = os.getenv("VAULT_ADDR", "").strip().rstrip("/")
+    if not vault_addr:
+        raise ValueError(f"{name} uses a vault reference but VAULT_ADDR is not set")
+
+    payload = _vault_request(f"{vault_addr}/v1/{path}", token=_get_vault_token(vault_addr))
+    data = payload.get("data")
+    # KV v2 nests values under data.data; KV v1 returns them directly under data.
+    if isinstance(data, dict) and isinstance(data.get("data"), dict):
+        data = data["data"]
+    if not isinstance(data, dict) or key not in data:
+        raise ValueError(f"{name}: key '{key}' not found at vault path '{path}'")
+    return str(data[key])
+
+
+def vault_api(name: str, path: str, body: dict[str, str]) -> dict[str, object]:
+    """POST *body* to ``/v1/<path>`` of Vault, authenticated like secret references."""
+    vault_addr = os.getenv("VAULT_ADDR", "").strip().rstrip("/")
+    if not vault_addr:
+        raise ValueError(f"{name} uses Vault but VAULT_ADDR is not set")
+    return _vault_request(
+        f"{vault_addr}/v1/{path.strip('/')}", token=_get_vault_token(vault_addr), body=body
+    )
+
+
+def _get_vault_token(vault_addr: str) -> str:
+    global _vault_token  # noqa: PLW0603
+    token_file = os.getenv("VAULT_TOKEN_FILE", "").strip()
+    if token_file:
+        try:
+            return Path(token_file).read_text(encoding="utf-8").strip()
+        except OSError as exc:
+            raise ValueError(f"VAULT_TOKEN_FILE could not be read: {token_file}") from exc
+    token = os.getenv("VAULT_TOKEN", "").strip()
+    if token:
+        return token
+
+    role = os.getenv("VAULT_K8S_ROLE", "").strip()
+    if not role:
+        raise ValueError("VAULT_TOKEN, VAULT_TOKEN_FILE or VAULT_K8S_ROLE is required")
+
+    with _vault_token_lock:
+        if _vault_token:
+            return _vault_token
+        mount = os.getenv("VAULT_K8S_AUTH_MOUNT", "kubernetes").strip().strip("/")
+        jwt_path = os.getenv("VAULT_K8S_TOKEN_PATH", _DEFAULT_SA_TOKEN_PATH)
+        try:
+            jwt = Path(jwt_path).read_text(encoding="utf-8").strip()
+        except OSError as exc:
+            raise ValueError(f"service account token could not be read: {jwt_path}") from exc
+        payload = _vault_request(
+            f"{vault_addr}/v1/auth/{mount}/login",
+            body={"role": role, "jwt": jwt},
+        )
+        auth = payload.get("auth")
+        client_token = auth.get("client_token") if isinstance(auth, dict) else None
+        if not isinstance(client_token, str) or not client_token:
+            raise ValueError("vault kubernetes login returned no client token")
+        _vault_token = client_token
+        return client_token
+
+
+def reset_vault_token() -> None:
+    global _vault_token  # noqa: PLW0603
+    with _vault_token_lock:
+        _vault_token = None
+
+
+def _vault_request(
+    url: str, *, token: str | None = None, body: dict[str, str] | None = None
+) -> dict[str, object]:
+    headers = {"Accept": "application/json"}
+    if token:
+        headers["X-Vault-Token"] = token
+    namespace = os.getenv("VAULT_NAMESPACE", "").strip()
+    if namespace:
+        headers["X-Vault-Namespace"] = namespace
+    data = None
+    if body is not None:
+        data = json.dumps(body).encode("utf-8")
+        headers["Content-Type"] = "application/json"
+
+    request = urllib.request.Request(url, data=data, headers=headers)
+    try:
+        with urllib.request.urlopen(request, timeout=_VAULT_TIMEOUT_SECONDS) as response:
+            payload = json.loads(response.read().decode("utf-8"))
+    except urllib.error.HTTPError as exc:
+        if exc.code == 403:
+            # Expired kubernetes-auth token: force a fresh login on the next read.
+            reset_vault_token()
+        raise ValueError(f"vault request failed with HTTP {exc.code}") from exc
+    except (OSError, json.JSONDecodeError) as exc:
+        raise ValueError(f"vault request failed: {exc}") from exc
+    if not isinstance(payload, dict):
+        raise ValueError("vault returned an unexpected payload")
+    return payload
+
+
+async def watch_secret_rotation(
+    interval_seconds: int, names: Iterable[str], on_change: Callable[[], None]
+) -> None:
+    """Poll secret sources and call *on_change* when any resolved value changes."""
+    names = tuple(names)
+    current = await asyncio.to_thread(_safe_fingerprint, names)
+    while True:
+        await asyncio.sleep(interval_seconds)
+        latest = await asyncio.to_thread(_safe_fingerprint, names)
+        if latest is None or latest == current:
+            continue
+        if current is not None:
+            logger.info("Secret rotation detected; reloading dependent clients")
+            on_change()
+        current = latest
+
+
+def _safe_fingerprint(names: tuple[str, ...]) -> str | None:
+    try:
+        return secret_fingerprint(names)
+    except ValueError as exc:
+        logger.warning("Failed to re-read secrets: %s", exc)
+        return None
@@ -0,0 +1,72 @@
+from __future__ import annotations
+
+import hashlib
+import hmac
+import json
+from collections.abc import Mapping, Sequence
+from datetime import datetime, timezone
+
+SIGNATURE_ALGORITHM = "hmac-sha256"
+_KEY_ID_LABEL = b"kube-rca analysis signing key id"
+
+
+def canonical_json(value: object) -> bytes:
+    """Serialize *value* deterministically so equal records hash identically."""
+    return json.dumps(value, sort_keys=True, separators=(",", ":"), ensure_ascii=False).encode(
+        "utf-8"
+    )
+
+
+def key_id_for(key: str) -> str:
+    """Public ID of *key*: an HMAC of a fixed label, so it reveals no hash of the key."""
+    return hmac.new(key.encode("utf-8"), _KEY_ID_LABEL, hashlib.sha256).hexdigest()[:12]
+
+
+class RecordSigner:
+    """Sign analysis records with HMAC-SHA256 so they can be shown to be unmodified.
+
+    The first key signs; every key verifies, so keys can be rotated by
+    prepending a new key while older records stay verifiable.
+    """
+
+    def __init__(self, keys: Sequence[str]) -> None:
+        if not keys:
+            raise ValueError("ANALYSIS_SIGNING_KEYS must contain at least one key")
+        self._keys = {key_id_for(key): key.encode("utf-8") for key in reversed(keys)}
+        self._signing_key_id = key_id_for(keys[0])
+
+    def sign(self, record: Mapping[str, object]) -> dict[str, str]:
+        signed_at = datetime.now(timezone.utc).isoformat()
+        digest = hashlib.sha256(canonical_json(record)).hexdigest()
+        return {
+            "algorithm": SIGNATURE_ALGORITHM,
+            "key_id": self._signing_key_id,
+            "signed_at": signed_at,
+            "digest": digest,
+            "signature": self._mac(self._signing_key_id, digest, signed_at),
+        }
+
+    def verify(self, record: Mapping[str, object], signature: Mapping[str, object]) -> bool:
+        if signature.get("algorithm") != SIGNATURE_ALGORITHM:
+            return False
+        key_id = signature.get("key_id")
+        signed_at = signature.get("signed_at")
+        expected = signature.get("signature")
+        if not isinstance(key_id, str) or key_id not in self._keys:
+            return False
+        if not isinstance(signed_at, str) or not isinstance(expected, str):
+            return False
+        digest = hashlib.sha256(canonical_json(record)).hexdigest()
+        if not hmac.compare_digest(digest, str(signature.get("digest", ""))):
+            return False
+        return hmac.compare_digest(self._mac(key_id, digest, signed_at), expected)
+
+    def _mac(self, key_id: str, digest: str, signed_at: str) -> str:
+        message = f"{SIGNATURE_ALGORITHM}\n{key_id}\n{signed_at}\n{digest}".encode()
+        return hmac.new(self._keys[key_id], message, hashlib.sha256).hexdigest()
+
+
+def build_record_signer(keys: Sequence[str]) -> RecordSigner | None:
+    if not keys:
+        return None
+    return RecordSigner(keys)
@@ -0,0 +1,42 @@
+"""Verify Slack's request signature (``X-Slack-Signature``).
+
+Slack signs ``v0:<X-Slack-Request-Timestamp>:<raw body>`` with the app's
+signing secret (HMAC-SHA256, sent as ``v0=<hex>``). Requests older than the
+replay window are rejected, so a captured request cannot be sent again later.
+"""
+
+from __future__ import annotations
+
+import hashlib
+import hmac
+import time
+
+from app.core.auth import AuthenticationError
+
+_VERSION = "v0"
+# Slack's recommended tolerance for the request timestamp.
+REPLAY_WINDOW_SECONDS = 300
+
+
+def verify_slack_signature(
+    secret: str,
+    *,
+    timestamp: str,
+    body: bytes,
+    signature: str,
+    now: float | None = None,
+) -> None:
+    """Raise ``AuthenticationError`` unless *signature* signs *body* at *timestamp*."""
+    if not timestamp or not signature:
+        raise AuthenticationError("Slack request signature required")
+    try:
+        sent_at = int(timestamp)
+    except ValueError as exc:
+        raise AuthenticationError("invalid Slack request timestamp") from exc
+    current = time.time() if now is None else now
+    if abs(current - sent_at) > REPLAY_WINDOW_SECONDS:
+        raise AuthenticationError("Slack request timestamp is outside the replay window")
+    base = f"{_VERSION}:{timestamp}:".encode() + body
+    expected = hmac.new(secret.encode("utf-8"), base, hashlib.sha256).hexdigest()
+    if not hmac.compare_digest(f"{_VERSION}={expected}", signature):
+        raise AuthenticationError("invalid Slack request signature")
@@ -0,0 +1,105 @@
+from __future__ import annotations
+
+import threading
+from collections.abc import Mapping
+from dataclasses import dataclass
+
+SLO_DEFAULT_KEY = "default"
+_SEVERITY_KEY_PREFIX = "severity:"
+OUTCOME_MET = "met"
+OUTCOME_MISSED = "missed"
+OUTCOME_TIME_BOXED = "time_boxed"
+_OUTCOMES = (OUTCOME_MET, OUTCOME_MISSED, OUTCOME_TIME_BOXED)
+
+
+@dataclass(frozen=True)
+class LatencySlo:
+    key: str
+    target_seconds: float
+
+
+@dataclass
+class _SloStats:
+    outcomes: dict[str, int]
+    duration_sum: float = 0.0
+
+
+class LatencySloTracker:
+    """Per-alert-type analysis duration targets and their compliance counters.
+
+    Targets are keyed by alertname, then ``severity:<severity>``, then
+    ``default``; the first match applies. Counters are exported from
+    ``/metrics`` in the Prometheus text format.
+    """
+
+    def __init__(self, targets: Mapping[str, float] | tuple[tuple[str, float], ...] = ()) -> None:
+        items = targets.items() if isinstance(targets, Mapping) else targets
+        self._targets = {key: float(seconds) for key, seconds in items if seconds > 0}
+        self._stats: dict[str, _SloStats] = {}
+        self._lock = threading.Lock()
+
+    @property
+    def enabled(self) -> bool:
+        return bool(self._targets)
+
+    def resolve(self, labels: Mapping[str, str]) -> LatencySlo | None:
+        severity = (labels.get("severity") or "").strip().lower()
+        for key in (
+            (labels.get("alertname") or "").strip(),
+            f"{_SEVERITY_KEY_PREFIX}{severity}" if severity else "",
+            SLO_DEFAULT_KEY,
+        ):
+            if key and key in self._targets:
+                return LatencySlo(key=key, target_seconds=self._targets[key])
+        return None
+
+    def observe(self, slo: LatencySlo, duration_seconds: float, *, time_boxed: bool) -> str:
+        if time_boxed:
+            outcome = OUTCOME_TIME_BOXED
+        elif duration_seconds <= slo.target_seconds:
+            outcome = OUTCOME_MET
+        else:
+            outcome = OUTCOME_MISSED
+        with self._lock:
+            stats = self._stats.setdefault(slo.key, _SloStats(dict.fromkeys(_OUTCOMES, 0)))
+            stats.outcomes[outcome] += 1
+            stats.duration_sum += duration_seconds
+        return outcome
+
+    def render_prometheus(self) -> str:
+        with self._lock:
+            stats = {
+                key: (dict(value.outcomes), value.duration_sum)
+                for key, value in self._stats.items()
+            }
+        lines = [
+            "# HELP kube_rca_analysis_slo_target_seconds Target analysis duration per SLO.",
+            "# TYPE kube_rca_analysis_slo_target_seconds gauge",
+        ]
+        for key, seconds in sorted(self._targets.items()):
+            lines.append(f'kube_rca_analysis_slo_target_seconds{{slo="{_escape(key)}"}} {seconds}')
+        lines += [
+            "# HELP kube_rca_analysis_slo_total Analyses per SLO and outcome "
+            "(met, missed, time_boxed).",
+            "# TYPE kube_rca_analysis_slo_total counter",
+        ]
+        for key, (outcomes, _) in sorted(stats.items()):
+            for outcome in _OUTCOMES:
+                lines.append(
+                    f'kube_rca_analysis_slo_total{{slo="{_escape(key)}",outcome="{outcome}"}} '
+                    f"{outcomes[outcome]}"
+                )
+        lines += [
+            "# HELP kube_rca_analysis_slo_duration_seconds_sum Total analysis duration per SLO.",
+            "# TYPE kube_rca_analysis_slo_duration_seconds_sum counter",
+        ]
+        for key, (_, duration_sum) in sorted(stats.items()):
+            lines.append(
+                f'kube_rca_analysis_slo_duration_seconds_sum{{slo="{_escape(key)}"}} '
+                f"{round(duration_sum, 3)}"
+            )
+        return "\n".join(lines) + "\n"
+
+
+def _escape(value: str) -> str:
+    return value.replace("\\", "\\\\").replace('"', '\\"').replace("\n", "\\n")
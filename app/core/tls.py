@@ -0,0 +1,97 @@
+"""Client certificates for mTLS to data sources (e.g. SPIFFE X.509-SVIDs).
+
+With SPIRE, run ``spiffe-helper`` as a sidecar that writes the SVID, its key
+and the trust bundle to a shared volume. The files are re-read whenever they
+change, so short-lived SVIDs are picked up without restarting the agent.
+
+The certificate and the CA bundle are only used for the configured internal
+hosts; public endpoints (GitHub, status pages, registries, ...) are verified
+against the system CAs and never see the agent's identity.
+"""
+
+from __future__ import annotations
+
+import logging
+import os
+import ssl
+import urllib.parse
+import urllib.request
+from collections.abc import Iterable
+from threading import Lock
+from typing import Any
+
+from app.core.deadline import bounded_timeout
+
+logger = logging.getLogger(__name__)
+
+_lock = Lock()
+_files: tuple[str, str, str] | None = None
+_hosts: tuple[str, ...] = ()
+_context: ssl.SSLContext | None = None
+_context_mtimes: tuple[float, ...] = ()
+
+
+def init_client_tls(
+    cert_file: str = "", key_file: str = "", ca_file: str = "", *, hosts: Iterable[str] = ()
+) -> None:
+    """Configure the client certificate presented to *hosts* (disabled without ``cert_file``).
+
+    A host entry matches exactly, or every subdomain when it starts with ``.``.
+    """
+    global _files, _hosts, _context, _context_mtimes  # noqa: PLW0603
+    with _lock:
+        _context = None
+        _context_mtimes = ()
+        if not cert_file:
+            _files = None
+            _hosts = ()
+            return
+        _files = (cert_file, key_file or cert_file, ca_file)
+        _hosts = tuple(host.strip().lower() for host in hosts if host.strip())
+    # Fail fast on unreadable or mismatched files.
+    client_ssl_context()
+    logger.info(
+        "Client mTLS enabled (cert=%s, ca=%s, hosts=%s)",
+        cert_file,
+        ca_file or "system",
+        ",".join(_hosts) or "none",
+    )
+
+
+def client_ssl_context() -> ssl.SSLContext | None:
+    global _context, _context_mtimes  # noqa: PLW0603
+    with _lock:
+        if _files is None:
+            return None
+        cert_file, key_file, ca_file = _files
+        mtimes = tuple(os.stat(path).st_mtime for path in _files if path)
+        if _context is None or mtimes != _context_mtimes:
+            context = ssl.create_default_context(cafile=ca_file or None)
+            context.minimum_version = ssl.TLSVersion.TLSv1_2
+            context.load_cert_chain(certfile=cert_file, keyfile=key_file)
+            if _context is not None:
+                logger.info("Client certificate rotated; reloaded %s", cert_file)
+            _context = context
+            _context_mtimes = mtimes
+        return _context
+
+
+def client_ssl_context_for(host: str | None) -> ssl.SSLContext | None:
+    """Client certificate context for an internal *host*, ``None`` for any other host."""
+    host = (host or "").lower().rstrip(".")
+    if not host or not any(
+        host == entry or (entry.startswith(".") and host.endswith(entry)) for entry in _hosts
+    ):
+        return None
+    return client_ssl_context()
+
+
+def open_url(request: urllib.request.Request | str, timeout: float) -> Any:
+    """``urllib.request.urlopen`` that presents the client certificate to internal hosts."""
+    url = request.full_url if isinstance(request, urllib.request.Request) else request
+    # Under a latency SLO no request may outlive the analysis deadline.
+    timeout = bounded_timeout(timeout)
+    context = client_ssl_context_for(urllib.parse.urlsplit(url).hostname)
+    if context is None:
+        return urllib.request.urlopen(request, timeout=timeout)
+    return urllib.request.urlopen(request, timeout=timeout, context=context)
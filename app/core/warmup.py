@@ -0,0 +1,85 @@
+"""Cold-start prewarming that gates ``GET /readyz``.
+
+The first analysis after a start otherwise pays for building every client,
+loading the kubeconfig and opening the API server connection, importing the
+LLM provider SDK and the first prompt render. :func:`run_warmup` does that
+work in the background while ``/healthz`` already answers, and readiness
+flips true once it finished (or ``PREWARM_TIMEOUT_SECONDS`` passed) so
+Kubernetes only routes alerts to a warm pod. A failing step is logged and
+reported but does not keep the pod unready.
+"""
+
+from __future__ import annotations
+
+import asyncio
+import logging
+import time
+from collections.abc import Callable
+from dataclasses import dataclass
+
+logger = logging.getLogger(__name__)
+
+_ready = True
+_steps: list[dict[str, object]] = []
+_duration_ms: int | None = None
+
+
+@dataclass(frozen=True)
+class WarmupStep:
+    name: str
+    run: Callable[[], object]
+
+
+def init_warmup(enabled: bool) -> None:
+    """Reset the readiness state; readiness starts false only when prewarming runs."""
+    global _ready, _steps, _duration_ms  # noqa: PLW0603
+    _ready = not enabled
+    _steps = []
+    _duration_ms = None
+
+
+def readiness() -> tuple[bool, dict[str, object]]:
+    state: dict[str, object] = {
+        "status": "ready" if _ready else "warming",
+        "steps": list(_steps),
+    }
+    if _duration_ms is not None:
+        state["duration_ms"] = _duration_ms
+    return _ready, state
+
+
+async def run_warmup(steps: list[WarmupStep], *, timeout_seconds: float) -> None:
+    """Run *steps* in order in a worker thread, then mark the service ready.
+
+    Steps still pending when *timeout_seconds* runs out are skipped; a step
+    that is cut off keeps running in its thread.
+    """
+    global _ready, _duration_ms  # noqa: PLW0603
+    started = time.perf_counter()
+    deadline = started + timeout_seconds
+    try:
+        for step in steps:
+            remaining = deadline - time.perf_counter()
+            if remaining <= 0:
+                _steps.append({"name": step.name, "status": "skipped"})
+                continue
+            step_started = time.perf_counter()
+            record: dict[str, object] = {"name": step.name, "status": "ok"}
+            try:
+                await asyncio.wait_for(asyncio.to_thread(step.run), timeout=remaining)
+            except asyncio.TimeoutError:
+                record["status"] = "timeout"
+            except Exception as exc:  # noqa: BLE001
+                logger.warning("Prewarm step %s failed: %s", step.name, exc)
+                record["status"] = "failed"
+                record["detail"] = str(exc)
+            record["duration_ms"] = int((time.perf_counter() - step_started) * 1000)
+            _steps.append(record)
+    finally:
+        _duration_ms = int((time.perf_counter() - started) * 1000)
+        _ready = True
+        logger.info(
+            "Prewarm finished in %d ms (%s)",
+            _duration_ms,
+            ", ".join(f"{step['name']}={step['status']}" for step in _steps) or "no steps",
+        )
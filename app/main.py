@@ -1,23 +1,115 @@
 from __future__ import annotations
 
+import asyncio
 import logging
-from contextlib import asynccontextmanager
+from contextlib import asynccontextmanager, suppress
 
 from fastapi import FastAPI
+from fastapi.middleware.gzip import GZipMiddleware
 
-from app.api import analysis, chat, config, health
+from app.api import (
+    analysis,
+    backfill,
+    canary,
+    chat,
+    config,
+    diagnostics,
+    digest,
+    health,
+    health_scan,
+    investigation,
+    metrics,
+    oom_forecast,
+    retention,
+    shadow,
+    slack,
+    suppressions,
+    ui,
+)
+from app.core.chaos import init_fault_injection
+from app.core.compression import GzipRequestMiddleware
 from app.core.concurrency import init_concurrency
-from app.core.dependencies import get_settings
+from app.core.config import SECRET_SETTING_NAMES
+from app.core.dependencies import (
+    build_warmup_steps,
+    get_alert_storm_guard,
+    get_diagnostics_service,
+    get_digest_service,
+    get_event_archiver,
+    get_health_scan_service,
+    get_masker,
+    get_memory_monitor,
+    get_oom_forecast_service,
+    get_retention_service,
+    get_settings,
+    reset_secret_dependencies,
+)
+from app.core.egress import init_egress_policy
+from app.core.fips import enforce_fips_mode
+from app.core.k8s_clusters import init_clusters
+from app.core.k8s_credentials import init_credential_passthrough
+from app.core.k8s_scope import init_access_scope
 from app.core.logging import configure_logging
+from app.core.overrides import init_analysis_overrides, watch_analysis_overrides
+from app.core.paths import configure_data_dir
+from app.core.payload_log import PayloadLoggingMiddleware, init_payload_logging
+from app.core.profiling import configure_profiling
+from app.core.secret_sources import watch_secret_rotation
+from app.core.tls import init_client_tls
+from app.core.warmup import init_warmup, run_warmup
+from app.services.diagnostics import run_startup_rbac_check
+from app.services.digest import run_digest_scheduler
+from app.services.event_archive import run_event_archiver
+from app.services.health_scan import run_health_scan_scheduler
+from app.services.oom_forecast import run_oom_forecast_scheduler
+from app.services.retention import run_retention_janitor
+from app.services.storm import run_alert_storm_monitor
 
 settings = get_settings()
 configure_logging(settings.log_level)
 logger = logging.getLogger(__name__)
+configure_data_dir(settings.data_dir)
 
 
 @asynccontextmanager
 async def lifespan(app: FastAPI):
-    init_concurrency(settings.max_concurrent_analyses)
+    enforce_fips_mode(settings.fips_mode)
+    # Chaos mode must be configured before clients are constructed below.
+    init_fault_injection(
+        settings.chaos_enabled,
+        settings.chaos_targets,
+        error_rate=settings.chaos_error_rate,
+        latency_ms=settings.chaos_latency_ms,
+        latency_jitter_ms=settings.chaos_latency_jitter_ms,
+    )
+    init_egress_policy(settings.egress_allowed_hosts)
+    init_access_scope(
+        settings.k8s_allowed_namespaces,
+        settings.k8s_allowed_api_groups,
+        settings.k8s_allowed_verbs,
+        cluster_scoped=settings.k8s_cluster_scoped_access,
+        least_privilege=settings.k8s_least_privilege,
+    )
+    init_credential_passthrough(
+        settings.k8s_credential_passthrough, vault_prefix=settings.k8s_credential_vault_prefix
+    )
+    init_clusters(settings.k8s_clusters, local_name=settings.k8s_cluster_name)
+    init_payload_logging(
+        settings.payload_log_sample_rate,
+        path=settings.payload_log_file,
+        max_bytes=settings.payload_log_max_bytes,
+        masker=get_masker(),
+    )
+    init_client_tls(
+        settings.client_tls_cert_file,
+        settings.client_tls_key_file,
+        settings.client_tls_ca_file,
+        hosts=settings.client_tls_hosts,
+    )
+    init_concurrency(settings.max_concurrent_analyses, memory_monitor=get_memory_monitor())
+    init_analysis_overrides(settings.analysis_overrides_file)
+    configure_profiling(settings)
+    init_warmup(settings.prewarm_enabled)
 
     # Eagerly initialize analysis engine and session schema
     # before any requests are served — avoids race condition
@@ -35,11 +127,111 @@ async def lifespan(app: FastAPI):
         settings.port,
         settings.max_concurrent_analyses,
     )
+
+    warmup_task: asyncio.Task[None] | None = None
+    if settings.prewarm_enabled:
+        warmup_task = asyncio.create_task(
+            run_warmup(build_warmup_steps(), timeout_seconds=settings.prewarm_timeout_seconds)
+        )
+
+    rotation_task: asyncio.Task[None] | None = None
+    if settings.secrets_refresh_interval_seconds > 0:
+        rotation_task = asyncio.create_task(
+            watch_secret_rotation(
+                settings.secrets_refresh_interval_seconds,
+                SECRET_SETTING_NAMES,
+                reset_secret_dependencies,
+            )
+        )
+
+    janitor_task: asyncio.Task[None] | None = None
+    retention_service = get_retention_service()
+    if retention_service.enabled:
+        janitor_task = asyncio.create_task(
+            run_retention_janitor(retention_service, settings.retention_janitor_interval_seconds)
+        )
+
+    health_scan_task: asyncio.Task[None] | None = None
+    health_scan_service = get_health_scan_service()
+    if health_scan_service.enabled:
+        health_scan_task = asyncio.create_task(
+            run_health_scan_scheduler(health_scan_service, settings.health_scan_interval_seconds)
+        )
+
+    oom_forecast_task: asyncio.Task[None] | None = None
+    oom_forecast_service = get_oom_forecast_service()
+    if oom_forecast_service is not None:
+        oom_forecast_task = asyncio.create_task(
+            run_oom_forecast_scheduler(
+                oom_forecast_service, settings.oom_forecast_interval_seconds
+            )
+        )
+
+    digest_task: asyncio.Task[None] | None = None
+    digest_service = get_digest_service()
+    if digest_service.enabled:
+        digest_task = asyncio.create_task(
+            run_digest_scheduler(digest_service, settings.digest_period_hours * 3600)
+        )
+
+    overrides_task: asyncio.Task[None] | None = None
+    if settings.analysis_overrides_file:
+        overrides_task = asyncio.create_task(
+            watch_analysis_overrides(settings.analysis_overrides_reload_seconds)
+        )
+
+    event_archive_task: asyncio.Task[None] | None = None
+    event_archiver = get_event_archiver()
+    if event_archiver is not None:
+        event_archive_task = asyncio.create_task(run_event_archiver(event_archiver))
+
+    rbac_check_task = asyncio.create_task(run_startup_rbac_check(get_diagnostics_service()))
+
+    storm_task: asyncio.Task[None] | None = None
+    storm_guard = get_alert_storm_guard()
+    if storm_guard is not None:
+        storm_task = asyncio.create_task(
+            run_alert_storm_monitor(storm_guard, settings.alert_storm_summary_interval_seconds)
+        )
     yield
+    for task in (
+        warmup_task,
+        rotation_task,
+        janitor_task,
+        health_scan_task,
+        oom_forecast_task,
+        digest_task,
+        overrides_task,
+        event_archive_task,
+        storm_task,
+        rbac_check_task,
+    ):
+        if task is None:
+            continue
+        task.cancel()
+        with suppress(asyncio.CancelledError):
+            await task
 
 
 app = FastAPI(title="kube-rca-agent", version="1.0.0", lifespan=lifespan)
+# Innermost, so payloads are logged uncompressed.
+app.add_middleware(PayloadLoggingMiddleware)
+app.add_middleware(GZipMiddleware, minimum_size=settings.gzip_minimum_size)
+app.add_middleware(GzipRequestMiddleware, max_body_bytes=settings.gzip_max_request_bytes)
 app.include_router(health.router)
 app.include_router(analysis.router)
 app.include_router(chat.router)
+app.include_router(investigation.router)
 app.include_router(config.router)
+app.include_router(retention.router)
+app.include_router(health_scan.router)
+app.include_router(oom_forecast.router)
+app.include_router(digest.router)
+app.include_router(diagnostics.router)
+app.include_router(slack.router)
+app.include_router(metrics.router)
+app.include_router(shadow.router)
+app.include_router(canary.router)
+app.include_router(suppressions.router)
+app.include_router(backfill.router)
+app.include_router(ui.router)
@@ -12,6 +12,7 @@ class PodStatusSnapshot:
     message: str | None
     conditions: list[dict[str, str | None]]
     container_statuses: list[dict[str, str | int | bool | None]]
+    init_container_statuses: list[dict[str, object]] = field(default_factory=list)
 
     def to_dict(self) -> dict[str, object]:
         return asdict(self)
@@ -37,9 +38,15 @@ class PodLogSnippet:
     previous: bool
     logs: list[str]
     error: str | None = None
+    note: str | None = None
+
+    @property
+    def stream(self) -> str:
+        """Log stream read: the last terminated instance ("previous") or the running one."""
+        return "previous" if self.previous else "current"
 
     def to_dict(self) -> dict[str, object]:
-        return asdict(self)
+        return {**asdict(self), "stream": self.stream}
 
 
 @dataclass(frozen=True)
@@ -84,9 +91,22 @@ class K8sContext:
     pod_spec: dict[str, object] | None = None
     workload_status: dict[str, object] | None = None
     pod_metrics: dict[str, object] | None = None
+    node_metrics: dict[str, object] | None = None
     node_status: dict[str, object] | None = None
     service_manifest: dict[str, object] | None = None
     endpoints_manifest: dict[str, object] | None = None
+    recent_rollouts: list[dict[str, object]] = field(default_factory=list)
+    volume_claims: list[dict[str, object]] = field(default_factory=list)
+    hpa_status: dict[str, object] | None = None
+    network_connectivity: dict[str, object] | None = None
+    service_endpoints: dict[str, object] | None = None
+    job_run: dict[str, object] | None = None
+    cluster_dns: dict[str, object] | None = None
+    resource_quotas: dict[str, object] | None = None
+    # Pod first, then each controller up to the top-level workload.
+    owner_chain: list[dict[str, object]] = field(default_factory=list)
+    # Workload and pod health of the whole namespace for alerts without a pod.
+    namespace_snapshot: dict[str, object] | None = None
 
     def to_dict(self) -> dict[str, object]:
         return {
@@ -102,8 +122,19 @@ class K8sContext:
             "pod_spec": self.pod_spec,
             "workload_status": self.workload_status,
             "pod_metrics": self.pod_metrics,
+            "node_metrics": self.node_metrics,
             "node_status": self.node_status,
             "service_manifest": self.service_manifest,
             "endpoints_manifest": self.endpoints_manifest,
+            "recent_rollouts": self.recent_rollouts,
+            "volume_claims": self.volume_claims,
+            "hpa_status": self.hpa_status,
+            "network_connectivity": self.network_connectivity,
+            "service_endpoints": self.service_endpoints,
+            "job_run": self.job_run,
+            "cluster_dns": self.cluster_dns,
+            "resource_quotas": self.resource_quotas,
+            "owner_chain": self.owner_chain,
+            "namespace_snapshot": self.namespace_snapshot,
             "warnings": self.warnings,
         }
@@ -1,6 +1,6 @@
 from __future__ import annotations
 
-from pydantic import BaseModel
+from pydantic import BaseModel, Field, SecretStr
 
 from app.schemas.alert import Alert
 
@@ -12,12 +12,37 @@ class PreviousAnalysisContext(BaseModel):
     created_at: str | None = None
 
 
+class ClusterCredentials(BaseModel):
+    """Caller's Kubernetes credentials, used instead of the agent's ServiceAccount."""
+
+    token: SecretStr | None = None
+    # vault:<path>#<key> under K8S_CREDENTIAL_VAULT_PREFIX
+    token_ref: str | None = None
+
+
 class AlertAnalysisRequest(BaseModel):
     alert: Alert
     thread_ts: str
     incident_id: str | None = None
     analysis_type: str | None = None
     previous_analysis: PreviousAnalysisContext | None = None
+    # Configured cluster to analyze in; defaults to the alert's `cluster` label.
+    cluster: str | None = None
+    # Never stored or archived with the request.
+    cluster_credentials: ClusterCredentials | None = Field(default=None, exclude=True)
+    # Incident correlation ID to reuse (e.g. the Slack thread's); generated when missing.
+    correlation_id: str | None = None
+
+
+class ArtifactAttachment(BaseModel):
+    """Artifact result uploaded to the artifact store instead of being inlined."""
+
+    key: str
+    url: str
+    content_type: str
+    size_bytes: int
+    sha256: str
+    expires_at: str
 
 
 class AlertAnalysisArtifact(BaseModel):
@@ -25,6 +50,544 @@ class AlertAnalysisArtifact(BaseModel):
     query: str | None = None
     result: dict[str, object] | list[object] | str | None = None
     summary: str | None = None
+    attachment: ArtifactAttachment | None = None
+
+
+class RecordSignature(BaseModel):
+    """HMAC signature over the canonical JSON of a response without this field."""
+
+    algorithm: str
+    key_id: str
+    signed_at: str
+    digest: str
+    signature: str
+
+
+class RecoveryValidation(BaseModel):
+    verdict: str
+    note: str = ""
+
+
+class IncidentClosure(BaseModel):
+    """Closure of the firing analysis that a resolved alert ends."""
+
+    session_key: str
+    closed_analysis_id: str | None = None
+    opened_at: str | None = None
+    closed_at: str
+    duration_seconds: float | None = None
+    duration_source: str | None = None
+    validation: RecoveryValidation | None = None
+
+
+class ContainerTermination(BaseModel):
+    reason: str | None = None
+    exit_code: int | None = None
+    finished_at: str | None = None
+
+
+class ContainerDiagnostics(BaseModel):
+    name: str | None = None
+    init: bool = False
+    ready: bool | None = None
+    restart_count: int = 0
+    state: str | None = None
+    state_reason: str | None = None
+    exit_code: int | None = None
+    last_termination: ContainerTermination | None = None
+
+
+class WorkloadOwner(BaseModel):
+    """One link of the pod's ownerReference chain."""
+
+    kind: str | None = None
+    name: str | None = None
+    api_version: str | None = None
+    uid: str | None = None
+    controller: bool = False
+
+
+class PodDiagnostics(BaseModel):
+    """Container states of the alerting pod: restarts, waiting and termination reasons."""
+
+    pod: str | None = None
+    namespace: str | None = None
+    phase: str | None = None
+    reason: str | None = None
+    node: str | None = None
+    total_restarts: int = 0
+    containers: list[ContainerDiagnostics] = Field(default_factory=list)
+    failing_conditions: list[dict[str, str | None]] = Field(default_factory=list)
+    findings: list[str] = Field(default_factory=list)
+
+
+class NodePressure(BaseModel):
+    type: str
+    reason: str | None = None
+    message: str | None = None
+    since: str | None = None
+
+
+class NodeHealth(BaseModel):
+    """Conditions, pressure, health taints and reserved capacity of the alert's node."""
+
+    name: str | None = None
+    ready: str | None = None
+    ready_reason: str | None = None
+    pressure: list[NodePressure] = Field(default_factory=list)
+    unschedulable: bool = False
+    taints: list[str] = Field(default_factory=list)
+    # capacity, allocatable and reserved_percent per resource
+    resources: dict[str, dict[str, object]] = Field(default_factory=dict)
+    healthy: bool = True
+    findings: list[str] = Field(default_factory=list)
+
+
+class OomContainerAnalysis(BaseModel):
+    name: str
+    restart_count: int = 0
+    last_oom_at: str | None = None
+    memory_request: str | None = None
+    memory_limit: str | None = None
+    memory_usage: str | None = None
+    usage_percent_of_limit: float | None = None
+    suggested_limit: str | None = None
+
+
+class OomAnalysis(BaseModel):
+    """OOMKilled containers of the alerting pod with memory usage and a suggested limit."""
+
+    pod: str | None = None
+    namespace: str | None = None
+    containers: list[OomContainerAnalysis] = Field(default_factory=list)
+    findings: list[str] = Field(default_factory=list)
+
+
+class CrashCause(BaseModel):
+    kind: str
+    fatal_line: str
+    stack_trace: list[str] = Field(default_factory=list)
+
+
+class CrashClassification(BaseModel):
+    # "log_signature" (a known runtime error in the logs) or "exit_code".
+    source: str
+    signature: str | None = None
+    runtime: str | None = None
+    category: str
+    cause: str
+    recommendation: str | None = None
+    matched_line: str | None = None
+    exit_code: int | None = None
+    signal: str | None = None
+
+
+class CrashLoopContainer(BaseModel):
+    name: str
+    restart_count: int = 0
+    last_exit_code: int | None = None
+    last_reason: str | None = None
+    last_finished_at: str | None = None
+    backoff_seconds: int
+    backoff_source: str
+    next_restart_at: str | None = None
+    # "current" when the previous container's logs were rotated away.
+    log_stream: str | None = None
+    previous_logs_available: bool = False
+    crash_cause: CrashCause | None = None
+    classification: CrashClassification | None = None
+
+
+class CrashLoopAnalysis(BaseModel):
+    """Crash looping containers with the crash cause found in their previous logs."""
+
+    pod: str | None = None
+    namespace: str | None = None
+    containers: list[CrashLoopContainer] = Field(default_factory=list)
+    findings: list[str] = Field(default_factory=list)
+
+
+class ImagePullContainer(BaseModel):
+    container: str | None = None
+    init: bool = False
+    image: str | None = None
+    registry: str | None = None
+    repository: str | None = None
+    tag: str | None = None
+    digest: str | None = None
+    image_pull_policy: str | None = None
+    reason: str | None = None
+    cause: str
+    message: str | None = None
+
+
+class ImagePullAnalysis(BaseModel):
+    """Image pull failures with the registry error and the pod's imagePullSecrets."""
+
+    pod: str | None = None
+    namespace: str | None = None
+    pull_secrets: list[str] = Field(default_factory=list)
+    missing_pull_secrets: list[str] = Field(default_factory=list)
+    containers: list[ImagePullContainer] = Field(default_factory=list)
+    findings: list[str] = Field(default_factory=list)
+
+
+class SchedulingConstraint(BaseModel):
+    kind: str
+    nodes: int | None = None
+    message: str | None = None
+    resource: str | None = None
+    taints: list[dict[str, str | None]] = Field(default_factory=list)
+    mismatched_tolerations: list[str] = Field(default_factory=list)
+
+
+class SchedulingAnalysis(BaseModel):
+    """FailedScheduling constraints of a Pending pod with the nodes each one rejects."""
+
+    pod: str | None = None
+    namespace: str | None = None
+    total_nodes: int | None = None
+    available_nodes: int | None = None
+    constraints: list[SchedulingConstraint] = Field(default_factory=list)
+    pod_constraints: list[SchedulingConstraint] = Field(default_factory=list)
+    preemption: str | None = None
+    requests: list[dict[str, object]] = Field(default_factory=list)
+    message: str | None = None
+    findings: list[str] = Field(default_factory=list)
+
+
+class ProbeFailure(BaseModel):
+    container: str | None = None
+    probe: str
+    config: dict[str, object] | None = None
+    failures: int = 0
+    messages: list[str] = Field(default_factory=list)
+    verdict: str
+    cause: str | None = None
+    status_code: int | None = None
+    issues: list[str] = Field(default_factory=list)
+    restarts_by_probe: int = 0
+    container_restarts: int = 0
+    tight_timeout: bool = False
+
+
+class ProbeAnalysis(BaseModel):
+    """Failing probes against their spec: misconfigured probe or unhealthy app."""
+
+    pod: str | None = None
+    namespace: str | None = None
+    root_cause: str
+    probes: list[ProbeFailure] = Field(default_factory=list)
+    findings: list[str] = Field(default_factory=list)
+
+
+class PodEviction(BaseModel):
+    pod: str | None = None
+    kind: str
+    evicted_by: str
+    node: str | None = None
+    resource: str | None = None
+    threshold: str | None = None
+    available: str | None = None
+    containers: list[dict[str, object]] = Field(default_factory=list)
+    preemptor: str | None = None
+    message: str | None = None
+    time: str | None = None
+    count: int = 1
+    source: str | None = None
+
+
+class EvictionAnalysis(BaseModel):
+    """Who evicted or preempted which pods, and why."""
+
+    pod: str | None = None
+    namespace: str | None = None
+    priority_class_name: str | None = None
+    evictions: list[PodEviction] = Field(default_factory=list)
+    findings: list[str] = Field(default_factory=list)
+
+
+class QuotaFailure(BaseModel):
+    kind: str
+    owner: str | None = None
+    count: int = 1
+    quota: str | None = None
+    resource: str | None = None
+    requested: str | None = None
+    used: str | None = None
+    hard: str | None = None
+    containers: list[str] = Field(default_factory=list)
+    bound: str | None = None
+    scope: str | None = None
+    allowed: str | None = None
+    field: str | None = None
+    value: str | None = None
+    message: str | None = None
+
+
+class QuotaAnalysis(BaseModel):
+    """Pod creations rejected by ResourceQuotas or LimitRanges, with quota usage."""
+
+    namespace: str | None = None
+    trigger: str | None = None
+    failures: list[QuotaFailure] = Field(default_factory=list)
+    saturated: list[dict[str, object]] = Field(default_factory=list)
+    limit_ranges: list[dict[str, object]] = Field(default_factory=list)
+    findings: list[str] = Field(default_factory=list)
+
+
+class NamespaceWorkload(BaseModel):
+    kind: str
+    name: str | None = None
+    desired: int = 0
+    ready: int = 0
+    available: int = 0
+    updated: int = 0
+    healthy: bool = True
+
+
+class NamespaceFailingPod(BaseModel):
+    name: str | None = None
+    phase: str | None = None
+    reason: str | None = None
+    ready: str | None = None
+    restarts: int = 0
+    container_reasons: list[str] = Field(default_factory=list)
+    node: str | None = None
+
+
+class NamespaceEvent(BaseModel):
+    reason: str | None = None
+    object: str | None = None
+    count: int = 1
+    last_timestamp: str | None = None
+    message: str | None = None
+
+
+class NamespaceSnapshot(BaseModel):
+    """Workload replica health, failing pods and Warning events of the alert's namespace."""
+
+    namespace: str | None = None
+    workloads: list[NamespaceWorkload] = Field(default_factory=list)
+    workload_count: int = 0
+    unhealthy_workload_count: int = 0
+    failing_pods: list[NamespaceFailingPod] = Field(default_factory=list)
+    pod_count: int = 0
+    failing_pod_count: int = 0
+    recent_events: list[NamespaceEvent] = Field(default_factory=list)
+    # Some objects or events were left out by the NAMESPACE_SNAPSHOT_MAX_* limits.
+    truncated: bool = False
+    findings: list[str] = Field(default_factory=list)
+
+
+class StorageClaimAnalysis(BaseModel):
+    name: str
+    phase: str | None = None
+    storage_class: str | None = None
+    provisioner: str | None = None
+    volume_binding_mode: str | None = None
+    allow_volume_expansion: bool | None = None
+    access_modes: list[str] = Field(default_factory=list)
+    requested: str | None = None
+    capacity: str | None = None
+    volume_name: str | None = None
+    volume_phase: str | None = None
+    attached_nodes: list[str] = Field(default_factory=list)
+    used_bytes: float | None = None
+    capacity_bytes: float | None = None
+    usage_percent: float | None = None
+    inodes_usage_percent: float | None = None
+
+
+class StorageAnalysis(BaseModel):
+    """PVC binding, StorageClass, attach/mount failures and capacity usage of storage alerts."""
+
+    pod: str | None = None
+    namespace: str | None = None
+    claims: list[StorageClaimAnalysis] = Field(default_factory=list)
+    mount_events: list[str] = Field(default_factory=list)
+    findings: list[str] = Field(default_factory=list)
+
+
+class HpaMetric(BaseModel):
+    name: str
+    type: str | None = None
+    target: str | None = None
+    current: str | None = None
+    above_target: bool = False
+
+
+class HpaAnalysis(BaseModel):
+    """HorizontalPodAutoscaler of the alert's workload: saturation and metric failures."""
+
+    name: str | None = None
+    target: dict[str, str | None] | None = None
+    min_replicas: int | None = None
+    max_replicas: int | None = None
+    current_replicas: int | None = None
+    desired_replicas: int | None = None
+    last_scale_time: str | None = None
+    at_max_replicas: bool = False
+    metrics_unavailable: bool = False
+    metrics: list[HpaMetric] = Field(default_factory=list)
+    scaling_events: list[str] = Field(default_factory=list)
+    findings: list[str] = Field(default_factory=list)
+
+
+class ContainerResourceUsage(BaseModel):
+    name: str
+    cpu_usage: str | None = None
+    cpu_request: str | None = None
+    cpu_limit: str | None = None
+    cpu_percent_of_request: float | None = None
+    cpu_percent_of_limit: float | None = None
+    memory_usage: str | None = None
+    memory_request: str | None = None
+    memory_limit: str | None = None
+    memory_percent_of_request: float | None = None
+    memory_percent_of_limit: float | None = None
+
+
+class NodeResourceUsage(BaseModel):
+    name: str | None = None
+    cpu_usage: str | None = None
+    cpu_allocatable: str | None = None
+    cpu_percent_of_allocatable: float | None = None
+    memory_usage: str | None = None
+    memory_allocatable: str | None = None
+    memory_percent_of_allocatable: float | None = None
+
+
+class ResourcePressure(BaseModel):
+    """Current CPU/memory usage of the pod and its node against requests, limits, allocatable."""
+
+    pod: str | None = None
+    namespace: str | None = None
+    verdict: str
+    window: str | None = None
+    containers: list[ContainerResourceUsage] = Field(default_factory=list)
+    node: NodeResourceUsage | None = None
+    findings: list[str] = Field(default_factory=list)
+
+
+class NetworkPolicyDirection(BaseModel):
+    isolated: bool = False
+    policies: list[str] = Field(default_factory=list)
+    allowed_by: list[str] = Field(default_factory=list)
+    verdict: str
+    dns_allowed: bool | None = None
+    missing_rule: dict[str, object] | None = None
+
+
+class NetworkPolicyAnalysis(BaseModel):
+    """Whether NetworkPolicies block traffic from the alerting pod to the service it calls."""
+
+    source: dict[str, str | None]
+    destination: dict[str, object]
+    verdict: str
+    egress: NetworkPolicyDirection
+    ingress: NetworkPolicyDirection
+    findings: list[str] = Field(default_factory=list)
+
+
+class NotReadyEndpointPod(BaseModel):
+    pod: str | None = None
+    reason: str
+    detail: str | None = None
+
+
+class EndpointReadiness(BaseModel):
+    """Ready vs. not-ready endpoints of the Service behind a 5xx/unreachable alert."""
+
+    service: str
+    selector: dict[str, str] | None = None
+    verdict: str
+    cause: str | None = None
+    ready: int = 0
+    not_ready: int = 0
+    terminating: int = 0
+    selected_pods: int = 0
+    not_ready_pods: list[NotReadyEndpointPod] = Field(default_factory=list)
+    near_misses: list[dict[str, object]] = Field(default_factory=list)
+    findings: list[str] = Field(default_factory=list)
+
+
+class FailedJobPod(BaseModel):
+    pod: str | None = None
+    reason: str
+    detail: str | None = None
+    exit_code: int | None = None
+
+
+class JobFailureAnalysis(BaseModel):
+    """Why a Job (or the latest run of a CronJob) failed or keeps failing."""
+
+    job: str | None = None
+    cron_job: str | None = None
+    outcome: str
+    cause: str | None = None
+    backoff_limit: int | None = None
+    active_deadline_seconds: int | None = None
+    failed: int = 0
+    succeeded: int = 0
+    failed_pods: list[FailedJobPod] = Field(default_factory=list)
+    recent_runs: list[dict[str, object]] = Field(default_factory=list)
+    last_run_logs: list[dict[str, object]] = Field(default_factory=list)
+    findings: list[str] = Field(default_factory=list)
+
+
+class DnsAnalysis(BaseModel):
+    """Health of the cluster DNS Service (CoreDNS) behind a DNS-failure alert."""
+
+    service: str
+    trigger: str | None = None
+    verdict: str
+    cause: str | None = None
+    ready_endpoints: int = 0
+    endpoints: int = 0
+    not_ready_pods: list[str] = Field(default_factory=list)
+    restarts: int = 0
+    servfail: int = 0
+    errors: int = 0
+    upstream_timeouts: int = 0
+    log_samples: list[str] = Field(default_factory=list)
+    alert_log_lines: list[str] = Field(default_factory=list)
+    findings: list[str] = Field(default_factory=list)
+
+
+class RankedHypothesis(BaseModel):
+    """Candidate root cause investigated in its own branch, ranked by verdict and confidence."""
+
+    rank: int
+    key: str
+    title: str
+    verdict: str
+    confidence: float = 0.0
+    summary: str = ""
+    supporting: list[str] = Field(default_factory=list)
+    contradicting: list[str] = Field(default_factory=list)
+
+
+class AlertStorm(BaseModel):
+    """Alert storm in progress; the analysis of this alert was deferred."""
+
+    active: bool = True
+    started_at: str | None = None
+    alerts_per_minute: int
+    cluster: str | None = None
+    namespace: str | None = None
+    group_alerts: int
+    queued: bool
+
+
+class AlertSuppression(BaseModel):
+    """Active suppression window matching the alert; the alert was not analyzed."""
+
+    suppression_id: int
+    reason: str
+    created_by: str | None = None
+    ends_at: str
+    matchers: list[dict[str, str]] = Field(default_factory=list)
 
 
 class AlertAnalysisResponse(BaseModel):
@@ -38,8 +601,90 @@ class AlertAnalysisResponse(BaseModel):
     missing_data: list[str] | None = None
     warnings: list[str] | None = None
     capabilities: dict[str, str] | None = None
+    degraded: bool = False
+    degraded_reason: str | None = None
+    time_boxed: bool = False
+    expected_disruption: bool = False
+    analysis_id: str | None = None
+    # Also in the agent's log lines, trace spans and stored record of this analysis.
+    correlation_id: str | None = None
+    routing: str | None = None
+    closure: IncidentClosure | None = None
+    owner_chain: list[WorkloadOwner] | None = None
+    pod_diagnostics: PodDiagnostics | None = None
+    node_health: NodeHealth | None = None
+    oom_analysis: OomAnalysis | None = None
+    crash_loop: CrashLoopAnalysis | None = None
+    image_pull: ImagePullAnalysis | None = None
+    scheduling: SchedulingAnalysis | None = None
+    probe_analysis: ProbeAnalysis | None = None
+    eviction_analysis: EvictionAnalysis | None = None
+    quota_analysis: QuotaAnalysis | None = None
+    namespace_snapshot: NamespaceSnapshot | None = None
+    storage_analysis: StorageAnalysis | None = None
+    hpa_analysis: HpaAnalysis | None = None
+    resource_pressure: ResourcePressure | None = None
+    network_policy_analysis: NetworkPolicyAnalysis | None = None
+    endpoint_readiness: EndpointReadiness | None = None
+    job_failure_analysis: JobFailureAnalysis | None = None
+    dns_analysis: DnsAnalysis | None = None
+    hypotheses: list[RankedHypothesis] | None = None
+    storm: AlertStorm | None = None
+    suppression: AlertSuppression | None = None
     context: dict[str, object] | None = None
     artifacts: list[AlertAnalysisArtifact] | None = None
+    signature: RecordSignature | None = None
+
+
+class AlertGroupAnalysisRequest(BaseModel):
+    """Alerts of one Alertmanager webhook group, answered with a single summary."""
+
+    alerts: list[Alert] = Field(min_length=1, max_length=200)
+    thread_ts: str
+    incident_id: str | None = None
+    group_labels: dict[str, str] = Field(default_factory=dict)
+
+
+class AlertGroupMember(BaseModel):
+    fingerprint: str | None = None
+    alertname: str | None = None
+    status: str
+    namespace: str | None = None
+    workload: str | None = None
+    node: str | None = None
+    analysis_id: str | None = None
+    analysis_summary: str
+    degraded: bool = False
+
+
+class AlertGroupAnalysisResponse(BaseModel):
+    status: str
+    thread_ts: str
+    alert_count: int
+    analyzed_count: int
+    group_summary: str
+    common_cause: str | None = None
+    shared: dict[str, str] = Field(default_factory=dict)
+    degraded: bool = False
+    alerts: list[AlertGroupMember]
+    signature: RecordSignature | None = None
+
+
+class AnalysisFollowupRequest(BaseModel):
+    """Follow-up question asked in the Slack thread of a previous analysis."""
+
+    question: str = Field(min_length=1)
+    thread_ts: str
+    context: dict[str, object] | None = None
+    # Required when the analysis ran with caller credentials (K8S_CREDENTIAL_PASSTHROUGH).
+    cluster_credentials: ClusterCredentials | None = Field(default=None, exclude=True)
+
+
+class AnalysisFollowupResponse(BaseModel):
+    status: str
+    thread_ts: str
+    analysis_id: str
+    answer: str
 
 
 # Incident Summary schemas (for final RCA when incident is resolved)
@@ -67,3 +712,35 @@ class IncidentSummaryResponse(BaseModel):
     title: str
     summary: str
     detail: str
+    signature: RecordSignature | None = None
+
+
+class RecordVerificationRequest(BaseModel):
+    record: dict[str, object]
+    signature: RecordSignature
+
+
+class RecordVerificationResponse(BaseModel):
+    status: str = "ok"
+    valid: bool
+
+
+class AlertMappingResult(BaseModel):
+    index: int
+    valid: bool
+    alert: dict[str, object] | None = None
+    target: dict[str, object] | None = None
+    session_key: str | None = None
+    missing_fields: list[str] = Field(default_factory=list)
+    ignored_fields: list[str] = Field(default_factory=list)
+    warnings: list[str] = Field(default_factory=list)
+    errors: list[str] = Field(default_factory=list)
+
+
+class AlertmanagerValidationResponse(BaseModel):
+    valid: bool
+    payload_format: str
+    alert_count: int
+    alerts: list[AlertMappingResult] = Field(default_factory=list)
+    ignored_fields: list[str] = Field(default_factory=list)
+    errors: list[str] = Field(default_factory=list)
@@ -0,0 +1,13 @@
+from __future__ import annotations
+
+from pydantic import BaseModel
+
+
+class SlackInteractionResponse(BaseModel):
+    """Result of a Slack button or slash-command action; ``text`` goes to ``thread_ts``."""
+
+    status: str
+    action: str
+    analysis_id: str
+    thread_ts: str
+    text: str | None = None
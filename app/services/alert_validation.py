@@ -0,0 +1,140 @@
+"""Dry-run mapping of Alertmanager webhook payloads onto the analysis request model.
+
+Used by ``POST /analyze/alertmanager/validate`` when wiring up a new receiver:
+nothing is analyzed, the response only explains how each alert would be read.
+"""
+
+from __future__ import annotations
+
+from pydantic import ValidationError
+
+from app.clients.k8s import resolve_alert_target
+from app.schemas.alert import Alert
+from app.schemas.analysis import AlertAnalysisRequest
+from app.services.analysis import _resolve_alert_session_id
+
+_ALERT_FIELDS = frozenset(
+    {"status", "labels", "annotations", "startsAt", "endsAt", "generatorURL", "fingerprint"}
+)
+_VALID_STATUSES = frozenset({"firing", "resolved"})
+# Alertmanager uses the zero time as endsAt for alerts that are still firing.
+_ZERO_TIME_PREFIX = "0001-01-01"
+
+
+def validate_alertmanager_payload(payload: object) -> dict[str, object]:
+    """Explain how an Alertmanager webhook (or a single ``/analyze`` body) maps internally."""
+    if not isinstance(payload, dict):
+        return _result("unknown", [], [], ["payload must be a JSON object"])
+
+    if isinstance(payload.get("alerts"), list):
+        # Only the alerts are read; group-level fields (receiver, groupLabels,
+        # commonLabels, externalURL, ...) never reach the analysis.
+        ignored = sorted(set(payload) - {"alerts"})
+        alerts = [
+            _map_alert(index, raw_alert, common_labels=payload.get("commonLabels"))
+            for index, raw_alert in enumerate(payload["alerts"])
+        ]
+        errors = [] if alerts else ["alerts array is empty"]
+        return _result("alertmanager_webhook", alerts, ignored, errors)
+
+    if "alert" in payload:
+        try:
+            request = AlertAnalysisRequest.model_validate(payload)
+        except ValidationError as exc:
+            return _result("analyze_request", [], [], _validation_errors(exc))
+        ignored = sorted(set(payload) - set(AlertAnalysisRequest.model_fields))
+        mapped = _map_alert(0, payload["alert"], request=request)
+        return _result("analyze_request", [mapped], ignored, [])
+
+    return _result(
+        "unknown", [], sorted(payload), ["expected an Alertmanager webhook with an alerts array"]
+    )
+
+
+def _map_alert(
+    index: int,
+    raw_alert: object,
+    *,
+    common_labels: object = None,
+    request: AlertAnalysisRequest | None = None,
+) -> dict[str, object]:
+    mapping: dict[str, object] = {
+        "index": index,
+        "valid": False,
+        "alert": None,
+        "target": None,
+        "session_key": None,
+        "missing_fields": [],
+        "ignored_fields": [],
+        "warnings": [],
+        "errors": [],
+    }
+    if not isinstance(raw_alert, dict):
+        mapping["errors"] = ["alert must be a JSON object"]
+        return mapping
+    try:
+        alert = Alert.model_validate(raw_alert)
+    except ValidationError as exc:
+        mapping["errors"] = _validation_errors(exc)
+        return mapping
+
+    missing: list[str] = []
+    warnings: list[str] = []
+    if alert.status not in _VALID_STATUSES:
+        warnings.append(f"status {alert.status!r} is neither firing nor resolved")
+    if not alert.labels.get("alertname"):
+        missing.append("labels.alertname")
+    if not alert.fingerprint:
+        missing.append("fingerprint")
+        warnings.append("no fingerprint; the session key falls back to alert labels")
+    if alert.starts_at is None:
+        missing.append("startsAt")
+        warnings.append("no startsAt; trace and log windows are anchored at request time")
+    if alert.status == "resolved" and (
+        alert.ends_at is None or str(raw_alert.get("endsAt", "")).startswith(_ZERO_TIME_PREFIX)
+    ):
+        warnings.append("resolved alert without endsAt; the resolved window cannot be bounded")
+
+    target = resolve_alert_target(alert.labels)
+    if target.namespace is None:
+        missing.append("labels.namespace")
+        warnings.append("no namespace label; Kubernetes context cannot be collected")
+    if target.pod_name is None and target.workload is None:
+        warnings.append("no pod or workload label; analysis falls back to namespace-wide context")
+    if isinstance(common_labels, dict) and common_labels and not alert.labels:
+        warnings.append("alert has no labels; commonLabels are not merged into alerts")
+
+    session_request = request or AlertAnalysisRequest(alert=alert, thread_ts="")
+    mapping.update(
+        valid=True,
+        alert=alert.model_dump(mode="json", by_alias=True),
+        target=target.to_dict(),
+        session_key=_resolve_alert_session_id(session_request),
+        missing_fields=missing,
+        ignored_fields=sorted(set(raw_alert) - _ALERT_FIELDS),
+        warnings=warnings,
+    )
+    return mapping
+
+
+def _result(
+    payload_format: str,
+    alerts: list[dict[str, object]],
+    ignored_fields: list[str],
+    errors: list[str],
+) -> dict[str, object]:
+    return {
+        "valid": not errors and all(alert["valid"] for alert in alerts),
+        "payload_format": payload_format,
+        "alert_count": len(alerts),
+        "alerts": alerts,
+        "ignored_fields": ignored_fields,
+        "errors": errors,
+    }
+
+
+def _validation_errors(exc: ValidationError) -> list[str]:
+    return [
+        f"{'.'.join(str(part) for part in error['loc']) or 'payload'}: {error['msg']}"
+        for error in exc.errors()
+    ]
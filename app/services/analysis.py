@@ -3,19 +3,147 @@ from __future__ import annotations
 import json
 import logging
 import re
+import threading
 import time
-from dataclasses import dataclass
+from collections import OrderedDict
+from collections.abc import Callable, Iterator
+from contextlib import contextmanager, suppress
+from dataclasses import dataclass, replace
 from datetime import datetime, timedelta, timezone
-from typing import Any, cast
+from typing import Any, Protocol, cast
 from uuid import uuid4
 
+from app.clients.analysis_store import AnalysisStore, StoredAnalysis
+from app.clients.cloud_status import CloudStatusClient
+from app.clients.event_archive import EventArchive
 from app.clients.k8s import KubernetesClient, resolve_alert_target
+from app.clients.service_catalog import ServiceCatalogClient
 from app.clients.strands_agent import AnalysisEngine
 from app.clients.summary_store import SummaryStore
 from app.clients.tempo import TempoClient, build_traceql_query
+from app.core.correlation import (
+    current_correlation_id,
+    resolve_correlation_id,
+    use_correlation_id,
+)
+from app.core.deadline import DeadlineExceeded, check_deadline, remaining_seconds, use_deadline
+from app.core.evidence_budget import select_events, select_log_lines
+from app.core.k8s_clusters import UnknownCluster, current_cluster, resolve_cluster, use_cluster
+from app.core.k8s_credentials import (
+    ClusterCredentialError,
+    caller_credentials_active,
+    resolve_cluster_token,
+    use_cluster_token,
+)
 from app.core.masking import Masker, RegexMasker
+from app.core.memory import MEMORY_LEVEL_NORMAL, MemoryPressureMonitor, scale_limit
+from app.core.overrides import current_overrides
+from app.core.pipeline import (
+    DEFAULT_PIPELINE,
+    STAGE_CORRELATE,
+    STAGE_DELIVER,
+    STAGE_FORMAT,
+    STAGE_LLM,
+    STAGE_RULES,
+    AnalysisPipeline,
+    PipelineRegistry,
+)
+from app.core.slo import LatencySloTracker
 from app.models.k8s import AnalysisTarget, K8sContext
-from app.schemas.analysis import AlertAnalysisRequest, IncidentSummaryRequest
+from app.schemas.alert import Alert
+from app.schemas.analysis import (
+    AlertAnalysisRequest,
+    AnalysisFollowupRequest,
+    ClusterCredentials,
+    IncidentSummaryRequest,
+)
+from app.services.artifact_store import ArtifactOffloader
+from app.services.canary import CanaryRollout
+from app.services.closure import IncidentClosureTracker, OpenAnalysis, incident_duration_seconds
+from app.services.cloud_incidents import match_cloud_incidents
+from app.services.crash_loop import build_crash_loop_analysis
+from app.services.digest import AnalysisLedger, AnalysisRecord
+from app.services.dns_analysis import build_dns_analysis, dns_alert
+from app.services.event_archive import merge_archived_events
+from app.services.eviction_analysis import build_eviction_analysis
+from app.services.hpa_analysis import build_hpa_analysis
+from app.services.hypotheses import HypothesisInvestigator, format_ranked_hypotheses
+from app.services.image_pull import build_image_pull_analysis
+from app.services.investigation import (
+    MaskedLineStream,
+    build_investigation_prompt,
+    tool_result_text,
+)
+from app.services.job_analysis import build_job_failure_analysis, job_target
+from app.services.kube_state import KubeStateCollector
+from app.services.namespace_config import (
+    DEPTH_QUICK,
+    depth_scale,
+    format_namespace_config,
+    resolve_namespace_config,
+    scale_evidence_limit,
+)
+from app.services.namespace_snapshot import build_namespace_snapshot
+from app.services.network_policy import build_network_policy_analysis, connectivity_target
+from app.services.node_health import resolve_alert_node, summarize_node_health
+from app.services.oom_analysis import build_oom_analysis
+from app.services.pod_diagnostics import build_pod_diagnostics
+from app.services.probe_analysis import build_probe_analysis
+from app.services.quota_analysis import build_quota_analysis, quota_alert
+from app.services.resource_pressure import build_resource_pressure
+from app.services.rollout_correlation import find_recent_rollouts
+from app.services.rules import RuleFinding, run_rule_analyzers
+from app.services.scheduling_analysis import build_scheduling_analysis
+from app.services.service_endpoints import build_endpoint_readiness, endpoints_target
+from app.services.shadow import ShadowAnalysisRunner
+from app.services.storage_analysis import (
+    build_storage_analysis,
+    storage_claim_names,
+    volume_stats_nodes,
+)
+
+# Time-box at 90% of the SLO target, leaving room to build and deliver the result.
+_SLO_DEADLINE_RATIO = 0.9
+# ReplicaSet revisions checked for rollouts before the alert.
+_ROLLOUT_HISTORY_LIMIT = 5
+_ARCHIVED_EVENT_LIMIT = 50
+# Log lines per container and events kept when the prompt budget is exceeded.
+_BUDGET_REDUCED_LOG_LINES = 5
+_BUDGET_REDUCED_EVENTS = 5
+# Share of the remaining SLO deadline the parallel hypothesis branches may use.
+_HYPOTHESIS_DEADLINE_SHARE = 0.5
+# Access scopes of recent sessions kept in memory (the session store keeps all).
+_MAX_SESSION_SCOPES = 5000
+# Structured analyses built from the collected context, by context key.
+_CONTEXT_ANALYSES: tuple[tuple[str, Callable[[K8sContext], object | None]], ...] = (
+    ("pod_diagnostics", build_pod_diagnostics),
+    ("node_health", lambda k8s_context: summarize_node_health(k8s_context.node_status)),
+    ("oom_analysis", build_oom_analysis),
+    ("crash_loop", build_crash_loop_analysis),
+    ("image_pull", build_image_pull_analysis),
+    ("scheduling", build_scheduling_analysis),
+    ("probe_analysis", build_probe_analysis),
+    ("eviction_analysis", build_eviction_analysis),
+    ("quota_analysis", build_quota_analysis),
+    ("namespace_snapshot", build_namespace_snapshot),
+    ("storage_analysis", build_storage_analysis),
+    ("hpa_analysis", build_hpa_analysis),
+    ("resource_pressure", build_resource_pressure),
+    ("network_policy_analysis", build_network_policy_analysis),
+    ("endpoint_readiness", build_endpoint_readiness),
+    ("job_failure_analysis", build_job_failure_analysis),
+    ("dns_analysis", build_dns_analysis),
+)
+# Returned to the caller only; the prompt already has the pod status they summarize.
+_RESPONSE_ONLY_ANALYSES = frozenset({"pod_diagnostics"})
+
+
+class AnalysisNotFoundError(LookupError):
+    """No stored investigation session exists for the given analysis id."""
+
+
+class _SessionLookup(Protocol):
+    def read_session(self, session_id: str, **kwargs: Any) -> object | None: ...
 
 
 class AnalysisService:
@@ -36,6 +164,42 @@ class AnalysisService:
         prompt_token_budget: int = 32000,
         prompt_max_log_lines: int = 25,
         prompt_max_events: int = 25,
+        memory_monitor: MemoryPressureMonitor | None = None,
+        infra_changes_enabled: bool = False,
+        image_metadata_enabled: bool = False,
+        code_changes_enabled: bool = False,
+        datastore_health_enabled: bool = False,
+        kafka_lag_enabled: bool = False,
+        endpoint_probe_enabled: bool = False,
+        observability_enabled: bool = False,
+        ledger: AnalysisLedger | None = None,
+        session_repository: _SessionLookup | None = None,
+        slo_tracker: LatencySloTracker | None = None,
+        shadow_runner: ShadowAnalysisRunner | None = None,
+        canary: CanaryRollout | None = None,
+        analysis_store: AnalysisStore | None = None,
+        pipeline_version: str = "",
+        closure_tracker: IncidentClosureTracker | None = None,
+        closure_validation: bool = False,
+        maintenance_awareness: bool = False,
+        maintenance_disruption_alerts: tuple[str, ...] = (),
+        rollout_correlation_window_minutes: int = 0,
+        cluster_dns_namespace: str = "kube-system",
+        cluster_dns_service: str = "kube-dns",
+        cluster_dns_log_lookback_minutes: int = 15,
+        cloud_status: CloudStatusClient | None = None,
+        service_catalog: ServiceCatalogClient | None = None,
+        kube_state: KubeStateCollector | None = None,
+        hypothesis_investigator: HypothesisInvestigator | None = None,
+        pipelines: PipelineRegistry | None = None,
+        event_archive: EventArchive | None = None,
+        event_archive_lookback_minutes: int = 60,
+        artifact_offloader: ArtifactOffloader | None = None,
+        namespace_config_enabled: bool = False,
+        namespace_snapshot_enabled: bool = False,
+        namespace_snapshot_max_workloads: int = 20,
+        namespace_snapshot_max_pods: int = 20,
+        namespace_snapshot_max_events: int = 20,
     ) -> None:
         self._logger = logging.getLogger(__name__)
         self._k8s_client = k8s_client
@@ -53,11 +217,709 @@ class AnalysisService:
         self._prompt_token_budget = max(0, prompt_token_budget)
         self._prompt_max_log_lines = max(0, prompt_max_log_lines)
         self._prompt_max_events = max(0, prompt_max_events)
+        self._memory_monitor = memory_monitor
+        self._infra_changes_enabled = infra_changes_enabled
+        self._image_metadata_enabled = image_metadata_enabled
+        self._code_changes_enabled = code_changes_enabled
+        self._datastore_health_enabled = datastore_health_enabled
+        self._kafka_lag_enabled = kafka_lag_enabled
+        self._endpoint_probe_enabled = endpoint_probe_enabled
+        self._observability_enabled = observability_enabled
+        self._ledger = ledger
+        self._session_repository = session_repository
+        self._slo_tracker = slo_tracker
+        self._shadow_runner = shadow_runner
+        self._canary = canary
+        self._analysis_store = analysis_store
+        self._pipeline_version = pipeline_version or "unversioned"
+        self._closure_tracker = closure_tracker
+        self._closure_validation = closure_validation
+        self._maintenance_awareness = maintenance_awareness
+        self._maintenance_disruption_alerts = frozenset(maintenance_disruption_alerts)
+        self._rollout_window_minutes = max(0, rollout_correlation_window_minutes)
+        self._cluster_dns_namespace = cluster_dns_namespace
+        self._cluster_dns_service = cluster_dns_service
+        self._cluster_dns_lookback_seconds = max(1, cluster_dns_log_lookback_minutes) * 60
+        self._cloud_status = cloud_status
+        self._service_catalog = service_catalog
+        self._kube_state = kube_state
+        self._hypothesis_investigator = hypothesis_investigator
+        self._pipelines = pipelines
+        self._event_archive = event_archive
+        self._event_archive_lookback_minutes = max(0, event_archive_lookback_minutes)
+        self._artifact_offloader = artifact_offloader
+        self._namespace_config_enabled = namespace_config_enabled
+        self._namespace_snapshot_enabled = namespace_snapshot_enabled
+        self._namespace_snapshot_max_workloads = max(0, namespace_snapshot_max_workloads)
+        self._namespace_snapshot_max_pods = max(0, namespace_snapshot_max_pods)
+        self._namespace_snapshot_max_events = max(0, namespace_snapshot_max_events)
+        self._session_scopes: OrderedDict[str, dict[str, object]] = OrderedDict()
+        self._session_scopes_lock = threading.Lock()
 
     def analyze(
         self, request: AlertAnalysisRequest
+    ) -> tuple[str, str, str, dict[str, object], list[dict[str, object]]]:
+        cluster, cluster_warning = _request_cluster(request)
+        token = resolve_cluster_token(request.cluster_credentials)
+        correlation_id = resolve_correlation_id(request.correlation_id)
+        with use_cluster(cluster), use_cluster_token(token), use_correlation_id(correlation_id):
+            result = self._analyze_live(request)
+        context = result[3]
+        if cluster is not None:
+            context["cluster"] = cluster
+        if cluster_warning:
+            context["warnings"] = [*cast(list[str], context.get("warnings") or []), cluster_warning]
+        if token is not None:
+            context["k8s_credentials"] = "caller"
+        analysis_id = context.get("analysis_id")
+        if isinstance(analysis_id, str):
+            self._remember_session_scope(
+                analysis_id,
+                {"credentials": "caller" if token is not None else "agent", "cluster": cluster},
+            )
+        return result
+
+    def _analyze_live(
+        self, request: AlertAnalysisRequest
+    ) -> tuple[str, str, str, dict[str, object], list[dict[str, object]]]:
+        canary_arm = self._canary is not None and self._canary.assign(
+            _resolve_alert_session_id(request)
+        )
+        pipeline = self._resolve_pipeline(request)
+        slo = self._slo_tracker.resolve(request.alert.labels) if self._slo_tracker else None
+        if slo is None:
+            result = self._analyze(request, canary_arm=canary_arm, pipeline=pipeline)
+        else:
+            started = time.perf_counter()
+            # Every Kubernetes, HTTP and LLM call of the analysis is bounded by the deadline.
+            with use_deadline(started + slo.target_seconds * _SLO_DEADLINE_RATIO):
+                try:
+                    result = self._analyze(request, canary_arm=canary_arm, pipeline=pipeline)
+                except DeadlineExceeded:
+                    # The deadline passed while the evidence was still being collected.
+                    result = self._time_boxed_result(request)
+            duration = time.perf_counter() - started
+            outcome = cast(LatencySloTracker, self._slo_tracker).observe(
+                slo, duration, time_boxed=result[3].get("time_boxed") is True
+            )
+            self._logger.info(
+                "analysis_slo slo=%s target_s=%.1f duration_s=%.1f outcome=%s",
+                slo.key,
+                slo.target_seconds,
+                duration,
+                outcome,
+            )
+
+        result = self._offload_artifacts(request, self._apply_pipeline(result, pipeline))
+        context = result[3]
+        context["correlation_id"] = current_correlation_id()
+        if self._canary is not None and context.get("degraded_reason") not in {
+            "not_configured",
+            "llm_disabled",
+        }:
+            context["rollout_arm"] = "canary" if canary_arm else "stable"
+            analysis_id = context.get("analysis_id")
+            self._canary.record(
+                canary_arm,
+                failed=context.get("degraded") is True,
+                analysis_id=analysis_id if isinstance(analysis_id, str) else None,
+            )
+        if pipeline.runs(STAGE_DELIVER):
+            self._track_closure(request, result)
+            self._store_analysis(request, result, source="live")
+        return result
+
+    def warm_up(self) -> int:
+        """Render the prompt of a synthetic alert once, ahead of the first analysis.
+
+        Touches the masking patterns, rule analyzers and prompt budgeting
+        without calling Kubernetes or the LLM; returns the prompt length.
+        """
+        request = AlertAnalysisRequest(
+            alert=Alert(status="firing", labels={"alertname": "Prewarm", "namespace": "default"}),
+            thread_ts="prewarm",
+        )
+        k8s_context = K8sContext(
+            namespace="default",
+            pod_name=None,
+            workload=None,
+            pod_status=None,
+            events=[],
+            previous_logs=[],
+            warnings=[],
+        )
+        capabilities, _ = self._collect_capabilities(k8s_context=k8s_context, tempo_context=None)
+        prompt = _build_prompt(
+            request,
+            k8s_context,
+            self._prometheus_enabled,
+            self._loki_enabled,
+            self._tempo_enabled,
+            None,
+            capabilities,
+            [],
+            [],
+            [],
+            self._prompt_token_budget,
+            self._prompt_max_log_lines,
+            self._prompt_max_events,
+            self._masker,
+            rule_findings=run_rule_analyzers(k8s_context),
+        )
+        return len(prompt)
+
+    def reanalyze(self, request: AlertAnalysisRequest, *, backfill_job_id: str) -> int | None:
+        """Re-run a stored alert through the current pipeline and store it as a new version.
+
+        Summary history, the digest ledger, shadow runs and the canary are left
+        untouched so a backfill does not look like new alert activity.
+        """
+        pipeline = self._resolve_pipeline(request)
+        # A re-run is a new analysis of the stored request, with its own correlation ID.
+        correlation_id = resolve_correlation_id(None)
+        with use_cluster(_request_cluster(request)[0]), use_correlation_id(correlation_id):
+            result = self._offload_artifacts(
+                request,
+                self._apply_pipeline(
+                    self._analyze(request, backfill=True, pipeline=pipeline),
+                    pipeline,
+                ),
+            )
+        result[3]["correlation_id"] = correlation_id
+        return self._store_analysis(
+            request, result, source="backfill", backfill_job_id=backfill_job_id
+        )
+
+    def _resolve_pipeline(self, request: AlertAnalysisRequest) -> AnalysisPipeline:
+        if self._pipelines is None:
+            return DEFAULT_PIPELINE
+        return self._pipelines.resolve(request.alert.labels)
+
+    def _apply_pipeline(
+        self,
+        result: tuple[str, str, str, dict[str, object], list[dict[str, object]]],
+        pipeline: AnalysisPipeline,
+    ) -> tuple[str, str, str, dict[str, object], list[dict[str, object]]]:
+        """Drop the formatting of a profile without ``format`` and record the profile."""
+        analysis, summary, detail, context, artifacts = result
+        if not pipeline.runs(STAGE_FORMAT):
+            summary, detail, artifacts = analysis, "", []
+        if self._pipelines is not None:
+            context["pipeline"] = pipeline.to_dict()
+        return analysis, summary, detail, context, artifacts
+
+    def _offload_artifacts(
+        self,
+        request: AlertAnalysisRequest,
+        result: tuple[str, str, str, dict[str, object], list[dict[str, object]]],
+    ) -> tuple[str, str, str, dict[str, object], list[dict[str, object]]]:
+        """Replace large artifact results with signed URLs of the artifact store."""
+        analysis, summary, detail, context, artifacts = result
+        if self._artifact_offloader is None or not artifacts:
+            return result
+        analysis_id = context.get("analysis_id")
+        namespace = context.get("namespace")
+        artifacts = self._artifact_offloader.offload(
+            analysis_id if isinstance(analysis_id, str) else None,
+            artifacts,
+            namespace=namespace if isinstance(namespace, str) else None,
+            incident_id=request.incident_id,
+        )
+        return analysis, summary, detail, context, artifacts
+
+    def _track_closure(
+        self,
+        request: AlertAnalysisRequest,
+        result: tuple[str, str, str, dict[str, object], list[dict[str, object]]],
+    ) -> None:
+        """Open the alert on a firing analysis; close it on the resolved one."""
+        if self._closure_tracker is None:
+            return
+        _, summary, _, context, _ = result
+        session_key = _resolve_alert_session_id(request)
+        namespace = cast(str | None, context.get("namespace"))
+        analysis_type = request.analysis_type or request.alert.status
+        if analysis_type == "firing":
+            analysis_id = context.get("analysis_id")
+            self._closure_tracker.open(
+                OpenAnalysis(
+                    session_key=session_key,
+                    analysis_id=analysis_id if isinstance(analysis_id, str) else None,
+                    thread_ts=request.thread_ts,
+                    incident_id=request.incident_id,
+                    alertname=request.alert.labels.get("alertname"),
+                    namespace=namespace,
+                    summary=summary,
+                    opened_at=datetime.now(timezone.utc),
+                )
+            )
+            return
+        if analysis_type != "resolved":
+            return
+
+        opened = self._closure_tracker.close(session_key)
+        duration_seconds, duration_source = incident_duration_seconds(
+            request.alert.starts_at,
+            request.alert.ends_at,
+            opened.opened_at if opened is not None else None,
+        )
+        suspected_cause = opened.summary if opened is not None else ""
+        if not suspected_cause and request.previous_analysis is not None:
+            suspected_cause = request.previous_analysis.summary
+        closure: dict[str, object] = {
+            "session_key": session_key,
+            "closed_analysis_id": opened.analysis_id if opened is not None else None,
+            "opened_at": opened.opened_at.isoformat() if opened is not None else None,
+            "closed_at": datetime.now(timezone.utc).isoformat(),
+            "duration_seconds": duration_seconds,
+            "duration_source": duration_source,
+            "validation": None,
+        }
+        if self._closure_validation and suspected_cause and context.get("degraded") is not True:
+            closure["validation"] = self._validate_recovery(session_key, suspected_cause, summary)
+            self._tag_session_namespace(session_key, namespace)
+        context["closure"] = closure
+        try:
+            self._closure_tracker.notify(
+                {
+                    **closure,
+                    "thread_ts": request.thread_ts,
+                    "incident_id": request.incident_id,
+                    "correlation_id": current_correlation_id(),
+                    "alertname": request.alert.labels.get("alertname"),
+                    "namespace": namespace,
+                    "resolved_summary": summary,
+                }
+            )
+        except Exception as exc:  # noqa: BLE001
+            self._logger.warning("Failed to send incident closure callback: %s", exc)
+
+    def _attach_node_status(
+        self, request: AlertAnalysisRequest, k8s_context: K8sContext
+    ) -> K8sContext:
+        """Read the Node of node-level alerts (``node``/``instance`` label) into the context."""
+        node_name, explicit = resolve_alert_node(request.alert.labels)
+        if not node_name or k8s_context.node_status is not None:
+            return k8s_context
+        status = self._k8s_client.get_node_status(node_name)
+        if status is None:
+            if not explicit:
+                # ``instance`` is often an IP rather than a node name.
+                return k8s_context
+            return replace(
+                k8s_context, warnings=[*k8s_context.warnings, f"failed to read node {node_name}"]
+            )
+        return replace(k8s_context, node_status=status)
+
+    def _attach_recent_rollouts(
+        self, request: AlertAnalysisRequest, k8s_context: K8sContext
+    ) -> K8sContext:
+        """Rollouts of the alert's Deployment within the window before ``startsAt``."""
+        namespace = k8s_context.namespace
+        if self._rollout_window_minutes <= 0 or not namespace:
+            return k8s_context
+        if len(k8s_context.owner_chain) > 1:
+            # collect_context already followed the pod's ownerReferences.
+            deployment = next(
+                (
+                    str(link["name"])
+                    for link in k8s_context.owner_chain
+                    if link.get("kind") == "Deployment" and link.get("name")
+                ),
+                None,
+            )
+        else:
+            deployment = (
+                self._k8s_client.get_pod_deployment(namespace, k8s_context.pod_name)
+                if k8s_context.pod_name
+                else None
+            ) or k8s_context.workload
+        if not deployment:
+            return k8s_context
+        revisions = self._k8s_client.get_deployment_revisions(
+            namespace, deployment, limit=_ROLLOUT_HISTORY_LIMIT
+        )
+        if revisions is None:
+            return replace(
+                k8s_context,
+                warnings=[
+                    *k8s_context.warnings,
+                    f"failed to read rollout history of deployment {namespace}/{deployment}",
+                ],
+            )
+        rollouts = find_recent_rollouts(
+            deployment,
+            revisions,
+            request.alert.starts_at or datetime.now(timezone.utc),
+            self._rollout_window_minutes,
+        )
+        return replace(k8s_context, recent_rollouts=rollouts) if rollouts else k8s_context
+
+    def _attach_archived_events(
+        self, request: AlertAnalysisRequest, k8s_context: K8sContext
+    ) -> K8sContext:
+        """Archived events of the alerting pod or workload that the cluster no longer holds."""
+        namespace = k8s_context.namespace
+        # The archive holds the agent's own cluster as its ServiceAccount sees it.
+        if self._event_archive is None or not namespace or _request_scoped_kubernetes():
+            return k8s_context
+        starts_at = request.alert.starts_at or datetime.now(timezone.utc)
+        try:
+            archived = self._event_archive.list_events(
+                namespace,
+                involved_name=k8s_context.pod_name,
+                involved_name_prefix=k8s_context.workload,
+                since=starts_at - timedelta(minutes=self._event_archive_lookback_minutes),
+                until=request.alert.ends_at,
+                limit=_ARCHIVED_EVENT_LIMIT,
+            )
+        except Exception as exc:  # noqa: BLE001
+            self._logger.warning("Failed to read archived events of %s: %s", namespace, exc)
+            return replace(
+                k8s_context, warnings=[*k8s_context.warnings, "failed to read archived events"]
+            )
+        events = merge_archived_events(k8s_context.events, archived)
+        if len(events) == len(k8s_context.events):
+            return k8s_context
+        return replace(k8s_context, events=events)
+
+    def _attach_resource_usage(self, k8s_context: K8sContext) -> K8sContext:
+        """Current pod and node CPU/memory usage from metrics.k8s.io."""
+        updates: dict[str, object] = {}
+        if k8s_context.pod_metrics is None and k8s_context.namespace and k8s_context.pod_name:
+            pod_metrics = self._k8s_client.get_pod_metrics(
+                k8s_context.namespace, k8s_context.pod_name
+            )
+            if pod_metrics:
+                updates["pod_metrics"] = pod_metrics
+        node_name = (
+            k8s_context.pod_status.node_name
+            if k8s_context.pod_status is not None
+            else (k8s_context.node_status or {}).get("name")
+        )
+        if k8s_context.node_metrics is None and isinstance(node_name, str) and node_name:
+            node_metrics = self._k8s_client.get_node_resource_usage(node_name)
+            if node_metrics:
+                updates["node_metrics"] = node_metrics
+        return replace(k8s_context, **updates) if updates else k8s_context
+
+    def _attach_hpa_status(self, k8s_context: K8sContext) -> K8sContext:
+        """HorizontalPodAutoscaler of the alert's workload (or the pod's owner), if any."""
+        namespace = k8s_context.namespace
+        if (
+            k8s_context.hpa_status is not None
+            or not namespace
+            or not (k8s_context.workload or k8s_context.pod_name)
+        ):
+            return k8s_context
+        status = self._k8s_client.get_hpa_status(
+            namespace, workload=k8s_context.workload, pod_name=k8s_context.pod_name
+        )
+        return replace(k8s_context, hpa_status=status) if status else k8s_context
+
+    def _attach_network_connectivity(
+        self, request: AlertAnalysisRequest, k8s_context: K8sContext
+    ) -> K8sContext:
+        """NetworkPolicies between the alerting pod and the service it fails to reach."""
+        target = connectivity_target(request.alert.labels, k8s_context)
+        if k8s_context.network_connectivity is not None or target is None:
+            return k8s_context
+        connectivity = self._k8s_client.get_network_connectivity(
+            str(k8s_context.namespace),
+            str(k8s_context.pod_name),
+            destination_namespace=str(target["namespace"]),
+            destination_service=str(target["service"]),
+        )
+        if connectivity is None:
+            return replace(
+                k8s_context,
+                warnings=[*k8s_context.warnings, "failed to list networkpolicies"],
+            )
+        return replace(k8s_context, network_connectivity={**connectivity, "port": target["port"]})
+
+    def _attach_service_endpoints(
+        self, request: AlertAnalysisRequest, k8s_context: K8sContext
+    ) -> K8sContext:
+        """EndpointSlices and selected pods of the Service a 5xx/unreachable alert is about."""
+        target = endpoints_target(request.alert.labels, k8s_context)
+        if k8s_context.service_endpoints is not None or target is None:
+            return k8s_context
+        endpoints = self._k8s_client.get_service_endpoints(target["namespace"], target["service"])
+        if endpoints is None:
+            return replace(
+                k8s_context,
+                warnings=[*k8s_context.warnings, "failed to list endpointslices"],
+            )
+        return replace(k8s_context, service_endpoints=endpoints)
+
+    def _attach_job_run(
+        self, request: AlertAnalysisRequest, k8s_context: K8sContext
+    ) -> K8sContext:
+        """Conditions, pods and last logs of the Job or CronJob a Job alert is about."""
+        target = job_target(request.alert.labels, k8s_context)
+        if k8s_context.job_run is not None or target is None:
+            return k8s_context
+        job_run = self._k8s_client.get_job_run(
+            target["namespace"], job=target.get("job"), cron_job=target.get("cron_job")
+        )
+        if job_run is None:
+            name = f"{target['namespace']}/{target.get('job') or target.get('cron_job')}"
+            return replace(
+                k8s_context, warnings=[*k8s_context.warnings, f"failed to read job {name}"]
+            )
+        return replace(k8s_context, job_run=job_run)
+
+    def _attach_cluster_dns(
+        self, request: AlertAnalysisRequest, k8s_context: K8sContext
+    ) -> K8sContext:
+        """CoreDNS endpoints, pods and recent log errors for DNS-failure alerts."""
+        trigger = dns_alert(request.alert.labels, request.alert.annotations, k8s_context)
+        if k8s_context.cluster_dns is not None or trigger is None:
+            return k8s_context
+        status = self._k8s_client.get_cluster_dns_status(
+            self._cluster_dns_namespace,
+            self._cluster_dns_service,
+            since_seconds=self._cluster_dns_lookback_seconds,
+        )
+        if status is None:
+            return replace(
+                k8s_context,
+                warnings=[*k8s_context.warnings, "failed to read cluster DNS status"],
+            )
+        return replace(k8s_context, cluster_dns={**status, "trigger": trigger})
+
+    def _attach_resource_quotas(
+        self, request: AlertAnalysisRequest, k8s_context: K8sContext
+    ) -> K8sContext:
+        """ResourceQuotas and LimitRanges of the namespace when pod creation is rejected."""
+        trigger = quota_alert(request.alert.labels, k8s_context)
+        namespace = k8s_context.namespace
+        if k8s_context.resource_quotas is not None or trigger is None or not namespace:
+            return k8s_context
+        quotas = self._k8s_client.get_namespace_quotas(namespace)
+        if quotas is None:
+            return replace(
+                k8s_context,
+                warnings=[*k8s_context.warnings, f"failed to read resource quotas of {namespace}"],
+            )
+        return replace(k8s_context, resource_quotas={**quotas, "trigger": trigger})
+
+    def _attach_namespace_snapshot(self, k8s_context: K8sContext) -> K8sContext:
+        """Workload and failing pod health of the namespace when the alert names no pod."""
+        namespace = k8s_context.namespace
+        if not self._namespace_snapshot_enabled or k8s_context.pod_name or not namespace:
+            return k8s_context
+        if k8s_context.namespace_snapshot is not None:
+            return k8s_context
+        snapshot = self._k8s_client.get_namespace_snapshot(
+            namespace,
+            max_workloads=self._namespace_snapshot_max_workloads,
+            max_pods=self._namespace_snapshot_max_pods,
+        )
+        if snapshot is None:
+            warning = f"failed to read namespace snapshot of {namespace}"
+            return replace(k8s_context, warnings=[*k8s_context.warnings, warning])
+        return replace(
+            k8s_context,
+            namespace_snapshot={**snapshot, "max_events": self._namespace_snapshot_max_events},
+            warnings=[*k8s_context.warnings, *cast(list[str], snapshot.get("warnings") or [])],
+        )
+
+    def _attach_volume_claims(
+        self, request: AlertAnalysisRequest, k8s_context: K8sContext
+    ) -> K8sContext:
+        """PVCs of storage alerts and of pods waiting on volumes, with capacity usage."""
+        namespace = k8s_context.namespace
+        names = storage_claim_names(request.alert.labels, k8s_context)
+        if k8s_context.volume_claims or not namespace or not names:
+            return k8s_context
+        warnings = list(k8s_context.warnings)
+        claims: list[dict[str, object]] = []
+        for name in names:
+            claim = self._k8s_client.get_volume_claim_status(namespace, name)
+            if claim is None:
+                warnings.append(f"failed to read persistentvolumeclaim {namespace}/{name}")
+                continue
+            claims.append(claim)
+        usage: dict[object, dict[str, object]] = {}
+        for node in volume_stats_nodes(k8s_context, claims):
+            for item in self._k8s_client.get_volume_stats(node) or []:
+                if item.get("namespace") == namespace:
+                    usage[item.get("claim")] = item
+        claims = [{**claim, "usage": usage.get(claim.get("name"))} for claim in claims]
+        return replace(k8s_context, volume_claims=claims, warnings=warnings)
+
+    def _check_cloud_incidents(
+        self, request: AlertAnalysisRequest
+    ) -> tuple[list[dict[str, object]], list[str]]:
+        """Status page incidents in the cluster's region that were ongoing at ``startsAt``."""
+        if self._cloud_status is None:
+            return [], []
+        incidents, errors = self._cloud_status.active_incidents()
+        matched = match_cloud_incidents(
+            incidents,
+            region=self._cloud_status.region,
+            services=self._cloud_status.services,
+            started_at=request.alert.starts_at or datetime.now(timezone.utc),
+        )
+        warnings = [f"cloud status feed unavailable: {error}" for error in errors]
+        return matched, warnings
+
+    def _lookup_service_catalog(
+        self, request: AlertAnalysisRequest, target: AnalysisTarget
+    ) -> tuple[dict[str, object] | None, list[str]]:
+        """Catalog entry of the alerted service: tier, owner, dependencies and runbooks."""
+        if self._service_catalog is None:
+            return None, []
+        labels = request.alert.labels
+        service = next(
+            (
+                labels[label].strip()
+                for label in self._service_catalog.service_labels
+                if labels.get(label, "").strip()
+            ),
+            None,
+        ) or (target.service_name or target.workload)
+        if not service:
+            return None, []
+        try:
+            entry = self._service_catalog.lookup(service, target.namespace)
+        except Exception as exc:  # noqa: BLE001
+            self._logger.warning("Service catalog lookup failed for %s: %s", service, exc)
+            return None, [f"service catalog unavailable: {exc}"]
+        if entry is None:
+            return None, [f"service {service} not found in the service catalog"]
+        return entry, []
+
+    def _load_namespace_config(
+        self, request: AlertAnalysisRequest, k8s_context: K8sContext
+    ) -> tuple[dict[str, object] | None, list[str]]:
+        """Team settings of the RCAConfigs in the alert's namespace that match the alert."""
+        namespace = k8s_context.namespace
+        if not self._namespace_config_enabled or not namespace:
+            return None, []
+        items = self._k8s_client.list_rca_configs(namespace)
+        return resolve_namespace_config(items, request.alert.labels)
+
+    def _collect_kube_state(
+        self, request: AlertAnalysisRequest, k8s_context: K8sContext
+    ) -> tuple[dict[str, object] | None, list[str]]:
+        """kube-state-metrics snapshot of the alerted pod and workload at ``startsAt``."""
+        namespace = k8s_context.namespace
+        if self._kube_state is None or not namespace:
+            return None, []
+        if not (k8s_context.pod_name or k8s_context.workload):
+            return None, []
+        try:
+            snapshot = self._kube_state.snapshot(
+                namespace,
+                pod_name=k8s_context.pod_name,
+                workload=k8s_context.workload,
+                at=request.alert.starts_at,
+            )
+        except Exception as exc:  # noqa: BLE001
+            self._logger.warning("kube-state-metrics query failed for %s: %s", namespace, exc)
+            return None, [f"kube-state-metrics unavailable: {exc}"]
+        if snapshot is None:
+            return None, [f"no kube-state-metrics series for the alerted objects in {namespace}"]
+        return snapshot, []
+
+    def _check_node_maintenance(
+        self, request: AlertAnalysisRequest, k8s_context: K8sContext
+    ) -> dict[str, object] | None:
+        """Maintenance status of the alert's node when a disruption alert hits planned work."""
+        if not self._maintenance_awareness or request.alert.status != "firing":
+            return None
+        if request.alert.labels.get("alertname") not in self._maintenance_disruption_alerts:
+            return None
+        node_name = request.alert.labels.get("node") or (
+            k8s_context.pod_status.node_name if k8s_context.pod_status else None
+        )
+        if not node_name:
+            return None
+        status = self._k8s_client.get_node_maintenance_status(node_name)
+        if not status or status.get("in_maintenance") is not True:
+            return None
+        self._logger.info(
+            "expected_disruption alertname=%s node=%s",
+            request.alert.labels.get("alertname"),
+            node_name,
+        )
+        return status
+
+    def _validate_recovery(
+        self, session_key: str, suspected_cause: str, recovery_summary: str
+    ) -> dict[str, str] | None:
+        if self._analysis_engine is None:
+            return None
+        prompt = _build_recovery_validation_prompt(suspected_cause, recovery_summary, self._masker)
+        # A fresh session per closure, so verdicts never see earlier incidents of the alert.
+        session_id = f"{session_key}:closure:{uuid4().hex[:8]}"
+        try:
+            answer = self._analysis_engine.analyze(prompt, session_id)
+        except Exception as exc:  # noqa: BLE001
+            self._logger.warning("Recovery validation failed: %s", exc)
+            return None
+        return _parse_recovery_validation(
+            self._masker.mask_text(answer if isinstance(answer, str) else "")
+        )
+
+    def _store_analysis(
+        self,
+        request: AlertAnalysisRequest,
+        result: tuple[str, str, str, dict[str, object], list[dict[str, object]]],
+        *,
+        source: str,
+        backfill_job_id: str | None = None,
+    ) -> int | None:
+        if self._analysis_store is None:
+            return None
+        analysis, summary, detail, context, artifacts = result
+        analysis_id = context.get("analysis_id")
+        try:
+            return self._analysis_store.record(
+                StoredAnalysis(
+                    session_key=_resolve_alert_session_id(request),
+                    analysis_id=analysis_id if isinstance(analysis_id, str) else None,
+                    correlation_id=cast(str | None, context.get("correlation_id")),
+                    alertname=request.alert.labels.get("alertname"),
+                    namespace=cast(str | None, context.get("namespace")),
+                    fingerprint=request.alert.fingerprint,
+                    incident_id=request.incident_id,
+                    alert_status=request.alert.status,
+                    pipeline_version=self._pipeline_version,
+                    source=source,
+                    request=cast(
+                        dict[str, Any],
+                        self._masker.mask_object(request.model_dump(mode="json", by_alias=True)),
+                    ),
+                    result={
+                        "analysis": analysis,
+                        "analysis_summary": summary,
+                        "analysis_detail": detail,
+                        "context": context,
+                        "artifacts": artifacts,
+                    },
+                    backfill_job_id=backfill_job_id,
+                )
+            )
+        except Exception as exc:  # noqa: BLE001
+            self._logger.warning("Failed to store analysis history: %s", exc)
+            return None
+
+    def _analyze(
+        self,
+        request: AlertAnalysisRequest,
+        *,
+        canary_arm: bool = False,
+        backfill: bool = False,
+        pipeline: AnalysisPipeline = DEFAULT_PIPELINE,
     ) -> tuple[str, str, str, dict[str, object], list[dict[str, object]]]:
         t_start = time.perf_counter()
+        # Correlation ahead of the LLM (or without it) feeds the prompt; after it,
+        # it only enriches the returned context.
+        correlate_early = pipeline.runs_before(STAGE_CORRELATE, STAGE_LLM)
+        late_correlation = pipeline.runs(STAGE_CORRELATE) and not correlate_early
 
         target = resolve_alert_target(request.alert.labels)
         t_resolve = time.perf_counter()
@@ -68,12 +930,38 @@ class AnalysisService:
             target.workload,
             service_name=target.service_name,
         )
+        k8s_context = self._attach_archived_events(request, k8s_context)
+        k8s_context = self._attach_node_status(request, k8s_context)
+        if correlate_early:
+            k8s_context = self._attach_recent_rollouts(request, k8s_context)
+        k8s_context = self._attach_resource_usage(k8s_context)
+        k8s_context = self._attach_volume_claims(request, k8s_context)
+        k8s_context = self._attach_hpa_status(k8s_context)
+        k8s_context = self._attach_network_connectivity(request, k8s_context)
+        k8s_context = self._attach_service_endpoints(request, k8s_context)
+        k8s_context = self._attach_job_run(request, k8s_context)
+        k8s_context = self._attach_cluster_dns(request, k8s_context)
+        k8s_context = self._attach_resource_quotas(request, k8s_context)
+        k8s_context = self._attach_namespace_snapshot(k8s_context)
         t_k8s = time.perf_counter()
 
         tempo_context = self._collect_tempo_context(request, target)
+        service_catalog, catalog_warnings = self._lookup_service_catalog(request, target)
+        kube_state, kube_state_warnings = self._collect_kube_state(request, k8s_context)
+        namespace_config, namespace_config_warnings = self._load_namespace_config(
+            request, k8s_context
+        )
+        cloud_incidents, cloud_warnings = (
+            self._check_cloud_incidents(request) if correlate_early else ([], [])
+        )
         t_tempo = time.perf_counter()
 
-        artifacts = _build_alert_artifacts(k8s_context, tempo_context)
+        # Offloaded artifacts carry the full logs; inline ones stay short.
+        artifacts = _build_alert_artifacts(
+            k8s_context,
+            tempo_context,
+            max_log_lines=None if self._artifact_offloader is not None else 20,
+        )
         masked_artifacts = cast(list[dict[str, object]], self._masker.mask_object(artifacts))
         capabilities, capability_warnings = self._collect_capabilities(
             k8s_context=k8s_context,
@@ -87,9 +975,24 @@ class AnalysisService:
         base_warnings = _collect_analysis_warnings(
             k8s_warnings=k8s_context.warnings,
             tempo_context=tempo_context,
-            capability_warnings=capability_warnings,
+            capability_warnings=[
+                *capability_warnings,
+                *catalog_warnings,
+                *kube_state_warnings,
+                *namespace_config_warnings,
+                *cloud_warnings,
+            ],
         )
 
+        def correlate_after_llm() -> None:
+            nonlocal k8s_context, cloud_incidents, base_warnings, late_correlation
+            if not late_correlation:
+                return
+            late_correlation = False
+            k8s_context = self._attach_recent_rollouts(request, k8s_context)
+            cloud_incidents, late_warnings = self._check_cloud_incidents(request)
+            base_warnings = _dedupe_strings([*base_warnings, *late_warnings])
+
         def build_masked_context(engine_issue: str | None = None) -> dict[str, object]:
             missing_data = list(base_missing_data)
             if engine_issue:
@@ -110,19 +1013,86 @@ class AnalysisService:
             context = k8s_context.to_dict()
             if tempo_context:
                 context["tempo"] = tempo_context
+            for key, build in _CONTEXT_ANALYSES:
+                built = build(k8s_context)
+                if built is not None:
+                    context[key] = built
+            if service_catalog is not None:
+                context["service_catalog"] = service_catalog
+            if namespace_config is not None:
+                context["namespace_config"] = namespace_config
+            if kube_state is not None:
+                context["kube_state"] = kube_state
+            if cloud_incidents:
+                context["cloud_incidents"] = cloud_incidents
             context["analysis_quality"] = analysis_quality
             context["missing_data"] = missing_data
             context["warnings"] = warnings
             context["capabilities"] = capabilities
+            # LLM 없이 규칙 기반 분석만 수행된 결과임을 명시
+            context["degraded"] = engine_issue is not None
+            if engine_issue:
+                context["degraded_reason"] = engine_issue
             return cast(dict[str, object], self._masker.mask_object(context))
 
-        if self._analysis_engine is None:
+        def build_degraded_result(
+            reason: str, engine_issue: str
+        ) -> tuple[str, str, str, dict[str, object], list[dict[str, object]]]:
+            correlate_after_llm()
+            findings = run_rule_analyzers(k8s_context) if pipeline.runs(STAGE_RULES) else []
+            if not backfill:
+                self._record_analysis(request, k8s_context, findings, degraded=True)
             analysis = self._masker.mask_text(
-                _fallback_summary(request, k8s_context, "analysis engine not configured")
+                _fallback_summary(
+                    request,
+                    k8s_context,
+                    reason,
+                    findings,
+                    cloud_incidents=cloud_incidents,
+                    service_catalog=service_catalog,
+                    kube_state=kube_state,
+                    namespace_config=namespace_config,
+                )
+            )
+            _, detail = _split_alert_analysis(analysis)
+            summary = self._masker.mask_text(_degraded_summary(findings, reason))
+            rule_artifacts = cast(
+                list[dict[str, object]],
+                self._masker.mask_object(_build_rule_artifacts(findings)),
+            )
+            return (
+                analysis,
+                summary,
+                detail,
+                build_masked_context(engine_issue=engine_issue),
+                [*masked_artifacts, *rule_artifacts],
             )
+
+        maintenance = (
+            self._check_node_maintenance(request, k8s_context) if correlate_early else None
+        )
+        if maintenance is not None:
+            if not backfill:
+                self._record_analysis(request, k8s_context, [], degraded=False)
+            analysis = self._masker.mask_text(_maintenance_analysis(request, maintenance))
             summary, detail = _split_alert_analysis(analysis)
-            masked_context = build_masked_context(engine_issue="not_configured")
-            return analysis, summary, detail, masked_context, masked_artifacts
+            context = build_masked_context()
+            context["expected_disruption"] = True
+            context["maintenance"] = self._masker.mask_object(maintenance)
+            return analysis, summary, detail, context, masked_artifacts
+
+        if not pipeline.runs(STAGE_LLM):
+            return build_degraded_result(
+                f"LLM stage disabled by analysis pipeline profile {pipeline.profile}",
+                "llm_disabled",
+            )
+        if self._analysis_engine is None:
+            return build_degraded_result("analysis engine not configured", "not_configured")
+        engine = self._analysis_engine
+        prompt_instructions: str | None = None
+        if canary_arm and self._canary is not None:
+            engine = self._canary.engine or engine
+            prompt_instructions = self._canary.prompt_instructions
 
         summary_key = _resolve_alert_session_id(request)
         recent_summaries = self._load_recent_summaries(summary_key)
@@ -131,10 +1101,36 @@ class AnalysisService:
         analysis_type = request.analysis_type or request.alert.status
         effective_max_log_lines = self._prompt_max_log_lines
         effective_max_events = self._prompt_max_events
+        effective_token_budget = self._prompt_token_budget
         if analysis_type == "resolved" and request.previous_analysis is not None:
             effective_max_log_lines = max(1, self._prompt_max_log_lines // 2)
             effective_max_events = max(1, self._prompt_max_events // 2)
 
+        # 메모리 압박 시 프롬프트에 포함되는 증거량 축소
+        if self._memory_monitor is not None and self._memory_monitor.enabled:
+            memory_level = self._memory_monitor.level()
+            if memory_level != MEMORY_LEVEL_NORMAL:
+                memory_scale = self._memory_monitor.scale()
+                effective_max_log_lines = scale_limit(effective_max_log_lines, memory_scale)
+                effective_max_events = scale_limit(effective_max_events, memory_scale)
+                effective_token_budget = scale_limit(effective_token_budget, memory_scale)
+                base_warnings = _dedupe_strings(
+                    [*base_warnings, f"evidence limits reduced (memory pressure {memory_level})"]
+                )
+
+        # The team's analysisDepth scales the evidence on top of the global limits.
+        scale = depth_scale(namespace_config)
+        if scale != 1.0:
+            effective_max_log_lines = scale_evidence_limit(effective_max_log_lines, scale)
+            effective_max_events = scale_evidence_limit(effective_max_events, scale)
+            effective_token_budget = scale_evidence_limit(effective_token_budget, scale)
+
+        # Rule findings ahead of the LLM are handed to it as leads.
+        rule_findings = (
+            run_rule_analyzers(k8s_context)
+            if pipeline.runs_before(STAGE_RULES, STAGE_LLM)
+            else []
+        )
         prompt = _build_prompt(
             request,
             k8s_context,
@@ -146,31 +1142,51 @@ class AnalysisService:
             base_missing_data,
             base_warnings,
             recent_summaries,
-            self._prompt_token_budget,
+            effective_token_budget,
             effective_max_log_lines,
             effective_max_events,
             self._masker,
+            prompt_instructions=prompt_instructions,
+            cloud_incidents=cloud_incidents,
+            service_catalog=service_catalog,
+            kube_state=kube_state,
+            rule_findings=rule_findings,
+            namespace_config=namespace_config,
         )
         t_prompt = time.perf_counter()
 
         try:
             session_id = _build_runtime_session_id(summary_key)
-            analysis = self._analysis_engine.analyze(prompt, session_id)
+            hypotheses: list[dict[str, object]] | None = None
+            quick = (namespace_config or {}).get("analysis_depth") == DEPTH_QUICK
+            # Collection used up the deadline: deliver the rule-based findings instead.
+            check_deadline()
+            remaining = remaining_seconds()
+            if self._hypothesis_investigator is not None and not quick:
+                # Under a deadline the branches get at most half of the remaining time,
+                # leaving the rest to the final analysis.
+                hypotheses = self._masker.mask_object(
+                    self._hypothesis_investigator.investigate(
+                        engine,
+                        prompt,
+                        session_id,
+                        timeout_seconds=(
+                            None if remaining is None else remaining * _HYPOTHESIS_DEADLINE_SHARE
+                        ),
+                    )
+                )
+                prompt += format_ranked_hypotheses(hypotheses or [])
+                check_deadline()
+            analysis = engine.analyze(prompt, session_id)
             t_llm = time.perf_counter()
             if not isinstance(analysis, str):
                 analysis = ""
             analysis = self._masker.mask_text(analysis)
             if not analysis.strip():
                 self._logger.warning("Strands analysis returned empty response")
-                analysis = self._masker.mask_text(
-                    _fallback_summary(
-                        request,
-                        k8s_context,
-                        "analysis engine returned empty response",
-                    )
+                degraded = build_degraded_result(
+                    "analysis engine returned empty response", "empty_response"
                 )
-                summary, detail = _split_alert_analysis(analysis)
-                masked_context = build_masked_context(engine_issue="empty_response")
                 self._log_analysis_timing(
                     t_start,
                     t_resolve,
@@ -179,10 +1195,34 @@ class AnalysisService:
                     t_prompt,
                     t_llm,
                 )
-                return analysis, summary, detail, masked_context, masked_artifacts
+                return degraded
             summary, detail = _split_alert_analysis(analysis)
-            self._store_summary(summary_key, summary)
+            correlate_after_llm()
+            if not backfill:
+                if pipeline.runs(STAGE_DELIVER):
+                    self._store_summary(summary_key, summary, namespace=k8s_context.namespace)
+                self._record_analysis(request, k8s_context, rule_findings or None, degraded=False)
             masked_context = build_masked_context()
+            # The runtime session keeps the prompt and tool calls of this run,
+            # which is what follow-up questions continue from.
+            masked_context["analysis_id"] = session_id
+            if hypotheses:
+                masked_context["hypotheses"] = hypotheses
+            # Shadow runs outlive the request, and with it the caller's token and cluster.
+            if (
+                self._shadow_runner is not None
+                and not backfill
+                and not _request_scoped_kubernetes()
+            ):
+                self._shadow_runner.submit(
+                    prompt,
+                    analysis_id=session_id,
+                    session_key=summary_key,
+                    alertname=request.alert.labels.get("alertname"),
+                    namespace=k8s_context.namespace,
+                    primary_analysis=analysis,
+                    primary_ms=(t_llm - t_prompt) * 1000,
+                )
             self._log_analysis_timing(
                 t_start,
                 t_resolve,
@@ -191,7 +1231,29 @@ class AnalysisService:
                 t_prompt,
                 t_llm,
             )
-            return analysis, summary, detail, masked_context, masked_artifacts
+            rule_artifacts = cast(
+                list[dict[str, object]],
+                self._masker.mask_object(_build_rule_artifacts(rule_findings)),
+            )
+            return analysis, summary, detail, masked_context, [*masked_artifacts, *rule_artifacts]
+        except DeadlineExceeded:
+            t_llm = time.perf_counter()
+            self._logger.warning("Analysis time-boxed by its latency SLO: session=%s", summary_key)
+            # Late correlation would only hit the passed deadline again.
+            late_correlation = False
+            time_boxed = build_degraded_result(
+                "analysis time-boxed by its latency SLO", "time_boxed"
+            )
+            time_boxed[3]["time_boxed"] = True
+            self._log_analysis_timing(
+                t_start,
+                t_resolve,
+                t_k8s,
+                t_tempo,
+                t_prompt,
+                t_llm,
+            )
+            return time_boxed
         except Exception as exc:  # noqa: BLE001
             t_llm = time.perf_counter()
             error_cat = _categorize_analysis_error(exc)
@@ -201,11 +1263,7 @@ class AnalysisService:
                 type(exc).__name__,
                 exc,
             )
-            analysis = self._masker.mask_text(
-                _fallback_summary(request, k8s_context, error_cat.user_message)
-            )
-            summary, detail = _split_alert_analysis(analysis)
-            masked_context = build_masked_context(engine_issue=error_cat.name)
+            degraded = build_degraded_result(error_cat.user_message, error_cat.name)
             self._log_analysis_timing(
                 t_start,
                 t_resolve,
@@ -214,7 +1272,45 @@ class AnalysisService:
                 t_prompt,
                 t_llm,
             )
-            return analysis, summary, detail, masked_context, masked_artifacts
+            return degraded
+        finally:
+            # Degraded runs store no summary, so deletions find their sessions by this tag.
+            self._tag_session_namespace(summary_key, k8s_context.namespace)
+
+    def _time_boxed_result(
+        self, request: AlertAnalysisRequest
+    ) -> tuple[str, str, str, dict[str, object], list[dict[str, object]]]:
+        """Degraded result of an analysis whose deadline passed before the LLM stage."""
+        reason = "analysis time-boxed by its latency SLO"
+        self._logger.warning("Analysis time-boxed before its evidence was collected")
+        target = resolve_alert_target(request.alert.labels)
+        k8s_context = K8sContext(
+            namespace=target.namespace,
+            pod_name=target.pod_name,
+            workload=target.workload,
+            pod_status=None,
+            events=[],
+            previous_logs=[],
+            warnings=[],
+            target=target,
+        )
+        analysis = self._masker.mask_text(_fallback_summary(request, k8s_context, reason))
+        _, detail = _split_alert_analysis(analysis)
+        context = k8s_context.to_dict()
+        context["analysis_quality"] = "low"
+        context["missing_data"] = ["analysis_engine.time_boxed"]
+        context["warnings"] = ["analysis engine issue: time_boxed"]
+        context["capabilities"] = {}
+        context["degraded"] = True
+        context["degraded_reason"] = "time_boxed"
+        context["time_boxed"] = True
+        return (
+            analysis,
+            self._masker.mask_text(_degraded_summary([], reason)),
+            detail,
+            cast(dict[str, object], self._masker.mask_object(context)),
+            [],
+        )
 
     def _log_analysis_timing(
         self,
@@ -264,6 +1360,190 @@ class AnalysisService:
                 _fallback_incident_summary(request, f"analysis failed: {exc}")
             )
 
+    def follow_up(self, analysis_id: str, request: AnalysisFollowupRequest) -> str:
+        """Answer a follow-up question by continuing a stored analysis session.
+
+        Raises:
+            ValueError: when *analysis_id* is not an analysis run id.
+            AnalysisNotFoundError: when the session is not (or no longer) stored.
+        """
+        analysis_id = _require_analysis_id(analysis_id)
+        if self._analysis_engine is None:
+            return self._masker.mask_text(
+                "Follow-up is unavailable because the analysis engine is not configured."
+            )
+        self._require_stored_session(analysis_id)
+
+        prompt = _build_followup_prompt(request, self._masker)
+        try:
+            with self._session_access(analysis_id, request.cluster_credentials):
+                answer = self._analysis_engine.analyze(prompt, analysis_id)
+        except (ClusterCredentialError, UnknownCluster):
+            raise
+        except Exception:  # noqa: BLE001
+            self._logger.exception("Follow-up analysis failed: analysis_id=%s", analysis_id)
+            return self._masker.mask_text(
+                "An error occurred while continuing the analysis. Please try again shortly."
+            )
+        if not isinstance(answer, str):
+            answer = ""
+        return self._masker.mask_text(answer).strip() or (
+            "I couldn't generate an answer. Please try rephrasing the question."
+        )
+
+    def open_investigation(self, analysis_id: str) -> dict[str, object]:
+        """Check the stored session of an interactive investigation and describe it.
+
+        Raises:
+            ValueError: when *analysis_id* is not an analysis run id.
+            AnalysisNotFoundError: when the session is not (or no longer) stored.
+            ClusterCredentialError: when the analysis ran with caller credentials,
+                which interactive sessions cannot pass.
+        """
+        analysis_id = _require_analysis_id(analysis_id)
+        self._require_stored_session(analysis_id)
+        self._session_token(analysis_id, None)
+        tool_names = getattr(self._analysis_engine, "tool_names", None)
+        return {
+            "analysis_id": analysis_id,
+            "available": self._analysis_engine is not None,
+            "tools": tool_names() if callable(tool_names) else [],
+        }
+
+    def investigate(
+        self,
+        analysis_id: str,
+        question: str,
+        on_event: Callable[[dict[str, object]], None],
+    ) -> str:
+        """Answer a session question, streaming it to *on_event* while the agent works.
+
+        *on_event* gets masked ``chunk`` events line by line and ``tool_call``
+        events; engines without streaming only return the final answer.
+        """
+        analysis_id = _require_analysis_id(analysis_id)
+        if self._analysis_engine is None:
+            return self._masker.mask_text(
+                "Investigation is unavailable because the analysis engine is not configured."
+            )
+        prompt = build_investigation_prompt(question, self._masker)
+        stream = MaskedLineStream(self._masker, on_event)
+        stream_analyze = getattr(self._analysis_engine, "stream_analyze", None)
+        try:
+            with self._session_access(analysis_id, None):
+                if callable(stream_analyze):
+                    answer = stream_analyze(prompt, analysis_id, stream.feed)
+                else:
+                    answer = self._analysis_engine.analyze(prompt, analysis_id)
+        except (ClusterCredentialError, UnknownCluster):
+            raise
+        except Exception:  # noqa: BLE001
+            self._logger.exception("Investigation answer failed: analysis_id=%s", analysis_id)
+            return self._masker.mask_text(
+                "An error occurred while continuing the analysis. Please try again shortly."
+            )
+        stream.flush()
+        if not isinstance(answer, str):
+            answer = ""
+        return self._masker.mask_text(answer).strip() or (
+            "I couldn't generate an answer. Please try rephrasing the question."
+        )
+
+    def run_investigation_tool(
+        self, analysis_id: str, tool: str, arguments: dict[str, object]
+    ) -> dict[str, object]:
+        """Run *tool* directly in the session; the call and its result join its history.
+
+        Raises:
+            ValueError: for unknown tools or an engine that cannot run tools directly.
+        """
+        analysis_id = _require_analysis_id(analysis_id)
+        run_tool = getattr(self._analysis_engine, "run_tool", None)
+        if not callable(run_tool):
+            raise ValueError("the analysis engine cannot run tools directly")
+        try:
+            with self._session_access(analysis_id, None):
+                result = run_tool(tool, arguments, analysis_id)
+        except ValueError:
+            raise
+        except Exception as exc:  # noqa: BLE001
+            self._logger.warning(
+                "Investigation tool %s failed: analysis_id=%s: %s", tool, analysis_id, exc
+            )
+            return {
+                "tool": tool,
+                "status": "error",
+                "output": self._masker.mask_text(f"tool failed: {exc}"),
+            }
+        return {
+            "tool": tool,
+            "status": str(result.get("status") or "success"),
+            "output": self._masker.mask_text(tool_result_text(result)),
+        }
+
+    def _require_stored_session(self, analysis_id: str) -> None:
+        if (
+            self._session_repository is not None
+            and self._session_repository.read_session(analysis_id) is None
+        ):
+            raise AnalysisNotFoundError(analysis_id)
+
+    def _remember_session_scope(self, analysis_id: str, scope: dict[str, object]) -> None:
+        with self._session_scopes_lock:
+            self._session_scopes[analysis_id] = scope
+            self._session_scopes.move_to_end(analysis_id)
+            while len(self._session_scopes) > _MAX_SESSION_SCOPES:
+                self._session_scopes.popitem(last=False)
+        write = getattr(self._session_repository, "write_access_scope", None)
+        if callable(write):
+            try:
+                write(analysis_id, scope)
+            except Exception as exc:  # noqa: BLE001
+                self._logger.warning("Failed to store session access scope: %s", exc)
+
+    def _tag_session_namespace(self, session_key: str, namespace: str | None) -> None:
+        write = getattr(self._session_repository, "write_session_namespace", None)
+        if not namespace or not callable(write):
+            return
+        try:
+            write(session_key, namespace)
+        except Exception as exc:  # noqa: BLE001
+            self._logger.warning("Failed to tag sessions with their namespace: %s", exc)
+
+    def _session_scope(self, analysis_id: str) -> dict[str, object]:
+        with self._session_scopes_lock:
+            scope = self._session_scopes.get(analysis_id)
+        if scope is not None:
+            return scope
+        read = getattr(self._session_repository, "read_access_scope", None)
+        return (read(analysis_id) if callable(read) else None) or {}
+
+    def _session_token(
+        self, analysis_id: str, credentials: ClusterCredentials | None
+    ) -> str | None:
+        """Caller token to continue a session with, ``None`` for the agent's ServiceAccount.
+
+        A session started with caller credentials is only continued with caller
+        credentials again, never with the agent's ServiceAccount.
+        """
+        token = resolve_cluster_token(credentials)
+        if token is None and self._session_scope(analysis_id).get("credentials") == "caller":
+            raise ClusterCredentialError(
+                "the analysis ran with caller credentials; "
+                "continuing it requires cluster_credentials"
+            )
+        return token
+
+    @contextmanager
+    def _session_access(
+        self, analysis_id: str, credentials: ClusterCredentials | None
+    ) -> Iterator[None]:
+        """Run session tools in the cluster and with the access the analysis ran with."""
+        token = self._session_token(analysis_id, credentials)
+        cluster = self._session_scope(analysis_id).get("cluster")
+        with use_cluster(cluster if isinstance(cluster, str) else None), use_cluster_token(token):
+            yield
+
     def _mask_incident_result(self, result: tuple[str, str, str]) -> tuple[str, str, str]:
         title, summary, detail = result
         return (
@@ -282,7 +1562,7 @@ class AnalysisService:
             self._logger.warning("Failed to load session summaries: %s", exc)
             return []
 
-    def _store_summary(self, session_id: str, summary: str) -> None:
+    def _store_summary(self, session_id: str, summary: str, namespace: str | None = None) -> None:
         if self._summary_store is None or self._summary_history_size <= 0:
             return
         masked_summary = self._masker.mask_text(summary)
@@ -294,10 +1574,44 @@ class AnalysisService:
                 session_id,
                 compact,
                 max_items=self._summary_history_size,
+                namespace=namespace,
             )
         except Exception as exc:  # noqa: BLE001
             self._logger.warning("Failed to store session summary: %s", exc)
 
+    def _record_analysis(
+        self,
+        request: AlertAnalysisRequest,
+        k8s_context: K8sContext,
+        findings: list[RuleFinding] | None = None,
+        *,
+        degraded: bool,
+    ) -> None:
+        if self._ledger is None:
+            return
+        if findings is None:
+            findings = run_rule_analyzers(k8s_context)
+        labels = request.alert.labels
+        starts_at, ends_at = request.alert.starts_at, request.alert.ends_at
+        duration_seconds = None
+        if starts_at is not None and ends_at is not None:
+            with suppress(TypeError):  # naive vs aware timestamps
+                duration_seconds = max(0.0, (ends_at - starts_at).total_seconds()) or None
+        self._ledger.record(
+            AnalysisRecord(
+                recorded_at=datetime.now(timezone.utc),
+                alertname=labels.get("alertname") or "unknown",
+                namespace=k8s_context.namespace,
+                severity=labels.get("severity"),
+                status=request.analysis_type or request.alert.status,
+                incident_id=request.incident_id,
+                fingerprint=request.alert.fingerprint,
+                root_causes=tuple(dict.fromkeys(finding.rule for finding in findings)),
+                degraded=degraded,
+                duration_seconds=duration_seconds,
+            )
+        )
+
     def _collect_tempo_context(
         self,
         request: AlertAnalysisRequest,
@@ -368,6 +1682,16 @@ class AnalysisService:
             "tempo": "ok" if self._tempo_enabled else "unavailable",
             "mesh_type": _resolve_mesh_type(k8s_context),
             "routing_evidence": "unavailable",
+            "infra_changes": "ok" if self._infra_changes_enabled else "unavailable",
+            "image_metadata": "ok" if self._image_metadata_enabled else "unavailable",
+            "code_changes": "ok" if self._code_changes_enabled else "unavailable",
+            "datastore_health": "ok" if self._datastore_health_enabled else "unavailable",
+            "kafka_lag": "ok" if self._kafka_lag_enabled else "unavailable",
+            "endpoint_probe": "ok" if self._endpoint_probe_enabled else "unavailable",
+            "observability_events": "ok" if self._observability_enabled else "unavailable",
+            "cloud_status": "ok" if self._cloud_status is not None else "unavailable",
+            "service_catalog": "ok" if self._service_catalog is not None else "unavailable",
+            "kube_state_metrics": "ok" if self._kube_state is not None else "unavailable",
         }
         warnings: list[str] = []
         if any(
@@ -403,6 +1727,13 @@ def _build_prompt(
     prompt_max_log_lines: int,
     prompt_max_events: int,
     masker: Masker,
+    *,
+    prompt_instructions: str | None = None,
+    cloud_incidents: list[dict[str, object]] | None = None,
+    service_catalog: dict[str, object] | None = None,
+    kube_state: dict[str, object] | None = None,
+    rule_findings: list[RuleFinding] | None = None,
+    namespace_config: dict[str, object] | None = None,
 ) -> str:
     alert_payload = cast(
         dict[str, Any],
@@ -425,8 +1756,16 @@ def _build_prompt(
         "- get_previous_pod_logs, get_pod_logs",
         "- get_workload_status, get_daemonset_manifest, get_node_status",
         "- get_pod_metrics, get_node_metrics",
+        "- get_cluster_upgrade_status (version skew, draining/surge nodes during upgrades)",
+        "- get_node_maintenance_status (cordon, drain taints, cloud maintenance, CAPI deletion)",
+        "- find_removed_api_usage (deprecated/removed API versions in Helm releases)",
         "- get_service, get_endpoints",
         "- get_manifest, list_manifests",
+        "- get_crossplane_resource_tree (Crossplane claim/composite/managed resource conditions)",
+        "- get_keda_scaling_status (KEDA ScaledObject triggers, auth and external metrics)",
+        "- get_knative_service_status (Knative revisions, scale-to-zero, activator)",
+        "- get_velero_operation_status (Velero backup/restore failures and failed volumes)",
+        "- get_external_dns_status (external-dns provider errors and record resolution)",
     ]
     if prometheus_enabled:
         tool_lines.append("- discover_prometheus, list_prometheus_metrics")
@@ -438,6 +1777,42 @@ def _build_prompt(
         tool_lines.append("- discover_tempo, search_tempo_traces, get_tempo_trace")
     if mesh_type == "istio":
         tool_lines.append("- list_virtual_services, list_destination_rules, list_service_entries")
+    if capabilities.get("infra_changes") == "ok":
+        tool_lines.append(
+            "- list_infrastructure_changes (Terraform runs near the alert time; "
+            "check node pool, load balancer, DNS changes)"
+        )
+    if capabilities.get("image_metadata") == "ok":
+        tool_lines.append(
+            "- get_workload_image_metadata (image digest, git revision and build time; "
+            "name the exact code version and link the commit when a deploy is suspected)"
+        )
+    if capabilities.get("code_changes") == "ok":
+        tool_lines.append(
+            "- get_rollout_code_changes (commits of the latest rollout; flags dependency, "
+            "config and migration changes and partially failed CI pipelines)"
+        )
+    if capabilities.get("datastore_health") == "ok":
+        tool_lines.append(
+            "- check_datastore_health (Postgres/MySQL/Redis reachability, connection "
+            "saturation and replication lag; use when errors point at a datastore)"
+        )
+    if capabilities.get("kafka_lag") == "ok":
+        tool_lines.append(
+            "- analyze_kafka_consumer_lag (lagging partitions of a consumer group and whether "
+            "brokers or consumers are the bottleneck; use for consumergroup/topic alerts)"
+        )
+    if capabilities.get("endpoint_probe") == "ok":
+        tool_lines.append(
+            "- probe_external_endpoint (status, latency and TLS validity of the external URL "
+            "from the alert or Service/Ingress annotations; confirms user-facing impact)"
+        )
+    if capabilities.get("observability_events") == "ok":
+        tool_lines.append(
+            "- query_observability_events (high-cardinality span/event counts and latency "
+            "percentiles broken down by any attribute; use to find which route, customer or "
+            "version the errors concentrate on)"
+        )
     tool_block = "\n".join(tool_lines)
     policy_block = (
         "Analysis policy:\n"
@@ -505,6 +1880,8 @@ def _build_prompt(
             "Do NOT list data that is expectedly absent "
             "(e.g., previous_logs when restart_count is 0, "
             "or service info when no service label exists).\n"
+            "- previous_logs entries with stream=current hold the running container's logs, "
+            "because the kubelet no longer had the previous ones.\n"
             "- Prefer direct evidence from logs, events, workload state, "
             "Service, and Endpoints before adding manual follow-up actions.\n"
             "- Do not infer routing behavior or external dependency failures "
@@ -580,6 +1957,59 @@ def _build_prompt(
             "do not claim live Envoy behavior.\n\n"
         )
 
+    instructions = (
+        current_overrides().prompt_instructions
+        if prompt_instructions is None
+        else prompt_instructions
+    )
+    if instructions:
+        prompt += (
+            "Operator instructions (follow unless contradicted by evidence):\n"
+            f"{instructions}\n\n"
+        )
+
+    if service_catalog is not None:
+        prompt += (
+            "Service context (from the service catalog, see service_catalog in the context):\n"
+            "Use the tier to judge business impact and urgency, name the owning team, check "
+            "the listed dependencies as possible upstream causes, and point to the runbooks "
+            "that apply.\n\n"
+        )
+
+    if namespace_config is not None:
+        prompt += masker.mask_text(format_namespace_config(namespace_config))
+
+    kube_state_findings = cast(list[str], (kube_state or {}).get("findings") or [])
+    if kube_state_findings:
+        prompt += (
+            "Object state from kube-state-metrics (see kube_state in the context; compare it "
+            "with the live pod status, which may have changed since the alert started):\n"
+        )
+        for finding in kube_state_findings:
+            prompt += f"- {masker.mask_text(finding)}\n"
+        prompt += "\n"
+
+    if cloud_incidents:
+        prompt += (
+            "Possible upstream cloud incident:\n"
+            "The cloud provider's status page reports an ongoing incident in the cluster's "
+            "region for services the cluster depends on (see cloud_incidents in the context). "
+            "If the symptoms fit (node, network, load balancer or storage failures), mention it "
+            "as a possible upstream cause and link the status page; do not blame it without "
+            "matching evidence.\n\n"
+        )
+
+    if rule_findings:
+        prompt += (
+            "Rule-based findings (leads from deterministic checks; confirm them with the "
+            "evidence before relying on them):\n"
+        )
+        for finding in rule_findings:
+            prompt += f"- [{finding.severity}] {masker.mask_text(finding.title)}\n"
+            for evidence in finding.evidence[:3]:
+                prompt += f"  - {masker.mask_text(evidence)}\n"
+        prompt += "\n"
+
     if summary_block:
         prompt += summary_block
 
@@ -590,6 +2020,12 @@ def _build_prompt(
     )
     if tempo_context:
         context_dict["tempo"] = _compact_tempo_context(tempo_context)
+    if service_catalog is not None:
+        context_dict["service_catalog"] = service_catalog
+    if kube_state is not None:
+        context_dict["kube_state"] = kube_state
+    if cloud_incidents:
+        context_dict["cloud_incidents"] = cloud_incidents
     context_dict["capabilities"] = capabilities
     context_dict["missing_data"] = missing_data
     context_dict["diagnostic_warnings"] = diagnostic_warnings
@@ -613,6 +2049,10 @@ def _build_runtime_session_id(summary_key: str) -> str:
     return f"run:{suffix}"
 
 
+def _is_runtime_session_id(value: str) -> bool:
+    return value.startswith("run:") or ":run:" in value
+
+
 def _format_session_summaries(summaries: list[str]) -> str:
     if not summaries:
         return ""
@@ -631,11 +2071,13 @@ def _prepare_k8s_context(
     k8s_context: K8sContext, *, max_events: int, max_log_lines: int
 ) -> dict[str, object]:
     context = k8s_context.to_dict()
-    events = context.get("events") or []
-    if max_events <= 0:
-        context["events"] = []
-    else:
-        context["events"] = events[:max_events]
+    for key, build in _CONTEXT_ANALYSES:
+        if key in _RESPONSE_ONLY_ANALYSES:
+            continue
+        built = build(k8s_context)
+        if built is not None:
+            context[key] = built
+    context["events"] = select_events(context.get("events") or [], max_events)
 
     if max_log_lines <= 0:
         context["current_logs"] = []
@@ -662,10 +2104,8 @@ def _trim_log_context(raw_snippets: list[object], *, max_log_lines: int) -> list
         if not isinstance(lines, list):
             lines = []
         normalized = [line for line in lines if isinstance(line, str)]
-        normalized = _dedupe_consecutive_lines(normalized)
-        normalized = normalized[-max_log_lines:]
         trimmed = dict(snippet)
-        trimmed["logs"] = normalized
+        trimmed["logs"] = select_log_lines(normalized, max_log_lines)
         trimmed_logs.append(trimmed)
     return trimmed_logs
 
@@ -702,6 +2142,22 @@ def _apply_prompt_budget(
         if len(candidate) <= budget_chars:
             return candidate
 
+    # Step 1b: keep only the highest-scored log lines and events
+    if context_dict.get("current_logs") or context_dict.get("previous_logs"):
+        trimmed = dict(context_dict)
+        for key in ("current_logs", "previous_logs"):
+            trimmed[key] = _trim_log_context(
+                cast(list[object], context_dict.get(key) or []),
+                max_log_lines=_BUDGET_REDUCED_LOG_LINES,
+            )
+        trimmed["events"] = select_events(
+            cast(list[dict[str, object]], context_dict.get("events") or []),
+            _BUDGET_REDUCED_EVENTS,
+        )
+        candidate = prompt_prefix + alert_block + build_context_block(trimmed, "logs reduced")
+        if len(candidate) <= budget_chars:
+            return candidate
+
     # Step 2: remove previous logs
     if context_dict.get("previous_logs"):
         trimmed = dict(context_dict)
@@ -761,7 +2217,18 @@ def _compact_context_dict(context: dict[str, object]) -> dict[str, object]:
         "pod_name": context.get("pod_name"),
         "workload": context.get("workload"),
         "service_name": context.get("service_name"),
+        "owner_chain": _compact_owner_chain(context.get("owner_chain")),
         "pod_status": compact_status,
+        **{
+            key: context.get(key)
+            for key, _ in _CONTEXT_ANALYSES
+            if key not in _RESPONSE_ONLY_ANALYSES
+        },
+        "recent_rollouts": context.get("recent_rollouts") or [],
+        "service_catalog": context.get("service_catalog"),
+        "namespace_config": context.get("namespace_config"),
+        "kube_state": context.get("kube_state"),
+        "cloud_incidents": context.get("cloud_incidents") or [],
         "current_logs": _compact_log_snippets(context.get("current_logs")),
         "tempo": compact_tempo,
         "warnings": context.get("warnings") or [],
@@ -771,15 +2238,12 @@ def _compact_context_dict(context: dict[str, object]) -> dict[str, object]:
     }
 
 
-def _dedupe_consecutive_lines(lines: list[str]) -> list[str]:
-    deduped: list[str] = []
-    last = None
-    for line in lines:
-        if line == last:
-            continue
-        deduped.append(line)
-        last = line
-    return deduped
+def _compact_owner_chain(raw_chain: object) -> list[str]:
+    if not isinstance(raw_chain, list):
+        return []
+    return [
+        f"{link.get('kind')}/{link.get('name')}" for link in raw_chain if isinstance(link, dict)
+    ]
 
 
 def _compact_log_snippets(
@@ -794,8 +2258,7 @@ def _compact_log_snippets(
         logs = snippet.get("logs")
         if not isinstance(logs, list):
             logs = []
-        lines = [line for line in logs if isinstance(line, str)]
-        lines = _dedupe_consecutive_lines(lines)[-5:]
+        lines = select_log_lines([line for line in logs if isinstance(line, str)], 5)
         compact.append(
             {
                 "container": snippet.get("container"),
@@ -853,6 +2316,21 @@ def _prompt_budget_to_chars(prompt_token_budget: int) -> int:
     return prompt_token_budget * 4
 
 
+def _request_cluster(request: AlertAnalysisRequest) -> tuple[str | None, str | None]:
+    """Remote cluster of the request (``None`` for the agent's own) and a warning, if any."""
+    if request.cluster:
+        return resolve_cluster(request.cluster), None
+    try:
+        return resolve_cluster(request.alert.labels.get("cluster")), None
+    except UnknownCluster as exc:
+        # Alerts from unconfigured clusters still get an analysis, flagged as possibly wrong.
+        return None, f"{exc}; analyzed in the agent's own cluster"
+
+
+def _request_scoped_kubernetes() -> bool:
+    return caller_credentials_active() or current_cluster() is not None
+
+
 def _resolve_alert_session_id(request: AlertAnalysisRequest) -> str:
     incident_id = _normalize_session_token(request.incident_id)
     fingerprint = _normalize_session_token(request.alert.fingerprint)
@@ -941,7 +2419,8 @@ def _collect_missing_data(
         missing_data.append("k8s.events")
     if not _has_log_lines(k8s_context.current_logs):
         missing_data.append("k8s.current_logs")
-    if not _has_log_lines(k8s_context.previous_logs) and _total_restart_count(k8s_context) > 0:
+    previous_logs = [snippet for snippet in k8s_context.previous_logs if snippet.previous]
+    if not _has_log_lines(previous_logs) and _total_restart_count(k8s_context) > 0:
         missing_data.append("k8s.previous_logs")
     if capabilities.get("k8s_core") != "ok":
         missing_data.append("k8s.core_api")
@@ -1113,14 +2592,32 @@ def _fallback_summary(
     request: AlertAnalysisRequest,
     k8s_context: K8sContext,
     reason: str,
+    findings: list[RuleFinding] | None = None,
+    *,
+    cloud_incidents: list[dict[str, object]] | None = None,
+    service_catalog: dict[str, object] | None = None,
+    kube_state: dict[str, object] | None = None,
+    namespace_config: dict[str, object] | None = None,
 ) -> str:
     alert = request.alert
     lines = [
         f"analysis engine unavailable: {reason}",
+        "mode=degraded (rule-based analysis only)",
         f"alert_status={alert.status}",
     ]
     if k8s_context.namespace or k8s_context.pod_name:
         lines.append(f"target: namespace={k8s_context.namespace}, pod={k8s_context.pod_name}")
+    if service_catalog is not None:
+        lines.append(
+            f"service: {service_catalog.get('service')} (tier={service_catalog.get('tier')}, "
+            f"owner={service_catalog.get('owner')})"
+        )
+        for runbook in cast(list[dict[str, object]], service_catalog.get("runbooks") or []):
+            lines.append(f"  runbook: {runbook.get('url')}")
+    if namespace_config is not None:
+        for key in ("runbooks", "dashboards"):
+            for link in cast(list[dict[str, object]], namespace_config.get(key) or []):
+                lines.append(f"  {key[:-1]}: {link.get('title')} <{link.get('url')}>")
     if k8s_context.events:
         lines.append(f"recent_events ({len(k8s_context.events)}):")
         for event in k8s_context.events[:5]:
@@ -1129,13 +2626,64 @@ def _fallback_summary(
         lines.append("warnings: " + ", ".join(k8s_context.warnings))
     if k8s_context.pod_status:
         lines.append(f"pod_phase: {k8s_context.pod_status.phase}")
+    for finding in cast(list[str], (kube_state or {}).get("findings") or []):
+        lines.append(f"kube_state: {finding}")
     if alert.annotations:
         ann_summary = alert.annotations.get("summary") or alert.annotations.get("description")
         if ann_summary:
             lines.append(f"alert_summary: {ann_summary}")
+    if findings:
+        lines.append(f"rule_findings ({len(findings)}):")
+        for finding in findings:
+            lines.append(f"  - [{finding.severity}] {finding.rule}: {finding.title}")
+            for evidence in finding.evidence[:3]:
+                lines.append(f"      evidence: {evidence}")
+            lines.append(f"      recommendation: {finding.recommendation}")
+    else:
+        lines.append("rule_findings: none matched")
+    for incident in cloud_incidents or []:
+        lines.append(
+            f"possible upstream cloud incident: [{incident.get('provider')}] "
+            f"{incident.get('title')} (since {incident.get('started_at')}, {incident.get('url')})"
+        )
+    return "\n".join(lines)
+
+
+def _maintenance_analysis(request: AlertAnalysisRequest, maintenance: dict[str, object]) -> str:
+    node = maintenance.get("node")
+    signals = maintenance.get("signals")
+    lines = [
+        "요약:",
+        f"[expected] 노드 {node}의 계획된 유지보수 중 발생한 예상된 중단입니다 "
+        f"({request.alert.labels.get('alertname') or 'alert'})",
+        "",
+        "상세 분석:",
+        f"노드 {node}에서 다음 유지보수 신호가 확인되어 장애 조사를 생략했습니다.",
+    ]
+    if isinstance(signals, list):
+        lines.extend(f"- {signal}" for signal in signals if isinstance(signal, str))
+    lines.append("유지보수가 끝난 뒤에도 알림이 계속되면 다시 분석하세요.")
     return "\n".join(lines)
 
 
+def _degraded_summary(findings: list[RuleFinding], reason: str) -> str:
+    if not findings:
+        return f"[degraded] 규칙 기반 분석에서 알려진 원인을 찾지 못했습니다 ({reason})"
+    top = findings[0]
+    return f"[degraded] {top.title} ({reason})"
+
+
+def _build_rule_artifacts(findings: list[RuleFinding]) -> list[dict[str, object]]:
+    return [
+        {
+            "type": "rule_finding",
+            "summary": f"[{finding.severity}] {finding.title}",
+            "result": finding.to_dict(),
+        }
+        for finding in findings
+    ]
+
+
 def _to_pretty_json(payload: dict[str, Any]) -> str:
     return json.dumps(payload, ensure_ascii=True, indent=2, sort_keys=True)
 
@@ -1284,6 +2832,55 @@ def _build_incident_summary_prompt(request: IncidentSummaryRequest, masker: Mask
     )
 
 
+def _require_analysis_id(analysis_id: str) -> str:
+    analysis_id = analysis_id.strip()
+    if not _is_runtime_session_id(analysis_id):
+        raise ValueError("analysis_id must be the analysis_id of a previous /analyze response")
+    return analysis_id
+
+
+def _build_followup_prompt(request: AnalysisFollowupRequest, masker: Masker) -> str:
+    question = masker.mask_text(request.question).strip()
+    prompt = (
+        "A user replied in the Slack thread of your analysis above with a follow-up question. "
+        "Continue the same investigation: reuse the evidence already gathered and call "
+        "K8s, Prometheus, Loki or Tempo tools again only when fresher or additional data "
+        "is needed. Answer the question directly and concisely, cite the evidence you rely "
+        "on, and say so when the data cannot answer it. "
+        "Respond in English unless the user asks in another language.\n\n"
+    )
+    if request.context:
+        masked = cast(dict[str, Any], masker.mask_object(request.context))
+        prompt += f"Additional context:\n{_to_pretty_json(masked)}\n\n"
+    prompt += f"Follow-up question: {question}"
+    return prompt
+
+
+_RECOVERY_VERDICTS = ("not_explained", "partially_explained", "explained")
+
+
+def _build_recovery_validation_prompt(
+    suspected_cause: str, recovery_summary: str, masker: Masker
+) -> str:
+    return (
+        "An alert you analyzed has resolved. Judge, without calling tools, whether the "
+        "suspected cause from the firing analysis explains the recovery described below.\n"
+        "Answer with exactly one verdict on the first line: explained, partially_explained "
+        "or not_explained. On the second line give one sentence (<= 200 chars) of reasoning.\n\n"
+        f"Suspected cause (firing analysis):\n{masker.mask_text(suspected_cause).strip()}\n\n"
+        f"Recovery (resolved analysis):\n{masker.mask_text(recovery_summary).strip()}"
+    )
+
+
+def _parse_recovery_validation(answer: str) -> dict[str, str]:
+    lines = [line.strip() for line in answer.strip().splitlines() if line.strip()]
+    first = lines[0].lower().strip("*`#: ").replace(" ", "_") if lines else ""
+    # Check the longer verdicts first: "explained" is a suffix of the others.
+    verdict = next((item for item in _RECOVERY_VERDICTS if item in first), "unknown")
+    note = " ".join(lines[1:]) if len(lines) > 1 else ""
+    return {"verdict": verdict, "note": note[:_SUMMARY_MAX_LEN]}
+
+
 def _split_alert_analysis(result: str) -> tuple[str, str]:
     all_keys = ["요약", "summary", "상세", "detail"]
     summary = _extract_section(result, ["요약", "summary"], all_keys)
@@ -1369,6 +2966,8 @@ def _compact_summary(text: str, limit: int = 300) -> str:
 def _build_alert_artifacts(
     k8s_context: K8sContext,
     tempo_context: dict[str, object] | None = None,
+    *,
+    max_log_lines: int | None = 20,
 ) -> list[dict[str, object]]:
     artifacts: list[dict[str, object]] = []
 
@@ -1391,7 +2990,7 @@ def _build_alert_artifacts(
         )
 
     for snippet in [*k8s_context.current_logs, *k8s_context.previous_logs]:
-        logs = snippet.logs[:20]
+        logs = snippet.logs[:max_log_lines]
         log_kind = "previous" if snippet.previous else "current"
         artifacts.append(
             {
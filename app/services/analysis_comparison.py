@@ -0,0 +1,175 @@
+"""Structured comparison of stored analysis versions.
+
+An alert analyzed live and then re-run by a backfill, or answered by both
+canary arms, has several rows in ``kube_rca_analyses`` under one session key.
+``stored_conclusions`` reduces a row to what it concluded: the summary, the
+quality verdict, the rules that matched, the ranked hypotheses and the
+findings and root cause of each analyzer section. ``compare_stored_analyses``
+diffs two of them, so prompt and pipeline changes can be evaluated and
+audited without reading both answers side by side.
+"""
+
+from __future__ import annotations
+
+from app.services.analysis_view import summarize_stored_analysis
+
+# Keys of analyzer sections that carry a single conclusion.
+_VERDICT_KEYS = ("root_cause", "verdict", "cause")
+_HYPOTHESIS_FIELDS = ("verdict", "confidence", "rank")
+_RULE_FIELDS = ("severity", "title")
+
+
+def describe_version(row: dict[str, object]) -> dict[str, object]:
+    """List entry of one stored version with the rules and root causes it concluded."""
+    conclusions = stored_conclusions(row)
+    return {
+        **summarize_stored_analysis(row),
+        "backfill_job_id": row.get("backfill_job_id"),
+        "rollout_arm": conclusions["rollout_arm"],
+        "rules": [rule["rule"] for rule in _dicts(conclusions["rules"])],
+        "root_causes": conclusions["root_causes"],
+    }
+
+
+def stored_conclusions(row: dict[str, object]) -> dict[str, object]:
+    result = _as_dict(row.get("result"))
+    context = _as_dict(result.get("context"))
+    rules = [
+        {
+            "rule": finding.get("rule"),
+            "severity": finding.get("severity"),
+            "title": finding.get("title"),
+        }
+        for finding in (
+            _as_dict(artifact.get("result"))
+            for artifact in _dicts(result.get("artifacts"))
+            if artifact.get("type") == "rule_finding"
+        )
+        if finding.get("rule")
+    ]
+    hypotheses = [
+        {
+            "key": item.get("key"),
+            "title": item.get("title"),
+            "verdict": item.get("verdict"),
+            "confidence": item.get("confidence"),
+            "rank": item.get("rank"),
+        }
+        for item in _dicts(context.get("hypotheses"))
+        if item.get("key")
+    ]
+    root_causes: dict[str, object] = {}
+    findings: dict[str, list[str]] = {}
+    for key, section in sorted(context.items()):
+        if not isinstance(section, dict):
+            continue
+        verdict = next((section[name] for name in _VERDICT_KEYS if section.get(name)), None)
+        if isinstance(verdict, str):
+            root_causes[key] = verdict
+        section_findings = section.get("findings")
+        if isinstance(section_findings, list) and section_findings:
+            findings[key] = [str(item) for item in section_findings]
+    return {
+        "summary": str(result.get("analysis_summary") or result.get("analysis") or "").strip(),
+        "analysis_quality": context.get("analysis_quality"),
+        "degraded": bool(context.get("degraded")),
+        "degraded_reason": context.get("degraded_reason"),
+        "rollout_arm": context.get("rollout_arm"),
+        "rules": rules,
+        "hypotheses": hypotheses,
+        "root_causes": root_causes,
+        "findings": findings,
+    }
+
+
+def compare_stored_analyses(
+    base_row: dict[str, object], target_row: dict[str, object]
+) -> dict[str, object]:
+    """What *target_row* concludes differently from *base_row*; unchanged parts are omitted."""
+    base = stored_conclusions(base_row)
+    target = stored_conclusions(target_row)
+    changes: dict[str, object] = {}
+    for key in ("summary", "analysis_quality", "degraded", "degraded_reason"):
+        if base[key] != target[key]:
+            changes[key] = {"base": base[key], "target": target[key]}
+    rules = _keyed_diff(base["rules"], target["rules"], "rule", _RULE_FIELDS)
+    if rules:
+        changes["rules"] = rules
+    hypotheses = _keyed_diff(base["hypotheses"], target["hypotheses"], "key", _HYPOTHESIS_FIELDS)
+    if hypotheses:
+        changes["hypotheses"] = hypotheses
+    root_causes = {
+        key: {"base": _as_dict(base["root_causes"]).get(key), "target": value}
+        for key, value in _merged(base["root_causes"], target["root_causes"]).items()
+        if _as_dict(base["root_causes"]).get(key) != value
+    }
+    if root_causes:
+        changes["root_causes"] = root_causes
+    findings: dict[str, object] = {}
+    base_findings = _as_dict(base["findings"])
+    target_findings = _as_dict(target["findings"])
+    for key in sorted(set(base_findings) | set(target_findings)):
+        before = _strings(base_findings.get(key))
+        after = _strings(target_findings.get(key))
+        added = [item for item in after if item not in before]
+        removed = [item for item in before if item not in after]
+        if added or removed:
+            findings[key] = {"added": added, "removed": removed}
+    if findings:
+        changes["findings"] = findings
+    return {
+        "base": _version_ref(base_row, base),
+        "target": _version_ref(target_row, target),
+        "identical": not changes,
+        "changes": changes,
+    }
+
+
+def _keyed_diff(
+    base: object, target: object, key: str, fields: tuple[str, ...]
+) -> dict[str, object]:
+    before = {item[key]: item for item in _dicts(base)}
+    after = {item[key]: item for item in _dicts(target)}
+    changed = [
+        {
+            key: name,
+            "base": {field: before[name].get(field) for field in fields},
+            "target": {field: item.get(field) for field in fields},
+        }
+        for name, item in after.items()
+        if name in before and any(before[name].get(field) != item.get(field) for field in fields)
+    ]
+    diff: dict[str, object] = {
+        "added": [item for name, item in after.items() if name not in before],
+        "removed": [item for name, item in before.items() if name not in after],
+        "changed": changed,
+    }
+    return diff if any(diff.values()) else {}
+
+
+def _version_ref(row: dict[str, object], conclusions: dict[str, object]) -> dict[str, object]:
+    return {
+        "result_id": row.get("result_id"),
+        "pipeline_version": row.get("pipeline_version"),
+        "source": row.get("source"),
+        "backfill_job_id": row.get("backfill_job_id"),
+        "rollout_arm": conclusions["rollout_arm"],
+        "created_at": row.get("created_at"),
+    }
+
+
+def _merged(base: object, target: object) -> dict[str, object]:
+    # Sections the target no longer has are reported with a None target.
+    return {**{key: None for key in _as_dict(base)}, **_as_dict(target)}
+
+
+def _strings(value: object) -> list[str]:
+    return [str(item) for item in value] if isinstance(value, list) else []
+
+
+def _dicts(value: object) -> list[dict[str, object]]:
+    return [item for item in value if isinstance(item, dict)] if isinstance(value, list) else []
+
+
+def _as_dict(value: object) -> dict[str, object]:
+    return value if isinstance(value, dict) else {}
@@ -0,0 +1,166 @@
+"""Read-only views of stored analyses for the built-in web UI.
+
+Work on rows of ``PostgresAnalysisStore.list_recent``/``get_result``. The
+stored payloads are already masked, so the views only reshape them: a list
+entry with the headline fields, and a detail view with the analysis text, the
+evidence sections of the context and a timeline of the incident.
+"""
+
+from __future__ import annotations
+
+from datetime import datetime, timezone
+
+_SUMMARY_CHARS = 280
+# Context keys describing the run itself rather than evidence about the incident.
+_RUN_KEYS = frozenset(
+    {
+        "analysis_id",
+        "namespace",
+        "pod_name",
+        "workload",
+        "analysis_quality",
+        "missing_data",
+        "warnings",
+        "capabilities",
+        "degraded",
+        "degraded_reason",
+        "pipeline",
+        "rollout_arm",
+    }
+)
+
+
+_EVICTORS = {
+    "kubelet": "kubelet",
+    "scheduler": "scheduler",
+    "eviction_api": "Eviction API",
+    "taint_manager": "taint manager",
+}
+
+
+def summarize_stored_analysis(row: dict[str, object]) -> dict[str, object]:
+    result = _as_dict(row.get("result"))
+    context = _as_dict(result.get("context"))
+    summary = str(result.get("analysis_summary") or result.get("analysis") or "").strip()
+    if len(summary) > _SUMMARY_CHARS:
+        summary = summary[:_SUMMARY_CHARS].rstrip() + "..."
+    return {
+        "result_id": row.get("result_id"),
+        "analysis_id": row.get("analysis_id"),
+        "correlation_id": row.get("correlation_id"),
+        "session_key": row.get("session_key"),
+        "alertname": row.get("alertname"),
+        "namespace": row.get("namespace"),
+        "pod_name": context.get("pod_name"),
+        "alert_status": row.get("alert_status"),
+        "source": row.get("source"),
+        "pipeline_version": row.get("pipeline_version"),
+        "created_at": row.get("created_at"),
+        "analysis_quality": context.get("analysis_quality"),
+        "degraded": bool(context.get("degraded")),
+        "summary": summary,
+    }
+
+
+def describe_stored_analysis(row: dict[str, object]) -> dict[str, object]:
+    request = _as_dict(row.get("request"))
+    result = _as_dict(row.get("result"))
+    context = _as_dict(result.get("context"))
+    alert = _as_dict(request.get("alert"))
+    return {
+        **summarize_stored_analysis(row),
+        "incident_id": row.get("incident_id"),
+        "fingerprint": row.get("fingerprint"),
+        "backfill_job_id": row.get("backfill_job_id"),
+        "labels": _as_dict(alert.get("labels")),
+        "annotations": _as_dict(alert.get("annotations")),
+        "analysis": result.get("analysis"),
+        "analysis_summary": result.get("analysis_summary"),
+        "analysis_detail": result.get("analysis_detail"),
+        "warnings": context.get("warnings") or [],
+        "missing_data": context.get("missing_data") or [],
+        "evidence": {
+            key: value
+            for key, value in context.items()
+            if key not in _RUN_KEYS and value not in (None, "", [], {})
+        },
+        "artifacts": result.get("artifacts") or [],
+        "timeline": build_analysis_timeline(alert, context, row.get("created_at")),
+    }
+
+
+def build_analysis_timeline(
+    alert: dict[str, object], context: dict[str, object], analyzed_at: object
+) -> list[dict[str, object]]:
+    """Alert start/end, rollouts, evictions, Kubernetes events and the analysis, oldest first."""
+    entries: list[dict[str, object]] = []
+
+    def add(at: object, kind: str, text: str) -> None:
+        parsed = _parse_time(at)
+        if parsed is not None:
+            entries.append({"time": parsed, "kind": kind, "text": text})
+
+    alertname = _as_dict(alert.get("labels")).get("alertname") or "alert"
+    add(alert.get("startsAt"), "alert", f"{alertname} started firing")
+    if alert.get("status") == "resolved":
+        add(alert.get("endsAt"), "alert", f"{alertname} resolved")
+    for rollout in _dicts(context.get("recent_rollouts")):
+        images = ", ".join(str(image) for image in rollout.get("images") or [] if image)
+        add(
+            rollout.get("created"),
+            "rollout",
+            f"deployment {rollout.get('deployment')} rolled out revision "
+            f"{rollout.get('revision')}" + (f" ({images})" if images else ""),
+        )
+    evictions = _dicts(_as_dict(context.get("eviction_analysis")).get("evictions"))
+    for eviction in evictions:
+        add(
+            eviction.get("time"),
+            "eviction",
+            f"{_EVICTORS.get(str(eviction.get('evicted_by')), 'kubelet')} "
+            f"{'preempted' if eviction.get('kind') == 'preemption' else 'evicted'} pod "
+            f"{eviction.get('pod')}"
+            + (f" on {eviction['node']}" if eviction.get("node") else "")
+            + f": {eviction.get('message') or eviction.get('kind')}",
+        )
+    # Events already shown as an eviction entry are not repeated.
+    evicted_messages = {eviction["message"] for eviction in evictions if eviction.get("message")}
+    for event in _dicts(context.get("events")):
+        if event.get("message") in evicted_messages:
+            continue
+        involved = _as_dict(event.get("involved_object"))
+        subject = "/".join(
+            str(part) for part in (involved.get("kind"), involved.get("name")) if part
+        )
+        count = event.get("count")
+        text = (
+            f"{event.get('type') or 'Event'} {event.get('reason') or ''}".rstrip()
+            + (f" on {subject}" if subject else "")
+            + f": {event.get('message') or ''}"
+            + (f" (x{count})" if isinstance(count, int) and count > 1 else "")
+        )
+        add(event.get("last_timestamp") or event.get("first_timestamp"), "event", text)
+    add(analyzed_at, "analysis", "analysis stored")
+    entries.sort(key=lambda entry: entry["time"])
+    return [{**entry, "time": entry["time"].isoformat()} for entry in entries]
+
+
+def _parse_time(value: object) -> datetime | None:
+    if isinstance(value, datetime):
+        parsed = value
+    elif isinstance(value, str) and value.strip():
+        try:
+            parsed = datetime.fromisoformat(value.strip().replace("Z", "+00:00"))
+        except ValueError:
+            return None
+    else:
+        return None
+    return parsed if parsed.tzinfo is not None else parsed.replace(tzinfo=timezone.utc)
+
+
+def _dicts(value: object) -> list[dict[str, object]]:
+    return [item for item in value if isinstance(item, dict)] if isinstance(value, list) else []
+
+
+def _as_dict(value: object) -> dict[str, object]:
+    return value if isinstance(value, dict) else {}
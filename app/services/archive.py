@@ -0,0 +1,180 @@
+from __future__ import annotations
+
+import json
+import logging
+import re
+import threading
+from concurrent.futures import ThreadPoolExecutor
+from datetime import datetime, timedelta, timezone
+
+from app.clients.object_storage import ObjectStore
+from app.core.masking import Masker, RegexMasker
+from app.schemas.analysis import AlertAnalysisRequest
+
+logger = logging.getLogger(__name__)
+
+_UNSAFE_KEY_CHARS = re.compile(r"[^A-Za-z0-9._-]+")
+
+
+class AnalysisArchiver:
+    """Export completed analyses to object storage as JSON plus a rendered Markdown report.
+
+    Objects are written as ``<prefix>/YYYY/MM/DD/<namespace>/<incident>/<name>.json|.md``
+    (``_`` without an incident) by one background worker, so uploads never add
+    latency to ``/analyze``.
+    At most ``max_pending`` exports wait for the worker; beyond that new
+    analyses are skipped (and logged) rather than queued without bound.
+    """
+
+    def __init__(
+        self,
+        store: ObjectStore,
+        *,
+        prefix: str = "kube-rca/analyses",
+        masker: Masker | None = None,
+        max_pending: int = 100,
+    ) -> None:
+        self._store = store
+        self._prefix = prefix.strip("/")
+        self._masker = masker or RegexMasker()
+        self._max_pending = max(1, max_pending)
+        self._pending = 0
+        self._lock = threading.Lock()
+        self._executor = ThreadPoolExecutor(max_workers=1, thread_name_prefix="analysis-archive")
+
+    def submit(self, request: AlertAnalysisRequest, response: dict[str, object]) -> bool:
+        """Queue an export; returns False when the backlog is full."""
+        with self._lock:
+            if self._pending >= self._max_pending:
+                logger.warning(
+                    "analysis_archive_skipped analysis_id=%s reason=backlog_full",
+                    response.get("analysis_id"),
+                )
+                return False
+            self._pending += 1
+        self._executor.submit(self._run, request, response)
+        return True
+
+    def export(self, request: AlertAnalysisRequest, response: dict[str, object]) -> str:
+        """Write both objects and return their common key (without extension)."""
+        now = datetime.now(timezone.utc)
+        record: dict[str, object] = {
+            "archived_at": now.isoformat(),
+            "alert": self._masker.mask_object(
+                request.alert.model_dump(mode="json", by_alias=True)
+            ),
+            "incident_id": request.incident_id,
+            "response": response,
+        }
+        key = self._object_key(request, response, now)
+        self._store.put(
+            f"{key}.json",
+            json.dumps(record, ensure_ascii=False, indent=2).encode("utf-8"),
+            "application/json",
+        )
+        self._store.put(
+            f"{key}.md", render_report(record).encode("utf-8"), "text/markdown; charset=utf-8"
+        )
+        return key
+
+    def purge_older_than(self, older_than_days: int) -> int:
+        cutoff = datetime.now(timezone.utc) - timedelta(days=older_than_days)
+        return self._store.delete_matching(
+            f"{self._prefix}/", lambda _key, modified: modified < cutoff
+        )
+
+    def delete_analyses(
+        self,
+        *,
+        namespace: str | None = None,
+        incident_id: str | None = None,
+        since: datetime | None = None,
+        until: datetime | None = None,
+    ) -> int:
+        """Delete the exported objects matching the filters; returns the objects deleted.
+
+        Times are matched against the upload time. Objects written before the
+        incident segment existed only match namespace and time filters.
+        """
+        prefix = f"{self._prefix}/"
+
+        def matches(key: str, modified: datetime) -> bool:
+            parts = key[len(prefix) :].split("/")
+            if len(parts) not in (5, 6):
+                return False
+            if namespace and parts[3] != _safe_key(namespace):
+                return False
+            if incident_id and (len(parts) == 5 or parts[4] != _safe_key(incident_id)):
+                return False
+            return (since is None or modified >= since) and (until is None or modified < until)
+
+        return self._store.delete_matching(prefix, matches)
+
+    def _run(self, request: AlertAnalysisRequest, response: dict[str, object]) -> None:
+        try:
+            key = self.export(request, response)
+            logger.info("analysis_archived key=%s", key)
+        except Exception as exc:  # noqa: BLE001
+            logger.warning("Failed to archive analysis: %s", exc)
+        finally:
+            with self._lock:
+                self._pending -= 1
+
+    def _object_key(
+        self, request: AlertAnalysisRequest, response: dict[str, object], now: datetime
+    ) -> str:
+        context = response.get("context")
+        namespace = context.get("namespace") if isinstance(context, dict) else None
+        analysis_id = response.get("analysis_id")
+        name = (
+            analysis_id
+            if isinstance(analysis_id, str) and analysis_id
+            else f"{request.alert.fingerprint or 'alert'}-{now:%H%M%S%f}"
+        )
+        parts = [
+            self._prefix,
+            f"{now:%Y/%m/%d}",
+            _safe_key(namespace if isinstance(namespace, str) and namespace else "cluster"),
+            _safe_key(request.incident_id or ""),
+            _safe_key(name),
+        ]
+        return "/".join(part for part in parts if part)
+
+
+def render_report(record: dict[str, object]) -> str:
+    """Human-readable Markdown report of an archived analysis record."""
+    alert = _as_dict(record.get("alert"))
+    response = _as_dict(record.get("response"))
+    labels = _as_dict(alert.get("labels"))
+    context = _as_dict(response.get("context"))
+
+    degraded = "no"
+    if response.get("degraded") is True:
+        degraded = f"yes ({response.get('degraded_reason') or 'unknown'})"
+    rows = [
+        ("Status", alert.get("status")),
+        ("Namespace", context.get("namespace") or labels.get("namespace")),
+        ("Fingerprint", alert.get("fingerprint")),
+        ("Incident", record.get("incident_id")),
+        ("Analysis ID", response.get("analysis_id")),
+        ("Quality", response.get("analysis_quality")),
+        ("Degraded", degraded),
+        ("Archived at", record.get("archived_at")),
+    ]
+    lines = [
+        f"# {labels.get('alertname') or 'Alert analysis'}",
+        "",
+        "| Field | Value |",
+        "|-------|-------|",
+    ]
+    lines.extend(f"| {field} | {value if value else '-'} |" for field, value in rows)
+    lines.extend(["", str(response.get("analysis") or "").strip(), ""])
+    return "\n".join(lines)
+
+
+def _as_dict(value: object) -> dict[str, object]:
+    return value if isinstance(value, dict) else {}
+
+
+def _safe_key(value: str) -> str:
+    return _UNSAFE_KEY_CHARS.sub("_", value).strip("_") or "_"
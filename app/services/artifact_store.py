@@ -0,0 +1,134 @@
+from __future__ import annotations
+
+import hashlib
+import json
+import logging
+import re
+import uuid
+from collections.abc import Callable
+from datetime import datetime, timedelta, timezone
+
+from app.clients.object_storage import SignedObjectStore
+
+logger = logging.getLogger(__name__)
+
+_UNSAFE_KEY_CHARS = re.compile(r"[^A-Za-z0-9._-]+")
+
+
+class ArtifactOffloader:
+    """Upload large analysis artifacts to object storage and reference them by signed URL.
+
+    An artifact whose ``result`` serializes to more than ``inline_max_bytes``
+    is written to
+    ``<prefix>/YYYY/MM/DD/<namespace>/<incident>/<analysis_id>/<index>-<type>.json``
+    (``_`` without an incident, ``.txt`` for text results); the response
+    keeps its type and summary, drops the result and carries an
+    ``attachment`` with the signed URL, size, SHA-256 and expiry instead.
+    Artifacts are already masked when they get here. A failed upload keeps
+    the artifact inline, so nothing is lost.
+    """
+
+    def __init__(
+        self,
+        store: SignedObjectStore,
+        *,
+        prefix: str = "kube-rca/artifacts",
+        inline_max_bytes: int = 65536,
+        url_ttl_seconds: int = 86400,
+        clock: Callable[[], datetime] = lambda: datetime.now(timezone.utc),
+    ) -> None:
+        self._store = store
+        self._prefix = prefix.strip("/")
+        self._inline_max_bytes = max(0, inline_max_bytes)
+        # Signed URLs of all three backends are valid for at most seven days.
+        self._url_ttl_seconds = min(max(60, url_ttl_seconds), 7 * 24 * 3600)
+        self._clock = clock
+
+    def offload(
+        self,
+        analysis_id: str | None,
+        artifacts: list[dict[str, object]],
+        *,
+        namespace: str | None = None,
+        incident_id: str | None = None,
+    ) -> list[dict[str, object]]:
+        now = self._clock()
+        folder = "/".join(
+            (
+                _safe(namespace or "") or "cluster",
+                _safe(incident_id or "") or "_",
+                _safe(analysis_id or "") or uuid.uuid4().hex,
+            )
+        )
+        offloaded: list[dict[str, object]] = []
+        for index, artifact in enumerate(artifacts):
+            result = artifact.get("result")
+            if isinstance(result, str):
+                body, content_type, extension = result.encode("utf-8"), "text/plain", "txt"
+            elif result is not None:
+                body = json.dumps(result, ensure_ascii=False, default=str).encode("utf-8")
+                content_type, extension = "application/json", "json"
+            else:
+                offloaded.append(artifact)
+                continue
+            if len(body) <= self._inline_max_bytes:
+                offloaded.append(artifact)
+                continue
+            name = _safe(str(artifact.get("type") or "artifact")) or "artifact"
+            key = f"{self._prefix}/{now:%Y/%m/%d}/{folder}/{index:02d}-{name}.{extension}"
+            try:
+                self._store.put(key, body, f"{content_type}; charset=utf-8")
+                url = self._store.signed_url(key, self._url_ttl_seconds)
+            except Exception as exc:  # noqa: BLE001
+                logger.warning("artifact_offload_failed key=%s error=%s", key, exc)
+                offloaded.append(artifact)
+                continue
+            offloaded.append(
+                {
+                    **artifact,
+                    "result": None,
+                    "attachment": {
+                        "key": key,
+                        "url": url,
+                        "content_type": content_type,
+                        "size_bytes": len(body),
+                        "sha256": hashlib.sha256(body).hexdigest(),
+                        "expires_at": (
+                            now + timedelta(seconds=self._url_ttl_seconds)
+                        ).isoformat(),
+                    },
+                }
+            )
+        return offloaded
+
+    def delete_analyses(
+        self,
+        *,
+        namespace: str | None = None,
+        incident_id: str | None = None,
+        since: datetime | None = None,
+        until: datetime | None = None,
+    ) -> int:
+        """Delete the uploaded artifacts matching the filters; returns the objects deleted.
+
+        Times are matched against the upload time. Artifacts uploaded before
+        the namespace and incident segments existed only match time filters.
+        """
+        prefix = f"{self._prefix}/"
+
+        def matches(key: str, modified: datetime) -> bool:
+            parts = key[len(prefix) :].split("/")
+            if len(parts) == 7:
+                if namespace and parts[3] != _safe(namespace):
+                    return False
+                if incident_id and parts[4] != _safe(incident_id):
+                    return False
+            elif len(parts) != 5 or namespace or incident_id:
+                return False
+            return (since is None or modified >= since) and (until is None or modified < until)
+
+        return self._store.delete_matching(prefix, matches)
+
+
+def _safe(value: str) -> str:
+    return _UNSAFE_KEY_CHARS.sub("-", value).strip("-")[:120]
@@ -0,0 +1,118 @@
+from __future__ import annotations
+
+import logging
+import threading
+from dataclasses import asdict
+from datetime import datetime, timezone
+from typing import Protocol
+from uuid import uuid4
+
+from pydantic import ValidationError
+
+from app.clients.analysis_store import AnalysisFilter, AnalysisStore
+from app.schemas.analysis import AlertAnalysisRequest
+
+logger = logging.getLogger(__name__)
+
+_MAX_KEPT_JOBS = 20
+
+
+class _Reanalyzer(Protocol):
+    def reanalyze(self, request: AlertAnalysisRequest, *, backfill_job_id: str) -> int | None: ...
+
+
+class BackfillService:
+    """Admin-triggered re-analysis of stored alerts with the current pipeline version.
+
+    One job runs at a time on a background thread and processes alerts
+    sequentially, so a backfill never competes with live analyses for more
+    than one slot. Job state is kept in memory (last 20 jobs).
+    """
+
+    def __init__(self, store: AnalysisStore, analysis_service: _Reanalyzer) -> None:
+        self._store = store
+        self._analysis_service = analysis_service
+        self._jobs: dict[str, dict[str, object]] = {}
+        self._lock = threading.Lock()
+
+    def start(self, analysis_filter: AnalysisFilter, *, limit: int) -> dict[str, object]:
+        """Start a job; raises RuntimeError while another job is still running."""
+        with self._lock:
+            if any(job["status"] == "running" for job in self._jobs.values()):
+                raise RuntimeError("a backfill job is already running")
+            job_id = uuid4().hex[:12]
+            job: dict[str, object] = {
+                "job_id": job_id,
+                "status": "running",
+                "filter": {
+                    key: value.isoformat() if isinstance(value, datetime) else value
+                    for key, value in asdict(analysis_filter).items()
+                },
+                "limit": limit,
+                "total": None,
+                "completed": 0,
+                "failed": 0,
+                "result_ids": [],
+                "errors": [],
+                "started_at": datetime.now(timezone.utc).isoformat(),
+                "finished_at": None,
+            }
+            self._jobs[job_id] = job
+            for stale_id in list(self._jobs)[:-_MAX_KEPT_JOBS]:
+                self._jobs.pop(stale_id)
+        threading.Thread(
+            target=self._run, args=(job, analysis_filter, limit), name="backfill", daemon=True
+        ).start()
+        return dict(job)
+
+    def job(self, job_id: str) -> dict[str, object] | None:
+        with self._lock:
+            job = self._jobs.get(job_id)
+            return dict(job) if job is not None else None
+
+    def jobs(self) -> list[dict[str, object]]:
+        with self._lock:
+            return [dict(job) for job in reversed(self._jobs.values())]
+
+    def _run(self, job: dict[str, object], analysis_filter: AnalysisFilter, limit: int) -> None:
+        result_ids: list[int] = []
+        errors: list[str] = []
+        completed = 0
+        try:
+            requests = self._store.list_requests(analysis_filter, limit=limit)
+            job["total"] = len(requests)
+            for source_id, payload in requests:
+                try:
+                    request = AlertAnalysisRequest.model_validate(payload)
+                    result_id = self._analysis_service.reanalyze(
+                        request, backfill_job_id=str(job["job_id"])
+                    )
+                except ValidationError as exc:
+                    errors.append(f"result {source_id}: stored request is invalid: {exc}")
+                    job["failed"] = len(errors)
+                    continue
+                except Exception as exc:  # noqa: BLE001
+                    errors.append(f"result {source_id}: {exc}")
+                    job["failed"] = len(errors)
+                    continue
+                if result_id is not None:
+                    result_ids.append(result_id)
+                completed += 1
+                job["completed"] = completed
+            job["status"] = "completed"
+        except Exception as exc:  # noqa: BLE001
+            logger.warning("Backfill job %s failed: %s", job["job_id"], exc)
+            errors.append(str(exc))
+            job["status"] = "failed"
+        finally:
+            job["result_ids"] = result_ids
+            job["errors"] = errors[-20:]
+            job["failed"] = len(errors)
+            job["finished_at"] = datetime.now(timezone.utc).isoformat()
+        logger.info(
+            "backfill job_id=%s status=%s completed=%s failed=%s",
+            job["job_id"],
+            job["status"],
+            job["completed"],
+            job["failed"],
+        )
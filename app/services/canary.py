@@ -0,0 +1,178 @@
+from __future__ import annotations
+
+import hashlib
+import logging
+import threading
+from collections import OrderedDict, deque
+from datetime import datetime, timezone
+
+from app.clients.strands_agent import AnalysisEngine
+
+logger = logging.getLogger(__name__)
+
+# Arms of recent analyses kept for feedback.
+_MAX_TRACKED_ANALYSES = 5000
+
+
+class CanaryRollout:
+    """Send a percentage of analyses to a new model and/or prompt, rolling back on errors.
+
+    Alerts are assigned by a hash of their session key, so re-analyses of the
+    same alert stay on one arm. Each arm keeps its last ``window`` outcomes; an
+    outcome fails when the analysis degraded (engine error, empty answer or
+    time-boxed). Once both arms have ``min_samples`` outcomes and the canary
+    error rate exceeds the stable one by more than ``max_error_rate_delta``,
+    the canary is rolled back until ``reset()``. Feedback scores (0-1) of
+    recent analyses are kept per arm the same way; once both arms have
+    ``min_feedback`` scores and the canary's mean score is more than
+    ``max_score_drop`` below the stable one, the canary is rolled back too.
+    """
+
+    def __init__(
+        self,
+        percent: int,
+        *,
+        engine: AnalysisEngine | None = None,
+        model: str | None = None,
+        prompt_instructions: str | None = None,
+        window: int = 100,
+        min_samples: int = 20,
+        max_error_rate_delta: float = 0.1,
+        min_feedback: int = 10,
+        max_score_drop: float = 0.1,
+    ) -> None:
+        self._percent = min(max(percent, 0), 100)
+        self._engine = engine
+        self._model = model
+        self._prompt_instructions = prompt_instructions
+        self._min_samples = max(1, min_samples)
+        self._max_error_rate_delta = max_error_rate_delta
+        self._outcomes: dict[bool, deque[bool]] = {
+            True: deque(maxlen=max(self._min_samples, window)),
+            False: deque(maxlen=max(self._min_samples, window)),
+        }
+        self._min_feedback = max(1, min_feedback)
+        self._max_score_drop = max_score_drop
+        self._scores: dict[bool, deque[float]] = {
+            True: deque(maxlen=max(self._min_feedback, window)),
+            False: deque(maxlen=max(self._min_feedback, window)),
+        }
+        self._arms: OrderedDict[str, bool] = OrderedDict()
+        self._lock = threading.Lock()
+        self._rolled_back_at: datetime | None = None
+        self._rollback_reason: str | None = None
+
+    @property
+    def active(self) -> bool:
+        return self._percent > 0 and self._rolled_back_at is None
+
+    @property
+    def engine(self) -> AnalysisEngine | None:
+        return self._engine
+
+    @property
+    def prompt_instructions(self) -> str | None:
+        return self._prompt_instructions
+
+    def assign(self, key: str) -> bool:
+        """Return True when the alert identified by *key* goes to the canary."""
+        if not self.active:
+            return False
+        bucket = int(hashlib.sha256(key.encode("utf-8")).hexdigest()[:8], 16) % 100
+        return bucket < self._percent
+
+    def record(self, canary: bool, *, failed: bool, analysis_id: str | None = None) -> None:
+        with self._lock:
+            self._outcomes[canary].append(failed)
+            if analysis_id:
+                self._arms[analysis_id] = canary
+                self._arms.move_to_end(analysis_id)
+                while len(self._arms) > _MAX_TRACKED_ANALYSES:
+                    self._arms.popitem(last=False)
+            if not canary or self._rolled_back_at is not None:
+                return
+            canary_rate = _error_rate(self._outcomes[True])
+            stable_rate = _error_rate(self._outcomes[False])
+            if (
+                len(self._outcomes[True]) < self._min_samples
+                or len(self._outcomes[False]) < self._min_samples
+                or canary_rate - stable_rate <= self._max_error_rate_delta
+            ):
+                return
+            reason = (
+                f"canary error rate {canary_rate:.0%} exceeds stable {stable_rate:.0%} "
+                f"by more than {self._max_error_rate_delta:.0%}"
+            )
+            self._roll_back(reason)
+
+    def record_feedback(self, analysis_id: str, score: float) -> str | None:
+        """Score (0-1) an analysis once; returns its arm, ``None`` when it is not tracked."""
+        with self._lock:
+            canary = self._arms.pop(analysis_id, None)
+            if canary is None:
+                return None
+            self._scores[canary].append(min(max(score, 0.0), 1.0))
+            arm = "canary" if canary else "stable"
+            if self._rolled_back_at is not None or any(
+                len(scores) < self._min_feedback for scores in self._scores.values()
+            ):
+                return arm
+            canary_score = _mean(self._scores[True])
+            stable_score = _mean(self._scores[False])
+            if stable_score - canary_score > self._max_score_drop:
+                self._roll_back(
+                    f"canary feedback score {canary_score:.2f} is below stable "
+                    f"{stable_score:.2f} by more than {self._max_score_drop:.2f}"
+                )
+            return arm
+
+    def _roll_back(self, reason: str) -> None:
+        self._rolled_back_at = datetime.now(timezone.utc)
+        self._rollback_reason = reason
+        logger.warning("Canary rolled back: %s", reason)
+
+    def reset(self) -> None:
+        """Re-enable a rolled-back canary with fresh outcome windows."""
+        with self._lock:
+            for outcomes in self._outcomes.values():
+                outcomes.clear()
+            for scores in self._scores.values():
+                scores.clear()
+            self._rolled_back_at = None
+            self._rollback_reason = None
+
+    def status(self) -> dict[str, object]:
+        with self._lock:
+            canary, stable = list(self._outcomes[True]), list(self._outcomes[False])
+            canary_scores, stable_scores = list(self._scores[True]), list(self._scores[False])
+            rolled_back_at, reason = self._rolled_back_at, self._rollback_reason
+        return {
+            "percent": self._percent,
+            "active": self.active,
+            "model": self._model,
+            "prompt_override": self._prompt_instructions is not None,
+            "rolled_back_at": rolled_back_at.isoformat() if rolled_back_at else None,
+            "rollback_reason": reason,
+            "canary": {"samples": len(canary), "error_rate": _rounded_rate(canary)},
+            "stable": {"samples": len(stable), "error_rate": _rounded_rate(stable)},
+            "feedback": {
+                "canary": {"samples": len(canary_scores), "score": _rounded_mean(canary_scores)},
+                "stable": {"samples": len(stable_scores), "score": _rounded_mean(stable_scores)},
+            },
+        }
+
+
+def _error_rate(outcomes: deque[bool] | list[bool]) -> float:
+    return sum(outcomes) / len(outcomes) if outcomes else 0.0
+
+
+def _rounded_rate(outcomes: list[bool]) -> float | None:
+    return round(_error_rate(outcomes), 3) if outcomes else None
+
+
+def _mean(scores: deque[float] | list[float]) -> float:
+    return sum(scores) / len(scores) if scores else 0.0
+
+
+def _rounded_mean(scores: list[float]) -> float | None:
+    return round(_mean(scores), 3) if scores else None
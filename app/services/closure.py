@@ -0,0 +1,88 @@
+from __future__ import annotations
+
+import logging
+import threading
+from collections import OrderedDict
+from dataclasses import dataclass
+from datetime import datetime, timezone
+
+from app.clients.report_sink import ReportSink
+
+logger = logging.getLogger(__name__)
+
+
+@dataclass(frozen=True)
+class OpenAnalysis:
+    session_key: str
+    analysis_id: str | None
+    thread_ts: str
+    incident_id: str | None
+    alertname: str | None
+    namespace: str | None
+    summary: str
+    opened_at: datetime
+
+
+class IncidentClosureTracker:
+    """Open firing analyses by alert session key, closed by the matching resolved alert.
+
+    Kept in memory per replica and bounded to ``max_open`` entries (oldest
+    dropped first). A closure notice is sent to the report sink with the
+    Slack thread of the resolved alert, so the backend can post it there.
+    """
+
+    def __init__(self, *, sink: ReportSink | None = None, max_open: int = 5000) -> None:
+        self._sink = sink
+        self._max_open = max(1, max_open)
+        self._open: OrderedDict[str, OpenAnalysis] = OrderedDict()
+        self._lock = threading.Lock()
+
+    def open(self, entry: OpenAnalysis) -> None:
+        with self._lock:
+            self._open.pop(entry.session_key, None)
+            self._open[entry.session_key] = entry
+            while len(self._open) > self._max_open:
+                self._open.popitem(last=False)
+
+    def close(self, session_key: str) -> OpenAnalysis | None:
+        with self._lock:
+            return self._open.pop(session_key, None)
+
+    def open_count(self) -> int:
+        with self._lock:
+            return len(self._open)
+
+    def notify(self, closure: dict[str, object]) -> dict[str, object] | None:
+        if self._sink is None:
+            return None
+        delivery = self._sink.send("incident_closure", closure)
+        logger.info(
+            "incident_closure thread_ts=%s delivered=%s",
+            closure.get("thread_ts"),
+            delivery.get("delivered"),
+        )
+        return delivery
+
+
+def incident_duration_seconds(
+    starts_at: datetime | None,
+    ends_at: datetime | None,
+    opened_at: datetime | None,
+    *,
+    now: datetime | None = None,
+) -> tuple[float | None, str | None]:
+    """Duration from the alert timestamps, else from when the firing analysis was opened."""
+    if starts_at is not None and ends_at is not None:
+        start, end = _as_utc(starts_at), _as_utc(ends_at)
+        if end >= start:
+            return round((end - start).total_seconds(), 1), "alert"
+    if opened_at is not None:
+        end = _as_utc(ends_at) if ends_at is not None else now or datetime.now(timezone.utc)
+        return round(max(0.0, (end - _as_utc(opened_at)).total_seconds()), 1), "tracked"
+    return None, None
+
+
+def _as_utc(value: datetime) -> datetime:
+    if value.tzinfo is None:
+        return value.replace(tzinfo=timezone.utc)
+    return value.astimezone(timezone.utc)
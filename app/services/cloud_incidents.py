@@ -0,0 +1,83 @@
+"""Match cloud provider status page incidents to an alert.
+
+An incident counts as a possible upstream cause when it affects the
+cluster's region, names one of the services the cluster depends on, and was
+ongoing when the alert started. Providers often post an incident well after
+impact began, so incidents reported up to an hour after ``startsAt`` still
+match.
+"""
+
+from __future__ import annotations
+
+import re
+from datetime import datetime, timedelta, timezone
+
+_POSTING_DELAY = timedelta(hours=1)
+_GLOBAL_REGIONS = frozenset({"global", "multi-region", "multiple regions"})
+
+
+def match_cloud_incidents(
+    incidents: list[dict[str, object]],
+    *,
+    region: str,
+    services: tuple[str, ...],
+    started_at: datetime,
+) -> list[dict[str, object]]:
+    if started_at.tzinfo is None:
+        started_at = started_at.replace(tzinfo=timezone.utc)
+    matched: list[dict[str, object]] = []
+    for incident in incidents:
+        text = " ".join(str(incident.get(key) or "") for key in ("title", "summary")).lower()
+        incident_start = _parse_time(incident.get("started_at"))
+        incident_end = _parse_time(incident.get("ended_at"))
+        if incident_start is not None and incident_start > started_at + _POSTING_DELAY:
+            continue
+        if incident_end is not None and incident_end < started_at:
+            continue
+        if not _region_matches(incident, region, text):
+            continue
+        affected = _service_matches(incident, services, text)
+        if not affected:
+            continue
+        matched.append(
+            {
+                **incident,
+                "matched_services": affected,
+                "minutes_from_alert": (
+                    round((incident_start - started_at).total_seconds() / 60, 1)
+                    if incident_start is not None
+                    else None
+                ),
+            }
+        )
+    return matched
+
+
+def _region_matches(incident: dict[str, object], region: str, text: str) -> bool:
+    regions = incident.get("regions")
+    region = region.lower()
+    if isinstance(regions, list) and regions:
+        names = {str(item).lower() for item in regions}
+        return region in names or bool(names & _GLOBAL_REGIONS)
+    # Free-text feeds (Azure) name regions by display name, e.g. "East US" for eastus.
+    compact = re.sub(r"[^a-z0-9]", "", text)
+    return region.replace("-", "") in compact or any(name in text for name in _GLOBAL_REGIONS)
+
+
+def _service_matches(
+    incident: dict[str, object], services: tuple[str, ...], text: str
+) -> list[str]:
+    named = incident.get("services")
+    names = [str(item).lower() for item in named] if isinstance(named, list) else []
+    haystack = " ".join([*names, text])
+    return [service for service in services if service.lower() in haystack]
+
+
+def _parse_time(value: object) -> datetime | None:
+    if not isinstance(value, str) or not value:
+        return None
+    try:
+        parsed = datetime.fromisoformat(value.replace("Z", "+00:00"))
+    except ValueError:
+        return None
+    return parsed if parsed.tzinfo else parsed.replace(tzinfo=timezone.utc)
@@ -0,0 +1,248 @@
+from __future__ import annotations
+
+from pathlib import PurePosixPath
+from typing import Protocol
+
+_REVISION_KEYS = ("org.opencontainers.image.revision", "org.label-schema.vcs-ref")
+_SOURCE_KEYS = ("org.opencontainers.image.source", "org.label-schema.vcs-url")
+_MAX_SUSPICIOUS_FILES = 30
+
+# File names whose change bumps dependencies.
+_DEPENDENCY_FILES = frozenset(
+    {
+        "go.mod",
+        "go.sum",
+        "package.json",
+        "package-lock.json",
+        "yarn.lock",
+        "pnpm-lock.yaml",
+        "poetry.lock",
+        "pipfile",
+        "pipfile.lock",
+        "pyproject.toml",
+        "uv.lock",
+        "cargo.toml",
+        "cargo.lock",
+        "pom.xml",
+        "build.gradle",
+        "build.gradle.kts",
+        "gradle.properties",
+        "gemfile",
+        "gemfile.lock",
+        "composer.json",
+        "composer.lock",
+    }
+)
+_CONFIG_SUFFIXES = frozenset(
+    {".yaml", ".yml", ".json", ".toml", ".ini", ".conf", ".cfg", ".properties", ".env"}
+)
+_CONFIG_DIRS = frozenset({"config", "configs", "conf", "deploy", "helm", "charts", "k8s"})
+_MIGRATION_DIRS = frozenset({"migrations", "migrate", "db", "alembic", "flyway", "liquibase"})
+
+
+class _RevisionSource(Protocol):
+    def get_deployment_revisions(
+        self, namespace: str, deployment: str, *, limit: int = 2
+    ) -> list[dict[str, object]] | None: ...
+
+
+class _GitHost(Protocol):
+    def compare(self, source_url: str, base: str, head: str) -> dict[str, object]: ...
+
+    def pipeline_status(self, source_url: str, sha: str) -> dict[str, object]: ...
+
+
+class _ImageDescriber(Protocol):
+    def describe_image(self, image: str, image_id: str | None = None) -> dict[str, object]: ...
+
+
+class CodeChangeCorrelator:
+    """List the commits shipped by a Deployment's latest rollout.
+
+    The git revision and source repository of the current and previous
+    ReplicaSet come from ``org.opencontainers.image.*`` pod template
+    annotations, else from the image labels in the registry. The commits in
+    between are fetched from GitHub/GitLab and changed files are flagged when
+    they touch dependencies, configuration or database migrations. With
+    pipeline checks on, the CI runs of the deployed commit are checked for
+    failed or skipped jobs.
+    """
+
+    def __init__(
+        self,
+        k8s_client: _RevisionSource,
+        git_client: _GitHost,
+        registry_client: _ImageDescriber | None = None,
+        *,
+        pipeline_checks: bool = False,
+    ) -> None:
+        self._k8s_client = k8s_client
+        self._git_client = git_client
+        self._registry_client = registry_client
+        self._pipeline_checks = pipeline_checks
+
+    def correlate(self, namespace: str, deployment: str) -> dict[str, object]:
+        revisions = self._k8s_client.get_deployment_revisions(namespace, deployment, limit=2)
+        if revisions is None:
+            return {"error": f"failed to read revisions of deployment {namespace}/{deployment}"}
+        if len(revisions) < 2:
+            return {"deployment": deployment, "warning": "no previous revision to compare"}
+        current, previous = revisions[0], revisions[1]
+
+        previous_images = {
+            container.get("name"): container.get("image")
+            for container in _containers(previous)
+        }
+        containers: list[dict[str, object]] = []
+        findings: list[str] = []
+        for container in _containers(current):
+            name = container.get("name")
+            image = container.get("image")
+            previous_image = previous_images.get(name)
+            entry: dict[str, object] = {
+                "container": name,
+                "image": image,
+                "previous_image": previous_image,
+            }
+            containers.append(entry)
+            if not isinstance(image, str) or not isinstance(previous_image, str):
+                entry["status"] = "new_container"
+                continue
+            if image == previous_image:
+                entry["status"] = "unchanged"
+                continue
+            head_source, head = self._resolve_revision(current, image)
+            _, base = self._resolve_revision(previous, previous_image)
+            entry.update({"revision": head, "previous_revision": base, "source": head_source})
+            if self._pipeline_checks and head_source and head:
+                pipeline = self._git_client.pipeline_status(head_source, head)
+                entry["pipeline"] = pipeline
+                findings.extend(_pipeline_findings(str(name), pipeline))
+            if not head_source or not head or not base:
+                entry["status"] = "no_revision_labels"
+                continue
+            if head == base:
+                entry["status"] = "same_revision"
+                continue
+            comparison = self._git_client.compare(head_source, base, head)
+            entry["comparison"] = comparison
+            suspicious = suspicious_changes(comparison.get("files"))
+            entry["status"] = "error" if "error" in comparison else "compared"
+            entry["suspicious_changes"] = suspicious[:_MAX_SUSPICIOUS_FILES]
+            findings.extend(_findings(str(name), comparison, suspicious))
+
+        return {
+            "deployment": deployment,
+            "namespace": namespace,
+            "current_revision": current.get("revision"),
+            "previous_revision": previous.get("revision"),
+            "rolled_out_at": current.get("created"),
+            "containers": containers,
+            "findings": findings,
+        }
+
+    def _resolve_revision(
+        self, revision: dict[str, object], image: str
+    ) -> tuple[str | None, str | None]:
+        build = revision.get("build")
+        annotations = build if isinstance(build, dict) else {}
+        source = _first_value(annotations, _SOURCE_KEYS)
+        sha = _first_value(annotations, _REVISION_KEYS)
+        if (source and sha) or self._registry_client is None:
+            return source, sha
+        described = self._registry_client.describe_image(image)
+        labels = described.get("build")
+        if isinstance(labels, dict):
+            source = source or _str_or_none(labels.get("source"))
+            sha = sha or _str_or_none(labels.get("revision"))
+        return source, sha
+
+
+def classify_change(path: str) -> str | None:
+    """Category of a changed file worth calling out in an RCA, or None."""
+    parts = [part.lower() for part in PurePosixPath(path).parts]
+    if not parts:
+        return None
+    name = parts[-1]
+    if name in _DEPENDENCY_FILES or (name.startswith("requirements") and name.endswith(".txt")):
+        return "dependency"
+    if any(part in _MIGRATION_DIRS for part in parts[:-1]) or name.endswith(".sql"):
+        return "migration"
+    if name.startswith("dockerfile") or name.startswith(".env"):
+        return "config"
+    if PurePosixPath(name).suffix in _CONFIG_SUFFIXES or any(
+        part in _CONFIG_DIRS for part in parts[:-1]
+    ):
+        return "config"
+    return None
+
+
+def suspicious_changes(files: object) -> list[dict[str, object]]:
+    if not isinstance(files, list):
+        return []
+    changes: list[dict[str, object]] = []
+    for item in files:
+        path = item.get("path") if isinstance(item, dict) else None
+        if not isinstance(path, str):
+            continue
+        category = classify_change(path)
+        if category is not None:
+            changes.append({"path": path, "category": category, "status": item.get("status")})
+    return changes
+
+
+def _findings(
+    container: str, comparison: dict[str, object], suspicious: list[dict[str, object]]
+) -> list[str]:
+    if "error" in comparison:
+        return [f"{container}: commit comparison failed ({comparison['error']})"]
+    findings = [
+        f"{container}: {comparison.get('commit_count')} commit(s) shipped in the latest rollout"
+    ]
+    for category in ("dependency", "config", "migration"):
+        paths = [str(item["path"]) for item in suspicious if item["category"] == category]
+        if paths:
+            shown = ", ".join(paths[:5]) + (f" (+{len(paths) - 5} more)" if len(paths) > 5 else "")
+            findings.append(f"{container}: {category} changes in {shown}")
+    return findings
+
+
+def _pipeline_findings(container: str, pipeline: dict[str, object]) -> list[str]:
+    if pipeline.get("partially_failed") is not True:
+        return []
+    runs = pipeline.get("runs")
+    failed: list[str] = []
+    skipped: list[str] = []
+    for run in runs if isinstance(runs, list) else []:
+        if isinstance(run, dict):
+            failed.extend(str(job) for job in run.get("failed_jobs") or [])
+            skipped.extend(str(job) for job in run.get("skipped_jobs") or [])
+    details = []
+    if failed:
+        details.append("failed: " + ", ".join(failed[:5]))
+    if skipped:
+        details.append("skipped: " + ", ".join(skipped[:5]))
+    finding = f"{container}: deployed from a partially failed pipeline"
+    if details:
+        finding += f" ({'; '.join(details)})"
+    if pipeline.get("skipped_migrations"):
+        finding += "; database migrations may not have run"
+    return [finding]
+
+
+def _containers(revision: dict[str, object]) -> list[dict[str, object]]:
+    containers = revision.get("containers")
+    if not isinstance(containers, list):
+        return []
+    return [item for item in containers if isinstance(item, dict)]
+
+
+def _first_value(mapping: dict[str, object], keys: tuple[str, ...]) -> str | None:
+    for key in keys:
+        if value := _str_or_none(mapping.get(key)):
+            return value
+    return None
+
+
+def _str_or_none(value: object) -> str | None:
+    return value if isinstance(value, str) and value else None
@@ -0,0 +1,367 @@
+"""Exit codes, signals and runtime error signatures with their usual causes.
+
+A static knowledge table the rule analyzers use to classify a crash before
+(or without) the LLM. ``classify_crash`` looks for a known error signature
+in the container's logs (JVM, Go, Node.js and Python runtime errors, then
+runtime-independent ones such as ``connection refused``) and otherwise falls
+back to the meaning of the exit code: codes above 128 are ``128 + signal``,
+so 137 is SIGKILL and 143 SIGTERM. Signatures are tried in table order, which
+puts the specific runtime errors ahead of the generic ones, and the last log
+line matching a signature is reported.
+"""
+
+from __future__ import annotations
+
+import re
+from dataclasses import dataclass
+
+
+@dataclass(frozen=True)
+class ErrorSignature:
+    key: str
+    runtime: str
+    category: str
+    pattern: re.Pattern[str]
+    cause: str
+    recommendation: str
+
+
+def _signature(
+    key: str, runtime: str, category: str, pattern: str, cause: str, recommendation: str
+) -> ErrorSignature:
+    return ErrorSignature(key, runtime, category, re.compile(pattern), cause, recommendation)
+
+
+_JVM_HEAP_FIX = (
+    "Raise -Xmx (or -XX:MaxRAMPercentage) within the container memory limit, or find the heap "
+    "growth with -XX:+HeapDumpOnOutOfMemoryError."
+)
+_MISSING_DEPENDENCY_FIX = "Add the missing dependency to the image or fix the module/class path."
+_CODE_BUG_FIX = "Fix the bug at the top of the stack trace; the crash repeats on every restart."
+
+SIGNATURES: tuple[ErrorSignature, ...] = (
+    _signature(
+        "jvm_heap_exhausted",
+        "jvm",
+        "out_of_memory",
+        r"java\.lang\.OutOfMemoryError: (Java heap space|GC overhead limit exceeded)",
+        "the JVM heap is exhausted",
+        _JVM_HEAP_FIX,
+    ),
+    _signature(
+        "jvm_metaspace_exhausted",
+        "jvm",
+        "out_of_memory",
+        r"java\.lang\.OutOfMemoryError: (Metaspace|Compressed class space)",
+        "the JVM metaspace is exhausted",
+        "Raise -XX:MaxMetaspaceSize or look for a class loader leak.",
+    ),
+    _signature(
+        "jvm_direct_memory_exhausted",
+        "jvm",
+        "out_of_memory",
+        r"java\.lang\.OutOfMemoryError: (Direct buffer memory|Cannot reserve .* direct buffer)",
+        "the JVM ran out of direct (off-heap) buffer memory",
+        "Raise -XX:MaxDirectMemorySize and the memory limit, or release direct buffers.",
+    ),
+    _signature(
+        "jvm_native_threads_exhausted",
+        "jvm",
+        "resource_limit",
+        r"OutOfMemoryError: unable to create (new )?native thread",
+        "the JVM cannot create more threads",
+        "Check the pod's PID limit and the thread count; bound the thread pools.",
+    ),
+    _signature(
+        "jvm_class_not_found",
+        "jvm",
+        "missing_dependency",
+        r"java\.lang\.(ClassNotFoundException|NoClassDefFoundError)",
+        "a class is missing from the classpath",
+        _MISSING_DEPENDENCY_FIX,
+    ),
+    _signature(
+        "jvm_class_version_mismatch",
+        "jvm",
+        "runtime_mismatch",
+        r"java\.lang\.UnsupportedClassVersionError",
+        "the image's JRE is older than the compiled bytecode",
+        "Run the application on the JDK version it was compiled for.",
+    ),
+    _signature(
+        "go_out_of_memory",
+        "go",
+        "out_of_memory",
+        r"fatal error: runtime: out of memory",
+        "the Go runtime could not allocate memory",
+        "Set GOMEMLIMIT below the memory limit or raise the limit; profile allocations with pprof.",
+    ),
+    _signature(
+        "go_nil_pointer",
+        "go",
+        "code_bug",
+        r"panic: runtime error: invalid memory address or nil pointer dereference",
+        "a nil pointer dereference",
+        _CODE_BUG_FIX,
+    ),
+    _signature(
+        "go_out_of_range",
+        "go",
+        "code_bug",
+        r"panic: runtime error: (index|slice bounds) out of range",
+        "an index or slice bounds error",
+        _CODE_BUG_FIX,
+    ),
+    _signature(
+        "go_concurrent_map_access",
+        "go",
+        "code_bug",
+        r"fatal error: concurrent map (writes|read and map write|iteration and map write)",
+        "a data race on a map",
+        "Guard the map with a mutex or use sync.Map; run the tests with -race.",
+    ),
+    _signature(
+        "go_deadlock",
+        "go",
+        "code_bug",
+        r"fatal error: all goroutines are asleep - deadlock!",
+        "a deadlock: all goroutines are blocked",
+        _CODE_BUG_FIX,
+    ),
+    _signature(
+        "node_heap_exhausted",
+        "node",
+        "out_of_memory",
+        r"JavaScript heap out of memory|Reached heap limit Allocation failed",
+        "the Node.js heap is exhausted",
+        "Raise --max-old-space-size within the container memory limit, or fix the heap growth.",
+    ),
+    _signature(
+        "node_module_not_found",
+        "node",
+        "missing_dependency",
+        r"Error: Cannot find module ",
+        "a Node.js module is missing",
+        _MISSING_DEPENDENCY_FIX,
+    ),
+    _signature(
+        "node_unhandled_rejection",
+        "node",
+        "code_bug",
+        r"UnhandledPromiseRejection|Unhandled promise rejection",
+        "an unhandled promise rejection",
+        "Handle the rejected promise; Node.js exits on unhandled rejections.",
+    ),
+    _signature(
+        "python_memory_error",
+        "python",
+        "out_of_memory",
+        r"^MemoryError\b",
+        "Python could not allocate memory",
+        "Raise the memory limit or reduce what the process loads into memory.",
+    ),
+    _signature(
+        "python_module_not_found",
+        "python",
+        "missing_dependency",
+        r"^(ModuleNotFoundError|ImportError): ",
+        "a Python module is missing",
+        "Install the missing package in the image or fix PYTHONPATH.",
+    ),
+    _signature(
+        "python_missing_env",
+        "python",
+        "configuration",
+        r"^KeyError: '[A-Z][A-Z0-9_]*'$",
+        "a required environment variable is not set",
+        "Set the environment variable in the pod spec, ConfigMap or Secret it is read from.",
+    ),
+    _signature(
+        "exec_format_error",
+        "any",
+        "runtime_mismatch",
+        r"exec format error",
+        "the image was built for another CPU architecture",
+        "Build a multi-arch image or schedule the pod on nodes of the image's architecture.",
+    ),
+    _signature(
+        "address_in_use",
+        "any",
+        "configuration",
+        r"address already in use|EADDRINUSE",
+        "the listen port is already taken",
+        "Give the container a free port; another container of the pod listens on it.",
+    ),
+    _signature(
+        "permission_denied",
+        "any",
+        "permissions",
+        r"[Pp]ermission denied|EACCES|PermissionError",
+        "a file or socket cannot be accessed",
+        "Check runAsUser/fsGroup, readOnlyRootFilesystem and the file modes in the image.",
+    ),
+    _signature(
+        "missing_file",
+        "any",
+        "configuration",
+        r"[Nn]o such file or directory|FileNotFoundError|ENOENT|file .* not found",
+        "a file the process needs is missing",
+        "Mount the missing file (ConfigMap, Secret or volume) or fix its path.",
+    ),
+    _signature(
+        "tls_verification_failed",
+        "any",
+        "tls",
+        r"x509: |SSLHandshakeException|CERTIFICATE_VERIFY_FAILED|unable to verify the first cert",
+        "TLS certificate verification failed",
+        "Trust the issuing CA in the image or fix the server certificate.",
+    ),
+    _signature(
+        "dns_resolution_failed",
+        "any",
+        "dependency_unavailable",
+        r"no such host|Name or service not known|ENOTFOUND|UnknownHostException"
+        r"|Temporary failure in name resolution",
+        "a dependency's host name does not resolve",
+        "Fix the host name or check cluster DNS and the dependency's Service.",
+    ),
+    _signature(
+        "connection_refused",
+        "any",
+        "dependency_unavailable",
+        r"[Cc]onnection refused|ECONNREFUSED",
+        "a dependency refused the connection",
+        "Check that the dependency is up and reachable, or retry on startup instead of exiting.",
+    ),
+)
+
+_SIGNALS = {
+    1: "SIGHUP",
+    2: "SIGINT",
+    3: "SIGQUIT",
+    4: "SIGILL",
+    5: "SIGTRAP",
+    6: "SIGABRT",
+    7: "SIGBUS",
+    8: "SIGFPE",
+    9: "SIGKILL",
+    10: "SIGUSR1",
+    11: "SIGSEGV",
+    12: "SIGUSR2",
+    13: "SIGPIPE",
+    14: "SIGALRM",
+    15: "SIGTERM",
+}
+# category, meaning
+_SIGNAL_MEANINGS = {
+    "SIGKILL": ("killed", "OOM kill or failed liveness probe"),
+    "SIGSEGV": ("segfault", "segmentation fault in native code"),
+    "SIGABRT": ("aborted", "the process aborted itself (failed assertion, runtime abort)"),
+    "SIGTERM": ("terminated", "asked to shut down (pod deletion, eviction, liveness probe)"),
+    "SIGBUS": ("bus_error", "invalid memory access, such as a truncated memory-mapped file"),
+    "SIGILL": ("illegal_instruction", "binary built for a newer CPU"),
+    "SIGFPE": ("arithmetic_error", "arithmetic exception such as integer division by zero"),
+    "SIGPIPE": ("broken_pipe", "wrote to a closed pipe or socket"),
+    "SIGINT": ("interrupted", "interrupted"),
+    "SIGHUP": ("hangup", "hangup"),
+}
+_EXIT_CODES = {
+    1: ("application_error", "application error"),
+    2: ("invalid_usage", "invalid arguments or shell misuse"),
+    125: ("container_runtime", "the container runtime could not run the command"),
+    126: ("not_executable", "command not executable"),
+    127: ("command_not_found", "command not found"),
+    255: ("exit_out_of_range", "exit status out of range, such as exit(-1)"),
+}
+_EXIT_RECOMMENDATIONS = {
+    "oom_killed": "Raise the memory limit or reduce the container's memory use.",
+    "killed": (
+        "Check for OOM kills (memory usage against the limit) and liveness probe failures "
+        "before the kill."
+    ),
+    "terminated": (
+        "Check for evictions, rollouts and liveness probe failures, and exit promptly on SIGTERM."
+    ),
+    "segfault": "Debug the native crash: native libraries, the base image's libc, core dumps.",
+    "aborted": "Look for the assertion or runtime abort message just before the exit.",
+    "illegal_instruction": "Build for the nodes' CPU or schedule onto newer nodes.",
+    "container_runtime": "Check the kubelet and container runtime logs on the node.",
+    "not_executable": "Make the entrypoint executable and check its shebang and architecture.",
+    "command_not_found": "Fix command/args or add the binary to the image (entrypoint, PATH).",
+}
+
+
+def describe_exit_code(code: object, reason: object = None) -> dict[str, object] | None:
+    """Signal, category and meaning of a non-zero exit code; ``None`` for 0 or unknown."""
+    try:
+        value = int(str(code))
+    except (TypeError, ValueError):
+        return None
+    if value == 0:
+        return None
+    signal = _SIGNALS.get(value - 128) if 128 < value < 160 else None
+    if reason == "OOMKilled":
+        category, meaning = "oom_killed", "killed by the kernel OOM killer at the memory limit"
+    elif signal is not None:
+        category, meaning = _SIGNAL_MEANINGS.get(signal, ("signal", f"killed by {signal}"))
+    elif value in _EXIT_CODES:
+        category, meaning = _EXIT_CODES[value]
+    else:
+        category, meaning = "application_error", "application-defined exit code"
+    return {"code": value, "signal": signal, "category": category, "meaning": meaning}
+
+
+def exit_code_hint(code: object) -> str | None:
+    """Short meaning of an exit code, like ``SIGKILL: OOM kill or failed liveness probe``."""
+    info = describe_exit_code(code)
+    if info is None or info["meaning"] == "application-defined exit code":
+        return None
+    return f"{info['signal']}: {info['meaning']}" if info["signal"] else str(info["meaning"])
+
+
+def exit_code_recommendation(category: object) -> str | None:
+    return _EXIT_RECOMMENDATIONS.get(str(category))
+
+
+def match_error_signature(lines: list[str]) -> tuple[ErrorSignature, str] | None:
+    """The first signature of the table found in *lines*, with the last line it matched."""
+    stripped = [line.strip() for line in lines if line.strip()]
+    for signature in SIGNATURES:
+        for line in reversed(stripped):
+            if signature.pattern.search(line):
+                return signature, line
+    return None
+
+
+def classify_crash(
+    lines: list[str], *, exit_code: object = None, reason: object = None
+) -> dict[str, object] | None:
+    """Deterministic crash class from the logs and the last exit code and reason."""
+    exit_info = describe_exit_code(exit_code, reason)
+    match = match_error_signature(lines)
+    if match is not None:
+        signature, line = match
+        classification: dict[str, object] = {
+            "source": "log_signature",
+            "signature": signature.key,
+            "runtime": signature.runtime,
+            "category": signature.category,
+            "cause": signature.cause,
+            "recommendation": signature.recommendation,
+            "matched_line": line,
+        }
+    elif exit_info is not None:
+        classification = {
+            "source": "exit_code",
+            "signature": None,
+            "runtime": None,
+            "category": exit_info["category"],
+            "cause": exit_info["meaning"],
+            "recommendation": exit_code_recommendation(exit_info["category"]),
+            "matched_line": None,
+        }
+    else:
+        return None
+    classification["exit_code"] = exit_info["code"] if exit_info else None
+    classification["signal"] = exit_info["signal"] if exit_info else None
+    return classification
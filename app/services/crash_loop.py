@@ -0,0 +1,223 @@
+"""Crash cause of CrashLoopBackOff containers, returned as ``crash_loop``.
+
+A container is crash looping when it waits in ``CrashLoopBackOff`` or a
+``BackOff`` event names it as a restarting failed container (between
+crashes it briefly runs). The previous container's logs, already collected
+with ``previous=true``, are searched from the end for the last stack trace
+(Python, Go, Java, Node.js) or, failing that, the last fatal/error line.
+Back-off delays come from the kubelet's waiting message when present;
+otherwise they are estimated from the restart count (10s doubling per
+restart, capped at 5 minutes). Each container also gets the deterministic
+``classification`` of the crash from the knowledge base in
+``crash_knowledge`` (known runtime error signature, else exit code meaning).
+"""
+
+from __future__ import annotations
+
+import re
+from datetime import datetime, timedelta
+
+from app.core.evidence_budget import strip_log_timestamp
+from app.models.k8s import K8sContext
+from app.services.crash_knowledge import classify_crash
+
+_BACKOFF_INITIAL_SECONDS = 10
+_BACKOFF_MAX_SECONDS = 300
+_MAX_TRACE_LINES = 30
+
+_BACKOFF_MESSAGE_RE = re.compile(r"back-off ((?:\d+h)?(?:\d+m)?(?:\d+s)?) restarting", re.I)
+_EVENT_CONTAINER_RE = re.compile(r"restarting failed container[= ](\S+)", re.I)
+_DURATION_PART_RE = re.compile(r"(\d+)([hms])")
+_GO_PANIC_RE = re.compile(r"^(panic: |fatal error: )")
+_JAVA_EXCEPTION_RE = re.compile(r"^(Exception in thread \S+ )?[\w.$]+(Exception|Error)(: .*)?$")
+_NODE_ERROR_RE = re.compile(r"^(Uncaught )?\w*Error(: .*)?$")
+_FATAL_LINE_RE = re.compile(r"\b(fatal|panic|critical|error|exception)\b", re.I)
+
+
+def build_crash_loop_analysis(k8s_context: K8sContext) -> dict[str, object] | None:
+    if k8s_context.pod_status is None:
+        return None
+    backoff_containers = _backoff_event_containers(k8s_context)
+    logs = {
+        snippet.container: ([strip_log_timestamp(line) for line in snippet.logs], snippet.stream)
+        for snippet in k8s_context.previous_logs
+    }
+    containers: list[dict[str, object]] = []
+    for status in k8s_context.pod_status.container_statuses:
+        if not isinstance(status, dict):
+            continue
+        name = str(status.get("name") or "unknown")
+        state = _state(status.get("state"))
+        waiting = state.get("type") == "waiting" and state.get("reason") == "CrashLoopBackOff"
+        if not waiting and name not in backoff_containers:
+            continue
+        lines, stream = logs.get(name, (None, None))
+        containers.append(_container_analysis(name, status, state, lines, stream))
+    if not containers:
+        return None
+    return {
+        "pod": k8s_context.pod_name,
+        "namespace": k8s_context.namespace,
+        "containers": containers,
+        "findings": [_finding(item) for item in containers],
+    }
+
+
+def extract_crash_cause(lines: list[str]) -> dict[str, object] | None:
+    """The last stack trace or fatal line in *lines*, or ``None`` when there is neither."""
+    lines = [line.rstrip() for line in lines]
+    for index in range(len(lines) - 1, -1, -1):
+        line = lines[index]
+        if line.startswith("Traceback (most recent call last):"):
+            trace = _python_trace(lines[index:])
+            return {"kind": "python", "fatal_line": trace[-1], "stack_trace": trace}
+        if _GO_PANIC_RE.match(line):
+            trace = _until_blank(lines[index:], max_blank=1)
+            return {"kind": "go", "fatal_line": line, "stack_trace": trace}
+        frame = lines[index + 1] if index + 1 < len(lines) else ""
+        # Java indents frames with a tab, Node.js with four spaces.
+        if frame.startswith("\tat ") and _JAVA_EXCEPTION_RE.match(line):
+            trace = _indented(lines[index:], prefixes=("at ", "...", "Caused by:"))
+            causes = [item for item in trace if item.startswith("Caused by:")]
+            return {
+                "kind": "java",
+                "fatal_line": causes[-1] if causes else line,
+                "stack_trace": trace,
+            }
+        if frame.startswith("    at ") and _NODE_ERROR_RE.match(line):
+            trace = _indented(lines[index:], prefixes=("at ",))
+            return {"kind": "node", "fatal_line": line, "stack_trace": trace}
+    # Without a stack trace, the last fatal or error line.
+    for line in reversed(lines):
+        if line.strip() and _FATAL_LINE_RE.search(line):
+            return {"kind": "log_line", "fatal_line": line.strip(), "stack_trace": []}
+    return None
+
+
+def _container_analysis(
+    name: str,
+    status: dict[str, object],
+    state: dict[str, object],
+    logs: list[str] | None,
+    stream: str | None = None,
+) -> dict[str, object]:
+    restarts = _int(status.get("restart_count")) or 0
+    last_state = _state(status.get("last_state"))
+    backoff = _parse_duration(_BACKOFF_MESSAGE_RE.search(str(state.get("message") or "")))
+    backoff_source = "kubelet"
+    if backoff is None:
+        backoff = min(_BACKOFF_INITIAL_SECONDS * 2 ** max(restarts - 1, 0), _BACKOFF_MAX_SECONDS)
+        backoff_source = "estimated"
+    finished_at = last_state.get("finished_at")
+    finished = _parse_time(finished_at)
+    return {
+        "name": name,
+        "restart_count": restarts,
+        "last_exit_code": _int(last_state.get("exit_code")),
+        "last_reason": last_state.get("reason"),
+        "last_finished_at": finished_at,
+        "backoff_seconds": backoff,
+        "backoff_source": backoff_source,
+        "next_restart_at": (
+            (finished + timedelta(seconds=backoff)).isoformat() if finished else None
+        ),
+        "log_stream": stream if logs else None,
+        "previous_logs_available": bool(logs) and stream == "previous",
+        "crash_cause": extract_crash_cause(logs) if logs else None,
+        "classification": classify_crash(
+            logs or [], exit_code=last_state.get("exit_code"), reason=last_state.get("reason")
+        ),
+    }
+
+
+def _finding(entry: dict[str, object]) -> str:
+    finding = (
+        f"container {entry['name']} is crash looping ({entry['restart_count']} restart(s), "
+        f"back-off {entry['backoff_seconds']}s"
+    )
+    if entry["next_restart_at"]:
+        finding += f", next restart at {entry['next_restart_at']}"
+    finding += ")"
+    cause = entry["crash_cause"]
+    if isinstance(cause, dict):
+        finding += f"; probable cause: {cause['fatal_line']}"
+        if entry["log_stream"] == "current":
+            finding += " (from the current container logs)"
+    elif not entry["log_stream"]:
+        finding += "; previous container logs are empty or unavailable"
+    else:
+        finding += f"; no fatal line in the {entry['log_stream']} container logs"
+    if entry["last_exit_code"] is not None:
+        finding += f" (exit code {entry['last_exit_code']})"
+    return finding
+
+
+def _python_trace(lines: list[str]) -> list[str]:
+    # Frames are indented; the exception line is the first unindented line after them.
+    trace = [lines[0]]
+    for line in lines[1:]:
+        trace.append(line)
+        if line and not line[0].isspace():
+            break
+    return trace[-_MAX_TRACE_LINES:]
+
+
+def _indented(lines: list[str], *, prefixes: tuple[str, ...]) -> list[str]:
+    trace = [lines[0]]
+    for line in lines[1:]:
+        if not line.lstrip().startswith(prefixes):
+            break
+        trace.append(line)
+    return trace[:_MAX_TRACE_LINES]
+
+
+def _until_blank(lines: list[str], *, max_blank: int) -> list[str]:
+    trace: list[str] = []
+    blank = 0
+    for line in lines:
+        if not line.strip():
+            blank += 1
+            if blank > max_blank:
+                break
+        trace.append(line)
+    return trace[:_MAX_TRACE_LINES]
+
+
+def _backoff_event_containers(k8s_context: K8sContext) -> set[str]:
+    names: set[str] = set()
+    for event in k8s_context.events:
+        if event.reason != "BackOff":
+            continue
+        match = _EVENT_CONTAINER_RE.search(event.message or "")
+        if match:
+            names.add(match.group(1).strip("\"'"))
+    return names
+
+
+def _parse_duration(match: re.Match[str] | None) -> int | None:
+    if match is None or not match.group(1):
+        return None
+    units = {"h": 3600, "m": 60, "s": 1}
+    return sum(
+        int(value) * units[unit] for value, unit in _DURATION_PART_RE.findall(match.group(1))
+    )
+
+
+def _parse_time(value: object) -> datetime | None:
+    if not isinstance(value, str) or not value:
+        return None
+    try:
+        return datetime.fromisoformat(value.replace("Z", "+00:00"))
+    except ValueError:
+        return None
+
+
+def _state(value: object) -> dict[str, object]:
+    return value if isinstance(value, dict) else {}
+
+
+def _int(value: object) -> int | None:
+    try:
+        return int(str(value))
+    except (TypeError, ValueError):
+        return None
@@ -0,0 +1,266 @@
+from __future__ import annotations
+
+import asyncio
+import dataclasses
+import logging
+import time
+import urllib.error
+import urllib.parse
+import urllib.request
+from collections.abc import Callable, Sequence
+from datetime import datetime, timedelta, timezone
+from typing import Protocol
+
+from app.clients.llm_providers import get_provider_config
+from app.core.config import SECRET_SETTING_FIELDS, Settings
+from app.core.egress import LLM_PROVIDER_HOSTS, check_egress
+from app.core.k8s_scope import (
+    current_access_scope,
+    record_denied_permissions,
+    scope_permissions,
+)
+from app.core.overrides import overrides_status
+from app.core.rbac import Permission, render_rbac_manifest, required_permissions
+from app.core.tls import open_url
+
+logger = logging.getLogger(__name__)
+
+# URLs whose path carries a credential (e.g. Slack-style incoming webhooks).
+_SECRET_PATH_URL_FIELDS = frozenset({"report_webhook_url", "review_webhook_url"})
+_REDACTED = "<redacted>"
+_LLM_PROBE_TIMEOUT_SECONDS = 5
+
+
+class _ClusterProbe(Protocol):
+    def describe_api_server(self) -> dict[str, object]: ...
+
+    def check_permissions(
+        self, namespace: str | None = None, permissions: Sequence[Permission] = ...
+    ) -> list[dict[str, object]]: ...
+
+
+class _PrometheusProbe(Protocol):
+    def query(self, query: str, *, time: str | None = None) -> dict[str, object]: ...
+
+
+class _LokiProbe(Protocol):
+    def list_labels(self) -> dict[str, object]: ...
+
+
+class _TempoProbe(Protocol):
+    def search_traces(
+        self, *, query: str, start: str, end: str, limit: int = 5
+    ) -> dict[str, object]: ...
+
+
+class DiagnosticsService:
+    """Answer "why is analysis empty?" in one call.
+
+    Collects the effective configuration with secrets redacted, a cheap probe
+    per data source, RBAC self-checks of the enabled collectors, LLM provider
+    reachability and the reload status of the runtime analysis overrides.
+    """
+
+    def __init__(
+        self,
+        settings: Settings,
+        k8s_client: _ClusterProbe,
+        *,
+        prometheus_client: _PrometheusProbe | None = None,
+        loki_client: _LokiProbe | None = None,
+        tempo_client: _TempoProbe | None = None,
+    ) -> None:
+        self._settings = settings
+        self._k8s_client = k8s_client
+        self._prometheus_client = prometheus_client
+        self._loki_client = loki_client
+        self._tempo_client = tempo_client
+
+    def collect(self, namespace: str | None = None) -> dict[str, object]:
+        permissions = self._k8s_client.check_permissions(namespace, self._required_permissions())
+        return {
+            "config": sanitize_settings(self._settings),
+            "data_sources": {
+                "kubernetes": self._k8s_client.describe_api_server(),
+                "prometheus": _timed_probe(self._prometheus_probe()),
+                "loki": _timed_probe(self._loki_client.list_labels if self._loki_client else None),
+                "tempo": _timed_probe(self._tempo_probe()),
+            },
+            "permissions": permissions,
+            "denied_permissions": [
+                f"{check['verb']} {check['resource']}"
+                for check in permissions
+                if check.get("allowed") is False
+            ],
+            "missing_permissions": missing_permissions(permissions),
+            "access_scope": current_access_scope().describe(),
+            "llm": self._probe_llm(),
+            "analysis_overrides": overrides_status(),
+        }
+
+    def rbac_manifest(self) -> str:
+        """Minimal ClusterRole/Role YAML for the collectors the current settings enable."""
+        return render_rbac_manifest(
+            self._required_permissions(),
+            service_account=self._settings.k8s_service_account,
+            namespace=self._settings.k8s_service_account_namespace,
+        )
+
+    def log_missing_permissions(self) -> dict[str, list[str]]:
+        """Review the required permissions once (on startup) and log the missing ones.
+
+        In least-privilege mode the denied calls are skipped from then on.
+        """
+        checks = self._k8s_client.check_permissions(None, self._required_permissions())
+        missing = missing_permissions(checks)
+        skipped = record_denied_permissions(checks)
+        if skipped:
+            logger.info("K8S_LEAST_PRIVILEGE: skipping %d denied Kubernetes call types", skipped)
+        for collector, checks in missing.items():
+            required = [check for check in checks if not check.endswith(" (optional)")]
+            # Add-on permissions are only missed when the add-on is installed.
+            logger.log(
+                logging.WARNING if required else logging.INFO,
+                "RBAC: %s lacks %s; see GET /diagnostics/rbac for the minimal manifest",
+                collector,
+                ", ".join(checks),
+            )
+        return missing
+
+    def _required_permissions(self) -> list[Permission]:
+        # Only what the access scope lets the collectors touch is needed.
+        return scope_permissions(required_permissions(self._settings), current_access_scope())
+
+    def _prometheus_probe(self) -> Callable[[], dict[str, object]] | None:
+        prometheus = self._prometheus_client
+        if prometheus is None:
+            return None
+        return lambda: prometheus.query("vector(1)")
+
+    def _tempo_probe(self) -> Callable[[], dict[str, object]] | None:
+        tempo = self._tempo_client
+        if tempo is None:
+            return None
+
+        def search() -> dict[str, object]:
+            end = datetime.now(timezone.utc)
+            return tempo.search_traces(
+                query="{}",
+                start=(end - timedelta(minutes=5)).isoformat(),
+                end=end.isoformat(),
+                limit=1,
+            )
+
+        return search
+
+    def _probe_llm(self) -> dict[str, object]:
+        config = get_provider_config(self._settings)
+        provider = self._settings.ai_provider.lower()
+        if config is None:
+            return {"provider": provider, "configured": False, "reachable": False}
+        provider = config.provider.value
+        host = LLM_PROVIDER_HOSTS.get(provider)
+        result: dict[str, object] = {
+            "provider": provider,
+            "model_id": config.model_id,
+            "configured": True,
+            "host": host,
+        }
+        if host is None:
+            result["reachable"] = None
+            return result
+        url = f"https://{host}/"
+        started = time.perf_counter()
+        try:
+            check_egress(url)
+            with open_url(urllib.request.Request(url), timeout=_LLM_PROBE_TIMEOUT_SECONDS) as resp:
+                result["status_code"] = resp.status
+        except urllib.error.HTTPError as exc:
+            # Any HTTP answer (401/404 without credentials) proves the network path works.
+            result["status_code"] = exc.code
+        except Exception as exc:  # noqa: BLE001
+            logger.warning("LLM provider %s unreachable: %s", provider, exc)
+            result["reachable"] = False
+            result["error"] = str(exc)
+            return result
+        result["reachable"] = True
+        result["latency_ms"] = round((time.perf_counter() - started) * 1000, 1)
+        return result
+
+
+async def run_startup_rbac_check(service: DiagnosticsService) -> None:
+    """Log missing permissions once at startup without delaying it."""
+    try:
+        await asyncio.to_thread(service.log_missing_permissions)
+    except Exception as exc:  # noqa: BLE001
+        logger.warning("RBAC self-check failed: %s", exc)
+
+
+def missing_permissions(permissions: list[dict[str, object]]) -> dict[str, list[str]]:
+    """Denied checks as ``verb resource`` per collector; add-on checks are marked optional."""
+    missing: dict[str, list[str]] = {}
+    for check in permissions:
+        if check.get("allowed") is not False:
+            continue
+        text = f"{check['verb']} {check['resource']}"
+        if check.get("group") not in (None, "core"):
+            text += f".{check['group']}"
+        if check.get("namespace") not in (None, "*"):
+            text += f" in {check['namespace']}"
+        if check.get("optional"):
+            text += " (optional)"
+        missing.setdefault(str(check.get("collector") or "analysis"), []).append(text)
+    return missing
+
+
+def _timed_probe(call: Callable[[], dict[str, object]] | None) -> dict[str, object]:
+    if call is None:
+        return {"configured": False}
+    started = time.perf_counter()
+    result = call()
+    probe: dict[str, object] = {
+        "configured": True,
+        "reachable": "error" not in result,
+        "latency_ms": round((time.perf_counter() - started) * 1000, 1),
+    }
+    endpoint = result.get("endpoint")
+    if isinstance(endpoint, dict):
+        probe["endpoint"] = {
+            key: _sanitize_url(value, keep_path=True) if key == "base_url" else value
+            for key, value in endpoint.items()
+        }
+    if "error" in result:
+        probe["error"] = result["error"]
+        probe["detail"] = result.get("detail")
+    return probe
+
+
+def sanitize_settings(settings: Settings) -> dict[str, object]:
+    """Effective settings with secrets redacted and credentials stripped from URLs."""
+    sanitized: dict[str, object] = {}
+    for field in dataclasses.fields(settings):
+        value = getattr(settings, field.name)
+        if field.name in SECRET_SETTING_FIELDS:
+            sanitized[field.name] = _REDACTED if value else None
+        else:
+            keep_path = field.name not in _SECRET_PATH_URL_FIELDS
+            sanitized[field.name] = _sanitize_value(value, keep_path=keep_path)
+    return sanitized
+
+
+def _sanitize_value(value: object, *, keep_path: bool) -> object:
+    if isinstance(value, str) and "://" in value:
+        return _sanitize_url(value, keep_path=keep_path)
+    if isinstance(value, tuple):
+        # Nested tuples (per-service or per-cluster entries) can hold URLs too.
+        return [_sanitize_value(item, keep_path=keep_path) for item in value]
+    return value
+
+
+def _sanitize_url(value: str, *, keep_path: bool) -> str:
+    parts = urllib.parse.urlsplit(value)
+    netloc = parts.hostname or ""
+    if parts.port:
+        netloc = f"{netloc}:{parts.port}"
+    path = parts.path if keep_path else ("/" + _REDACTED if parts.path.strip("/") else "")
+    return urllib.parse.urlunsplit((parts.scheme, netloc, path, "", ""))
@@ -0,0 +1,215 @@
+from __future__ import annotations
+
+import asyncio
+import logging
+import math
+import statistics
+import threading
+from collections import Counter, deque
+from dataclasses import dataclass
+from datetime import datetime, timedelta, timezone
+
+from app.clients.report_sink import ReportSink
+
+logger = logging.getLogger(__name__)
+
+_DIGEST_TOP_ITEMS = 10
+# Alerts that resolve faster than this are treated as transient.
+_TRANSIENT_ALERT_SECONDS = 300
+
+
+@dataclass(frozen=True)
+class AnalysisRecord:
+    recorded_at: datetime
+    alertname: str
+    namespace: str | None
+    severity: str | None
+    status: str
+    incident_id: str | None
+    fingerprint: str | None
+    root_causes: tuple[str, ...]
+    degraded: bool
+    duration_seconds: float | None = None
+
+
+class AnalysisLedger:
+    """Bounded in-memory log of performed analyses, the input of periodic digests.
+
+    Records are lost on restart; the digest only covers what this replica saw.
+    """
+
+    def __init__(self, max_records: int = 5000) -> None:
+        self._records: deque[AnalysisRecord] = deque(maxlen=max(1, max_records))
+        self._lock = threading.Lock()
+
+    def record(self, record: AnalysisRecord) -> None:
+        with self._lock:
+            self._records.append(record)
+
+    def since(self, start: datetime) -> list[AnalysisRecord]:
+        with self._lock:
+            return [record for record in self._records if record.recorded_at >= start]
+
+
+class DigestService:
+    """Summarize recent analyses into a trend report and deliver it to the report sink."""
+
+    def __init__(
+        self,
+        ledger: AnalysisLedger,
+        *,
+        sink: ReportSink | None = None,
+        period_hours: int = 24,
+    ) -> None:
+        self._ledger = ledger
+        self._sink = sink
+        self._period_hours = period_hours
+
+    @property
+    def enabled(self) -> bool:
+        return self._period_hours > 0
+
+    def build(self, period_hours: int | None = None) -> dict[str, object]:
+        hours = period_hours or self._period_hours or 24
+        end = datetime.now(timezone.utc)
+        start = end - timedelta(hours=hours)
+        records = self._ledger.since(start)
+
+        root_causes = Counter(cause for record in records for cause in record.root_causes)
+        alerts = Counter((record.alertname, record.namespace) for record in records)
+        return {
+            "period_start": start.isoformat(),
+            "period_end": end.isoformat(),
+            "period_hours": hours,
+            "analysis_count": len(records),
+            "degraded_count": sum(1 for record in records if record.degraded),
+            "top_root_causes": [
+                {"root_cause": cause, "count": count}
+                for cause, count in root_causes.most_common(_DIGEST_TOP_ITEMS)
+            ],
+            "noisiest_alerts": [
+                {"alertname": alertname, "namespace": namespace, "count": count}
+                for (alertname, namespace), count in alerts.most_common(_DIGEST_TOP_ITEMS)
+            ],
+            "unresolved_incidents": _unresolved_incidents(records),
+            "noisy_alert_rules": [
+                item for item in score_alert_noise(records) if item["suggestions"]
+            ][:_DIGEST_TOP_ITEMS],
+        }
+
+    def noise_report(
+        self, period_hours: int | None = None, *, min_occurrences: int = 3
+    ) -> dict[str, object]:
+        hours = period_hours or self._period_hours or 24
+        start = datetime.now(timezone.utc) - timedelta(hours=hours)
+        return {
+            "period_hours": hours,
+            "alerts": score_alert_noise(
+                self._ledger.since(start), min_occurrences=min_occurrences
+            ),
+        }
+
+    def send(self, period_hours: int | None = None) -> dict[str, object]:
+        report = self.build(period_hours)
+        if self._sink is not None:
+            report["delivery"] = self._sink.send("digest", report)
+        logger.info(
+            "digest period_hours=%s analyses=%s", report["period_hours"], report["analysis_count"]
+        )
+        return report
+
+
+def _unresolved_incidents(records: list[AnalysisRecord]) -> list[dict[str, object]]:
+    """Incidents (or bare alerts) whose latest analysis was for a firing alert."""
+    latest: dict[str, AnalysisRecord] = {}
+    for record in records:
+        key = record.incident_id or record.fingerprint
+        if key:
+            latest[key] = record
+    return [
+        {
+            "incident_id": record.incident_id,
+            "fingerprint": record.fingerprint,
+            "alertname": record.alertname,
+            "namespace": record.namespace,
+            "last_analyzed_at": record.recorded_at.isoformat(),
+        }
+        for record in latest.values()
+        if record.status == "firing"
+    ]
+
+
+def score_alert_noise(
+    records: list[AnalysisRecord], *, min_occurrences: int = 3
+) -> list[dict[str, object]]:
+    """Score alert rules by actionability and suggest threshold/``for:`` tuning.
+
+    An analysis is actionable when the rule analyzers identified a cause. Alerts
+    whose resolved analyses show they cleared within a few minutes are transient.
+    ``noise_score`` is 0 (always actionable) to 1 (pure noise), noisiest first.
+    """
+    groups: dict[str, list[AnalysisRecord]] = {}
+    for record in records:
+        groups.setdefault(record.alertname, []).append(record)
+
+    scored: list[tuple[float, dict[str, object]]] = []
+    for alertname, group in groups.items():
+        firing = [record for record in group if record.status == "firing"]
+        if len(firing) < min_occurrences:
+            continue
+        actionable = sum(1 for record in firing if record.root_causes)
+        actionability = actionable / len(firing)
+        durations = [
+            record.duration_seconds for record in group if record.duration_seconds is not None
+        ]
+        transient = sum(1 for value in durations if value < _TRANSIENT_ALERT_SECONDS)
+        transient_ratio = transient / len(durations) if durations else None
+        noise_score = 1 - actionability
+        if transient_ratio is not None:
+            noise_score = (noise_score + transient_ratio) / 2
+        severities = Counter(record.severity for record in firing if record.severity)
+
+        suggestions: list[str] = []
+        if transient_ratio is not None and transient_ratio >= 0.5:
+            median_seconds = statistics.median(durations)
+            for_minutes = max(5, math.ceil(median_seconds * 2 / 60))
+            suggestions.append(
+                f"{transient} of {len(durations)} firings resolved within "
+                f"{_TRANSIENT_ALERT_SECONDS // 60} minutes (median {median_seconds:.0f}s); "
+                f"raise the rule's `for:` duration to at least {for_minutes}m"
+            )
+        if actionability < 0.3:
+            severity = severities.most_common(1)[0][0] if severities else None
+            suggestions.append(
+                f"only {actionable} of {len(firing)} firings had an identifiable cause; "
+                "raise the alert threshold"
+                + (f" or lower its severity from {severity}" if severity else "")
+            )
+        scored.append(
+            (
+                round(noise_score, 2),
+                {
+                    "alertname": alertname,
+                    "firing_count": len(firing),
+                    "actionable_count": actionable,
+                    "actionability": round(actionability, 2),
+                    "transient_ratio": (
+                        round(transient_ratio, 2) if transient_ratio is not None else None
+                    ),
+                    "noise_score": round(noise_score, 2),
+                    "suggestions": suggestions,
+                },
+            )
+        )
+    scored.sort(key=lambda item: (-item[0], str(item[1]["alertname"])))
+    return [item for _, item in scored]
+
+
+async def run_digest_scheduler(service: DigestService, interval_seconds: int) -> None:
+    """Send a digest every *interval_seconds* (first one after a full period) until cancelled."""
+    while True:
+        await asyncio.sleep(interval_seconds)
+        try:
+            await asyncio.to_thread(service.send)
+        except Exception as exc:  # noqa: BLE001
+            logger.warning("Digest delivery failed: %s", exc)
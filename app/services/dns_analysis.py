@@ -0,0 +1,176 @@
+"""Cluster DNS health behind DNS-failure alerts, returned as ``dns_analysis``.
+
+The diagnostic runs for alerts whose name or annotations mention DNS errors
+or upstream timeouts, and for alerts whose pod logs show failed name
+lookups (see ``dns_alert``). ``KubernetesClient.get_cluster_dns_status``
+reads the endpoints and pods of the cluster DNS Service (``kube-system/
+kube-dns`` by default) and scans the recent CoreDNS logs; the verdict is
+``healthy`` when every DNS endpoint is ready and the logs show no SERVFAIL
+spike, ``degraded`` when some endpoints are not ready or CoreDNS answers
+SERVFAIL or times out upstream, and ``unavailable`` when no endpoint is
+ready. A healthy CoreDNS points at the client side (``dnsPolicy``,
+``ndots``, NetworkPolicy egress to port 53) instead.
+"""
+
+from __future__ import annotations
+
+from app.models.k8s import K8sContext
+
+_DNS_ALERT_MARKERS = (
+    "dns",
+    "nxdomain",
+    "servfail",
+    "no such host",
+    "name resolution",
+    "resolve host",
+    "could not resolve",
+    "temporary failure in name",
+)
+_DNS_LOG_MARKERS = (
+    "no such host",
+    "name resolution",
+    "could not resolve",
+    "temporary failure in name",
+    "servfail",
+    "nxdomain",
+)
+# SERVFAIL answers in the scanned log window above which CoreDNS counts as degraded.
+_SERVFAIL_SPIKE = 10
+_ALERT_LOG_LINES_IN_FINDING = 3
+_NOT_READY_PODS_IN_FINDING = 3
+_LOG_SAMPLES = 6
+
+
+def dns_alert(
+    labels: dict[str, str], annotations: dict[str, str], k8s_context: K8sContext
+) -> str | None:
+    """Why an alert looks like a DNS failure (``alert`` or ``logs``), else ``None``.
+
+    Timeouts in the pod logs count only when they are lookups against port 53,
+    since most timeouts are about the application's own dependencies.
+    """
+    text = " ".join(
+        [labels.get("alertname", ""), *(str(value) for value in annotations.values())]
+    ).lower()
+    if "coredns" in text or any(marker in text for marker in _DNS_ALERT_MARKERS):
+        return "alert"
+    if _alert_log_lines(k8s_context):
+        return "logs"
+    return None
+
+
+def build_dns_analysis(k8s_context: K8sContext) -> dict[str, object] | None:
+    data = k8s_context.cluster_dns
+    if not data:
+        return None
+    name = f"{data.get('namespace')}/{data.get('service')}"
+    result: dict[str, object] = {
+        "service": name,
+        "trigger": data.get("trigger"),
+        "verdict": "unknown",
+        "cause": None,
+        "ready_endpoints": 0,
+        "endpoints": 0,
+        "not_ready_pods": [],
+        "restarts": 0,
+        "servfail": 0,
+        "errors": 0,
+        "upstream_timeouts": 0,
+        "log_samples": [],
+        "alert_log_lines": _alert_log_lines(k8s_context)[:_ALERT_LOG_LINES_IN_FINDING],
+        "findings": [],
+    }
+    if not data.get("found"):
+        result.update(
+            verdict="unavailable",
+            cause="dns_service_not_found",
+            findings=[
+                f"cluster DNS service {name} does not exist or could not be read; set "
+                "CLUSTER_DNS_NAMESPACE/CLUSTER_DNS_SERVICE when the cluster runs DNS elsewhere"
+            ],
+        )
+        return result
+    endpoints = [item for item in _dicts(data.get("endpoints")) if not item.get("terminating")]
+    ready = sum(1 for item in endpoints if item.get("ready"))
+    pods = _dicts(data.get("pods"))
+    not_ready_pods = [str(pod.get("name")) for pod in pods if not pod.get("ready")]
+    restarts = sum(
+        int(container.get("restart_count") or 0)
+        for pod in pods
+        for container in _dicts(pod.get("containers"))
+    )
+    logs = _dicts(data.get("logs"))
+    servfail = sum(int(item.get("servfail") or 0) for item in logs)
+    errors = sum(int(item.get("errors") or 0) for item in logs)
+    upstream_timeouts = sum(int(item.get("upstream_timeouts") or 0) for item in logs)
+    samples = [str(line) for item in logs for line in item.get("samples") or []]
+    result.update(
+        ready_endpoints=ready,
+        endpoints=len(endpoints),
+        not_ready_pods=not_ready_pods,
+        restarts=restarts,
+        servfail=servfail,
+        errors=errors,
+        upstream_timeouts=upstream_timeouts,
+        log_samples=samples[:_LOG_SAMPLES],
+    )
+    findings: list[str] = []
+    if not ready:
+        result.update(verdict="unavailable", cause="no_ready_endpoints")
+        findings.append(
+            f"cluster DNS service {name} has no ready endpoints ({len(pods)} DNS pods selected); "
+            "every in-cluster name lookup fails"
+        )
+    elif ready < len(endpoints) or not_ready_pods:
+        result.update(verdict="degraded", cause="pods_not_ready")
+        findings.append(
+            f"cluster DNS service {name} has {ready} of {len(endpoints)} endpoints ready; "
+            "not ready: " + ", ".join(not_ready_pods[:_NOT_READY_PODS_IN_FINDING])
+        )
+    if restarts:
+        findings.append(f"DNS pods restarted {restarts} times")
+    if upstream_timeouts:
+        findings.append(
+            f"CoreDNS logged {upstream_timeouts} upstream timeouts; the forward resolver "
+            "(/etc/resolv.conf of the nodes or the configured forwarders) is slow or unreachable"
+        )
+    if servfail >= _SERVFAIL_SPIKE:
+        findings.append(f"CoreDNS answered SERVFAIL {servfail} times in the scanned log window")
+    elif errors and not upstream_timeouts:
+        findings.append(f"CoreDNS logged {errors} errors")
+    if result["verdict"] == "unknown":
+        if upstream_timeouts:
+            result.update(verdict="degraded", cause="upstream_timeouts")
+        elif servfail >= _SERVFAIL_SPIKE:
+            result.update(verdict="degraded", cause="servfail_spike")
+        elif errors:
+            result.update(verdict="degraded", cause="dns_errors")
+        else:
+            result["verdict"] = "healthy"
+            findings.append(
+                f"cluster DNS {name} looks healthy ({ready} ready endpoints, no SERVFAIL spike); "
+                "check the pod's dnsPolicy and ndots search path, NetworkPolicy egress to "
+                "port 53 and whether the looked-up name exists"
+            )
+    read_errors = [item for item in logs if item.get("error")]
+    if read_errors:
+        findings.append(
+            "could not read the logs of DNS pods "
+            + ", ".join(str(item.get("pod")) for item in read_errors)
+        )
+    result["findings"] = findings
+    return result
+
+
+def _alert_log_lines(k8s_context: K8sContext) -> list[str]:
+    return [
+        line.strip()
+        for snippet in [*k8s_context.current_logs, *k8s_context.previous_logs]
+        for line in snippet.logs
+        if any(marker in line.lower() for marker in _DNS_LOG_MARKERS)
+        or ("i/o timeout" in line and ":53" in line)
+    ]
+
+
+def _dicts(value: object) -> list[dict[str, object]]:
+    return [item for item in value if isinstance(item, dict)] if isinstance(value, list) else []
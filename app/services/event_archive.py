@@ -0,0 +1,116 @@
+"""Cluster events persisted beyond the API server's event TTL (eventrouter-style).
+
+``EventArchiver`` watches core Events across all namespaces and upserts them
+into the event archive by uid, so their count and lastTimestamp stay
+current. Analyses merge archived events of the alerting pod (or workload)
+into the live ones with ``merge_archived_events``, which lets alerts
+reported hours later and backfill re-runs still see events Kubernetes has
+already garbage-collected.
+"""
+
+from __future__ import annotations
+
+import asyncio
+import logging
+import threading
+from collections.abc import Iterable
+from datetime import datetime, timezone
+from typing import Protocol
+
+from app.clients.event_archive import EventArchive
+from app.models.k8s import PodEventSummary
+
+logger = logging.getLogger(__name__)
+
+_BATCH_SIZE = 100
+_RETRY_SECONDS = 30
+
+
+class _EventWatcher(Protocol):
+    def watch_cluster_events(
+        self, timeout_seconds: int
+    ) -> Iterable[tuple[str, PodEventSummary]]: ...
+
+
+class EventArchiver:
+    def __init__(
+        self,
+        k8s_client: _EventWatcher,
+        archive: EventArchive,
+        *,
+        watch_seconds: int = 300,
+        batch_size: int = _BATCH_SIZE,
+    ) -> None:
+        self._k8s_client = k8s_client
+        self._archive = archive
+        self._watch_seconds = max(1, watch_seconds)
+        self._batch_size = max(1, batch_size)
+        self._stopped = threading.Event()
+
+    def run_once(self) -> int:
+        """Archive the events of one watch window and return how many were written."""
+        recorded = 0
+        batch: list[tuple[str, PodEventSummary]] = []
+        for uid, event in self._k8s_client.watch_cluster_events(self._watch_seconds):
+            if self._stopped.is_set():
+                break
+            batch.append((uid, event))
+            if len(batch) >= self._batch_size:
+                recorded += self._archive.record(batch)
+                batch = []
+        recorded += self._archive.record(batch)
+        logger.debug("event_archive recorded=%d", recorded)
+        return recorded
+
+    def stop(self) -> None:
+        self._stopped.set()
+
+
+async def run_event_archiver(archiver: EventArchiver) -> None:
+    """Re-open the cluster event watch after each window until cancelled."""
+    try:
+        while True:
+            try:
+                await asyncio.to_thread(archiver.run_once)
+            except Exception as exc:  # noqa: BLE001
+                logger.warning("Event archive watch failed: %s", exc)
+                await asyncio.sleep(_RETRY_SECONDS)
+    finally:
+        archiver.stop()
+
+
+def merge_archived_events(
+    live: list[PodEventSummary], archived: list[PodEventSummary]
+) -> list[PodEventSummary]:
+    """Live events followed by the archived events Kubernetes no longer returns.
+
+    An archived event matches a live one with the same involved object,
+    reason, message and first timestamp; the live copy is kept.
+    """
+    seen = {_event_key(event) for event in live}
+    missing = [event for event in archived if _event_key(event) not in seen]
+    return [*live, *missing]
+
+
+def _event_key(event: PodEventSummary) -> tuple[object, ...]:
+    involved = event.involved_object or {}
+    return (
+        involved.get("kind"),
+        involved.get("name"),
+        event.reason,
+        event.message,
+        _normalize_time(event.first_timestamp),
+    )
+
+
+def _normalize_time(value: str | None) -> str | None:
+    # Stored timestamps come back in the database session's time zone.
+    if not value:
+        return None
+    try:
+        parsed = datetime.fromisoformat(value.replace("Z", "+00:00"))
+    except ValueError:
+        return value
+    if parsed.tzinfo is None:
+        parsed = parsed.replace(tzinfo=timezone.utc)
+    return parsed.astimezone(timezone.utc).isoformat()
@@ -0,0 +1,234 @@
+"""Evicted and preempted pods, returned as ``eviction_analysis``.
+
+A pod is removed from its node by one of several components, and each one
+leaves a different trace: the kubelet sets ``status.reason=Evicted`` and an
+``Evicted`` event when the node runs low on memory or disk, or when the pod
+exceeds its own ephemeral storage limits; the scheduler records a
+``Preempted`` event when it frees room for a higher-priority pod; the
+Eviction API (``kubectl drain``, cluster autoscaler scale-down, descheduler)
+and the taint manager only leave a ``DisruptionTarget`` condition. Each
+eviction is reported with who evicted which pod and why, and the web UI adds
+them to the incident timeline.
+"""
+
+from __future__ import annotations
+
+import re
+
+from app.models.k8s import K8sContext
+
+_EVENT_REASONS = frozenset({"Evicted", "Preempted", "TaintManagerEviction"})
+_LOW_RESOURCE_RE = re.compile(r"The node was low on resource: ([\w.-]*\w)")
+_THRESHOLD_RE = re.compile(r"Threshold quantity: (\S+?), available: (\S+?)\.?(?:\s|$)")
+_CONTAINER_USAGE_RE = re.compile(
+    r"Container (\S+) was using (\S+?), request is (\S+?), has larger consumption of ([\w.-]+)"
+)
+_PREEMPTED_RE = re.compile(r"Preempted by (?:pod )?(\S+) on node (\S+?)\.?$")
+_EPHEMERAL_MARKERS = ("ephemeral local storage", "local ephemeral storage", "EmptyDir volume")
+# DisruptionTarget condition reasons, for evictions that leave no event on the pod.
+_CONDITION_KINDS = {
+    "PreemptionByScheduler": "preemption",
+    "EvictionByEvictionAPI": "api_eviction",
+    "DeletionByTaintManager": "taint_manager",
+    "TerminationByKubelet": "node_pressure",
+}
+_EVICTED_BY = {
+    "node_pressure": "kubelet",
+    "ephemeral_storage": "kubelet",
+    "node_shutdown": "kubelet",
+    "preemption": "scheduler",
+    "api_eviction": "eviction_api",
+    "taint_manager": "taint_manager",
+}
+
+
+def build_eviction_analysis(k8s_context: K8sContext) -> dict[str, object] | None:
+    evictions: list[dict[str, object]] = []
+    seen: set[tuple[object, str]] = set()
+
+    def add(entry: dict[str, object]) -> None:
+        key = (entry["pod"], str(entry["kind"]))
+        if key not in seen:
+            seen.add(key)
+            evictions.append(entry)
+
+    status = k8s_context.pod_status
+    node = status.node_name if status is not None else None
+    for event in k8s_context.events:
+        if event.reason not in _EVENT_REASONS:
+            continue
+        involved = event.involved_object or {}
+        pod = involved.get("name") or k8s_context.pod_name
+        message = (event.message or "").strip()
+        kind = "taint_manager" if event.reason == "TaintManagerEviction" else _kind(message)
+        add(
+            {
+                **_eviction(pod, kind, message, node if pod == k8s_context.pod_name else None),
+                "time": event.last_timestamp or event.first_timestamp,
+                "count": event.count or 1,
+                "source": "event",
+            }
+        )
+    status_kind = _status_kind(k8s_context)
+    if status is not None and status_kind is not None:
+        add(
+            {
+                **_eviction(k8s_context.pod_name, status_kind, status.message or "", node),
+                "time": _disruption_time(k8s_context),
+                "count": 1,
+                "source": "status",
+            }
+        )
+    condition = _disruption_condition(k8s_context)
+    if condition is not None:
+        message = str(condition.get("message") or "")
+        kind = _CONDITION_KINDS.get(str(condition.get("reason")))
+        if kind == "node_pressure":
+            kind = _kind(message)
+        if kind is not None:
+            add(
+                {
+                    **_eviction(k8s_context.pod_name, kind, message, node),
+                    "time": condition.get("last_transition_time"),
+                    "count": 1,
+                    "source": "condition",
+                }
+            )
+    if not evictions:
+        return None
+    spec = k8s_context.pod_spec if isinstance(k8s_context.pod_spec, dict) else {}
+    priority_class = spec.get("priority_class_name")
+    return {
+        "pod": k8s_context.pod_name,
+        "namespace": k8s_context.namespace,
+        "priority_class_name": priority_class,
+        "evictions": evictions,
+        "findings": [
+            _finding(entry, priority_class if entry["pod"] == k8s_context.pod_name else False)
+            for entry in evictions
+        ],
+    }
+
+
+def _status_kind(k8s_context: K8sContext) -> str | None:
+    status = k8s_context.pod_status
+    if status is None or not status.message:
+        return None
+    if status.reason == "Evicted":
+        return _kind(status.message)
+    # Graceful node shutdown marks the pod Failed with reason Terminated.
+    if status.reason == "Terminated" and _kind(status.message) == "node_shutdown":
+        return "node_shutdown"
+    return None
+
+
+def _kind(message: str) -> str:
+    if _PREEMPTED_RE.search(message) or "preempting to accommodate" in message:
+        return "preemption"
+    if any(marker in message for marker in _EPHEMERAL_MARKERS):
+        return "ephemeral_storage"
+    if "imminent node shutdown" in message:
+        return "node_shutdown"
+    if "Taint manager" in message:
+        return "taint_manager"
+    if "Eviction API" in message:
+        return "api_eviction"
+    return "node_pressure"
+
+
+def _eviction(pod: object, kind: str, message: str, node: object) -> dict[str, object]:
+    entry: dict[str, object] = {
+        "pod": pod,
+        "kind": kind,
+        "evicted_by": _EVICTED_BY[kind],
+        "node": node,
+        "resource": None,
+        "threshold": None,
+        "available": None,
+        "containers": [],
+        "preemptor": None,
+        "message": message,
+    }
+    if kind == "preemption":
+        match = _PREEMPTED_RE.search(message)
+        if match is not None:
+            entry.update(preemptor=match.group(1), node=match.group(2))
+    elif kind == "node_pressure":
+        low = _LOW_RESOURCE_RE.search(message)
+        threshold = _THRESHOLD_RE.search(message)
+        entry.update(
+            resource=low.group(1) if low else None,
+            threshold=threshold.group(1) if threshold else None,
+            available=threshold.group(2) if threshold else None,
+            containers=[
+                {"name": name, "usage": usage, "request": request}
+                for name, usage, request, _ in _CONTAINER_USAGE_RE.findall(message)
+            ],
+        )
+    elif kind == "ephemeral_storage":
+        entry["resource"] = "ephemeral-storage"
+    return entry
+
+
+def _finding(entry: dict[str, object], priority_class: object) -> str:
+    # priority_class is False for other pods of the workload, whose spec was not read.
+    pod = entry["pod"] or "?"
+    node = f" on {entry['node']}" if entry.get("node") else ""
+    kind = entry["kind"]
+    if kind == "preemption":
+        finding = (
+            f"pod {pod} was preempted by the scheduler{node} to make room for higher-priority "
+            f"pod {entry.get('preemptor') or '?'}"
+        )
+        if priority_class is False:
+            return finding
+        if priority_class:
+            return f"{finding}; its priority class is {priority_class}"
+        return f"{finding}; it has no priorityClassName and runs at the default priority"
+    if kind == "api_eviction":
+        return (
+            f"pod {pod} was evicted through the Eviction API{node} (kubectl drain, cluster "
+            "autoscaler scale-down or a descheduler)"
+        )
+    if kind == "taint_manager":
+        return (
+            f"pod {pod} was deleted by the taint manager{node}: the node has a NoExecute "
+            "taint the pod does not tolerate"
+        )
+    if kind == "node_shutdown":
+        return f"pod {pod} was terminated by the kubelet{node} because the node is shutting down"
+    if kind == "ephemeral_storage":
+        return (
+            f"pod {pod} was evicted by the kubelet{node} for its own disk usage: "
+            f"{str(entry['message']).strip().rstrip('.')}"
+        )
+    finding = f"pod {pod} was evicted by the kubelet{node}: the node was low on "
+    finding += str(entry.get("resource") or "a resource")
+    if entry.get("available") and entry.get("threshold"):
+        finding += f" (available {entry['available']}, threshold {entry['threshold']})"
+    containers = entry.get("containers")
+    if isinstance(containers, list) and containers:
+        finding += "; " + ", ".join(
+            f"container {item['name']} used {item['usage']} with a request of {item['request']}"
+            for item in containers
+        )
+        finding += ", and pods using more than they request are evicted first"
+    return finding
+
+
+def _disruption_condition(k8s_context: K8sContext) -> dict[str, object] | None:
+    if k8s_context.pod_status is None:
+        return None
+    return next(
+        (
+            dict(condition)
+            for condition in k8s_context.pod_status.conditions
+            if condition.get("type") == "DisruptionTarget" and condition.get("status") == "True"
+        ),
+        None,
+    )
+
+
+def _disruption_time(k8s_context: K8sContext) -> object:
+    condition = _disruption_condition(k8s_context)
+    return condition.get("last_transition_time") if condition is not None else None
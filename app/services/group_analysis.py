@@ -0,0 +1,194 @@
+from __future__ import annotations
+
+import logging
+from collections import Counter
+from typing import Protocol
+from uuid import uuid4
+
+from app.clients.strands_agent import AnalysisEngine
+from app.core.masking import Masker, RegexMasker
+from app.schemas.alert import Alert
+from app.schemas.analysis import AlertAnalysisRequest, AlertGroupAnalysisRequest
+
+logger = logging.getLogger(__name__)
+
+_COMMON_CAUSE_PREFIX = "COMMON_CAUSE:"
+
+
+class _AlertAnalyzer(Protocol):
+    def analyze(
+        self, request: AlertAnalysisRequest
+    ) -> tuple[str, str, str, dict[str, object], list[dict[str, object]]]: ...
+
+
+class AlertGroupService:
+    """Analyze the alerts of one webhook group and summarize them in a single message.
+
+    Alerts are de-duplicated by fingerprint and analyzed one by one (firing
+    first, at most ``max_alerts``); the rest are only counted. Members are
+    correlated on shared node, workload, namespace and alertname, and the
+    engine writes one group summary from the member summaries. Without an
+    engine (or when it fails) the summary is built from the correlation.
+    """
+
+    def __init__(
+        self,
+        analysis_service: _AlertAnalyzer,
+        analysis_engine: AnalysisEngine | None,
+        *,
+        masker: Masker | None = None,
+        max_alerts: int = 10,
+    ) -> None:
+        self._analysis_service = analysis_service
+        self._analysis_engine = analysis_engine
+        self._masker = masker or RegexMasker()
+        self._max_alerts = max(1, max_alerts)
+
+    def analyze(self, request: AlertGroupAnalysisRequest) -> dict[str, object]:
+        alerts = _unique_alerts(request.alerts)
+        members: list[dict[str, object]] = []
+        for alert in alerts[: self._max_alerts]:
+            members.append(self._analyze_member(request, alert))
+
+        shared = shared_dimensions(members)
+        common_cause: str | None = None
+        summary = ""
+        degraded = False
+        if self._analysis_engine is not None:
+            try:
+                answer = self._analysis_engine.analyze(
+                    _build_group_prompt(request, members, shared, len(alerts), self._masker),
+                    f"group:{uuid4().hex[:12]}",
+                )
+                common_cause, summary = _parse_group_summary(
+                    self._masker.mask_text(answer if isinstance(answer, str) else "")
+                )
+            except Exception as exc:  # noqa: BLE001
+                logger.warning("Group summary failed: %s", exc)
+        if not summary:
+            degraded = True
+            summary = fallback_group_summary(members, shared, len(alerts))
+
+        return {
+            "alert_count": len(alerts),
+            "analyzed_count": len(members),
+            "group_summary": self._masker.mask_text(summary),
+            "common_cause": self._masker.mask_text(common_cause) if common_cause else None,
+            "shared": shared,
+            "degraded": degraded,
+            "alerts": members,
+        }
+
+    def _analyze_member(
+        self, request: AlertGroupAnalysisRequest, alert: Alert
+    ) -> dict[str, object]:
+        member_request = AlertAnalysisRequest(
+            alert=alert, thread_ts=request.thread_ts, incident_id=request.incident_id
+        )
+        _, summary, _, context, _ = self._analysis_service.analyze(member_request)
+        pod_status = context.get("pod_status")
+        node = pod_status.get("node_name") if isinstance(pod_status, dict) else None
+        return {
+            "fingerprint": alert.fingerprint,
+            "alertname": alert.labels.get("alertname"),
+            "status": alert.status,
+            "namespace": context.get("namespace"),
+            "workload": context.get("workload"),
+            "node": node,
+            "analysis_id": context.get("analysis_id"),
+            "analysis_summary": summary,
+            "degraded": context.get("degraded") is True,
+        }
+
+
+def _unique_alerts(alerts: list[Alert]) -> list[Alert]:
+    seen: set[str] = set()
+    unique: list[Alert] = []
+    for alert in alerts:
+        key = alert.fingerprint or repr(sorted(alert.labels.items()))
+        if key in seen:
+            continue
+        seen.add(key)
+        unique.append(alert)
+    # Stable sort: firing alerts first, webhook order otherwise.
+    return sorted(unique, key=lambda alert: alert.status != "firing")
+
+
+def shared_dimensions(members: list[dict[str, object]]) -> dict[str, str]:
+    """Values shared by at least two members and half of the group, per dimension."""
+    shared: dict[str, str] = {}
+    for dimension in ("node", "workload", "namespace", "alertname"):
+        values = Counter(
+            value for member in members if isinstance(value := member.get(dimension), str) and value
+        )
+        if not values:
+            continue
+        value, count = values.most_common(1)[0]
+        if count >= 2 and count * 2 >= len(members):
+            shared[dimension] = value
+    return shared
+
+
+def _build_group_prompt(
+    request: AlertGroupAnalysisRequest,
+    members: list[dict[str, object]],
+    shared: dict[str, str],
+    alert_count: int,
+    masker: Masker,
+) -> str:
+    lines = [
+        "You are kube-rca-agent. One Alertmanager group fired several alerts, and each was "
+        "analyzed on its own. Write ONE summary for the whole group for a Slack thread.",
+        "Decide whether the alerts share one underlying cause (for example a node pool out of "
+        "memory or a failing dependency) or are independent.",
+        "Return your response in Korean in this form, without calling tools:",
+        f"{_COMMON_CAUSE_PREFIX} <the single underlying cause in one sentence, or 'none'>",
+        "<2-4 sentences: how many alerts, what they have in common, the next action>",
+        "",
+        f"Alerts in the group: {alert_count} ({len(members)} analyzed individually)",
+    ]
+    if request.group_labels:
+        labels = ", ".join(f"{key}={value}" for key, value in sorted(request.group_labels.items()))
+        lines.append(f"Group labels: {masker.mask_text(labels)}")
+    if shared:
+        lines.append(
+            "Shared by most alerts: "
+            + ", ".join(f"{key}={value}" for key, value in sorted(shared.items()))
+        )
+    lines.append("")
+    for index, member in enumerate(members, start=1):
+        where = "/".join(
+            str(member[key]) for key in ("namespace", "workload", "node") if member.get(key)
+        )
+        lines.append(
+            f"{index}. {member.get('alertname') or 'unknown'} ({member.get('status')}"
+            f"{', ' + where if where else ''}): "
+            f"{masker.mask_text(str(member.get('analysis_summary') or '')).strip()}"
+        )
+    return "\n".join(lines)
+
+
+def _parse_group_summary(answer: str) -> tuple[str | None, str]:
+    common_cause: str | None = None
+    body: list[str] = []
+    for line in answer.strip().splitlines():
+        stripped = line.strip()
+        if stripped.upper().startswith(_COMMON_CAUSE_PREFIX):
+            value = stripped[len(_COMMON_CAUSE_PREFIX) :].strip()
+            common_cause = None if value.lower() in {"", "none", "-"} else value
+            continue
+        body.append(line)
+    return common_cause, "\n".join(body).strip()
+
+
+def fallback_group_summary(
+    members: list[dict[str, object]], shared: dict[str, str], alert_count: int
+) -> str:
+    names = Counter(str(member.get("alertname") or "unknown") for member in members)
+    parts = [f"{name} x{count}" for name, count in names.most_common(3)]
+    summary = f"{alert_count}개 알림 ({', '.join(parts)})"
+    if shared:
+        summary += " 공통: " + ", ".join(f"{key}={value}" for key, value in sorted(shared.items()))
+    else:
+        summary += " 공통 원인이 확인되지 않았습니다"
+    return summary
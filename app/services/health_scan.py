@@ -0,0 +1,143 @@
+from __future__ import annotations
+
+import asyncio
+import logging
+from datetime import datetime, timedelta, timezone
+from typing import Protocol
+
+from app.clients.report_sink import ReportSink
+
+logger = logging.getLogger(__name__)
+
+
+class _HealthSource(Protocol):
+    def list_unhealthy_pods(
+        self, namespace: str, *, pending_minutes: int = 10
+    ) -> list[dict[str, object]]: ...
+
+    def list_certificates(self, namespace: str) -> list[dict[str, object]]: ...
+
+    def list_resource_quota_usage(self, namespace: str) -> list[dict[str, object]]: ...
+
+
+class HealthScanService:
+    """Scan namespaces for problems that have not (yet) fired an alert.
+
+    Checks pods stuck Pending or crash looping, cert-manager Certificates
+    close to expiry or not ready, and ResourceQuotas near their hard limit.
+    Findings are kept as the latest report and sent to the report sink.
+    """
+
+    def __init__(
+        self,
+        k8s_client: _HealthSource,
+        namespaces: tuple[str, ...] | list[str],
+        *,
+        sink: ReportSink | None = None,
+        pending_minutes: int = 10,
+        cert_expiry_days: int = 14,
+        quota_threshold: float = 0.9,
+    ) -> None:
+        self._k8s_client = k8s_client
+        self._namespaces = [namespace for namespace in namespaces if namespace]
+        self._sink = sink
+        self._pending_minutes = pending_minutes
+        self._cert_expiry_days = cert_expiry_days
+        self._quota_threshold = quota_threshold
+        self._latest_report: dict[str, object] | None = None
+
+    @property
+    def enabled(self) -> bool:
+        return bool(self._namespaces)
+
+    @property
+    def latest_report(self) -> dict[str, object] | None:
+        return self._latest_report
+
+    def scan(self) -> dict[str, object]:
+        now = datetime.now(timezone.utc)
+        findings: list[dict[str, object]] = []
+        for namespace in self._namespaces:
+            findings.extend(self._scan_namespace(namespace, now))
+
+        report: dict[str, object] = {
+            "scanned_at": now.isoformat(),
+            "namespaces": list(self._namespaces),
+            "finding_count": len(findings),
+            "findings": findings,
+        }
+        if self._sink is not None and findings:
+            report["delivery"] = self._sink.send("health_scan", report)
+        self._latest_report = report
+        logger.info(
+            "health_scan namespaces=%d findings=%d", len(self._namespaces), len(findings)
+        )
+        return report
+
+    def _scan_namespace(self, namespace: str, now: datetime) -> list[dict[str, object]]:
+        findings: list[dict[str, object]] = []
+        for issue in self._k8s_client.list_unhealthy_pods(
+            namespace, pending_minutes=self._pending_minutes
+        ):
+            if issue.get("issue") == "pending":
+                summary = f"pod {issue.get('pod')} pending: {issue.get('reason') or 'unknown'}"
+            else:
+                summary = (
+                    f"pod {issue.get('pod')} container {issue.get('container')} "
+                    f"crash looping ({issue.get('restart_count')} restarts)"
+                )
+            findings.append(_finding(namespace, str(issue.get("issue")), summary, issue))
+
+        expiry_cutoff = now + timedelta(days=self._cert_expiry_days)
+        for certificate in self._k8s_client.list_certificates(namespace):
+            not_after = _parse_time(certificate.get("not_after"))
+            if not_after is not None and not_after <= expiry_cutoff:
+                days_left = (not_after - now).total_seconds() / 86400
+                summary = (
+                    f"certificate {certificate.get('name')} expires in {days_left:.1f} days"
+                    if days_left > 0
+                    else f"certificate {certificate.get('name')} expired"
+                )
+                findings.append(_finding(namespace, "certificate_expiry", summary, certificate))
+            elif certificate.get("ready") == "False":
+                summary = (
+                    f"certificate {certificate.get('name')} not ready: "
+                    f"{certificate.get('reason') or 'unknown'}"
+                )
+                findings.append(_finding(namespace, "certificate_not_ready", summary, certificate))
+
+        for usage in self._k8s_client.list_resource_quota_usage(namespace):
+            ratio = usage.get("ratio")
+            if isinstance(ratio, float | int) and ratio >= self._quota_threshold:
+                summary = (
+                    f"quota {usage.get('quota')} {usage.get('resource')} at {ratio:.0%} "
+                    f"({usage.get('used')}/{usage.get('hard')})"
+                )
+                findings.append(_finding(namespace, "quota_saturation", summary, usage))
+        return findings
+
+
+def _finding(
+    namespace: str, check: str, summary: str, evidence: dict[str, object]
+) -> dict[str, object]:
+    return {"namespace": namespace, "check": check, "summary": summary, "evidence": evidence}
+
+
+def _parse_time(value: object) -> datetime | None:
+    if not isinstance(value, str) or not value:
+        return None
+    try:
+        parsed = datetime.fromisoformat(value.replace("Z", "+00:00"))
+    except ValueError:
+        return None
+    return parsed if parsed.tzinfo else parsed.replace(tzinfo=timezone.utc)
+
+
+async def run_health_scan_scheduler(service: HealthScanService, interval_seconds: int) -> None:
+    """Run a health scan every *interval_seconds* until cancelled."""
+    while True:
+        try:
+            await asyncio.to_thread(service.scan)
+        except Exception as exc:  # noqa: BLE001
+            logger.warning("Health scan failed: %s", exc)
+        await asyncio.sleep(interval_seconds)
@@ -0,0 +1,205 @@
+"""HorizontalPodAutoscaler saturation of the alert's workload, returned as ``hpa_analysis``.
+
+Built from ``KubernetesClient.get_hpa_status``. Many latency and error-rate
+alerts trace back to an HPA that cannot add capacity: it is pinned at
+``maxReplicas`` (``ScalingLimited``/``TooManyReplicas``), its metrics API
+does not answer (``ScalingActive=False``, ``FailedGet*Metric`` events) so it
+holds its replica count, or it cannot read or update the target's scale.
+"""
+
+from __future__ import annotations
+
+from app.clients.k8s import parse_quantity
+from app.models.k8s import K8sContext
+
+# V2MetricSpec/V2MetricStatus attribute holding the source of each metric type.
+_METRIC_SOURCES = {
+    "Resource": "resource",
+    "ContainerResource": "container_resource",
+    "Pods": "pods",
+    "Object": "object",
+    "External": "external",
+}
+_METRIC_FAILURE_REASONS = frozenset(
+    {
+        "FailedGetResourceMetric",
+        "FailedGetContainerResourceMetric",
+        "FailedGetPodsMetric",
+        "FailedGetObjectMetric",
+        "FailedGetExternalMetric",
+        "FailedComputeMetricsReplicas",
+        "InvalidMetricSourceType",
+    }
+)
+# MetricTarget.type -> the MetricTarget/MetricValueStatus field it uses.
+_TARGET_FIELDS = {
+    "Utilization": "average_utilization",
+    "AverageValue": "average_value",
+    "Value": "value",
+}
+_MAX_SCALING_EVENTS = 5
+
+
+def build_hpa_analysis(k8s_context: K8sContext) -> dict[str, object] | None:
+    hpa = k8s_context.hpa_status
+    if not hpa:
+        return None
+    conditions = {str(item.get("type")): item for item in _dicts(hpa.get("conditions"))}
+    events = _dicts(hpa.get("events"))
+    current = _int(hpa.get("current_replicas"))
+    desired = _int(hpa.get("desired_replicas"))
+    max_replicas = _int(hpa.get("max_replicas"))
+    limited = conditions.get("ScalingLimited", {})
+    at_max = (current is not None and max_replicas is not None and current >= max_replicas) or (
+        limited.get("status") == "True" and limited.get("reason") == "TooManyReplicas"
+    )
+    active = conditions.get("ScalingActive", {})
+    metric_failures = [
+        f"{item.get('reason')}: {item.get('message') or ''}".strip()
+        for item in events
+        if item.get("reason") in _METRIC_FAILURE_REASONS
+    ]
+    if active.get("status") == "False" and active.get("reason") in _METRIC_FAILURE_REASONS:
+        metric_failures.insert(0, f"{active.get('reason')}: {active.get('message') or ''}".strip())
+    metrics = _metrics(hpa)
+    analysis: dict[str, object] = {
+        "name": hpa.get("name"),
+        "target": hpa.get("target"),
+        "min_replicas": _int(hpa.get("min_replicas")),
+        "max_replicas": max_replicas,
+        "current_replicas": current,
+        "desired_replicas": desired,
+        "last_scale_time": hpa.get("last_scale_time"),
+        "at_max_replicas": at_max,
+        "metrics_unavailable": bool(metric_failures),
+        "metrics": metrics,
+        "scaling_events": [
+            str(item.get("message") or "")
+            for item in events
+            if item.get("reason") == "SuccessfulRescale"
+        ][-_MAX_SCALING_EVENTS:],
+    }
+    analysis["findings"] = _findings(analysis, conditions, _dedupe(metric_failures))
+    return analysis
+
+
+def _findings(
+    analysis: dict[str, object],
+    conditions: dict[str, dict[str, object]],
+    metric_failures: list[str],
+) -> list[str]:
+    name = analysis["name"]
+    current = analysis["current_replicas"]
+    findings: list[str] = []
+    if analysis["at_max_replicas"]:
+        finding = f"HPA {name} is pinned at maxReplicas ({current}/{analysis['max_replicas']})"
+        over = [
+            f"{item['name']} at {item['current']} (target {item['target']})"
+            for item in _dicts(analysis["metrics"])
+            if item.get("above_target")
+        ]
+        if over:
+            finding += f" with {', '.join(over)}"
+        findings.append(
+            f"{finding}; the workload cannot scale out further, so extra load shows up as "
+            "latency and errors: raise maxReplicas (and check the cluster has room for the "
+            "pods) or reduce the load per pod"
+        )
+    if metric_failures:
+        findings.append(
+            f"HPA {name} cannot get its metrics ({metric_failures[0]}); it holds {current} "
+            "replica(s) and does not scale until the metrics API (metrics-server, a "
+            "Prometheus adapter or the external metrics provider) answers"
+        )
+    able = conditions.get("AbleToScale", {})
+    if able.get("status") == "False":
+        target = _dict(analysis["target"])
+        detail = str(able.get("reason"))
+        if able.get("message"):
+            detail += f": {able['message']}"
+        findings.append(
+            f"HPA {name} cannot scale {target.get('kind')}/{target.get('name')} "
+            f"(AbleToScale=False, {detail})"
+        )
+    desired = analysis["desired_replicas"]
+    if (
+        not analysis["at_max_replicas"]
+        and isinstance(current, int)
+        and isinstance(desired, int)
+        and desired > current
+    ):
+        findings.append(
+            f"HPA {name} is scaling out from {current} to {desired} replicas; the new pods "
+            "may still be starting or Pending"
+        )
+    return findings
+
+
+def _metrics(hpa: dict[str, object]) -> list[dict[str, object]]:
+    current_by_key = {
+        _metric_key(item): _source(item).get("current")
+        for item in _dicts(hpa.get("current_metrics"))
+    }
+    metrics: list[dict[str, object]] = []
+    for spec in _dicts(hpa.get("metrics")):
+        target = _dict(_source(spec).get("target"))
+        # The current value is compared in the unit of the target.
+        field = _TARGET_FIELDS.get(str(target.get("type")), "average_utilization")
+        current = _dict(current_by_key.get(_metric_key(spec))).get(field)
+        target_value = _number(target.get(field))
+        current_value = _number(current)
+        metrics.append(
+            {
+                "name": _metric_key(spec)[1],
+                "type": spec.get("type"),
+                "target": _format_value(target.get(field), field),
+                "current": _format_value(current, field),
+                "above_target": (
+                    target_value is not None
+                    and current_value is not None
+                    and current_value > target_value
+                ),
+            }
+        )
+    return metrics
+
+
+def _source(metric: dict[str, object]) -> dict[str, object]:
+    return _dict(metric.get(_METRIC_SOURCES.get(str(metric.get("type")), "")))
+
+
+def _metric_key(metric: dict[str, object]) -> tuple[str, str]:
+    source = _source(metric)
+    name = source.get("name") or _dict(source.get("metric")).get("name") or "unknown"
+    container = source.get("container")
+    if container:
+        name = f"{name} ({container})"
+    return str(metric.get("type")), str(name)
+
+
+def _number(value: object) -> float | None:
+    if isinstance(value, (int, float)) and not isinstance(value, bool):
+        return float(value)
+    return parse_quantity(value) if isinstance(value, str) else None
+
+
+def _format_value(value: object, field: str) -> str | None:
+    if value is None:
+        return None
+    return f"{value}%" if field == "average_utilization" else str(value)
+
+
+def _dedupe(items: list[str]) -> list[str]:
+    return list(dict.fromkeys(items))
+
+
+def _int(value: object) -> int | None:
+    return value if isinstance(value, int) and not isinstance(value, bool) else None
+
+
+def _dict(value: object) -> dict[str, object]:
+    return value if isinstance(value, dict) else {}
+
+
+def _dicts(value: object) -> list[dict[str, object]]:
+    return [item for item in value if isinstance(item, dict)] if isinstance(value, list) else []
@@ -0,0 +1,216 @@
+"""Parallel investigation of competing root-cause hypotheses.
+
+Before the final analysis, the engine investigates each candidate hypothesis
+(config change, capacity, dependency) in its own runtime session, in
+parallel, with the same prompt plus instructions to gather evidence for and
+against that hypothesis only and to answer with a JSON verdict. Verdicts are
+ranked (supported before inconclusive before refuted, then by confidence)
+and handed to the final analysis, which leads with the best-supported one.
+A branch that fails or does not finish within the timeout counts as
+inconclusive, so one slow investigation never blocks the analysis. Each
+investigation runs its branches on its own pool, so branches of concurrent
+analyses never queue behind each other.
+"""
+
+from __future__ import annotations
+
+import contextvars
+import json
+import logging
+from concurrent.futures import Future, ThreadPoolExecutor, wait
+from dataclasses import dataclass
+
+from app.clients.strands_agent import AnalysisEngine
+
+logger = logging.getLogger(__name__)
+
+_VERDICT_ORDER = {"supported": 0, "inconclusive": 1, "refuted": 2}
+_MAX_EVIDENCE_ITEMS = 5
+_MAX_TEXT_CHARS = 400
+
+
+@dataclass(frozen=True)
+class Hypothesis:
+    key: str
+    title: str
+    focus: str
+
+
+DEFAULT_HYPOTHESES = (
+    Hypothesis(
+        key="config_change",
+        title="A recent change caused the incident",
+        focus=(
+            "a rollout (image, config, env vars, feature flags), a ConfigMap/Secret edit, "
+            "or an infrastructure or code change shortly before the alert started"
+        ),
+    ),
+    Hypothesis(
+        key="capacity",
+        title="The workload ran out of capacity",
+        focus=(
+            "CPU/memory saturation or throttling, OOM kills, an HPA pinned at maxReplicas, "
+            "node pressure, quota limits or a traffic surge beyond what the replicas serve"
+        ),
+    ),
+    Hypothesis(
+        key="dependency",
+        title="A dependency is failing",
+        focus=(
+            "a database, cache, message broker, downstream service, DNS or external API "
+            "returning errors, timing out or refusing connections"
+        ),
+    ),
+)
+
+
+class HypothesisInvestigator:
+    def __init__(
+        self,
+        *,
+        hypotheses: tuple[Hypothesis, ...] = DEFAULT_HYPOTHESES,
+        max_parallel: int = 3,
+        timeout_seconds: float = 120.0,
+    ) -> None:
+        self._hypotheses = hypotheses
+        self._max_parallel = max(1, min(max_parallel, len(hypotheses)))
+        self._timeout_seconds = timeout_seconds
+
+    def investigate(
+        self,
+        engine: AnalysisEngine,
+        prompt: str,
+        session_id: str,
+        *,
+        timeout_seconds: float | None = None,
+    ) -> list[dict[str, object]]:
+        """Ranked verdicts of all hypotheses; waits at most the configured timeout."""
+        timeout = self._timeout_seconds
+        if timeout_seconds is not None:
+            timeout = max(0.0, min(timeout, timeout_seconds))
+        executor = ThreadPoolExecutor(
+            max_workers=self._max_parallel, thread_name_prefix="hypothesis"
+        )
+        try:
+            futures: dict[Future[str], Hypothesis] = {
+                # Branches share request-scoped state (e.g. caller credentials) with the caller.
+                executor.submit(
+                    contextvars.copy_context().run,
+                    engine.analyze,
+                    _branch_prompt(prompt, hypothesis),
+                    f"{session_id}:hypothesis:{hypothesis.key}",
+                ): hypothesis
+                for hypothesis in self._hypotheses
+            }
+            done, pending = wait(futures, timeout=timeout)
+            for future in pending:
+                future.cancel()
+        finally:
+            # Branches not started yet never run; running ones finish in the background.
+            executor.shutdown(wait=False, cancel_futures=True)
+        results: list[dict[str, object]] = []
+        for future, hypothesis in futures.items():
+            if future not in done:
+                results.append(_inconclusive(hypothesis, f"not finished within {timeout:g}s"))
+                continue
+            try:
+                answer = future.result()
+            except Exception as exc:  # noqa: BLE001
+                logger.warning("Hypothesis %s investigation failed: %s", hypothesis.key, exc)
+                results.append(_inconclusive(hypothesis, f"investigation failed: {exc}"))
+                continue
+            results.append(parse_verdict(hypothesis, answer))
+        return rank_hypotheses(results)
+
+
+def parse_verdict(hypothesis: Hypothesis, answer: str) -> dict[str, object]:
+    """The JSON verdict in *answer*, inconclusive when there is none."""
+    start, end = answer.find("{"), answer.rfind("}")
+    try:
+        payload = json.loads(answer[start : end + 1]) if 0 <= start < end else None
+    except ValueError:
+        payload = None
+    if not isinstance(payload, dict):
+        return _inconclusive(hypothesis, "no JSON verdict in the investigation answer")
+    verdict = str(payload.get("verdict") or "").strip().lower()
+    try:
+        confidence = min(max(float(payload.get("confidence") or 0.0), 0.0), 1.0)
+    except (TypeError, ValueError):
+        confidence = 0.0
+    return {
+        "key": hypothesis.key,
+        "title": hypothesis.title,
+        "verdict": verdict if verdict in _VERDICT_ORDER else "inconclusive",
+        "confidence": round(confidence, 2),
+        "summary": _text(payload.get("summary")),
+        "supporting": _evidence(payload.get("supporting")),
+        "contradicting": _evidence(payload.get("contradicting")),
+    }
+
+
+def rank_hypotheses(results: list[dict[str, object]]) -> list[dict[str, object]]:
+    ranked = sorted(
+        results,
+        key=lambda item: (
+            _VERDICT_ORDER.get(str(item["verdict"]), len(_VERDICT_ORDER)),
+            -float(item["confidence"]),  # type: ignore[arg-type]
+        ),
+    )
+    return [{"rank": index, **item} for index, item in enumerate(ranked, start=1)]
+
+
+def format_ranked_hypotheses(ranked: list[dict[str, object]]) -> str:
+    """Prompt section handing the ranked verdicts to the final analysis."""
+    if not ranked:
+        return ""
+    lines = [
+        "",
+        "## Ranked hypotheses (investigated in parallel)",
+        "Base the analysis on these verdicts: lead with the best-supported hypothesis, "
+        "then list the others with their supporting and contradicting evidence. Verify "
+        "a verdict with tools only when the evidence below is insufficient.",
+    ]
+    for item in ranked:
+        lines.append(
+            f"{item['rank']}. {item['title']} — {item['verdict']} "
+            f"(confidence {item['confidence']}): {item['summary'] or 'no summary'}"
+        )
+        for label in ("supporting", "contradicting"):
+            for evidence in item[label] or []:  # type: ignore[attr-defined]
+                lines.append(f"   - {label}: {evidence}")
+    return "\n".join(lines) + "\n"
+
+
+def _branch_prompt(prompt: str, hypothesis: Hypothesis) -> str:
+    return (
+        f"{prompt}\n\n"
+        f"## Hypothesis under investigation: {hypothesis.title}\n"
+        f"Investigate only whether the incident is explained by {hypothesis.focus}. "
+        "Use the tools to collect evidence that supports and evidence that contradicts "
+        "this hypothesis; ignore other causes.\n"
+        "Reply with a single JSON object and nothing else:\n"
+        '{"verdict": "supported" | "refuted" | "inconclusive", "confidence": 0.0-1.0, '
+        '"summary": "<one sentence>", "supporting": ["<evidence>", ...], '
+        '"contradicting": ["<evidence>", ...]}'
+    )
+
+
+def _inconclusive(hypothesis: Hypothesis, reason: str) -> dict[str, object]:
+    return {
+        "key": hypothesis.key,
+        "title": hypothesis.title,
+        "verdict": "inconclusive",
+        "confidence": 0.0,
+        "summary": reason,
+        "supporting": [],
+        "contradicting": [],
+    }
+
+
+def _evidence(value: object) -> list[str]:
+    items = value if isinstance(value, list) else []
+    return [_text(item) for item in items if _text(item)][:_MAX_EVIDENCE_ITEMS]
+
+
+def _text(value: object) -> str:
+    return str(value).strip()[:_MAX_TEXT_CHARS] if value is not None else ""
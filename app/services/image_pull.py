@@ -0,0 +1,270 @@
+"""Registry diagnostics of image pull failures, returned as ``image_pull``.
+
+A container fails to pull while it waits in ``ErrImagePull``,
+``ImagePullBackOff``, ``InvalidImageName`` or ``ErrImageNeverPull``, or
+while the kubelet's ``Failed to pull image`` events name its image. The
+kubelet's message (its waiting message, else the pull events of the same
+image) is classified by ``classify_pull_error`` into auth failures, missing
+tags or repositories, registry timeouts and the like. Each container is
+reported with its exact image reference, split into registry, repository,
+tag and digest, next to the pod's imagePullSecrets (ServiceAccount pull
+secrets are already merged into the pod spec on admission).
+"""
+
+from __future__ import annotations
+
+import re
+
+from app.models.k8s import K8sContext
+
+_PULL_REASONS = frozenset(
+    {"ErrImagePull", "ImagePullBackOff", "InvalidImageName", "ErrImageNeverPull"}
+)
+_PULL_EVENT_REASONS = frozenset({"Failed", "ErrImagePull", "BackOff", "InspectFailed"})
+_DEFAULT_REGISTRY = "docker.io"
+
+_EVENT_IMAGE_RE = re.compile(r'\bimage "([^"]+)"', re.I)
+_MISSING_SECRETS_RE = re.compile(r"image pull secrets \(([^)]*)\)", re.I)
+
+# Checked in order: a timed out TLS handshake is a timeout, not a certificate error.
+_PULL_ERROR_PATTERNS: tuple[tuple[str, tuple[str, ...]], ...] = (
+    ("rate_limited", ("toomanyrequests", "429 too many requests", "rate limit")),
+    (
+        "registry_timeout",
+        ("i/o timeout", "deadline exceeded", "handshake timeout", "client.timeout", "timed out"),
+    ),
+    ("tls_error", ("x509:", "certificate signed by unknown authority", "tls: ")),
+    (
+        "auth_failed",
+        (
+            "unauthorized",
+            "authentication required",
+            "no basic auth credentials",
+            "access denied",
+            "denied:",
+            "insufficient_scope",
+            "forbidden",
+        ),
+    ),
+    ("repository_not_found", ("name unknown", "repository does not exist", "repository not found")),
+    ("tag_not_found", ("manifest unknown", "not found")),
+    (
+        "registry_unreachable",
+        (
+            "no such host",
+            "connection refused",
+            "no route to host",
+            "network is unreachable",
+            "connection reset",
+        ),
+    ),
+    ("invalid_reference", ("invalid reference format", "couldn't parse image reference")),
+)
+
+
+def build_image_pull_analysis(k8s_context: K8sContext) -> dict[str, object] | None:
+    spec = k8s_context.pod_spec if isinstance(k8s_context.pod_spec, dict) else {}
+    images = {
+        (str(container.get("name")), init): container
+        for init, key in ((False, "containers"), (True, "init_containers"))
+        for container in _dicts(spec.get(key))
+    }
+    messages = _pull_event_messages(k8s_context)
+    containers: list[dict[str, object]] = []
+    seen_images: set[str] = set()
+    if k8s_context.pod_status is not None:
+        statuses = [
+            *((status, True) for status in k8s_context.pod_status.init_container_statuses),
+            *((status, False) for status in k8s_context.pod_status.container_statuses),
+        ]
+        for status, init in statuses:
+            if not isinstance(status, dict):
+                continue
+            state = _dict(status.get("state"))
+            if state.get("type") != "waiting" or state.get("reason") not in _PULL_REASONS:
+                continue
+            name = str(status.get("name") or "unknown")
+            container = images.get((name, init), {})
+            candidates = [str(state["message"])] if state.get("message") else []
+            match = _EVENT_IMAGE_RE.search(candidates[0]) if candidates else None
+            image = str(container.get("image") or (match.group(1) if match else ""))
+            seen_images.add(image)
+            containers.append(
+                _container_analysis(
+                    name,
+                    init,
+                    image,
+                    container.get("image_pull_policy"),
+                    str(state.get("reason")),
+                    [*candidates, *messages.get(image, [])],
+                )
+            )
+    # Pods whose statuses were not collected (or already recovered) only have the events.
+    for image, image_messages in messages.items():
+        if image in seen_images:
+            continue
+        owner = next(
+            (key for key, container in images.items() if container.get("image") == image),
+            None,
+        )
+        containers.append(
+            _container_analysis(
+                owner[0] if owner else None,
+                owner[1] if owner else False,
+                image,
+                images[owner].get("image_pull_policy") if owner else None,
+                None,
+                image_messages,
+            )
+        )
+    missing_secrets = _missing_pull_secrets(k8s_context)
+    if not containers and not missing_secrets:
+        return None
+    pull_secrets = [str(item) for item in spec.get("image_pull_secrets") or []]
+    findings = [_finding(item, pull_secrets) for item in containers]
+    if missing_secrets:
+        findings.append(
+            f"imagePullSecrets {', '.join(missing_secrets)} of pod {k8s_context.pod_name} do not "
+            f"exist in namespace {k8s_context.namespace}; pulls cannot use their credentials"
+        )
+    return {
+        "pod": k8s_context.pod_name,
+        "namespace": k8s_context.namespace,
+        "pull_secrets": pull_secrets,
+        "missing_pull_secrets": missing_secrets,
+        "containers": containers,
+        "findings": findings,
+    }
+
+
+def classify_pull_error(message: str | None, reason: str | None = None) -> str:
+    """The pull failure category of a kubelet message, ``unknown`` when none matches."""
+    if reason == "InvalidImageName":
+        return "invalid_reference"
+    if reason == "ErrImageNeverPull":
+        return "never_pull"
+    text = (message or "").lower()
+    for cause, patterns in _PULL_ERROR_PATTERNS:
+        if any(pattern in text for pattern in patterns):
+            return cause
+    return "unknown"
+
+
+def parse_image_reference(image: str) -> dict[str, str | None]:
+    """Registry, repository, tag and digest of *image*, with Docker Hub defaults applied."""
+    name, _, digest = image.partition("@")
+    tag: str | None = None
+    if name.rfind(":") > name.rfind("/"):
+        name, _, tag = name.rpartition(":")
+    first, slash, rest = name.partition("/")
+    if slash and ("." in first or ":" in first or first == "localhost"):
+        registry, repository = first, rest
+    else:
+        registry = _DEFAULT_REGISTRY
+        repository = name if "/" in name else f"library/{name}"
+    if tag is None and not digest:
+        tag = "latest"
+    return {"registry": registry, "repository": repository, "tag": tag, "digest": digest or None}
+
+
+def _container_analysis(
+    name: str | None,
+    init: bool,
+    image: str,
+    pull_policy: object,
+    reason: str | None,
+    messages: list[str],
+) -> dict[str, object]:
+    # The back-off message only repeats the image; the first classified message explains why.
+    cause, message = "unknown", messages[0] if messages else None
+    for candidate in messages:
+        candidate_cause = classify_pull_error(candidate, reason)
+        if candidate_cause != "unknown":
+            cause, message = candidate_cause, candidate
+            break
+    else:
+        cause = classify_pull_error(None, reason)
+    return {
+        "container": name,
+        "init": init,
+        "image": image or None,
+        **(parse_image_reference(image) if image else _empty_reference()),
+        "image_pull_policy": pull_policy,
+        "reason": reason,
+        "cause": cause,
+        "message": message,
+    }
+
+
+def _finding(entry: dict[str, object], pull_secrets: list[str]) -> str:
+    subject = (
+        f"{'init container' if entry['init'] else 'container'} {entry['container']}"
+        if entry["container"]
+        else "pod"
+    )
+    finding = f"{subject} cannot pull image {entry['image'] or 'unknown'}"
+    registry, repository = entry["registry"], entry["repository"]
+    cause = entry["cause"]
+    if cause == "auth_failed":
+        finding += f": registry {registry} denied access to {repository} " + (
+            f"with imagePullSecrets {', '.join(pull_secrets)}"
+            if pull_secrets
+            else "and the pod has no imagePullSecrets"
+        )
+        if "repository does not exist" in str(entry["message"] or "").lower():
+            finding += " (or the repository does not exist)"
+    elif cause == "tag_not_found":
+        missing = f"digest {entry['digest']}" if entry["digest"] else f"tag {entry['tag']}"
+        finding += f": {missing} does not exist in {registry}/{repository}"
+    elif cause == "repository_not_found":
+        finding += f": repository {repository} does not exist in registry {registry}"
+    elif cause == "registry_timeout":
+        finding += f": pulling from registry {registry} timed out"
+    elif cause == "registry_unreachable":
+        finding += f": registry {registry} is unreachable (DNS lookup or connection failed)"
+    elif cause == "tls_error":
+        finding += f": the TLS certificate of registry {registry} is not trusted"
+    elif cause == "rate_limited":
+        finding += f": registry {registry} is rate limiting pulls"
+    elif cause == "invalid_reference":
+        finding += ": the image reference is malformed"
+    elif cause == "never_pull":
+        finding += ": imagePullPolicy is Never and the image is not present on the node"
+    if entry["message"]:
+        finding += f" ({entry['message']})"
+    return finding
+
+
+def _pull_event_messages(k8s_context: K8sContext) -> dict[str, list[str]]:
+    messages: dict[str, list[str]] = {}
+    for event in k8s_context.events:
+        if event.reason not in _PULL_EVENT_REASONS or not event.message:
+            continue
+        match = _EVENT_IMAGE_RE.search(event.message)
+        if match and event.message not in messages.get(match.group(1), []):
+            messages.setdefault(match.group(1), []).append(event.message)
+    return messages
+
+
+def _missing_pull_secrets(k8s_context: K8sContext) -> list[str]:
+    names: list[str] = []
+    for event in k8s_context.events:
+        if event.reason != "FailedToRetrieveImagePullSecret":
+            continue
+        match = _MISSING_SECRETS_RE.search(event.message or "")
+        for name in match.group(1).split(",") if match else []:
+            if name.strip() and name.strip() not in names:
+                names.append(name.strip())
+    return names
+
+
+def _empty_reference() -> dict[str, str | None]:
+    return {"registry": None, "repository": None, "tag": None, "digest": None}
+
+
+def _dicts(value: object) -> list[dict[str, object]]:
+    return [item for item in value if isinstance(item, dict)] if isinstance(value, list) else []
+
+
+def _dict(value: object) -> dict[str, object]:
+    return value if isinstance(value, dict) else {}
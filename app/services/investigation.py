@@ -0,0 +1,75 @@
+"""Helpers of interactive investigation sessions on a stored analysis.
+
+A session continues the runtime session of an ``/analyze`` run: questions go
+to the same agent (with its evidence and tool calls), and tools the user runs
+directly join that history, so later answers can use their results. Streamed
+text is masked line by line before it leaves the agent, because a secret split
+across two deltas would slip past pattern masking.
+"""
+
+from __future__ import annotations
+
+import json
+from collections.abc import Callable
+
+from app.core.masking import Masker
+
+_MAX_TOOL_OUTPUT_CHARS = 20_000
+
+
+class MaskedLineStream:
+    """Forwards ``chunk`` events to *on_event* one masked line at a time."""
+
+    def __init__(self, masker: Masker, on_event: Callable[[dict[str, object]], None]) -> None:
+        self._masker = masker
+        self._on_event = on_event
+        self._pending = ""
+        self.streamed = False
+
+    def feed(self, event: dict[str, object]) -> None:
+        if event.get("type") != "chunk":
+            self._on_event(event)
+            return
+        self._pending += str(event.get("text") or "")
+        lines, newline, rest = self._pending.rpartition("\n")
+        if newline:
+            self._pending = rest
+            self._emit(lines + newline)
+
+    def flush(self) -> None:
+        if self._pending:
+            self._emit(self._pending)
+            self._pending = ""
+
+    def _emit(self, text: str) -> None:
+        self.streamed = True
+        self._on_event({"type": "chunk", "text": self._masker.mask_text(text)})
+
+
+def build_investigation_prompt(question: str, masker: Masker) -> str:
+    return (
+        "A user continues your analysis above in an interactive investigation session. "
+        "Reuse the evidence already gathered, including results of tools the user ran in "
+        "this session, and call tools again only when fresher or additional data is "
+        "needed. Answer the question directly and concisely, cite the evidence you rely "
+        "on, and say so when the data cannot answer it. "
+        "Respond in English unless the user asks in another language.\n\n"
+        f"Question: {masker.mask_text(question).strip()}"
+    )
+
+
+def tool_result_text(result: dict[str, object]) -> str:
+    """Text of a Strands ``ToolResult``: its text blocks, JSON blocks pretty-printed."""
+    parts: list[str] = []
+    content = result.get("content")
+    for block in content if isinstance(content, list) else []:
+        if not isinstance(block, dict):
+            continue
+        if "text" in block:
+            parts.append(str(block["text"]))
+        elif "json" in block:
+            parts.append(json.dumps(block["json"], ensure_ascii=False, indent=2, default=str))
+    text = "\n".join(parts)
+    if len(text) > _MAX_TOOL_OUTPUT_CHARS:
+        return text[:_MAX_TOOL_OUTPUT_CHARS] + "\n... (truncated)"
+    return text
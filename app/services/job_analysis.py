@@ -0,0 +1,256 @@
+"""Failed Job and CronJob runs, returned as ``job_failure_analysis``.
+
+The Job comes from the ``job_name`` label (``cronjob`` for CronJob alerts) of
+alerts named like a Job alert (``KubeJobFailed``, ``KubeJobNotCompleted``,
+``KubeCronJob...``), or from the alerting pod's owning Job/CronJob.
+``KubernetesClient.get_job_run`` reads the Job (the latest one of a
+CronJob), its pods and the last failed pod's logs. The ``cause`` tells
+image errors, ``activeDeadlineSeconds`` being exceeded and ``backoffLimit``
+exhaustion apart; the last failed containers' exit codes back it up.
+"""
+
+from __future__ import annotations
+
+from datetime import datetime
+
+from app.models.k8s import K8sContext
+
+_IMAGE_ERRORS = frozenset(
+    {"ErrImagePull", "ImagePullBackOff", "InvalidImageName", "ErrImageNeverPull"}
+)
+_CONFIG_ERRORS = frozenset({"CreateContainerConfigError", "CreateContainerError"})
+_FAILED_PODS_IN_FINDING = 3
+_LOG_LINES_PER_CONTAINER = 10
+
+
+def job_target(labels: dict[str, str], k8s_context: K8sContext) -> dict[str, str] | None:
+    """Namespace and Job (``job``) or CronJob (``cron_job``) name of a Job alert, else ``None``."""
+    namespace = labels.get("namespace") or k8s_context.namespace
+    if not namespace:
+        return None
+    if "job" in labels.get("alertname", "").lower():
+        if labels.get("job_name"):
+            return {"namespace": namespace, "job": labels["job_name"]}
+        cron_job = labels.get("cronjob") or labels.get("cron_job")
+        if cron_job:
+            return {"namespace": namespace, "cron_job": cron_job}
+    workload = k8s_context.workload_status
+    if isinstance(workload, dict) and workload.get("name"):
+        if workload.get("kind") == "Job":
+            return {"namespace": namespace, "job": str(workload["name"])}
+        if workload.get("kind") == "CronJob":
+            return {"namespace": namespace, "cron_job": str(workload["name"])}
+    return None
+
+
+def build_job_failure_analysis(k8s_context: K8sContext) -> dict[str, object] | None:
+    data = k8s_context.job_run
+    if not data:
+        return None
+    namespace = data.get("namespace")
+    cron_job = data.get("cron_job")
+    spec = _dict(data.get("spec"))
+    status = _dict(data.get("status"))
+    recent_runs = [_run_outcome(run) for run in _dicts(data.get("recent_runs"))]
+    result: dict[str, object] = {
+        "job": f"{namespace}/{data['job']}" if data.get("job") else None,
+        "cron_job": f"{namespace}/{cron_job}" if cron_job else None,
+        "outcome": "unknown",
+        "cause": None,
+        "backoff_limit": spec.get("backoff_limit"),
+        "active_deadline_seconds": spec.get("active_deadline_seconds"),
+        "failed": status.get("failed", 0),
+        "succeeded": status.get("succeeded", 0),
+        "failed_pods": [],
+        "recent_runs": [
+            {key: value for key, value in run.items() if key != "condition"}
+            for run in recent_runs
+        ],
+        "last_run_logs": [],
+        "findings": [],
+    }
+    if not data.get("found"):
+        result["cause"] = "job_not_found"
+        result["findings"] = [
+            f"cronjob {result['cron_job']} has no Jobs left; failed runs were removed by its "
+            "history limits or ttlSecondsAfterFinished"
+            if cron_job and not data.get("job")
+            else f"job {result['job']} no longer exists (removed by ttlSecondsAfterFinished or "
+            "its CronJob's history limits); its pods and logs are gone"
+        ]
+        return result
+
+    name = str(result["job"])
+    run = _run_outcome(status)
+    result["outcome"] = run["outcome"]
+    failed_condition = _dict(run.get("condition"))
+    failed_pods = [_failed_pod(pod) for pod in _dicts(data.get("pods")) if pod.get("failed")]
+    result["failed_pods"] = failed_pods
+    result["last_run_logs"] = [
+        {
+            "container": snippet.get("container"),
+            "previous": snippet.get("previous"),
+            "logs": _strings(snippet.get("logs"))[-_LOG_LINES_PER_CONTAINER:],
+        }
+        for snippet in _dicts(data.get("logs"))
+        if snippet.get("logs")
+    ]
+
+    findings: list[str] = []
+    image_pod = next((pod for pod in failed_pods if pod["reason"] == "image_error"), None)
+    config_pod = next((pod for pod in failed_pods if pod["reason"] == "config_error"), None)
+    reason = failed_condition.get("reason")
+    if image_pod is not None:
+        result["cause"] = "image_error"
+        findings.append(f"job {name} pods cannot start: {image_pod['detail']}")
+    elif config_pod is not None:
+        result["cause"] = "config_error"
+        findings.append(f"job {name} pods cannot start: {config_pod['detail']}")
+    elif reason == "DeadlineExceeded":
+        result["cause"] = "deadline_exceeded"
+        findings.append(
+            f"job {name} was stopped after exceeding activeDeadlineSeconds="
+            f"{spec.get('active_deadline_seconds')}"
+            + _duration_text(status.get("start_time"), failed_condition.get("last_transition_time"))
+        )
+    elif reason == "BackoffLimitExceeded":
+        result["cause"] = "backoff_limit_exceeded"
+        findings.append(
+            f"job {name} failed {status.get('failed', 0)} pods and reached its backoffLimit of "
+            f"{spec.get('backoff_limit')}" + _last_failures_text(failed_pods)
+        )
+    elif reason == "PodFailurePolicy":
+        result["cause"] = "pod_failure_policy"
+        findings.append(
+            f"job {name} was failed by its podFailurePolicy: "
+            f"{failed_condition.get('message') or 'a pod failure matched a FailJob rule'}"
+            + _last_failures_text(failed_pods)
+        )
+    elif run["outcome"] == "failed":
+        result["cause"] = "job_failed"
+        findings.append(
+            f"job {name} failed ({reason or 'no reason'}: "
+            f"{failed_condition.get('message') or 'no message'})" + _last_failures_text(failed_pods)
+        )
+    elif run["outcome"] == "running" and failed_pods:
+        result["cause"] = "pods_failing"
+        limit = spec.get("backoff_limit")
+        retries = (
+            f"; {max(0, int(limit) - int(status.get('failed') or 0))} retries left"
+            if isinstance(limit, int)
+            else ""
+        )
+        findings.append(
+            f"job {name} is still running after {status.get('failed', 0)} failed pods{retries}"
+            + _last_failures_text(failed_pods)
+        )
+
+    failed_runs = [run for run in recent_runs if run["outcome"] == "failed"]
+    if cron_job and len(recent_runs) > 1 and failed_runs:
+        findings.append(
+            f"{len(failed_runs)} of the last {len(recent_runs)} runs of cronjob "
+            f"{result['cron_job']} failed"
+        )
+    result["findings"] = findings
+    return result
+
+
+def _run_outcome(run: dict[str, object]) -> dict[str, object]:
+    conditions = _dicts(run.get("conditions"))
+    true = {str(item.get("type")): item for item in conditions if item.get("status") == "True"}
+    failed = true.get("Failed")
+    if failed is not None:
+        outcome = "failed"
+    elif "Complete" in true:
+        outcome = "succeeded"
+    elif "Suspended" in true:
+        outcome = "suspended"
+    elif run.get("active"):
+        outcome = "running"
+    else:
+        outcome = "unknown"
+    return {
+        "name": run.get("name"),
+        "outcome": outcome,
+        "reason": failed.get("reason") if failed else None,
+        "start_time": run.get("start_time"),
+        "condition": failed,
+    }
+
+
+def _failed_pod(pod: dict[str, object]) -> dict[str, object]:
+    entry: dict[str, object] = {
+        "pod": pod.get("name"),
+        "reason": "failed",
+        "detail": pod.get("message") or pod.get("reason"),
+        "exit_code": None,
+    }
+    for container in _dicts(pod.get("containers")):
+        state = _dict(container.get("state"))
+        if state.get("type") == "waiting":
+            waiting = state.get("reason")
+            if waiting in _IMAGE_ERRORS:
+                entry.update(
+                    reason="image_error",
+                    detail=f"container {container.get('name')} image {container.get('image')} "
+                    f"{waiting}" + (f": {state.get('message')}" if state.get("message") else ""),
+                )
+                return entry
+            if waiting in _CONFIG_ERRORS:
+                entry.update(
+                    reason="config_error",
+                    detail=f"container {container.get('name')} {waiting}"
+                    + (f": {state.get('message')}" if state.get("message") else ""),
+                )
+                return entry
+    if pod.get("reason") == "DeadlineExceeded":
+        entry.update(reason="deadline_exceeded", detail="pod killed at the Job's deadline")
+        return entry
+    if pod.get("reason") == "Evicted":
+        entry.update(reason="evicted", detail=pod.get("message") or "pod evicted")
+        return entry
+    for container in _dicts(pod.get("containers")):
+        for key in ("state", "last_state"):
+            state = _dict(container.get(key))
+            exit_code = state.get("exit_code")
+            if state.get("type") != "terminated" or exit_code in (None, "0"):
+                continue
+            oom = state.get("reason") == "OOMKilled"
+            entry.update(
+                reason="oom_killed" if oom else "exit_code",
+                exit_code=int(str(exit_code)) if str(exit_code).lstrip("-").isdigit() else None,
+                detail=f"container {container.get('name')} exited with code {exit_code}"
+                + (f" ({state.get('reason')})" if state.get("reason") else ""),
+            )
+            return entry
+    return entry
+
+
+def _last_failures_text(failed_pods: list[dict[str, object]]) -> str:
+    details = [
+        f"{pod['pod']}: {pod['detail']}"
+        for pod in failed_pods[:_FAILED_PODS_IN_FINDING]
+        if pod.get("detail")
+    ]
+    return f"; last failures: {'; '.join(details)}" if details else ""
+
+
+def _duration_text(start: object, end: object) -> str:
+    try:
+        started = datetime.fromisoformat(str(start).replace("Z", "+00:00"))
+        ended = datetime.fromisoformat(str(end).replace("Z", "+00:00"))
+    except ValueError:
+        return ""
+    return f" (ran {int((ended - started).total_seconds())}s)"
+
+
+def _strings(value: object) -> list[str]:
+    return [str(item) for item in value] if isinstance(value, list) else []
+
+
+def _dicts(value: object) -> list[dict[str, object]]:
+    return [item for item in value if isinstance(item, dict)] if isinstance(value, list) else []
+
+
+def _dict(value: object) -> dict[str, object]:
+    return value if isinstance(value, dict) else {}
@@ -0,0 +1,224 @@
+from __future__ import annotations
+
+from typing import Protocol
+
+_MAX_PARTITIONS = 20
+_RATE_WINDOW = "5m"
+
+
+class _PrometheusQuery(Protocol):
+    def query(self, query: str, *, time: str | None = None) -> dict[str, object]: ...
+
+
+class KafkaLagAnalyzer:
+    """Explain Kafka consumer lag from kafka-exporter metrics in Prometheus.
+
+    Reads per-partition lag of a consumer group (``kafka_consumergroup_lag``),
+    its growth over the window, produce vs. consume offset rates, group
+    members and partition replication/leadership, then names the likely
+    bottleneck: ``brokers`` (under-replicated or leaderless partitions),
+    ``consumers`` (no members, stalled or slower than producers),
+    ``partition_skew`` (lag concentrated on few partitions) or ``none``.
+    """
+
+    def __init__(
+        self,
+        prometheus_client: _PrometheusQuery,
+        *,
+        lag_threshold: int = 1000,
+        window_minutes: int = 10,
+    ) -> None:
+        self._prometheus = prometheus_client
+        self._lag_threshold = max(1, lag_threshold)
+        self._window_minutes = max(1, window_minutes)
+
+    def analyze(self, consumer_group: str, topic: str | None = None) -> dict[str, object]:
+        errors: list[str] = []
+        group_matcher = f'consumergroup="{_escape(consumer_group)}"'
+        if topic:
+            group_matcher += f',topic="{_escape(topic)}"'
+
+        lags = self._vector(f"kafka_consumergroup_lag{{{group_matcher}}}", errors)
+        if not lags:
+            return {
+                "consumer_group": consumer_group,
+                "topic": topic,
+                "warning": (
+                    "no kafka_consumergroup_lag series for the consumer group "
+                    "(is kafka-exporter scraped by Prometheus?)"
+                ),
+                "errors": errors,
+            }
+        growth = {
+            _partition_key(labels): value
+            for labels, value in self._vector(
+                f"delta(kafka_consumergroup_lag{{{group_matcher}}}[{self._window_minutes}m])",
+                errors,
+            )
+        }
+        partitions = sorted(
+            (
+                {
+                    "topic": labels.get("topic"),
+                    "partition": labels.get("partition"),
+                    "lag": int(value),
+                    "growth": _round(growth.get(_partition_key(labels))),
+                }
+                for labels, value in lags
+            ),
+            key=lambda item: int(item["lag"] or 0),
+            reverse=True,
+        )
+        topics = sorted({str(item["topic"]) for item in partitions if item["topic"]})
+        topic_matcher = f'topic=~"{"|".join(_escape_regex(name) for name in topics)}"'
+
+        consume_rates = self._by_topic(
+            "sum by (topic) (rate(kafka_consumergroup_current_offset"
+            f"{{{group_matcher}}}[{_RATE_WINDOW}]))",
+            errors,
+        )
+        produce_rates = self._by_topic(
+            "sum by (topic) (rate(kafka_topic_partition_current_offset"
+            f"{{{topic_matcher}}}[{_RATE_WINDOW}]))",
+            errors,
+        )
+        under_replicated = self._by_topic(
+            f"sum by (topic) (kafka_topic_partition_under_replicated_partition{{{topic_matcher}}})",
+            errors,
+        )
+        offline = self._by_topic(
+            f"count by (topic) (kafka_topic_partition_leader{{{topic_matcher}}} < 0)", errors
+        )
+        members = self._scalar(
+            f'sum(kafka_consumergroup_members{{consumergroup="{_escape(consumer_group)}"}})',
+            errors,
+        )
+        brokers = self._scalar("sum(kafka_brokers)", errors)
+
+        total_lag = sum(int(item["lag"] or 0) for item in partitions)
+        lagging = [item for item in partitions if int(item["lag"] or 0) >= self._lag_threshold]
+        bottleneck, findings = self._diagnose(
+            partitions=partitions,
+            lagging=lagging,
+            total_lag=total_lag,
+            consume_rates=consume_rates,
+            produce_rates=produce_rates,
+            under_replicated=under_replicated,
+            offline=offline,
+            members=members,
+        )
+        return {
+            "consumer_group": consumer_group,
+            "topic": topic,
+            "total_lag": total_lag,
+            "partition_count": len(partitions),
+            "lagging_partition_count": len(lagging),
+            "lagging_partitions": lagging[:_MAX_PARTITIONS],
+            "consume_rate_per_second": consume_rates,
+            "produce_rate_per_second": produce_rates,
+            "consumer_members": members,
+            "brokers": brokers,
+            "under_replicated_partitions": under_replicated,
+            "offline_partitions": offline,
+            "bottleneck": bottleneck,
+            "findings": findings,
+            "errors": errors,
+        }
+
+    def _diagnose(
+        self,
+        *,
+        partitions: list[dict[str, object]],
+        lagging: list[dict[str, object]],
+        total_lag: int,
+        consume_rates: dict[str, float],
+        produce_rates: dict[str, float],
+        under_replicated: dict[str, float],
+        offline: dict[str, float],
+        members: float | None,
+    ) -> tuple[str, list[str]]:
+        findings: list[str] = []
+        if any(offline.values()):
+            findings.append(f"partitions without a leader: {_topic_counts(offline)}")
+        if any(under_replicated.values()):
+            findings.append(f"under-replicated partitions: {_topic_counts(under_replicated)}")
+        if findings:
+            return "brokers", findings
+        if not lagging:
+            return "none", [
+                f"no partition lags by {self._lag_threshold} or more messages "
+                f"(total lag {total_lag})"
+            ]
+
+        growing = any(isinstance(item["growth"], float) and item["growth"] > 0 for item in lagging)
+        consumed = sum(consume_rates.values())
+        produced = sum(produce_rates.values())
+        if members == 0:
+            findings.append("consumer group has no active members")
+        elif consume_rates and consumed == 0:
+            findings.append(f"consumers committed no offsets in the last {_RATE_WINDOW} (stalled)")
+        elif growing and produced > consumed * 1.1:
+            findings.append(
+                f"producers write {produced:.1f} msg/s but the group consumes only "
+                f"{consumed:.1f} msg/s; lag is growing"
+            )
+        if findings:
+            return "consumers", findings
+
+        top_lag = int(lagging[0]["lag"] or 0)
+        if len(partitions) > 1 and len(lagging) * 4 <= len(partitions) and top_lag * 2 >= total_lag:
+            return "partition_skew", [
+                f"lag is concentrated on {len(lagging)} of {len(partitions)} partitions "
+                f"(top: {lagging[0]['topic']}/{lagging[0]['partition']} with {top_lag}); "
+                "check for a hot key or one slow consumer instance"
+            ]
+        trend = "growing" if growing else "stable or shrinking"
+        return "unknown", [f"{len(lagging)} partition(s) lag above the threshold; lag is {trend}"]
+
+    def _vector(self, query: str, errors: list[str]) -> list[tuple[dict[str, str], float]]:
+        response = self._prometheus.query(query)
+        if "error" in response:
+            errors.append(f"{query}: {response.get('detail') or response['error']}")
+            return []
+        data = response.get("data")
+        result = data.get("data", {}).get("result") if isinstance(data, dict) else None
+        samples: list[tuple[dict[str, str], float]] = []
+        for item in result if isinstance(result, list) else []:
+            value = item.get("value") if isinstance(item, dict) else None
+            try:
+                number = float(value[1])  # type: ignore[index]
+            except (TypeError, ValueError, IndexError):
+                continue
+            if number != number:  # NaN from empty rate windows
+                continue
+            samples.append((item.get("metric") or {}, number))
+        return samples
+
+    def _by_topic(self, query: str, errors: list[str]) -> dict[str, float]:
+        samples = self._vector(query, errors)
+        return {labels.get("topic", ""): round(value, 3) for labels, value in samples}
+
+    def _scalar(self, query: str, errors: list[str]) -> float | None:
+        samples = self._vector(query, errors)
+        return samples[0][1] if samples else None
+
+
+def _partition_key(labels: dict[str, str]) -> tuple[str | None, str | None]:
+    return labels.get("topic"), labels.get("partition")
+
+
+def _topic_counts(values: dict[str, float]) -> str:
+    return ", ".join(f"{topic}={int(count)}" for topic, count in values.items() if count)
+
+
+def _round(value: float | None) -> float | None:
+    return None if value is None else round(value, 1)
+
+
+def _escape(value: str) -> str:
+    return value.replace("\\", "\\\\").replace('"', '\\"')
+
+
+def _escape_regex(value: str) -> str:
+    # Kafka topic names only use [a-zA-Z0-9._-]; "." is the one regex metacharacter.
+    return _escape(value).replace(".", "\\\\.")
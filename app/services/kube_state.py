@@ -0,0 +1,298 @@
+from __future__ import annotations
+
+from datetime import datetime
+from typing import Protocol
+
+from app.clients.kube_state_metrics import SeriesSelector, StateSample
+
+POD_SERIES = (
+    "kube_pod_status_phase",
+    "kube_pod_status_ready",
+    "kube_pod_status_reason",
+    "kube_pod_container_status_ready",
+    "kube_pod_container_status_restarts_total",
+    "kube_pod_container_status_waiting_reason",
+    "kube_pod_container_status_last_terminated_reason",
+)
+# Series per workload kind, keyed by the label that names the object.
+WORKLOAD_SERIES = {
+    "deployment": (
+        "kube_deployment_spec_replicas",
+        "kube_deployment_status_replicas_available",
+        "kube_deployment_status_replicas_unavailable",
+        "kube_deployment_status_replicas_updated",
+        "kube_deployment_status_condition",
+        "kube_deployment_metadata_generation",
+        "kube_deployment_status_observed_generation",
+    ),
+    "statefulset": (
+        "kube_statefulset_replicas",
+        "kube_statefulset_status_replicas_ready",
+        "kube_statefulset_status_replicas_updated",
+    ),
+    "daemonset": (
+        "kube_daemonset_status_desired_number_scheduled",
+        "kube_daemonset_status_number_available",
+        "kube_daemonset_status_number_unavailable",
+        "kube_daemonset_status_number_misscheduled",
+    ),
+}
+_HEALTHY_PHASES = frozenset({"Running", "Succeeded"})
+
+
+class StateSeriesSource(Protocol):
+    source: str
+
+    def series(
+        self, selectors: list[SeriesSelector], *, at: datetime | None = None
+    ) -> list[StateSample]: ...
+
+
+class _PrometheusQuery(Protocol):
+    def query(self, query: str, *, time: str | None = None) -> dict[str, object]: ...
+
+
+class PrometheusStateSource:
+    """kube-state-metrics series as scraped by Prometheus, evaluated at ``at``."""
+
+    source = "prometheus"
+
+    def __init__(self, prometheus_client: _PrometheusQuery) -> None:
+        self._prometheus = prometheus_client
+
+    def series(
+        self, selectors: list[SeriesSelector], *, at: datetime | None = None
+    ) -> list[StateSample]:
+        """Raises ``RuntimeError`` when Prometheus cannot answer the query."""
+        query = " or ".join(_vector_selector(selector) for selector in selectors)
+        response = self._prometheus.query(
+            query, time=at.isoformat().replace("+00:00", "Z") if at else None
+        )
+        if "error" in response:
+            raise RuntimeError(str(response.get("detail") or response["error"]))
+        data = response.get("data")
+        result = data.get("data", {}).get("result") if isinstance(data, dict) else None
+        samples: list[StateSample] = []
+        for item in result if isinstance(result, list) else []:
+            labels = dict(item.get("metric") or {}) if isinstance(item, dict) else {}
+            value = item.get("value") if isinstance(item, dict) else None
+            try:
+                number = float(value[1])  # type: ignore[index]
+            except (TypeError, ValueError, IndexError):
+                continue
+            name = labels.pop("__name__", "")
+            samples.append(StateSample(name=name, labels=labels, value=number))
+        return samples
+
+
+class KubeStateCollector:
+    """Object-state snapshot of the alerted pod and workload from kube-state-metrics.
+
+    Reads pod phase, readiness, eviction reason and per-container restarts,
+    waiting and last termination reasons, plus the replica counts, conditions
+    and rollout generation of the Deployment, StatefulSet or DaemonSet named
+    by the workload, and lists what looks unhealthy under ``findings``.
+    """
+
+    def __init__(self, source: StateSeriesSource) -> None:
+        self._source = source
+
+    @property
+    def source(self) -> str:
+        return self._source.source
+
+    def snapshot(
+        self,
+        namespace: str,
+        *,
+        pod_name: str | None,
+        workload: str | None,
+        at: datetime | None = None,
+    ) -> dict[str, object] | None:
+        """``None`` when kube-state-metrics has no series for the objects."""
+        selectors: list[SeriesSelector] = []
+        if pod_name:
+            selectors.append(
+                SeriesSelector(POD_SERIES, (("namespace", namespace), ("pod", pod_name)))
+            )
+        if workload:
+            selectors.extend(
+                SeriesSelector(names, (("namespace", namespace), (kind, workload)))
+                for kind, names in WORKLOAD_SERIES.items()
+            )
+        if not selectors:
+            return None
+        samples = self._source.series(selectors, at=at)
+        pod = _pod_state(pod_name, samples) if pod_name else None
+        workload_state = _workload_state(workload, samples) if workload else None
+        if pod is None and workload_state is None:
+            return None
+        findings = [*_pod_findings(pod), *_workload_findings(workload_state)]
+        return {
+            "source": self._source.source,
+            "evaluated_at": at.isoformat() if at and self._source.source == "prometheus" else None,
+            "pod": pod,
+            "workload": workload_state,
+            "findings": findings,
+        }
+
+
+def _pod_state(pod_name: str, samples: list[StateSample]) -> dict[str, object] | None:
+    pod_samples = [
+        sample
+        for sample in samples
+        if sample.name in POD_SERIES and sample.labels.get("pod") == pod_name
+    ]
+    if not pod_samples:
+        return None
+    containers: dict[str, dict[str, object]] = {}
+    state: dict[str, object] = {"name": pod_name, "phase": None, "ready": None, "reason": None}
+    for sample in pod_samples:
+        if "container" in sample.labels:
+            container = containers.setdefault(
+                sample.labels["container"],
+                {
+                    "container": sample.labels["container"],
+                    "ready": None,
+                    "restarts": None,
+                    "waiting_reason": None,
+                    "last_terminated_reason": None,
+                },
+            )
+            if sample.name == "kube_pod_container_status_ready":
+                container["ready"] = sample.value == 1
+            elif sample.name == "kube_pod_container_status_restarts_total":
+                container["restarts"] = int(sample.value)
+            elif sample.name == "kube_pod_container_status_waiting_reason" and sample.value == 1:
+                container["waiting_reason"] = sample.labels.get("reason")
+            elif (
+                sample.name == "kube_pod_container_status_last_terminated_reason"
+                and sample.value == 1
+            ):
+                container["last_terminated_reason"] = sample.labels.get("reason")
+        elif sample.value != 1:
+            continue
+        elif sample.name == "kube_pod_status_phase":
+            state["phase"] = sample.labels.get("phase")
+        elif sample.name == "kube_pod_status_ready":
+            state["ready"] = sample.labels.get("condition") == "true"
+        elif sample.name == "kube_pod_status_reason":
+            state["reason"] = sample.labels.get("reason")
+    state["containers"] = sorted(containers.values(), key=lambda item: str(item["container"]))
+    return state
+
+
+def _workload_state(workload: str, samples: list[StateSample]) -> dict[str, object] | None:
+    for kind, names in WORKLOAD_SERIES.items():
+        values: dict[str, float] = {}
+        conditions: dict[str, str] = {}
+        for sample in samples:
+            if sample.name not in names or sample.labels.get(kind) != workload:
+                continue
+            if sample.name == "kube_deployment_status_condition":
+                if sample.value == 1:
+                    conditions[sample.labels.get("condition", "")] = sample.labels.get(
+                        "status", ""
+                    )
+            else:
+                values[sample.name] = sample.value
+        if not values and not conditions:
+            continue
+        if kind == "deployment":
+            generation = values.get("kube_deployment_metadata_generation")
+            observed = values.get("kube_deployment_status_observed_generation")
+            return {
+                "kind": kind,
+                "name": workload,
+                "desired": _count(values.get("kube_deployment_spec_replicas")),
+                "available": _count(values.get("kube_deployment_status_replicas_available")),
+                "unavailable": _count(values.get("kube_deployment_status_replicas_unavailable")),
+                "updated": _count(values.get("kube_deployment_status_replicas_updated")),
+                "conditions": conditions,
+                "generation_pending": (
+                    generation is not None and observed is not None and observed < generation
+                ),
+            }
+        if kind == "statefulset":
+            desired = _count(values.get("kube_statefulset_replicas"))
+            ready = _count(values.get("kube_statefulset_status_replicas_ready"))
+            return {
+                "kind": kind,
+                "name": workload,
+                "desired": desired,
+                "available": ready,
+                "unavailable": (
+                    max(0, desired - ready) if desired is not None and ready is not None else None
+                ),
+                "updated": _count(values.get("kube_statefulset_status_replicas_updated")),
+            }
+        return {
+            "kind": kind,
+            "name": workload,
+            "desired": _count(values.get("kube_daemonset_status_desired_number_scheduled")),
+            "available": _count(values.get("kube_daemonset_status_number_available")),
+            "unavailable": _count(values.get("kube_daemonset_status_number_unavailable")),
+            "misscheduled": _count(values.get("kube_daemonset_status_number_misscheduled")),
+        }
+    return None
+
+
+def _pod_findings(pod: dict[str, object] | None) -> list[str]:
+    if pod is None:
+        return []
+    findings: list[str] = []
+    name = pod["name"]
+    if pod.get("reason"):
+        findings.append(f"pod {name} status reason: {pod['reason']}")
+    if pod.get("phase") and pod["phase"] not in _HEALTHY_PHASES:
+        findings.append(f"pod {name} is in phase {pod['phase']}")
+    elif pod.get("ready") is False and pod.get("phase") != "Succeeded":
+        findings.append(f"pod {name} is not ready")
+    for container in pod.get("containers") or []:  # type: ignore[attr-defined]
+        if container.get("waiting_reason"):
+            findings.append(
+                f"container {container['container']} is waiting: {container['waiting_reason']}"
+            )
+        if container.get("restarts"):
+            last = container.get("last_terminated_reason")
+            findings.append(
+                f"container {container['container']} restarted {container['restarts']} times"
+                + (f" (last termination: {last})" if last else "")
+            )
+    return findings
+
+
+def _workload_findings(workload: dict[str, object] | None) -> list[str]:
+    if workload is None:
+        return []
+    findings: list[str] = []
+    label = f"{workload['kind']} {workload['name']}"
+    desired = workload.get("desired")
+    if workload.get("unavailable"):
+        findings.append(f"{label} has {workload['unavailable']} of {desired} replicas unavailable")
+    updated = workload.get("updated")
+    if isinstance(desired, int) and isinstance(updated, int) and updated < desired:
+        findings.append(f"{label} rollout incomplete: {updated} of {desired} replicas updated")
+    conditions = workload.get("conditions") or {}
+    for condition in ("Available", "Progressing"):
+        if conditions.get(condition) == "false":  # type: ignore[union-attr]
+            findings.append(f"{label} condition {condition}=false")
+    if workload.get("generation_pending"):
+        findings.append(f"{label} spec change not yet observed by the controller")
+    if workload.get("misscheduled"):
+        findings.append(f"{label} has {workload['misscheduled']} misscheduled pods")
+    return findings
+
+
+def _count(value: float | None) -> int | None:
+    return None if value is None else int(value)
+
+
+def _vector_selector(selector: SeriesSelector) -> str:
+    matchers = [f'__name__=~"{"|".join(selector.names)}"']
+    matchers.extend(f'{key}="{_escape(value)}"' for key, value in selector.labels)
+    return "{" + ",".join(matchers) + "}"
+
+
+def _escape(value: str) -> str:
+    return value.replace("\\", "\\\\").replace('"', '\\"')
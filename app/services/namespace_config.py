@@ -0,0 +1,170 @@
+"""Per-namespace analysis settings from ``RCAConfig`` custom resources.
+
+App teams declare, next to their workloads, where their logs live, which
+runbooks and dashboards apply and how deep analyses of their alerts go::
+
+    apiVersion: kube-rca.io/v1alpha1
+    kind: RCAConfig
+    metadata:
+      name: checkout
+      namespace: shop
+    spec:
+      alertSelector:        # alert labels that must match; empty = every alert of the namespace
+        app: checkout
+      analysisDepth: deep   # quick | standard | deep
+      logSources:
+        - name: checkout
+          type: loki
+          query: '{namespace="shop", app="checkout"}'
+        - name: payments
+          url: https://kibana.example.com/app/discover#/view/payments
+      runbooks:
+        - title: Checkout on-call
+          url: https://runbooks.example.com/checkout
+      dashboards:
+        - title: Checkout golden signals
+          url: https://grafana.example.com/d/checkout
+      promptInstructions: Checkout pods restart nightly at 03:00 UTC on purpose.
+
+The RCAConfigs of the alert's namespace whose selector matches are merged in
+name order: lists are concatenated (duplicates dropped), the first
+``analysisDepth`` wins and instructions are joined. The result is merged with
+the global configuration by the analysis: it adds to the prompt and the
+global operator instructions, never replaces them.
+"""
+
+from __future__ import annotations
+
+from collections.abc import Mapping
+
+DEPTH_QUICK = "quick"
+DEPTH_STANDARD = "standard"
+DEPTH_DEEP = "deep"
+# Scale of the prompt's evidence limits (log lines, events, token budget).
+DEPTH_SCALES = {DEPTH_QUICK: 0.5, DEPTH_STANDARD: 1.0, DEPTH_DEEP: 2.0}
+
+_LINK_FIELDS = ("runbooks", "dashboards")
+
+
+def resolve_namespace_config(
+    items: list[dict[str, object]], labels: Mapping[str, str]
+) -> tuple[dict[str, object] | None, list[str]]:
+    """Merge the RCAConfigs matching the alert's labels; return the settings and warnings."""
+    warnings: list[str] = []
+    matched: list[str] = []
+    depth: str | None = None
+    log_sources: list[dict[str, object]] = []
+    links: dict[str, list[dict[str, object]]] = {field: [] for field in _LINK_FIELDS}
+    instructions: list[str] = []
+    for item in sorted(items, key=_name):
+        spec = item.get("spec")
+        if not isinstance(spec, dict):
+            continue
+        name = f"{_namespace(item)}/{_name(item)}"
+        selector = spec.get("alertSelector") or {}
+        if not isinstance(selector, dict):
+            warnings.append(f"RCAConfig {name}: alertSelector must be a map of labels")
+            continue
+        if any(labels.get(str(key)) != str(value) for key, value in selector.items()):
+            continue
+        matched.append(name)
+        requested = spec.get("analysisDepth")
+        if requested is not None:
+            if str(requested).lower() not in DEPTH_SCALES:
+                warnings.append(
+                    f"RCAConfig {name}: unknown analysisDepth {requested!r}; "
+                    f"use one of {sorted(DEPTH_SCALES)}"
+                )
+            elif depth is None:
+                depth = str(requested).lower()
+        for source in _entries(spec.get("logSources"), name, "logSources", warnings):
+            entry = {
+                "name": source.get("name"),
+                "type": str(source.get("type") or ("link" if source.get("url") else "loki")),
+                "query": source.get("query"),
+                "url": source.get("url"),
+            }
+            if not entry["query"] and not entry["url"]:
+                warnings.append(f"RCAConfig {name}: log source without query or url")
+            elif entry not in log_sources:
+                log_sources.append(entry)
+        for field in _LINK_FIELDS:
+            for link in _entries(spec.get(field), name, field, warnings):
+                if not link.get("url"):
+                    warnings.append(f"RCAConfig {name}: {field} entry without url")
+                    continue
+                entry = {"title": link.get("title") or link.get("url"), "url": link.get("url")}
+                if all(existing["url"] != entry["url"] for existing in links[field]):
+                    links[field].append(entry)
+        text = spec.get("promptInstructions")
+        if isinstance(text, str) and text.strip():
+            instructions.append(text.strip())
+    if not matched:
+        return None, warnings
+    return {
+        "configs": matched,
+        "analysis_depth": depth or DEPTH_STANDARD,
+        "log_sources": log_sources,
+        **links,
+        "prompt_instructions": "\n".join(instructions),
+    }, warnings
+
+
+def depth_scale(config: Mapping[str, object] | None) -> float:
+    if config is None:
+        return 1.0
+    return DEPTH_SCALES.get(str(config.get("analysis_depth")), 1.0)
+
+
+def scale_evidence_limit(value: int, scale: float) -> int:
+    # Zero disables a limit (the token budget), whatever the depth.
+    return value if value <= 0 else max(1, int(value * scale))
+
+
+def format_namespace_config(config: Mapping[str, object]) -> str:
+    """Prompt section with the team's log sources, runbooks, dashboards and instructions."""
+    section = (
+        "Team settings (from the RCAConfig of the alert's namespace):\n"
+    )
+    for source in _dicts(config.get("log_sources")):
+        label = source.get("name") or source.get("type")
+        if source.get("query"):
+            section += f"- log source {label} ({source.get('type')}): {source['query']}\n"
+        else:
+            section += f"- log source {label}: {source.get('url')}\n"
+    for key, noun in (("runbooks", "runbook"), ("dashboards", "dashboard")):
+        for link in _dicts(config.get(key)):
+            section += f"- {noun}: {link.get('title')} <{link.get('url')}>\n"
+    section += (
+        "Query the team's log sources first, and link the runbooks and dashboards that apply "
+        "in the answer.\n"
+    )
+    instructions = config.get("prompt_instructions")
+    if isinstance(instructions, str) and instructions:
+        section += f"Team instructions (follow unless contradicted by evidence):\n{instructions}\n"
+    return section + "\n"
+
+
+def _entries(
+    value: object, name: str, field: str, warnings: list[str]
+) -> list[dict[str, object]]:
+    if value is None:
+        return []
+    if not isinstance(value, list):
+        warnings.append(f"RCAConfig {name}: {field} must be a list")
+        return []
+    return [entry for entry in value if isinstance(entry, dict)]
+
+
+def _dicts(value: object) -> list[dict[str, object]]:
+    return [item for item in value if isinstance(item, dict)] if isinstance(value, list) else []
+
+
+def _name(item: Mapping[str, object]) -> str:
+    metadata = item.get("metadata")
+    return str(metadata.get("name") or "") if isinstance(metadata, dict) else ""
+
+
+def _namespace(item: Mapping[str, object]) -> str:
+    metadata = item.get("metadata")
+    return str(metadata.get("namespace") or "") if isinstance(metadata, dict) else ""
@@ -0,0 +1,107 @@
+"""Health of a whole namespace for broad-impact alerts, returned as ``namespace_snapshot``.
+
+Alerts without a pod (a namespace error-rate or SLO alert) cannot be traced
+to one container, so the analysis looks at the namespace instead:
+``KubernetesClient.get_namespace_snapshot`` reads the replica health of its
+Deployments, StatefulSets and DaemonSets and the pods that are not running
+ready, and the namespace's Warning events are already collected. Each list is
+bounded (``NAMESPACE_SNAPSHOT_MAX_*``), unhealthy objects first, with totals so
+the truncation stays visible.
+"""
+
+from __future__ import annotations
+
+from collections import Counter
+
+from app.models.k8s import K8sContext
+
+_MAX_EVENT_REASONS = 5
+
+
+def build_namespace_snapshot(k8s_context: K8sContext) -> dict[str, object] | None:
+    data = k8s_context.namespace_snapshot
+    if not data:
+        return None
+    workloads = _dicts(data.get("workloads"))
+    failing_pods = _dicts(data.get("failing_pods"))
+    max_events = _int(data.get("max_events"))
+    events = sorted(
+        (event for event in k8s_context.events if event.type == "Warning"),
+        key=lambda event: event.last_timestamp or event.first_timestamp or "",
+        reverse=True,
+    )
+    recent_events = [
+        {
+            "reason": event.reason,
+            "object": _object_name(event.involved_object),
+            "count": event.count or 1,
+            "last_timestamp": event.last_timestamp or event.first_timestamp,
+            "message": event.message,
+        }
+        for event in events[:max_events]
+    ]
+    unhealthy = _int(data.get("unhealthy_workload_count"))
+    failing = _int(data.get("failing_pod_count"))
+    namespace = data.get("namespace") or k8s_context.namespace
+    findings = [
+        f"namespace {namespace}: {unhealthy}/{_int(data.get('workload_count'))} workload(s) "
+        f"below their desired replicas, {failing}/{_int(data.get('pod_count'))} pod(s) failing"
+    ]
+    for item in workloads:
+        if not item.get("healthy"):
+            findings.append(
+                f"{item.get('kind')} {item.get('name')}: {item.get('ready')}/{item.get('desired')} "
+                f"ready, {item.get('available')} available, {item.get('updated')} updated"
+            )
+    for pod in failing_pods:
+        finding = (
+            f"pod {pod.get('name')} {pod.get('phase')} ({pod.get('ready')} ready, "
+            f"{pod.get('restarts')} restart(s))"
+        )
+        reasons = [str(reason) for reason in pod.get("container_reasons") or []]
+        if reasons or pod.get("reason"):
+            finding += f": {', '.join(reasons or [str(pod.get('reason'))])}"
+        findings.append(finding)
+    omitted_workloads = unhealthy - sum(1 for item in workloads if not item.get("healthy"))
+    if omitted_workloads > 0:
+        findings.append(f"{omitted_workloads} more unhealthy workload(s) not listed")
+    if failing > len(failing_pods):
+        findings.append(f"{failing - len(failing_pods)} more failing pod(s) not listed")
+    reasons = Counter[str]()
+    for event in events:
+        reasons[str(event.reason)] += event.count or 1
+    if reasons:
+        findings.append(
+            "warning events: "
+            + ", ".join(
+                f"{reason} x{count}" for reason, count in reasons.most_common(_MAX_EVENT_REASONS)
+            )
+        )
+    return {
+        "namespace": namespace,
+        "workloads": workloads,
+        "workload_count": _int(data.get("workload_count")),
+        "unhealthy_workload_count": unhealthy,
+        "failing_pods": failing_pods,
+        "pod_count": _int(data.get("pod_count")),
+        "failing_pod_count": failing,
+        "recent_events": recent_events,
+        "truncated": (
+            omitted_workloads > 0 or failing > len(failing_pods) or len(events) > max_events
+        ),
+        "findings": findings,
+    }
+
+
+def _object_name(involved: dict[str, str | None] | None) -> str | None:
+    if not involved:
+        return None
+    return f"{involved.get('kind') or 'object'}/{involved.get('name') or '?'}"
+
+
+def _dicts(value: object) -> list[dict[str, object]]:
+    return [item for item in value if isinstance(item, dict)] if isinstance(value, list) else []
+
+
+def _int(value: object) -> int:
+    return value if isinstance(value, int) else 0
@@ -0,0 +1,419 @@
+"""NetworkPolicy evaluation of connection-failure alerts, returned as ``network_policy_analysis``.
+
+Connection-failure alerts between services (a ``destination_service`` label
+as set by Istio/Linkerd metrics, or a connection/timeout/5xx alert name with a
+``service`` label) get the NetworkPolicies of both ends read with
+``KubernetesClient.get_network_connectivity``. Egress is evaluated against the
+policies selecting the alerting pod, ingress against those selecting the
+destination service's pods, following the NetworkPolicy semantics: a pod
+selected by no policy of a direction is not isolated, otherwise traffic must
+match a rule's peers and ports. ``ipBlock`` peers and named ports cannot be
+matched to pods from the API alone and make the verdict ``unknown``. For
+blocked traffic the findings name the isolating policies and the allow rule
+they are missing.
+"""
+
+from __future__ import annotations
+
+import json
+
+from app.models.k8s import K8sContext
+
+_DESTINATION_SERVICE_LABELS = (
+    "destination_service_name",
+    "destination_service",
+    "destination_workload",
+    "dst_service",
+    "target_service",
+    "upstream_service",
+)
+_DESTINATION_NAMESPACE_LABELS = (
+    "destination_service_namespace",
+    "destination_namespace",
+    "destination_workload_namespace",
+    "dst_namespace",
+    "target_namespace",
+    "exported_namespace",
+)
+_DESTINATION_PORT_LABELS = ("destination_port", "dst_port", "target_port")
+# A plain ``service`` label usually names the scrape target, so it only counts as the
+# destination of alerts named like a connection failure.
+_CONNECTIVITY_ALERT_MARKERS = (
+    "connect",
+    "refused",
+    "timeout",
+    "unreachable",
+    "network",
+    "egress",
+    "ingress",
+    "dns",
+    "upstream",
+    "dial",
+    "5xx",
+    "502",
+    "503",
+    "504",
+)
+# Labels that differ per pod or per revision and make poor policy selectors.
+_VOLATILE_POD_LABELS = frozenset(
+    {
+        "pod-template-hash",
+        "controller-revision-hash",
+        "pod-template-generation",
+        "statefulset.kubernetes.io/pod-name",
+        "apps.kubernetes.io/pod-index",
+    }
+)
+_IDENTITY_POD_LABELS = ("app.kubernetes.io/name", "app", "k8s-app", "name")
+_NAMESPACE_NAME_LABEL = "kubernetes.io/metadata.name"
+_DNS_PORT = 53
+
+
+def connectivity_target(
+    labels: dict[str, str], k8s_context: K8sContext
+) -> dict[str, object] | None:
+    """Destination service of a connection-failure alert from the alerting pod, else ``None``."""
+    if not k8s_context.namespace or not k8s_context.pod_name:
+        return None
+    service = next((labels[key] for key in _DESTINATION_SERVICE_LABELS if labels.get(key)), None)
+    alertname = labels.get("alertname", "").lower()
+    if not service and any(marker in alertname for marker in _CONNECTIVITY_ALERT_MARKERS):
+        service = labels.get("service")
+    if not service or service.lower() == "unknown":
+        return None
+    namespace = next(
+        (labels[key] for key in _DESTINATION_NAMESPACE_LABELS if labels.get(key)), None
+    )
+    # Istio reports destination_service as <name>.<namespace>.svc.cluster.local.
+    name, _, rest = service.partition(".")
+    if rest.partition(".")[2].startswith("svc"):
+        namespace = namespace or rest.partition(".")[0]
+    port = next((labels[key] for key in _DESTINATION_PORT_LABELS if labels.get(key)), None)
+    return {
+        "namespace": namespace or k8s_context.namespace,
+        "service": name,
+        "port": int(port) if port and port.isdigit() else port,
+    }
+
+
+def build_network_policy_analysis(k8s_context: K8sContext) -> dict[str, object] | None:
+    connectivity = k8s_context.network_connectivity
+    if not connectivity:
+        return None
+    source = _dict(connectivity.get("source"))
+    destination = _dict(connectivity.get("destination"))
+    source_labels = _labels(source.get("labels"))
+    destination_labels = _labels(destination.get("selector")) or None
+    if (
+        source_labels is not None
+        and destination_labels is not None
+        and source.get("namespace") == destination.get("namespace")
+        and _selector_matches({"match_labels": destination_labels}, source_labels)
+    ):
+        # The alert's service label names the pod's own service, not a dependency.
+        return None
+    ports = _destination_ports(destination, connectivity.get("port"))
+    source_name = f"{source.get('namespace')}/{source.get('pod')}"
+    destination_name = f"{destination.get('namespace')}/{destination.get('service')}"
+    if len(ports) == 1:
+        destination_name += f":{ports[0]['port']}"
+    egress = _evaluate(
+        "egress",
+        _dicts(source.get("policies")),
+        selected_labels=source_labels,
+        peer_namespace=str(destination.get("namespace")),
+        peer_namespace_labels=_labels(destination.get("namespace_labels")) or {},
+        peer_labels=destination_labels,
+        ports=ports,
+    )
+    ingress = _evaluate(
+        "ingress",
+        _dicts(destination.get("policies")),
+        selected_labels=destination_labels,
+        peer_namespace=str(source.get("namespace")),
+        peer_namespace_labels=_labels(source.get("namespace_labels")) or {},
+        peer_labels=source_labels,
+        ports=ports,
+    )
+    findings: list[str] = []
+    if source_labels is None:
+        findings.append(f"pod {source_name} could not be read; egress was not evaluated")
+    if not destination.get("found", True):
+        findings.append(f"service {destination_name} was not found; ingress was not evaluated")
+    elif destination_labels is None:
+        findings.append(
+            f"service {destination_name} has no pod selector; ingress was not evaluated"
+        )
+    if egress["verdict"] == "blocked":
+        rule = {
+            "to": [_peer_rule(destination_labels, destination, source)],
+            **_ports_rule(ports),
+        }
+        egress["missing_rule"] = rule
+        findings.append(
+            f"egress from {source_name} to {destination_name} is blocked: "
+            f"NetworkPolicy {_names(egress['policies'])} selects the pod and none of its "
+            f"egress rules allow the destination; missing allow rule: {_json(rule)}"
+        )
+    elif egress["verdict"] == "unknown" and source_labels is not None:
+        findings.append(
+            f"egress from {source_name} to {destination_name} could not be evaluated: "
+            f"the rules of NetworkPolicy {_names(egress['policies'])} use ipBlock peers or "
+            "ports that do not resolve from the API"
+        )
+    if egress["isolated"] and not egress["dns_allowed"]:
+        findings.append(
+            f"egress of {source_name} is restricted by NetworkPolicy "
+            f"{_names(egress['policies'])} and no egress rule allows DNS (port 53); "
+            "service names do not resolve"
+        )
+    if ingress["verdict"] == "blocked":
+        rule = {
+            "from": [_peer_rule(_selector_labels(source_labels or {}), source, destination)],
+            **_ports_rule(ports),
+        }
+        ingress["missing_rule"] = rule
+        findings.append(
+            f"ingress to {destination_name} from {source_name} is blocked: "
+            f"NetworkPolicy {_names(ingress['policies'])} selects the destination pods and "
+            f"none of its ingress rules allow the source; missing allow rule: {_json(rule)}"
+        )
+    elif ingress["verdict"] == "unknown" and destination_labels is not None:
+        findings.append(
+            f"ingress to {destination_name} from {source_name} could not be evaluated: "
+            f"the rules of NetworkPolicy {_names(ingress['policies'])} use ipBlock peers or "
+            "ports that do not resolve from the API"
+        )
+    verdicts = {egress["verdict"], ingress["verdict"]}
+    if "blocked" in verdicts or (egress["isolated"] and not egress["dns_allowed"]):
+        verdict = "blocked"
+    elif "unknown" in verdicts:
+        verdict = "unknown"
+    else:
+        verdict = "allowed"
+    return {
+        "source": {"namespace": source.get("namespace"), "pod": source.get("pod")},
+        "destination": {
+            "namespace": destination.get("namespace"),
+            "service": destination.get("service"),
+            "ports": [item["port"] for item in ports],
+        },
+        "verdict": verdict,
+        "egress": egress,
+        "ingress": ingress,
+        "findings": findings,
+    }
+
+
+def _evaluate(
+    direction: str,
+    policies: list[dict[str, object]],
+    *,
+    selected_labels: dict[str, str] | None,
+    peer_namespace: str,
+    peer_namespace_labels: dict[str, str],
+    peer_labels: dict[str, str] | None,
+    ports: list[dict[str, object]],
+) -> dict[str, object]:
+    """Whether *policies* let traffic of *direction* through for the selected pods."""
+    result: dict[str, object] = {
+        "isolated": False,
+        "policies": [],
+        "allowed_by": [],
+        "verdict": "unknown",
+    }
+    if direction == "egress":
+        result["dns_allowed"] = True
+    if selected_labels is None:
+        return result
+    policy_type = direction.capitalize()
+    selecting = [
+        policy
+        for policy in policies
+        if policy_type in _strings(policy.get("policy_types"))
+        and _selector_matches(_dict(policy.get("pod_selector")), selected_labels)
+    ]
+    if not selecting:
+        result["verdict"] = "allowed"
+        return result
+    peer_key = "to" if direction == "egress" else "from"
+    allowed_by: list[str] = []
+    undecided = False
+    dns_allowed = False
+    for policy in selecting:
+        for rule in _dicts(policy.get(direction)):
+            rule_ports = _dicts(rule.get("ports"))
+            if direction == "egress" and _ports_match(
+                rule_ports, [{"port": _DNS_PORT, "protocol": "UDP"}]
+            ):
+                dns_allowed = True
+            port_match = _ports_match(rule_ports, ports)
+            peers = _dicts(rule.get(peer_key))
+            peer_matches = [
+                _peer_matches(
+                    peer,
+                    str(policy.get("namespace")),
+                    peer_namespace,
+                    peer_namespace_labels,
+                    peer_labels,
+                )
+                for peer in peers
+            ]
+            peer_match: bool | None = True
+            if peers and True not in peer_matches:
+                peer_match = None if None in peer_matches else False
+            if port_match is False or peer_match is False:
+                continue
+            if port_match and peer_match:
+                allowed_by.append(_qualified_name(policy))
+                break
+            undecided = True
+    result["isolated"] = True
+    result["policies"] = [_qualified_name(policy) for policy in selecting]
+    result["allowed_by"] = allowed_by
+    result["verdict"] = "allowed" if allowed_by else "unknown" if undecided else "blocked"
+    if direction == "egress":
+        result["dns_allowed"] = dns_allowed
+    return result
+
+
+def _peer_matches(
+    peer: dict[str, object],
+    policy_namespace: str,
+    peer_namespace: str,
+    peer_namespace_labels: dict[str, str],
+    peer_labels: dict[str, str] | None,
+) -> bool | None:
+    if peer.get("ip_block"):
+        # Pod IPs are not part of the context; an ipBlock may or may not cover them.
+        return None
+    namespace_selector = peer.get("namespace_selector")
+    pod_selector = peer.get("pod_selector")
+    if isinstance(namespace_selector, dict):
+        if not _selector_matches(namespace_selector, peer_namespace_labels):
+            return False
+    elif peer_namespace != policy_namespace:
+        return False
+    if not isinstance(pod_selector, dict) or _selector_empty(pod_selector):
+        return True
+    if peer_labels is None:
+        return None
+    return _selector_matches(pod_selector, peer_labels)
+
+
+def _ports_match(
+    rule_ports: list[dict[str, object]], ports: list[dict[str, object]]
+) -> bool | None:
+    if not rule_ports:
+        return True
+    if not ports:
+        return None
+    undecided = False
+    for rule_port in rule_ports:
+        for port in ports:
+            if str(rule_port.get("protocol") or "TCP") != str(port.get("protocol") or "TCP"):
+                continue
+            allowed, wanted = rule_port.get("port"), port.get("port")
+            if allowed is None or allowed == wanted:
+                return True
+            if isinstance(allowed, int) and isinstance(wanted, int):
+                end_port = rule_port.get("end_port")
+                if isinstance(end_port, int) and allowed <= wanted <= end_port:
+                    return True
+                continue
+            # A named port on one side and a number on the other depend on the pod's spec.
+            undecided = True
+    return None if undecided else False
+
+
+def _destination_ports(
+    destination: dict[str, object], port_hint: object
+) -> list[dict[str, object]]:
+    """Destination pod ports: the service's target ports, narrowed by the alert's port."""
+    ports = [
+        {"port": item.get("target_port"), "protocol": item.get("protocol") or "TCP"}
+        for item in _dicts(destination.get("ports"))
+        if port_hint is None or port_hint in (item.get("port"), item.get("target_port"))
+    ]
+    if not ports and port_hint is not None:
+        ports = [{"port": port_hint, "protocol": "TCP"}]
+    return ports
+
+
+def _selector_matches(selector: dict[str, object], labels: dict[str, str]) -> bool:
+    for key, value in _labels(selector.get("match_labels") or {}).items():
+        if labels.get(key) != value:
+            return False
+    for expression in _dicts(selector.get("match_expressions")):
+        key = str(expression.get("key"))
+        operator = expression.get("operator")
+        values = _strings(expression.get("values"))
+        if operator == "In" and labels.get(key) not in values:
+            return False
+        if operator == "NotIn" and key in labels and labels[key] in values:
+            return False
+        if operator == "Exists" and key not in labels:
+            return False
+        if operator == "DoesNotExist" and key in labels:
+            return False
+    return True
+
+
+def _selector_empty(selector: dict[str, object]) -> bool:
+    return not selector.get("match_labels") and not selector.get("match_expressions")
+
+
+def _selector_labels(labels: dict[str, str]) -> dict[str, str]:
+    """Stable labels to select a pod by in a suggested rule."""
+    for key in _IDENTITY_POD_LABELS:
+        if labels.get(key):
+            return {key: labels[key]}
+    return {key: value for key, value in labels.items() if key not in _VOLATILE_POD_LABELS}
+
+
+def _peer_rule(
+    labels: dict[str, str] | None, peer: dict[str, object], policy_side: dict[str, object]
+) -> dict[str, object]:
+    """Rule peer selecting *peer*'s pods, from a policy in *policy_side*'s namespace."""
+    rule: dict[str, object] = {"podSelector": {"matchLabels": labels or {}}}
+    if peer.get("namespace") != policy_side.get("namespace"):
+        rule["namespaceSelector"] = {
+            "matchLabels": {_NAMESPACE_NAME_LABEL: str(peer.get("namespace"))}
+        }
+    return rule
+
+
+def _ports_rule(ports: list[dict[str, object]]) -> dict[str, object]:
+    if not ports:
+        return {}
+    return {"ports": [{"protocol": item["protocol"], "port": item["port"]} for item in ports]}
+
+
+def _qualified_name(policy: dict[str, object]) -> str:
+    return f"{policy.get('namespace')}/{policy.get('name')}"
+
+
+def _names(policies: object) -> str:
+    return ", ".join(_strings(policies)) or "-"
+
+
+def _json(value: object) -> str:
+    return json.dumps(value, separators=(", ", ": "))
+
+
+def _labels(value: object) -> dict[str, str] | None:
+    if not isinstance(value, dict):
+        return None
+    return {str(key): str(item) for key, item in value.items()}
+
+
+def _strings(value: object) -> list[str]:
+    return [str(item) for item in value] if isinstance(value, list) else []
+
+
+def _dict(value: object) -> dict[str, object]:
+    return value if isinstance(value, dict) else {}
+
+
+def _dicts(value: object) -> list[dict[str, object]]:
+    return [item for item in value if isinstance(item, dict)] if isinstance(value, list) else []
@@ -0,0 +1,134 @@
+"""Node health summary for alerts that carry a ``node`` or ``instance`` label.
+
+Built from ``KubernetesClient.get_node_status``: Ready and pressure
+conditions, taints, and how much of the node's capacity is left allocatable
+to pods after system/kube reservations.
+"""
+
+from __future__ import annotations
+
+from app.clients.k8s import parse_quantity
+
+_PRESSURE_CONDITIONS = {
+    "MemoryPressure": "memory is low; the kubelet evicts pods",
+    "DiskPressure": "disk or image filesystem is nearly full; the kubelet evicts pods",
+    "PIDPressure": "process IDs are nearly exhausted",
+    "NetworkUnavailable": "node network (CNI routes) is not configured",
+}
+# Taints the node lifecycle controller and kubelet set for unhealthy nodes.
+_HEALTH_TAINTS = frozenset(
+    {
+        "node.kubernetes.io/not-ready",
+        "node.kubernetes.io/unreachable",
+        "node.kubernetes.io/memory-pressure",
+        "node.kubernetes.io/disk-pressure",
+        "node.kubernetes.io/pid-pressure",
+        "node.kubernetes.io/network-unavailable",
+        "node.kubernetes.io/unschedulable",
+        "node.cloudprovider.kubernetes.io/shutdown",
+    }
+)
+_RESOURCES = ("cpu", "memory", "ephemeral-storage", "pods")
+# Share of capacity reserved away from pods above which the reservation is flagged.
+_HIGH_RESERVATION_PERCENT = 25.0
+
+
+def resolve_alert_node(labels: dict[str, str]) -> tuple[str | None, bool]:
+    """Node name from the alert labels and whether it came from an explicit ``node`` label.
+
+    ``instance`` (``host:port`` from node-exporter/kubelet scrapes) is only a
+    best guess: it is the node name on most setups but can be an IP.
+    """
+    node = (labels.get("node") or "").strip()
+    if node:
+        return node, True
+    instance = (labels.get("instance") or "").strip()
+    if not instance:
+        return None, False
+    host = instance.rsplit(":", 1)[0] if instance.count(":") == 1 else instance
+    return host.strip("[]") or None, False
+
+
+def summarize_node_health(node_status: dict[str, object] | None) -> dict[str, object] | None:
+    if not node_status:
+        return None
+    conditions = [item for item in _list(node_status.get("conditions")) if isinstance(item, dict)]
+    findings: list[str] = []
+
+    ready = next((item for item in conditions if item.get("type") == "Ready"), None)
+    ready_status = ready.get("status") if ready else None
+    if ready is not None and ready_status != "True":
+        findings.append(_ready_finding(ready))
+
+    pressures: list[dict[str, object]] = []
+    for condition in conditions:
+        condition_type = str(condition.get("type"))
+        if condition_type in _PRESSURE_CONDITIONS and condition.get("status") == "True":
+            pressures.append(
+                {
+                    "type": condition_type,
+                    "reason": condition.get("reason"),
+                    "message": condition.get("message"),
+                    "since": condition.get("last_transition_time"),
+                }
+            )
+            findings.append(f"{condition_type}: {_PRESSURE_CONDITIONS[condition_type]}")
+
+    taints = [item for item in _list(node_status.get("taints")) if isinstance(item, dict)]
+    health_taints = [
+        f"{taint.get('key')}:{taint.get('effect')}"
+        for taint in taints
+        if taint.get("key") in _HEALTH_TAINTS
+    ]
+    if node_status.get("unschedulable"):
+        findings.append("node is cordoned (unschedulable)")
+    if health_taints:
+        findings.append("health taints: " + ", ".join(health_taints))
+
+    resources = _resources(node_status.get("capacity"), node_status.get("allocatable"))
+    for name, item in resources.items():
+        reserved = item.get("reserved_percent")
+        if isinstance(reserved, float) and reserved >= _HIGH_RESERVATION_PERCENT:
+            findings.append(f"{reserved}% of {name} capacity is reserved away from pods")
+
+    return {
+        "name": node_status.get("name"),
+        "ready": ready_status,
+        "ready_reason": ready.get("reason") if ready else None,
+        "pressure": pressures,
+        "unschedulable": bool(node_status.get("unschedulable")),
+        "taints": [f"{taint.get('key')}:{taint.get('effect')}" for taint in taints],
+        "resources": resources,
+        "healthy": not findings,
+        "findings": findings,
+    }
+
+
+def _ready_finding(ready: dict[str, object]) -> str:
+    if ready.get("status") == "Unknown":
+        cause = "kubelet stopped posting status (node down, kubelet crashed or network partition)"
+    else:
+        cause = "kubelet reports the node not ready"
+    detail = ready.get("message") or ready.get("reason")
+    return f"node NotReady: {cause}" + (f" - {detail}" if detail else "")
+
+
+def _resources(capacity: object, allocatable: object) -> dict[str, dict[str, object]]:
+    if not isinstance(capacity, dict) or not isinstance(allocatable, dict):
+        return {}
+    resources: dict[str, dict[str, object]] = {}
+    for name in _RESOURCES:
+        raw_capacity, raw_allocatable = capacity.get(name), allocatable.get(name)
+        if raw_capacity is None or raw_allocatable is None:
+            continue
+        total = parse_quantity(str(raw_capacity))
+        usable = parse_quantity(str(raw_allocatable))
+        entry: dict[str, object] = {"capacity": raw_capacity, "allocatable": raw_allocatable}
+        if total and usable is not None:
+            entry["reserved_percent"] = round((total - usable) * 100 / total, 1)
+        resources[name] = entry
+    return resources
+
+
+def _list(value: object) -> list[object]:
+    return value if isinstance(value, list) else []
@@ -0,0 +1,152 @@
+"""OOMKilled analysis of the alerting pod, returned as ``oom_analysis``.
+
+For each container whose current or last termination was ``OOMKilled``:
+memory request and limit from the pod spec, current usage from
+metrics.k8s.io, and a suggested limit. A kill at the limit means peak usage
+reached it, so the suggestion is 1.5x the largest of the limit, the current
+usage and the request, rounded up to 64Mi.
+"""
+
+from __future__ import annotations
+
+import math
+
+from app.clients.k8s import parse_quantity
+from app.models.k8s import K8sContext
+
+_HEADROOM = 1.5
+_ROUND_BYTES = 64 * 1024**2
+_MI = 1024**2
+_GI = 1024**3
+
+
+def has_oom_kill(k8s_context: K8sContext) -> bool:
+    return bool(_oom_statuses(k8s_context))
+
+
+def build_oom_analysis(k8s_context: K8sContext) -> dict[str, object] | None:
+    statuses = _oom_statuses(k8s_context)
+    if not statuses:
+        return None
+    specs = _container_specs(k8s_context.pod_spec)
+    usage = _container_usage(k8s_context.pod_metrics)
+    containers: list[dict[str, object]] = []
+    findings: list[str] = []
+    for status, state in statuses:
+        name = str(status.get("name") or "unknown")
+        resources = specs.get(name, {})
+        limit = _memory(resources.get("limits"))
+        request = _memory(resources.get("requests"))
+        used = usage.get(name)
+        restarts = status.get("restart_count")
+        suggested = _suggest_limit(limit, used, request)
+        entry: dict[str, object] = {
+            "name": name,
+            "restart_count": restarts if isinstance(restarts, int) else 0,
+            "last_oom_at": state.get("finished_at"),
+            "memory_request": _format_bytes(request),
+            "memory_limit": _format_bytes(limit),
+            "memory_usage": _format_bytes(used),
+            "usage_percent_of_limit": (
+                round(used * 100 / limit, 1) if used is not None and limit else None
+            ),
+            "suggested_limit": _format_bytes(suggested),
+        }
+        containers.append(entry)
+        findings.append(_finding(entry, has_limit=limit is not None, has_spec=name in specs))
+    return {
+        "pod": k8s_context.pod_name,
+        "namespace": k8s_context.namespace,
+        "containers": containers,
+        "findings": findings,
+    }
+
+
+def _oom_statuses(
+    k8s_context: K8sContext,
+) -> list[tuple[dict[str, object], dict[str, object]]]:
+    if k8s_context.pod_status is None:
+        return []
+    matches: list[tuple[dict[str, object], dict[str, object]]] = []
+    for status in [
+        *k8s_context.pod_status.init_container_statuses,
+        *k8s_context.pod_status.container_statuses,
+    ]:
+        if not isinstance(status, dict):
+            continue
+        for key in ("state", "last_state"):
+            state = status.get(key)
+            if isinstance(state, dict) and state.get("reason") == "OOMKilled":
+                matches.append((status, state))
+                break
+    return matches
+
+
+def _finding(entry: dict[str, object], *, has_limit: bool, has_spec: bool) -> str:
+    name = entry["name"]
+    if not has_spec:
+        finding = f"container {name} was OOMKilled (memory limit unknown)"
+        if entry["suggested_limit"]:
+            finding += f"; size resources.limits.memory to at least {entry['suggested_limit']}"
+        return finding
+    if not has_limit:
+        # Without a limit the kernel only kills the container under node memory pressure.
+        finding = f"container {name} was OOMKilled without a memory limit (node out of memory)"
+        if entry["suggested_limit"]:
+            finding += f"; set resources.limits.memory to {entry['suggested_limit']}"
+        return finding
+    finding = f"container {name} was OOMKilled at its {entry['memory_limit']} memory limit"
+    if entry["memory_usage"]:
+        finding += (
+            f" (current usage {entry['memory_usage']}, "
+            f"{entry['usage_percent_of_limit']}% of the limit)"
+        )
+    return (
+        f"{finding}; raise resources.limits.memory to {entry['suggested_limit']} "
+        "or reduce its memory use"
+    )
+
+
+def _suggest_limit(limit: float | None, used: float | None, request: float | None) -> float | None:
+    base = max((value for value in (limit, used, request) if value), default=None)
+    if base is None:
+        return None
+    return math.ceil(base * _HEADROOM / _ROUND_BYTES) * _ROUND_BYTES
+
+
+def _format_bytes(value: float | None) -> str | None:
+    if value is None:
+        return None
+    if value >= _GI and value % _GI == 0:
+        return f"{int(value // _GI)}Gi"
+    if value >= _MI:
+        return f"{math.ceil(value / _MI)}Mi"
+    return f"{math.ceil(value / 1024)}Ki"
+
+
+def _container_specs(pod_spec: dict[str, object] | None) -> dict[str, dict[str, object]]:
+    specs: dict[str, dict[str, object]] = {}
+    for key in ("init_containers", "containers"):
+        items = pod_spec.get(key) if pod_spec else None
+        for item in items if isinstance(items, list) else []:
+            if isinstance(item, dict) and isinstance(item.get("resources"), dict):
+                specs[str(item.get("name"))] = item["resources"]
+    return specs
+
+
+def _container_usage(pod_metrics: dict[str, object] | None) -> dict[str, float]:
+    usage: dict[str, float] = {}
+    items = pod_metrics.get("containers") if pod_metrics else None
+    for item in items if isinstance(items, list) else []:
+        if not isinstance(item, dict) or not isinstance(item.get("usage"), dict):
+            continue
+        memory = _memory(item["usage"])
+        if memory is not None:
+            usage[str(item.get("name"))] = memory
+    return usage
+
+
+def _memory(resources: object) -> float | None:
+    if not isinstance(resources, dict) or resources.get("memory") is None:
+        return None
+    return parse_quantity(str(resources["memory"]))
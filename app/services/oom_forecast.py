@@ -0,0 +1,199 @@
+from __future__ import annotations
+
+import asyncio
+import logging
+from collections.abc import Callable
+from datetime import datetime, timedelta, timezone
+from typing import Protocol
+
+from app.clients.report_sink import ReportSink
+
+logger = logging.getLogger(__name__)
+
+_CONTAINER_MATCHER = 'container!="",container!="POD"'
+_MIB = 1024 * 1024
+
+
+class _PrometheusQuery(Protocol):
+    def query(self, query: str, *, time: str | None = None) -> dict[str, object]: ...
+
+
+class OomForecastService:
+    """Predict containers that will reach their memory limit before it happens.
+
+    The working set history in Prometheus is the baseline: the growth rate is
+    ``deriv()`` of ``container_memory_working_set_bytes`` over the lookback
+    window, and the limit comes from kube-state-metrics. A container whose
+    usage is already a meaningful share of its limit (``min_usage_ratio``) and
+    keeps growing is forecast to be OOMKilled in ``(limit - usage) / growth``
+    hours; forecasts within the horizon are sent to the report sink as
+    ``oom_forecast``. A container is notified again only after the cooldown or
+    when its forecast moves at least halfway closer.
+    """
+
+    def __init__(
+        self,
+        prometheus_client: _PrometheusQuery,
+        namespaces: tuple[str, ...] | list[str] = (),
+        *,
+        sink: ReportSink | None = None,
+        lookback_hours: int = 6,
+        horizon_hours: int = 24,
+        min_usage_ratio: float = 0.5,
+        notify_cooldown_hours: int = 12,
+        clock: Callable[[], datetime] = lambda: datetime.now(timezone.utc),
+    ) -> None:
+        self._prometheus = prometheus_client
+        self._namespaces = [namespace for namespace in namespaces if namespace]
+        self._sink = sink
+        self._lookback_hours = max(1, lookback_hours)
+        self._horizon_hours = max(1, horizon_hours)
+        self._min_usage_ratio = min_usage_ratio
+        self._cooldown = timedelta(hours=max(0, notify_cooldown_hours))
+        self._clock = clock
+        # (namespace, pod, container) -> (notified at, hours to limit then)
+        self._notified: dict[tuple[str, str, str], tuple[datetime, float]] = {}
+        self._latest_report: dict[str, object] | None = None
+
+    @property
+    def latest_report(self) -> dict[str, object] | None:
+        return self._latest_report
+
+    def scan(self) -> dict[str, object]:
+        now = self._clock()
+        self._notified = {
+            key: value for key, value in self._notified.items() if now - value[0] < self._cooldown
+        }
+        errors: list[str] = []
+        forecasts = self._forecasts(errors)
+        notify = [item for item in forecasts if self._should_notify(item)]
+        report: dict[str, object] = {
+            "scanned_at": now.isoformat(),
+            "namespaces": list(self._namespaces),
+            "lookback_hours": self._lookback_hours,
+            "horizon_hours": self._horizon_hours,
+            "forecast_count": len(forecasts),
+            "forecasts": forecasts,
+            "notified": len(notify),
+            "errors": errors,
+        }
+        if self._sink is not None and notify:
+            report["delivery"] = self._sink.send(
+                "oom_forecast", {**report, "forecasts": notify, "forecast_count": len(notify)}
+            )
+            for item in notify:
+                self._notified[_key(item)] = (now, float(str(item["hours_to_limit"])))
+        self._latest_report = report
+        logger.info(
+            "oom_forecast namespaces=%s forecasts=%d notified=%d",
+            ",".join(self._namespaces) or "*",
+            len(forecasts),
+            len(notify),
+        )
+        return report
+
+    def _forecasts(self, errors: list[str]) -> list[dict[str, object]]:
+        selector = _CONTAINER_MATCHER
+        if self._namespaces:
+            selector += f',namespace=~"{"|".join(_escape(name) for name in self._namespaces)}"'
+        by = "max by (namespace, pod, container)"
+        usage = self._vector(f"{by} (container_memory_working_set_bytes{{{selector}}})", errors)
+        if not usage:
+            return []
+        growth = self._vector(
+            f"{by} (deriv(container_memory_working_set_bytes{{{selector}}}"
+            f"[{self._lookback_hours}h]))",
+            errors,
+        )
+        limits = self._vector(
+            f'{by} (kube_pod_container_resource_limits{{{selector},resource="memory"}})', errors
+        )
+        forecasts: list[dict[str, object]] = []
+        for key, used in usage.items():
+            limit = limits.get(key)
+            rate = growth.get(key)
+            if not limit or rate is None or rate <= 0:
+                continue
+            ratio = used / limit
+            if ratio < self._min_usage_ratio:
+                continue
+            hours = max(0.0, (limit - used) / rate / 3600)
+            if hours > self._horizon_hours:
+                continue
+            forecasts.append(_forecast(key, used, limit, rate, hours))
+        forecasts.sort(key=lambda item: float(str(item["hours_to_limit"])))
+        return forecasts
+
+    def _should_notify(self, forecast: dict[str, object]) -> bool:
+        previous = self._notified.get(_key(forecast))
+        if previous is None:
+            return True
+        # Within the cooldown: only a forecast that moved halfway closer is sent again.
+        return float(str(forecast["hours_to_limit"])) <= previous[1] / 2
+
+    def _vector(self, query: str, errors: list[str]) -> dict[tuple[str, str, str], float]:
+        response = self._prometheus.query(query)
+        if "error" in response:
+            errors.append(f"{query}: {response.get('detail') or response['error']}")
+            return {}
+        data = response.get("data")
+        result = data.get("data", {}).get("result") if isinstance(data, dict) else None
+        samples: dict[tuple[str, str, str], float] = {}
+        for item in result if isinstance(result, list) else []:
+            value = item.get("value") if isinstance(item, dict) else None
+            try:
+                number = float(value[1])  # type: ignore[index]
+            except (TypeError, ValueError, IndexError):
+                continue
+            if number != number:  # NaN from series without enough samples
+                continue
+            labels = item.get("metric") or {}
+            key = (
+                str(labels.get("namespace", "")),
+                str(labels.get("pod", "")),
+                str(labels.get("container", "")),
+            )
+            samples[key] = number
+        return samples
+
+
+def _forecast(
+    key: tuple[str, str, str], used: float, limit: float, rate: float, hours: float
+) -> dict[str, object]:
+    namespace, pod, container = key
+    growth_mib = rate * 3600 / _MIB
+    eta = f"~{hours:.0f} hours" if hours >= 1 else "less than an hour"
+    return {
+        "namespace": namespace,
+        "pod": pod,
+        "container": container,
+        "usage_bytes": int(used),
+        "limit_bytes": int(limit),
+        "usage_ratio": round(used / limit, 3),
+        "growth_bytes_per_hour": int(rate * 3600),
+        "hours_to_limit": round(hours, 1),
+        "summary": (
+            f"container {container} of pod {namespace}/{pod} will OOM in {eta}: "
+            f"{used / _MIB:.0f}Mi of its {limit / _MIB:.0f}Mi limit, growing "
+            f"{growth_mib:.1f}Mi/h"
+        ),
+    }
+
+
+def _key(forecast: dict[str, object]) -> tuple[str, str, str]:
+    return str(forecast["namespace"]), str(forecast["pod"]), str(forecast["container"])
+
+
+def _escape(value: str) -> str:
+    # Namespace names are DNS labels; only quotes and backslashes need escaping.
+    return value.replace("\\", "\\\\").replace('"', '\\"')
+
+
+async def run_oom_forecast_scheduler(service: OomForecastService, interval_seconds: int) -> None:
+    """Run an OOM forecast every *interval_seconds* until cancelled."""
+    while True:
+        try:
+            await asyncio.to_thread(service.scan)
+        except Exception as exc:  # noqa: BLE001
+            logger.warning("OOM forecast failed: %s", exc)
+        await asyncio.sleep(interval_seconds)
@@ -0,0 +1,129 @@
+"""Container state summary of the alerting pod, returned as ``pod_diagnostics``.
+
+Built from the already collected ``PodStatusSnapshot`` (no extra API calls):
+restart counts, waiting reasons, last termination reason and exit code per
+container, and pod conditions that are not ``True``.
+"""
+
+from __future__ import annotations
+
+from app.models.k8s import K8sContext
+from app.services.crash_knowledge import exit_code_hint
+
+# Waiting reasons that keep a container from ever starting.
+_PROBLEM_WAITING_REASONS = frozenset(
+    {
+        "CrashLoopBackOff",
+        "ImagePullBackOff",
+        "ErrImagePull",
+        "InvalidImageName",
+        "CreateContainerConfigError",
+        "CreateContainerError",
+        "RunContainerError",
+    }
+)
+
+
+def build_pod_diagnostics(k8s_context: K8sContext) -> dict[str, object] | None:
+    status = k8s_context.pod_status
+    if status is None:
+        return None
+    containers = [
+        _container_diagnostics(item, init=True)
+        for item in status.init_container_statuses
+        if isinstance(item, dict)
+    ]
+    containers.extend(
+        _container_diagnostics(item, init=False)
+        for item in status.container_statuses
+        if isinstance(item, dict)
+    )
+    failing_conditions = [
+        {
+            "type": condition.get("type"),
+            "status": condition.get("status"),
+            "reason": condition.get("reason"),
+            "message": condition.get("message"),
+        }
+        for condition in status.conditions
+        if condition.get("status") != "True"
+    ]
+    findings = [finding for item in containers for finding in _container_findings(item)]
+    findings.extend(
+        f"condition {item['type']}={item['status']}"
+        + (f" ({item['reason']})" if item.get("reason") else "")
+        for item in failing_conditions
+    )
+    return {
+        "pod": k8s_context.pod_name,
+        "namespace": k8s_context.namespace,
+        "phase": status.phase,
+        "reason": status.reason,
+        "node": status.node_name,
+        "total_restarts": sum(int(item["restart_count"] or 0) for item in containers),
+        "containers": containers,
+        "failing_conditions": failing_conditions,
+        "findings": findings,
+    }
+
+
+def _container_diagnostics(status: dict[str, object], *, init: bool) -> dict[str, object]:
+    state = _state(status.get("state"))
+    last_state = _state(status.get("last_state"))
+    entry: dict[str, object] = {
+        "name": status.get("name"),
+        "init": init,
+        "ready": status.get("ready"),
+        "restart_count": _int(status.get("restart_count")) or 0,
+        "state": state.get("type"),
+        "state_reason": state.get("reason"),
+        "exit_code": _int(state.get("exit_code")),
+        "last_termination": None,
+    }
+    if last_state.get("type") == "terminated":
+        entry["last_termination"] = {
+            "reason": last_state.get("reason"),
+            "exit_code": _int(last_state.get("exit_code")),
+            "finished_at": last_state.get("finished_at"),
+        }
+    return entry
+
+
+def _container_findings(entry: dict[str, object]) -> list[str]:
+    name = f"{'init container' if entry['init'] else 'container'} {entry['name']}"
+    findings: list[str] = []
+    state = entry.get("state")
+    if state == "waiting" and entry.get("state_reason") in _PROBLEM_WAITING_REASONS:
+        findings.append(f"{name} is waiting: {entry['state_reason']}")
+    if state == "terminated" and entry.get("exit_code") not in (None, 0):
+        findings.append(
+            f"{name} terminated: {entry.get('state_reason')}{_exit(entry.get('exit_code'))}"
+        )
+    last_termination = entry.get("last_termination")
+    restarts = entry.get("restart_count")
+    if isinstance(last_termination, dict) and isinstance(restarts, int) and restarts > 0:
+        findings.append(
+            f"{name} restarted {restarts} time(s), last termination: "
+            f"{last_termination.get('reason')}{_exit(last_termination.get('exit_code'))}"
+        )
+    if state == "running" and entry.get("ready") is False and not entry["init"]:
+        findings.append(f"{name} is running but not ready")
+    return findings
+
+
+def _exit(code: object) -> str:
+    if not isinstance(code, int):
+        return ""
+    hint = exit_code_hint(code)
+    return f" (exit code {code}, {hint})" if hint else f" (exit code {code})"
+
+
+def _state(value: object) -> dict[str, object]:
+    return value if isinstance(value, dict) else {}
+
+
+def _int(value: object) -> int | None:
+    try:
+        return int(str(value))
+    except (TypeError, ValueError):
+        return None
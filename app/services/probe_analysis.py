@@ -0,0 +1,352 @@
+"""Probe configuration versus observed probe failures, returned as ``probe_analysis``.
+
+The kubelet reports failed liveness, readiness and startup probes as
+``Unhealthy`` events (``Liveness probe failed: HTTP probe failed with
+statuscode: 500``) and restarts containers with a ``Killing`` event naming
+the container. Each failing probe is matched to its container's probe spec
+(type, path, port, timeout and thresholds) and the failure is classified.
+Failures that the spec itself explains (a 404 on the probe path, a port the
+container does not declare, a missing exec binary, an HTTPS probe against
+plain HTTP, or a liveness probe that fires before the app can start) make the
+probe ``misconfigured``; 5xx answers, timeouts, refused connections on the
+declared port and failing exec checks make the app ``unhealthy``.
+"""
+
+from __future__ import annotations
+
+import re
+
+from app.models.k8s import K8sContext
+
+_UNHEALTHY_RE = re.compile(
+    r"^(Liveness|Readiness|Startup) probe (?:failed|errored):?\s*(.*)", re.I | re.S
+)
+_KILLING_RE = re.compile(r"Container (\S+) failed (liveness|startup) probe", re.I)
+_STATUS_RE = re.compile(r"statuscode:\s*(\d{3})", re.I)
+_URL_RE = re.compile(r'(?:Get|Head) "?(https?)://[^/:"\s]+(?::(\d+))?(/[^"\s]*)?', re.I)
+_DIAL_RE = re.compile(r"dial tcp [^:\s]+:(\d+)", re.I)
+
+# Checked in order against the lowercased failure message.
+_FAILURE_PATTERNS: tuple[tuple[str, tuple[str, ...]], ...] = (
+    # Not "no such file": exec checks like `cat /tmp/healthy` report app state that way.
+    ("exec_not_found", ("executable file not found", "exec format error")),
+    (
+        "scheme_mismatch",
+        ("server gave http response to https client", "tls: first record does not look"),
+    ),
+    ("connection_refused", ("connection refused",)),
+    (
+        "timeout",
+        ("context deadline exceeded", "client.timeout", "i/o timeout", "timed out", "timeout"),
+    ),
+    ("connection_reset", ("connection reset", "eof")),
+)
+_MISCONFIGURED = frozenset({"wrong_path", "auth_required", "exec_not_found", "scheme_mismatch"})
+_UNHEALTHY_REASONS = {
+    "connection_refused": "nothing listens on the probed port; the app is down or restarting",
+    "connection_reset": "the app drops the probe connection",
+    "command_failed": "the exec check reports failure",
+}
+_PROBE_DEFAULTS = {
+    "initial_delay_seconds": 0,
+    "period_seconds": 10,
+    "timeout_seconds": 1,
+    "failure_threshold": 3,
+}
+# A liveness probe without a startup probe that gives up sooner than this
+# restarts slow-starting apps before they listen.
+_MIN_LIVENESS_GRACE_SECONDS = 30
+_MESSAGES_PER_PROBE = 3
+
+
+def build_probe_analysis(k8s_context: K8sContext) -> dict[str, object] | None:
+    spec = k8s_context.pod_spec if isinstance(k8s_context.pod_spec, dict) else {}
+    containers = _dicts(spec.get("containers"))
+    restarts = _restart_counts(k8s_context)
+    probes: dict[tuple[str | None, str], dict[str, object]] = {}
+    killed: dict[tuple[str, str], int] = {}
+    for event in k8s_context.events:
+        message = (event.message or "").strip()
+        if event.reason == "Killing":
+            match = _KILLING_RE.search(message)
+            if match:
+                key = (match.group(1), match.group(2).lower())
+                killed[key] = killed.get(key, 0) + (event.count or 1)
+            continue
+        if event.reason != "Unhealthy":
+            continue
+        match = _UNHEALTHY_RE.match(message)
+        if match is None:
+            continue
+        kind = match.group(1).lower()
+        detail = match.group(2).strip()
+        container = _probe_container(containers, kind, detail)
+        name = str(container.get("name")) if container else None
+        entry = probes.get((name, kind))
+        if entry is None:
+            entry = probes[(name, kind)] = _probe_entry(name, kind, container)
+        entry["failures"] = _failures(entry) + (event.count or 1)
+        messages = entry["messages"]
+        if isinstance(messages, list) and len(messages) < _MESSAGES_PER_PROBE:
+            messages.append(detail)
+        entry.setdefault("observed", _observed(detail))
+    if not probes:
+        return None
+    results = []
+    for (name, kind), entry in probes.items():
+        entry["restarts_by_probe"] = killed.get((str(name), kind), 0)
+        entry["container_restarts"] = restarts.get(str(name), 0)
+        _classify(entry, _container(containers, name))
+        results.append(entry)
+    results.sort(key=lambda entry: (entry["verdict"] != "misconfigured", -_failures(entry)))
+    misconfigured = [entry for entry in results if entry["verdict"] == "misconfigured"]
+    return {
+        "pod": k8s_context.pod_name,
+        "namespace": k8s_context.namespace,
+        "root_cause": "misconfigured_probe" if misconfigured else "unhealthy_app",
+        "probes": results,
+        "findings": [_finding(entry) for entry in results],
+    }
+
+
+def summarize_probe(probe: object) -> dict[str, object] | None:
+    """The probe spec (``V1Probe.to_dict()``) as type, target and timings with defaults."""
+    if not isinstance(probe, dict):
+        return None
+    summary: dict[str, object] = {"type": None}
+    http_get = _dict(probe.get("http_get"))
+    tcp_socket = _dict(probe.get("tcp_socket"))
+    grpc = _dict(probe.get("grpc"))
+    command = _dict(probe.get("_exec") or probe.get("exec")).get("command")
+    if http_get:
+        summary.update(
+            type="httpGet",
+            scheme=str(http_get.get("scheme") or "HTTP").upper(),
+            path=http_get.get("path") or "/",
+            port=http_get.get("port"),
+        )
+    elif tcp_socket:
+        summary.update(type="tcpSocket", port=tcp_socket.get("port"))
+    elif grpc:
+        summary.update(type="grpc", port=grpc.get("port"))
+    elif isinstance(command, list):
+        summary.update(type="exec", command=[str(item) for item in command])
+    for key, default in _PROBE_DEFAULTS.items():
+        value = probe.get(key)
+        summary[key] = value if isinstance(value, int) else default
+    return summary
+
+
+def _probe_entry(
+    name: str | None, kind: str, container: dict[str, object] | None
+) -> dict[str, object]:
+    probe = summarize_probe(container.get(f"{kind}_probe")) if container else None
+    return {
+        "container": name,
+        "probe": kind,
+        "config": probe,
+        "failures": 0,
+        "messages": [],
+        "verdict": "unhealthy",
+        "cause": None,
+        "status_code": None,
+        "issues": [],
+    }
+
+
+def _probe_container(
+    containers: list[dict[str, object]], kind: str, detail: str
+) -> dict[str, object] | None:
+    # Unhealthy events name no container; match the probed port when several have the probe.
+    candidates = [item for item in containers if isinstance(item.get(f"{kind}_probe"), dict)]
+    if len(candidates) <= 1:
+        return candidates[0] if candidates else None
+    port = _observed(detail).get("port")
+    for container in candidates:
+        config = summarize_probe(container.get(f"{kind}_probe")) or {}
+        if port is not None and _resolve_port(container, config.get("port")) == port:
+            return container
+    return candidates[0]
+
+
+def _observed(detail: str) -> dict[str, object]:
+    observed: dict[str, object] = {"failure": "other", "status_code": None, "port": None}
+    status = _STATUS_RE.search(detail)
+    url = _URL_RE.search(detail)
+    dial = _DIAL_RE.search(detail)
+    if url:
+        observed.update(scheme=url.group(1).upper(), path=url.group(3) or "/")
+        if url.group(2):
+            observed["port"] = int(url.group(2))
+    if dial and observed["port"] is None:
+        observed["port"] = int(dial.group(1))
+    if status:
+        code = int(status.group(1))
+        observed["status_code"] = code
+        observed["failure"] = "http_status"
+        if code == 404:
+            observed["failure"] = "wrong_path"
+        elif code in (401, 403):
+            observed["failure"] = "auth_required"
+        return observed
+    lowered = detail.lower()
+    observed["failure"] = next(
+        (kind for kind, patterns in _FAILURE_PATTERNS if any(p in lowered for p in patterns)),
+        "command_failed" if detail else "other",
+    )
+    return observed
+
+
+def _classify(entry: dict[str, object], container: dict[str, object] | None) -> None:
+    observed = _dict(entry.pop("observed", None))
+    config = _dict(entry.get("config"))
+    failure = str(observed.get("failure") or "other")
+    entry.update(cause=failure, status_code=observed.get("status_code"))
+    issues: list[str] = []
+    if failure in _MISCONFIGURED:
+        issues.append(failure)
+    declared = _resolve_port(container or {}, config.get("port"))
+    if isinstance(config.get("port"), str) and declared is None:
+        issues.append("undeclared_named_port")
+    elif (
+        failure == "connection_refused"
+        and declared is not None
+        and _container_ports(container)
+        and declared not in _container_ports(container)
+    ):
+        issues.append("wrong_port")
+    if (
+        entry["probe"] == "liveness"
+        and failure in ("connection_refused", "timeout")
+        and container is not None
+        and not isinstance(container.get("startup_probe"), dict)
+        and _grace_seconds(config) < _MIN_LIVENESS_GRACE_SECONDS
+        and int(str(entry["container_restarts"])) > 0
+    ):
+        issues.append("liveness_before_startup")
+    if failure == "timeout" and int(str(config.get("timeout_seconds") or 1)) <= 1:
+        # Not a misconfiguration on its own: slow answers are usually the app's.
+        entry["tight_timeout"] = True
+    entry["issues"] = issues
+    entry["verdict"] = "misconfigured" if issues else "unhealthy"
+
+
+def _finding(entry: dict[str, object]) -> str:
+    config = _dict(entry.get("config"))
+    subject = f"{entry['probe']} probe of container {entry['container'] or '?'}"
+    target = _describe_probe(config)
+    failures = f"failed {entry['failures']} times"
+    restarts = (
+        f", restarting the container {entry['restarts_by_probe']} times"
+        if entry.get("restarts_by_probe")
+        else ""
+    )
+    head = f"{subject} ({target}) {failures}{restarts}"
+    issues = [str(issue) for issue in entry.get("issues") or []]
+    if issues:
+        return f"{head}: misconfigured probe, " + "; ".join(
+            _issue_text(issue, config, entry) for issue in issues
+        )
+    reason = _UNHEALTHY_REASONS.get(str(entry.get("cause")), "the probe fails")
+    if entry.get("cause") == "http_status":
+        reason = f"the app answers HTTP {entry.get('status_code')}"
+    elif entry.get("cause") == "timeout":
+        reason = f"the app does not answer within {config.get('timeout_seconds')}s"
+        if entry.get("tight_timeout"):
+            reason += " (the 1s default; raise timeoutSeconds if the endpoint is slow by design)"
+    message = _first_message(entry)
+    return f"{head}: app unhealthy, {reason}" + (f" ({message})" if message else "")
+
+
+def _issue_text(issue: str, config: dict[str, object], entry: dict[str, object]) -> str:
+    if issue == "wrong_path":
+        return f"path {config.get('path')} returns 404"
+    if issue == "auth_required":
+        return f"path {config.get('path')} requires authentication ({entry.get('status_code')})"
+    if issue == "exec_not_found":
+        return "the exec command is not present in the image"
+    if issue == "scheme_mismatch":
+        return f"scheme {config.get('scheme')} does not match the endpoint"
+    if issue == "undeclared_named_port":
+        return f"named port {config.get('port')} is not declared by the container"
+    if issue == "wrong_port":
+        return f"port {config.get('port')} is refused and not one of the container's ports"
+    if issue == "liveness_before_startup":
+        return (
+            f"it gives up after {_grace_seconds(config)}s without a startup probe; add a "
+            "startupProbe or raise initialDelaySeconds/failureThreshold"
+        )
+    return issue
+
+
+def _describe_probe(config: dict[str, object]) -> str:
+    if not config:
+        return "spec not found"
+    kind = config.get("type")
+    if kind == "httpGet":
+        target = f"{config.get('scheme')} {config.get('path')} on port {config.get('port')}"
+    elif kind == "exec":
+        target = "exec " + " ".join(str(item) for item in config.get("command") or [])
+    else:
+        target = f"{kind} on port {config.get('port')}"
+    return (
+        f"{target}, period {config.get('period_seconds')}s, timeout "
+        f"{config.get('timeout_seconds')}s, failureThreshold {config.get('failure_threshold')}"
+    )
+
+
+def _first_message(entry: dict[str, object]) -> str | None:
+    messages = entry.get("messages")
+    return str(messages[0]) if isinstance(messages, list) and messages else None
+
+
+def _grace_seconds(config: dict[str, object]) -> int:
+    return int(str(config.get("initial_delay_seconds") or 0)) + int(
+        str(config.get("period_seconds") or 10)
+    ) * int(str(config.get("failure_threshold") or 3))
+
+
+def _resolve_port(container: dict[str, object], port: object) -> int | None:
+    if isinstance(port, int):
+        return port
+    if isinstance(port, str) and port.isdigit():
+        return int(port)
+    for item in _dicts(container.get("ports")):
+        if port is not None and item.get("name") == port:
+            value = item.get("container_port")
+            return value if isinstance(value, int) else None
+    return None
+
+
+def _container_ports(container: dict[str, object] | None) -> set[int]:
+    return {
+        int(str(item["container_port"]))
+        for item in _dicts((container or {}).get("ports"))
+        if item.get("container_port") is not None
+    }
+
+
+def _container(containers: list[dict[str, object]], name: str | None) -> dict[str, object] | None:
+    return next((item for item in containers if item.get("name") == name), None)
+
+
+def _restart_counts(k8s_context: K8sContext) -> dict[str, int]:
+    if k8s_context.pod_status is None:
+        return {}
+    return {
+        str(status.get("name")): int(str(status.get("restart_count") or 0))
+        for status in k8s_context.pod_status.container_statuses
+    }
+
+
+def _failures(entry: dict[str, object]) -> int:
+    failures = entry.get("failures")
+    return failures if isinstance(failures, int) else 0
+
+
+def _dicts(value: object) -> list[dict[str, object]]:
+    return [item for item in value if isinstance(item, dict)] if isinstance(value, list) else []
+
+
+def _dict(value: object) -> dict[str, object]:
+    return value if isinstance(value, dict) else {}
@@ -0,0 +1,201 @@
+"""ResourceQuota and LimitRange rejections of new pods, returned as ``quota_analysis``.
+
+Admission rejects pods before they exist, so the symptom is a workload that
+never reaches its replica count and a ``FailedCreate`` event on its
+ReplicaSet, StatefulSet or Job (``pods "api-7d9f-x" is forbidden: exceeded
+quota: compute, requested: limits.memory=2Gi, used: limits.memory=9Gi,
+limited: limits.memory=10Gi``). ``quota_alert`` spots these rejections in the
+collected events, as well as kube-prometheus' ``KubeQuota*`` alerts;
+``KubernetesClient.get_namespace_quotas`` then reads the namespace's quotas
+and LimitRanges. Each rejection is reported with the quota resource and its
+requested, used and hard amounts, a quota that requires requests or limits
+the pod does not set, or the LimitRange bound the pod breaks.
+"""
+
+from __future__ import annotations
+
+import re
+
+from app.models.k8s import K8sContext
+
+_EXCEEDED_RE = re.compile(
+    r"exceeded quota: ([^,]+), requested: (.+?), used: (.+?), limited: (.+?)$", re.I
+)
+_MUST_SPECIFY_RE = re.compile(r"failed quota: ([^:]+): must specify (.+)$", re.I)
+_LIMIT_RANGE_RE = re.compile(
+    r"(maximum|minimum) (\S+) usage per (Container|Pod|PersistentVolumeClaim) is (\S+), "
+    r"but (limit|request) is (\S+?)(?:[,\]]|$)",
+    re.I,
+)
+_RATIO_RE = re.compile(
+    r"(\S+) max limit to request ratio per (Container|Pod) is (\S+), but provided ratio is "
+    r"(\S+?)(?:[,\]]|$)",
+    re.I,
+)
+_QUOTA_ALERTS = frozenset({"KubeQuotaExceeded", "KubeQuotaFullyUsed", "KubeQuotaAlmostFull"})
+# Quota resources reported as saturated even when no rejection names them.
+_SATURATED_RATIO = 0.9
+_MAX_FAILURES = 10
+
+
+def quota_alert(labels: dict[str, str], k8s_context: K8sContext) -> str | None:
+    """Why quotas should be read (``alert`` or ``events``), else ``None``."""
+    if labels.get("alertname") in _QUOTA_ALERTS or labels.get("resourcequota"):
+        return "alert"
+    if quota_failures(k8s_context):
+        return "events"
+    return None
+
+
+def quota_failures(k8s_context: K8sContext) -> list[dict[str, object]]:
+    """Pod creations rejected by a ResourceQuota or LimitRange, newest events first."""
+    failures: list[dict[str, object]] = []
+    seen: set[str] = set()
+    events = sorted(
+        (event for event in k8s_context.events if event.reason == "FailedCreate"),
+        key=lambda event: event.last_timestamp or event.first_timestamp or "",
+        reverse=True,
+    )
+    for event in events:
+        message = (event.message or "").strip()
+        if "forbidden" not in message or message in seen:
+            continue
+        seen.add(message)
+        involved = event.involved_object or {}
+        owner = f"{involved.get('kind') or 'workload'} {involved.get('name') or '?'}"
+        failures.extend(
+            {**failure, "owner": owner, "count": event.count or 1, "message": message}
+            for failure in _parse_rejection(message)
+        )
+    return failures[:_MAX_FAILURES]
+
+
+def build_quota_analysis(k8s_context: K8sContext) -> dict[str, object] | None:
+    data = k8s_context.resource_quotas
+    failures = quota_failures(k8s_context)
+    if not data and not failures:
+        return None
+    data = data or {}
+    quotas = _dicts(data.get("quotas"))
+    saturated = [item for item in quotas if _ratio(item) >= _SATURATED_RATIO]
+    findings = [_finding(failure) for failure in failures]
+    rejected = {(item.get("quota"), item.get("resource")) for item in failures}
+    findings.extend(
+        f"ResourceQuota {item.get('quota')} {item.get('resource')} at "
+        f"{_ratio(item):.0%} ({item.get('used')}/{item.get('hard')})"
+        for item in saturated
+        if (item.get("quota"), item.get("resource")) not in rejected
+    )
+    return {
+        "namespace": data.get("namespace") or k8s_context.namespace,
+        "trigger": data.get("trigger"),
+        "failures": failures,
+        "saturated": saturated,
+        "limit_ranges": _dicts(data.get("limit_ranges")),
+        "findings": findings,
+    }
+
+
+def _parse_rejection(message: str) -> list[dict[str, object]]:
+    exceeded = _EXCEEDED_RE.search(message)
+    if exceeded:
+        requested = _amounts(exceeded.group(2))
+        used = _amounts(exceeded.group(3))
+        limited = _amounts(exceeded.group(4))
+        return [
+            {
+                "kind": "quota_exceeded",
+                "quota": exceeded.group(1).strip(),
+                "resource": resource,
+                "requested": requested.get(resource),
+                "used": used.get(resource),
+                "hard": hard,
+            }
+            for resource, hard in limited.items()
+        ]
+    missing = _MUST_SPECIFY_RE.search(message)
+    if missing:
+        # "limits.cpu for: app,sidecar; limits.memory for: app"
+        return [
+            {
+                "kind": "quota_requires_resources",
+                "quota": missing.group(1).strip(),
+                "resource": resource.strip(),
+                "containers": [name.strip() for name in containers.split(",") if name.strip()],
+            }
+            for resource, _, containers in (
+                part.partition(" for: ") for part in missing.group(2).split(";")
+            )
+            if resource.strip()
+        ]
+    failures: list[dict[str, object]] = [
+        {
+            "kind": "limit_range_exceeded",
+            "bound": bound.lower(),
+            "resource": resource,
+            "scope": scope,
+            "allowed": allowed,
+            "field": field.lower(),
+            "value": value,
+        }
+        for bound, resource, scope, allowed, field, value in _LIMIT_RANGE_RE.findall(message)
+    ]
+    failures.extend(
+        {
+            "kind": "limit_range_ratio_exceeded",
+            "resource": resource,
+            "scope": scope,
+            "allowed": allowed,
+            "value": value,
+        }
+        for resource, scope, allowed, value in _RATIO_RE.findall(message)
+    )
+    return failures
+
+
+def _amounts(text: str) -> dict[str, str]:
+    amounts: dict[str, str] = {}
+    for part in text.split(","):
+        name, _, value = part.strip().partition("=")
+        if name and value:
+            amounts[name] = value
+    return amounts
+
+
+def _finding(failure: dict[str, object]) -> str:
+    owner = failure["owner"]
+    kind = failure["kind"]
+    if kind == "quota_exceeded":
+        return (
+            f"{owner} cannot create pods: ResourceQuota {failure['quota']} "
+            f"{failure['resource']} is exhausted (pod requests {failure.get('requested')}, "
+            f"{failure.get('used')} of {failure.get('hard')} already used)"
+        )
+    if kind == "quota_requires_resources":
+        containers = failure.get("containers")
+        names = ", ".join(str(name) for name in containers) if isinstance(containers, list) else ""
+        return (
+            f"{owner} cannot create pods: ResourceQuota {failure['quota']} requires "
+            f"{failure['resource']} but containers {names or '?'} do not set it; set it "
+            "or add a LimitRange default"
+        )
+    if kind == "limit_range_ratio_exceeded":
+        return (
+            f"{owner} cannot create pods: LimitRange allows a {failure['resource']} "
+            f"limit/request ratio of {failure['allowed']} per {failure['scope']}, the pod "
+            f"has {failure['value']}"
+        )
+    return (
+        f"{owner} cannot create pods: LimitRange {failure['bound']} {failure['resource']} per "
+        f"{failure['scope']} is {failure['allowed']}, but the {failure['field']} is "
+        f"{failure['value']}"
+    )
+
+
+def _ratio(item: dict[str, object]) -> float:
+    ratio = item.get("ratio")
+    return float(ratio) if isinstance(ratio, float | int) else 0.0
+
+
+def _dicts(value: object) -> list[dict[str, object]]:
+    return [item for item in value if isinstance(item, dict)] if isinstance(value, list) else []
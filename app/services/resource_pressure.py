@@ -0,0 +1,179 @@
+"""CPU/memory pressure of the alerting pod and its node, returned as ``resource_pressure``.
+
+Current usage comes from metrics.k8s.io (metrics-server): each container is
+compared with its requests and limits from the pod spec, and the node with its
+allocatable CPU and memory. The verdict is ``critical`` when a container is
+near a limit (CPU throttling, imminent OOM kill) or the node is nearly full,
+``elevated`` when usage exceeds a request or approaches a limit, else ``ok``.
+Usage is a sample over the metrics window, not a peak.
+"""
+
+from __future__ import annotations
+
+import math
+
+from app.clients.k8s import parse_quantity
+from app.models.k8s import K8sContext
+
+_CRITICAL_PERCENT = 90.0
+_ELEVATED_PERCENT = 75.0
+_MI = 1024**2
+_GI = 1024**3
+_VERDICT_ORDER = ("ok", "elevated", "critical")
+
+
+def build_resource_pressure(k8s_context: K8sContext) -> dict[str, object] | None:
+    pod_usage = _container_usage(k8s_context.pod_metrics)
+    node_metrics = k8s_context.node_metrics
+    if not pod_usage and not node_metrics:
+        return None
+    specs = _container_specs(k8s_context.pod_spec)
+    containers: list[dict[str, object]] = []
+    findings: list[str] = []
+    verdict = "ok"
+    for name, usage in pod_usage.items():
+        resources = specs.get(name, {})
+        entry: dict[str, object] = {"name": name}
+        for resource in ("cpu", "memory"):
+            used = usage.get(resource)
+            request = _quantity(resources.get("requests"), resource)
+            limit = _quantity(resources.get("limits"), resource)
+            entry[f"{resource}_usage"] = _format(used, resource)
+            entry[f"{resource}_request"] = _format(request, resource)
+            entry[f"{resource}_limit"] = _format(limit, resource)
+            entry[f"{resource}_percent_of_request"] = _percent(used, request)
+            entry[f"{resource}_percent_of_limit"] = _percent(used, limit)
+            level, finding = _container_finding(name, resource, entry)
+            verdict = _worse(verdict, level)
+            if finding:
+                findings.append(finding)
+        containers.append(entry)
+    node = _node_entry(node_metrics) if node_metrics else None
+    if node is not None:
+        for resource in ("cpu", "memory"):
+            percent = node[f"{resource}_percent_of_allocatable"]
+            if not isinstance(percent, float) or percent < _ELEVATED_PERCENT:
+                continue
+            level = "critical" if percent >= _CRITICAL_PERCENT else "elevated"
+            verdict = _worse(verdict, level)
+            findings.append(
+                f"node {node['name']} uses {percent}% of its allocatable {resource} "
+                f"({node[f'{resource}_usage']} of {node[f'{resource}_allocatable']})"
+                + (
+                    "; pods on it compete for CPU and may be evicted under memory pressure"
+                    if level == "critical"
+                    else ""
+                )
+            )
+    return {
+        "pod": k8s_context.pod_name,
+        "namespace": k8s_context.namespace,
+        "verdict": verdict,
+        "window": _window(k8s_context.pod_metrics or node_metrics),
+        "containers": containers,
+        "node": node,
+        "findings": findings,
+    }
+
+
+def _container_finding(
+    name: str, resource: str, entry: dict[str, object]
+) -> tuple[str, str | None]:
+    used = entry[f"{resource}_usage"]
+    of_limit = entry[f"{resource}_percent_of_limit"]
+    of_request = entry[f"{resource}_percent_of_request"]
+    if isinstance(of_limit, float) and of_limit >= _CRITICAL_PERCENT:
+        consequence = (
+            "it is throttled by the CFS quota, which shows up as latency"
+            if resource == "cpu"
+            else "the next allocation spike gets it OOMKilled"
+        )
+        return "critical", (
+            f"container {name} uses {of_limit}% of its {resource} limit "
+            f"({used} of {entry[f'{resource}_limit']}); {consequence}"
+        )
+    if isinstance(of_limit, float) and of_limit >= _ELEVATED_PERCENT:
+        return "elevated", (
+            f"container {name} uses {of_limit}% of its {resource} limit "
+            f"({used} of {entry[f'{resource}_limit']})"
+        )
+    if isinstance(of_request, float) and of_request > 100.0:
+        consequence = (
+            "it depends on spare CPU on the node"
+            if resource == "cpu"
+            else "it is among the first pods evicted under node memory pressure"
+        )
+        return "elevated", (
+            f"container {name} uses {of_request}% of its {resource} request "
+            f"({used} of {entry[f'{resource}_request']}); {consequence}"
+        )
+    return "ok", None
+
+
+def _node_entry(node_metrics: dict[str, object]) -> dict[str, object]:
+    usage = node_metrics.get("usage")
+    allocatable = node_metrics.get("allocatable")
+    entry: dict[str, object] = {"name": node_metrics.get("name")}
+    for resource in ("cpu", "memory"):
+        used = _quantity(usage, resource)
+        available = _quantity(allocatable, resource)
+        entry[f"{resource}_usage"] = _format(used, resource)
+        entry[f"{resource}_allocatable"] = _format(available, resource)
+        entry[f"{resource}_percent_of_allocatable"] = _percent(used, available)
+    return entry
+
+
+def _container_usage(pod_metrics: dict[str, object] | None) -> dict[str, dict[str, float]]:
+    usage: dict[str, dict[str, float]] = {}
+    items = pod_metrics.get("containers") if pod_metrics else None
+    for item in items if isinstance(items, list) else []:
+        if not isinstance(item, dict) or not isinstance(item.get("usage"), dict):
+            continue
+        values = {
+            resource: value
+            for resource in ("cpu", "memory")
+            if (value := _quantity(item["usage"], resource)) is not None
+        }
+        if values:
+            usage[str(item.get("name"))] = values
+    return usage
+
+
+def _container_specs(pod_spec: dict[str, object] | None) -> dict[str, dict[str, object]]:
+    specs: dict[str, dict[str, object]] = {}
+    items = pod_spec.get("containers") if pod_spec else None
+    for item in items if isinstance(items, list) else []:
+        if isinstance(item, dict) and isinstance(item.get("resources"), dict):
+            specs[str(item.get("name"))] = item["resources"]
+    return specs
+
+
+def _quantity(resources: object, resource: str) -> float | None:
+    if not isinstance(resources, dict) or resources.get(resource) is None:
+        return None
+    return parse_quantity(str(resources[resource]))
+
+
+def _percent(used: float | None, total: float | None) -> float | None:
+    if used is None or not total:
+        return None
+    return round(used * 100 / total, 1)
+
+
+def _format(value: float | None, resource: str) -> str | None:
+    if value is None:
+        return None
+    if resource == "cpu":
+        return f"{round(value * 1000)}m"
+    if value >= _GI:
+        return f"{value / _GI:.1f}Gi"
+    return f"{math.ceil(value / _MI)}Mi"
+
+
+def _window(metrics: dict[str, object] | None) -> str | None:
+    window = metrics.get("window") if metrics else None
+    return str(window) if window is not None else None
+
+
+def _worse(current: str, level: str) -> str:
+    return max(current, level, key=_VERDICT_ORDER.index)
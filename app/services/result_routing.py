@@ -0,0 +1,45 @@
+from __future__ import annotations
+
+import logging
+
+from app.clients.report_sink import ReportSink
+
+logger = logging.getLogger(__name__)
+
+ROUTE_CALLBACK = "callback"
+ROUTE_REVIEW = "review"
+_QUALITY_RANKS = {"low": 0, "medium": 1, "high": 2}
+
+
+class ResultRouter:
+    """Send analyses below a quality threshold to a review sink instead of the thread.
+
+    ``analysis_quality`` is the confidence signal; degraded results are always
+    ``low``. Reviewed results carry their full context and artifacts so a
+    reviewer sees the raw evidence. When the review sink cannot be reached the
+    result falls back to the callback rather than being lost.
+    """
+
+    def __init__(self, sink: ReportSink, *, min_quality: str = "medium") -> None:
+        self._sink = sink
+        self._min_quality = min_quality.lower()
+        if self._min_quality not in _QUALITY_RANKS:
+            raise ValueError(f"unknown minimum analysis quality {min_quality!r}")
+        self._min_rank = _QUALITY_RANKS[self._min_quality]
+
+    def route(self, result: dict[str, object]) -> str:
+        quality = str(result.get("analysis_quality") or "low")
+        if _QUALITY_RANKS.get(quality, 0) >= self._min_rank:
+            return ROUTE_CALLBACK
+        report = {
+            "reason": f"analysis_quality {quality} is below {self._min_quality}",
+            "result": {**result, "routing": ROUTE_REVIEW},
+        }
+        delivery = self._sink.send("analysis_review", report)
+        if not delivery.get("delivered"):
+            logger.warning(
+                "Review sink unavailable, delivering low-confidence result to callback: %s",
+                delivery.get("reason"),
+            )
+            return ROUTE_CALLBACK
+        return ROUTE_REVIEW
@@ -0,0 +1,300 @@
+from __future__ import annotations
+
+import asyncio
+import logging
+from datetime import datetime
+from typing import Protocol
+
+from app.core.k8s_clusters import clusters_configured
+
+logger = logging.getLogger(__name__)
+
+
+class _SessionPurger(Protocol):
+    def purge_inactive_sessions(self, older_than_days: int) -> int: ...
+
+    def delete_sessions(
+        self,
+        *,
+        namespace: str | None = None,
+        session_keys: list[str] | None = None,
+        session_prefix: str | None = None,
+        since: datetime | None = None,
+        until: datetime | None = None,
+    ) -> int: ...
+
+
+class _SummaryPurger(Protocol):
+    def purge_older_than(self, older_than_days: int) -> int: ...
+
+    def delete_summaries(
+        self,
+        *,
+        namespace: str | None = None,
+        session_prefix: str | None = None,
+        since: datetime | None = None,
+        until: datetime | None = None,
+    ) -> list[str]: ...
+
+
+class _ResultPurger(Protocol):
+    def purge_older_than(self, older_than_days: int) -> int: ...
+
+    def delete_results(
+        self,
+        *,
+        namespace: str | None = None,
+        session_prefix: str | None = None,
+        since: datetime | None = None,
+        until: datetime | None = None,
+    ) -> int: ...
+
+
+class _ArchivePurger(Protocol):
+    def purge_older_than(self, older_than_days: int) -> int: ...
+
+    def delete_analyses(
+        self,
+        *,
+        namespace: str | None = None,
+        incident_id: str | None = None,
+        since: datetime | None = None,
+        until: datetime | None = None,
+    ) -> int: ...
+
+
+class _ArtifactPurger(Protocol):
+    def delete_analyses(
+        self,
+        *,
+        namespace: str | None = None,
+        incident_id: str | None = None,
+        since: datetime | None = None,
+        until: datetime | None = None,
+    ) -> int: ...
+
+
+class _EventPurger(Protocol):
+    def purge_older_than(self, older_than_days: int) -> int: ...
+
+    def delete_events(
+        self,
+        *,
+        namespace: str | None = None,
+        since: datetime | None = None,
+        until: datetime | None = None,
+    ) -> int: ...
+
+
+class RetentionService:
+    """Apply retention windows to stored session transcripts and summaries.
+
+    Session transcripts hold raw evidence (logs, events, tool output) and are
+    usually kept for a short window; summaries are small and kept longer.
+    Shadow-mode results hold full analyses and follow the session window;
+    the analysis history is a long-term record and follows the summary window.
+    Exported archive objects and archived cluster events have their own windows.
+    A retention of 0 days disables purging for that data set.
+    """
+
+    def __init__(
+        self,
+        session_repository: _SessionPurger | None,
+        summary_store: _SummaryPurger | None,
+        *,
+        session_retention_days: int = 0,
+        summary_retention_days: int = 0,
+        shadow_store: _ResultPurger | None = None,
+        analysis_store: _ResultPurger | None = None,
+        archive: _ArchivePurger | None = None,
+        archive_retention_days: int = 0,
+        event_archive: _EventPurger | None = None,
+        event_archive_retention_days: int = 0,
+        artifact_store: _ArtifactPurger | None = None,
+    ) -> None:
+        self._session_repository = session_repository
+        self._summary_store = summary_store
+        self._shadow_store = shadow_store
+        self._analysis_store = analysis_store
+        self._archive = archive
+        self._archive_retention_days = archive_retention_days
+        self._event_archive = event_archive
+        self._event_archive_retention_days = event_archive_retention_days
+        self._artifact_store = artifact_store
+        self._session_retention_days = session_retention_days
+        self._summary_retention_days = summary_retention_days
+
+    @property
+    def enabled(self) -> bool:
+        return (
+            (self._session_repository is not None and self._session_retention_days > 0)
+            or (self._summary_store is not None and self._summary_retention_days > 0)
+            or (self._archive is not None and self._archive_retention_days > 0)
+            or (self._event_archive is not None and self._event_archive_retention_days > 0)
+        )
+
+    def purge(
+        self,
+        session_retention_days: int | None = None,
+        summary_retention_days: int | None = None,
+    ) -> dict[str, int | None]:
+        """Delete data older than the retention windows.
+
+        Explicit arguments override the configured windows. Counts are ``None``
+        when the data set is not configured or its retention is disabled.
+        """
+        session_days = (
+            self._session_retention_days
+            if session_retention_days is None
+            else session_retention_days
+        )
+        summary_days = (
+            self._summary_retention_days
+            if summary_retention_days is None
+            else summary_retention_days
+        )
+
+        sessions_deleted: int | None = None
+        if self._session_repository is not None and session_days > 0:
+            sessions_deleted = self._session_repository.purge_inactive_sessions(session_days)
+
+        summaries_deleted: int | None = None
+        if self._summary_store is not None and summary_days > 0:
+            summaries_deleted = self._summary_store.purge_older_than(summary_days)
+
+        logger.info(
+            "retention_purge sessions_deleted=%s (days=%d) summaries_deleted=%s (days=%d)",
+            sessions_deleted,
+            session_days,
+            summaries_deleted,
+            summary_days,
+        )
+        result: dict[str, int | None] = {
+            "sessions_deleted": sessions_deleted,
+            "summaries_deleted": summaries_deleted,
+        }
+        if self._shadow_store is not None:
+            result["shadow_results_deleted"] = (
+                self._shadow_store.purge_older_than(session_days) if session_days > 0 else None
+            )
+        if self._analysis_store is not None:
+            result["analysis_results_deleted"] = (
+                self._analysis_store.purge_older_than(summary_days) if summary_days > 0 else None
+            )
+        if self._archive is not None:
+            result["archives_deleted"] = (
+                self._archive.purge_older_than(self._archive_retention_days)
+                if self._archive_retention_days > 0
+                else None
+            )
+        if self._event_archive is not None:
+            result["archived_events_deleted"] = (
+                self._event_archive.purge_older_than(self._event_archive_retention_days)
+                if self._event_archive_retention_days > 0
+                else None
+            )
+        return result
+
+    def delete_analyses(
+        self,
+        *,
+        namespace: str | None = None,
+        incident_id: str | None = None,
+        since: datetime | None = None,
+        until: datetime | None = None,
+    ) -> dict[str, int | None]:
+        """Hard-delete the stored data of the analyses matching the filters.
+
+        Every store applies all filters: summaries, session transcripts,
+        shadow results, stored history, exported archive objects and uploaded
+        artifacts. Archived events are cluster records without an incident, so
+        they are deleted for namespace and time filters only and kept when
+        *incident_id* is set. Namespaces are ambiguous across the clusters of
+        ``K8S_CLUSTERS_JSON``, so namespace filters are rejected there.
+        """
+        if not (namespace or incident_id or since or until):
+            raise ValueError("at least one of namespace, incident_id, since or until is required")
+        if namespace and clusters_configured():
+            raise ValueError(
+                "namespace filters are not supported with K8S_CLUSTERS_JSON: analyses of "
+                "several clusters share namespace names; filter by incident_id or time range"
+            )
+        session_prefix = f"{incident_id}:" if incident_id else None
+
+        summaries_deleted: int | None = None
+        session_keys: list[str] = []
+        if self._summary_store is not None:
+            deleted_keys = self._summary_store.delete_summaries(
+                namespace=namespace,
+                session_prefix=session_prefix,
+                since=since,
+                until=until,
+            )
+            summaries_deleted = len(deleted_keys)
+            session_keys = sorted(set(deleted_keys))
+
+        sessions_deleted: int | None = None
+        if self._session_repository is not None:
+            if namespace:
+                # Summary keys only add the untagged sessions of older releases.
+                sessions_deleted = self._session_repository.delete_sessions(
+                    namespace=namespace,
+                    session_keys=session_keys,
+                    session_prefix=session_prefix,
+                    since=since,
+                    until=until,
+                )
+            else:
+                sessions_deleted = self._session_repository.delete_sessions(
+                    session_prefix=session_prefix, since=since, until=until
+                )
+
+        logger.info(
+            "analysis_deletion namespace=%s incident_id=%s since=%s until=%s "
+            "sessions_deleted=%s summaries_deleted=%s",
+            namespace,
+            incident_id,
+            since,
+            until,
+            sessions_deleted,
+            summaries_deleted,
+        )
+        result: dict[str, int | None] = {
+            "sessions_deleted": sessions_deleted,
+            "summaries_deleted": summaries_deleted,
+        }
+        if self._shadow_store is not None:
+            result["shadow_results_deleted"] = self._shadow_store.delete_results(
+                namespace=namespace, session_prefix=session_prefix, since=since, until=until
+            )
+        if self._analysis_store is not None:
+            result["analysis_results_deleted"] = self._analysis_store.delete_results(
+                namespace=namespace, session_prefix=session_prefix, since=since, until=until
+            )
+        if self._archive is not None:
+            result["archives_deleted"] = self._archive.delete_analyses(
+                namespace=namespace, incident_id=incident_id, since=since, until=until
+            )
+        if self._artifact_store is not None:
+            result["artifacts_deleted"] = self._artifact_store.delete_analyses(
+                namespace=namespace, incident_id=incident_id, since=since, until=until
+            )
+        if self._event_archive is not None:
+            result["archived_events_deleted"] = (
+                None
+                if incident_id
+                else self._event_archive.delete_events(
+                    namespace=namespace, since=since, until=until
+                )
+            )
+        return result
+
+
+async def run_retention_janitor(service: RetentionService, interval_seconds: int) -> None:
+    """Purge expired data every *interval_seconds* until cancelled."""
+    while True:
+        try:
+            await asyncio.to_thread(service.purge)
+        except Exception as exc:  # noqa: BLE001
+            logger.warning("Retention purge failed: %s", exc)
+        await asyncio.sleep(interval_seconds)
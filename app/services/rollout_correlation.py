@@ -0,0 +1,53 @@
+"""Deployment rollouts shortly before an alert started.
+
+Works on ``KubernetesClient.get_deployment_revisions`` output: a revision
+counts as a rollout at its ReplicaSet's creation time. A rollback to an old
+revision reuses that ReplicaSet, so it shows up with its original time and
+is not flagged.
+"""
+
+from __future__ import annotations
+
+from datetime import datetime, timedelta, timezone
+
+
+def find_recent_rollouts(
+    deployment: str,
+    revisions: list[dict[str, object]],
+    started_at: datetime,
+    window_minutes: int,
+) -> list[dict[str, object]]:
+    """Revisions created within ``window_minutes`` before ``started_at``, newest first."""
+    if started_at.tzinfo is None:
+        started_at = started_at.replace(tzinfo=timezone.utc)
+    window_start = started_at - timedelta(minutes=window_minutes)
+    rollouts: list[dict[str, object]] = []
+    for revision in revisions:
+        created = _parse_time(revision.get("created"))
+        # Allow a minute of clock skew between Alertmanager and the API server.
+        if created is None or not window_start <= created <= started_at + timedelta(minutes=1):
+            continue
+        containers = revision.get("containers")
+        if not isinstance(containers, list):
+            containers = []
+        rollouts.append(
+            {
+                "deployment": deployment,
+                "revision": revision.get("revision"),
+                "replica_set": revision.get("replica_set"),
+                "created": revision.get("created"),
+                "minutes_before_alert": round((started_at - created).total_seconds() / 60, 1),
+                "images": [item.get("image") for item in containers if isinstance(item, dict)],
+            }
+        )
+    return rollouts
+
+
+def _parse_time(value: object) -> datetime | None:
+    if not isinstance(value, str):
+        return None
+    try:
+        parsed = datetime.fromisoformat(value.replace("Z", "+00:00"))
+    except ValueError:
+        return None
+    return parsed if parsed.tzinfo else parsed.replace(tzinfo=timezone.utc)
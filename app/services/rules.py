@@ -0,0 +1,729 @@
+"""Rule-based analyzers used when the LLM analysis engine is unavailable.
+
+Each rule inspects the collected ``K8sContext`` only (no extra API calls) and
+returns findings with evidence, so degraded responses remain actionable.
+"""
+
+from __future__ import annotations
+
+from collections.abc import Callable
+from dataclasses import asdict, dataclass, replace
+from typing import Any, cast
+
+from app.core.evidence_budget import strip_log_timestamp
+from app.core.overrides import current_overrides
+from app.models.k8s import K8sContext
+from app.services.crash_knowledge import classify_crash
+from app.services.crash_loop import build_crash_loop_analysis
+from app.services.dns_analysis import build_dns_analysis
+from app.services.eviction_analysis import build_eviction_analysis
+from app.services.hpa_analysis import build_hpa_analysis
+from app.services.image_pull import build_image_pull_analysis
+from app.services.job_analysis import build_job_failure_analysis
+from app.services.namespace_snapshot import build_namespace_snapshot
+from app.services.network_policy import build_network_policy_analysis
+from app.services.node_health import summarize_node_health
+from app.services.oom_analysis import build_oom_analysis
+from app.services.probe_analysis import build_probe_analysis
+from app.services.quota_analysis import build_quota_analysis
+from app.services.resource_pressure import build_resource_pressure
+from app.services.scheduling_analysis import build_scheduling_analysis
+from app.services.service_endpoints import build_endpoint_readiness
+from app.services.storage_analysis import build_storage_analysis
+
+_SEVERITY_ORDER = {"critical": 0, "warning": 1, "info": 2}
+_REGISTRY_NETWORK_FIX = (
+    "Check DNS, egress NetworkPolicies, firewalls and proxies between the nodes and the registry."
+)
+_IMAGE_PULL_RECOMMENDATIONS = {
+    "auth_failed": (
+        "Check that the imagePullSecrets hold valid credentials for the registry and grant "
+        "pull access to the repository."
+    ),
+    "missing_pull_secret": "Create the missing imagePullSecrets in the pod's namespace.",
+    "tag_not_found": "Push the missing tag or digest, or fix it in the workload's image.",
+    "repository_not_found": "Fix the repository name in the workload's image.",
+    "registry_timeout": _REGISTRY_NETWORK_FIX,
+    "registry_unreachable": _REGISTRY_NETWORK_FIX,
+    "tls_error": "Trust the registry's CA on the nodes or fix the registry certificate.",
+    "rate_limited": "Authenticate pulls or use a registry mirror to stay under the rate limit.",
+    "invalid_reference": "Fix the malformed image reference in the workload spec.",
+    "never_pull": "Preload the image on the node or change imagePullPolicy.",
+}
+_SCHEDULING_RECOMMENDATIONS = {
+    "insufficient_resource": (
+        "Lower the pod's requests, free capacity on the nodes or let the cluster autoscaler "
+        "add nodes."
+    ),
+    "too_many_pods": "Add nodes or raise the kubelet's maxPods.",
+    "untolerated_taint": "Add a toleration for the taint or schedule onto untainted nodes.",
+    "node_unschedulable": "Uncordon the nodes once their maintenance is done.",
+    "node_selector_mismatch": (
+        "Fix the nodeSelector/required node affinity or label nodes that should run the pod."
+    ),
+    "volume_node_affinity_conflict": (
+        "Add capacity in the zone of the bound PersistentVolume or recreate the volume where "
+        "the nodes are."
+    ),
+    "volume_zone_conflict": "Add capacity in the zone of the bound PersistentVolume.",
+    "unbound_pvc": "Bind the PersistentVolumeClaim: check its StorageClass and provisioner.",
+    "pvc_not_found": "Create the missing PersistentVolumeClaim or fix the claim name.",
+    "pod_anti_affinity": "Relax the pod anti-affinity rules or add nodes.",
+    "existing_pod_anti_affinity": "Relax the anti-affinity of the pods already running.",
+    "topology_spread": "Relax maxSkew/whenUnsatisfiable or add nodes in the missing domains.",
+}
+_EVICTION_TITLES = {
+    "preemption": "Pod was preempted by a higher-priority pod",
+    "api_eviction": "Pod was evicted through the Eviction API",
+    "taint_manager": "Pod was deleted by the taint manager",
+    "node_shutdown": "Pod was terminated by a node shutdown",
+}
+_EVICTION_RECOMMENDATIONS = {
+    "node_pressure": (
+        "Set requests close to actual usage so the pod is not first in line, and check the "
+        "node's memory/disk pressure."
+    ),
+    "ephemeral_storage": "Raise the ephemeral-storage or emptyDir limits, or write less to disk.",
+    "preemption": (
+        "Give the workload a PriorityClass, or add capacity so higher-priority pods fit "
+        "without preemption."
+    ),
+    "api_eviction": (
+        "Check node drains and autoscaler scale-downs; a PodDisruptionBudget limits how many "
+        "replicas they evict at once."
+    ),
+    "taint_manager": "Add a toleration or fix the node condition behind the NoExecute taint.",
+    "node_shutdown": "Check why the node shut down (maintenance, spot reclaim, cloud event).",
+}
+
+
+@dataclass(frozen=True)
+class RuleFinding:
+    rule: str
+    severity: str
+    title: str
+    evidence: list[str]
+    recommendation: str
+
+    def to_dict(self) -> dict[str, object]:
+        return asdict(self)
+
+
+def run_rule_analyzers(k8s_context: K8sContext) -> list[RuleFinding]:
+    """Run all rules and return findings ordered by severity.
+
+    Rules disabled in the runtime overrides are skipped and severity overrides
+    are applied before sorting.
+    """
+    overrides = current_overrides()
+    findings: list[RuleFinding] = []
+    for rule in _RULES:
+        finding = rule(k8s_context)
+        if finding is None or finding.rule in overrides.disabled_rules:
+            continue
+        severity = overrides.rule_severities.get(finding.rule)
+        if severity is not None:
+            finding = replace(finding, severity=severity)
+        findings.append(finding)
+    findings.sort(key=lambda item: _SEVERITY_ORDER.get(item.severity, len(_SEVERITY_ORDER)))
+    return findings
+
+
+def _iter_container_states(
+    k8s_context: K8sContext,
+) -> list[tuple[str, str, dict[str, object]]]:
+    """Yield (container, state_key, state) for current and last container states."""
+    if k8s_context.pod_status is None:
+        return []
+    states: list[tuple[str, str, dict[str, object]]] = []
+    for status in k8s_context.pod_status.container_statuses:
+        if not isinstance(status, dict):
+            continue
+        name = str(status.get("name") or "unknown")
+        for key in ("state", "last_state"):
+            state = status.get(key)
+            if isinstance(state, dict):
+                states.append((name, key, state))
+    return states
+
+
+def _matching_events(k8s_context: K8sContext, reasons: set[str]) -> list[str]:
+    evidence: list[str] = []
+    for event in k8s_context.events:
+        if event.reason in reasons:
+            count = f" (x{event.count})" if event.count else ""
+            evidence.append(f"event {event.reason}{count}: {event.message or ''}".strip())
+    return evidence
+
+
+def _state_evidence(container: str, key: str, state: dict[str, object]) -> str:
+    parts = [f"container {container} {key}={state.get('type')}", f"reason={state.get('reason')}"]
+    exit_code = state.get("exit_code")
+    if exit_code not in (None, "None"):
+        parts.append(f"exit_code={exit_code}")
+    return " ".join(parts)
+
+
+def _rule_oom_killed(k8s_context: K8sContext) -> RuleFinding | None:
+    evidence = [
+        _state_evidence(container, key, state)
+        for container, key, state in _iter_container_states(k8s_context)
+        if state.get("reason") == "OOMKilled"
+    ]
+    evidence.extend(_matching_events(k8s_context, {"OOMKilling"}))
+    if not evidence:
+        return None
+    recommendation = (
+        "Compare memory usage with resources.limits.memory; raise the limit or "
+        "fix the memory growth (leak, oversized cache, JVM heap vs limit)."
+    )
+    oom_analysis = build_oom_analysis(k8s_context)
+    if oom_analysis is not None:
+        findings = cast(list[str], oom_analysis["findings"])
+        recommendation = "; ".join(findings) + ". " + recommendation
+    return RuleFinding(
+        rule="oom_killed",
+        severity="critical",
+        title="Container was OOMKilled (memory limit exceeded)",
+        evidence=evidence,
+        recommendation=recommendation,
+    )
+
+
+def _rule_crash_loop(k8s_context: K8sContext) -> RuleFinding | None:
+    evidence = [
+        _state_evidence(container, key, state)
+        for container, key, state in _iter_container_states(k8s_context)
+        if state.get("reason") == "CrashLoopBackOff"
+    ]
+    evidence.extend(
+        item
+        for item in _matching_events(k8s_context, {"BackOff"})
+        if "restarting failed container" in item.lower()
+    )
+    if not evidence:
+        return None
+    recommendation = (
+        "Check previous container logs and the last termination exit code; "
+        "verify configuration, dependencies and probes."
+    )
+    crash_loop = build_crash_loop_analysis(k8s_context)
+    if crash_loop is not None:
+        evidence.extend(cast(list[str], crash_loop["findings"]))
+        containers = cast(list[dict[str, Any]], crash_loop["containers"])
+        classifications = [
+            (item["name"], item["classification"])
+            for item in containers
+            if item.get("classification")
+        ]
+        evidence.extend(
+            _classification_evidence(name, classification)
+            for name, classification in classifications
+        )
+        fixes = list(
+            dict.fromkeys(
+                str(classification["recommendation"])
+                for _, classification in classifications
+                if classification["recommendation"]
+            )
+        )
+        if fixes:
+            recommendation = " ".join([*fixes, recommendation])
+        causes = [
+            str(item["crash_cause"]["fatal_line"])
+            for item in containers
+            if item.get("crash_cause")
+        ]
+        if causes:
+            recommendation = (
+                f"Fix the crash in the previous container logs: {'; '.join(causes)}. "
+                + recommendation
+            )
+    return RuleFinding(
+        rule="crash_loop_back_off",
+        severity="critical",
+        title="Container is crash looping",
+        evidence=evidence,
+        recommendation=recommendation,
+    )
+
+
+def _rule_image_pull(k8s_context: K8sContext) -> RuleFinding | None:
+    reasons = {"ErrImagePull", "ImagePullBackOff", "InvalidImageName"}
+    evidence = [
+        _state_evidence(container, key, state)
+        for container, key, state in _iter_container_states(k8s_context)
+        if state.get("reason") in reasons
+    ]
+    evidence.extend(
+        item
+        for item in _matching_events(k8s_context, {"Failed", "ErrImagePull", "BackOff"})
+        if "pull" in item.lower() and "image" in item.lower()
+    )
+    if not evidence:
+        return None
+    recommendation = "Verify the image name/tag exists, registry reachability and imagePullSecrets."
+    image_pull = build_image_pull_analysis(k8s_context)
+    if image_pull is not None:
+        evidence.extend(cast(list[str], image_pull["findings"]))
+        causes = [
+            str(item["cause"]) for item in cast(list[dict[str, Any]], image_pull["containers"])
+        ]
+        if image_pull["missing_pull_secrets"]:
+            causes.append("missing_pull_secret")
+        fixes = list(
+            dict.fromkeys(
+                _IMAGE_PULL_RECOMMENDATIONS[cause]
+                for cause in causes
+                if cause in _IMAGE_PULL_RECOMMENDATIONS
+            )
+        )
+        if fixes:
+            recommendation = " ".join([*fixes, recommendation])
+    return RuleFinding(
+        rule="image_pull_failure",
+        severity="critical",
+        title="Container image cannot be pulled",
+        evidence=evidence,
+        recommendation=recommendation,
+    )
+
+
+def _rule_container_config(k8s_context: K8sContext) -> RuleFinding | None:
+    reasons = {"CreateContainerConfigError", "CreateContainerError"}
+    evidence = [
+        _state_evidence(container, key, state)
+        for container, key, state in _iter_container_states(k8s_context)
+        if state.get("reason") in reasons
+    ]
+    if not evidence:
+        return None
+    return RuleFinding(
+        rule="container_config_error",
+        severity="critical",
+        title="Container cannot be created from its configuration",
+        evidence=evidence,
+        recommendation=(
+            "Check referenced ConfigMaps/Secrets and keys exist and volume mounts are valid."
+        ),
+    )
+
+
+def _rule_non_zero_exit(k8s_context: K8sContext) -> RuleFinding | None:
+    evidence: list[str] = []
+    fixes: list[str] = []
+    logs = {
+        snippet.container: [strip_log_timestamp(line) for line in snippet.logs]
+        for snippet in k8s_context.previous_logs
+    }
+    for container, key, state in _iter_container_states(k8s_context):
+        if state.get("type") != "terminated" or state.get("reason") == "OOMKilled":
+            continue
+        exit_code = str(state.get("exit_code") or "")
+        if exit_code and exit_code not in ("0", "None"):
+            evidence.append(_state_evidence(container, key, state))
+            classification = classify_crash(
+                logs.get(container, []), exit_code=exit_code, reason=state.get("reason")
+            )
+            if classification is not None:
+                evidence.append(_classification_evidence(container, classification))
+                if classification["recommendation"]:
+                    fixes.append(str(classification["recommendation"]))
+    if not evidence:
+        return None
+    return RuleFinding(
+        rule="non_zero_exit",
+        severity="warning",
+        title="Container terminated with a non-zero exit code",
+        evidence=list(dict.fromkeys(evidence)),
+        recommendation=" ".join(
+            [
+                *dict.fromkeys(fixes),
+                "Inspect previous logs around the termination for the failing step.",
+            ]
+        ),
+    )
+
+
+def _classification_evidence(container: str, classification: dict[str, Any]) -> str:
+    line = (
+        f"container {container} crash classified as {classification['category']}: "
+        f"{classification['cause']}"
+    )
+    if classification["signature"]:
+        line += f" (signature {classification['signature']}: {classification['matched_line']})"
+    elif classification["signal"]:
+        line += f" (exit code {classification['exit_code']}, {classification['signal']})"
+    return line
+
+
+def _rule_failed_scheduling(k8s_context: K8sContext) -> RuleFinding | None:
+    evidence = _matching_events(k8s_context, {"FailedScheduling"})
+    pod_status = k8s_context.pod_status
+    if pod_status is not None:
+        for condition in pod_status.conditions:
+            if condition.get("type") == "PodScheduled" and condition.get("status") == "False":
+                evidence.append(
+                    f"condition PodScheduled=False reason={condition.get('reason')}: "
+                    f"{condition.get('message') or ''}".strip()
+                )
+    if not evidence:
+        return None
+    recommendation = (
+        "Check node capacity versus requests, taints/tolerations, node selectors/affinity "
+        "and PVC binding."
+    )
+    scheduling = build_scheduling_analysis(k8s_context)
+    if scheduling is not None:
+        evidence.extend(cast(list[str], scheduling["findings"]))
+        kinds = [
+            str(item["kind"])
+            for key in ("constraints", "pod_constraints")
+            for item in cast(list[dict[str, Any]], scheduling[key])
+        ]
+        fixes = list(
+            dict.fromkeys(
+                _SCHEDULING_RECOMMENDATIONS[kind]
+                for kind in kinds
+                if kind in _SCHEDULING_RECOMMENDATIONS
+            )
+        )
+        if fixes:
+            recommendation = " ".join(fixes)
+    return RuleFinding(
+        rule="failed_scheduling",
+        severity="warning",
+        title="Pod cannot be scheduled",
+        evidence=evidence,
+        recommendation=recommendation,
+    )
+
+
+def _rule_probe_failure(k8s_context: K8sContext) -> RuleFinding | None:
+    evidence = _matching_events(k8s_context, {"Unhealthy"})
+    if not evidence:
+        return None
+    title = "Liveness/readiness probes are failing"
+    recommendation = "Verify probe path/port and timeouts against the application's startup time."
+    probe_analysis = build_probe_analysis(k8s_context)
+    if probe_analysis is not None:
+        evidence.extend(cast(list[str], probe_analysis["findings"]))
+        if probe_analysis["root_cause"] == "misconfigured_probe":
+            title = "Probes are misconfigured"
+            recommendation = (
+                "Fix the probe spec (path, port, scheme, command or startup grace) of the "
+                "misconfigured probes; the app itself may be healthy."
+            )
+        else:
+            title = "Application fails its health probes"
+            recommendation = (
+                "The probe spec matches the app; investigate why the app answers errors or "
+                "times out (logs, dependencies, resource pressure) before tuning the probe."
+            )
+    return RuleFinding(
+        rule="probe_failure",
+        severity="warning",
+        title=title,
+        evidence=evidence,
+        recommendation=recommendation,
+    )
+
+
+def _rule_evicted(k8s_context: K8sContext) -> RuleFinding | None:
+    pod_status = k8s_context.pod_status
+    evidence: list[str] = []
+    if pod_status is not None and pod_status.reason == "Evicted":
+        evidence.append(f"pod reason=Evicted: {pod_status.message or ''}".strip())
+    evidence.extend(_matching_events(k8s_context, {"Evicted", "Preempted"}))
+    eviction_analysis = build_eviction_analysis(k8s_context)
+    if not evidence and eviction_analysis is None:
+        return None
+    kinds: set[str] = set()
+    if eviction_analysis is not None:
+        evidence.extend(cast(list[str], eviction_analysis["findings"]))
+        evictions = cast(list[dict[str, Any]], eviction_analysis["evictions"])
+        kinds = {str(item["kind"]) for item in evictions}
+    title = _EVICTION_TITLES.get(next(iter(kinds)), "") if len(kinds) == 1 else ""
+    recommendation = " ".join(
+        text for kind, text in _EVICTION_RECOMMENDATIONS.items() if kind in kinds
+    )
+    return RuleFinding(
+        rule="evicted",
+        severity="warning",
+        title=title or "Pod was evicted by the kubelet",
+        evidence=evidence,
+        recommendation=recommendation
+        or "Check node pressure conditions (memory/disk) and pod ephemeral storage.",
+    )
+
+
+def _rule_volume_mount(k8s_context: K8sContext) -> RuleFinding | None:
+    evidence = _matching_events(k8s_context, {"FailedMount", "FailedAttachVolume"})
+    storage_analysis = build_storage_analysis(k8s_context)
+    findings = cast(list[str], storage_analysis["findings"]) if storage_analysis else []
+    if not evidence and not findings:
+        return None
+    title = (
+        "Volume cannot be attached or mounted"
+        if evidence
+        else "Persistent volume claim is unbound, failing or nearly full"
+    )
+    return RuleFinding(
+        rule="volume_mount_failure",
+        severity="warning",
+        title=title,
+        evidence=[*evidence, *findings],
+        recommendation="Check PVC status, storage class provisioner and referenced Secrets.",
+    )
+
+
+def _rule_node_unhealthy(k8s_context: K8sContext) -> RuleFinding | None:
+    health = summarize_node_health(k8s_context.node_status)
+    if health is None or health["healthy"]:
+        return None
+    not_ready = health["ready"] not in (None, "True")
+    return RuleFinding(
+        rule="node_unhealthy",
+        severity="critical" if not_ready else "warning",
+        title=(
+            f"Node {health['name']} is NotReady"
+            if not_ready
+            else f"Node {health['name']} reports resource pressure"
+        ),
+        evidence=[str(item) for item in health["findings"]],  # type: ignore[attr-defined]
+        recommendation=(
+            "Check kubelet and container runtime logs on the node, its disk and memory usage, "
+            "and the cloud instance status; pods on a NotReady node are evicted after the "
+            "toleration timeout."
+        ),
+    )
+
+
+def _rule_hpa_saturation(k8s_context: K8sContext) -> RuleFinding | None:
+    hpa_analysis = build_hpa_analysis(k8s_context)
+    if hpa_analysis is None or not (
+        hpa_analysis["at_max_replicas"] or hpa_analysis["metrics_unavailable"]
+    ):
+        return None
+    name = hpa_analysis["name"]
+    return RuleFinding(
+        rule="hpa_saturation",
+        severity="warning",
+        title=(
+            f"HPA {name} is pinned at maxReplicas"
+            if hpa_analysis["at_max_replicas"]
+            else f"HPA {name} cannot get its metrics"
+        ),
+        evidence=[
+            *cast(list[str], hpa_analysis["findings"]),
+            *cast(list[str], hpa_analysis["scaling_events"]),
+        ],
+        recommendation=(
+            "Raise maxReplicas or the per-pod capacity when the workload is pinned at its "
+            "maximum; fix the metrics API (metrics-server, custom/external metrics adapter) "
+            "when the HPA cannot read its metrics."
+        ),
+    )
+
+
+def _rule_resource_pressure(k8s_context: K8sContext) -> RuleFinding | None:
+    pressure = build_resource_pressure(k8s_context)
+    if pressure is None or pressure["verdict"] != "critical":
+        return None
+    return RuleFinding(
+        rule="resource_pressure",
+        severity="warning",
+        title="CPU or memory usage is at a limit or the node is nearly full",
+        evidence=cast(list[str], pressure["findings"]),
+        recommendation=(
+            "Raise the container's requests/limits or reduce its load when it runs at a limit; "
+            "move pods off or add nodes when the node's allocatable CPU or memory is exhausted."
+        ),
+    )
+
+
+def _rule_network_policy_blocked(k8s_context: K8sContext) -> RuleFinding | None:
+    analysis = build_network_policy_analysis(k8s_context)
+    if analysis is None or analysis["verdict"] != "blocked":
+        return None
+    destination = cast(dict[str, object], analysis["destination"])
+    service = f"{destination['namespace']}/{destination['service']}"
+    return RuleFinding(
+        rule="network_policy_blocked",
+        severity="critical",
+        title=f"NetworkPolicy blocks traffic to {service}",
+        evidence=cast(list[str], analysis["findings"]),
+        recommendation=(
+            "Add the missing allow rule to the isolating NetworkPolicy (or a new policy "
+            "selecting the same pods); policies are additive, so no existing rule has to change."
+        ),
+    )
+
+
+def _rule_endpoints_unavailable(k8s_context: K8sContext) -> RuleFinding | None:
+    readiness = build_endpoint_readiness(k8s_context)
+    if readiness is None or readiness["verdict"] not in {"unavailable", "degraded"}:
+        return None
+    unavailable = readiness["verdict"] == "unavailable"
+    return RuleFinding(
+        rule="endpoints_unavailable",
+        severity="critical" if unavailable else "warning",
+        title=(
+            f"Service {readiness['service']} has no ready endpoints"
+            if unavailable
+            else f"Service {readiness['service']} has not-ready endpoints"
+        ),
+        evidence=cast(list[str], readiness["findings"]),
+        recommendation=(
+            "Fix the Service selector or the pod labels when the selector matches no pod; "
+            "otherwise check why the selected pods fail their readiness probes (probe path, "
+            "port and timeout, or a dependency the probe checks) or keep crashing."
+        ),
+    )
+
+
+def _rule_job_failed(k8s_context: K8sContext) -> RuleFinding | None:
+    analysis = build_job_failure_analysis(k8s_context)
+    if analysis is None or analysis["cause"] in (None, "job_not_found"):
+        return None
+    failed = analysis["outcome"] == "failed"
+    name = analysis["job"] or analysis["cron_job"]
+    return RuleFinding(
+        rule="job_failed",
+        severity="critical" if failed else "warning",
+        title=f"Job {name} failed" if failed else f"Job {name} pods keep failing",
+        evidence=cast(list[str], analysis["findings"]),
+        recommendation=(
+            "Fix the image reference or pull secret for image errors; raise "
+            "activeDeadlineSeconds or shorten the work for deadline errors; otherwise fix the "
+            "error in the last run's logs before raising backoffLimit."
+        ),
+    )
+
+
+def _rule_cluster_dns_unhealthy(k8s_context: K8sContext) -> RuleFinding | None:
+    analysis = build_dns_analysis(k8s_context)
+    if analysis is None or analysis["verdict"] not in {"unavailable", "degraded"}:
+        return None
+    cause = analysis["cause"]
+    if cause == "upstream_timeouts":
+        recommendation = (
+            "Check the upstream resolvers CoreDNS forwards to (the nodes' /etc/resolv.conf or "
+            "the forward plugin in the CoreDNS ConfigMap) and egress from the DNS pods to them."
+        )
+    elif cause in ("servfail_spike", "dns_errors"):
+        recommendation = (
+            "Read the CoreDNS errors for the failing zones; check stub domains and forwarders "
+            "in the CoreDNS ConfigMap and the health of the resolvers they point at."
+        )
+    else:
+        recommendation = (
+            "Restore the CoreDNS pods (crashes, evictions, scheduling) or scale the deployment; "
+            "check the kube-dns Service selector when pods run but no endpoint is ready."
+        )
+    return RuleFinding(
+        rule="cluster_dns_unhealthy",
+        severity="critical" if analysis["verdict"] == "unavailable" else "warning",
+        title=(
+            f"Cluster DNS {analysis['service']} is unavailable"
+            if analysis["verdict"] == "unavailable"
+            else f"Cluster DNS {analysis['service']} is degraded"
+        ),
+        evidence=cast(list[str], analysis["findings"]),
+        recommendation=recommendation,
+    )
+
+
+def _rule_recent_rollout(k8s_context: K8sContext) -> RuleFinding | None:
+    if not k8s_context.recent_rollouts:
+        return None
+    evidence: list[str] = []
+    for item in k8s_context.recent_rollouts:
+        images = item.get("images")
+        evidence.append(
+            f"revision {item.get('revision')} ({item.get('replica_set')}) created "
+            f"{item.get('created')}: "
+            + ", ".join(str(image) for image in (images if isinstance(images, list) else []))
+        )
+    latest = k8s_context.recent_rollouts[0]
+    return RuleFinding(
+        rule="recent_rollout",
+        severity="warning",
+        title=(
+            f"Deployment {latest.get('deployment')} rolled out revision {latest.get('revision')} "
+            f"{latest.get('minutes_before_alert')} minutes before the alert"
+        ),
+        evidence=evidence,
+        recommendation=(
+            "Compare the new revision with the previous one (image, config, env) and roll back "
+            "with `kubectl rollout undo` if the alert started with the rollout."
+        ),
+    )
+
+
+def _rule_quota_exhausted(k8s_context: K8sContext) -> RuleFinding | None:
+    analysis = build_quota_analysis(k8s_context)
+    if analysis is None or not analysis["failures"]:
+        return None
+    kinds = {str(item["kind"]) for item in cast(list[dict[str, Any]], analysis["failures"])}
+    fixes = []
+    if "quota_exceeded" in kinds:
+        fixes.append(
+            "Raise the ResourceQuota, lower the pods' requests/limits or free quota by scaling "
+            "down other workloads in the namespace."
+        )
+    if "quota_requires_resources" in kinds:
+        fixes.append(
+            "Set the requests/limits the quota covers on every container, or add a LimitRange "
+            "with defaults."
+        )
+    if kinds & {"limit_range_exceeded", "limit_range_ratio_exceeded"}:
+        fixes.append("Keep the pod's requests/limits within the namespace LimitRange.")
+    return RuleFinding(
+        rule="quota_exhausted",
+        severity="critical",
+        title="Pod creation is rejected by ResourceQuota/LimitRange admission",
+        evidence=cast(list[str], analysis["findings"]),
+        recommendation=" ".join(fixes),
+    )
+
+
+def _rule_namespace_degraded(k8s_context: K8sContext) -> RuleFinding | None:
+    snapshot = build_namespace_snapshot(k8s_context)
+    if snapshot is None:
+        return None
+    if not snapshot["unhealthy_workload_count"] and not snapshot["failing_pod_count"]:
+        return None
+    return RuleFinding(
+        rule="namespace_degraded",
+        severity="warning",
+        title="Workloads in the alert's namespace are degraded",
+        evidence=cast(list[str], snapshot["findings"]),
+        recommendation=(
+            "Start from the workloads below their desired replicas and the failing pods listed; "
+            "rerun the analysis on one of those pods for its logs and events."
+        ),
+    )
+
+
+_RULES: list[Callable[[K8sContext], RuleFinding | None]] = [
+    _rule_oom_killed,
+    _rule_crash_loop,
+    _rule_image_pull,
+    _rule_container_config,
+    _rule_non_zero_exit,
+    _rule_failed_scheduling,
+    _rule_probe_failure,
+    _rule_evicted,
+    _rule_volume_mount,
+    _rule_node_unhealthy,
+    _rule_recent_rollout,
+    _rule_hpa_saturation,
+    _rule_resource_pressure,
+    _rule_network_policy_blocked,
+    _rule_endpoints_unavailable,
+    _rule_job_failed,
+    _rule_cluster_dns_unhealthy,
+    _rule_quota_exhausted,
+    _rule_namespace_degraded,
+]
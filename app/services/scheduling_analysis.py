@@ -0,0 +1,280 @@
+"""Unsatisfiable scheduling constraints of Pending pods, returned as ``scheduling``.
+
+A pod counts as unschedulable while it is Pending with a ``FailedScheduling``
+event or a ``PodScheduled=False`` condition. The scheduler's message
+(``0/6 nodes are available: 2 Insufficient cpu, 1 node(s) had untolerated
+taint {...}. preemption: ...``) is split into one constraint per filter with
+the number of nodes it rejected; the scheduler counts every node under the
+first filter it fails, so the counts add up to the node total. Each
+constraint is backed by the pod spec: the container requests behind an
+``Insufficient`` resource, the tolerations missing for a taint, the node
+selector and affinity behind a selector mismatch.
+"""
+
+from __future__ import annotations
+
+import re
+
+from app.models.k8s import K8sContext, PodEventSummary
+
+_NODES_RE = re.compile(r"(\d+)/(\d+) nodes are available(?::\s*(.*))?", re.I | re.S)
+_ITEM_RE = re.compile(r"^(\d+)\s+(.+)$")
+_TAINT_RE = re.compile(r"\{([^:}]+):\s*([^}]*)\}")
+_INSUFFICIENT_RE = re.compile(r"^Insufficient (\S+)$", re.I)
+# Checked in order against the lowercased constraint text.
+_CONSTRAINT_PATTERNS: tuple[tuple[str, tuple[str, ...]], ...] = (
+    ("too_many_pods", ("too many pods",)),
+    ("untolerated_taint", ("taint",)),
+    ("node_unschedulable", ("were unschedulable", "node(s) were unschedulable")),
+    ("volume_node_affinity_conflict", ("volume node affinity conflict",)),
+    ("volume_zone_conflict", ("volume zone conflict",)),
+    ("volume_limit", ("max volume count", "exceed max volume")),
+    ("existing_pod_anti_affinity", ("existing pods anti-affinity",)),
+    ("pod_anti_affinity", ("pod anti-affinity",)),
+    ("pod_affinity", ("pod affinity",)),
+    ("topology_spread", ("topology spread",)),
+    ("node_selector_mismatch", ("node affinity/selector", "node selector", "node affinity")),
+    ("host_port_conflict", ("free ports",)),
+    ("node_name_mismatch", ("didn't match the requested node name",)),
+)
+_STANDARD_RESOURCES = frozenset({"cpu", "memory", "ephemeral-storage", "pods"})
+
+
+def build_scheduling_analysis(k8s_context: K8sContext) -> dict[str, object] | None:
+    status = k8s_context.pod_status
+    if status is not None and status.phase != "Pending":
+        return None
+    message = _scheduling_message(k8s_context)
+    if message is None:
+        return None
+    spec = k8s_context.pod_spec if isinstance(k8s_context.pod_spec, dict) else {}
+    requests = _requests(spec)
+    result: dict[str, object] = {
+        "pod": k8s_context.pod_name,
+        "namespace": k8s_context.namespace,
+        "total_nodes": None,
+        "available_nodes": None,
+        "constraints": [],
+        "pod_constraints": [],
+        "preemption": None,
+        "requests": requests,
+        "message": message,
+        "findings": [],
+    }
+    text, _, preemption = message.partition(" preemption: ")
+    result["preemption"] = preemption.strip().rstrip(".") or None
+    match = _NODES_RE.search(text)
+    if match is None:
+        # Messages without a node breakdown, e.g. unbound PVCs checked before any node.
+        result["pod_constraints"] = [_pod_constraint(text.strip())]
+        result["findings"] = [_pod_finding(k8s_context.pod_name, text.strip())]
+        return result
+    available, total = int(match.group(1)), int(match.group(2))
+    result.update(available_nodes=available, total_nodes=total)
+    constraints: list[dict[str, object]] = []
+    pod_constraints: list[dict[str, object]] = []
+    for item in _split_items(match.group(3) or ""):
+        counted = _ITEM_RE.match(item)
+        if counted is None:
+            pod_constraints.append(_pod_constraint(item))
+            continue
+        constraints.append(_constraint(counted.group(2), int(counted.group(1)), spec))
+    constraints.sort(key=lambda entry: _nodes(entry), reverse=True)
+    result.update(constraints=constraints, pod_constraints=pod_constraints)
+    rejected = "; ".join(f"{entry['nodes']} rejected by {entry['kind']}" for entry in constraints)
+    findings = [
+        f"pod {k8s_context.pod_name} fits {available} of {total} nodes"
+        + (f": {rejected}" if rejected else "")
+    ]
+    findings.extend(_finding(entry, total, requests, spec) for entry in constraints)
+    findings.extend(
+        _pod_finding(k8s_context.pod_name, str(entry["message"])) for entry in pod_constraints
+    )
+    if result["preemption"] and "not helpful" in str(result["preemption"]).lower():
+        findings.append(
+            "preemption cannot help: evicting lower-priority pods frees no fitting node"
+        )
+    result["findings"] = findings
+    return result
+
+
+def _scheduling_message(k8s_context: K8sContext) -> str | None:
+    # The newest FailedScheduling event, then the PodScheduled condition; a message
+    # with a per-node breakdown wins over a truncated one.
+    messages: list[str] = []
+    events = [event for event in k8s_context.events if event.reason == "FailedScheduling"]
+    latest = max(events, key=_event_time) if events else None
+    if latest is not None and latest.message:
+        messages.append(latest.message.strip())
+    status = k8s_context.pod_status
+    for condition in status.conditions if status is not None else []:
+        if (
+            condition.get("type") == "PodScheduled"
+            and condition.get("status") == "False"
+            and condition.get("message")
+        ):
+            messages.append(str(condition["message"]).strip())
+    detailed = [message for message in messages if _has_breakdown(message)]
+    return (detailed or messages or [None])[0]
+
+
+def _has_breakdown(message: str) -> bool:
+    match = _NODES_RE.search(message.partition(" preemption: ")[0])
+    return match is not None and bool((match.group(3) or "").strip(" ."))
+
+
+def _event_time(event: PodEventSummary) -> str:
+    return event.last_timestamp or event.first_timestamp or ""
+
+
+def _split_items(text: str) -> list[str]:
+    # Taints are printed as {key: value}; commas only separate constraints outside braces.
+    items: list[str] = []
+    depth = 0
+    current = ""
+    for char in text.strip().rstrip("."):
+        depth += char == "{"
+        depth -= char == "}"
+        if char == "," and depth == 0:
+            items.append(current.strip())
+            current = ""
+        else:
+            current += char
+    if current.strip():
+        items.append(current.strip())
+    return items
+
+
+def _constraint(item: str, nodes: int, spec: dict[str, object]) -> dict[str, object]:
+    entry: dict[str, object] = {"kind": "other", "nodes": nodes, "message": item}
+    insufficient = _INSUFFICIENT_RE.match(item)
+    if insufficient:
+        entry.update(kind="insufficient_resource", resource=insufficient.group(1))
+        return entry
+    lowered = item.lower()
+    entry["kind"] = next(
+        (kind for kind, patterns in _CONSTRAINT_PATTERNS if any(p in lowered for p in patterns)),
+        "other",
+    )
+    if entry["kind"] == "untolerated_taint":
+        taints = [
+            {"key": key.strip(), "value": value.strip() or None}
+            for key, value in _TAINT_RE.findall(item)
+        ]
+        entry["taints"] = taints
+        # Tolerations with the taint's key whose value or effect does not match.
+        entry["mismatched_tolerations"] = [
+            taint["key"] for taint in taints if _tolerates_key(spec, str(taint["key"]))
+        ]
+    return entry
+
+
+def _pod_constraint(message: str) -> dict[str, object]:
+    lowered = message.lower()
+    kind = "unbound_pvc" if "unbound" in lowered and "persistentvolumeclaim" in lowered else "other"
+    if "not found" in lowered and "persistentvolumeclaim" in lowered:
+        kind = "pvc_not_found"
+    return {"kind": kind, "message": message}
+
+
+def _requests(spec: dict[str, object]) -> list[dict[str, object]]:
+    requests: list[dict[str, object]] = []
+    for key in ("init_containers", "containers"):
+        for container in _dicts(spec.get(key)):
+            resources = container.get("resources")
+            values = resources.get("requests") if isinstance(resources, dict) else None
+            if isinstance(values, dict) and values:
+                requests.append(
+                    {
+                        "container": container.get("name"),
+                        "init": key == "init_containers",
+                        **{str(name): str(value) for name, value in values.items()},
+                    }
+                )
+    return requests
+
+
+def _finding(
+    entry: dict[str, object],
+    total: int,
+    requests: list[dict[str, object]],
+    spec: dict[str, object],
+) -> str:
+    nodes = f"{entry['nodes']} of {total} nodes"
+    kind = entry["kind"]
+    if kind == "insufficient_resource":
+        resource = str(entry["resource"])
+        asked = [
+            f"{item.get('container')} {item[resource]}"
+            for item in requests
+            if resource in item
+        ]
+        extended = "" if resource in _STANDARD_RESOURCES else " (extended resource)"
+        return (
+            f"{nodes} lack allocatable {resource}{extended} for the pod's requests"
+            + (f" ({', '.join(asked)})" if asked else "")
+            + "; lower the requests, free capacity or add nodes"
+        )
+    if kind == "too_many_pods":
+        return f"{nodes} already run their maximum number of pods"
+    if kind == "untolerated_taint":
+        taints = ", ".join(
+            f"{taint['key']}={taint['value']}" if taint.get("value") else str(taint["key"])
+            for taint in _dicts(entry.get("taints"))
+        )
+        mismatched = entry.get("mismatched_tolerations")
+        return f"{nodes} have taints the pod does not tolerate: {taints or entry['message']}" + (
+            f"; the pod tolerates {', '.join(str(key) for key in mismatched)} only with "
+            "another value or effect"
+            if isinstance(mismatched, list) and mismatched
+            else ""
+        )
+    if kind == "node_selector_mismatch":
+        selector = spec.get("node_selector")
+        has_affinity = isinstance(spec.get("affinity"), dict) and bool(
+            _dict(spec["affinity"]).get("node_affinity")
+        )
+        detail = []
+        if isinstance(selector, dict) and selector:
+            detail.append("nodeSelector " + ",".join(f"{k}={v}" for k, v in selector.items()))
+        if has_affinity:
+            detail.append("required node affinity")
+        return (
+            f"{nodes} do not match the pod's "
+            + (" and ".join(detail) if detail else "node selector/affinity")
+        )
+    if kind == "volume_node_affinity_conflict":
+        return (
+            f"{nodes} are outside the node affinity (usually the zone) of a bound "
+            "PersistentVolume; the pod can only run where its volume is"
+        )
+    if kind == "node_unschedulable":
+        return f"{nodes} are cordoned (spec.unschedulable)"
+    if kind in ("pod_affinity", "pod_anti_affinity", "existing_pod_anti_affinity"):
+        return f"{nodes} violate inter-pod (anti-)affinity rules: {entry['message']}"
+    if kind == "topology_spread":
+        return f"{nodes} would break the pod's topology spread constraints"
+    if kind == "host_port_conflict":
+        return f"{nodes} already use a hostPort the pod requests"
+    return f"{nodes}: {entry['message']}"
+
+
+def _pod_finding(pod_name: str | None, message: str) -> str:
+    return f"pod {pod_name} cannot be scheduled on any node: {message}"
+
+
+def _tolerates_key(spec: dict[str, object], key: str) -> bool:
+    return any(toleration.get("key") == key for toleration in _dicts(spec.get("tolerations")))
+
+
+def _nodes(entry: dict[str, object]) -> int:
+    nodes = entry.get("nodes")
+    return nodes if isinstance(nodes, int) else 0
+
+
+def _dicts(value: object) -> list[dict[str, object]]:
+    return [item for item in value if isinstance(item, dict)] if isinstance(value, list) else []
+
+
+def _dict(value: object) -> dict[str, object]:
+    return value if isinstance(value, dict) else {}
@@ -0,0 +1,248 @@
+"""Endpoints of the Service behind 5xx/unreachable alerts, returned as ``endpoint_readiness``.
+
+The Service is the destination of a connection-failure alert (see
+``connectivity_target``), or the ``service`` label of alerts named like an
+error rate or an unavailable service. ``KubernetesClient.get_service_endpoints``
+resolves it to its EndpointSlices and the pods its selector matches; the
+verdict is ``healthy`` when every endpoint is ready, ``degraded`` when only
+some are and ``unavailable`` when none is. The ``cause`` tells a selector
+matching no pod apart from selected pods failing their readiness probes,
+crashing or otherwise not running.
+"""
+
+from __future__ import annotations
+
+from app.models.k8s import K8sContext
+from app.services.network_policy import connectivity_target
+
+_ENDPOINT_ALERT_MARKERS = (
+    "5xx",
+    "500",
+    "502",
+    "503",
+    "504",
+    "error",
+    "unavailable",
+    "unreachable",
+    "down",
+    "endpoint",
+    "noready",
+    "notready",
+)
+_NOT_READY_PODS_IN_FINDING = 5
+
+
+def endpoints_target(labels: dict[str, str], k8s_context: K8sContext) -> dict[str, str] | None:
+    """Namespace and name of the Service a 5xx/unreachable alert is about, else ``None``."""
+    target = connectivity_target(labels, k8s_context)
+    if target is not None:
+        return {"namespace": str(target["namespace"]), "service": str(target["service"])}
+    alertname = labels.get("alertname", "").lower()
+    service = labels.get("service")
+    namespace = labels.get("namespace") or k8s_context.namespace
+    if not service or service.lower() == "unknown" or not namespace:
+        return None
+    if not any(marker in alertname for marker in _ENDPOINT_ALERT_MARKERS):
+        return None
+    return {"namespace": namespace, "service": service}
+
+
+def build_endpoint_readiness(k8s_context: K8sContext) -> dict[str, object] | None:
+    data = k8s_context.service_endpoints
+    if not data:
+        return None
+    name = f"{data.get('namespace')}/{data.get('service')}"
+    selector = data.get("selector") if isinstance(data.get("selector"), dict) else {}
+    result: dict[str, object] = {
+        "service": name,
+        "selector": selector or None,
+        "verdict": "unknown",
+        "cause": None,
+        "ready": 0,
+        "not_ready": 0,
+        "terminating": 0,
+        "selected_pods": 0,
+        "not_ready_pods": [],
+        "near_misses": [],
+        "findings": [],
+    }
+    if not data.get("found"):
+        result.update(
+            cause="service_not_found",
+            findings=[f"service {name} does not exist or could not be read"],
+        )
+        return result
+    endpoints = _dicts(data.get("endpoints"))
+    pods = _dicts(data.get("pods"))
+    current = [item for item in endpoints if not item.get("terminating")]
+    ready = sum(1 for item in current if item.get("ready"))
+    not_ready_pods = [_not_ready_pod(pod) for pod in pods if not pod.get("ready")]
+    near_misses = _dicts(data.get("near_misses"))
+    result.update(
+        ready=ready,
+        not_ready=len(current) - ready,
+        terminating=len(endpoints) - len(current),
+        selected_pods=len(pods),
+        not_ready_pods=not_ready_pods,
+        near_misses=near_misses,
+    )
+    if ready and ready == len(current):
+        result["verdict"] = "healthy"
+    else:
+        result["verdict"] = "degraded" if ready else "unavailable"
+    findings: list[str] = []
+    if not selector:
+        if not endpoints:
+            result["cause"] = "no_selector"
+            findings.append(
+                f"service {name} has no selector and no endpoints; endpoints of selector-less "
+                "services are managed by hand or a controller, so none are registered"
+            )
+        elif result["verdict"] != "healthy":
+            result["cause"] = "no_selector"
+            findings.append(
+                f"service {name} has no selector and {ready} of {len(current)} of its "
+                "externally managed endpoints are ready"
+            )
+    elif not pods:
+        result["cause"] = "selector_mismatch"
+        findings.append(_selector_mismatch_finding(name, selector, near_misses))
+    elif result["verdict"] != "healthy" and not_ready_pods:
+        result["cause"] = _not_ready_cause(not_ready_pods)
+        findings.append(_not_ready_finding(name, ready, len(current), not_ready_pods))
+    port_findings = _target_port_findings(name, _dicts(data.get("ports")), pods)
+    if result["verdict"] != "healthy" and pods and result["cause"] is None:
+        result["cause"] = "target_port_mismatch" if port_findings else "endpoints_missing"
+        if not port_findings:
+            findings.append(
+                f"service {name} has {ready} of {len(current)} endpoints ready although its "
+                f"{len(pods)} selected pods are ready; check the EndpointSlice controller"
+            )
+    result["findings"] = [*findings, *port_findings]
+    return result
+
+
+def _not_ready_pod(pod: dict[str, object]) -> dict[str, object]:
+    entry: dict[str, object] = {"pod": pod.get("name"), "reason": "not_ready", "detail": None}
+    containers = _dicts(pod.get("containers"))
+    if pod.get("terminating"):
+        entry.update(reason="terminating", detail="pod is terminating")
+        return entry
+    for container in containers:
+        state = container.get("state") if isinstance(container.get("state"), dict) else {}
+        if state.get("type") in {"waiting", "terminated"}:
+            entry.update(
+                reason="container_not_running",
+                detail=f"container {container.get('name')} {state.get('type')}: "
+                f"{state.get('reason') or 'unknown reason'}",
+            )
+            return entry
+    if pod.get("phase") not in (None, "Running"):
+        entry.update(reason="not_running", detail=f"pod phase {pod.get('phase')}")
+        return entry
+    probed = [
+        container
+        for container in containers
+        if not container.get("ready") and isinstance(container.get("readiness_probe"), dict)
+    ]
+    if probed:
+        container = probed[0]
+        failures = pod.get("probe_failures")
+        last_failure = failures[0] if isinstance(failures, list) and failures else None
+        entry.update(
+            reason="readiness_probe_failing",
+            detail=f"container {container.get('name')} fails its readiness probe "
+            f"({_describe_probe(container['readiness_probe'])})"
+            + (f": {last_failure}" if last_failure else ""),
+        )
+        return entry
+    entry["detail"] = pod.get("ready_message")
+    return entry
+
+
+def _not_ready_cause(not_ready_pods: list[dict[str, object]]) -> str:
+    reasons = {pod["reason"] for pod in not_ready_pods}
+    if reasons == {"readiness_probe_failing"}:
+        return "readiness_probe_failing"
+    if "container_not_running" in reasons:
+        return "pods_crashing"
+    return "pods_not_ready"
+
+
+def _selector_mismatch_finding(
+    name: str, selector: dict[str, object], near_misses: list[dict[str, object]]
+) -> str:
+    text = (
+        f"service {name} has no endpoints: its selector {_format_labels(selector)} matches "
+        "no pod in the namespace"
+    )
+    if not near_misses:
+        return text + ", and no pod carries all of its label keys"
+    examples = ", ".join(
+        f"{item.get('name')} ({_format_labels(_dict(item.get('mismatched')))})"
+        for item in near_misses[:3]
+    )
+    return text + f"; pods with the same label keys differ in {examples}"
+
+
+def _not_ready_finding(
+    name: str, ready: int, total: int, not_ready_pods: list[dict[str, object]]
+) -> str:
+    details = "; ".join(
+        f"{pod['pod']}: {pod['detail'] or pod['reason']}"
+        for pod in not_ready_pods[:_NOT_READY_PODS_IN_FINDING]
+    )
+    more = len(not_ready_pods) - _NOT_READY_PODS_IN_FINDING
+    if more > 0:
+        details += f"; {more} more"
+    if ready == 0 and all(pod["reason"] == "readiness_probe_failing" for pod in not_ready_pods):
+        summary = f"all {len(not_ready_pods)} selected pods fail their readiness probes"
+    else:
+        summary = f"{len(not_ready_pods)} selected pods are not ready"
+    return f"service {name} has {ready} of {total} endpoints ready: {summary} ({details})"
+
+
+def _target_port_findings(
+    name: str, ports: list[dict[str, object]], pods: list[dict[str, object]]
+) -> list[str]:
+    if not pods:
+        return []
+    declared = {
+        port.get("name")
+        for pod in pods
+        for container in _dicts(pod.get("containers"))
+        for port in _dicts(container.get("ports"))
+        if port.get("name")
+    }
+    return [
+        f"service {name} port {port.get('port')} targets the named port "
+        f"{port.get('target_port')}, which no container of the selected pods declares; "
+        "that port has no endpoints"
+        for port in ports
+        if isinstance(port.get("target_port"), str)
+        and not str(port["target_port"]).isdigit()
+        and port["target_port"] not in declared
+    ]
+
+
+def _describe_probe(probe: dict[str, object]) -> str:
+    kind = probe.get("type") or "probe"
+    if kind == "httpGet":
+        return f"httpGet {probe.get('path') or '/'} on port {probe.get('port')}"
+    if kind == "exec":
+        command = probe.get("command")
+        parts = command if isinstance(command, list) else []
+        return " ".join(["exec", *(str(part) for part in parts)])
+    return f"{kind} on port {probe.get('port')}"
+
+
+def _format_labels(labels: dict[str, object]) -> str:
+    return ",".join(f"{key}={value}" for key, value in labels.items())
+
+
+def _dicts(value: object) -> list[dict[str, object]]:
+    return [item for item in value if isinstance(item, dict)] if isinstance(value, list) else []
+
+
+def _dict(value: object) -> dict[str, object]:
+    return value if isinstance(value, dict) else {}
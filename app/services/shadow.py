@@ -0,0 +1,104 @@
+from __future__ import annotations
+
+import logging
+import threading
+import time
+from dataclasses import replace
+
+from app.clients.shadow_store import ShadowResult, ShadowStore
+from app.clients.strands_agent import AnalysisEngine
+from app.core.masking import Masker, RegexMasker
+
+logger = logging.getLogger(__name__)
+
+
+class ShadowAnalysisRunner:
+    """Run a second model on the prompt of each analysis without delivering its answer.
+
+    Shadow runs happen on background threads after the primary result is
+    ready, so they never add latency to ``/analyze``. When
+    ``max_concurrency`` runs are already in flight the alert is skipped
+    instead of queueing. Both answers are written to the shadow store.
+    """
+
+    def __init__(
+        self,
+        engine: AnalysisEngine,
+        store: ShadowStore,
+        *,
+        primary_model: str,
+        shadow_model: str,
+        masker: Masker | None = None,
+        max_concurrency: int = 1,
+    ) -> None:
+        self._engine = engine
+        self._store = store
+        self._primary_model = primary_model
+        self._shadow_model = shadow_model
+        self._masker = masker or RegexMasker()
+        self._slots = threading.BoundedSemaphore(max(1, max_concurrency))
+
+    def submit(
+        self,
+        prompt: str,
+        *,
+        analysis_id: str,
+        session_key: str,
+        alertname: str | None,
+        namespace: str | None,
+        primary_analysis: str,
+        primary_ms: float,
+    ) -> bool:
+        """Start a shadow run; returns False when it was skipped because all slots are busy."""
+        if not self._slots.acquire(blocking=False):
+            logger.info("shadow_analysis_skipped analysis_id=%s reason=busy", analysis_id)
+            return False
+        base = ShadowResult(
+            analysis_id=analysis_id,
+            session_key=session_key,
+            alertname=alertname,
+            namespace=namespace,
+            primary_model=self._primary_model,
+            shadow_model=self._shadow_model,
+            primary_analysis=primary_analysis,
+            shadow_analysis="",
+            shadow_error=None,
+            primary_ms=round(primary_ms, 1),
+            shadow_ms=0.0,
+        )
+        threading.Thread(
+            target=self._run, args=(prompt, base), name="shadow-analysis", daemon=True
+        ).start()
+        return True
+
+    def _run(self, prompt: str, base: ShadowResult) -> None:
+        try:
+            started = time.perf_counter()
+            shadow_analysis = ""
+            shadow_error: str | None = None
+            try:
+                # Separate session, so the shadow transcript never mixes with the primary one.
+                answer = self._engine.analyze(prompt, f"{base.analysis_id}:shadow")
+                shadow_analysis = self._masker.mask_text(answer if isinstance(answer, str) else "")
+            except Exception as exc:  # noqa: BLE001
+                shadow_error = self._masker.mask_text(f"{type(exc).__name__}: {exc}")
+            shadow_ms = round((time.perf_counter() - started) * 1000, 1)
+            self._store.record(
+                replace(
+                    base,
+                    shadow_analysis=shadow_analysis,
+                    shadow_error=shadow_error,
+                    shadow_ms=shadow_ms,
+                )
+            )
+            logger.info(
+                "shadow_analysis_recorded analysis_id=%s shadow_model=%s shadow_ms=%.1f error=%s",
+                base.analysis_id,
+                self._shadow_model,
+                shadow_ms,
+                shadow_error is not None,
+            )
+        except Exception as exc:  # noqa: BLE001
+            logger.warning("Failed to store shadow analysis result: %s", exc)
+        finally:
+            self._slots.release()
@@ -0,0 +1,136 @@
+"""Map Slack interaction payloads forwarded by the backend onto agent actions.
+
+Two payload shapes are understood:
+
+- ``block_actions`` from message buttons. The button ``action_id`` names the
+  action and its ``value`` carries the ``analysis_id`` (plain, or as a JSON
+  object with an ``analysis_id`` key).
+- Slash commands (``command`` + ``text``), where the text is
+  ``<analysis_id> <question>``.
+"""
+
+from __future__ import annotations
+
+import json
+from typing import Protocol
+
+from app.schemas.analysis import AnalysisFollowupRequest
+
+ACTION_DIG_DEEPER = "dig_deeper"
+ACTION_FETCH_MORE_LOGS = "fetch_more_logs"
+ACTION_APPROVE_REMEDIATION = "approve_remediation"
+ACTION_ASK = "ask"
+
+_FOLLOWUP_QUESTIONS = {
+    ACTION_DIG_DEEPER: (
+        "Dig deeper into the root cause: verify the leading hypothesis with additional "
+        "evidence, rule out the alternatives and name what would confirm the fix."
+    ),
+    ACTION_FETCH_MORE_LOGS: (
+        "Fetch more logs for the affected workload (a wider time window, previous "
+        "containers and related pods) and report what is relevant to the root cause."
+    ),
+}
+
+
+class _FollowupHandler(Protocol):
+    def follow_up(self, analysis_id: str, request: AnalysisFollowupRequest) -> str: ...
+
+
+class SlackInteractionService:
+    def __init__(self, analysis_service: _FollowupHandler) -> None:
+        self._analysis_service = analysis_service
+
+    def handle(self, payload: object) -> dict[str, object]:
+        """Run the action behind a Slack interaction and return the thread reply.
+
+        Raises:
+            ValueError: when the payload is not a supported interaction.
+            AnalysisNotFoundError: when the referenced analysis is not stored.
+        """
+        payload = _decode_payload(payload)
+        if "command" in payload:
+            action, analysis_id, question = _parse_slash_command(payload)
+            thread_ts = _as_str(payload.get("thread_ts"))
+        elif payload.get("type") == "block_actions":
+            action, analysis_id = _parse_block_action(payload)
+            question = _FOLLOWUP_QUESTIONS.get(action, "")
+            thread_ts = _resolve_thread_ts(payload)
+        else:
+            raise ValueError("unsupported Slack interaction payload")
+
+        result: dict[str, object] = {
+            "status": "ok",
+            "action": action,
+            "analysis_id": analysis_id,
+            "thread_ts": thread_ts,
+        }
+        if action == ACTION_APPROVE_REMEDIATION:
+            result["status"] = "unsupported"
+            result["text"] = "Remediation actions are not available on this agent."
+            return result
+        if not question:
+            raise ValueError(f"unsupported Slack action: {action}")
+
+        result["text"] = self._analysis_service.follow_up(
+            analysis_id, AnalysisFollowupRequest(question=question, thread_ts=thread_ts)
+        )
+        return result
+
+
+def _decode_payload(payload: object) -> dict[str, object]:
+    # Slack posts interactions as form-encoded ``payload=<json>``; accept the
+    # backend forwarding either that wrapper or the decoded object.
+    if isinstance(payload, dict) and isinstance(payload.get("payload"), str):
+        try:
+            payload = json.loads(payload["payload"])
+        except ValueError as exc:
+            raise ValueError("payload is not valid JSON") from exc
+    if not isinstance(payload, dict):
+        raise ValueError("payload must be a JSON object")
+    return payload
+
+
+def _parse_slash_command(payload: dict[str, object]) -> tuple[str, str, str]:
+    analysis_id, _, question = _as_str(payload.get("text")).strip().partition(" ")
+    if not analysis_id or not question.strip():
+        raise ValueError("slash command text must be '<analysis_id> <question>'")
+    return ACTION_ASK, analysis_id, question.strip()
+
+
+def _parse_block_action(payload: dict[str, object]) -> tuple[str, str]:
+    actions = payload.get("actions")
+    if not isinstance(actions, list) or not actions or not isinstance(actions[0], dict):
+        raise ValueError("block_actions payload has no actions")
+    action = actions[0]
+    action_id = _as_str(action.get("action_id")).strip()
+    if not action_id:
+        raise ValueError("action has no action_id")
+    value = _as_str(action.get("value")).strip()
+    if value.startswith("{"):
+        try:
+            decoded = json.loads(value)
+        except ValueError as exc:
+            raise ValueError("action value is not valid JSON") from exc
+        value = _as_str(decoded.get("analysis_id")).strip() if isinstance(decoded, dict) else ""
+    if not value:
+        raise ValueError("action value must carry the analysis_id")
+    return action_id, value
+
+
+def _resolve_thread_ts(payload: dict[str, object]) -> str:
+    # Replies go to the thread of the clicked message; a top-level message
+    # starts its own thread.
+    for key in ("message", "container"):
+        section = payload.get(key)
+        if not isinstance(section, dict):
+            continue
+        for field in ("thread_ts", "ts", "message_ts"):
+            value = _as_str(section.get(field))
+            if value:
+                return value
+    return ""
+
+
+def _as_str(value: object) -> str:
+    return value if isinstance(value, str) else ""
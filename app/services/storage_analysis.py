@@ -0,0 +1,256 @@
+"""PersistentVolume/PVC analysis of storage alerts, returned as ``storage_analysis``.
+
+Storage alerts (``persistentvolumeclaim`` label, e.g. KubePersistentVolumeFillingUp)
+and pods waiting on their volumes (Pending, ContainerCreating, FailedMount or
+FailedAttachVolume events) get their claims read with
+``KubernetesClient.get_volume_claim_status``: binding phase, PersistentVolume,
+StorageClass, VolumeAttachments and claim events. Capacity usage comes from
+the kubelet stats summary of the node the volume is attached to.
+"""
+
+from __future__ import annotations
+
+from app.models.k8s import K8sContext
+
+_MAX_CLAIMS = 5
+# KubePersistentVolumeFillingUp fires below 15% free space (and inodes).
+_FULL_PERCENT = 85.0
+_MOUNT_EVENT_REASONS = frozenset({"FailedMount", "FailedAttachVolume"})
+_STORAGE_ALERT_MARKERS = ("volume", "pvc")
+_RESIZE_CONDITIONS = {
+    "Resizing": "is being resized by the volume provider",
+    "FileSystemResizePending": (
+        "was resized but its filesystem is expanded only when a pod (re)mounts it; "
+        "restart the pod using it"
+    ),
+    "ControllerResizeError": "failed to resize",
+    "NodeResizeError": "failed to expand its filesystem on the node",
+}
+
+
+def storage_claim_names(labels: dict[str, str], k8s_context: K8sContext) -> list[str]:
+    """Claims to inspect when the alert or the pod points at storage, else ``[]``."""
+    names: list[str] = []
+    labeled = labels.get("persistentvolumeclaim")
+    if labeled:
+        names.append(labeled)
+    alertname = labels.get("alertname", "").lower()
+    if (
+        labeled
+        or any(marker in alertname for marker in _STORAGE_ALERT_MARKERS)
+        or _mount_events(k8s_context)
+        or _waiting_on_volumes(k8s_context)
+    ):
+        for name in _pod_claim_names(k8s_context.pod_spec):
+            if name not in names:
+                names.append(name)
+    return names[:_MAX_CLAIMS]
+
+
+def volume_stats_nodes(k8s_context: K8sContext, claims: list[dict[str, object]]) -> list[str]:
+    """Nodes whose kubelet reports usage of *claims*: the pod's node, else attachments."""
+    nodes: list[str] = []
+    if k8s_context.pod_status is not None and k8s_context.pod_status.node_name:
+        nodes.append(k8s_context.pod_status.node_name)
+    for claim in claims:
+        for attachment in _dicts(claim.get("attachments")):
+            node = attachment.get("node")
+            if attachment.get("attached") and isinstance(node, str) and node not in nodes:
+                nodes.append(node)
+    return nodes
+
+
+def build_storage_analysis(k8s_context: K8sContext) -> dict[str, object] | None:
+    mount_events = _mount_events(k8s_context)
+    if not k8s_context.volume_claims and not mount_events:
+        return None
+    claims: list[dict[str, object]] = []
+    findings: list[str] = []
+    for claim in k8s_context.volume_claims:
+        entry = _claim_entry(claim)
+        claims.append(entry)
+        findings.extend(_claim_findings(claim, entry))
+    if any("Multi-Attach error" in message for _, message in mount_events):
+        findings.append(
+            "a ReadWriteOnce volume is still attached to another node (Multi-Attach error); "
+            "it is released once the old pod terminates and its node detaches the volume"
+        )
+    if mount_events and _waiting_on_volumes(k8s_context):
+        findings.append("pod is stuck in ContainerCreating until its volumes attach and mount")
+    return {
+        "pod": k8s_context.pod_name,
+        "namespace": k8s_context.namespace,
+        "claims": claims,
+        "mount_events": [f"{reason}: {message}" for reason, message in mount_events],
+        "findings": findings,
+    }
+
+
+def _claim_entry(claim: dict[str, object]) -> dict[str, object]:
+    storage_class = _dict(claim.get("storage_class_detail"))
+    volume = _dict(claim.get("persistent_volume"))
+    usage = _dict(claim.get("usage"))
+    return {
+        "name": claim.get("name"),
+        "phase": claim.get("phase"),
+        "storage_class": claim.get("storage_class"),
+        "provisioner": storage_class.get("provisioner"),
+        "volume_binding_mode": storage_class.get("volume_binding_mode"),
+        "allow_volume_expansion": storage_class.get("allow_volume_expansion"),
+        "access_modes": claim.get("access_modes") or [],
+        "requested": claim.get("requested"),
+        "capacity": claim.get("capacity"),
+        "volume_name": claim.get("volume_name"),
+        "volume_phase": volume.get("phase"),
+        "attached_nodes": [
+            attachment.get("node")
+            for attachment in _dicts(claim.get("attachments"))
+            if attachment.get("attached")
+        ],
+        "used_bytes": _number(usage.get("used_bytes")),
+        "capacity_bytes": _number(usage.get("capacity_bytes")),
+        "usage_percent": _percent(usage.get("used_bytes"), usage.get("capacity_bytes")),
+        "inodes_usage_percent": _percent(usage.get("inodes_used"), usage.get("inodes")),
+    }
+
+
+def _claim_findings(claim: dict[str, object], entry: dict[str, object]) -> list[str]:
+    name = entry["name"]
+    storage_class = _dict(claim.get("storage_class_detail"))
+    volume = _dict(claim.get("persistent_volume"))
+    findings: list[str] = []
+    if entry["phase"] == "Pending":
+        findings.append(f"claim {name} is Pending: {_pending_reason(claim, storage_class)}")
+    elif entry["phase"] == "Lost":
+        findings.append(
+            f"claim {name} is Lost: its PersistentVolume {entry['volume_name']} no longer exists"
+        )
+    if volume.get("phase") == "Failed":
+        message = volume.get("message") or volume.get("reason") or "reclaim failed"
+        findings.append(f"PersistentVolume {volume.get('name')} is Failed: {message}")
+    for attachment in _dicts(claim.get("attachments")):
+        if attachment.get("attach_error"):
+            findings.append(
+                f"volume {entry['volume_name']} failed to attach to node "
+                f"{attachment.get('node')}: {attachment['attach_error']}"
+            )
+        if attachment.get("detach_error"):
+            findings.append(
+                f"volume {entry['volume_name']} failed to detach from node "
+                f"{attachment.get('node')}: {attachment['detach_error']}"
+            )
+    for condition in _dicts(claim.get("conditions")):
+        description = _RESIZE_CONDITIONS.get(str(condition.get("type")))
+        if description and condition.get("status") == "True":
+            message = f" ({condition['message']})" if condition.get("message") else ""
+            findings.append(f"claim {name} {description}{message}")
+    usage_percent = entry["usage_percent"]
+    if isinstance(usage_percent, float) and usage_percent >= _FULL_PERCENT:
+        findings.append(
+            f"claim {name} is {usage_percent}% full ({_format_bytes(entry['used_bytes'])} of "
+            f"{_format_bytes(entry['capacity_bytes'])}){_expansion_hint(entry)}"
+        )
+    inodes_percent = entry["inodes_usage_percent"]
+    if isinstance(inodes_percent, float) and inodes_percent >= _FULL_PERCENT:
+        findings.append(
+            f"claim {name} uses {inodes_percent}% of its inodes; remove small files "
+            "(caches, temp files) rather than expanding the volume"
+        )
+    return findings
+
+
+def _pending_reason(claim: dict[str, object], storage_class: dict[str, object]) -> str:
+    failures = [
+        str(event.get("message") or "")
+        for event in _dicts(claim.get("events"))
+        if event.get("reason") in {"ProvisioningFailed", "FailedBinding"}
+    ]
+    if not claim.get("storage_class"):
+        return "no storageClassName is set and no default StorageClass exists"
+    if storage_class.get("found") is False:
+        return f"StorageClass {claim['storage_class']} does not exist"
+    if failures:
+        return f"provisioning failed: {failures[-1]}"
+    if (
+        storage_class.get("volume_binding_mode") == "WaitForFirstConsumer"
+        and not claim.get("selected_node")
+    ):
+        return (
+            f"StorageClass {claim['storage_class']} binds on first consumer and no pod "
+            "using the claim has been scheduled yet"
+        )
+    provisioner = storage_class.get("provisioner") or "its"
+    return f"waiting for the {provisioner} provisioner to create a volume"
+
+
+def _expansion_hint(entry: dict[str, object]) -> str:
+    if entry["allow_volume_expansion"] is True:
+        return "; expand it by raising spec.resources.requests.storage"
+    if entry["allow_volume_expansion"] is False:
+        return (
+            f"; StorageClass {entry['storage_class']} does not allow expansion, "
+            "free space or migrate the data to a larger volume"
+        )
+    return ""
+
+
+def _mount_events(k8s_context: K8sContext) -> list[tuple[str, str]]:
+    events: list[tuple[str, str]] = []
+    for event in k8s_context.events:
+        if event.reason in _MOUNT_EVENT_REASONS:
+            item = (event.reason, (event.message or "").strip())
+            if item not in events:
+                events.append(item)
+    return events
+
+
+def _waiting_on_volumes(k8s_context: K8sContext) -> bool:
+    status = k8s_context.pod_status
+    if status is None or not _pod_claim_names(k8s_context.pod_spec):
+        return False
+    if status.phase == "Pending":
+        return True
+    for container in status.container_statuses:
+        state = _dict(container.get("state")) if isinstance(container, dict) else {}
+        if state.get("type") == "waiting" and state.get("reason") == "ContainerCreating":
+            return True
+    return False
+
+
+def _pod_claim_names(pod_spec: dict[str, object] | None) -> list[str]:
+    names: list[str] = []
+    for volume in _dicts(pod_spec.get("volumes") if pod_spec else None):
+        claim = _dict(volume.get("persistent_volume_claim")).get("claim_name")
+        if isinstance(claim, str) and claim:
+            names.append(claim)
+    return names
+
+
+def _percent(used: object, total: object) -> float | None:
+    used_value = _number(used)
+    total_value = _number(total)
+    if used_value is None or not total_value:
+        return None
+    return round(used_value * 100 / total_value, 1)
+
+
+def _format_bytes(value: object) -> str:
+    number = _number(value) or 0.0
+    for unit, size in (("Ti", 1024**4), ("Gi", 1024**3), ("Mi", 1024**2)):
+        if number >= size:
+            return f"{number / size:.1f}{unit}"
+    return f"{int(number)}B"
+
+
+def _number(value: object) -> float | None:
+    if isinstance(value, bool) or not isinstance(value, (int, float)):
+        return None
+    return float(value)
+
+
+def _dict(value: object) -> dict[str, object]:
+    return value if isinstance(value, dict) else {}
+
+
+def _dicts(value: object) -> list[dict[str, object]]:
+    return [item for item in value if isinstance(item, dict)] if isinstance(value, list) else []
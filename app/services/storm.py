@@ -0,0 +1,288 @@
+from __future__ import annotations
+
+import asyncio
+import logging
+import threading
+import time
+from collections import Counter, deque
+from collections.abc import Callable
+from dataclasses import dataclass, field
+from datetime import datetime, timezone
+from typing import Protocol
+
+from app.clients.k8s import resolve_alert_target
+from app.clients.report_sink import ReportSink
+from app.core.concurrency import run_in_thread_limited
+from app.core.correlation import resolve_correlation_id, use_correlation_id
+from app.core.masking import Masker, RegexMasker
+from app.schemas.analysis import AlertAnalysisRequest
+from app.services.group_analysis import fallback_group_summary, shared_dimensions
+
+logger = logging.getLogger(__name__)
+
+_RATE_WINDOW_SECONDS = 60.0
+_TOP_ALERTNAMES = 5
+
+
+class _AlertAnalyzer(Protocol):
+    def analyze(
+        self, request: AlertAnalysisRequest
+    ) -> tuple[str, str, str, dict[str, object], list[dict[str, object]]]: ...
+
+
+@dataclass
+class _StormGroup:
+    alerts: dict[str, dict[str, object]] = field(default_factory=dict)
+    received: int = 0
+    pending: bool = False
+
+
+class AlertStormGuard:
+    """Compressed analysis while alerts arrive faster than ``alerts_per_minute``.
+
+    During a storm ``/analyze`` answers at once without an analysis. Alerts
+    are grouped per cluster (``cluster`` label) and namespace, and every
+    tick sends one correlated summary per group that received new alerts
+    (report type ``alert_storm_summary``). The storm ends once the rate has
+    stayed below the threshold for ``quiet_seconds``; the deferred alerts
+    (latest request per fingerprint, at most ``max_deferred``) are then
+    replayed by ``replay_deferred_analyses`` and delivered as
+    ``deferred_analysis`` reports. Requests carrying an inline caller token
+    are not kept: the token would outlive the request, and replaying them
+    with the agent's own credentials would widen their access.
+    """
+
+    def __init__(
+        self,
+        analysis_service: _AlertAnalyzer,
+        sink: ReportSink,
+        *,
+        alerts_per_minute: int,
+        quiet_seconds: int = 120,
+        max_deferred: int = 500,
+        masker: Masker | None = None,
+        clock: Callable[[], float] = time.monotonic,
+    ) -> None:
+        self._analysis_service = analysis_service
+        self._sink = sink
+        self._masker = masker or RegexMasker()
+        self._threshold = alerts_per_minute
+        self._quiet_seconds = quiet_seconds
+        self._max_deferred = max(1, max_deferred)
+        self._clock = clock
+        self._lock = threading.Lock()
+        self._arrivals: deque[float] = deque()
+        self._active = False
+        self._started_at: str | None = None
+        self._last_above = 0.0
+        self._groups: dict[tuple[str, str], _StormGroup] = {}
+        self._deferred: dict[str, AlertAnalysisRequest] = {}
+        self._replay: deque[AlertAnalysisRequest] = deque()
+        self._dropped = 0
+
+    @property
+    def enabled(self) -> bool:
+        return self._threshold > 0
+
+    def admit(self, request: AlertAnalysisRequest) -> dict[str, object] | None:
+        """Count an incoming alert; returns storm details when its analysis is deferred."""
+        now = self._clock()
+        with self._lock:
+            self._arrivals.append(now)
+            rate = self._rate(now)
+            if rate >= self._threshold:
+                self._last_above = now
+                if not self._active:
+                    self._active = True
+                    self._started_at = datetime.now(timezone.utc).isoformat()
+                    logger.warning("Alert storm started: %d alerts in the last minute", rate)
+            if not self._active:
+                return None
+
+            alert = request.alert
+            target = resolve_alert_target(alert.labels)
+            cluster = alert.labels.get("cluster", "")
+            namespace = target.namespace or ""
+            group = self._groups.setdefault((cluster, namespace), _StormGroup())
+            key = _alert_key(request)
+            group.alerts[key] = {
+                "alertname": alert.labels.get("alertname"),
+                "status": alert.status,
+                "namespace": target.namespace,
+                "workload": target.workload,
+                "node": alert.labels.get("node"),
+            }
+            group.received += 1
+            group.pending = True
+            # The deferral is answered with the ID the later deferred_analysis report carries;
+            # repeats of a queued alert keep the ID of the first deferral.
+            earlier = self._deferred.get(key)
+            correlation_id = resolve_correlation_id(
+                request.correlation_id or (earlier.correlation_id if earlier else None)
+            )
+            # The latest request of a repeating alert replaces the earlier one.
+            queued = not _has_inline_token(request) and (
+                earlier is not None or len(self._deferred) < self._max_deferred
+            )
+            if queued:
+                self._deferred[key] = request.model_copy(update={"correlation_id": correlation_id})
+            else:
+                self._dropped += 1
+            return {
+                "active": True,
+                "started_at": self._started_at,
+                "alerts_per_minute": rate,
+                "cluster": cluster or None,
+                "namespace": namespace or None,
+                "group_alerts": len(group.alerts),
+                "queued": queued,
+                "correlation_id": correlation_id,
+            }
+
+    def status(self) -> dict[str, object]:
+        with self._lock:
+            return {
+                "active": self._active,
+                "started_at": self._started_at,
+                "alerts_per_minute": self._rate(self._clock()),
+                "threshold": self._threshold,
+                "groups": len(self._groups),
+                "deferred": len(self._deferred),
+                "replaying": len(self._replay),
+                "dropped": self._dropped,
+            }
+
+    def tick(self) -> dict[str, object]:
+        """Send summaries of groups with new alerts; after a storm, queue the deferred analyses."""
+        now = self._clock()
+        with self._lock:
+            ended = (
+                self._active
+                and self._rate(now) < self._threshold
+                and now - self._last_above >= self._quiet_seconds
+            )
+            summaries = [
+                _group_summary(cluster, namespace, group, final=ended)
+                for (cluster, namespace), group in self._groups.items()
+                if group.pending
+            ]
+            for group in self._groups.values():
+                group.pending = False
+            dropped = 0
+            if ended:
+                logger.info("Alert storm ended; %d deferred analyses", len(self._deferred))
+                self._active = False
+                self._groups = {}
+                self._replay.extend(self._deferred.values())
+                dropped = self._dropped
+                self._deferred = {}
+                self._dropped = 0
+            replaying = len(self._replay)
+
+        for summary in summaries:
+            self._sink.send("alert_storm_summary", self._masker.mask_object(summary))
+        return {"summaries": len(summaries), "deferred": replaying, "dropped": dropped}
+
+    def next_deferred(self) -> AlertAnalysisRequest | None:
+        """The next deferred analysis to replay, ``None`` when done or a new storm started."""
+        with self._lock:
+            if self._active:
+                # A new storm started: keep the rest for after it.
+                for pending in self._replay:
+                    self._deferred.setdefault(_alert_key(pending), pending)
+                self._replay.clear()
+            return self._replay.popleft() if self._replay else None
+
+    def analyze_deferred(self, request: AlertAnalysisRequest) -> None:
+        # The report is sent under the analysis' ID, so it goes out as X-Correlation-ID.
+        with use_correlation_id(request.correlation_id or ""):
+            try:
+                analysis, summary, detail, context, _ = self._analysis_service.analyze(request)
+            except Exception as exc:  # noqa: BLE001
+                logger.warning("Deferred analysis failed: %s", exc)
+                return
+            self._sink.send(
+                "deferred_analysis",
+                {
+                    "thread_ts": request.thread_ts,
+                    "incident_id": request.incident_id,
+                    "fingerprint": request.alert.fingerprint,
+                    "alertname": request.alert.labels.get("alertname"),
+                    "status": request.alert.status,
+                    "analysis_id": context.get("analysis_id"),
+                    "correlation_id": request.correlation_id,
+                    "analysis": analysis,
+                    "analysis_summary": summary,
+                    "analysis_detail": detail,
+                    "degraded": context.get("degraded") is True,
+                },
+            )
+
+    def _rate(self, now: float) -> int:
+        while self._arrivals and now - self._arrivals[0] > _RATE_WINDOW_SECONDS:
+            self._arrivals.popleft()
+        return len(self._arrivals)
+
+
+def _has_inline_token(request: AlertAnalysisRequest) -> bool:
+    credentials = request.cluster_credentials
+    return credentials is not None and credentials.token is not None
+
+
+def _alert_key(request: AlertAnalysisRequest) -> str:
+    alert = request.alert
+    return alert.fingerprint or repr(sorted(alert.labels.items()))
+
+
+def _group_summary(
+    cluster: str, namespace: str, group: _StormGroup, *, final: bool
+) -> dict[str, object]:
+    members = list(group.alerts.values())
+    shared = shared_dimensions(members)
+    alertnames = Counter(str(member.get("alertname") or "unknown") for member in members)
+    return {
+        "cluster": cluster or None,
+        "namespace": namespace or None,
+        "alert_count": len(members),
+        "received": group.received,
+        "firing": sum(1 for member in members if member.get("status") == "firing"),
+        "alertnames": dict(alertnames.most_common(_TOP_ALERTNAMES)),
+        "shared": shared,
+        "summary": fallback_group_summary(members, shared, len(members)),
+        "final": final,
+    }
+
+
+async def replay_deferred_analyses(guard: AlertStormGuard) -> int:
+    """Run the deferred analyses of *guard* one at a time under the analysis limiter.
+
+    Each analysis takes a slot like an ``/analyze`` request, so replays wait
+    for memory pressure and leave the other slots to live alerts.
+    """
+    analyzed = 0
+    while (request := guard.next_deferred()) is not None:
+        await run_in_thread_limited(guard.analyze_deferred, request)
+        analyzed += 1
+    return analyzed
+
+
+async def run_alert_storm_monitor(guard: AlertStormGuard, interval_seconds: int) -> None:
+    """Tick *guard* every *interval_seconds* until cancelled.
+
+    Deferred analyses are replayed in a separate task, so summaries of a new
+    storm keep their interval.
+    """
+    replay: asyncio.Task[int] | None = None
+    try:
+        while True:
+            await asyncio.sleep(interval_seconds)
+            try:
+                result = await asyncio.to_thread(guard.tick)
+            except Exception as exc:  # noqa: BLE001
+                logger.warning("Alert storm tick failed: %s", exc)
+                continue
+            if result["deferred"] and (replay is None or replay.done()):
+                replay = asyncio.create_task(replay_deferred_analyses(guard))
+    finally:
+        if replay is not None:
+            replay.cancel()
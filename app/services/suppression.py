@@ -0,0 +1,192 @@
+from __future__ import annotations
+
+import logging
+import re
+from collections.abc import Callable
+from datetime import datetime, timedelta, timezone
+
+from app.clients.k8s import resolve_alert_target
+from app.clients.suppression_store import Suppression, SuppressionStore
+from app.schemas.analysis import AlertAnalysisRequest
+
+logger = logging.getLogger(__name__)
+
+# PromQL/Alertmanager matcher operators; regexes are anchored like Alertmanager's.
+OPERATORS = ("=", "!=", "=~", "!~")
+
+
+class SuppressionService:
+    """Temporary suppression windows for alerts that should not be analyzed.
+
+    A suppression is a set of label matchers and a time window, e.g. the
+    alerts of a namespace during a planned migration. ``/analyze`` answers
+    alerts matching an active window with a ``suppressed`` status instead of
+    analyzing them, and each suppressed alert is recorded against the window.
+    """
+
+    def __init__(
+        self,
+        store: SuppressionStore,
+        *,
+        max_duration_minutes: int,
+        clock: Callable[[], datetime] = lambda: datetime.now(timezone.utc),
+    ) -> None:
+        self._store = store
+        self._max_duration = timedelta(minutes=max(1, max_duration_minutes))
+        self._clock = clock
+
+    def create(
+        self,
+        matchers: list[dict[str, str]],
+        *,
+        duration_minutes: int,
+        reason: str,
+        created_by: str | None,
+        starts_at: datetime | None = None,
+    ) -> dict[str, object]:
+        """Raises ``ValueError`` for invalid matchers or a window that is too long."""
+        validate_matchers(matchers)
+        if not reason.strip():
+            raise ValueError("a reason is required")
+        duration = timedelta(minutes=duration_minutes)
+        if duration <= timedelta(0):
+            raise ValueError("duration_minutes must be positive")
+        if duration > self._max_duration:
+            raise ValueError(
+                f"duration exceeds the maximum of {int(self._max_duration.total_seconds() // 60)} "
+                "minutes"
+            )
+        now = self._clock()
+        start = max(starts_at, now) if starts_at is not None else now
+        suppression = self._store.create(
+            [_normalize(matcher) for matcher in matchers],
+            reason=reason.strip(),
+            created_by=created_by,
+            starts_at=start,
+            ends_at=start + duration,
+        )
+        logger.info(
+            "suppression_created id=%s by=%s until=%s matchers=%s",
+            suppression.suppression_id,
+            created_by,
+            suppression.ends_at.isoformat(),
+            _describe(suppression.matchers),
+        )
+        return suppression.to_dict()
+
+    def expire(self, suppression_id: int, *, expired_by: str | None) -> dict[str, object] | None:
+        suppression = self._store.expire(suppression_id, expired_by=expired_by, at=self._clock())
+        if suppression is None:
+            return None
+        logger.info("suppression_expired id=%s by=%s", suppression_id, expired_by)
+        return suppression.to_dict()
+
+    def list_suppressions(
+        self, *, include_expired: bool = False, limit: int = 100
+    ) -> list[dict[str, object]]:
+        now = self._clock()
+        suppressions = self._store.list_suppressions(
+            active_at=None if include_expired else now, limit=limit
+        )
+        return [{**item.to_dict(), "active": item.active(now)} for item in suppressions]
+
+    def get(self, suppression_id: int, *, hit_limit: int = 50) -> dict[str, object] | None:
+        suppression = self._store.get(suppression_id)
+        if suppression is None:
+            return None
+        return {
+            **suppression.to_dict(),
+            "active": suppression.active(self._clock()),
+            "recent_hits": self._store.list_hits(suppression_id, limit=hit_limit),
+        }
+
+    def match(self, request: AlertAnalysisRequest) -> dict[str, object] | None:
+        """The active suppression matching the alert, recorded as a hit; else ``None``.
+
+        Suppression is fail-open: when the store cannot be read the alert is
+        analyzed as usual.
+        """
+        labels = request.alert.labels
+        now = self._clock()
+        try:
+            active = self._store.list_suppressions(active_at=now)
+        except Exception as exc:  # noqa: BLE001
+            logger.warning("Failed to read suppressions, analyzing the alert: %s", exc)
+            return None
+        suppression = next((item for item in active if matches(item.matchers, labels)), None)
+        if suppression is None:
+            return None
+        try:
+            self._store.record_hit(
+                suppression.suppression_id,
+                alertname=labels.get("alertname"),
+                namespace=resolve_alert_target(labels).namespace,
+                fingerprint=request.alert.fingerprint,
+                at=now,
+            )
+        except Exception as exc:  # noqa: BLE001
+            logger.warning(
+                "Failed to record suppression hit id=%s: %s", suppression.suppression_id, exc
+            )
+        logger.info(
+            "alert_suppressed id=%s alertname=%s",
+            suppression.suppression_id,
+            labels.get("alertname"),
+        )
+        return _response_details(suppression)
+
+
+def validate_matchers(matchers: list[dict[str, str]]) -> None:
+    """Raises ``ValueError`` unless every matcher is valid and the set cannot match everything.
+
+    Like Alertmanager silences, at least one matcher must not match an empty
+    (missing) label, so a suppression never covers every alert.
+    """
+    if not matchers:
+        raise ValueError("at least one matcher is required")
+    for matcher in matchers:
+        if not str(matcher.get("name") or "").strip():
+            raise ValueError("matcher name is required")
+        operator = matcher.get("operator", "=")
+        if operator not in OPERATORS:
+            raise ValueError(f"unsupported matcher operator {operator!r}")
+        if operator in ("=~", "!~"):
+            try:
+                re.compile(str(matcher.get("value", "")))
+            except re.error as exc:
+                raise ValueError(f"invalid regex for {matcher['name']}: {exc}") from exc
+    if all(_matches(_normalize(matcher), "") for matcher in matchers):
+        raise ValueError("at least one matcher must not match an empty label")
+
+
+def matches(matchers: list[dict[str, str]], labels: dict[str, str]) -> bool:
+    return all(_matches(matcher, labels.get(matcher["name"], "")) for matcher in matchers)
+
+
+def _matches(matcher: dict[str, str], value: str) -> bool:
+    operator = matcher["operator"]
+    if operator in ("=", "!="):
+        return (value == matcher["value"]) == (operator == "=")
+    return (re.fullmatch(matcher["value"], value) is not None) == (operator == "=~")
+
+
+def _normalize(matcher: dict[str, str]) -> dict[str, str]:
+    return {
+        "name": str(matcher["name"]).strip(),
+        "operator": matcher.get("operator", "="),
+        "value": str(matcher.get("value", "")),
+    }
+
+
+def _describe(matchers: list[dict[str, str]]) -> str:
+    return ",".join(f'{item["name"]}{item["operator"]}"{item["value"]}"' for item in matchers)
+
+
+def _response_details(suppression: Suppression) -> dict[str, object]:
+    return {
+        "suppression_id": suppression.suppression_id,
+        "reason": suppression.reason,
+        "created_by": suppression.created_by,
+        "ends_at": suppression.ends_at.isoformat(),
+        "matchers": suppression.matchers,
+    }
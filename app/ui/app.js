@@ -0,0 +1,140 @@
+// Read-only browser for stored analyses. All stored text is rendered with
+// textContent, never as HTML: analyses quote alert labels, logs and events.
+"use strict";
+
+const state = { before: null, active: null };
+const $ = (id) => document.getElementById(id);
+
+function el(tag, text, className) {
+  const node = document.createElement(tag);
+  if (text !== undefined && text !== null) node.textContent = String(text);
+  if (className) node.className = className;
+  return node;
+}
+
+async function api(path) {
+  const token = sessionStorage.getItem("kube-rca-token") || "";
+  const headers = token ? { Authorization: `Bearer ${token}` } : {};
+  const response = await fetch(path, { headers });
+  if (!response.ok) {
+    let detail = response.statusText;
+    try { detail = (await response.json()).detail || detail; } catch (_) { /* not JSON */ }
+    if (response.status === 401 || response.status === 403) {
+      detail += " (set a bearer token above)";
+    }
+    throw new Error(`${response.status}: ${detail}`);
+  }
+  return response.json();
+}
+
+async function loadList(reset) {
+  if (reset) {
+    state.before = null;
+    $("items").replaceChildren();
+  }
+  const params = new URLSearchParams({ limit: "50" });
+  for (const key of ["alertname", "namespace"]) {
+    const value = $(key).value.trim();
+    if (value) params.set(key, value);
+  }
+  if (state.before) params.set("before", String(state.before));
+  $("error").textContent = "";
+  try {
+    const page = await api(`/ui/api/analyses?${params}`);
+    for (const item of page.analyses) $("items").append(listItem(item));
+    if (!page.analyses.length && reset) $("items").append(el("p", "No stored analyses.", "muted"));
+    state.before = page.next_before;
+    $("more").hidden = !page.next_before;
+  } catch (error) {
+    $("error").textContent = error.message;
+  }
+}
+
+function listItem(item) {
+  const node = el("div", null, "item");
+  const title = el("div");
+  title.append(el("strong", item.alertname || "(no alertname)"));
+  title.append(el("span", item.alert_status, `badge ${item.alert_status || ""}`));
+  if (item.degraded) title.append(el("span", "degraded", "badge"));
+  if (item.source !== "live") title.append(el("span", item.source, "badge"));
+  node.append(title);
+  node.append(el("div", [item.namespace, item.pod_name].filter(Boolean).join("/"), "meta"));
+  node.append(el("div", item.summary));
+  node.append(el("div", `${formatTime(item.created_at)} · ${item.pipeline_version || ""}`, "meta"));
+  node.addEventListener("click", () => {
+    document.querySelectorAll("#list .item.active").forEach((n) => n.classList.remove("active"));
+    node.classList.add("active");
+    location.hash = String(item.result_id);
+  });
+  return node;
+}
+
+async function loadDetail(resultId) {
+  const detail = $("detail");
+  detail.replaceChildren(el("p", "Loading...", "muted"));
+  try {
+    const analysis = await api(`/ui/api/analyses/${encodeURIComponent(resultId)}`);
+    detail.replaceChildren(...renderDetail(analysis));
+  } catch (error) {
+    detail.replaceChildren(el("p", error.message, "muted"));
+  }
+}
+
+function renderDetail(analysis) {
+  const nodes = [];
+  const heading = el("h2", analysis.alertname || "(no alertname)");
+  heading.append(el("span", analysis.alert_status, `badge ${analysis.alert_status || ""}`));
+  nodes.push(heading);
+  nodes.push(el("p", [
+    [analysis.namespace, analysis.pod_name].filter(Boolean).join("/"),
+    `stored ${formatTime(analysis.created_at)}`,
+    `pipeline ${analysis.pipeline_version || "-"}`,
+    analysis.analysis_id,
+  ].filter(Boolean).join(" · "), "muted"));
+  nodes.push(el("h3", "Summary"), el("pre", analysis.analysis_summary || analysis.analysis || ""));
+  if (analysis.analysis_detail) {
+    nodes.push(section("Detail", el("pre", analysis.analysis_detail), true));
+  }
+  if (analysis.timeline.length) {
+    const table = el("table");
+    for (const entry of analysis.timeline) {
+      const row = el("tr");
+      row.append(el("td", formatTime(entry.time), "time"), el("td", entry.kind, "muted"), el("td", entry.text));
+      table.append(row);
+    }
+    nodes.push(el("h3", "Timeline"), table);
+  }
+  nodes.push(el("h3", "Evidence"));
+  for (const [key, value] of Object.entries(analysis.evidence)) {
+    nodes.push(section(key, el("pre", JSON.stringify(value, null, 2)), false));
+  }
+  const notes = [...analysis.warnings, ...analysis.missing_data.map((item) => `missing: ${item}`)];
+  if (notes.length) nodes.push(section("Warnings", el("pre", notes.join("\n")), false));
+  nodes.push(section("Alert labels and annotations", el("pre", JSON.stringify(
+    { labels: analysis.labels, annotations: analysis.annotations }, null, 2)), false));
+  if (analysis.artifacts.length) {
+    nodes.push(section("Artifacts", el("pre", JSON.stringify(analysis.artifacts, null, 2)), false));
+  }
+  return nodes;
+}
+
+function section(title, body, open) {
+  const details = el("details");
+  details.open = open;
+  details.append(el("summary", title), body);
+  return details;
+}
+
+function formatTime(value) {
+  if (!value) return "";
+  const date = new Date(value);
+  return Number.isNaN(date.getTime()) ? String(value) : date.toLocaleString();
+}
+
+$("token").value = sessionStorage.getItem("kube-rca-token") || "";
+$("token").addEventListener("change", () => sessionStorage.setItem("kube-rca-token", $("token").value.trim()));
+$("search").addEventListener("click", () => loadList(true));
+$("more").addEventListener("click", () => loadList(false));
+window.addEventListener("hashchange", () => location.hash && loadDetail(location.hash.slice(1)));
+loadList(true);
+if (location.hash) loadDetail(location.hash.slice(1));
@@ -0,0 +1,99 @@
+from __future__ import annotations
+
+from app.schemas.analysis import AlertmanagerValidationResponse
+from app.services.alert_validation import validate_alertmanager_payload
+
+_WEBHOOK = {
+    "version": "4",
+    "groupKey": '{}:{alertname="KubePodCrashLooping"}',
+    "status": "firing",
+    "receiver": "kube-rca",
+    "groupLabels": {"alertname": "KubePodCrashLooping"},
+    "commonLabels": {"alertname": "KubePodCrashLooping"},
+    "externalURL": "http://alertmanager:9093",
+    "alerts": [
+        {
+            "status": "firing",
+            "labels": {
+                "alertname": "KubePodCrashLooping",
+                "namespace": "payments",
+                "pod": "api-0",
+            },
+            "annotations": {"summary": "Pod is crash looping"},
+            "startsAt": "2026-02-06T18:20:00Z",
+            "endsAt": "0001-01-01T00:00:00Z",
+            "generatorURL": "http://prometheus:9090/graph",
+            "fingerprint": "abc123",
+        },
+        {
+            "status": "resolved",
+            "labels": {"alertname": "HighLatency"},
+            "startsAt": "2026-02-06T18:00:00Z",
+            "endsAt": "0001-01-01T00:00:00Z",
+            "silenceURL": "http://alertmanager:9093/#/silences/new",
+        },
+    ],
+}
+
+
+def test_validate_webhook_maps_alerts_and_reports_gaps() -> None:
+    result = validate_alertmanager_payload(_WEBHOOK)
+
+    assert result["valid"] is True
+    assert result["payload_format"] == "alertmanager_webhook"
+    assert result["ignored_fields"] == [
+        "commonLabels",
+        "externalURL",
+        "groupKey",
+        "groupLabels",
+        "receiver",
+        "status",
+        "version",
+    ]
+    first, second = result["alerts"]  # type: ignore[misc]
+    assert first["target"] == {
+        "namespace": "payments",
+        "pod_name": "api-0",
+        "workload": None,
+        "service_name": None,
+    }
+    assert first["session_key"] == "alert:abc123"
+    assert first["missing_fields"] == []
+    assert second["missing_fields"] == ["fingerprint", "labels.namespace"]
+    assert second["ignored_fields"] == ["silenceURL"]
+    assert any("endsAt" in warning for warning in second["warnings"])
+    assert second["session_key"] == "alert:HighLatency"
+    AlertmanagerValidationResponse.model_validate(result)
+
+
+def test_validate_reports_invalid_alerts() -> None:
+    result = validate_alertmanager_payload({"alerts": [{"labels": {"a": "b"}}, "oops"]})
+
+    assert result["valid"] is False
+    first, second = result["alerts"]  # type: ignore[misc]
+    assert first["errors"] == ["status: Field required"]
+    assert second["errors"] == ["alert must be a JSON object"]
+
+
+def test_validate_accepts_analyze_request_body() -> None:
+    result = validate_alertmanager_payload(
+        {
+            "alert": {"status": "firing", "labels": {"namespace": "payments"}},
+            "thread_ts": "1234567890.123456",
+            "incident_id": "inc-1",
+            "channel": "#alerts",
+        }
+    )
+
+    assert result["payload_format"] == "analyze_request"
+    assert result["ignored_fields"] == ["channel"]
+    [mapped] = result["alerts"]  # type: ignore[misc]
+    assert mapped["session_key"] == "inc-1:payments"
+
+
+def test_validate_rejects_unknown_payloads() -> None:
+    result = validate_alertmanager_payload({"foo": "bar"})
+
+    assert result["valid"] is False
+    assert result["payload_format"] == "unknown"
+    assert result["ignored_fields"] == ["foo"]
@@ -0,0 +1,148 @@
+from __future__ import annotations
+
+from app.services.analysis_comparison import (
+    compare_stored_analyses,
+    describe_version,
+    stored_conclusions,
+)
+
+
+def _rule(rule: str, severity: str, title: str) -> dict[str, object]:
+    return {
+        "type": "rule_finding",
+        "summary": f"[{severity}] {title}",
+        "result": {"rule": rule, "severity": severity, "title": title, "evidence": []},
+    }
+
+
+def _hypothesis(rank: int, key: str, verdict: str, confidence: float) -> dict[str, object]:
+    return {"rank": rank, "key": key, "title": key, "verdict": verdict, "confidence": confidence}
+
+
+def _row(result_id: int, **context: object) -> dict[str, object]:
+    artifacts = context.pop("artifacts", [])
+    return {
+        "result_id": result_id,
+        "session_key": "alert:abc123",
+        "analysis_id": f"alert:abc123:run:{result_id}",
+        "alertname": "KubePodCrashLooping",
+        "namespace": "shop",
+        "alert_status": "firing",
+        "pipeline_version": "v2" if result_id > 1 else "v1",
+        "source": "backfill" if result_id > 1 else "live",
+        "backfill_job_id": "job-1" if result_id > 1 else None,
+        "created_at": "2026-10-14T09:20:00+00:00",
+        "result": {
+            "analysis": "full text",
+            "analysis_summary": context.pop("summary", "checkout crashes on startup"),
+            "context": {"analysis_quality": "high", "degraded": False, **context},
+            "artifacts": artifacts,
+        },
+    }
+
+
+def test_compare_stored_analyses_reports_changed_conclusions() -> None:
+    base = _row(
+        1,
+        summary="checkout crashes because of a missing env var",
+        crash_loop={"category": "config", "findings": ["DATABASE_URL is not set"]},
+        hypotheses=[
+            _hypothesis(1, "config", "supported", 0.8),
+            _hypothesis(2, "oom", "refuted", 0.7),
+        ],
+        artifacts=[_rule("crash_loop_back_off", "high", "Container is crash looping")],
+    )
+    target = _row(
+        2,
+        summary="checkout crashes because the database is unreachable",
+        crash_loop={"findings": ["DATABASE_URL is not set", "connection refused to db:5432"]},
+        dns_analysis={"verdict": "degraded", "findings": []},
+        hypotheses=[
+            _hypothesis(1, "config", "inconclusive", 0.4),
+            _hypothesis(2, "dns", "supported", 0.6),
+        ],
+        artifacts=[
+            _rule("crash_loop_back_off", "critical", "Container is crash looping"),
+            _rule("cluster_dns_unhealthy", "high", "Cluster DNS is unhealthy"),
+        ],
+    )
+
+    diff = compare_stored_analyses(base, target)
+
+    assert diff["identical"] is False
+    assert diff["base"] == {
+        "result_id": 1,
+        "pipeline_version": "v1",
+        "source": "live",
+        "backfill_job_id": None,
+        "rollout_arm": None,
+        "created_at": "2026-10-14T09:20:00+00:00",
+    }
+    changes = diff["changes"]
+    assert isinstance(changes, dict)
+    assert set(changes) == {"summary", "rules", "hypotheses", "root_causes", "findings"}
+    assert changes["rules"] == {
+        "added": [
+            {
+                "rule": "cluster_dns_unhealthy",
+                "severity": "high",
+                "title": "Cluster DNS is unhealthy",
+            }
+        ],
+        "removed": [],
+        "changed": [
+            {
+                "rule": "crash_loop_back_off",
+                "base": {"severity": "high", "title": "Container is crash looping"},
+                "target": {"severity": "critical", "title": "Container is crash looping"},
+            }
+        ],
+    }
+    hypotheses = changes["hypotheses"]
+    assert [item["key"] for item in hypotheses["added"]] == ["dns"]
+    assert [item["key"] for item in hypotheses["removed"]] == ["oom"]
+    assert hypotheses["changed"] == [
+        {
+            "key": "config",
+            "base": {"verdict": "supported", "confidence": 0.8, "rank": 1},
+            "target": {"verdict": "inconclusive", "confidence": 0.4, "rank": 1},
+        }
+    ]
+    assert changes["root_causes"] == {"dns_analysis": {"base": None, "target": "degraded"}}
+    assert changes["findings"] == {
+        "crash_loop": {"added": ["connection refused to db:5432"], "removed": []}
+    }
+
+
+def test_compare_stored_analyses_of_the_same_conclusions_is_identical() -> None:
+    context = {
+        "probe_analysis": {"root_cause": "misconfigured_probe", "findings": ["path returns 404"]},
+        "artifacts": [_rule("probe_failure", "high", "Health probes are misconfigured")],
+    }
+
+    diff = compare_stored_analyses(_row(1, **dict(context)), _row(2, **dict(context)))
+
+    assert diff["identical"] is True
+    assert diff["changes"] == {}
+    assert stored_conclusions(_row(1, **dict(context)))["root_causes"] == {
+        "probe_analysis": "misconfigured_probe"
+    }
+
+
+def test_describe_version_lists_rules_and_root_causes() -> None:
+    row = _row(
+        3,
+        rollout_arm="canary",
+        image_pull={"cause": "auth_failed"},
+        artifacts=[_rule("image_pull_failure", "high", "Image cannot be pulled")],
+    )
+
+    version = describe_version(row)
+
+    assert version["result_id"] == 3
+    assert version["source"] == "backfill"
+    assert version["backfill_job_id"] == "job-1"
+    assert version["rollout_arm"] == "canary"
+    assert version["rules"] == ["image_pull_failure"]
+    assert version["root_causes"] == {"image_pull": "auth_failed"}
+    assert version["summary"] == "checkout crashes on startup"
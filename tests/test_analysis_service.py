@@ -1,10 +1,30 @@
 from __future__ import annotations
 
 import json
+import re
+import threading
+import time
+from collections.abc import Callable
+from dataclasses import replace
 from datetime import datetime, timedelta, timezone
+from pathlib import Path
 
+import pytest
+
+from app.api.analysis import _CONTEXT_MODELS
+from app.clients.analysis_store import StoredAnalysis
 from app.clients.k8s import resolve_alert_target
+from app.core.deadline import bounded_timeout, remaining_seconds
+from app.core.k8s_clusters import current_cluster, init_clusters
+from app.core.k8s_credentials import (
+    ClusterCredentialError,
+    caller_credentials_active,
+    init_credential_passthrough,
+)
 from app.core.masking import RegexMasker
+from app.core.overrides import init_analysis_overrides
+from app.core.pipeline import PipelineRegistry
+from app.core.slo import LatencySloTracker
 from app.models.k8s import (
     AnalysisTarget,
     K8sContext,
@@ -16,20 +36,119 @@ from app.schemas.alert import Alert
 from app.schemas.analysis import (
     AlertAnalysisRequest,
     AlertSummaryInput,
+    AnalysisFollowupRequest,
+    ClusterCredentials,
     IncidentSummaryRequest,
+    NodeHealth,
     PreviousAnalysisContext,
 )
 from app.services.analysis import (
+    _CONTEXT_ANALYSES,
+    AnalysisNotFoundError,
     AnalysisService,
     _categorize_analysis_error,
     _extract_first_paragraph,
     _parse_incident_summary,
+    _parse_recovery_validation,
 )
+from app.services.canary import CanaryRollout
+from app.services.closure import IncidentClosureTracker
+from app.services.hypotheses import HypothesisInvestigator
 
 
 class FakeKubernetesClient:
-    def __init__(self, context: K8sContext) -> None:
+    def __init__(
+        self,
+        context: K8sContext,
+        nodes: dict[str, dict[str, object]] | None = None,
+        pod_metrics: dict[str, object] | None = None,
+    ) -> None:
         self._context = context
+        self._nodes = nodes or {}
+        self._pod_metrics = pod_metrics
+        self.node_calls: list[str] = []
+        self.pod_metrics_calls: list[tuple[str, str]] = []
+        self.volume_claims: dict[str, dict[str, object]] = {}
+        self.volume_stats: dict[str, list[dict[str, object]]] = {}
+        self.volume_claim_calls: list[tuple[str, str]] = []
+        self.hpa_status: dict[str, object] | None = None
+        self.hpa_calls: list[tuple[str, str | None, str | None]] = []
+        self.node_metrics: dict[str, dict[str, object]] = {}
+        self.node_metrics_calls: list[str] = []
+        self.network_connectivity: dict[str, object] | None = None
+        self.network_connectivity_calls: list[tuple[str, str, str, str]] = []
+        self.service_endpoints: dict[str, object] | None = None
+        self.service_endpoints_calls: list[tuple[str, str]] = []
+        self.job_run: dict[str, object] | None = None
+        self.job_run_calls: list[tuple[str, str | None, str | None]] = []
+        self.cluster_dns: dict[str, object] | None = None
+        self.cluster_dns_calls: list[tuple[str, str, int]] = []
+        self.namespace_quotas: dict[str, object] | None = None
+        self.namespace_quota_calls: list[str] = []
+
+    def get_node_status(self, node_name: str) -> dict[str, object] | None:
+        self.node_calls.append(node_name)
+        return self._nodes.get(node_name)
+
+    def get_pod_metrics(self, namespace: str, pod_name: str) -> dict[str, object] | None:
+        self.pod_metrics_calls.append((namespace, pod_name))
+        return self._pod_metrics
+
+    def get_node_resource_usage(self, node_name: str) -> dict[str, object] | None:
+        self.node_metrics_calls.append(node_name)
+        return self.node_metrics.get(node_name)
+
+    def get_volume_claim_status(
+        self, namespace: str, claim_name: str
+    ) -> dict[str, object] | None:
+        self.volume_claim_calls.append((namespace, claim_name))
+        return self.volume_claims.get(claim_name)
+
+    def get_volume_stats(self, node_name: str) -> list[dict[str, object]] | None:
+        return self.volume_stats.get(node_name)
+
+    def get_hpa_status(
+        self,
+        namespace: str,
+        *,
+        workload: str | None = None,
+        pod_name: str | None = None,
+    ) -> dict[str, object] | None:
+        self.hpa_calls.append((namespace, workload, pod_name))
+        return self.hpa_status
+
+    def get_network_connectivity(
+        self,
+        namespace: str,
+        pod_name: str,
+        *,
+        destination_namespace: str,
+        destination_service: str,
+    ) -> dict[str, object] | None:
+        self.network_connectivity_calls.append(
+            (namespace, pod_name, destination_namespace, destination_service)
+        )
+        return self.network_connectivity
+
+    def get_service_endpoints(self, namespace: str, service: str) -> dict[str, object] | None:
+        self.service_endpoints_calls.append((namespace, service))
+        return self.service_endpoints
+
+    def get_job_run(
+        self, namespace: str, *, job: str | None = None, cron_job: str | None = None
+    ) -> dict[str, object] | None:
+        self.job_run_calls.append((namespace, job, cron_job))
+        return self.job_run
+
+    def get_cluster_dns_status(
+        self, namespace: str, service: str, *, since_seconds: int
+    ) -> dict[str, object] | None:
+        self.cluster_dns_calls.append((namespace, service, since_seconds))
+        return self.cluster_dns
+
+    def get_namespace_quotas(self, namespace: str) -> dict[str, object] | None:
+        self.namespace_quota_calls.append(namespace)
+        return self.namespace_quotas
 
     def collect_context(
         self,
@@ -59,6 +178,7 @@ class FakeKubernetesClient:
             node_status=self._context.node_status,
             service_manifest=self._context.service_manifest,
             endpoints_manifest=self._context.endpoints_manifest,
+            owner_chain=self._context.owner_chain,
         )
 
 
@@ -84,14 +204,18 @@ class FakeSummaryStore:
     def __init__(self, summaries: list[str]) -> None:
         self._summaries = summaries
         self.appended: list[tuple[str, str]] = []
+        self.namespaces: list[str | None] = []
         self.last_session_id: str | None = None
 
     def list_summaries(self, session_id: str, limit: int) -> list[str]:
         self.last_session_id = session_id
         return self._summaries[:limit]
 
-    def append_summary(self, session_id: str, summary: str, max_items: int) -> None:
+    def append_summary(
+        self, session_id: str, summary: str, max_items: int, namespace: str | None = None
+    ) -> None:
         self.appended.append((session_id, summary))
+        self.namespaces.append(namespace)
 
 
 class FakeTempoClient:
@@ -231,6 +355,87 @@ def test_analysis_service_fallback() -> None:
     assert capabilities.get("routing_evidence") == "unavailable"
 
 
+def test_analysis_service_collects_with_caller_credentials() -> None:
+    class CredentialRecordingClient(FakeKubernetesClient):
+        def collect_context(self, *args: object, **kwargs: object) -> K8sContext:
+            collected.append(caller_credentials_active())
+            return super().collect_context(*args, **kwargs)  # type: ignore[arg-type]
+
+    collected: list[bool] = []
+    context = K8sContext(
+        namespace="default",
+        pod_name="demo-pod",
+        workload=None,
+        pod_status=None,
+        events=[],
+        previous_logs=[],
+        warnings=[],
+    )
+    service = AnalysisService(
+        CredentialRecordingClient(context), analysis_engine=None, prometheus_enabled=False
+    )
+    request = _sample_request().model_copy(
+        update={"cluster_credentials": ClusterCredentials(token="caller-token")}
+    )
+
+    init_credential_passthrough(True)
+    try:
+        _, _, _, caller_context, _ = service.analyze(request)
+        _, _, _, agent_context, _ = service.analyze(_sample_request())
+    finally:
+        init_credential_passthrough(False)
+
+    assert collected == [True, False]
+    assert caller_context["k8s_credentials"] == "caller"
+    assert "k8s_credentials" not in agent_context
+    assert not caller_credentials_active()
+
+
+def test_analysis_service_selects_the_cluster_of_the_alert() -> None:
+    class ClusterRecordingClient(FakeKubernetesClient):
+        def collect_context(self, *args: object, **kwargs: object) -> K8sContext:
+            collected.append(current_cluster())
+            return super().collect_context(*args, **kwargs)  # type: ignore[arg-type]
+
+    collected: list[str | None] = []
+    context = K8sContext(
+        namespace="default",
+        pod_name="demo-pod",
+        workload=None,
+        pod_status=None,
+        events=[],
+        previous_logs=[],
+        warnings=[],
+    )
+    service = AnalysisService(
+        ClusterRecordingClient(context), analysis_engine=None, prometheus_enabled=False
+    )
+    request = _sample_request()
+
+    def labelled(cluster: str) -> AlertAnalysisRequest:
+        labels = {**request.alert.labels, "cluster": cluster}
+        return request.model_copy(
+            update={"alert": request.alert.model_copy(update={"labels": labels})}
+        )
+
+    init_clusters([("prod-eu", "/etc/kube-rca/prod-eu", "")], local_name="prod-us")
+    try:
+        _, _, _, remote_context, _ = service.analyze(labelled("prod-eu"))
+        _, _, _, local_context, _ = service.analyze(
+            labelled("prod-eu").model_copy(update={"cluster": "prod-us"})
+        )
+        _, _, _, unknown_context, _ = service.analyze(labelled("dev"))
+    finally:
+        init_clusters(())
+
+    assert collected == ["prod-eu", None, None]
+    assert remote_context["cluster"] == "prod-eu"
+    assert "cluster" not in local_context
+    assert unknown_context["warnings"][-1] == (  # type: ignore[index]
+        "cluster dev is not configured in K8S_CLUSTERS_JSON; analyzed in the agent's own cluster"
+    )
+
+
 def test_analysis_service_quality_high_with_only_optional_missing_data() -> None:
     context = K8sContext(
         namespace="default",
@@ -360,6 +565,31 @@ def test_analysis_service_prompt_without_prometheus() -> None:
     assert "For Prometheus queries" not in engine.last_prompt
 
 
+def test_analysis_service_prompt_includes_operator_instructions(tmp_path: Path) -> None:
+    overrides = tmp_path / "overrides.json"
+    overrides.write_text(json.dumps({"prompt_instructions": "Batch jobs restart nightly."}))
+    context = K8sContext(
+        namespace="default",
+        pod_name="demo-pod",
+        workload=None,
+        pod_status=None,
+        events=[],
+        previous_logs=[],
+        warnings=[],
+    )
+    engine = CapturingAnalysisEngine("ok")
+    service = AnalysisService(FakeKubernetesClient(context), analysis_engine=engine)
+
+    init_analysis_overrides(str(overrides))
+    try:
+        service.analyze(_sample_request())
+    finally:
+        init_analysis_overrides("")
+
+    assert "Operator instructions" in engine.last_prompt
+    assert "Batch jobs restart nightly." in engine.last_prompt
+
+
 def test_analysis_service_prompt_with_prometheus() -> None:
     context = K8sContext(
         namespace="default",
@@ -621,6 +851,36 @@ def test_analysis_service_trims_log_lines() -> None:
     assert "line-5" in engine.last_prompt
 
 
+def test_analysis_service_keeps_early_error_line_within_log_budget() -> None:
+    logs = ["starting worker", "ERROR config file /etc/app.yaml not found"]
+    logs += [f"heartbeat {idx}" for idx in range(10)]
+    context = K8sContext(
+        namespace="default",
+        pod_name="demo-pod",
+        workload=None,
+        pod_status=None,
+        events=[],
+        previous_logs=[
+            PodLogSnippet(container="app", previous=True, logs=logs),
+        ],
+        warnings=[],
+    )
+    engine = CapturingAnalysisEngine("ok")
+    service = AnalysisService(
+        FakeKubernetesClient(context),
+        analysis_engine=engine,
+        prometheus_enabled=False,
+        prompt_max_log_lines=3,
+    )
+
+    service.analyze(_sample_request())
+
+    assert "ERROR config file /etc/app.yaml not found" in engine.last_prompt
+    assert "heartbeat 9" in engine.last_prompt
+    assert "heartbeat 0" not in engine.last_prompt
+    assert "line(s) omitted" in engine.last_prompt
+
+
 def test_analysis_service_masks_prompt_response_and_store() -> None:
     secret = "token-123456"
     context = K8sContext(
@@ -1320,3 +1580,1733 @@ def test_analysis_service_error_categorization_integration() -> None:
     assert "analysis engine unavailable" in analysis
     assert "RuntimeError" in analysis
     assert ctx.get("analysis_quality") == "low"
+
+
+def test_analysis_service_marks_engine_failure_as_degraded_with_rule_findings() -> None:
+    context = K8sContext(
+        namespace="default",
+        pod_name="demo-pod",
+        workload=None,
+        pod_status=PodStatusSnapshot(
+            phase="Running",
+            node_name="node-1",
+            start_time=None,
+            reason=None,
+            message=None,
+            conditions=[],
+            container_statuses=[
+                {
+                    "name": "app",
+                    "ready": False,
+                    "restart_count": 3,
+                    "state": {"type": "running", "started_at": None},
+                    "last_state": {
+                        "type": "terminated",
+                        "reason": "OOMKilled",
+                        "message": None,
+                        "exit_code": "137",
+                    },
+                }
+            ],
+        ),
+        events=[],
+        previous_logs=[],
+        warnings=[],
+    )
+    service = AnalysisService(
+        FakeKubernetesClient(context),
+        analysis_engine=FailingAnalysisEngine(),
+    )
+
+    analysis, summary, _, ctx, artifacts = service.analyze(_sample_request())
+
+    assert "mode=degraded" in analysis
+    assert "oom_killed" in analysis
+    assert summary.startswith("[degraded] Container was OOMKilled")
+    assert ctx.get("degraded") is True
+    assert ctx.get("degraded_reason") == "unknown"
+    assert any(artifact["type"] == "rule_finding" for artifact in artifacts)
+    assert ctx["pod_diagnostics"]["containers"][0]["last_termination"]["exit_code"] == 137
+    assert ctx["oom_analysis"]["containers"][0]["name"] == "app"
+
+
+def test_analysis_service_reads_pod_metrics_for_oom_killed_pod() -> None:
+    context = K8sContext(
+        namespace="default",
+        pod_name="demo-pod",
+        workload=None,
+        pod_status=PodStatusSnapshot(
+            phase="Running",
+            node_name="node-1",
+            start_time=None,
+            reason=None,
+            message=None,
+            conditions=[],
+            container_statuses=[
+                {
+                    "name": "app",
+                    "restart_count": 2,
+                    "state": {"type": "running"},
+                    "last_state": {"type": "terminated", "reason": "OOMKilled"},
+                }
+            ],
+        ),
+        events=[],
+        previous_logs=[],
+        warnings=[],
+        pod_spec={
+            "containers": [{"name": "app", "resources": {"limits": {"memory": "512Mi"}}}]
+        },
+    )
+    client = FakeKubernetesClient(
+        context,
+        pod_metrics={"containers": [{"name": "app", "usage": {"memory": "480Mi"}}]},
+    )
+    service = AnalysisService(client, analysis_engine=FailingAnalysisEngine())
+
+    _, _, _, ctx, _ = service.analyze(_sample_request())
+
+    assert client.pod_metrics_calls == [("default", "demo-pod")]
+    container = ctx["oom_analysis"]["containers"][0]
+    assert container["memory_usage"] == "480Mi"
+    assert container["suggested_limit"] == "768Mi"
+
+
+def test_analysis_service_reads_claim_and_usage_for_volume_filling_up_alert() -> None:
+    context = K8sContext(
+        namespace="default",
+        pod_name=None,
+        workload=None,
+        pod_status=None,
+        events=[],
+        previous_logs=[],
+        warnings=[],
+    )
+    client = FakeKubernetesClient(context)
+    client.volume_claims["data-db-0"] = {
+        "name": "data-db-0",
+        "phase": "Bound",
+        "storage_class": "gp3",
+        "volume_name": "pvc-1",
+        "storage_class_detail": {"allow_volume_expansion": True},
+        "attachments": [{"node": "node-2", "attached": True}],
+    }
+    client.volume_stats["node-2"] = [
+        {"namespace": "default", "claim": "data-db-0", "used_bytes": 97, "capacity_bytes": 100}
+    ]
+    request = AlertAnalysisRequest(
+        alert=Alert(
+            status="firing",
+            labels={
+                "alertname": "KubePersistentVolumeFillingUp",
+                "namespace": "default",
+                "persistentvolumeclaim": "data-db-0",
+            },
+            fingerprint="pvc-1",
+        ),
+        thread_ts="1234567890.123456",
+    )
+    service = AnalysisService(client, analysis_engine=FailingAnalysisEngine())
+
+    _, _, _, ctx, artifacts = service.analyze(request)
+
+    assert client.volume_claim_calls == [("default", "data-db-0")]
+    [claim] = ctx["storage_analysis"]["claims"]
+    assert claim["usage_percent"] == 97.0
+    assert ctx["storage_analysis"]["findings"][0].startswith("claim data-db-0 is 97.0% full")
+    assert any(
+        artifact["type"] == "rule_finding"
+        and artifact["result"]["rule"] == "volume_mount_failure"
+        for artifact in artifacts
+    )
+
+
+def test_analysis_service_attaches_hpa_analysis_of_the_workload() -> None:
+    context = K8sContext(
+        namespace="default",
+        pod_name="demo-pod",
+        workload="demo",
+        pod_status=None,
+        events=[],
+        previous_logs=[],
+        warnings=[],
+    )
+    client = FakeKubernetesClient(context)
+    client.hpa_status = {
+        "name": "demo",
+        "target": {"kind": "Deployment", "name": "demo"},
+        "max_replicas": 3,
+        "current_replicas": 3,
+        "desired_replicas": 3,
+    }
+    engine = CapturingAnalysisEngine("ok")
+    service = AnalysisService(client, analysis_engine=engine)
+
+    _, _, _, ctx, _ = service.analyze(_sample_request())
+
+    assert client.hpa_calls == [("default", "demo", "demo-pod")]
+    assert ctx["hpa_analysis"]["at_max_replicas"] is True
+    assert "HPA demo is pinned at maxReplicas (3/3)" in engine.last_prompt
+
+
+def test_analysis_service_attaches_resource_pressure_of_pod_and_node() -> None:
+    context = K8sContext(
+        namespace="default",
+        pod_name="demo-pod",
+        workload=None,
+        pod_status=PodStatusSnapshot(
+            phase="Running",
+            node_name="node-1",
+            start_time=None,
+            reason=None,
+            message=None,
+            conditions=[],
+            container_statuses=[],
+        ),
+        events=[],
+        previous_logs=[],
+        warnings=[],
+        pod_spec={"containers": [{"name": "app", "resources": {"limits": {"cpu": "500m"}}}]},
+    )
+    client = FakeKubernetesClient(
+        context, pod_metrics={"containers": [{"name": "app", "usage": {"cpu": "490m"}}]}
+    )
+    client.node_metrics["node-1"] = {
+        "name": "node-1",
+        "usage": {"cpu": "1", "memory": "2Gi"},
+        "allocatable": {"cpu": "4", "memory": "16Gi"},
+    }
+    engine = CapturingAnalysisEngine("ok")
+    service = AnalysisService(client, analysis_engine=engine)
+
+    _, _, _, ctx, _ = service.analyze(_sample_request())
+
+    assert client.pod_metrics_calls == [("default", "demo-pod")]
+    assert client.node_metrics_calls == ["node-1"]
+    assert ctx["resource_pressure"]["verdict"] == "critical"
+    assert ctx["resource_pressure"]["node"]["cpu_percent_of_allocatable"] == 25.0
+    assert "container app uses 98.0% of its cpu limit" in engine.last_prompt
+
+
+def test_analysis_service_evaluates_network_policies_of_connection_failure_alerts() -> None:
+    client = FakeKubernetesClient(_empty_context())
+    client.network_connectivity = {
+        "source": {
+            "namespace": "default",
+            "pod": "demo-pod",
+            "labels": {"app": "demo"},
+            "namespace_labels": {"kubernetes.io/metadata.name": "default"},
+            "policies": [
+                {
+                    "name": "default-deny",
+                    "namespace": "default",
+                    "pod_selector": {},
+                    "policy_types": ["Ingress", "Egress"],
+                }
+            ],
+        },
+        "destination": {
+            "namespace": "payments",
+            "service": "payments",
+            "found": True,
+            "selector": {"app": "payments"},
+            "ports": [],
+            "namespace_labels": {"kubernetes.io/metadata.name": "payments"},
+            "policies": [],
+        },
+    }
+    engine = CapturingAnalysisEngine("ok")
+    service = AnalysisService(client, analysis_engine=engine)
+    request = _sample_request()
+    request.alert.labels.update(
+        {
+            "alertname": "UpstreamConnectFailures",
+            "service": "payments",
+            "destination_namespace": "payments",
+        }
+    )
+
+    _, _, _, ctx, _ = service.analyze(request)
+
+    assert client.network_connectivity_calls == [("default", "demo-pod", "payments", "payments")]
+    assert ctx["network_policy_analysis"]["verdict"] == "blocked"
+    assert ctx["network_policy_analysis"]["egress"]["policies"] == ["default/default-deny"]
+    assert "egress from default/demo-pod to payments/payments is blocked" in engine.last_prompt
+
+
+def test_analysis_service_checks_endpoint_readiness_of_5xx_alerts() -> None:
+    client = FakeKubernetesClient(_empty_context())
+    client.service_endpoints = {
+        "namespace": "default",
+        "service": "web",
+        "found": True,
+        "selector": {"app": "web", "track": "stable"},
+        "ports": [],
+        "endpoints": [],
+        "pods": [],
+        "near_misses": [{"name": "web-6f9c-q8x2z", "mismatched": {"track": "blue"}}],
+    }
+    engine = CapturingAnalysisEngine("ok")
+    service = AnalysisService(client, analysis_engine=engine)
+    request = _sample_request()
+    request.alert.labels.update({"alertname": "HighHTTP5xxRate", "service": "web"})
+
+    _, _, _, ctx, _ = service.analyze(request)
+
+    assert client.service_endpoints_calls == [("default", "web")]
+    assert ctx["endpoint_readiness"]["verdict"] == "unavailable"
+    assert ctx["endpoint_readiness"]["cause"] == "selector_mismatch"
+    assert "web-6f9c-q8x2z (track=blue)" in engine.last_prompt
+
+
+def test_analysis_service_warns_when_endpointslices_cannot_be_listed() -> None:
+    client = FakeKubernetesClient(_empty_context())
+    service = AnalysisService(client, analysis_engine=FakeAnalysisEngine("ok"))
+    request = _sample_request()
+    request.alert.labels.update({"alertname": "ServiceUnavailable", "service": "web"})
+
+    _, _, _, ctx, _ = service.analyze(request)
+
+    assert "failed to list endpointslices" in ctx["warnings"]
+    assert "endpoint_readiness" not in ctx
+
+
+def test_analysis_service_reports_the_cause_of_failed_jobs() -> None:
+    client = FakeKubernetesClient(_empty_context())
+    client.job_run = {
+        "namespace": "default",
+        "job": "report-28301234",
+        "cron_job": "report",
+        "found": True,
+        "recent_runs": [],
+        "spec": {"backoff_limit": 2, "active_deadline_seconds": None},
+        "status": {
+            "name": "report-28301234",
+            "active": 0,
+            "succeeded": 0,
+            "failed": 3,
+            "conditions": [
+                {"type": "Failed", "status": "True", "reason": "BackoffLimitExceeded"}
+            ],
+        },
+        "pods": [
+            {
+                "name": "report-28301234-x7k2p",
+                "phase": "Failed",
+                "failed": True,
+                "containers": [
+                    {
+                        "name": "report",
+                        "image": "shop/report:2.1",
+                        "state": {"type": "terminated", "reason": "Error", "exit_code": "2"},
+                    }
+                ],
+            }
+        ],
+        "logs": [
+            {"container": "report", "previous": False, "logs": ["FATAL missing column amount"]}
+        ],
+    }
+    engine = CapturingAnalysisEngine("ok")
+    service = AnalysisService(client, analysis_engine=engine)
+    request = _sample_request()
+    request.alert.labels.update({"alertname": "KubeJobFailed", "job_name": "report-28301234"})
+
+    _, _, _, ctx, _ = service.analyze(request)
+
+    assert client.job_run_calls == [("default", "report-28301234", None)]
+    assert ctx["job_failure_analysis"]["cause"] == "backoff_limit_exceeded"
+    assert ctx["job_failure_analysis"]["last_run_logs"][0]["logs"] == [
+        "FATAL missing column amount"
+    ]
+    assert "reached its backoffLimit of 2" in engine.last_prompt
+
+
+def test_analysis_service_checks_cluster_dns_for_dns_alerts() -> None:
+    client = FakeKubernetesClient(_empty_context())
+    client.cluster_dns = {
+        "namespace": "kube-system",
+        "service": "kube-dns",
+        "found": True,
+        "selector": {"k8s-app": "kube-dns"},
+        "endpoints": [{"ready": True, "terminating": False, "pod": "coredns-a"}],
+        "pods": [{"name": "coredns-a", "ready": True, "containers": []}],
+        "logs": [
+            {
+                "pod": "coredns-a",
+                "container": "coredns",
+                "lines": 40,
+                "servfail": 3,
+                "errors": 3,
+                "upstream_timeouts": 3,
+                "samples": ["[ERROR] plugin/errors: 2 db.example.com. A: read udp: i/o timeout"],
+            }
+        ],
+    }
+    engine = CapturingAnalysisEngine("ok")
+    service = AnalysisService(
+        client, analysis_engine=engine, cluster_dns_log_lookback_minutes=10
+    )
+    request = _sample_request()
+    request.alert.labels["alertname"] = "CoreDNSErrorsHigh"
+
+    _, _, _, ctx, _ = service.analyze(request)
+
+    assert client.cluster_dns_calls == [("kube-system", "kube-dns", 600)]
+    assert ctx["dns_analysis"]["verdict"] == "degraded"
+    assert ctx["dns_analysis"]["cause"] == "upstream_timeouts"
+    assert ctx["dns_analysis"]["trigger"] == "alert"
+    assert "CoreDNS logged 3 upstream timeouts" in engine.last_prompt
+
+
+def test_analysis_service_skips_cluster_dns_for_other_alerts() -> None:
+    client = FakeKubernetesClient(_empty_context())
+    service = AnalysisService(client, analysis_engine=FakeAnalysisEngine("ok"))
+
+    _, _, _, ctx, _ = service.analyze(_sample_request())
+
+    assert client.cluster_dns_calls == []
+    assert "dns_analysis" not in ctx
+
+
+def test_analysis_service_reads_quotas_when_pod_creation_is_rejected() -> None:
+    context = replace(
+        _empty_context(),
+        pod_name=None,
+        events=[
+            PodEventSummary(
+                type="Warning",
+                reason="FailedCreate",
+                message='Error creating: pods "api-7d9f-x2" is forbidden: exceeded quota: '
+                "compute, requested: requests.cpu=500m, used: requests.cpu=3800m, "
+                "limited: requests.cpu=4",
+                count=7,
+                first_timestamp=None,
+                last_timestamp=None,
+                involved_object={"kind": "ReplicaSet", "name": "api-7d9f", "namespace": "default"},
+            )
+        ],
+    )
+    client = FakeKubernetesClient(context)
+    client.namespace_quotas = {
+        "namespace": "default",
+        "quotas": [
+            {
+                "quota": "compute",
+                "resource": "requests.cpu",
+                "used": "3800m",
+                "hard": "4",
+                "ratio": 0.95,
+            },
+            {"quota": "compute", "resource": "pods", "used": "8", "hard": "20", "ratio": 0.4},
+        ],
+        "limit_ranges": [],
+    }
+    service = AnalysisService(client, analysis_engine=FakeAnalysisEngine("ok"))
+
+    _, _, _, ctx, _ = service.analyze(_sample_request())
+
+    assert client.namespace_quota_calls == ["default"]
+    assert ctx["quota_analysis"]["trigger"] == "events"
+    assert ctx["quota_analysis"]["findings"] == [
+        "ReplicaSet api-7d9f cannot create pods: ResourceQuota compute requests.cpu is "
+        "exhausted (pod requests 500m, 3800m of 4 already used)"
+    ]
+
+
+class FakeEventArchive:
+    def __init__(self, events: list[PodEventSummary]) -> None:
+        self._events = events
+        self.calls: list[dict[str, object]] = []
+
+    def record(self, events: list[tuple[str, PodEventSummary]]) -> int:
+        return len(events)
+
+    def list_events(self, namespace: str, **filters: object) -> list[PodEventSummary]:
+        self.calls.append({"namespace": namespace, **filters})
+        return self._events
+
+
+def test_analysis_service_appends_archived_events_the_cluster_no_longer_returns() -> None:
+    live = PodEventSummary(
+        type="Warning",
+        reason="BackOff",
+        message="Back-off restarting failed container",
+        count=9,
+        first_timestamp="2026-10-14T09:03:00+00:00",
+        last_timestamp="2026-10-14T09:30:00+00:00",
+        involved_object={"kind": "Pod", "name": "demo-pod"},
+    )
+    expired = PodEventSummary(
+        type="Warning",
+        reason="FailedMount",
+        message="MountVolume.SetUp failed for volume config",
+        count=3,
+        first_timestamp="2026-10-14T08:58:00+00:00",
+        last_timestamp="2026-10-14T09:01:00+00:00",
+        involved_object={"kind": "Pod", "name": "demo-pod"},
+    )
+    archive = FakeEventArchive([replace(live, count=6), expired])
+    context = replace(_empty_context(), events=[live])
+    service = AnalysisService(
+        FakeKubernetesClient(context),
+        analysis_engine=FakeAnalysisEngine("ok"),
+        event_archive=archive,
+        event_archive_lookback_minutes=30,
+    )
+    request = _sample_request()
+    request.alert.starts_at = datetime(2026, 10, 14, 9, 5, tzinfo=timezone.utc)
+
+    _, _, _, ctx, _ = service.analyze(request)
+
+    assert [(event["reason"], event["count"]) for event in ctx["events"]] == [
+        ("BackOff", 9),
+        ("FailedMount", 3),
+    ]
+    assert archive.calls == [
+        {
+            "namespace": "default",
+            "involved_name": "demo-pod",
+            "involved_name_prefix": None,
+            "since": datetime(2026, 10, 14, 8, 35, tzinfo=timezone.utc),
+            "until": None,
+            "limit": 50,
+        }
+    ]
+
+
+def test_analysis_service_ranks_hypotheses_before_the_final_analysis() -> None:
+    engine = RecordingAnalysisEngine(
+        '{"verdict": "supported", "confidence": 0.7, "summary": "token-42 rejected"}'
+    )
+    service = AnalysisService(
+        FakeKubernetesClient(_empty_context()),
+        analysis_engine=engine,
+        masker=RegexMasker.from_patterns([r"token-\d+"]),
+        hypothesis_investigator=HypothesisInvestigator(timeout_seconds=5),
+    )
+
+    _, _, _, ctx, _ = service.analyze(_sample_request())
+
+    branch_ids = sorted(str(incident_id) for _, incident_id in engine.calls[:3])
+    final_prompt, final_id = engine.calls[-1]
+    assert len(engine.calls) == 4
+    assert branch_ids == [
+        f"{final_id}:hypothesis:capacity",
+        f"{final_id}:hypothesis:config_change",
+        f"{final_id}:hypothesis:dependency",
+    ]
+    assert "## Ranked hypotheses (investigated in parallel)" in final_prompt
+    assert [item["rank"] for item in ctx["hypotheses"]] == [1, 2, 3]
+    assert ctx["hypotheses"][0]["summary"] == "[MASKED] rejected"
+
+
+def test_analysis_service_successful_result_is_not_degraded() -> None:
+    context = K8sContext(
+        namespace="default",
+        pod_name="demo-pod",
+        workload=None,
+        pod_status=None,
+        events=[],
+        previous_logs=[],
+        warnings=[],
+    )
+    service = AnalysisService(
+        FakeKubernetesClient(context),
+        analysis_engine=FakeAnalysisEngine("## 요약\nok\n## 상세 분석\ndetail"),
+    )
+
+    _, _, _, ctx, artifacts = service.analyze(_sample_request())
+
+    assert ctx.get("degraded") is False
+    assert "degraded_reason" not in ctx
+    assert "pod_diagnostics" not in ctx
+    assert "oom_analysis" not in ctx
+    assert all(artifact["type"] != "rule_finding" for artifact in artifacts)
+
+
+class RecordingAnalysisEngine(FakeAnalysisEngine):
+    def __init__(self, result: str) -> None:
+        super().__init__(result)
+        self.calls: list[tuple[str, str | None]] = []
+
+    def analyze(self, prompt: str, incident_id: str | None = None) -> str:
+        self.calls.append((prompt, incident_id))
+        return super().analyze(prompt, incident_id)
+
+
+class RcaConfigKubernetesClient(FakeKubernetesClient):
+    def __init__(self, context: K8sContext, items: list[dict[str, object]]) -> None:
+        super().__init__(context)
+        self._items = items
+        self.rca_config_calls: list[str] = []
+
+    def list_rca_configs(self, namespace: str) -> list[dict[str, object]]:
+        self.rca_config_calls.append(namespace)
+        return self._items
+
+
+def test_analysis_service_merges_the_namespace_rca_config_into_the_prompt() -> None:
+    k8s = RcaConfigKubernetesClient(
+        _empty_context(),
+        [
+            {
+                "metadata": {"name": "demo", "namespace": "default"},
+                "spec": {
+                    "analysisDepth": "quick",
+                    "logSources": [{"name": "demo", "query": '{app="demo"}'}],
+                    "runbooks": [{"title": "Demo", "url": "https://runbooks.example.com/demo"}],
+                    "promptInstructions": "Demo restarts after every deploy.",
+                },
+            }
+        ],
+    )
+    engine = RecordingAnalysisEngine("## 요약\nok\n## 상세 분석\ndetail")
+    service = AnalysisService(k8s, analysis_engine=engine, namespace_config_enabled=True)
+
+    _, _, _, ctx, _ = service.analyze(_rollout_request())
+
+    assert k8s.rca_config_calls == ["default"]
+    assert ctx["namespace_config"]["configs"] == ["default/demo"]
+    assert ctx["namespace_config"]["analysis_depth"] == "quick"
+    prompt = engine.calls[0][0]
+    assert '- log source demo (loki): {app="demo"}' in prompt
+    assert "- runbook: Demo <https://runbooks.example.com/demo>" in prompt
+    assert "Demo restarts after every deploy." in prompt
+    _, _, _, disabled, _ = AnalysisService(k8s, analysis_engine=engine).analyze(
+        _rollout_request()
+    )
+    assert "namespace_config" not in disabled and k8s.rca_config_calls == ["default"]
+
+
+class FakeSessionRepository:
+    def __init__(self, session_ids: set[str]) -> None:
+        self._session_ids = session_ids
+
+    def read_session(self, session_id: str, **kwargs: object) -> object | None:
+        return object() if session_id in self._session_ids else None
+
+
+def _empty_context() -> K8sContext:
+    return K8sContext(
+        namespace="default",
+        pod_name="demo-pod",
+        workload=None,
+        pod_status=None,
+        events=[],
+        previous_logs=[],
+        warnings=[],
+    )
+
+
+def test_follow_up_continues_the_stored_analysis_session() -> None:
+    engine = RecordingAnalysisEngine("## 요약\nok\n## 상세 분석\ndetail")
+    sessions: set[str] = set()
+    service = AnalysisService(
+        FakeKubernetesClient(_empty_context()),
+        analysis_engine=engine,
+        session_repository=FakeSessionRepository(sessions),
+    )
+    _, _, _, ctx, _ = service.analyze(_sample_request())
+    analysis_id = str(ctx["analysis_id"])
+    assert analysis_id == engine.calls[0][1]
+    sessions.add(analysis_id)
+
+    answer = service.follow_up(
+        analysis_id,
+        AnalysisFollowupRequest(question="Which image tag is running?", thread_ts="123.456"),
+    )
+
+    assert answer == "## 요약\nok\n## 상세 분석\ndetail"
+    prompt, session_id = engine.calls[-1]
+    assert session_id == analysis_id
+    assert "Follow-up question: Which image tag is running?" in prompt
+
+
+def test_follow_up_rejects_unknown_or_malformed_analysis_ids() -> None:
+    service = AnalysisService(
+        FakeKubernetesClient(_empty_context()),
+        analysis_engine=FakeAnalysisEngine("answer"),
+        session_repository=FakeSessionRepository(set()),
+    )
+    request = AnalysisFollowupRequest(question="why?", thread_ts="123.456")
+
+    with pytest.raises(ValueError):
+        service.follow_up("alert:abc:summary", request)
+    with pytest.raises(AnalysisNotFoundError):
+        service.follow_up("alert:abc:run:deadbeef", request)
+
+
+class ScopedSessionRepository(FakeSessionRepository):
+    def __init__(self, session_ids: set[str]) -> None:
+        super().__init__(session_ids)
+        self.scopes: dict[str, dict[str, object]] = {}
+        self.namespaces: list[tuple[str, str]] = []
+
+    def write_session_namespace(self, session_key: str, namespace: str) -> int:
+        self.namespaces.append((session_key, namespace))
+        return 1
+
+    def write_access_scope(self, session_id: str, scope: dict[str, object]) -> None:
+        self.scopes[session_id] = scope
+
+    def read_access_scope(self, session_id: str) -> dict[str, object] | None:
+        return self.scopes.get(session_id)
+
+
+def test_follow_up_of_a_caller_credential_analysis_requires_the_callers_token() -> None:
+    class CredentialRecordingEngine(RecordingAnalysisEngine):
+        def analyze(self, prompt: str, incident_id: str | None = None) -> str:
+            with_caller_token.append(caller_credentials_active())
+            return super().analyze(prompt, incident_id)
+
+    with_caller_token: list[bool] = []
+    sessions: set[str] = set()
+    repository = ScopedSessionRepository(sessions)
+    service = AnalysisService(
+        FakeKubernetesClient(_empty_context()),
+        analysis_engine=CredentialRecordingEngine("## 요약\nok\n## 상세 분석\ndetail"),
+        session_repository=repository,
+    )
+    credentials = ClusterCredentials(token="caller-token")
+    question = AnalysisFollowupRequest(question="why?", thread_ts="123.456")
+
+    init_credential_passthrough(True)
+    try:
+        _, _, _, ctx, _ = service.analyze(
+            _sample_request().model_copy(update={"cluster_credentials": credentials})
+        )
+        analysis_id = str(ctx["analysis_id"])
+        sessions.add(analysis_id)
+        with pytest.raises(ClusterCredentialError):
+            service.follow_up(analysis_id, question)
+        with pytest.raises(ClusterCredentialError):
+            service.open_investigation(analysis_id)
+        # Another replica reads the access scope from the session store.
+        restarted = AnalysisService(
+            FakeKubernetesClient(_empty_context()),
+            analysis_engine=FakeAnalysisEngine("answer"),
+            session_repository=repository,
+        )
+        with pytest.raises(ClusterCredentialError):
+            restarted.follow_up(analysis_id, question)
+        service.follow_up(
+            analysis_id, question.model_copy(update={"cluster_credentials": credentials})
+        )
+    finally:
+        init_credential_passthrough(False)
+
+    assert repository.scopes[analysis_id] == {"credentials": "caller", "cluster": None}
+    assert with_caller_token == [True, True]
+
+
+def test_follow_up_of_a_remote_cluster_analysis_runs_in_that_cluster() -> None:
+    class ClusterRecordingEngine(RecordingAnalysisEngine):
+        def analyze(self, prompt: str, incident_id: str | None = None) -> str:
+            clusters.append(current_cluster())
+            return super().analyze(prompt, incident_id)
+
+    clusters: list[str | None] = []
+    sessions: set[str] = set()
+    service = AnalysisService(
+        FakeKubernetesClient(_empty_context()),
+        analysis_engine=ClusterRecordingEngine("## 요약\nok\n## 상세 분석\ndetail"),
+        session_repository=ScopedSessionRepository(sessions),
+    )
+
+    init_clusters([("prod-eu", "/etc/kube-rca/prod-eu", "")], local_name="prod-us")
+    try:
+        _, _, _, ctx, _ = service.analyze(
+            _sample_request().model_copy(update={"cluster": "prod-eu"})
+        )
+        analysis_id = str(ctx["analysis_id"])
+        sessions.add(analysis_id)
+        service.follow_up(analysis_id, AnalysisFollowupRequest(question="why?", thread_ts="1.2"))
+        service.investigate(analysis_id, "which image?", lambda event: None)
+    finally:
+        init_clusters(())
+
+    assert clusters == ["prod-eu", "prod-eu", "prod-eu"]
+
+
+def test_sessions_of_degraded_analyses_are_tagged_with_the_namespace() -> None:
+    repository = ScopedSessionRepository(set())
+    service = AnalysisService(
+        FakeKubernetesClient(_empty_context()),
+        analysis_engine=FakeAnalysisEngine(""),
+        session_repository=repository,
+    )
+
+    _, _, _, ctx, _ = service.analyze(_sample_request())
+
+    assert ctx["degraded"] is True
+    assert repository.namespaces == [("alert:abc123", "default")]
+
+
+class StreamingAnalysisEngine(RecordingAnalysisEngine):
+    def __init__(self, chunks: list[str]) -> None:
+        super().__init__("".join(chunks))
+        self._chunks = chunks
+        self.tool_runs: list[tuple[str, dict[str, object], str | None]] = []
+
+    def stream_analyze(
+        self,
+        prompt: str,
+        incident_id: str | None,
+        on_event: Callable[[dict[str, object]], None],
+    ) -> str:
+        on_event({"type": "tool_call", "tool": "get_pod_logs"})
+        for chunk in self._chunks:
+            on_event({"type": "chunk", "text": chunk})
+        return self.analyze(prompt, incident_id)
+
+    def tool_names(self) -> list[str]:
+        return ["get_pod_logs"]
+
+    def run_tool(
+        self, tool_name: str, arguments: dict[str, object], incident_id: str | None = None
+    ) -> dict[str, object]:
+        if tool_name != "get_pod_logs":
+            raise ValueError(f"unknown tool: {tool_name}")
+        self.tool_runs.append((tool_name, arguments, incident_id))
+        return {"status": "success", "content": [{"text": "password=hunter2 connection refused"}]}
+
+
+def test_investigation_streams_masked_lines_and_tool_calls() -> None:
+    analysis_id = "alert:abc:run:deadbeef"
+    engine = StreamingAnalysisEngine(["The pod logs token-1", "23 before\nit ", "crashed."])
+    service = AnalysisService(
+        FakeKubernetesClient(_empty_context()),
+        analysis_engine=engine,
+        session_repository=FakeSessionRepository({analysis_id}),
+        masker=RegexMasker.from_patterns([r"token-\d+"]),
+    )
+    events: list[dict[str, object]] = []
+
+    session = service.open_investigation(analysis_id)
+    answer = service.investigate(analysis_id, "Why did token-7 crash?", events.append)
+
+    assert session == {"analysis_id": analysis_id, "available": True, "tools": ["get_pod_logs"]}
+    assert events == [
+        {"type": "tool_call", "tool": "get_pod_logs"},
+        {"type": "chunk", "text": "The pod logs [MASKED] before\n"},
+        {"type": "chunk", "text": "it crashed."},
+    ]
+    assert answer == "The pod logs [MASKED] before\nit crashed."
+    prompt, session_id = engine.calls[-1]
+    assert session_id == analysis_id
+    assert prompt.endswith("Question: Why did [MASKED] crash?")
+
+
+def test_investigation_runs_tools_in_the_session_and_masks_their_output() -> None:
+    analysis_id = "alert:abc:run:deadbeef"
+    engine = StreamingAnalysisEngine([])
+    service = AnalysisService(
+        FakeKubernetesClient(_empty_context()),
+        analysis_engine=engine,
+        session_repository=FakeSessionRepository({analysis_id}),
+        masker=RegexMasker.from_patterns([r"password=\S+"]),
+    )
+
+    result = service.run_investigation_tool(analysis_id, "get_pod_logs", {"tail_lines": 50})
+
+    assert engine.tool_runs == [("get_pod_logs", {"tail_lines": 50}, analysis_id)]
+    assert result["tool"] == "get_pod_logs"
+    assert result["status"] == "success"
+    assert "hunter2" not in str(result["output"])
+    assert "connection refused" in str(result["output"])
+    with pytest.raises(ValueError):
+        service.run_investigation_tool(analysis_id, "delete_pod", {})
+    with pytest.raises(AnalysisNotFoundError):
+        service.open_investigation("alert:abc:run:cafebabe")
+    with pytest.raises(ValueError):
+        service.open_investigation("alert:abc:summary")
+
+
+def test_degraded_analysis_has_no_analysis_id() -> None:
+    service = AnalysisService(FakeKubernetesClient(_empty_context()), analysis_engine=None)
+
+    _, _, _, ctx, _ = service.analyze(_sample_request())
+
+    assert "analysis_id" not in ctx
+
+
+class SlowAnalysisEngine(FakeAnalysisEngine):
+    def __init__(self, result: str, delay_seconds: float) -> None:
+        super().__init__(result)
+        self._delay_seconds = delay_seconds
+
+    def analyze(self, prompt: str, incident_id: str | None = None) -> str:
+        time.sleep(self._delay_seconds)
+        return super().analyze(prompt, incident_id)
+
+
+class SlowKubernetesClient(FakeKubernetesClient):
+    def collect_context(self, *args: object, **kwargs: object) -> K8sContext:
+        time.sleep(0.3)
+        return super().collect_context(*args, **kwargs)  # type: ignore[arg-type]
+
+
+def test_analysis_is_time_boxed_when_collection_uses_up_the_slo_deadline() -> None:
+    tracker = LatencySloTracker({"default": 0.2})
+    engine = RecordingAnalysisEngine("## 요약\nok\n## 상세 분석\ndetail")
+    service = AnalysisService(
+        SlowKubernetesClient(_empty_context()), analysis_engine=engine, slo_tracker=tracker
+    )
+
+    _, summary, _, ctx, _ = service.analyze(_sample_request())
+
+    assert engine.calls == []
+    assert ctx["time_boxed"] is True
+    assert ctx["degraded_reason"] == "time_boxed"
+    assert "time-boxed" in summary
+    assert 'outcome="time_boxed"} 1' in tracker.render_prometheus()
+
+
+def test_analysis_is_time_boxed_when_a_kubernetes_read_hits_the_slo_deadline() -> None:
+    class DeadlineKubernetesClient(FakeKubernetesClient):
+        def collect_context(self, *args: object, **kwargs: object) -> K8sContext:
+            time.sleep(0.3)
+            bounded_timeout(5)
+            raise AssertionError("read past the deadline")
+
+    tracker = LatencySloTracker({"default": 0.2})
+    engine = RecordingAnalysisEngine("## 요약\nok\n## 상세 분석\ndetail")
+    service = AnalysisService(
+        DeadlineKubernetesClient(_empty_context()), analysis_engine=engine, slo_tracker=tracker
+    )
+
+    _, summary, _, ctx, _ = service.analyze(_sample_request())
+
+    assert engine.calls == []
+    assert ctx["time_boxed"] is True
+    assert ctx["degraded_reason"] == "time_boxed"
+    assert "time-boxed" in summary
+    assert 'outcome="time_boxed"} 1' in tracker.render_prometheus()
+
+
+def test_slo_deadline_bounds_the_calls_of_the_analysis_without_background_threads() -> None:
+    class DeadlineRecordingEngine(SlowAnalysisEngine):
+        def analyze(self, prompt: str, incident_id: str | None = None) -> str:
+            remaining.append(remaining_seconds())
+            return super().analyze(prompt, incident_id)
+
+    remaining: list[float | None] = []
+    tracker = LatencySloTracker({"default": 0.2})
+    service = AnalysisService(
+        FakeKubernetesClient(_empty_context()),
+        analysis_engine=DeadlineRecordingEngine("## 요약\nok\n## 상세 분석\ndetail", 0.3),
+        slo_tracker=tracker,
+    )
+    threads = threading.active_count()
+
+    _, summary, _, ctx, _ = service.analyze(_sample_request())
+
+    assert remaining[0] is not None and 0 < remaining[0] <= 0.18
+    assert remaining_seconds() is None
+    assert summary == "ok"
+    assert "time_boxed" not in ctx
+    assert 'outcome="missed"} 1' in tracker.render_prometheus()
+    assert threading.active_count() == threads
+
+
+def test_analysis_within_slo_is_counted_as_met() -> None:
+    tracker = LatencySloTracker({"default": 5})
+    service = AnalysisService(
+        FakeKubernetesClient(_empty_context()),
+        analysis_engine=FakeAnalysisEngine("## 요약\nok\n## 상세 분석\ndetail"),
+        slo_tracker=tracker,
+    )
+
+    _, _, _, ctx, _ = service.analyze(_sample_request())
+
+    assert "time_boxed" not in ctx
+    assert 'outcome="met"} 1' in tracker.render_prometheus()
+
+
+def test_canary_arm_uses_canary_engine_and_prompt() -> None:
+    stable = RecordingAnalysisEngine("## 요약\nstable\n## 상세 분석\ndetail")
+    candidate = RecordingAnalysisEngine("## 요약\ncanary\n## 상세 분석\ndetail")
+    canary = CanaryRollout(100, engine=candidate, prompt_instructions="Prefer node causes.")
+    service = AnalysisService(
+        FakeKubernetesClient(_empty_context()), analysis_engine=stable, canary=canary
+    )
+
+    analysis, _, _, ctx, _ = service.analyze(_sample_request())
+
+    assert "canary" in analysis
+    assert stable.calls == []
+    assert "Prefer node causes." in candidate.calls[0][0]
+    assert ctx["rollout_arm"] == "canary"
+    assert canary.status()["canary"] == {"samples": 1, "error_rate": 0.0}
+
+
+class FakeAnalysisStore:
+    def __init__(self) -> None:
+        self.records: list[StoredAnalysis] = []
+
+    def record(self, analysis: StoredAnalysis) -> int:
+        self.records.append(analysis)
+        return len(self.records)
+
+
+def test_live_analysis_is_stored_with_pipeline_version() -> None:
+    history = FakeAnalysisStore()
+    service = AnalysisService(
+        FakeKubernetesClient(_empty_context()),
+        analysis_engine=RecordingAnalysisEngine("## 요약\nok\n## 상세 분석\ndetail"),
+        analysis_store=history,
+        pipeline_version="gemini/v2",
+    )
+
+    service.analyze(_sample_request())
+
+    [stored] = history.records
+    assert stored.source == "live"
+    assert stored.pipeline_version == "gemini/v2"
+    assert stored.result["analysis_summary"] == "ok"
+    assert stored.fingerprint == "abc123"
+    assert stored.request["alert"]["labels"]["pod"] == "demo-pod"
+
+
+def test_reanalyze_stores_backfill_version_without_summary_history() -> None:
+    history = FakeAnalysisStore()
+    summaries = FakeSummaryStore([])
+    service = AnalysisService(
+        FakeKubernetesClient(_empty_context()),
+        analysis_engine=RecordingAnalysisEngine("## 요약\nok\n## 상세 분석\ndetail"),
+        summary_store=summaries,
+        analysis_store=history,
+    )
+
+    result_id = service.reanalyze(_sample_request(), backfill_job_id="job1")
+
+    assert result_id == 1
+    assert history.records[0].source == "backfill"
+    assert history.records[0].backfill_job_id == "job1"
+    assert history.records[0].pipeline_version == "unversioned"
+    assert summaries.appended == []
+
+
+def test_analysis_reuses_the_callers_correlation_id_and_reanalysis_gets_its_own() -> None:
+    history = FakeAnalysisStore()
+    service = AnalysisService(
+        FakeKubernetesClient(_empty_context()),
+        analysis_engine=RecordingAnalysisEngine("## 요약\nok\n## 상세 분석\ndetail"),
+        analysis_store=history,
+    )
+
+    _, _, _, ctx, _ = service.analyze(
+        _sample_request().model_copy(update={"correlation_id": "slack-1234567890.123456"})
+    )
+    _, _, _, generated, _ = service.analyze(_sample_request())
+    service.reanalyze(_sample_request(), backfill_job_id="job1")
+
+    assert ctx["correlation_id"] == "slack-1234567890.123456"
+    assert re.fullmatch(r"[0-9a-f]{32}", str(generated["correlation_id"]))
+    assert [record.correlation_id for record in history.records[:2]] == [
+        "slack-1234567890.123456",
+        generated["correlation_id"],
+    ]
+    assert history.records[2].correlation_id not in (None, generated["correlation_id"])
+
+
+class ScriptedAnalysisEngine:
+    def __init__(self, answers: list[str]) -> None:
+        self._answers = answers
+        self.calls: list[tuple[str, str | None]] = []
+
+    def analyze(self, prompt: str, incident_id: str | None = None) -> str:
+        self.calls.append((prompt, incident_id))
+        return self._answers[len(self.calls) - 1]
+
+
+def _resolved_request() -> AlertAnalysisRequest:
+    starts_at = datetime(2026, 1, 1, 10, 0, tzinfo=timezone.utc)
+    return AlertAnalysisRequest(
+        alert=Alert(
+            status="resolved",
+            labels={"namespace": "default", "pod": "demo-pod"},
+            annotations={"summary": "Test"},
+            startsAt=starts_at,
+            endsAt=starts_at + timedelta(minutes=30),
+            fingerprint="abc123",
+        ),
+        thread_ts="1234567890.123456",
+    )
+
+
+def test_resolved_alert_closes_firing_analysis_and_validates_recovery() -> None:
+    sink_reports: list[tuple[str, dict[str, object]]] = []
+
+    class Sink:
+        def send(self, report_type: str, report: dict[str, object]) -> dict[str, object]:
+            sink_reports.append((report_type, report))
+            return {"delivered": True}
+
+    engine = ScriptedAnalysisEngine(
+        [
+            "## 요약\nMemory limit too low\n## 상세 분석\ndetail",
+            "## 요약\nRecovered after the limit was raised\n## 상세 분석\ndetail",
+            "explained\nThe restart stopped once the memory limit was raised.",
+        ]
+    )
+    service = AnalysisService(
+        FakeKubernetesClient(_empty_context()),
+        analysis_engine=engine,
+        closure_tracker=IncidentClosureTracker(sink=Sink()),
+        closure_validation=True,
+    )
+
+    _, _, _, firing_ctx, _ = service.analyze(_sample_request())
+    _, _, _, ctx, _ = service.analyze(_resolved_request())
+
+    closure = ctx["closure"]
+    assert isinstance(closure, dict)
+    assert closure["closed_analysis_id"] == firing_ctx["analysis_id"]
+    assert closure["duration_seconds"] == 1800.0
+    assert closure["duration_source"] == "alert"
+    assert closure["validation"] == {
+        "verdict": "explained",
+        "note": "The restart stopped once the memory limit was raised.",
+    }
+    assert "Memory limit too low" in engine.calls[2][0]
+    assert engine.calls[2][1] is not None and ":closure:" in engine.calls[2][1]
+    [(report_type, report)] = sink_reports
+    assert report_type == "incident_closure"
+    assert report["thread_ts"] == "1234567890.123456"
+
+
+def test_resolved_alert_without_open_analysis_skips_validation_by_default() -> None:
+    engine = RecordingAnalysisEngine("## 요약\nRecovered\n## 상세 분석\ndetail")
+    service = AnalysisService(
+        FakeKubernetesClient(_empty_context()),
+        analysis_engine=engine,
+        closure_tracker=IncidentClosureTracker(),
+    )
+
+    _, _, _, ctx, _ = service.analyze(_resolved_request())
+
+    closure = ctx["closure"]
+    assert isinstance(closure, dict)
+    assert closure["closed_analysis_id"] is None
+    assert closure["validation"] is None
+    assert len(engine.calls) == 1
+
+
+@pytest.mark.parametrize(
+    "answer,verdict",
+    [
+        ("explained\nok", "explained"),
+        ("**Not explained**\nno", "not_explained"),
+        ("partially_explained", "partially_explained"),
+        ("maybe", "unknown"),
+    ],
+)
+def test_parse_recovery_validation(answer: str, verdict: str) -> None:
+    assert _parse_recovery_validation(answer)["verdict"] == verdict
+
+
+class MaintenanceKubernetesClient(FakeKubernetesClient):
+    def __init__(self, context: K8sContext, maintenance: dict[str, dict[str, object]]) -> None:
+        super().__init__(context)
+        self._maintenance = maintenance
+        self.maintenance_calls: list[str] = []
+
+    def get_node_maintenance_status(self, node_name: str) -> dict[str, object] | None:
+        self.maintenance_calls.append(node_name)
+        return self._maintenance.get(node_name)
+
+
+def _disruption_request(labels: dict[str, str]) -> AlertAnalysisRequest:
+    return AlertAnalysisRequest(
+        alert=Alert(
+            status="firing",
+            labels={"alertname": "KubePodNotReady", "namespace": "default", **labels},
+            annotations={"summary": "Pod not ready"},
+            fingerprint="abc123",
+        ),
+        thread_ts="1234567890.123456",
+    )
+
+
+def test_disruption_alert_on_node_in_maintenance_is_labeled_expected() -> None:
+    context = K8sContext(
+        namespace="default",
+        pod_name="demo-pod",
+        workload=None,
+        pod_status=PodStatusSnapshot(
+            phase="Pending",
+            node_name="node-a",
+            start_time=None,
+            reason=None,
+            message=None,
+            conditions=[],
+            container_statuses=[],
+        ),
+        events=[],
+        previous_logs=[],
+        warnings=[],
+    )
+    k8s = MaintenanceKubernetesClient(
+        context,
+        {
+            "node-a": {
+                "node": "node-a",
+                "in_maintenance": True,
+                "cordoned": True,
+                "signals": ["node is cordoned (unschedulable)"],
+                "machine": None,
+            }
+        },
+    )
+    engine = RecordingAnalysisEngine("## 요약\nshould not run\n## 상세 분석\ndetail")
+    service = AnalysisService(
+        k8s,
+        analysis_engine=engine,
+        maintenance_awareness=True,
+        maintenance_disruption_alerts=("KubePodNotReady",),
+    )
+
+    analysis, summary, _, ctx, _ = service.analyze(_disruption_request({"pod": "demo-pod"}))
+
+    assert engine.calls == []
+    assert k8s.maintenance_calls == ["node-a"]
+    assert summary.startswith("[expected]")
+    assert "node is cordoned (unschedulable)" in analysis
+    assert ctx["expected_disruption"] is True
+    assert ctx["degraded"] is False
+    assert ctx["maintenance"]["node"] == "node-a"
+
+
+def test_disruption_alert_is_investigated_when_node_is_not_in_maintenance() -> None:
+    k8s = MaintenanceKubernetesClient(
+        _empty_context(),
+        {"node-b": {"node": "node-b", "in_maintenance": False, "signals": []}},
+    )
+    engine = RecordingAnalysisEngine("## 요약\nok\n## 상세 분석\ndetail")
+    service = AnalysisService(
+        k8s,
+        analysis_engine=engine,
+        maintenance_awareness=True,
+        maintenance_disruption_alerts=("KubePodNotReady",),
+    )
+
+    _, summary, _, ctx, _ = service.analyze(_disruption_request({"node": "node-b"}))
+
+    assert summary == "ok"
+    assert len(engine.calls) == 1
+    assert "expected_disruption" not in ctx
+    assert k8s.maintenance_calls == ["node-b"]
+
+
+def test_node_level_alert_attaches_node_health() -> None:
+    k8s = FakeKubernetesClient(
+        _empty_context(),
+        {
+            "node-c": {
+                "name": "node-c",
+                "conditions": [
+                    {"type": "Ready", "status": "Unknown", "reason": "NodeStatusUnknown"}
+                ],
+            }
+        },
+    )
+    engine = RecordingAnalysisEngine("## 요약\nok\n## 상세 분석\ndetail")
+    service = AnalysisService(k8s, analysis_engine=engine)
+
+    _, _, _, ctx, _ = service.analyze(_disruption_request({"node": "node-c"}))
+    service.analyze(_disruption_request({"instance": "10.0.0.7:9100"}))
+    _, _, _, missing, _ = service.analyze(_disruption_request({"node": "node-x"}))
+
+    assert k8s.node_calls == ["node-c", "10.0.0.7", "node-x"]
+    assert ctx["node_health"]["ready"] == "Unknown"
+    assert ctx["node_health"]["healthy"] is False
+    assert '"node_health"' in engine.calls[0][0]
+    assert "failed to read node node-x" in missing["warnings"]
+    assert NodeHealth.model_validate(ctx["node_health"]).ready == "Unknown"
+
+
+def test_every_context_analysis_is_a_response_field() -> None:
+    # The closure verdict is the only structured result not built from the context.
+    assert set(_CONTEXT_MODELS) - {"closure"} == {key for key, _ in _CONTEXT_ANALYSES}
+
+
+class RolloutKubernetesClient(FakeKubernetesClient):
+    def __init__(self, context: K8sContext, revisions: list[dict[str, object]] | None) -> None:
+        super().__init__(context)
+        self._revisions = revisions
+        self.revision_calls: list[tuple[str, str]] = []
+
+    def get_pod_deployment(self, namespace: str, pod_name: str) -> str | None:
+        return "demo"
+
+    def get_deployment_revisions(
+        self, namespace: str, deployment: str, *, limit: int = 2
+    ) -> list[dict[str, object]] | None:
+        self.revision_calls.append((namespace, deployment))
+        return self._revisions
+
+
+def _rollout_request() -> AlertAnalysisRequest:
+    return AlertAnalysisRequest(
+        alert=Alert(
+            status="firing",
+            labels={"alertname": "HighErrorRate", "namespace": "default", "pod": "demo-pod"},
+            annotations={},
+            startsAt=datetime(2026, 10, 14, 2, 0, tzinfo=timezone.utc),
+            fingerprint="abc123",
+        ),
+        thread_ts="1234567890.123456",
+    )
+
+
+def test_rollout_shortly_before_the_alert_is_correlated() -> None:
+    k8s = RolloutKubernetesClient(
+        _empty_context(),
+        [
+            {
+                "revision": 3,
+                "replica_set": "demo-5c8d",
+                "created": "2026-10-14T01:56:00+00:00",
+                "containers": [{"name": "demo", "image": "demo:1.3"}],
+            },
+            {
+                "revision": 2,
+                "replica_set": "demo-4b7c",
+                "created": "2026-10-10T08:00:00+00:00",
+                "containers": [{"name": "demo", "image": "demo:1.2"}],
+            },
+        ],
+    )
+    service = AnalysisService(k8s, analysis_engine=None, rollout_correlation_window_minutes=30)
+
+    analysis, _, _, ctx, _ = service.analyze(_rollout_request())
+
+    assert k8s.revision_calls == [("default", "demo")]
+    assert [item["revision"] for item in ctx["recent_rollouts"]] == [3]
+    assert ctx["recent_rollouts"][0]["minutes_before_alert"] == 4.0
+    assert "Deployment demo rolled out revision 3 4.0 minutes before the alert" in analysis
+
+
+def test_rollout_correlation_uses_the_resolved_owner_chain() -> None:
+    k8s = RolloutKubernetesClient(
+        replace(
+            _empty_context(),
+            workload="checkout",
+            owner_chain=[
+                {"kind": "Pod", "name": "demo-pod"},
+                {"kind": "ReplicaSet", "name": "checkout-5c8d"},
+                {"kind": "Deployment", "name": "checkout"},
+            ],
+        ),
+        [],
+    )
+    service = AnalysisService(k8s, analysis_engine=None, rollout_correlation_window_minutes=30)
+
+    _, _, _, ctx, _ = service.analyze(_rollout_request())
+
+    assert k8s.revision_calls == [("default", "checkout")]
+    assert ctx["owner_chain"][-1] == {"kind": "Deployment", "name": "checkout"}
+
+
+def test_rollout_history_read_failure_is_a_warning() -> None:
+    k8s = RolloutKubernetesClient(_empty_context(), None)
+    service = AnalysisService(k8s, analysis_engine=None, rollout_correlation_window_minutes=30)
+
+    _, _, _, ctx, _ = service.analyze(_rollout_request())
+
+    assert ctx["recent_rollouts"] == []
+    assert "failed to read rollout history of deployment default/demo" in ctx["warnings"]
+
+
+def _oom_killed_context() -> K8sContext:
+    return K8sContext(
+        namespace="default",
+        pod_name="demo-pod",
+        workload=None,
+        pod_status=PodStatusSnapshot(
+            phase="Running",
+            node_name=None,
+            start_time=None,
+            reason=None,
+            message=None,
+            conditions=[],
+            container_statuses=[
+                {
+                    "name": "app",
+                    "restart_count": 3,
+                    "state": {"type": "running"},
+                    "last_state": {"type": "terminated", "reason": "OOMKilled"},
+                }
+            ],
+        ),
+        events=[],
+        previous_logs=[],
+        warnings=[],
+    )
+
+
+def test_pipeline_profile_with_rules_before_llm_hands_findings_to_the_prompt() -> None:
+    client = FakeKubernetesClient(_oom_killed_context())
+    engine = CapturingAnalysisEngine("## 요약\nok\n## 상세 분석\ndetail")
+    service = AnalysisService(
+        client,
+        analysis_engine=engine,
+        pipelines=PipelineRegistry(
+            (("default", ("collect", "correlate", "rules", "llm", "format", "deliver")),)
+        ),
+    )
+
+    _, _, _, ctx, artifacts = service.analyze(_sample_request())
+
+    assert "Rule-based findings" in engine.last_prompt
+    assert "- [critical] Container was OOMKilled" in engine.last_prompt
+    assert ctx["pipeline"] == {
+        "profile": "default",
+        "stages": ["collect", "correlate", "rules", "llm", "format", "deliver"],
+    }
+    assert ctx["degraded"] is False
+    assert [item["type"] for item in artifacts if item["type"] == "rule_finding"] == [
+        "rule_finding"
+    ]
+
+
+def test_pipeline_profile_without_llm_format_and_deliver_returns_raw_rule_analysis() -> None:
+    engine = RecordingAnalysisEngine("unused")
+    store = FakeSummaryStore([])
+    service = AnalysisService(
+        FakeKubernetesClient(_oom_killed_context()),
+        analysis_engine=engine,
+        summary_store=store,
+        pipelines=PipelineRegistry((("severity:info", ("collect", "rules")),)),
+    )
+    request = _sample_request()
+    request.alert.labels["severity"] = "info"
+
+    analysis, summary, detail, ctx, artifacts = service.analyze(request)
+
+    assert engine.calls == []
+    assert store.appended == []
+    assert ctx["degraded_reason"] == "llm_disabled"
+    assert ctx["pipeline"]["profile"] == "severity:info"
+    assert "LLM stage disabled by analysis pipeline profile severity:info" in analysis
+    assert "Container was OOMKilled" in analysis
+    assert (summary, detail, artifacts) == (analysis, "", [])
+
+
+def test_pipeline_profile_with_correlation_after_llm_keeps_rollouts_out_of_the_prompt() -> None:
+    k8s = RolloutKubernetesClient(
+        _empty_context(),
+        [
+            {
+                "revision": 3,
+                "replica_set": "demo-5c8d",
+                "created": "2026-10-14T01:56:00+00:00",
+                "containers": [{"name": "demo", "image": "demo:1.3"}],
+            }
+        ],
+    )
+    engine = CapturingAnalysisEngine("## 요약\nok\n## 상세 분석\ndetail")
+    service = AnalysisService(
+        k8s,
+        analysis_engine=engine,
+        rollout_correlation_window_minutes=30,
+        pipelines=PipelineRegistry((("HighErrorRate", ("collect", "llm", "correlate")),)),
+    )
+
+    _, _, _, ctx, _ = service.analyze(_rollout_request())
+
+    assert "demo-5c8d" not in engine.last_prompt
+    assert [item["revision"] for item in ctx["recent_rollouts"]] == [3]
+
+
+class FakeCloudStatus:
+    region = "us-east-1"
+    services = ("ec2",)
+
+    def active_incidents(self) -> tuple[list[dict[str, object]], list[str]]:
+        return [
+            {
+                "provider": "aws",
+                "id": "ec2-errors",
+                "title": "Increased API Error Rates",
+                "summary": "",
+                "services": ["ec2"],
+                "regions": ["us-east-1"],
+                "started_at": "2026-10-14T01:50:00+00:00",
+                "ended_at": None,
+                "url": "https://health.aws.amazon.com/health/status",
+            }
+        ], ["https://status.aws.amazon.com/rss/eks-us-east-1.rss: timed out"]
+
+
+def test_matching_cloud_incident_is_added_to_context_and_prompt() -> None:
+    engine = RecordingAnalysisEngine("## 요약\nok\n## 상세 분석\ndetail")
+    service = AnalysisService(
+        FakeKubernetesClient(_empty_context()),
+        analysis_engine=engine,
+        cloud_status=FakeCloudStatus(),  # type: ignore[arg-type]
+    )
+
+    _, _, _, ctx, _ = service.analyze(_rollout_request())
+
+    assert ctx["cloud_incidents"][0]["matched_services"] == ["ec2"]
+    assert ctx["capabilities"]["cloud_status"] == "ok"
+    assert any("cloud status feed unavailable" in warning for warning in ctx["warnings"])
+    assert "Possible upstream cloud incident" in engine.calls[0][0]
+
+
+def test_degraded_analysis_mentions_matching_cloud_incident() -> None:
+    service = AnalysisService(
+        FakeKubernetesClient(_empty_context()),
+        analysis_engine=None,
+        cloud_status=FakeCloudStatus(),  # type: ignore[arg-type]
+    )
+
+    analysis, _, _, _, _ = service.analyze(_rollout_request())
+
+    assert "possible upstream cloud incident: [aws] Increased API Error Rates" in analysis
+
+
+class FakeServiceCatalog:
+    service_labels = ("service",)
+
+    def __init__(self, entry: dict[str, object] | None = None) -> None:
+        self.entry = entry
+        self.lookups: list[tuple[str, str | None]] = []
+
+    def lookup(self, service: str, namespace: str | None = None) -> dict[str, object] | None:
+        self.lookups.append((service, namespace))
+        return self.entry
+
+
+_CHECKOUT_ENTRY: dict[str, object] = {
+    "source": "backstage",
+    "service": "checkout",
+    "tier": "tier-1",
+    "owner": "group:payments",
+    "lifecycle": "production",
+    "system": "shop",
+    "description": "Checkout API",
+    "dependencies": ["resource:payments-db"],
+    "runbooks": [{"title": "On-call runbook", "url": "https://wiki.example.com/checkout"}],
+}
+
+
+def test_service_catalog_entry_enriches_context_and_prompt() -> None:
+    engine = RecordingAnalysisEngine("## 요약\nok\n## 상세 분석\ndetail")
+    catalog = FakeServiceCatalog(_CHECKOUT_ENTRY)
+    service = AnalysisService(
+        FakeKubernetesClient(_empty_context()),
+        analysis_engine=engine,
+        service_catalog=catalog,  # type: ignore[arg-type]
+    )
+    request = _rollout_request()
+    request.alert.labels["service"] = "checkout"
+
+    _, _, _, ctx, _ = service.analyze(request)
+
+    assert catalog.lookups == [("checkout", "default")]
+    assert ctx["service_catalog"]["owner"] == "group:payments"
+    assert ctx["capabilities"]["service_catalog"] == "ok"
+    assert "Service context (from the service catalog" in engine.calls[0][0]
+    assert '"tier": "tier-1"' in engine.calls[0][0]
+
+
+def test_degraded_analysis_lists_catalog_owner_and_unknown_services_warn() -> None:
+    service = AnalysisService(
+        FakeKubernetesClient(_empty_context()),
+        analysis_engine=None,
+        service_catalog=FakeServiceCatalog(_CHECKOUT_ENTRY),  # type: ignore[arg-type]
+    )
+    request = _rollout_request()
+    request.alert.labels["service"] = "checkout"
+
+    analysis, _, _, _, _ = service.analyze(request)
+
+    assert "service: checkout (tier=tier-1, owner=group:payments)" in analysis
+    assert "runbook: https://wiki.example.com/checkout" in analysis
+
+    catalog = FakeServiceCatalog()
+    service = AnalysisService(
+        FakeKubernetesClient(_empty_context()),
+        analysis_engine=None,
+        service_catalog=catalog,  # type: ignore[arg-type]
+    )
+    request = _rollout_request()
+    request.alert.labels["app"] = "demo"
+
+    _, _, _, ctx, _ = service.analyze(request)
+
+    assert catalog.lookups == [("demo", "default")]
+    assert "service demo not found in the service catalog" in ctx["warnings"]
+    assert "service_catalog" not in ctx
+
+
+class FakeKubeState:
+    def __init__(self, snapshot: dict[str, object] | None) -> None:
+        self.snapshot_value = snapshot
+        self.calls: list[tuple[str, str | None, str | None, datetime | None]] = []
+
+    def snapshot(
+        self,
+        namespace: str,
+        *,
+        pod_name: str | None,
+        workload: str | None,
+        at: datetime | None = None,
+    ) -> dict[str, object] | None:
+        self.calls.append((namespace, pod_name, workload, at))
+        return self.snapshot_value
+
+
+def test_kube_state_snapshot_enriches_context_prompt_and_degraded_summary() -> None:
+    snapshot: dict[str, object] = {
+        "source": "prometheus",
+        "evaluated_at": "2026-10-14T02:00:00+00:00",
+        "pod": {"name": "demo-pod", "phase": "Running", "ready": False, "containers": []},
+        "workload": None,
+        "findings": ["pod demo-pod is not ready"],
+    }
+    engine = RecordingAnalysisEngine("## 요약\nok\n## 상세 분석\ndetail")
+    kube_state = FakeKubeState(snapshot)
+    service = AnalysisService(
+        FakeKubernetesClient(_empty_context()),
+        analysis_engine=engine,
+        kube_state=kube_state,  # type: ignore[arg-type]
+    )
+
+    _, _, _, ctx, _ = service.analyze(_rollout_request())
+
+    assert kube_state.calls == [
+        ("default", "demo-pod", None, datetime(2026, 10, 14, 2, 0, tzinfo=timezone.utc))
+    ]
+    assert ctx["kube_state"]["findings"] == ["pod demo-pod is not ready"]
+    assert ctx["capabilities"]["kube_state_metrics"] == "ok"
+    assert "Object state from kube-state-metrics" in engine.calls[0][0]
+    assert "- pod demo-pod is not ready" in engine.calls[0][0]
+
+    degraded = AnalysisService(
+        FakeKubernetesClient(_empty_context()),
+        analysis_engine=None,
+        kube_state=FakeKubeState(snapshot),  # type: ignore[arg-type]
+    )
+    analysis, _, _, _, _ = degraded.analyze(_rollout_request())
+    assert "kube_state: pod demo-pod is not ready" in analysis
+
+    missing = AnalysisService(
+        FakeKubernetesClient(_empty_context()),
+        analysis_engine=None,
+        kube_state=FakeKubeState(None),  # type: ignore[arg-type]
+    )
+    _, _, _, ctx, _ = missing.analyze(_rollout_request())
+    assert "no kube-state-metrics series for the alerted objects in default" in ctx["warnings"]
+    assert "kube_state" not in ctx
+
+
+class FakeArtifactOffloader:
+    def __init__(self) -> None:
+        self.calls: list[tuple[str | None, list[dict[str, object]]]] = []
+        self.folders: list[tuple[str | None, str | None]] = []
+
+    def offload(
+        self,
+        analysis_id: str | None,
+        artifacts: list[dict[str, object]],
+        *,
+        namespace: str | None = None,
+        incident_id: str | None = None,
+    ) -> list[dict[str, object]]:
+        self.calls.append((analysis_id, artifacts))
+        self.folders.append((namespace, incident_id))
+        return [
+            {**item, "result": None, "attachment": {"key": f"artifacts/{index}"}}
+            for index, item in enumerate(artifacts)
+        ]
+
+
+def test_analysis_service_offloads_artifacts_with_full_logs() -> None:
+    logs = [f"line {index}" for index in range(50)]
+    context = replace(
+        _empty_context(),
+        previous_logs=[PodLogSnippet(container="app", previous=True, logs=logs)],
+    )
+    offloader = FakeArtifactOffloader()
+    service = AnalysisService(
+        FakeKubernetesClient(context),
+        analysis_engine=FakeAnalysisEngine("ok"),
+        artifact_offloader=offloader,  # type: ignore[arg-type]
+    )
+
+    _, _, _, ctx, artifacts = service.analyze(_sample_request())
+
+    analysis_id, uploaded = offloader.calls[0]
+    assert analysis_id == ctx["analysis_id"]
+    assert offloader.folders == [("default", None)]
+    log = next(item for item in uploaded if item["type"] == "log")
+    assert log["result"]["logs"] == logs
+    assert all(item["result"] is None and item["attachment"] for item in artifacts)
+
+
+class NamespaceSnapshotKubernetesClient(FakeKubernetesClient):
+    def __init__(self, context: K8sContext) -> None:
+        super().__init__(context)
+        self.snapshot_calls: list[tuple[str, int, int]] = []
+
+    def get_namespace_snapshot(
+        self, namespace: str, *, max_workloads: int, max_pods: int
+    ) -> dict[str, object] | None:
+        self.snapshot_calls.append((namespace, max_workloads, max_pods))
+        return {
+            "namespace": namespace,
+            "workloads": [
+                {
+                    "kind": "Deployment",
+                    "name": "checkout",
+                    "desired": 3,
+                    "ready": 1,
+                    "available": 1,
+                    "updated": 3,
+                    "healthy": False,
+                }
+            ],
+            "workload_count": 4,
+            "unhealthy_workload_count": 1,
+            "failing_pods": [],
+            "pod_count": 9,
+            "failing_pod_count": 0,
+            "warnings": ["failed to list daemonsets in default"],
+        }
+
+
+def test_namespace_snapshot_is_collected_for_alerts_without_a_pod() -> None:
+    k8s = NamespaceSnapshotKubernetesClient(replace(_empty_context(), pod_name=None))
+    service = AnalysisService(
+        k8s,
+        analysis_engine=FakeAnalysisEngine("ok"),
+        namespace_snapshot_enabled=True,
+        namespace_snapshot_max_pods=5,
+    )
+    request = AlertAnalysisRequest(
+        alert=Alert(
+            status="firing",
+            labels={"alertname": "NamespaceErrorRateHigh", "namespace": "default"},
+            annotations={},
+        ),
+        thread_ts="1234567890.123456",
+    )
+
+    _, _, _, ctx, _ = service.analyze(request)
+
+    assert k8s.snapshot_calls == [("default", 20, 5)]
+    assert ctx["namespace_snapshot"]["findings"][:2] == [
+        "namespace default: 1/4 workload(s) below their desired replicas, 0/9 pod(s) failing",
+        "Deployment checkout: 1/3 ready, 1 available, 3 updated",
+    ]
+    assert "failed to list daemonsets in default" in ctx["warnings"]
+    pod_alert = NamespaceSnapshotKubernetesClient(_empty_context())
+    AnalysisService(
+        pod_alert, analysis_engine=FakeAnalysisEngine("ok"), namespace_snapshot_enabled=True
+    ).analyze(_sample_request())
+    assert pod_alert.snapshot_calls == []
@@ -0,0 +1,161 @@
+from __future__ import annotations
+
+from app.services.analysis_view import (
+    build_analysis_timeline,
+    describe_stored_analysis,
+    summarize_stored_analysis,
+)
+
+
+def _row(**overrides: object) -> dict[str, object]:
+    row: dict[str, object] = {
+        "result_id": 42,
+        "session_key": "alert:abc123",
+        "analysis_id": "alert:abc123:run:1f2e3d4c",
+        "alertname": "KubePodCrashLooping",
+        "namespace": "shop",
+        "fingerprint": "abc123",
+        "incident_id": None,
+        "alert_status": "resolved",
+        "pipeline_version": "v2",
+        "source": "live",
+        "backfill_job_id": None,
+        "created_at": "2026-10-14T09:20:00+00:00",
+        "request": {
+            "alert": {
+                "status": "resolved",
+                "labels": {"alertname": "KubePodCrashLooping", "namespace": "shop"},
+                "annotations": {"summary": "checkout restarts"},
+                "startsAt": "2026-10-14T09:05:00Z",
+                "endsAt": "2026-10-14T09:40:00Z",
+            }
+        },
+        "result": {
+            "analysis": "full text",
+            "analysis_summary": "checkout crashes after the v1.4.0 rollout " * 10,
+            "analysis_detail": "detail",
+            "context": {
+                "analysis_id": "alert:abc123:run:1f2e3d4c",
+                "namespace": "shop",
+                "pod_name": "checkout-7d9f8c-abcde",
+                "analysis_quality": "high",
+                "warnings": ["loki unavailable"],
+                "missing_data": [],
+                "degraded": False,
+                "crash_loop": {"exit_code": 1},
+                "previous_logs": [],
+                "recent_rollouts": [
+                    {
+                        "deployment": "checkout",
+                        "revision": 7,
+                        "created": "2026-10-14T09:01:00Z",
+                        "images": ["shop/checkout:v1.4.0"],
+                    }
+                ],
+                "events": [
+                    {
+                        "type": "Warning",
+                        "reason": "BackOff",
+                        "message": "Back-off restarting failed container",
+                        "count": 6,
+                        "first_timestamp": "2026-10-14T09:03:00Z",
+                        "last_timestamp": "2026-10-14T09:10:00Z",
+                        "involved_object": {"kind": "Pod", "name": "checkout-7d9f8c-abcde"},
+                    },
+                    {"type": "Normal", "reason": "Pulled", "message": "no time"},
+                ],
+            },
+            "artifacts": [],
+        },
+    }
+    row.update(overrides)
+    return row
+
+
+def test_summarize_stored_analysis_keeps_headline_fields_and_shortens_the_summary() -> None:
+    summary = summarize_stored_analysis(_row())
+
+    assert summary["result_id"] == 42
+    assert summary["pod_name"] == "checkout-7d9f8c-abcde"
+    assert summary["analysis_quality"] == "high"
+    assert summary["degraded"] is False
+    assert str(summary["summary"]).endswith("...")
+    assert len(str(summary["summary"])) <= 283
+
+
+def test_describe_stored_analysis_builds_evidence_and_an_ordered_timeline() -> None:
+    view = describe_stored_analysis(_row())
+
+    assert view["annotations"] == {"summary": "checkout restarts"}
+    assert view["warnings"] == ["loki unavailable"]
+    evidence = view["evidence"]
+    assert isinstance(evidence, dict)
+    assert set(evidence) == {"crash_loop", "recent_rollouts", "events"}
+    assert view["timeline"] == [
+        {
+            "time": "2026-10-14T09:01:00+00:00",
+            "kind": "rollout",
+            "text": "deployment checkout rolled out revision 7 (shop/checkout:v1.4.0)",
+        },
+        {
+            "time": "2026-10-14T09:05:00+00:00",
+            "kind": "alert",
+            "text": "KubePodCrashLooping started firing",
+        },
+        {
+            "time": "2026-10-14T09:10:00+00:00",
+            "kind": "event",
+            "text": "Warning BackOff on Pod/checkout-7d9f8c-abcde: "
+            "Back-off restarting failed container (x6)",
+        },
+        {"time": "2026-10-14T09:20:00+00:00", "kind": "analysis", "text": "analysis stored"},
+        {
+            "time": "2026-10-14T09:40:00+00:00",
+            "kind": "alert",
+            "text": "KubePodCrashLooping resolved",
+        },
+    ]
+
+
+def test_describe_stored_analysis_tolerates_rows_without_payloads() -> None:
+    view = describe_stored_analysis(_row(request={}, result={}, created_at=None))
+
+    assert view["summary"] == ""
+    assert view["evidence"] == {}
+    assert view["timeline"] == []
+
+
+def test_analysis_timeline_shows_who_evicted_which_pod() -> None:
+    message = "Preempted by shop/batch-high on node node-2"
+    context = {
+        "events": [
+            {
+                "type": "Normal",
+                "reason": "Preempted",
+                "message": message,
+                "last_timestamp": "2026-10-14T09:02:00Z",
+            }
+        ],
+        "eviction_analysis": {
+            "evictions": [
+                {
+                    "pod": "checkout-7d9f8c-abcde",
+                    "kind": "preemption",
+                    "evicted_by": "scheduler",
+                    "node": "node-2",
+                    "message": message,
+                    "time": "2026-10-14T09:02:00Z",
+                }
+            ]
+        },
+    }
+
+    timeline = build_analysis_timeline({}, context, None)
+
+    assert timeline == [
+        {
+            "time": "2026-10-14T09:02:00+00:00",
+            "kind": "eviction",
+            "text": "scheduler preempted pod checkout-7d9f8c-abcde on node-2: " + message,
+        }
+    ]
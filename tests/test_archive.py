@@ -0,0 +1,158 @@
+from __future__ import annotations
+
+import json
+import threading
+from collections.abc import Callable
+from datetime import datetime, timedelta, timezone
+
+import pytest
+
+from app.clients.object_storage import build_object_store
+from app.core.masking import RegexMasker
+from app.schemas.alert import Alert
+from app.schemas.analysis import AlertAnalysisRequest
+from app.services.archive import AnalysisArchiver, render_report
+
+
+class FakeObjectStore:
+    def __init__(self) -> None:
+        self.objects: dict[str, tuple[bytes, str]] = {}
+        self.purges: list[str] = []
+        self.modified: dict[str, datetime] = {}
+        self.release = threading.Event()
+        self.release.set()
+        self.stored = threading.Event()
+
+    def put(self, key: str, body: bytes, content_type: str) -> None:
+        self.release.wait(5)
+        self.objects[key] = (body, content_type)
+        if key.endswith(".md"):
+            self.stored.set()
+
+    def delete_matching(self, prefix: str, matches: Callable[[str, datetime], bool]) -> int:
+        self.purges.append(prefix)
+        now = datetime.now(timezone.utc)
+        deleted = [
+            key
+            for key in self.objects
+            if key.startswith(prefix) and matches(key, self.modified.get(key, now))
+        ]
+        for key in deleted:
+            del self.objects[key]
+        return len(deleted)
+
+
+def _request() -> AlertAnalysisRequest:
+    return AlertAnalysisRequest(
+        alert=Alert(
+            status="firing",
+            labels={"alertname": "KubePodCrashLooping", "namespace": "default"},
+            annotations={"summary": "token=abcdefgh12345678"},
+            fingerprint="abc123",
+        ),
+        thread_ts="1234567890.123456",
+        incident_id="INC-1",
+    )
+
+
+def _response(**overrides: object) -> dict[str, object]:
+    response: dict[str, object] = {
+        "status": "ok",
+        "analysis": "## 요약\nOOMKilled\n## 상세 분석\ndetail",
+        "analysis_id": "alert:abc123:run:1234abcd",
+        "analysis_quality": "high",
+        "degraded": False,
+        "context": {"namespace": "default"},
+    }
+    response.update(overrides)
+    return response
+
+
+def test_export_writes_json_and_markdown_under_dated_prefix() -> None:
+    store = FakeObjectStore()
+    archiver = AnalysisArchiver(
+        store,
+        prefix="/archive/rca/",
+        masker=RegexMasker.from_patterns([r"abcdefgh\d+"]),
+    )
+
+    key = archiver.export(_request(), _response())
+
+    today = datetime.now(timezone.utc)
+    assert key == f"archive/rca/{today:%Y/%m/%d}/default/INC-1/alert_abc123_run_1234abcd"
+    body, content_type = store.objects[f"{key}.json"]
+    record = json.loads(body)
+    assert content_type == "application/json"
+    assert record["incident_id"] == "INC-1"
+    assert record["response"]["analysis_id"] == "alert:abc123:run:1234abcd"
+    assert "abcdefgh12345678" not in body.decode("utf-8")
+    report, report_type = store.objects[f"{key}.md"]
+    assert report_type.startswith("text/markdown")
+    assert "OOMKilled" in report.decode("utf-8")
+
+
+def test_render_report_lists_alert_metadata() -> None:
+    report = render_report(
+        {
+            "archived_at": "2026-01-01T00:00:00+00:00",
+            "alert": {"status": "firing", "labels": {"alertname": "KubeNodeNotReady"}},
+            "incident_id": None,
+            "response": _response(degraded=True, degraded_reason="engine_error", context={}),
+        }
+    )
+
+    assert report.startswith("# KubeNodeNotReady\n")
+    assert "| Degraded | yes (engine_error) |" in report
+    assert "| Incident | - |" in report
+
+
+def test_submit_exports_in_background_and_skips_when_backlog_is_full() -> None:
+    store = FakeObjectStore()
+    store.release.clear()
+    archiver = AnalysisArchiver(store, max_pending=1)
+
+    assert archiver.submit(_request(), _response()) is True
+    assert archiver.submit(_request(), _response()) is False
+    store.release.set()
+    assert store.stored.wait(5)
+
+
+def test_purge_deletes_objects_under_prefix() -> None:
+    store = FakeObjectStore()
+    archiver = AnalysisArchiver(store, prefix="kube-rca/analyses")
+    key = archiver.export(_request(), _response())
+    store.modified[f"{key}.json"] = datetime.now(timezone.utc) - timedelta(days=31)
+
+    assert archiver.purge_older_than(30) == 1
+    assert store.purges == ["kube-rca/analyses/"]
+    assert list(store.objects) == [f"{key}.md"]
+
+
+def test_delete_analyses_matches_namespace_incident_and_upload_time() -> None:
+    store = FakeObjectStore()
+    archiver = AnalysisArchiver(store, prefix="kube-rca/analyses")
+    key = archiver.export(_request(), _response())
+    other = _request().model_copy(update={"incident_id": "INC-10"})
+    other_key = archiver.export(other, _response(analysis_id="alert:abc123:run:5678ef90"))
+    legacy = "kube-rca/analyses/2026/01/02/default/alert_abc123_run_0000aaaa.json"
+    store.objects[legacy] = (b"{}", "application/json")
+    store.modified[legacy] = datetime(2026, 1, 2, tzinfo=timezone.utc)
+
+    assert archiver.delete_analyses(namespace="payments") == 0
+    assert archiver.delete_analyses(since=datetime.now(timezone.utc) + timedelta(hours=1)) == 0
+    assert archiver.delete_analyses(namespace="default", incident_id="INC-1") == 2
+    assert sorted(store.objects) == [legacy, f"{other_key}.json", f"{other_key}.md"]
+    assert f"{key}.json" not in store.objects
+
+    assert archiver.delete_analyses(until=datetime(2026, 2, 1, tzinfo=timezone.utc)) == 1
+    assert legacy not in store.objects
+
+
+def test_build_object_store_rejects_unknown_backend() -> None:
+    with pytest.raises(ValueError, match="unknown archive backend"):
+        build_object_store("ftp", "bucket")
+
+
+def test_build_object_store_requires_account_url_for_azure() -> None:
+    with pytest.raises(ValueError):
+        build_object_store("azure", "container")
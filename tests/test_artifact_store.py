@@ -0,0 +1,122 @@
+from __future__ import annotations
+
+import hashlib
+import json
+from collections.abc import Callable
+from datetime import datetime, timezone
+
+import pytest
+
+from app.clients.object_storage import build_object_store
+from app.schemas.analysis import AlertAnalysisArtifact
+from app.services.artifact_store import ArtifactOffloader
+
+
+class FakeSignedStore:
+    def __init__(self, fail: bool = False) -> None:
+        self.objects: dict[str, tuple[bytes, str]] = {}
+        self.signed: list[tuple[str, int]] = []
+        self._fail = fail
+
+    def put(self, key: str, body: bytes, content_type: str) -> None:
+        if self._fail:
+            raise RuntimeError("access denied")
+        self.objects[key] = (body, content_type)
+
+    def delete_matching(self, prefix: str, matches: Callable[[str, datetime], bool]) -> int:
+        deleted = [key for key in self.objects if key.startswith(prefix) and matches(key, _clock())]
+        for key in deleted:
+            del self.objects[key]
+        return len(deleted)
+
+    def signed_url(self, key: str, expires_in_seconds: int) -> str:
+        self.signed.append((key, expires_in_seconds))
+        return f"https://artifacts.example.com/{key}?sig=abc"
+
+
+def _clock() -> datetime:
+    return datetime(2026, 10, 14, 9, 30, tzinfo=timezone.utc)
+
+
+def _log_artifact(lines: int) -> dict[str, object]:
+    return {
+        "type": "log",
+        "summary": f"api previous logs ({lines} lines)",
+        "result": {"container": "api", "logs": [f"line {index}" for index in range(lines)]},
+    }
+
+
+def test_offloader_uploads_large_artifacts_and_keeps_small_ones_inline() -> None:
+    store = FakeSignedStore()
+    offloader = ArtifactOffloader(
+        store,
+        prefix="/kube-rca/artifacts/",
+        inline_max_bytes=200,
+        url_ttl_seconds=3600,
+        clock=_clock,
+    )
+    small = {"type": "event", "summary": "BackOff", "result": {"reason": "BackOff"}}
+    chart = {"type": "rendered chart", "summary": "values", "result": "x" * 500}
+
+    artifacts = offloader.offload(
+        "alert:abc123:run:1f2e", [small, _log_artifact(100), chart], namespace="shop"
+    )
+
+    assert artifacts[0] is small
+    log = artifacts[1]
+    assert log["result"] is None
+    assert log["summary"] == "api previous logs (100 lines)"
+    attachment = log["attachment"]
+    assert isinstance(attachment, dict)
+    key = "kube-rca/artifacts/2026/10/14/shop/_/alert-abc123-run-1f2e/01-log.json"
+    body, content_type = store.objects[key]
+    assert json.loads(body)["logs"][-1] == "line 99"
+    assert content_type == "application/json; charset=utf-8"
+    assert attachment == {
+        "key": key,
+        "url": f"https://artifacts.example.com/{key}?sig=abc",
+        "content_type": "application/json",
+        "size_bytes": len(body),
+        "sha256": hashlib.sha256(body).hexdigest(),
+        "expires_at": "2026-10-14T10:30:00+00:00",
+    }
+    assert "kube-rca/artifacts/2026/10/14/shop/_/alert-abc123-run-1f2e/02-rendered-chart.txt" in (
+        store.objects
+    )
+    assert store.signed[0] == (key, 3600)
+    parsed = AlertAnalysisArtifact.model_validate(log)
+    assert parsed.attachment is not None and parsed.attachment.size_bytes == len(body)
+
+
+def test_offloader_keeps_artifacts_inline_when_the_upload_fails() -> None:
+    offloader = ArtifactOffloader(FakeSignedStore(fail=True), inline_max_bytes=10, clock=_clock)
+    artifact = _log_artifact(5)
+
+    assert offloader.offload(None, [artifact]) == [artifact]
+
+
+def test_delete_analyses_matches_namespace_and_incident_segments() -> None:
+    store = FakeSignedStore()
+    offloader = ArtifactOffloader(store, inline_max_bytes=10, clock=_clock)
+    for incident_id in ("INC-1", "INC-2"):
+        offloader.offload(
+            f"{incident_id}:abc:run:1f2e",
+            [_log_artifact(5)],
+            namespace="shop",
+            incident_id=incident_id,
+        )
+    legacy = "kube-rca/artifacts/2026/10/01/alert-abc-run-0000/00-log.json"
+    store.objects[legacy] = (b"{}", "application/json")
+
+    assert offloader.delete_analyses(namespace="web") == 0
+    assert offloader.delete_analyses(namespace="shop", incident_id="INC-1") == 1
+    assert [key.split("/")[6] for key in store.objects if key != legacy] == ["INC-2"]
+    assert offloader.delete_analyses(until=datetime(2026, 10, 15, tzinfo=timezone.utc)) == 2
+    assert store.objects == {}
+
+
+def test_build_object_store_names_the_settings_of_the_artifact_store() -> None:
+    with pytest.raises(ValueError, match="ARTIFACT_STORE_BUCKET is required"):
+        build_object_store("s3", "", setting_prefix="ARTIFACT_STORE")
+    with pytest.raises(ValueError, match="unknown artifact store backend"):
+        build_object_store("ftp", "bucket", setting_prefix="ARTIFACT_STORE")
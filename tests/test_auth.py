@@ -0,0 +1,192 @@
+from __future__ import annotations
+
+import asyncio
+import time
+from types import SimpleNamespace
+
+import jwt
+import pytest
+from cryptography.hazmat.primitives.asymmetric import rsa
+from fastapi import HTTPException
+
+from app.api.auth import authenticate_websocket, require_admin
+from app.core import auth
+from app.core.auth import (
+    AuthenticationError,
+    AuthorizationError,
+    OIDCVerifier,
+    build_oidc_verifier,
+)
+from app.core.config import load_settings
+
+_ISSUER = "https://sso.example.com/realms/ops"
+_AUDIENCE = "kube-rca-agent"
+_PRIVATE_KEY = rsa.generate_private_key(public_exponent=65537, key_size=2048)
+
+
+class _StaticKeyResolver:
+    def get_signing_key_from_jwt(self, token: str) -> SimpleNamespace:
+        return SimpleNamespace(key=_PRIVATE_KEY.public_key())
+
+
+def _token(**overrides: object) -> str:
+    claims: dict[str, object] = {
+        "iss": _ISSUER,
+        "aud": _AUDIENCE,
+        "sub": "alice",
+        "exp": int(time.time()) + 300,
+        "groups": ["sre"],
+    }
+    claims.update(overrides)
+    return jwt.encode(claims, _PRIVATE_KEY, algorithm="RS256")
+
+
+def _verifier(
+    allowed_groups: tuple[str, ...] = ("sre",), groups_claim: str = "groups"
+) -> OIDCVerifier:
+    return OIDCVerifier(
+        _ISSUER,
+        _AUDIENCE,
+        groups_claim=groups_claim,
+        allowed_groups=allowed_groups,
+        key_resolver=_StaticKeyResolver(),
+    )
+
+
+def test_valid_token_in_allowed_group_is_authorized() -> None:
+    principal = _verifier().authenticate(_token())
+
+    assert principal.subject == "alice"
+    assert principal.groups == ("sre",)
+
+
+def test_token_for_other_audience_or_issuer_is_rejected() -> None:
+    with pytest.raises(AuthenticationError):
+        _verifier().authenticate(_token(aud="another-app"))
+    with pytest.raises(AuthenticationError):
+        _verifier().authenticate(_token(iss="https://evil.example.com"))
+
+
+def test_issuer_is_compared_exactly_including_a_trailing_slash(
+    monkeypatch: pytest.MonkeyPatch,
+) -> None:
+    issuer = "https://tenant.auth0.com/"
+    verifier = OIDCVerifier(issuer, _AUDIENCE, key_resolver=_StaticKeyResolver())
+
+    assert verifier.authenticate(_token(iss=issuer)).subject == "alice"
+    with pytest.raises(AuthenticationError):
+        verifier.authenticate(_token(iss=issuer.rstrip("/")))
+
+    requested: list[str] = []
+
+    class _Discovery:
+        def __enter__(self) -> _Discovery:
+            return self
+
+        def __exit__(self, *exc: object) -> None:
+            return None
+
+        def read(self) -> bytes:
+            return b'{"jwks_uri": "https://tenant.auth0.com/.well-known/jwks.json"}'
+
+    def fake_urlopen(url: str, timeout: float) -> _Discovery:
+        requested.append(url)
+        return _Discovery()
+
+    monkeypatch.setattr(auth.urllib.request, "urlopen", fake_urlopen)
+    assert auth._discover_jwks_url(issuer) == "https://tenant.auth0.com/.well-known/jwks.json"
+    assert requested == ["https://tenant.auth0.com/.well-known/openid-configuration"]
+
+
+def test_expired_token_is_rejected() -> None:
+    with pytest.raises(AuthenticationError):
+        _verifier().authenticate(_token(exp=int(time.time()) - 60))
+
+
+def test_user_outside_allowed_groups_is_forbidden() -> None:
+    with pytest.raises(AuthorizationError):
+        _verifier().authenticate(_token(groups=["developers"]))
+
+
+def test_nested_groups_claim_is_supported() -> None:
+    verifier = _verifier(groups_claim="realm_access.roles")
+
+    principal = verifier.authenticate(_token(realm_access={"roles": ["sre", "viewer"]}))
+
+    assert principal.groups == ("sre", "viewer")
+
+
+def test_any_valid_token_is_authorized_without_group_restriction() -> None:
+    principal = _verifier(allowed_groups=()).authenticate(_token(groups=[]))
+
+    assert principal.subject == "alice"
+
+
+def _request(authorization: str | None) -> SimpleNamespace:
+    headers = {"authorization": authorization} if authorization else {}
+    return SimpleNamespace(headers=headers, url=SimpleNamespace(path="/retention/purge"))
+
+
+def test_require_admin_is_noop_when_oidc_disabled() -> None:
+    assert asyncio.run(require_admin(_request(None), verifier=None)) is None
+
+
+def test_require_admin_maps_errors_to_http_status() -> None:
+    verifier = _verifier()
+
+    with pytest.raises(HTTPException) as missing:
+        asyncio.run(require_admin(_request(None), verifier=verifier))
+    developer = _request(f"Bearer {_token(groups=['developers'])}")
+    with pytest.raises(HTTPException) as forbidden:
+        asyncio.run(require_admin(developer, verifier=verifier))
+    principal = asyncio.run(require_admin(_request(f"Bearer {_token()}"), verifier=verifier))
+
+    assert missing.value.status_code == 401
+    assert forbidden.value.status_code == 403
+    assert principal is not None and principal.subject == "alice"
+
+
+def test_authenticate_websocket_reads_header_or_access_token_query_parameter() -> None:
+    verifier = _verifier()
+
+    def websocket(
+        authorization: str | None, access_token: str | None = None
+    ) -> SimpleNamespace:
+        return SimpleNamespace(
+            headers={"authorization": authorization} if authorization else {},
+            query_params={"access_token": access_token} if access_token else {},
+            url=SimpleNamespace(path="/analyses/a1/session"),
+        )
+
+    from_header = asyncio.run(authenticate_websocket(websocket(f"Bearer {_token()}"), verifier))
+    from_query = asyncio.run(
+        authenticate_websocket(websocket(None, _token(sub="bob")), verifier)
+    )
+
+    assert from_header is not None and from_header.subject == "alice"
+    assert from_query is not None and from_query.subject == "bob"
+    assert asyncio.run(authenticate_websocket(websocket(None), None)) is None
+    with pytest.raises(AuthenticationError):
+        asyncio.run(authenticate_websocket(websocket(None), verifier))
+    with pytest.raises(AuthorizationError):
+        asyncio.run(
+            authenticate_websocket(websocket(None, _token(groups=["developers"])), verifier)
+        )
+
+
+def test_build_oidc_verifier_requires_audience() -> None:
+    assert build_oidc_verifier("", "") is None
+    with pytest.raises(ValueError):
+        build_oidc_verifier(_ISSUER, "")
+
+
+def test_oidc_settings_from_env(monkeypatch: pytest.MonkeyPatch) -> None:
+    monkeypatch.setenv("OIDC_ISSUER_URL", _ISSUER)
+    monkeypatch.setenv("OIDC_AUDIENCE", _AUDIENCE)
+    monkeypatch.setenv("OIDC_ADMIN_GROUPS_JSON", '["sre", "platform"]')
+
+    settings = load_settings()
+
+    assert settings.oidc_issuer_url == _ISSUER
+    assert settings.oidc_groups_claim == "groups"
+    assert settings.oidc_admin_groups == ("sre", "platform")
@@ -0,0 +1,113 @@
+from __future__ import annotations
+
+import threading
+import time
+from typing import Any
+
+import pytest
+
+from app.clients.analysis_store import AnalysisFilter, StoredAnalysis
+from app.schemas.analysis import AlertAnalysisRequest
+from app.services.backfill import BackfillService
+
+
+def _payload(pod: str) -> dict[str, Any]:
+    return {
+        "alert": {
+            "status": "firing",
+            "labels": {"alertname": "KubePodCrashLooping", "namespace": "default", "pod": pod},
+            "annotations": {},
+        },
+        "thread_ts": "1234567890.123456",
+    }
+
+
+class FakeAnalysisStore:
+    def __init__(self, requests: list[tuple[int, dict[str, Any]]]) -> None:
+        self._requests = requests
+        self.filters: list[AnalysisFilter] = []
+
+    def record(self, analysis: StoredAnalysis) -> int:
+        return 0
+
+    def list_requests(
+        self, analysis_filter: AnalysisFilter, *, limit: int
+    ) -> list[tuple[int, dict[str, Any]]]:
+        self.filters.append(analysis_filter)
+        return self._requests[:limit]
+
+    def list_versions(
+        self, session_key: str, *, limit: int = 20, before: int | None = None
+    ) -> list[dict[str, object]]:
+        return []
+
+
+class FakeReanalyzer:
+    def __init__(self, fail_pods: set[str] | None = None) -> None:
+        self.release = threading.Event()
+        self.release.set()
+        self.calls: list[tuple[str | None, str]] = []
+        self._fail_pods = fail_pods or set()
+
+    def reanalyze(self, request: AlertAnalysisRequest, *, backfill_job_id: str) -> int | None:
+        self.release.wait(5)
+        pod = request.alert.labels.get("pod")
+        if pod in self._fail_pods:
+            raise RuntimeError("engine down")
+        self.calls.append((pod, backfill_job_id))
+        return 100 + len(self.calls)
+
+
+def _wait_for(service: BackfillService, job_id: str) -> dict[str, object]:
+    deadline = time.monotonic() + 5
+    while time.monotonic() < deadline:
+        job = service.job(job_id)
+        if job is not None and job["status"] != "running":
+            return job
+        time.sleep(0.01)
+    raise AssertionError("backfill job did not finish")
+
+
+def test_backfill_reanalyzes_stored_requests_in_order() -> None:
+    store = FakeAnalysisStore([(1, _payload("a")), (2, _payload("b")), (3, _payload("c"))])
+    reanalyzer = FakeReanalyzer()
+    service = BackfillService(store, reanalyzer)
+
+    started = service.start(AnalysisFilter(alertname="KubePodCrashLooping"), limit=2)
+    job = _wait_for(service, str(started["job_id"]))
+
+    assert job["status"] == "completed"
+    assert job["total"] == 2
+    assert job["completed"] == 2
+    assert job["result_ids"] == [101, 102]
+    assert reanalyzer.calls == [("a", started["job_id"]), ("b", started["job_id"])]
+    assert store.filters[0].alertname == "KubePodCrashLooping"
+
+
+def test_backfill_records_per_alert_failures_and_continues() -> None:
+    store = FakeAnalysisStore([(1, _payload("a")), (2, {"alert": "broken"}), (3, _payload("c"))])
+    service = BackfillService(store, FakeReanalyzer(fail_pods={"a"}))
+
+    job = _wait_for(service, str(service.start(AnalysisFilter(), limit=10)["job_id"]))
+
+    assert job["status"] == "completed"
+    assert job["completed"] == 1
+    assert job["failed"] == 2
+    assert "engine down" in str(job["errors"])
+    assert "stored request is invalid" in str(job["errors"])
+
+
+def test_backfill_allows_one_running_job_at_a_time() -> None:
+    reanalyzer = FakeReanalyzer()
+    reanalyzer.release.clear()
+    service = BackfillService(FakeAnalysisStore([(1, _payload("a"))]), reanalyzer)
+
+    first = service.start(AnalysisFilter(), limit=10)
+    with pytest.raises(RuntimeError, match="already running"):
+        service.start(AnalysisFilter(), limit=10)
+    reanalyzer.release.set()
+    _wait_for(service, str(first["job_id"]))
+
+    second = service.start(AnalysisFilter(), limit=10)
+    _wait_for(service, str(second["job_id"]))
+    assert [job["job_id"] for job in service.jobs()] == [second["job_id"], first["job_id"]]
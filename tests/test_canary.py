@@ -0,0 +1,72 @@
+from __future__ import annotations
+
+from app.services.canary import CanaryRollout
+
+
+def test_assignment_is_deterministic_and_respects_percent() -> None:
+    canary = CanaryRollout(30, prompt_instructions="new prompt")
+    keys = [f"alert:{index}" for index in range(1000)]
+
+    assigned = [key for key in keys if canary.assign(key)]
+
+    assert [key for key in keys if canary.assign(key)] == assigned
+    assert 200 < len(assigned) < 400
+    assert CanaryRollout(0).assign("alert:1") is False
+    assert all(CanaryRollout(100).assign(key) for key in keys[:50])
+
+
+def test_canary_rolls_back_when_its_error_rate_degrades() -> None:
+    canary = CanaryRollout(50, min_samples=5, max_error_rate_delta=0.2)
+    for _ in range(5):
+        canary.record(False, failed=False)
+    for index in range(5):
+        canary.record(True, failed=index < 2)
+
+    status = canary.status()
+    assert canary.active is False
+    assert canary.assign("alert:any") is False
+    assert "exceeds stable" in str(status["rollback_reason"])
+    assert status["canary"] == {"samples": 5, "error_rate": 0.4}
+
+
+def test_canary_stays_active_within_tolerance_and_reset_clears_rollback() -> None:
+    canary = CanaryRollout(50, min_samples=4, max_error_rate_delta=0.3)
+    for _ in range(4):
+        canary.record(False, failed=False)
+    canary.record(True, failed=True)
+    for _ in range(3):
+        canary.record(True, failed=False)
+    assert canary.active is True
+
+    canary.record(True, failed=True)
+    canary.record(True, failed=True)
+    assert canary.active is False
+
+    canary.reset()
+    assert canary.active is True
+    assert canary.status()["canary"] == {"samples": 0, "error_rate": None}
+
+
+def test_canary_rolls_back_when_its_feedback_scores_degrade() -> None:
+    canary = CanaryRollout(50, min_samples=50, min_feedback=3, max_score_drop=0.2)
+    for index in range(3):
+        canary.record(False, failed=False, analysis_id=f"stable-{index}")
+        canary.record(True, failed=False, analysis_id=f"canary-{index}")
+
+    assert canary.record_feedback("unknown", 1.0) is None
+    for index in range(3):
+        assert canary.record_feedback(f"stable-{index}", 0.9) == "stable"
+    assert canary.record_feedback("stable-0", 0.0) is None
+    canary.record_feedback("canary-0", 0.9)
+    canary.record_feedback("canary-1", 0.8)
+    assert canary.active is True
+
+    canary.record_feedback("canary-2", 0.1)
+
+    status = canary.status()
+    assert canary.active is False
+    assert "feedback score" in str(status["rollback_reason"])
+    assert status["feedback"] == {
+        "canary": {"samples": 3, "score": 0.6},
+        "stable": {"samples": 3, "score": 0.9},
+    }
@@ -0,0 +1,87 @@
+from __future__ import annotations
+
+import pytest
+
+import app.core.chaos as chaos_module
+from app.clients.prometheus import PrometheusClient
+from app.core.chaos import (
+    InjectedFault,
+    init_fault_injection,
+    maybe_inject_fault,
+    wrap_with_faults,
+)
+from app.core.config import load_settings
+
+
+class _FakeApi:
+    def __init__(self) -> None:
+        self.calls = 0
+        self.api_client = object()
+
+    def read_namespaced_pod(self, name: str, namespace: str) -> str:
+        self.calls += 1
+        return f"{namespace}/{name}"
+
+
+@pytest.fixture(autouse=True)
+def _reset_injector():
+    yield
+    init_fault_injection(False)
+
+
+def test_disabled_injector_is_noop() -> None:
+    init_fault_injection(False, error_rate=1.0)
+
+    maybe_inject_fault("llm")
+    api = _FakeApi()
+    assert wrap_with_faults(api, "k8s") is api
+
+
+def test_error_rate_one_always_raises_for_target() -> None:
+    init_fault_injection(True, ["prometheus"], error_rate=1.0)
+
+    with pytest.raises(InjectedFault):
+        maybe_inject_fault("prometheus")
+    maybe_inject_fault("loki")
+
+
+def test_injected_fault_is_treated_as_transient() -> None:
+    assert InjectedFault("boom").status_code == 503
+
+
+def test_latency_is_applied(monkeypatch: pytest.MonkeyPatch) -> None:
+    sleeps: list[float] = []
+    monkeypatch.setattr(chaos_module.time, "sleep", sleeps.append)
+    init_fault_injection(True, latency_ms=250)
+
+    maybe_inject_fault("tempo")
+
+    assert sleeps == [0.25]
+
+
+def test_wrapped_api_injects_before_call() -> None:
+    init_fault_injection(True, ["k8s"], error_rate=1.0)
+    api = _FakeApi()
+    proxy = wrap_with_faults(api, "k8s")
+
+    with pytest.raises(InjectedFault):
+        proxy.read_namespaced_pod("demo", "default")
+    assert api.calls == 0
+    assert proxy.api_client is api.api_client
+
+
+def test_unknown_target_is_rejected() -> None:
+    with pytest.raises(ValueError, match="CHAOS_TARGETS"):
+        init_fault_injection(True, ["callbacks"])
+
+
+def test_prometheus_client_reports_injected_fault_as_error(
+    monkeypatch: pytest.MonkeyPatch,
+) -> None:
+    monkeypatch.setenv("PROMETHEUS_URL", "http://prometheus:9090")
+    init_fault_injection(True, ["prometheus"], error_rate=1.0)
+
+    result = PrometheusClient(load_settings()).query("up")
+
+    assert result["error"] == "failed to query Prometheus"
+    assert "chaos" in str(result["detail"])
@@ -0,0 +1,73 @@
+from __future__ import annotations
+
+from datetime import datetime, timedelta, timezone
+
+from app.services.closure import IncidentClosureTracker, OpenAnalysis, incident_duration_seconds
+
+
+class FakeSink:
+    def __init__(self) -> None:
+        self.sent: list[tuple[str, dict[str, object]]] = []
+
+    def send(self, report_type: str, report: dict[str, object]) -> dict[str, object]:
+        self.sent.append((report_type, report))
+        return {"delivered": True, "status_code": 200}
+
+
+def _entry(session_key: str) -> OpenAnalysis:
+    return OpenAnalysis(
+        session_key=session_key,
+        analysis_id=f"{session_key}:run:1234abcd",
+        thread_ts="1234567890.123456",
+        incident_id=None,
+        alertname="KubePodCrashLooping",
+        namespace="default",
+        summary="OOMKilled",
+        opened_at=datetime(2026, 1, 1, tzinfo=timezone.utc),
+    )
+
+
+def test_tracker_closes_open_analysis_once() -> None:
+    tracker = IncidentClosureTracker()
+    tracker.open(_entry("alert:a"))
+
+    closed = tracker.close("alert:a")
+
+    assert closed is not None and closed.summary == "OOMKilled"
+    assert tracker.close("alert:a") is None
+
+
+def test_tracker_drops_oldest_entries_beyond_limit() -> None:
+    tracker = IncidentClosureTracker(max_open=2)
+    for key in ("alert:a", "alert:b", "alert:c"):
+        tracker.open(_entry(key))
+
+    assert tracker.open_count() == 2
+    assert tracker.close("alert:a") is None
+    assert tracker.close("alert:c") is not None
+
+
+def test_notify_sends_closure_to_report_sink() -> None:
+    sink = FakeSink()
+    tracker = IncidentClosureTracker(sink=sink)
+
+    delivery = tracker.notify({"thread_ts": "1.2", "duration_seconds": 60.0})
+
+    assert delivery == {"delivered": True, "status_code": 200}
+    assert sink.sent == [("incident_closure", {"thread_ts": "1.2", "duration_seconds": 60.0})]
+    assert IncidentClosureTracker().notify({"thread_ts": "1.2"}) is None
+
+
+def test_duration_prefers_alert_timestamps() -> None:
+    starts_at = datetime(2026, 1, 1, 10, 0, tzinfo=timezone.utc)
+    ends_at = starts_at + timedelta(minutes=12)
+
+    assert incident_duration_seconds(starts_at, ends_at, None) == (720.0, "alert")
+
+
+def test_duration_falls_back_to_tracked_open_time() -> None:
+    opened_at = datetime(2026, 1, 1, 10, 0)
+    now = datetime(2026, 1, 1, 10, 5, tzinfo=timezone.utc)
+
+    assert incident_duration_seconds(None, None, opened_at, now=now) == (300.0, "tracked")
+    assert incident_duration_seconds(None, None, None) == (None, None)
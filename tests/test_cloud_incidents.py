@@ -0,0 +1,61 @@
+from __future__ import annotations
+
+from datetime import datetime, timezone
+
+from app.services.cloud_incidents import match_cloud_incidents
+
+_ALERT_START = datetime(2026, 10, 14, 2, 0, tzinfo=timezone.utc)
+
+
+def _incident(**overrides: object) -> dict[str, object]:
+    incident: dict[str, object] = {
+        "provider": "gcp",
+        "id": "abc",
+        "title": "Elevated GKE node provisioning failures",
+        "summary": "",
+        "services": ["Google Kubernetes Engine"],
+        "regions": ["us-central1"],
+        "started_at": "2026-10-14T01:30:00+00:00",
+        "ended_at": None,
+        "url": "https://status.cloud.google.com/incidents/abc",
+    }
+    incident.update(overrides)
+    return incident
+
+
+def test_match_requires_region_service_and_timing() -> None:
+    incidents = [
+        _incident(),
+        _incident(id="other-region", regions=["europe-west1"]),
+        _incident(id="other-service", services=["BigQuery"], title="BigQuery slow"),
+        _incident(id="later", started_at="2026-10-14T04:00:00+00:00"),
+        _incident(id="global", regions=["global"], started_at="2026-10-14T02:20:00+00:00"),
+    ]
+
+    matched = match_cloud_incidents(
+        incidents,
+        region="us-central1",
+        services=("Google Kubernetes Engine", "Persistent Disk"),
+        started_at=_ALERT_START,
+    )
+
+    assert [item["id"] for item in matched] == ["abc", "global"]
+    assert matched[0]["matched_services"] == ["Google Kubernetes Engine"]
+    assert matched[0]["minutes_from_alert"] == -30.0
+    assert matched[1]["minutes_from_alert"] == 20.0
+
+
+def test_match_free_text_incidents_by_region_display_name() -> None:
+    incident = _incident(
+        provider="azure",
+        title="Virtual Machines - East US - Degraded connectivity",
+        services=[],
+        regions=[],
+    )
+
+    assert match_cloud_incidents(
+        [incident], region="eastus", services=("Virtual Machines",), started_at=_ALERT_START
+    )
+    assert not match_cloud_incidents(
+        [incident], region="westeurope", services=("Virtual Machines",), started_at=_ALERT_START
+    )
@@ -0,0 +1,120 @@
+from __future__ import annotations
+
+import json
+
+import pytest
+
+import app.clients.cloud_status as cloud_status_module
+from app.clients.cloud_status import CloudStatusClient
+from app.core.config import load_settings
+
+
+class _FakeHTTPResponse:
+    def __init__(self, body: str) -> None:
+        self._body = body.encode("utf-8")
+
+    def read(self) -> bytes:
+        return self._body
+
+    def __enter__(self) -> _FakeHTTPResponse:
+        return self
+
+    def __exit__(self, exc_type, exc, tb) -> None:  # type: ignore[no-untyped-def]
+        return None
+
+
+def _rss(*items: tuple[str, str]) -> str:
+    entries = "".join(
+        f"<item><title>{title}</title><pubDate>{published}</pubDate>"
+        f"<link>https://status.example.com/</link><guid>{title}</guid>"
+        "<description>details</description></item>"
+        for title, published in items
+    )
+    return f"<rss><channel>{entries}</channel></rss>"
+
+
+def _client(monkeypatch: pytest.MonkeyPatch, provider: str, region: str) -> CloudStatusClient:
+    monkeypatch.setenv("CLOUD_STATUS_ENABLED", "true")
+    monkeypatch.setenv("CLOUD_STATUS_PROVIDER", provider)
+    monkeypatch.setenv("CLOUD_STATUS_REGION", region)
+    return CloudStatusClient(load_settings())
+
+
+def test_aws_feeds_report_unresolved_incidents(monkeypatch: pytest.MonkeyPatch) -> None:
+    monkeypatch.setenv("CLOUD_STATUS_SERVICES_JSON", '["ec2", "eks"]')
+    client = _client(monkeypatch, "aws", "us-east-1")
+    feeds = {
+        "ec2-us-east-1": _rss(
+            ("Increased API Error Rates", "Wed, 14 Oct 2026 01:40:00 PDT"),
+            ("Informational message: Increased API Error Rates", "Wed, 14 Oct 2026 01:10:00 PDT"),
+            ("[RESOLVED] Instance launch delays", "Mon, 12 Oct 2026 09:00:00 PDT"),
+        ),
+        "eks-us-east-1": _rss(
+            ("[RESOLVED] Control plane latency", "Tue, 13 Oct 2026 11:00:00 GMT")
+        ),
+    }
+    urls: list[str] = []
+
+    def fake_urlopen(request, timeout=0):  # type: ignore[no-untyped-def]
+        urls.append(request.full_url)
+        name = request.full_url.rsplit("/", 1)[-1].removesuffix(".rss")
+        return _FakeHTTPResponse(feeds[name])
+
+    monkeypatch.setattr(cloud_status_module.urllib.request, "urlopen", fake_urlopen)
+
+    incidents, errors = client.active_incidents()
+    client.active_incidents()
+
+    assert errors == []
+    assert urls == [
+        "https://status.aws.amazon.com/rss/ec2-us-east-1.rss",
+        "https://status.aws.amazon.com/rss/eks-us-east-1.rss",
+    ]
+    assert len(incidents) == 1
+    assert incidents[0]["title"] == "Increased API Error Rates"
+    assert incidents[0]["services"] == ["ec2"]
+    assert incidents[0]["started_at"] == "2026-10-14T08:10:00+00:00"
+
+
+def test_gcp_incidents_skip_ended_ones(monkeypatch: pytest.MonkeyPatch) -> None:
+    client = _client(monkeypatch, "gcp", "us-central1")
+    payload = [
+        {
+            "id": "abc",
+            "begin": "2026-10-14T01:00:00+00:00",
+            "end": None,
+            "external_desc": "Elevated GKE node provisioning failures",
+            "affected_products": [{"title": "Google Kubernetes Engine", "id": "gke"}],
+            "currently_affected_locations": [{"title": "Iowa (us-central1)", "id": "us-central1"}],
+            "most_recent_update": {"text": "Mitigation in progress."},
+            "uri": "incidents/abc",
+        },
+        {"id": "old", "begin": "2026-10-01T01:00:00+00:00", "end": "2026-10-01T03:00:00+00:00"},
+    ]
+    monkeypatch.setattr(
+        cloud_status_module.urllib.request,
+        "urlopen",
+        lambda request, timeout=0: _FakeHTTPResponse(json.dumps(payload)),
+    )
+
+    incidents, _ = client.active_incidents()
+
+    assert [item["id"] for item in incidents] == ["abc"]
+    assert incidents[0]["regions"] == ["us-central1"]
+    assert incidents[0]["services"] == ["Google Kubernetes Engine"]
+    assert incidents[0]["url"] == "https://status.cloud.google.com/incidents/abc"
+
+
+def test_feed_errors_are_returned(monkeypatch: pytest.MonkeyPatch) -> None:
+    client = _client(monkeypatch, "azure", "eastus")
+
+    def fail(request, timeout=0):  # type: ignore[no-untyped-def]
+        raise OSError("connection reset")
+
+    monkeypatch.setattr(cloud_status_module.urllib.request, "urlopen", fail)
+
+    incidents, errors = client.active_incidents()
+
+    assert incidents == []
+    assert errors == ["https://azure.status.microsoft/en-us/status/feed: connection reset"]
+    assert _client(monkeypatch, "unknown", "eastus").enabled is False
@@ -0,0 +1,165 @@
+from __future__ import annotations
+
+import pytest
+
+from app.services.code_changes import CodeChangeCorrelator, classify_change
+
+_SOURCE = "org.opencontainers.image.source"
+_REVISION = "org.opencontainers.image.revision"
+
+
+class FakeRevisionSource:
+    def __init__(self, revisions: list[dict[str, object]] | None) -> None:
+        self._revisions = revisions
+
+    def get_deployment_revisions(
+        self, namespace: str, deployment: str, *, limit: int = 2
+    ) -> list[dict[str, object]] | None:
+        return self._revisions
+
+
+class FakeGitClient:
+    def __init__(
+        self, comparison: dict[str, object], pipeline: dict[str, object] | None = None
+    ) -> None:
+        self._comparison = comparison
+        self._pipeline = pipeline or {}
+        self.calls: list[tuple[str, str, str]] = []
+        self.pipeline_calls: list[tuple[str, str]] = []
+
+    def compare(self, source_url: str, base: str, head: str) -> dict[str, object]:
+        self.calls.append((source_url, base, head))
+        return self._comparison
+
+    def pipeline_status(self, source_url: str, sha: str) -> dict[str, object]:
+        self.pipeline_calls.append((source_url, sha))
+        return self._pipeline
+
+
+class FakeRegistry:
+    def __init__(self, builds: dict[str, dict[str, object]]) -> None:
+        self._builds = builds
+
+    def describe_image(self, image: str, image_id: str | None = None) -> dict[str, object]:
+        return {"image": image, "build": self._builds.get(image, {})}
+
+
+def _revision(revision: int, image: str, build: dict[str, str]) -> dict[str, object]:
+    return {
+        "revision": revision,
+        "replica_set": f"api-{revision}",
+        "created": "2026-10-14T01:00:00Z",
+        "containers": [{"name": "api", "image": image}, {"name": "proxy", "image": "envoy:1.31"}],
+        "build": build,
+    }
+
+
+@pytest.mark.parametrize(
+    "path,category",
+    [
+        ("go.mod", "dependency"),
+        ("services/api/requirements-prod.txt", "dependency"),
+        ("db/migrations/007_orders.sql", "migration"),
+        ("charts/api/values.yaml", "config"),
+        ("Dockerfile", "config"),
+        (".env.production", "config"),
+        ("internal/orders/handler.go", None),
+    ],
+)
+def test_classify_change(path: str, category: str | None) -> None:
+    assert classify_change(path) == category
+
+
+def test_correlate_compares_revisions_and_flags_suspicious_changes() -> None:
+    git = FakeGitClient(
+        {
+            "provider": "github",
+            "commit_count": 3,
+            "commits": [],
+            "files": [
+                {"path": "go.mod", "status": "modified"},
+                {"path": "config/app.yaml", "status": "modified"},
+                {"path": "internal/orders/handler.go", "status": "modified"},
+            ],
+        }
+    )
+    registry = FakeRegistry(
+        {"ghcr.io/acme/api:1.4.1": {"source": "https://github.com/acme/api", "revision": "aaa"}}
+    )
+    revisions = [
+        _revision(
+            8,
+            "ghcr.io/acme/api:1.4.2",
+            {_SOURCE: "https://github.com/acme/api", _REVISION: "bbb"},
+        ),
+        _revision(7, "ghcr.io/acme/api:1.4.1", {}),
+    ]
+    correlator = CodeChangeCorrelator(FakeRevisionSource(revisions), git, registry)
+
+    result = correlator.correlate("shop", "api")
+
+    assert git.calls == [("https://github.com/acme/api", "aaa", "bbb")]
+    api, proxy = result["containers"]
+    assert api["status"] == "compared"
+    assert api["suspicious_changes"] == [
+        {"path": "go.mod", "category": "dependency", "status": "modified"},
+        {"path": "config/app.yaml", "category": "config", "status": "modified"},
+    ]
+    assert proxy["status"] == "unchanged"
+    assert result["findings"] == [
+        "api: 3 commit(s) shipped in the latest rollout",
+        "api: dependency changes in go.mod",
+        "api: config changes in config/app.yaml",
+    ]
+
+
+def test_correlate_needs_a_previous_revision_and_labels() -> None:
+    git = FakeGitClient({})
+    single = CodeChangeCorrelator(FakeRevisionSource([_revision(1, "api:1", {})]), git)
+    assert single.correlate("shop", "api")["warning"] == "no previous revision to compare"
+
+    unlabeled = CodeChangeCorrelator(
+        FakeRevisionSource([_revision(2, "api:2", {}), _revision(1, "api:1", {})]), git
+    )
+    result = unlabeled.correlate("shop", "api")
+    assert result["containers"][0]["status"] == "no_revision_labels"
+    assert git.calls == []
+
+
+def test_correlate_flags_partially_failed_pipelines() -> None:
+    git = FakeGitClient(
+        {"commit_count": 1, "commits": [], "files": []},
+        {
+            "provider": "github",
+            "run_count": 1,
+            "runs": [
+                {
+                    "name": "deploy",
+                    "conclusion": "failure",
+                    "failed_jobs": ["integration-tests"],
+                    "skipped_jobs": ["db-migrate"],
+                }
+            ],
+            "partially_failed": True,
+            "skipped_migrations": ["db-migrate"],
+        },
+    )
+    revisions = [
+        _revision(2, "api:2", {_SOURCE: "https://github.com/acme/api", _REVISION: "bbb"}),
+        _revision(1, "api:1", {_SOURCE: "https://github.com/acme/api", _REVISION: "aaa"}),
+    ]
+    correlator = CodeChangeCorrelator(FakeRevisionSource(revisions), git, pipeline_checks=True)
+
+    result = correlator.correlate("shop", "api")
+
+    assert git.pipeline_calls == [("https://github.com/acme/api", "bbb")]
+    assert result["containers"][0]["pipeline"]["partially_failed"] is True
+    assert (
+        "api: deployed from a partially failed pipeline "
+        "(failed: integration-tests; skipped: db-migrate); "
+        "database migrations may not have run"
+    ) in result["findings"]
+
+    git.pipeline_calls.clear()
+    CodeChangeCorrelator(FakeRevisionSource(revisions), git).correlate("shop", "api")
+    assert git.pipeline_calls == []
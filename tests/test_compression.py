@@ -0,0 +1,90 @@
+from __future__ import annotations
+
+import asyncio
+import gzip
+import json
+
+import pytest
+
+from app.core.compression import GzipRequestMiddleware, RequestBodyTooLarge, decompress_gzip
+
+
+class _RecordingApp:
+    def __init__(self) -> None:
+        self.body = b""
+        self.headers: dict[bytes, bytes] = {}
+
+    async def __call__(self, scope, receive, send) -> None:  # type: ignore[no-untyped-def]
+        self.headers = dict(scope["headers"])
+        message = await receive()
+        self.body = message["body"]
+        await send({"type": "http.response.start", "status": 200, "headers": []})
+        await send({"type": "http.response.body", "body": b"ok"})
+
+
+def _run(
+    middleware: GzipRequestMiddleware, body: bytes, headers: list[tuple[bytes, bytes]]
+) -> list[dict[str, object]]:
+    sent: list[dict[str, object]] = []
+    messages = [{"type": "http.request", "body": body, "more_body": False}]
+
+    async def receive() -> dict[str, object]:
+        return messages.pop(0) if messages else {"type": "http.disconnect"}
+
+    async def send(message: dict[str, object]) -> None:
+        sent.append(message)
+
+    scope = {"type": "http", "method": "POST", "path": "/analyze", "headers": headers}
+    asyncio.run(middleware(scope, receive, send))
+    return sent
+
+
+def test_gzip_request_body_is_decoded() -> None:
+    app = _RecordingApp()
+    middleware = GzipRequestMiddleware(app, max_body_bytes=1024)
+    payload = json.dumps({"alert": {"status": "firing"}}).encode("utf-8")
+
+    sent = _run(
+        middleware,
+        gzip.compress(payload),
+        [(b"content-type", b"application/json"), (b"content-encoding", b"gzip")],
+    )
+
+    assert sent[0]["status"] == 200
+    assert app.body == payload
+    assert b"content-encoding" not in app.headers
+    assert app.headers[b"content-length"] == str(len(payload)).encode("latin-1")
+
+
+def test_plain_request_body_passes_through() -> None:
+    app = _RecordingApp()
+    middleware = GzipRequestMiddleware(app, max_body_bytes=1024)
+
+    _run(middleware, b'{"a":1}', [(b"content-type", b"application/json")])
+
+    assert app.body == b'{"a":1}'
+
+
+def test_invalid_gzip_body_returns_400() -> None:
+    app = _RecordingApp()
+    middleware = GzipRequestMiddleware(app, max_body_bytes=1024)
+
+    sent = _run(middleware, b"not-gzip", [(b"content-encoding", b"gzip")])
+
+    assert sent[0]["status"] == 400
+    assert app.body == b""
+
+
+def test_oversized_decompressed_body_returns_413() -> None:
+    app = _RecordingApp()
+    middleware = GzipRequestMiddleware(app, max_body_bytes=64)
+
+    sent = _run(middleware, gzip.compress(b"x" * 4096), [(b"content-encoding", b"gzip")])
+
+    assert sent[0]["status"] == 413
+    assert app.body == b""
+
+
+def test_decompress_gzip_rejects_bomb() -> None:
+    with pytest.raises(RequestBodyTooLarge):
+        decompress_gzip(gzip.compress(b"0" * 100_000), max_bytes=1000)
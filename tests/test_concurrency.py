@@ -61,3 +61,71 @@ def test_run_without_init_still_works():
     concurrency._semaphore = None
     result = asyncio.run(run_in_thread_limited(lambda: "ok"))
     assert result == "ok"
+
+
+class _FixedScaleMonitor:
+    def __init__(self, scale: float) -> None:
+        self._scale = scale
+
+    @property
+    def enabled(self) -> bool:
+        return True
+
+    def scale(self) -> float:
+        return self._scale
+
+
+def test_memory_pressure_reduces_effective_limit():
+    """메모리 압박 시 동시 분석 수가 축소되는지 확인."""
+    from app.core import concurrency
+
+    init_concurrency(max_concurrent=4, memory_monitor=_FixedScaleMonitor(0.25))
+    assert concurrency.effective_concurrency_limit() == 1
+
+    init_concurrency(max_concurrent=4, memory_monitor=_FixedScaleMonitor(0.5))
+    assert concurrency.effective_concurrency_limit() == 2
+
+    init_concurrency(max_concurrent=4)
+    assert concurrency.effective_concurrency_limit() == 4
+
+
+def test_memory_pressure_serializes_analyses():
+    init_concurrency(max_concurrent=2, memory_monitor=_FixedScaleMonitor(0.25))
+    running: list[int] = []
+    peak: list[int] = []
+
+    def task(idx: int) -> int:
+        running.append(idx)
+        peak.append(len(running))
+        time.sleep(0.05)
+        running.remove(idx)
+        return idx
+
+    async def _run() -> list[int]:
+        return list(
+            await asyncio.gather(
+                run_in_thread_limited(task, 0),
+                run_in_thread_limited(task, 1),
+            )
+        )
+
+    results = asyncio.run(_run())
+    assert set(results) == {0, 1}
+    assert max(peak) == 1
+
+
+def test_wait_refuses_when_no_slot_frees_up_and_keeps_the_slot_count():
+    from app.core import concurrency
+
+    init_concurrency(max_concurrent=1)
+
+    async def _run() -> None:
+        busy = asyncio.ensure_future(run_in_thread_limited(time.sleep, 0.3))
+        await asyncio.sleep(0.05)
+        with pytest.raises(concurrency.ConcurrencyLimitExceeded):
+            await run_in_thread_limited(lambda: "late", wait=0.05)
+        await busy
+        assert await run_in_thread_limited(lambda: "next", wait=0.05) == "next"
+
+    asyncio.run(_run())
+    assert concurrency._active == 0
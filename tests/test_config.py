@@ -74,3 +74,80 @@ def test_load_settings_falls_back_for_invalid_anthropic_max_tokens(
     settings = load_settings()
 
     assert settings.anthropic_max_tokens == DEFAULT_ANTHROPIC_MAX_TOKENS
+
+
+def test_load_settings_parses_analysis_slo_targets(monkeypatch: pytest.MonkeyPatch) -> None:
+    monkeypatch.setenv(
+        "ANALYSIS_SLO_TARGETS_JSON", '{"KubePodCrashLooping": 60, "severity:critical": 45.5}'
+    )
+
+    settings = load_settings()
+
+    assert settings.analysis_slo_targets == (
+        ("KubePodCrashLooping", 60.0),
+        ("severity:critical", 45.5),
+    )
+
+
+def test_load_settings_rejects_non_positive_slo_target(monkeypatch: pytest.MonkeyPatch) -> None:
+    monkeypatch.setenv("ANALYSIS_SLO_TARGETS_JSON", '{"default": 0}')
+
+    with pytest.raises(ValueError, match="ANALYSIS_SLO_TARGETS_JSON.default"):
+        load_settings()
+
+
+def test_load_settings_parses_registry_credentials(monkeypatch: pytest.MonkeyPatch) -> None:
+    monkeypatch.setenv("REGISTRY_CREDENTIALS_JSON", '{"ghcr.io": "bot:ghp_token"}')
+
+    assert load_settings().registry_credentials == (("ghcr.io", "bot:ghp_token"),)
+
+    monkeypatch.setenv("REGISTRY_CREDENTIALS_JSON", '{"ghcr.io": "ghp_token"}')
+    with pytest.raises(ValueError, match="REGISTRY_CREDENTIALS_JSON.ghcr.io"):
+        load_settings()
+
+
+def test_load_settings_parses_k8s_clusters(monkeypatch: pytest.MonkeyPatch) -> None:
+    monkeypatch.setenv(
+        "K8S_CLUSTERS_JSON",
+        '{"prod-eu": {"kubeconfig": "/etc/kube-rca/prod-eu", "context": "eu-admin"}, '
+        '"staging": {"kubeconfig": "/etc/kube-rca/staging"}}',
+    )
+
+    assert load_settings().k8s_clusters == (
+        ("prod-eu", "/etc/kube-rca/prod-eu", "eu-admin"),
+        ("staging", "/etc/kube-rca/staging", ""),
+    )
+
+    monkeypatch.setenv("K8S_CLUSTERS_JSON", '{"staging": {"context": "staging"}}')
+    with pytest.raises(ValueError, match="K8S_CLUSTERS_JSON.staging.kubeconfig"):
+        load_settings()
+
+
+def test_load_settings_parses_datastore_targets(monkeypatch: pytest.MonkeyPatch) -> None:
+    monkeypatch.setenv(
+        "DATASTORE_TARGETS_JSON",
+        '{"orders": ["postgresql://app:pw@orders-db:5432/orders", " "], "cart": "redis://cache"}',
+    )
+
+    assert load_settings().datastore_targets == (
+        ("orders", ("postgresql://app:pw@orders-db:5432/orders",)),
+        ("cart", ("redis://cache",)),
+    )
+
+    monkeypatch.setenv("DATASTORE_TARGETS_JSON", '{"orders": {"url": "mysql://db"}}')
+    with pytest.raises(ValueError, match="DATASTORE_TARGETS_JSON.orders"):
+        load_settings()
+
+
+def test_load_settings_parses_analysis_pipelines(monkeypatch: pytest.MonkeyPatch) -> None:
+    monkeypatch.setenv(
+        "ANALYSIS_PIPELINES_JSON", '{"severity:info": ["collect", "Rules", "deliver"]}'
+    )
+
+    assert load_settings().analysis_pipelines == (
+        ("severity:info", ("collect", "rules", "deliver")),
+    )
+
+    monkeypatch.setenv("ANALYSIS_PIPELINES_JSON", '{"default": ["collect", "deliver", "llm"]}')
+    with pytest.raises(ValueError, match="ANALYSIS_PIPELINES_JSON.default"):
+        load_settings()
@@ -0,0 +1,63 @@
+from __future__ import annotations
+
+import logging
+import urllib.request
+
+import pytest
+
+from app.clients import report_sink
+from app.clients.report_sink import WebhookReportSink
+from app.core.correlation import (
+    CorrelationIdFilter,
+    current_correlation_id,
+    resolve_correlation_id,
+    use_correlation_id,
+)
+
+
+def test_resolve_correlation_id_keeps_valid_ids_and_generates_the_rest() -> None:
+    assert resolve_correlation_id(" 1234567890.123456 ") == "1234567890.123456"
+    generated = resolve_correlation_id("bad id\nwith newline")
+    assert len(generated) == 32 and generated != resolve_correlation_id(None)
+    assert len(resolve_correlation_id("x" * 129)) == 32
+
+
+def test_filter_adds_the_current_correlation_id_to_log_records() -> None:
+    record = logging.LogRecord("app", logging.INFO, __file__, 1, "msg", None, None)
+    log_filter = CorrelationIdFilter()
+
+    with use_correlation_id("abc"):
+        assert current_correlation_id() == "abc"
+        assert log_filter.filter(record) and record.correlation_id == "abc"
+    assert current_correlation_id() is None
+    log_filter.filter(record)
+    assert record.correlation_id == "-"
+
+
+def test_callbacks_sent_during_an_analysis_carry_the_correlation_header(
+    monkeypatch: pytest.MonkeyPatch,
+) -> None:
+    sent: list[urllib.request.Request] = []
+
+    class _Response:
+        status = 204
+
+        def __enter__(self) -> _Response:
+            return self
+
+        def __exit__(self, *exc: object) -> None:
+            return None
+
+    def _open(request: urllib.request.Request, timeout: int) -> _Response:
+        sent.append(request)
+        return _Response()
+
+    monkeypatch.setattr(report_sink, "open_url", _open)
+    webhook = WebhookReportSink("https://hooks.example.com/services/T000/B000/s3cr3t")
+
+    with use_correlation_id("1234567890.123456"):
+        webhook.send("closure", {"status": "resolved"})
+    webhook.send("digest", {"summary": "3 incidents"})
+
+    assert sent[0].get_header("X-correlation-id") == "1234567890.123456"
+    assert sent[1].get_header("X-correlation-id") is None
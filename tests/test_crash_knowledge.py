@@ -0,0 +1,52 @@
+from __future__ import annotations
+
+from app.services.crash_knowledge import classify_crash, describe_exit_code, exit_code_hint
+
+
+def test_runtime_signatures_win_over_generic_ones_and_the_exit_code() -> None:
+    lines = [
+        "WARN dial tcp 10.0.0.7:5432: connect: connection refused, retrying",
+        'Exception in thread "main" java.lang.OutOfMemoryError: Java heap space',
+        "\tat com.example.Cache.load(Cache.java:42)",
+    ]
+
+    assert classify_crash(lines, exit_code=1, reason="Error") == {
+        "source": "log_signature",
+        "signature": "jvm_heap_exhausted",
+        "runtime": "jvm",
+        "category": "out_of_memory",
+        "cause": "the JVM heap is exhausted",
+        "recommendation": (
+            "Raise -Xmx (or -XX:MaxRAMPercentage) within the container memory limit, or find "
+            "the heap growth with -XX:+HeapDumpOnOutOfMemoryError."
+        ),
+        "matched_line": lines[1],
+        "exit_code": 1,
+        "signal": None,
+    }
+    go = classify_crash(
+        ["panic: runtime error: invalid memory address or nil pointer dereference"],
+        exit_code=2,
+    )
+    assert go is not None and (go["signature"], go["category"]) == ("go_nil_pointer", "code_bug")
+    node = classify_crash(["FATAL ERROR: Reached heap limit Allocation failed"])
+    assert node is not None and node["signature"] == "node_heap_exhausted"
+    python = classify_crash(["Traceback (most recent call last):", "KeyError: 'DATABASE_URL'"])
+    assert python is not None and python["signature"] == "python_missing_env"
+
+
+def test_exit_codes_are_classified_when_no_signature_matches() -> None:
+    killed = classify_crash(["starting worker"], exit_code=137, reason="Error")
+
+    assert killed is not None
+    assert (killed["source"], killed["category"], killed["signal"]) == (
+        "exit_code",
+        "killed",
+        "SIGKILL",
+    )
+    oom = describe_exit_code(137, "OOMKilled")
+    assert oom is not None and oom["category"] == "oom_killed"
+    assert exit_code_hint(137) == "SIGKILL: OOM kill or failed liveness probe"
+    assert exit_code_hint(127) == "command not found"
+    assert exit_code_hint(42) is None
+    assert classify_crash([], exit_code=0) is None
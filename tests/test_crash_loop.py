@@ -0,0 +1,216 @@
+from __future__ import annotations
+
+from app.models.k8s import K8sContext, PodEventSummary, PodLogSnippet, PodStatusSnapshot
+from app.schemas.analysis import CrashLoopAnalysis
+from app.services.crash_loop import build_crash_loop_analysis, extract_crash_cause
+
+
+def _context(
+    container_statuses: list[dict[str, object]],
+    *,
+    previous_logs: list[PodLogSnippet] | None = None,
+    events: list[PodEventSummary] | None = None,
+) -> K8sContext:
+    return K8sContext(
+        namespace="shop",
+        pod_name="api-0",
+        workload="api",
+        pod_status=PodStatusSnapshot(
+            phase="Running",
+            node_name="node-1",
+            start_time=None,
+            reason=None,
+            message=None,
+            conditions=[],
+            container_statuses=container_statuses,  # type: ignore[arg-type]
+        ),
+        events=events or [],
+        previous_logs=previous_logs or [],
+        warnings=[],
+    )
+
+
+def _crash_looping(
+    name: str, *, restarts: int = 4, message: str | None = None
+) -> dict[str, object]:
+    return {
+        "name": name,
+        "restart_count": restarts,
+        "state": {"type": "waiting", "reason": "CrashLoopBackOff", "message": message},
+        "last_state": {
+            "type": "terminated",
+            "reason": "Error",
+            "exit_code": 1,
+            "finished_at": "2026-10-14T02:00:00+00:00",
+        },
+    }
+
+
+def test_crash_loop_analysis_extracts_python_traceback_from_previous_logs() -> None:
+    logs = [
+        "2026-10-14T01:59:58.100000000Z starting api",
+        "2026-10-14T01:59:59.200000000Z Traceback (most recent call last):",
+        '2026-10-14T01:59:59.200000000Z   File "/app/main.py", line 12, in <module>',
+        "2026-10-14T01:59:59.200000000Z     connect(os.environ['DATABASE_URL'])",
+        "2026-10-14T01:59:59.200000000Z KeyError: 'DATABASE_URL'",
+    ]
+    context = _context(
+        [
+            _crash_looping(
+                "api",
+                message="back-off 2m40s restarting failed container=api pod=api-0_shop(uid)",
+            ),
+            {"name": "sidecar", "restart_count": 0, "state": {"type": "running"}},
+        ],
+        previous_logs=[
+            PodLogSnippet(container="api", previous=True, logs=logs),
+            PodLogSnippet(container="sidecar", previous=True, logs=[]),
+        ],
+    )
+
+    analysis = build_crash_loop_analysis(context)
+
+    assert analysis is not None
+    [container] = analysis["containers"]
+    assert container["name"] == "api"
+    assert container["backoff_seconds"] == 160
+    assert container["backoff_source"] == "kubelet"
+    assert container["next_restart_at"] == "2026-10-14T02:02:40+00:00"
+    assert container["crash_cause"] == {
+        "kind": "python",
+        "fatal_line": "KeyError: 'DATABASE_URL'",
+        "stack_trace": [
+            "Traceback (most recent call last):",
+            '  File "/app/main.py", line 12, in <module>',
+            "    connect(os.environ['DATABASE_URL'])",
+            "KeyError: 'DATABASE_URL'",
+        ],
+    }
+    assert analysis["findings"] == [
+        "container api is crash looping (4 restart(s), back-off 160s, next restart at "
+        "2026-10-14T02:02:40+00:00); probable cause: KeyError: 'DATABASE_URL' (exit code 1)"
+    ]
+    assert CrashLoopAnalysis.model_validate(analysis).containers[0].crash_cause is not None
+
+
+def test_crash_loop_analysis_notes_cause_from_the_current_stream() -> None:
+    context = _context(
+        [_crash_looping("api")],
+        previous_logs=[
+            PodLogSnippet(
+                container="api",
+                previous=False,
+                logs=["2026-10-14T02:00:01Z panic: runtime error: invalid memory address"],
+                note="previous container logs empty or rotated by the kubelet",
+            )
+        ],
+    )
+
+    analysis = build_crash_loop_analysis(context)
+
+    assert analysis is not None
+    [container] = analysis["containers"]
+    assert container["log_stream"] == "current"
+    assert container["previous_logs_available"] is False
+    assert analysis["findings"][0].endswith(
+        "probable cause: panic: runtime error: invalid memory address "
+        "(from the current container logs) (exit code 1)"
+    )
+
+
+def test_crash_loop_analysis_from_backoff_event_estimates_backoff() -> None:
+    running = {
+        "name": "worker",
+        "restart_count": 3,
+        "state": {"type": "running"},
+        "last_state": {"type": "terminated", "reason": "Error", "exit_code": 2},
+    }
+    event = PodEventSummary(
+        type="Warning",
+        reason="BackOff",
+        message="Back-off restarting failed container worker in pod api-0_shop(uid)",
+        count=5,
+        first_timestamp=None,
+        last_timestamp=None,
+        involved_object=None,
+    )
+
+    analysis = build_crash_loop_analysis(_context([running], events=[event]))
+
+    assert analysis is not None
+    container = analysis["containers"][0]
+    assert container["backoff_seconds"] == 40
+    assert container["backoff_source"] == "estimated"
+    assert container["next_restart_at"] is None
+    assert analysis["findings"][0].endswith(
+        "previous container logs are empty or unavailable (exit code 2)"
+    )
+
+
+def test_crash_loop_analysis_ignores_healthy_pods() -> None:
+    context = _context([{"name": "api", "restart_count": 0, "state": {"type": "running"}}])
+
+    assert build_crash_loop_analysis(context) is None
+
+
+def test_extract_crash_cause_go_panic() -> None:
+    cause = extract_crash_cause(
+        [
+            "level=info msg=starting",
+            "panic: runtime error: invalid memory address or nil pointer dereference",
+            "[signal SIGSEGV: segmentation violation code=0x1 addr=0x0 pc=0x4a2b3c]",
+            "",
+            "goroutine 1 [running]:",
+            "main.main()",
+            "\t/src/main.go:42 +0x1d",
+        ]
+    )
+
+    assert cause is not None
+    assert cause["kind"] == "go"
+    assert cause["fatal_line"].startswith("panic: runtime error: invalid memory address")
+    assert cause["stack_trace"][-1] == "\t/src/main.go:42 +0x1d"
+
+
+def test_extract_crash_cause_java_uses_innermost_cause() -> None:
+    cause = extract_crash_cause(
+        [
+            'Exception in thread "main" java.lang.IllegalStateException: context failed',
+            "\tat com.example.App.start(App.java:31)",
+            "Caused by: java.net.ConnectException: Connection refused",
+            "\tat java.base/sun.nio.ch.Net.connect0(Native Method)",
+            "\t... 4 more",
+            "shutting down",
+        ]
+    )
+
+    assert cause is not None
+    assert cause["kind"] == "java"
+    assert cause["fatal_line"] == "Caused by: java.net.ConnectException: Connection refused"
+    assert len(cause["stack_trace"]) == 5
+
+
+def test_extract_crash_cause_node_error_and_fatal_line() -> None:
+    node = extract_crash_cause(
+        [
+            "Error: Cannot find module '/app/server.js'",
+            "    at Module._resolveFilename (node:internal/modules/cjs/loader:1039:15)",
+        ]
+    )
+    type_error = extract_crash_cause(
+        [
+            "TypeError: Cannot read properties of undefined (reading 'port')",
+            "    at Object.<anonymous> (/app/server.js:4:22)",
+        ]
+    )
+    fatal = extract_crash_cause(["listening on :8080", "FATAL: password authentication failed"])
+
+    assert node is not None and node["kind"] == "node"
+    assert node["fatal_line"] == "Error: Cannot find module '/app/server.js'"
+    assert type_error is not None and type_error["kind"] == "node"
+    assert fatal == {
+        "kind": "log_line",
+        "fatal_line": "FATAL: password authentication failed",
+        "stack_trace": [],
+    }
+    assert extract_crash_cause(["listening on :8080", "shutting down"]) is None
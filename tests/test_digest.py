@@ -0,0 +1,221 @@
+from __future__ import annotations
+
+from datetime import datetime, timedelta, timezone
+
+import pytest
+
+from app.core.config import load_settings
+from app.models.k8s import K8sContext
+from app.schemas.alert import Alert
+from app.schemas.analysis import AlertAnalysisRequest
+from app.services.analysis import AnalysisService
+from app.services.digest import (
+    AnalysisLedger,
+    AnalysisRecord,
+    DigestService,
+    score_alert_noise,
+)
+
+
+def _record(
+    alertname: str,
+    *,
+    status: str = "firing",
+    incident_id: str | None = None,
+    root_causes: tuple[str, ...] = (),
+    age_hours: float = 1,
+    degraded: bool = False,
+) -> AnalysisRecord:
+    return AnalysisRecord(
+        recorded_at=datetime.now(timezone.utc) - timedelta(hours=age_hours),
+        alertname=alertname,
+        namespace="payments",
+        severity="critical",
+        status=status,
+        incident_id=incident_id,
+        fingerprint=None,
+        root_causes=root_causes,
+        degraded=degraded,
+    )
+
+
+class _FakeSink:
+    def __init__(self) -> None:
+        self.reports: list[tuple[str, dict[str, object]]] = []
+
+    def send(self, report_type: str, report: dict[str, object]) -> dict[str, object]:
+        self.reports.append((report_type, report))
+        return {"delivered": True, "status_code": 200}
+
+
+def test_digest_summarizes_period() -> None:
+    ledger = AnalysisLedger()
+    ledger.record(_record("PodCrashLooping", incident_id="inc-1", root_causes=("oom_killed",)))
+    ledger.record(
+        _record(
+            "PodCrashLooping",
+            status="resolved",
+            incident_id="inc-1",
+            root_causes=("oom_killed",),
+        )
+    )
+    ledger.record(_record("HighLatency", incident_id="inc-2", degraded=True))
+    ledger.record(_record("PodCrashLooping", incident_id="inc-3", root_causes=("oom_killed",)))
+    ledger.record(_record("OldAlert", incident_id="inc-0", age_hours=48))
+
+    report = DigestService(ledger, period_hours=24).build()
+
+    assert report["analysis_count"] == 4
+    assert report["degraded_count"] == 1
+    assert report["top_root_causes"] == [{"root_cause": "oom_killed", "count": 3}]
+    assert report["noisiest_alerts"][0] == {  # type: ignore[index]
+        "alertname": "PodCrashLooping",
+        "namespace": "payments",
+        "count": 3,
+    }
+    unresolved = report["unresolved_incidents"]
+    assert isinstance(unresolved, list)
+    assert [item["incident_id"] for item in unresolved] == ["inc-2", "inc-3"]
+
+
+def test_digest_send_delivers_to_sink() -> None:
+    sink = _FakeSink()
+    ledger = AnalysisLedger()
+    ledger.record(_record("HighLatency"))
+
+    report = DigestService(ledger, sink=sink, period_hours=168).send()
+
+    assert report["period_hours"] == 168
+    assert report["delivery"] == {"delivered": True, "status_code": 200}
+    assert sink.reports[0][0] == "digest"
+
+
+def test_ledger_drops_oldest_records() -> None:
+    ledger = AnalysisLedger(max_records=2)
+    for alertname in ("first", "second", "third"):
+        ledger.record(_record(alertname))
+
+    records = ledger.since(datetime.now(timezone.utc) - timedelta(days=1))
+
+    assert [record.alertname for record in records] == ["second", "third"]
+
+
+class _StaticKubernetesClient:
+    def collect_context(
+        self,
+        namespace: str | None,
+        pod_name: str | None,
+        workload: str | None = None,
+        service_name: str | None = None,
+    ) -> K8sContext:
+        return K8sContext(
+            namespace=namespace,
+            pod_name=pod_name,
+            workload=None,
+            pod_status=None,
+            events=[],
+            previous_logs=[],
+            warnings=[],
+        )
+
+    def get_hpa_status(
+        self,
+        namespace: str,
+        *,
+        workload: str | None = None,
+        pod_name: str | None = None,
+    ) -> dict[str, object] | None:
+        return None
+
+    def get_pod_metrics(self, namespace: str, pod_name: str) -> dict[str, object] | None:
+        return None
+
+    def get_node_resource_usage(self, node_name: str) -> dict[str, object] | None:
+        return None
+
+
+def test_analysis_service_records_analyses_in_ledger() -> None:
+    ledger = AnalysisLedger()
+    service = AnalysisService(_StaticKubernetesClient(), analysis_engine=None, ledger=ledger)
+    request = AlertAnalysisRequest(
+        alert=Alert(
+            status="firing",
+            labels={"alertname": "PodCrashLooping", "namespace": "payments", "pod": "api-0"},
+            fingerprint="fp-1",
+        ),
+        thread_ts="1234567890.123456",
+        incident_id="inc-9",
+    )
+
+    service.analyze(request)
+
+    [record] = ledger.since(datetime.now(timezone.utc) - timedelta(minutes=1))
+    assert record.alertname == "PodCrashLooping"
+    assert record.namespace == "payments"
+    assert record.incident_id == "inc-9"
+    assert record.degraded is True
+
+
+def test_digest_settings_from_env(monkeypatch: pytest.MonkeyPatch) -> None:
+    monkeypatch.setenv("DIGEST_PERIOD_HOURS", "168")
+    monkeypatch.setenv("DIGEST_MAX_RECORDS", "100")
+
+    settings = load_settings()
+
+    assert settings.digest_period_hours == 168
+    assert settings.digest_max_records == 100
+
+
+def test_noise_scoring_suggests_longer_for_duration_for_transient_alerts() -> None:
+    records = [_record("CPUThrottlingHigh") for _ in range(4)]
+    records += [
+        AnalysisRecord(
+            recorded_at=datetime.now(timezone.utc),
+            alertname="CPUThrottlingHigh",
+            namespace="payments",
+            severity="warning",
+            status="resolved",
+            incident_id=None,
+            fingerprint=None,
+            root_causes=(),
+            degraded=False,
+            duration_seconds=seconds,
+        )
+        for seconds in (90, 120, 150)
+    ]
+    records += [_record("PodOOMKilled", root_causes=("oom_killed",)) for _ in range(3)]
+    records.append(_record("RareAlert"))
+
+    scores = score_alert_noise(records)
+
+    assert [item["alertname"] for item in scores] == ["CPUThrottlingHigh", "PodOOMKilled"]
+    noisy, actionable = scores
+    assert noisy["noise_score"] == 1.0
+    assert noisy["transient_ratio"] == 1.0
+    suggestions = noisy["suggestions"]
+    assert isinstance(suggestions, list)
+    assert "`for:` duration to at least 5m" in suggestions[0]
+    assert "lower its severity from critical" in suggestions[1]
+    assert actionable["actionability"] == 1.0
+    assert actionable["suggestions"] == []
+
+
+def test_analysis_service_records_resolved_alert_duration() -> None:
+    ledger = AnalysisLedger()
+    service = AnalysisService(_StaticKubernetesClient(), analysis_engine=None, ledger=ledger)
+    starts_at = datetime(2026, 2, 6, 18, 0, tzinfo=timezone.utc)
+    request = AlertAnalysisRequest(
+        alert=Alert(
+            status="resolved",
+            labels={"alertname": "CPUThrottlingHigh", "namespace": "payments"},
+            startsAt=starts_at,
+            endsAt=starts_at + timedelta(minutes=2),
+        ),
+        thread_ts="1234567890.123456",
+    )
+
+    service.analyze(request)
+
+    [record] = ledger.since(datetime.now(timezone.utc) - timedelta(minutes=1))
+    assert record.status == "resolved"
+    assert record.duration_seconds == 120.0
@@ -0,0 +1,141 @@
+from __future__ import annotations
+
+from app.models.k8s import K8sContext, PodLogSnippet
+from app.schemas.analysis import DnsAnalysis
+from app.services.dns_analysis import build_dns_analysis, dns_alert
+
+
+def _pod(name: str, *, ready: bool, restarts: int = 0) -> dict[str, object]:
+    return {
+        "name": name,
+        "phase": "Running",
+        "ready": ready,
+        "containers": [{"name": "coredns", "ready": ready, "restart_count": restarts}],
+    }
+
+
+def _endpoint(pod: str, *, ready: bool) -> dict[str, object]:
+    return {"addresses": ["10.0.0.10"], "ready": ready, "terminating": False, "pod": pod}
+
+
+def _log(pod: str, **counts: object) -> dict[str, object]:
+    return {
+        "pod": pod,
+        "container": "coredns",
+        "lines": 100,
+        "servfail": 0,
+        "errors": 0,
+        "upstream_timeouts": 0,
+        "samples": [],
+        **counts,
+    }
+
+
+def _context(pod_logs: list[str] | None = None, **cluster_dns: object) -> K8sContext:
+    return K8sContext(
+        namespace="shop",
+        pod_name="checkout-7d9f8c-abcde",
+        workload="checkout",
+        pod_status=None,
+        events=[],
+        previous_logs=[],
+        current_logs=[PodLogSnippet(container="app", previous=False, logs=pod_logs or [])],
+        warnings=[],
+        cluster_dns={
+            "namespace": "kube-system",
+            "service": "kube-dns",
+            "found": True,
+            "selector": {"k8s-app": "kube-dns"},
+            "endpoints": [_endpoint("coredns-a", ready=True), _endpoint("coredns-b", ready=True)],
+            "pods": [_pod("coredns-a", ready=True), _pod("coredns-b", ready=True)],
+            "logs": [_log("coredns-a"), _log("coredns-b")],
+            "trigger": "alert",
+            **cluster_dns,
+        },
+    )
+
+
+def test_dns_alert_matches_dns_alerts_and_failed_lookups_in_pod_logs() -> None:
+    empty = _context()
+    lookup_failure = _context(
+        pod_logs=["dial tcp: lookup payments.shop.svc.cluster.local on 10.96.0.10:53: i/o timeout"]
+    )
+
+    assert dns_alert({"alertname": "CoreDNSLatencyHigh"}, {}, empty) == "alert"
+    assert (
+        dns_alert(
+            {"alertname": "UpstreamErrors"},
+            {"description": "getaddrinfo: Temporary failure in name resolution"},
+            empty,
+        )
+        == "alert"
+    )
+    assert dns_alert({"alertname": "HighErrorRate"}, {}, lookup_failure) == "logs"
+    assert dns_alert({"alertname": "HighErrorRate"}, {}, empty) is None
+    assert (
+        dns_alert({"alertname": "HighErrorRate"}, {}, _context(pod_logs=["upstream i/o timeout"]))
+        is None
+    )
+
+
+def test_dns_analysis_reports_missing_service_and_no_ready_endpoints() -> None:
+    missing = build_dns_analysis(_context(found=False))
+    down = build_dns_analysis(
+        _context(
+            endpoints=[_endpoint("coredns-a", ready=False)],
+            pods=[_pod("coredns-a", ready=False, restarts=7)],
+            logs=[_log("coredns-a")],
+        )
+    )
+
+    assert missing is not None
+    assert (missing["verdict"], missing["cause"]) == ("unavailable", "dns_service_not_found")
+    assert down is not None
+    assert (down["verdict"], down["cause"]) == ("unavailable", "no_ready_endpoints")
+    assert down["findings"] == [
+        "cluster DNS service kube-system/kube-dns has no ready endpoints (1 DNS pods selected); "
+        "every in-cluster name lookup fails",
+        "DNS pods restarted 7 times",
+    ]
+
+
+def test_dns_analysis_reports_servfail_spikes_and_upstream_timeouts() -> None:
+    servfail = build_dns_analysis(_context(logs=[_log("coredns-a", servfail=25)]))
+    timeouts = build_dns_analysis(
+        _context(
+            logs=[
+                _log(
+                    "coredns-a",
+                    errors=4,
+                    upstream_timeouts=4,
+                    samples=["[ERROR] plugin/errors: 2 example.com. A: i/o timeout"],
+                ),
+                {"pod": "coredns-b", "container": "coredns", "error": "forbidden"},
+            ]
+        )
+    )
+
+    assert servfail is not None
+    assert (servfail["verdict"], servfail["cause"]) == ("degraded", "servfail_spike")
+    assert servfail["findings"] == [
+        "CoreDNS answered SERVFAIL 25 times in the scanned log window"
+    ]
+    assert timeouts is not None
+    assert (timeouts["verdict"], timeouts["cause"]) == ("degraded", "upstream_timeouts")
+    assert timeouts["log_samples"] == ["[ERROR] plugin/errors: 2 example.com. A: i/o timeout"]
+    assert timeouts["findings"][-1] == "could not read the logs of DNS pods coredns-b"
+    assert DnsAnalysis.model_validate(timeouts).upstream_timeouts == 4
+
+
+def test_dns_analysis_points_at_the_client_when_coredns_is_healthy() -> None:
+    analysis = build_dns_analysis(
+        _context(pod_logs=["Get http://payments: dial tcp: lookup payments: no such host"])
+    )
+
+    assert analysis is not None
+    assert analysis["verdict"] == "healthy"
+    assert analysis["cause"] is None
+    assert analysis["alert_log_lines"] == [
+        "Get http://payments: dial tcp: lookup payments: no such host"
+    ]
+    assert "check the pod's dnsPolicy and ndots search path" in str(analysis["findings"][0])
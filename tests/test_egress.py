@@ -0,0 +1,68 @@
+from __future__ import annotations
+
+import pytest
+
+from app.clients.prometheus import PrometheusClient
+from app.core.config import load_settings
+from app.core.egress import (
+    EgressDenied,
+    EgressPolicy,
+    check_egress,
+    init_egress_policy,
+    is_host_allowed,
+)
+
+
+@pytest.fixture(autouse=True)
+def _reset_policy():
+    yield
+    init_egress_policy(())
+
+
+def test_policy_matches_exact_wildcard_and_port_patterns() -> None:
+    policy = EgressPolicy(
+        allowed=frozenset({"prometheus.monitoring.svc", "*.googleapis.com", "loki:3100"})
+    )
+
+    assert policy.allows_url("http://prometheus.monitoring.svc:9090/api/v1/query")
+    assert policy.allows_url("https://generativelanguage.googleapis.com/v1beta")
+    assert policy.allows_url("http://loki:3100/loki/api/v1/query_range")
+    assert not policy.allows_url("http://loki:8080/loki/api/v1/query_range")
+    assert not policy.allows_url("http://googleapis.com.evil.example/")
+    assert not policy.allows_url("http://169.254.169.254/latest/meta-data")
+
+
+def test_empty_allowlist_allows_everything() -> None:
+    init_egress_policy(())
+
+    check_egress("http://anything.example")
+    assert is_host_allowed("api.openai.com") is True
+
+
+def test_check_egress_rejects_hosts_outside_allowlist() -> None:
+    init_egress_policy(["prometheus"])
+
+    check_egress("http://prometheus:9090/api/v1/query")
+    with pytest.raises(EgressDenied):
+        check_egress("http://tempo:3200/api/search")
+    assert is_host_allowed("api.openai.com") is False
+
+
+def test_data_source_client_returns_error_for_denied_host(
+    monkeypatch: pytest.MonkeyPatch,
+) -> None:
+    monkeypatch.setenv("PROMETHEUS_URL", "http://prometheus:9090")
+    init_egress_policy(["loki"])
+
+    result = PrometheusClient(load_settings()).query("up")
+
+    assert result["error"] == "failed to query Prometheus"
+    assert "EGRESS_ALLOWED_HOSTS_JSON" in str(result["detail"])
+
+
+def test_egress_allowlist_from_env(monkeypatch: pytest.MonkeyPatch) -> None:
+    monkeypatch.setenv("EGRESS_ALLOWED_HOSTS_JSON", '["prometheus", "*.googleapis.com"]')
+
+    settings = load_settings()
+
+    assert settings.egress_allowed_hosts == ("prometheus", "*.googleapis.com")
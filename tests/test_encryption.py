@@ -0,0 +1,187 @@
+from __future__ import annotations
+
+import logging
+
+import pytest
+from cryptography.fernet import Fernet
+
+from app.clients import kms as kms_module
+from app.clients.kms import build_key_wrapper
+from app.clients.summary_store import PostgresSummaryStore
+from app.core.config import load_settings
+from app.core.encryption import FieldCipher, build_field_cipher
+
+
+class _FakeCursor:
+    def __init__(self, store: list[str]) -> None:
+        self._store = store
+        self._rows: list[dict[str, str]] = []
+
+    def execute(self, query: str, params: tuple[object, ...]) -> None:
+        if query.lstrip().startswith("INSERT"):
+            self._store.append(str(params[1]))
+        elif query.lstrip().startswith("SELECT"):
+            self._rows = [{"summary": value} for value in reversed(self._store)]
+
+    def fetchall(self) -> list[dict[str, str]]:
+        return self._rows
+
+    def __enter__(self) -> _FakeCursor:
+        return self
+
+    def __exit__(self, exc_type, exc, tb) -> None:  # type: ignore[no-untyped-def]
+        return None
+
+
+class _FakeConnection:
+    def __init__(self, store: list[str]) -> None:
+        self._store = store
+
+    def cursor(self) -> _FakeCursor:
+        return _FakeCursor(self._store)
+
+    def __enter__(self) -> _FakeConnection:
+        return self
+
+    def __exit__(self, exc_type, exc, tb) -> None:  # type: ignore[no-untyped-def]
+        return None
+
+
+def _summary_store(cipher: FieldCipher | None, rows: list[str]) -> PostgresSummaryStore:
+    store = PostgresSummaryStore.__new__(PostgresSummaryStore)
+    store._cipher = cipher
+    store._connect = lambda: _FakeConnection(rows)  # type: ignore[method-assign]
+    store._logger = logging.getLogger(__name__)
+    return store
+
+
+def test_json_roundtrip_and_ciphertext_hides_payload() -> None:
+    cipher = FieldCipher([Fernet.generate_key().decode()])
+    payload = {"message": {"content": [{"text": "password=hunter2"}]}}
+
+    stored = cipher.encrypt_json(payload)
+
+    assert "hunter2" not in str(stored)
+    assert cipher.decrypt_json(stored) == payload
+
+
+def test_plain_values_written_before_encryption_pass_through() -> None:
+    cipher = FieldCipher([Fernet.generate_key().decode()])
+
+    assert cipher.decrypt_json({"message": "legacy"}) == {"message": "legacy"}
+    assert cipher.decrypt_text("legacy summary") == "legacy summary"
+
+
+def test_rotated_keys_still_decrypt_old_values() -> None:
+    old_key = Fernet.generate_key().decode()
+    new_key = Fernet.generate_key().decode()
+    old_token = FieldCipher([old_key]).encrypt_text("summary")
+
+    rotated = FieldCipher([new_key, old_key])
+
+    assert rotated.decrypt_text(old_token) == "summary"
+    with pytest.raises(ValueError, match="could not be decrypted"):
+        FieldCipher([new_key]).decrypt_text(old_token)
+
+
+def test_invalid_key_is_rejected() -> None:
+    with pytest.raises(ValueError, match="Fernet keys"):
+        FieldCipher(["not-a-key"])
+
+
+def test_encryption_disabled_without_keys(monkeypatch: pytest.MonkeyPatch) -> None:
+    monkeypatch.delenv("ENCRYPTION_KEYS", raising=False)
+
+    assert build_field_cipher(load_settings().encryption_keys) is None
+
+
+def test_settings_parse_comma_separated_keys(monkeypatch: pytest.MonkeyPatch) -> None:
+    monkeypatch.setenv("ENCRYPTION_KEYS", " key-a , key-b ,")
+
+    assert load_settings().encryption_keys == ("key-a", "key-b")
+
+
+def test_summary_store_encrypts_rows() -> None:
+    rows: list[str] = []
+    store = _summary_store(FieldCipher([Fernet.generate_key().decode()]), rows)
+
+    store.append_summary("session-1", "OOMKilled in payments", max_items=3)
+
+    assert rows and "OOMKilled" not in rows[0]
+    assert store.list_summaries("session-1", limit=3) == ["OOMKilled in payments"]
+
+
+class _FakeKms:
+    def __init__(self) -> None:
+        self._master = Fernet(Fernet.generate_key())
+        self.unwrapped = 0
+
+    def wrap(self, data_key: bytes) -> bytes:
+        return self._master.encrypt(data_key)
+
+    def unwrap(self, wrapped: bytes) -> bytes:
+        self.unwrapped += 1
+        return self._master.decrypt(wrapped)
+
+
+def test_kms_envelope_roundtrip_keeps_data_key_wrapped() -> None:
+    kms = _FakeKms()
+    writer = FieldCipher([], key_wrapper=kms)
+    payload = {"message": "password=hunter2"}
+
+    stored = writer.encrypt_json(payload)
+    text = writer.encrypt_text("OOMKilled in payments")
+
+    assert stored["__enc__"] == "kms-v1" and "hunter2" not in str(stored)
+    assert text.startswith("enc:kms-v1:") and "OOMKilled" not in text
+    reader = FieldCipher([], key_wrapper=kms)
+    assert reader.decrypt_json(stored) == payload
+    assert reader.decrypt_text(text) == "OOMKilled in payments"
+    assert reader.decrypt_text(writer.encrypt_text("again")) == "again"
+    assert kms.unwrapped == 1
+
+
+def test_kms_cipher_still_reads_values_of_local_keys() -> None:
+    key = Fernet.generate_key().decode()
+    old_token = FieldCipher([key]).encrypt_text("summary")
+
+    assert FieldCipher([key], key_wrapper=_FakeKms()).decrypt_text(old_token) == "summary"
+    with pytest.raises(ValueError, match="ENCRYPTION_KEYS, which are not set"):
+        FieldCipher([], key_wrapper=_FakeKms()).decrypt_text(old_token)
+
+
+def test_envelope_value_needs_its_kms() -> None:
+    stored = FieldCipher([], key_wrapper=_FakeKms()).encrypt_json({"message": "m"})
+
+    with pytest.raises(ValueError, match="ENCRYPTION_KMS_PROVIDER is not set"):
+        FieldCipher([Fernet.generate_key().decode()]).decrypt_json(stored)
+    with pytest.raises(ValueError, match="could not be unwrapped"):
+        FieldCipher([], key_wrapper=_FakeKms()).decrypt_json(stored)
+
+
+def test_vault_transit_wraps_data_keys(monkeypatch: pytest.MonkeyPatch) -> None:
+    calls: list[tuple[str, dict[str, str]]] = []
+
+    def fake_vault_api(name: str, path: str, body: dict[str, str]) -> dict[str, object]:
+        calls.append((path, body))
+        if path.endswith("/encrypt/kube-rca"):
+            return {"data": {"ciphertext": f"vault:v1:{body['plaintext']}"}}
+        return {"data": {"plaintext": body["ciphertext"].removeprefix("vault:v1:")}}
+
+    monkeypatch.setattr(kms_module, "vault_api", fake_vault_api)
+    wrapper = build_key_wrapper("vault", "kube-rca", vault_mount="transit-rca")
+
+    assert wrapper is not None
+    assert wrapper.unwrap(wrapper.wrap(b"data-key")) == b"data-key"
+    assert [path for path, _ in calls] == [
+        "transit-rca/encrypt/kube-rca",
+        "transit-rca/decrypt/kube-rca",
+    ]
+
+
+def test_key_wrapper_settings_are_validated() -> None:
+    assert build_key_wrapper("", "") is None
+    with pytest.raises(ValueError, match="ENCRYPTION_KMS_KEY_ID is required"):
+        build_key_wrapper("vault", "")
+    with pytest.raises(ValueError, match="expected aws or vault"):
+        build_key_wrapper("gcp", "key")
@@ -0,0 +1,187 @@
+from __future__ import annotations
+
+import socket
+import threading
+from collections.abc import Iterator
+from contextlib import contextmanager
+from http.server import BaseHTTPRequestHandler, HTTPServer
+
+import pytest
+
+from app.clients.endpoint_probe import EndpointProbeClient
+from app.core.config import load_settings
+from app.core.egress import EgressDenied
+
+
+_LOOPBACK_ALLOWED = '["127.0.0.1/32"]'
+_requests: list[str] = []
+
+
+class _Handler(BaseHTTPRequestHandler):
+    def do_GET(self) -> None:  # noqa: N802
+        _requests.append(self.path)
+        if self.path == "/redirect-private":
+            self.send_response(302)
+            self.send_header("Location", "http://10.0.0.1/admin")
+            self.send_header("Content-Length", "0")
+            self.end_headers()
+            return
+        status = 503 if self.path == "/down" else 200
+        self.send_response(status)
+        self.send_header("Content-Length", "2")
+        self.end_headers()
+        self.wfile.write(b"ok")
+
+    def log_message(self, format: str, *args: object) -> None:  # noqa: A002
+        return None
+
+
+@contextmanager
+def _serve() -> Iterator[str]:
+    server = HTTPServer(("127.0.0.1", 0), _Handler)
+    thread = threading.Thread(target=server.serve_forever, daemon=True)
+    thread.start()
+    try:
+        yield f"http://127.0.0.1:{server.server_port}"
+    finally:
+        server.shutdown()
+        server.server_close()
+
+
+def _client(monkeypatch: pytest.MonkeyPatch, **env: str) -> EndpointProbeClient:
+    monkeypatch.setenv("ENDPOINT_PROBE_ENABLED", "true")
+    for key, value in env.items():
+        monkeypatch.setenv(key, value)
+    return EndpointProbeClient(load_settings())
+
+
+def test_probe_reports_status_and_latency(monkeypatch: pytest.MonkeyPatch) -> None:
+    client = _client(monkeypatch, ENDPOINT_PROBE_ALLOWED_NETWORKS_JSON=_LOOPBACK_ALLOWED)
+
+    with _serve() as server_url:
+        healthy = client.probe(f"{server_url}/")
+        down = client.probe(f"{server_url}/down")
+
+    assert healthy["impact"] == "none"
+    assert healthy["http"]["status_code"] == 200  # type: ignore[index]
+    assert isinstance(healthy["http"]["latency_ms"], float)  # type: ignore[index]
+    assert healthy["tls"] is None
+    assert down["impact"] == "confirmed"
+    assert down["findings"] == ["endpoint returned HTTP 503"]
+
+
+def test_probe_flags_slow_and_unreachable_endpoints(monkeypatch: pytest.MonkeyPatch) -> None:
+    client = _client(
+        monkeypatch,
+        ENDPOINT_PROBE_SLOW_THRESHOLD_MS="1",
+        ENDPOINT_PROBE_ALLOWED_NETWORKS_JSON=_LOOPBACK_ALLOWED,
+    )
+    monkeypatch.setattr("app.clients.endpoint_probe._elapsed_ms", lambda started: 2500.0)
+
+    with _serve() as server_url:
+        slow = client.probe(f"{server_url}/")
+    unreachable = client.probe("http://127.0.0.1:1/")
+
+    assert slow["impact"] == "degraded"
+    assert slow["findings"] == ["endpoint answered in 2500.0 ms (threshold 1 ms)"]
+    assert unreachable["impact"] == "confirmed"
+    assert "endpoint unreachable:" in str(unreachable["findings"])
+
+
+def test_probe_measures_http_behind_an_invalid_certificate(
+    monkeypatch: pytest.MonkeyPatch,
+) -> None:
+    client = _client(monkeypatch)
+    requests: list[tuple[str, bool]] = []
+    monkeypatch.setattr(
+        client,
+        "_check_tls",
+        lambda host, port: {"valid": False, "error": "certificate has expired"},
+    )
+
+    def fake_request(url: str, *, verify: bool) -> dict[str, object]:
+        requests.append((url, verify))
+        return {"status_code": 200, "latency_ms": 12.0, "final_url": None}
+
+    monkeypatch.setattr(client, "_request", fake_request)
+
+    result = client.probe("https://shop.example.com/health")
+
+    assert requests == [("https://shop.example.com/health", False)]
+    assert result["impact"] == "confirmed"
+    assert result["findings"] == ["TLS certificate is not valid: certificate has expired"]
+
+
+def test_probe_rejects_non_http_urls_and_denied_egress(monkeypatch: pytest.MonkeyPatch) -> None:
+    client = _client(monkeypatch)
+
+    assert "only absolute" in str(client.probe("file:///etc/passwd")["error"])
+
+    def deny(url: str) -> None:
+        raise EgressDenied("egress to internal.example.com is not in EGRESS_ALLOWED_HOSTS_JSON")
+
+    monkeypatch.setattr("app.clients.endpoint_probe.check_egress", deny)
+    result = client.probe("https://internal.example.com/")
+
+    assert "EGRESS_ALLOWED_HOSTS_JSON" in str(result["error"])
+
+
+def test_probe_refuses_the_cloud_metadata_address(monkeypatch: pytest.MonkeyPatch) -> None:
+    client = _client(monkeypatch)
+
+    result = client.probe("http://169.254.169.254/latest/meta-data/iam/security-credentials/")
+
+    assert "http" not in result
+    assert "169.254.169.254 is an internal address" in str(result["error"])
+
+
+def test_probe_refuses_localhost_unless_allowlisted(monkeypatch: pytest.MonkeyPatch) -> None:
+    client = _client(monkeypatch)
+    _requests.clear()
+
+    with _serve() as server_url:
+        port = server_url.rsplit(":", 1)[1]
+        result = client.probe(f"http://localhost:{port}/")
+
+    assert "http" not in result
+    assert "localhost (127.0.0.1) is an internal address" in str(result["error"])
+    assert _requests == []
+
+
+def test_probe_refuses_a_redirect_to_a_private_address(monkeypatch: pytest.MonkeyPatch) -> None:
+    client = _client(monkeypatch, ENDPOINT_PROBE_ALLOWED_NETWORKS_JSON=_LOOPBACK_ALLOWED)
+    _requests.clear()
+
+    with _serve() as server_url:
+        result = client.probe(f"{server_url}/redirect-private")
+
+    assert _requests == ["/redirect-private"]
+    assert "10.0.0.1 is an internal address" in str(result["error"])
+
+
+def test_probe_connects_to_the_address_it_checked(monkeypatch: pytest.MonkeyPatch) -> None:
+    client = _client(monkeypatch)
+    answers = iter(["93.184.215.14", "10.0.0.5"])
+    connected: list[tuple[object, ...]] = []
+
+    def rebinding_getaddrinfo(host: str, port: int, **kwargs: object) -> list[tuple[object, ...]]:
+        return [(socket.AF_INET, socket.SOCK_STREAM, 6, "", (next(answers), port))]
+
+    def fake_create_connection(address: tuple[object, ...], *args: object) -> socket.socket:
+        connected.append(address)
+        raise ConnectionRefusedError("refused")
+
+    monkeypatch.setattr(socket, "getaddrinfo", rebinding_getaddrinfo)
+    monkeypatch.setattr(socket, "create_connection", fake_create_connection)
+
+    result = client.probe("http://shop.example.com/health")
+
+    assert connected == [("93.184.215.14", 80)]
+    assert result["impact"] == "confirmed"
+
+
+def test_allowed_networks_must_be_cidrs(monkeypatch: pytest.MonkeyPatch) -> None:
+    monkeypatch.setenv("ENDPOINT_PROBE_ALLOWED_NETWORKS_JSON", '["10.0.0.0/8", "intranet"]')
+
+    with pytest.raises(ValueError):
+        load_settings()
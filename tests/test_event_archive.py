@@ -0,0 +1,63 @@
+from __future__ import annotations
+
+from app.models.k8s import PodEventSummary
+from app.services.event_archive import EventArchiver, merge_archived_events
+
+
+def _event(reason: str, first_timestamp: str, *, count: int = 1) -> PodEventSummary:
+    return PodEventSummary(
+        type="Warning",
+        reason=reason,
+        message=f"{reason} message",
+        count=count,
+        first_timestamp=first_timestamp,
+        last_timestamp=first_timestamp,
+        involved_object={"kind": "Pod", "name": "checkout-7d9f8c-abcde", "namespace": "shop"},
+    )
+
+
+class _FakeWatcher:
+    def __init__(self, events: list[tuple[str, PodEventSummary]]) -> None:
+        self._events = events
+        self.timeouts: list[int] = []
+
+    def watch_cluster_events(self, timeout_seconds: int):
+        self.timeouts.append(timeout_seconds)
+        yield from self._events
+
+
+class _FakeArchive:
+    def __init__(self) -> None:
+        self.batches: list[list[str]] = []
+
+    def record(self, events: list[tuple[str, PodEventSummary]]) -> int:
+        if events:
+            self.batches.append([uid for uid, _ in events])
+        return len(events)
+
+
+def test_event_archiver_records_one_watch_window_in_batches() -> None:
+    watched = [(f"uid-{index}", _event("BackOff", "2026-10-14T09:00:00Z")) for index in range(5)]
+    watcher = _FakeWatcher(watched)
+    archive = _FakeArchive()
+    archiver = EventArchiver(watcher, archive, watch_seconds=120, batch_size=2)
+
+    assert archiver.run_once() == 5
+    assert watcher.timeouts == [120]
+    assert archive.batches == [["uid-0", "uid-1"], ["uid-2", "uid-3"], ["uid-4"]]
+
+    archiver.stop()
+    assert archiver.run_once() == 0
+
+
+def test_merge_archived_events_keeps_live_copies_and_appends_expired_events() -> None:
+    live = [_event("BackOff", "2026-10-14T09:03:00+00:00", count=9)]
+    archived = [
+        _event("BackOff", "2026-10-14T09:03:00Z", count=6),
+        _event("FailedMount", "2026-10-14T08:58:00+00:00", count=3),
+    ]
+
+    merged = merge_archived_events(live, archived)
+
+    assert [(event.reason, event.count) for event in merged] == [("BackOff", 9), ("FailedMount", 3)]
+    assert merge_archived_events(live, []) == live
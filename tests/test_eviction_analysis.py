@@ -0,0 +1,151 @@
+from __future__ import annotations
+
+from app.models.k8s import K8sContext, PodEventSummary, PodStatusSnapshot
+from app.schemas.analysis import EvictionAnalysis
+from app.services.eviction_analysis import build_eviction_analysis
+from app.services.rules import run_rule_analyzers
+
+_PRESSURE_MESSAGE = (
+    "The node was low on resource: memory. Threshold quantity: 100Mi, available: 84Mi. "
+    "Container api was using 1200Mi, request is 512Mi, has larger consumption of memory. "
+)
+
+
+def _event(reason: str, message: str, pod: str = "api-0") -> PodEventSummary:
+    return PodEventSummary(
+        type="Warning",
+        reason=reason,
+        message=message,
+        count=1,
+        first_timestamp=None,
+        last_timestamp="2026-10-14T09:02:00Z",
+        involved_object={"kind": "Pod", "name": pod, "namespace": "shop"},
+    )
+
+
+def _context(
+    events: list[PodEventSummary],
+    *,
+    reason: str | None = None,
+    message: str | None = None,
+    conditions: list[dict[str, str | None]] | None = None,
+    priority_class: str | None = None,
+) -> K8sContext:
+    return K8sContext(
+        namespace="shop",
+        pod_name="api-0",
+        workload="api",
+        pod_status=PodStatusSnapshot(
+            phase="Failed",
+            node_name="node-1",
+            start_time=None,
+            reason=reason,
+            message=message,
+            conditions=conditions or [],
+            container_statuses=[],
+        ),
+        events=events,
+        previous_logs=[],
+        warnings=[],
+        pod_spec={"priority_class_name": priority_class, "containers": []},
+    )
+
+
+def test_eviction_analysis_reports_node_pressure_once_per_pod() -> None:
+    context = _context(
+        [_event("Evicted", _PRESSURE_MESSAGE.strip())],
+        reason="Evicted",
+        message=_PRESSURE_MESSAGE,
+        conditions=[
+            {
+                "type": "DisruptionTarget",
+                "status": "True",
+                "reason": "TerminationByKubelet",
+                "message": _PRESSURE_MESSAGE,
+                "last_transition_time": "2026-10-14T09:02:00Z",
+            }
+        ],
+    )
+
+    analysis = build_eviction_analysis(context)
+
+    assert analysis is not None
+    evictions = analysis["evictions"]
+    assert isinstance(evictions, list) and len(evictions) == 1
+    assert evictions[0]["evicted_by"] == "kubelet"
+    assert (evictions[0]["resource"], evictions[0]["threshold"], evictions[0]["available"]) == (
+        "memory",
+        "100Mi",
+        "84Mi",
+    )
+    assert analysis["findings"] == [
+        "pod api-0 was evicted by the kubelet on node-1: the node was low on memory (available "
+        "84Mi, threshold 100Mi); container api used 1200Mi with a request of 512Mi, and pods "
+        "using more than they request are evicted first"
+    ]
+    finding = next(item for item in run_rule_analyzers(context) if item.rule == "evicted")
+    assert finding.title == "Pod was evicted by the kubelet"
+    assert finding.evidence[-1] == analysis["findings"][0]
+    assert finding.recommendation.startswith("Set requests close to actual usage")
+    assert EvictionAnalysis.model_validate(analysis).evictions[0].containers[0]["usage"] == (
+        "1200Mi"
+    )
+
+
+def test_eviction_analysis_names_the_preemptor_and_priority() -> None:
+    context = _context(
+        [
+            _event("Preempted", "Preempted by shop/batch-high on node node-2"),
+            _event("Preempted", "Preempted by pod 6f1c0a on node node-3", pod="api-1"),
+        ],
+        conditions=[
+            {
+                "type": "DisruptionTarget",
+                "status": "True",
+                "reason": "PreemptionByScheduler",
+                "message": "Kubernetes default scheduler: preempting to accommodate a higher "
+                "priority pod",
+                "last_transition_time": "2026-10-14T09:02:00Z",
+            }
+        ],
+    )
+
+    analysis = build_eviction_analysis(context)
+
+    assert analysis is not None
+    assert analysis["findings"] == [
+        "pod api-0 was preempted by the scheduler on node-2 to make room for higher-priority pod "
+        "shop/batch-high; it has no priorityClassName and runs at the default priority",
+        "pod api-1 was preempted by the scheduler on node-3 to make room for higher-priority pod "
+        "6f1c0a",
+    ]
+    finding = next(item for item in run_rule_analyzers(context) if item.rule == "evicted")
+    assert finding.title == "Pod was preempted by a higher-priority pod"
+
+
+def test_eviction_analysis_reads_disruption_conditions_without_events() -> None:
+    context = _context(
+        [],
+        conditions=[
+            {
+                "type": "DisruptionTarget",
+                "status": "True",
+                "reason": "EvictionByEvictionAPI",
+                "message": "Eviction API: evicting",
+                "last_transition_time": "2026-10-14T09:00:00Z",
+            }
+        ],
+        priority_class="batch-low",
+    )
+
+    analysis = build_eviction_analysis(context)
+
+    assert analysis is not None
+    assert analysis["priority_class_name"] == "batch-low"
+    assert analysis["findings"] == [
+        "pod api-0 was evicted through the Eviction API on node-1 (kubectl drain, cluster "
+        "autoscaler scale-down or a descheduler)"
+    ]
+    finding = next(item for item in run_rule_analyzers(context) if item.rule == "evicted")
+    assert finding.title == "Pod was evicted through the Eviction API"
+    assert build_eviction_analysis(_context([], reason="Terminated", message="done")) is None
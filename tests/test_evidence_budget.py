@@ -0,0 +1,118 @@
+from __future__ import annotations
+
+from app.core.evidence_budget import (
+    budget_range_result,
+    score_log_line,
+    select_events,
+    select_log_lines,
+)
+
+
+def test_score_log_line_ranks_errors_over_warnings() -> None:
+    assert score_log_line("2026-10-14T02:00:00Z ERROR connection refused") == 3
+    assert score_log_line("E1014 02:00:00.000000 1 controller.go:42] sync failed") == 3
+    assert score_log_line('level=warn msg="retrying request"') == 2
+    assert score_log_line("GET /healthz 200") == 1
+
+
+def test_select_log_lines_keeps_error_and_stack_over_routine_tail() -> None:
+    lines = [
+        "starting server",
+        "Traceback (most recent call last):",
+        '  File "/app/db.py", line 8, in connect',
+        "psycopg2.OperationalError: could not connect to server",
+        *[f"GET /healthz 200 req={idx}" for idx in range(20)],
+    ]
+
+    selected = select_log_lines(lines, 5)
+
+    assert selected == [
+        "... 1 line(s) omitted ...",
+        "Traceback (most recent call last):",
+        '  File "/app/db.py", line 8, in connect',
+        "psycopg2.OperationalError: could not connect to server",
+        "... 18 line(s) omitted ...",
+        "GET /healthz 200 req=18",
+        "GET /healthz 200 req=19",
+    ]
+
+
+def test_select_log_lines_collapses_repeats_ignoring_timestamps() -> None:
+    lines = [
+        "2026-10-14T02:00:00.1Z dial tcp 10.0.0.5:5432: connect: connection refused",
+        "2026-10-14T02:00:01.1Z dial tcp 10.0.0.5:5432: connect: connection refused",
+        "2026-10-14T02:00:02.1Z dial tcp 10.0.0.5:5432: connect: connection refused",
+        "2026-10-14T02:00:03.1Z shutting down",
+    ]
+
+    assert select_log_lines(lines, 10) == [
+        "2026-10-14T02:00:02.1Z dial tcp 10.0.0.5:5432: connect: connection refused [x3]",
+        "2026-10-14T02:00:03.1Z shutting down",
+    ]
+    assert select_log_lines(lines, 0) == []
+
+
+def test_select_events_dedupes_and_prefers_warnings() -> None:
+    def event(event_type: str, reason: str, message: str, count: int) -> dict[str, object]:
+        return {"type": event_type, "reason": reason, "message": message, "count": count}
+
+    events = [
+        event("Normal", "Pulled", "image pulled", 1),
+        event("Warning", "BackOff", "back-off restarting failed container", 3),
+        event("Normal", "Created", "created container", 1),
+        event("Warning", "BackOff", "back-off restarting failed container", 4),
+        event("Warning", "Unhealthy", "readiness probe failed", 2),
+    ]
+
+    selected = select_events(events, 2)
+
+    assert [item["reason"] for item in selected] == ["BackOff", "Unhealthy"]
+    assert selected[0]["count"] == 7
+
+
+def _range_result(series: list[list[float]]) -> dict[str, object]:
+    return {
+        "endpoint": {"base_url": "http://prometheus:9090"},
+        "data": {
+            "status": "success",
+            "data": {
+                "resultType": "matrix",
+                "result": [
+                    {
+                        "metric": {"pod": f"api-{index}"},
+                        "values": [
+                            [1760400000 + step * 60, str(value)]
+                            for step, value in enumerate(values)
+                        ],
+                    }
+                    for index, values in enumerate(series)
+                ],
+            },
+        },
+    }
+
+
+def test_budget_range_result_keeps_anomalous_series_and_spikes() -> None:
+    flat = [10.0] * 12
+    spiky = [10.0] * 5 + [95.0] + [10.0] * 6
+    result = _range_result([flat, spiky, flat])
+
+    budgeted = budget_range_result(result, max_series=1, max_points=4)
+
+    kept = budgeted["data"]["data"]["result"]  # type: ignore[index]
+    assert [item["metric"]["pod"] for item in kept] == ["api-1"]
+    assert len(kept[0]["values"]) == 4
+    assert any(value == "95.0" for _, value in kept[0]["values"])
+    assert budgeted["budget"] == {
+        "series_total": 3,
+        "series_kept": 1,
+        "max_points": 4,
+        "selection": "most anomalous (max deviation from median)",
+    }
+
+
+def test_budget_range_result_leaves_small_results_unchanged() -> None:
+    result = _range_result([[1.0, 2.0]])
+
+    assert budget_range_result(result) is result
+    assert budget_range_result({"error": "failed"}) == {"error": "failed"}
@@ -0,0 +1,37 @@
+from __future__ import annotations
+
+import pytest
+
+import app.core.fips as fips_module
+from app.core.config import load_settings
+from app.core.fips import enforce_fips_mode
+
+
+def test_fips_mode_disabled_is_noop(monkeypatch: pytest.MonkeyPatch) -> None:
+    monkeypatch.setattr(fips_module, "openssl_fips_enabled", lambda: False)
+
+    enforce_fips_mode(False)
+
+
+def test_fips_mode_fails_without_fips_openssl(monkeypatch: pytest.MonkeyPatch) -> None:
+    monkeypatch.setattr(fips_module, "openssl_fips_enabled", lambda: False)
+    monkeypatch.setattr(fips_module, "_md5_blocked", lambda: False)
+
+    with pytest.raises(RuntimeError) as exc_info:
+        enforce_fips_mode(True)
+
+    assert "FIPS-enabled OpenSSL" in str(exc_info.value)
+    assert "MD5" in str(exc_info.value)
+
+
+def test_fips_mode_passes_on_fips_openssl(monkeypatch: pytest.MonkeyPatch) -> None:
+    monkeypatch.setattr(fips_module, "openssl_fips_enabled", lambda: True)
+    monkeypatch.setattr(fips_module, "_md5_blocked", lambda: True)
+
+    enforce_fips_mode(True)
+
+
+def test_fips_mode_from_env(monkeypatch: pytest.MonkeyPatch) -> None:
+    monkeypatch.setenv("FIPS_MODE", "true")
+
+    assert load_settings().fips_mode is True
@@ -0,0 +1,196 @@
+from __future__ import annotations
+
+import json
+import urllib.error
+
+import pytest
+
+import app.clients.git_hosting as git_module
+from app.clients.git_hosting import GitHostingClient
+from app.core.config import load_settings
+
+
+class _FakeHTTPResponse:
+    def __init__(self, payload: object) -> None:
+        self._body = json.dumps(payload).encode("utf-8")
+
+    def read(self) -> bytes:
+        return self._body
+
+    def __enter__(self) -> _FakeHTTPResponse:
+        return self
+
+    def __exit__(self, exc_type, exc, tb) -> None:  # type: ignore[no-untyped-def]
+        return None
+
+
+def test_compare_github_lists_commits_and_files(monkeypatch: pytest.MonkeyPatch) -> None:
+    monkeypatch.setenv("GIT_CORRELATION_ENABLED", "true")
+    monkeypatch.setenv("GITHUB_TOKEN", "ghp_test")
+    captured: dict[str, object] = {}
+
+    def fake_urlopen(request, timeout=0):  # type: ignore[no-untyped-def]
+        captured["url"] = request.full_url
+        captured["authorization"] = request.get_header("Authorization")
+        return _FakeHTTPResponse(
+            {
+                "html_url": "https://github.com/acme/api/compare/aaa...bbb",
+                "commits": [
+                    {
+                        "sha": "bbb1234567890",
+                        "commit": {
+                            "message": "Bump pgx to v5.6\n\nSigned-off-by: dev",
+                            "author": {"name": "dev", "date": "2026-10-13T08:00:00Z"},
+                        },
+                    }
+                ],
+                "files": [
+                    {"filename": "go.mod", "status": "modified", "additions": 1, "deletions": 1}
+                ],
+            }
+        )
+
+    monkeypatch.setattr(git_module.urllib.request, "urlopen", fake_urlopen)
+
+    result = GitHostingClient(load_settings()).compare(
+        "https://github.com/acme/api.git", "aaa", "bbb"
+    )
+
+    assert captured["url"] == "https://api.github.com/repos/acme/api/compare/aaa...bbb"
+    assert captured["authorization"] == "Bearer ghp_test"
+    assert result["commits"] == [
+        {
+            "sha": "bbb123456789",
+            "message": "Bump pgx to v5.6",
+            "author": "dev",
+            "date": "2026-10-13T08:00:00Z",
+        }
+    ]
+    assert result["files"] == [
+        {"path": "go.mod", "status": "modified", "additions": 1, "deletions": 1}
+    ]
+    assert result["compare_url"] == "https://github.com/acme/api/compare/aaa...bbb"
+
+
+def test_compare_gitlab_uses_project_path(monkeypatch: pytest.MonkeyPatch) -> None:
+    monkeypatch.setenv("GIT_CORRELATION_ENABLED", "true")
+    captured: dict[str, object] = {}
+
+    def fake_urlopen(request, timeout=0):  # type: ignore[no-untyped-def]
+        captured["url"] = request.full_url
+        return _FakeHTTPResponse(
+            {
+                "commits": [{"id": "ccc", "title": "Add orders migration", "author_name": "a"}],
+                "diffs": [{"new_path": "db/migrations/007_orders.sql", "new_file": True}],
+            }
+        )
+
+    monkeypatch.setattr(git_module.urllib.request, "urlopen", fake_urlopen)
+
+    result = GitHostingClient(load_settings()).compare(
+        "git@gitlab.com:acme/platform/orders.git", "aaa", "ccc"
+    )
+
+    assert str(captured["url"]).startswith(
+        "https://gitlab.com/api/v4/projects/acme%2Fplatform%2Forders/repository/compare?"
+    )
+    assert result["repository"] == "acme/platform/orders"
+    assert result["files"] == [{"path": "db/migrations/007_orders.sql", "status": "added"}]
+
+
+def test_compare_reports_http_errors_and_unknown_hosts(monkeypatch: pytest.MonkeyPatch) -> None:
+    monkeypatch.setenv("GIT_CORRELATION_ENABLED", "true")
+
+    def fake_urlopen(request, timeout=0):  # type: ignore[no-untyped-def]
+        raise urllib.error.HTTPError(request.full_url, 404, "Not Found", {}, None)
+
+    monkeypatch.setattr(git_module.urllib.request, "urlopen", fake_urlopen)
+    client = GitHostingClient(load_settings())
+
+    assert client.compare("https://github.com/acme/api", "aaa", "bbb") == {
+        "provider": "github",
+        "repository": "acme/api",
+        "status_code": 404,
+        "error": "Not Found",
+    }
+    assert client.compare("https://bitbucket.org/acme/api", "aaa", "bbb") == {
+        "error": "unsupported source repository: https://bitbucket.org/acme/api"
+    }
+
+
+def test_pipeline_status_github_reports_failed_and_skipped_jobs(
+    monkeypatch: pytest.MonkeyPatch,
+) -> None:
+    monkeypatch.setenv("GIT_CORRELATION_ENABLED", "true")
+    urls: list[str] = []
+
+    def fake_urlopen(request, timeout=0):  # type: ignore[no-untyped-def]
+        urls.append(request.full_url)
+        if "/actions/runs/42/jobs" in request.full_url:
+            return _FakeHTTPResponse(
+                {
+                    "jobs": [
+                        {"name": "build", "conclusion": "success"},
+                        {"name": "e2e", "conclusion": "failure"},
+                        {"name": "db-migrate", "conclusion": "skipped"},
+                    ]
+                }
+            )
+        return _FakeHTTPResponse(
+            {
+                "workflow_runs": [
+                    {
+                        "id": 42,
+                        "name": "release",
+                        "event": "push",
+                        "status": "completed",
+                        "conclusion": "failure",
+                        "html_url": "https://github.com/acme/api/actions/runs/42",
+                    }
+                ]
+            }
+        )
+
+    monkeypatch.setattr(git_module.urllib.request, "urlopen", fake_urlopen)
+
+    result = GitHostingClient(load_settings()).pipeline_status(
+        "https://github.com/acme/api", "bbb"
+    )
+
+    assert urls[0] == "https://api.github.com/repos/acme/api/actions/runs?head_sha=bbb&per_page=5"
+    assert result["run_count"] == 1
+    assert result["runs"][0]["failed_jobs"] == ["e2e"]
+    assert result["runs"][0]["skipped_jobs"] == ["db-migrate"]
+    assert result["partially_failed"] is True
+    assert result["skipped_migrations"] == ["db-migrate"]
+
+
+def test_pipeline_status_gitlab_maps_pipeline_status(monkeypatch: pytest.MonkeyPatch) -> None:
+    monkeypatch.setenv("GIT_CORRELATION_ENABLED", "true")
+
+    def fake_urlopen(request, timeout=0):  # type: ignore[no-untyped-def]
+        if "/pipelines/7/jobs" in request.full_url:
+            return _FakeHTTPResponse([{"name": "test", "status": "success"}])
+        return _FakeHTTPResponse(
+            [{"id": 7, "ref": "main", "source": "push", "status": "success", "web_url": "u"}]
+        )
+
+    monkeypatch.setattr(git_module.urllib.request, "urlopen", fake_urlopen)
+
+    result = GitHostingClient(load_settings()).pipeline_status(
+        "https://gitlab.com/acme/orders", "ccc"
+    )
+
+    assert result["runs"] == [
+        {
+            "id": 7,
+            "name": "main",
+            "event": "push",
+            "status": "success",
+            "conclusion": "success",
+            "url": "u",
+            "failed_jobs": [],
+            "skipped_jobs": [],
+        }
+    ]
+    assert result["partially_failed"] is False
@@ -0,0 +1,100 @@
+from __future__ import annotations
+
+from app.schemas.alert import Alert
+from app.schemas.analysis import AlertAnalysisRequest, AlertGroupAnalysisRequest
+from app.services.group_analysis import AlertGroupService, _parse_group_summary
+
+
+class FakeAnalyzer:
+    def __init__(self) -> None:
+        self.requests: list[AlertAnalysisRequest] = []
+
+    def analyze(
+        self, request: AlertAnalysisRequest
+    ) -> tuple[str, str, str, dict[str, object], list[dict[str, object]]]:
+        self.requests.append(request)
+        pod = request.alert.labels.get("pod", "")
+        context: dict[str, object] = {
+            "namespace": request.alert.labels.get("namespace"),
+            "workload": None,
+            "pod_status": {"node_name": "pool-x-1" if pod != "other" else "pool-y-1"},
+            "analysis_id": f"alert:{request.alert.fingerprint}:run:1234abcd",
+        }
+        return "analysis", f"{pod} OOMKilled", "detail", context, []
+
+
+class FakeEngine:
+    def __init__(self, answer: str | None = None) -> None:
+        self.prompts: list[str] = []
+        self._answer = answer
+
+    def analyze(self, prompt: str, incident_id: str | None = None) -> str:
+        self.prompts.append(prompt)
+        if self._answer is None:
+            raise RuntimeError("engine down")
+        return self._answer
+
+
+def _alert(pod: str, fingerprint: str, status: str = "firing") -> Alert:
+    return Alert(
+        status=status,
+        labels={"alertname": "KubePodOOMKilled", "namespace": "shop", "pod": pod},
+        fingerprint=fingerprint,
+    )
+
+
+def _request(*alerts: Alert) -> AlertGroupAnalysisRequest:
+    return AlertGroupAnalysisRequest(
+        alerts=list(alerts), thread_ts="1234567890.123456", group_labels={"alertname": "x"}
+    )
+
+
+def test_group_analysis_summarizes_members_in_one_answer() -> None:
+    analyzer = FakeAnalyzer()
+    engine = FakeEngine("COMMON_CAUSE: node pool X out of memory\n3개 알림이 같은 노드 풀에서 발생")
+    service = AlertGroupService(analyzer, engine)
+
+    result = service.analyze(
+        _request(_alert("a", "f1"), _alert("b", "f2"), _alert("a", "f1"), _alert("c", "f3"))
+    )
+
+    assert result["alert_count"] == 3
+    assert result["analyzed_count"] == 3
+    assert result["common_cause"] == "node pool X out of memory"
+    assert result["group_summary"] == "3개 알림이 같은 노드 풀에서 발생"
+    assert result["shared"] == {
+        "alertname": "KubePodOOMKilled",
+        "namespace": "shop",
+        "node": "pool-x-1",
+    }
+    assert [request.alert.fingerprint for request in analyzer.requests] == ["f1", "f2", "f3"]
+    assert "Shared by most alerts: alertname=KubePodOOMKilled" in engine.prompts[0]
+    assert "b OOMKilled" in engine.prompts[0]
+
+
+def test_group_analysis_limits_individual_analyses_and_prefers_firing() -> None:
+    analyzer = FakeAnalyzer()
+    service = AlertGroupService(analyzer, FakeEngine("COMMON_CAUSE: none\nsummary"), max_alerts=2)
+
+    result = service.analyze(
+        _request(_alert("a", "f1", "resolved"), _alert("b", "f2"), _alert("other", "f3"))
+    )
+
+    assert result["alert_count"] == 3
+    assert result["analyzed_count"] == 2
+    assert result["common_cause"] is None
+    assert [request.alert.fingerprint for request in analyzer.requests] == ["f2", "f3"]
+
+
+def test_group_analysis_falls_back_to_correlation_summary() -> None:
+    service = AlertGroupService(FakeAnalyzer(), FakeEngine(None))
+
+    result = service.analyze(_request(_alert("a", "f1"), _alert("b", "f2")))
+
+    assert result["degraded"] is True
+    assert str(result["group_summary"]).startswith("2개 알림 (KubePodOOMKilled x2)")
+    assert "node=pool-x-1" in str(result["group_summary"])
+
+
+def test_parse_group_summary_without_cause_line() -> None:
+    assert _parse_group_summary("just a summary") == (None, "just a summary")
@@ -0,0 +1,161 @@
+from __future__ import annotations
+
+import json
+from datetime import datetime, timedelta, timezone
+
+import pytest
+
+import app.clients.report_sink as report_sink_module
+from app.clients.k8s import parse_quantity
+from app.clients.report_sink import WebhookReportSink, build_report_sink
+from app.core.config import load_settings
+from app.services.health_scan import HealthScanService
+
+
+class _FakeHealthSource:
+    def __init__(
+        self,
+        *,
+        pods: list[dict[str, object]] | None = None,
+        certificates: list[dict[str, object]] | None = None,
+        quotas: list[dict[str, object]] | None = None,
+    ) -> None:
+        self._pods = pods or []
+        self._certificates = certificates or []
+        self._quotas = quotas or []
+        self.pending_minutes: list[int] = []
+
+    def list_unhealthy_pods(
+        self, namespace: str, *, pending_minutes: int = 10
+    ) -> list[dict[str, object]]:
+        self.pending_minutes.append(pending_minutes)
+        return self._pods
+
+    def list_certificates(self, namespace: str) -> list[dict[str, object]]:
+        return self._certificates
+
+    def list_resource_quota_usage(self, namespace: str) -> list[dict[str, object]]:
+        return self._quotas
+
+
+class _FakeSink:
+    def __init__(self) -> None:
+        self.reports: list[tuple[str, dict[str, object]]] = []
+
+    def send(self, report_type: str, report: dict[str, object]) -> dict[str, object]:
+        self.reports.append((report_type, report))
+        return {"delivered": True, "status_code": 202}
+
+
+def test_scan_reports_pods_certificates_and_quotas() -> None:
+    soon = (datetime.now(timezone.utc) + timedelta(days=3)).isoformat()
+    later = (datetime.now(timezone.utc) + timedelta(days=60)).isoformat()
+    source = _FakeHealthSource(
+        pods=[
+            {"pod": "api-0", "issue": "pending", "reason": "Unschedulable"},
+            {"pod": "worker-1", "issue": "crash_loop", "container": "app", "restart_count": 7},
+        ],
+        certificates=[
+            {"name": "api-tls", "not_after": soon, "ready": "True"},
+            {"name": "web-tls", "not_after": later, "ready": "True"},
+            {"name": "admin-tls", "not_after": None, "ready": "False", "reason": "Failed"},
+        ],
+        quotas=[
+            {
+                "quota": "compute",
+                "resource": "limits.cpu",
+                "used": "19",
+                "hard": "20",
+                "ratio": 0.95,
+            },
+            {"quota": "compute", "resource": "pods", "used": "3", "hard": "50", "ratio": 0.06},
+        ],
+    )
+    sink = _FakeSink()
+    service = HealthScanService(source, ["payments"], sink=sink, pending_minutes=15)
+
+    report = service.scan()
+
+    assert source.pending_minutes == [15]
+    checks = [finding["check"] for finding in report["findings"]]  # type: ignore[index]
+    assert checks == [
+        "pending",
+        "crash_loop",
+        "certificate_expiry",
+        "certificate_not_ready",
+        "quota_saturation",
+    ]
+    assert report["finding_count"] == 5
+    assert report["delivery"] == {"delivered": True, "status_code": 202}
+    assert sink.reports[0][0] == "health_scan"
+    assert service.latest_report is report
+
+
+def test_scan_without_findings_skips_sink() -> None:
+    sink = _FakeSink()
+    service = HealthScanService(_FakeHealthSource(), ["payments"], sink=sink)
+
+    report = service.scan()
+
+    assert report["finding_count"] == 0
+    assert "delivery" not in report
+    assert sink.reports == []
+
+
+def test_scan_disabled_without_namespaces() -> None:
+    assert HealthScanService(_FakeHealthSource(), []).enabled is False
+
+
+@pytest.mark.parametrize(
+    "value, expected",
+    [("10", 10.0), ("500m", 0.5), ("2Gi", 2 * 2**30), ("1k", 1000.0), ("bogus", None)],
+)
+def test_parse_quantity(value: str, expected: float | None) -> None:
+    assert parse_quantity(value) == expected
+
+
+class _FakeHTTPResponse:
+    status = 202
+
+    def __enter__(self) -> _FakeHTTPResponse:
+        return self
+
+    def __exit__(self, exc_type, exc, tb) -> None:  # type: ignore[no-untyped-def]
+        return None
+
+
+def test_webhook_sink_posts_report(monkeypatch: pytest.MonkeyPatch) -> None:
+    captured: dict[str, object] = {}
+
+    def fake_urlopen(request, timeout=0):  # type: ignore[no-untyped-def]
+        captured["url"] = request.full_url
+        captured["body"] = json.loads(request.data)
+        return _FakeHTTPResponse()
+
+    monkeypatch.setattr(report_sink_module.urllib.request, "urlopen", fake_urlopen)
+
+    result = WebhookReportSink("https://hooks.example.com/rca").send(
+        "health_scan", {"finding_count": 1}
+    )
+
+    assert result == {"delivered": True, "status_code": 202}
+    assert captured["url"] == "https://hooks.example.com/rca"
+    assert captured["body"] == {"type": "health_scan", "report": {"finding_count": 1}}
+
+
+def test_build_report_sink_disabled_without_url() -> None:
+    assert build_report_sink("  ") is None
+
+
+def test_health_scan_settings_from_env(monkeypatch: pytest.MonkeyPatch) -> None:
+    monkeypatch.setenv("HEALTH_SCAN_NAMESPACES_JSON", '["payments", "checkout"]')
+    monkeypatch.setenv("HEALTH_SCAN_INTERVAL_SECONDS", "900")
+    monkeypatch.setenv("HEALTH_SCAN_QUOTA_THRESHOLD", "0.8")
+    monkeypatch.setenv("REPORT_WEBHOOK_URL", "https://hooks.example.com/rca")
+
+    settings = load_settings()
+
+    assert settings.health_scan_namespaces == ("payments", "checkout")
+    assert settings.health_scan_interval_seconds == 900
+    assert settings.health_scan_quota_threshold == 0.8
+    assert settings.report_webhook_url == "https://hooks.example.com/rca"
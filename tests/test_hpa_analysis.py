@@ -0,0 +1,185 @@
+from __future__ import annotations
+
+from app.models.k8s import K8sContext
+from app.schemas.analysis import HpaAnalysis
+from app.services.hpa_analysis import build_hpa_analysis
+
+
+def _context(hpa_status: dict[str, object] | None) -> K8sContext:
+    return K8sContext(
+        namespace="shop",
+        pod_name="api-7d9f8c-abcde",
+        workload="api",
+        pod_status=None,
+        events=[],
+        previous_logs=[],
+        warnings=[],
+        hpa_status=hpa_status,
+    )
+
+
+def _cpu_metric(target: int) -> dict[str, object]:
+    return {
+        "type": "Resource",
+        "resource": {
+            "name": "cpu",
+            "target": {"type": "Utilization", "average_utilization": target},
+        },
+    }
+
+
+def _hpa(**overrides: object) -> dict[str, object]:
+    hpa: dict[str, object] = {
+        "name": "api",
+        "namespace": "shop",
+        "target": {"kind": "Deployment", "name": "api"},
+        "min_replicas": 2,
+        "max_replicas": 10,
+        "current_replicas": 4,
+        "desired_replicas": 4,
+        "last_scale_time": "2026-10-14T01:50:00+00:00",
+        "metrics": [_cpu_metric(70)],
+        "current_metrics": [
+            {
+                "type": "Resource",
+                "resource": {
+                    "name": "cpu",
+                    "current": {"average_utilization": 55, "average_value": "275m"},
+                },
+            }
+        ],
+        "conditions": [
+            {"type": "AbleToScale", "status": "True", "reason": "ReadyForNewScale"},
+            {"type": "ScalingActive", "status": "True", "reason": "ValidMetricFound"},
+            {"type": "ScalingLimited", "status": "False", "reason": "DesiredWithinRange"},
+        ],
+        "events": [],
+    }
+    hpa.update(overrides)
+    return hpa
+
+
+def test_hpa_analysis_flags_workload_pinned_at_max_replicas() -> None:
+    hpa = _hpa(
+        current_replicas=10,
+        desired_replicas=10,
+        current_metrics=[
+            {
+                "type": "Resource",
+                "resource": {"name": "cpu", "current": {"average_utilization": 96}},
+            }
+        ],
+        conditions=[
+            {
+                "type": "ScalingLimited",
+                "status": "True",
+                "reason": "TooManyReplicas",
+                "message": "the desired replica count is more than the maximum replica count",
+            }
+        ],
+        events=[
+            {"reason": "SuccessfulRescale", "message": "New size: 8; reason: cpu above target"},
+            {"reason": "SuccessfulRescale", "message": "New size: 10; reason: cpu above target"},
+        ],
+    )
+
+    analysis = build_hpa_analysis(_context(hpa))
+
+    assert analysis is not None
+    assert analysis["at_max_replicas"] is True
+    assert analysis["metrics_unavailable"] is False
+    assert analysis["metrics"] == [
+        {"name": "cpu", "type": "Resource", "target": "70%", "current": "96%", "above_target": True}
+    ]
+    assert analysis["scaling_events"] == [
+        "New size: 8; reason: cpu above target",
+        "New size: 10; reason: cpu above target",
+    ]
+    assert analysis["findings"] == [
+        "HPA api is pinned at maxReplicas (10/10) with cpu at 96% (target 70%); the workload "
+        "cannot scale out further, so extra load shows up as latency and errors: raise "
+        "maxReplicas (and check the cluster has room for the pods) or reduce the load per pod"
+    ]
+    assert HpaAnalysis.model_validate(analysis).metrics[0].above_target is True
+
+
+def test_hpa_analysis_reports_unavailable_metrics() -> None:
+    hpa = _hpa(
+        current_metrics=[],
+        conditions=[
+            {
+                "type": "ScalingActive",
+                "status": "False",
+                "reason": "FailedGetResourceMetric",
+                "message": "unable to get metrics for resource cpu: no metrics returned",
+            },
+            {
+                "type": "AbleToScale",
+                "status": "False",
+                "reason": "FailedGetScale",
+                "message": None,
+            },
+        ],
+        events=[
+            {
+                "reason": "FailedGetResourceMetric",
+                "message": "unable to get metrics for resource cpu: no metrics returned",
+            }
+        ],
+    )
+
+    analysis = build_hpa_analysis(_context(hpa))
+
+    assert analysis is not None
+    assert analysis["metrics_unavailable"] is True
+    assert analysis["metrics"][0]["current"] is None
+    assert analysis["findings"] == [
+        "HPA api cannot get its metrics (FailedGetResourceMetric: unable to get metrics for "
+        "resource cpu: no metrics returned); it holds 4 replica(s) and does not scale until the "
+        "metrics API (metrics-server, a Prometheus adapter or the external metrics provider) "
+        "answers",
+        "HPA api cannot scale Deployment/api (AbleToScale=False, FailedGetScale)",
+    ]
+
+
+def test_hpa_analysis_compares_average_value_targets_and_scale_out() -> None:
+    hpa = _hpa(
+        desired_replicas=6,
+        metrics=[
+            {
+                "type": "Pods",
+                "pods": {
+                    "metric": {"name": "http_requests_per_second"},
+                    "target": {"type": "AverageValue", "average_value": "100"},
+                },
+            }
+        ],
+        current_metrics=[
+            {
+                "type": "Pods",
+                "pods": {
+                    "metric": {"name": "http_requests_per_second"},
+                    "current": {"average_value": "150500m"},
+                },
+            }
+        ],
+    )
+
+    analysis = build_hpa_analysis(_context(hpa))
+
+    assert analysis is not None
+    assert analysis["metrics"][0]["above_target"] is True
+    assert analysis["metrics"][0]["current"] == "150500m"
+    assert analysis["findings"] == [
+        "HPA api is scaling out from 4 to 6 replicas; the new pods may still be starting or "
+        "Pending"
+    ]
+
+
+def test_hpa_analysis_healthy_or_missing_hpa() -> None:
+    analysis = build_hpa_analysis(_context(_hpa()))
+
+    assert analysis is not None
+    assert analysis["findings"] == []
+    assert analysis["metrics"][0]["above_target"] is False
+    assert build_hpa_analysis(_context(None)) is None
@@ -0,0 +1,171 @@
+from __future__ import annotations
+
+import json
+import threading
+import time
+
+from app.schemas.analysis import RankedHypothesis
+from app.services.hypotheses import (
+    DEFAULT_HYPOTHESES,
+    HypothesisInvestigator,
+    format_ranked_hypotheses,
+    parse_verdict,
+)
+
+
+def _verdict(verdict: str, confidence: float, **extra: object) -> str:
+    return json.dumps({"verdict": verdict, "confidence": confidence, **extra})
+
+
+class _BranchEngine:
+    """Answers each hypothesis branch from *answers*, keyed by hypothesis key."""
+
+    def __init__(self, answers: dict[str, str], *, block: set[str] | None = None) -> None:
+        self._answers = answers
+        self._block = block or set()
+        self.release = threading.Event()
+        self.session_ids: list[str] = []
+        self.threads: set[str] = set()
+        self._lock = threading.Lock()
+
+    def analyze(self, prompt: str, incident_id: str | None = None) -> str:
+        key = str(incident_id).rsplit(":", 1)[-1]
+        with self._lock:
+            self.session_ids.append(str(incident_id))
+            self.threads.add(threading.current_thread().name)
+        assert "Hypothesis under investigation: " in prompt
+        if key in self._block:
+            self.release.wait(5)
+        if key not in self._answers:
+            raise RuntimeError("model unavailable")
+        return self._answers[key]
+
+
+def test_investigator_runs_branches_in_parallel_and_ranks_verdicts() -> None:
+    engine = _BranchEngine(
+        {
+            "config_change": _verdict("refuted", 0.9, summary="no rollout in the last day"),
+            "capacity": "Analysis:\n"
+            + _verdict(
+                "supported",
+                0.8,
+                summary="HPA pinned at max",
+                supporting=["HPA api at 10/10", "cpu 96%"],
+                contradicting=[],
+            ),
+            "dependency": _verdict("inconclusive", 0.4, summary="no dependency metrics"),
+        }
+    )
+    investigator = HypothesisInvestigator(max_parallel=3, timeout_seconds=5)
+
+    ranked = investigator.investigate(engine, "base prompt", "alert:abc")
+
+    assert [item["key"] for item in ranked] == ["capacity", "dependency", "config_change"]
+    assert [item["rank"] for item in ranked] == [1, 2, 3]
+    assert ranked[0]["supporting"] == ["HPA api at 10/10", "cpu 96%"]
+    assert sorted(engine.session_ids) == [
+        "alert:abc:hypothesis:capacity",
+        "alert:abc:hypothesis:config_change",
+        "alert:abc:hypothesis:dependency",
+    ]
+    assert all(name.startswith("hypothesis") for name in engine.threads)
+    assert RankedHypothesis.model_validate(ranked[0]).verdict == "supported"
+
+
+def test_investigator_marks_slow_and_failing_branches_inconclusive() -> None:
+    engine = _BranchEngine(
+        {"config_change": _verdict("supported", 0.7), "capacity": _verdict("refuted", 0.6)},
+        block={"capacity"},
+    )
+    investigator = HypothesisInvestigator(max_parallel=3, timeout_seconds=5)
+
+    ranked = investigator.investigate(engine, "base prompt", "alert:abc", timeout_seconds=0.2)
+    engine.release.set()
+
+    by_key = {item["key"]: item for item in ranked}
+    assert by_key["config_change"]["verdict"] == "supported"
+    assert by_key["capacity"]["verdict"] == "inconclusive"
+    assert by_key["capacity"]["summary"] == "not finished within 0.2s"
+    assert by_key["dependency"]["verdict"] == "inconclusive"
+    assert by_key["dependency"]["summary"] == "investigation failed: model unavailable"
+    assert ranked[0]["key"] == "config_change"
+
+
+def test_overlapping_investigations_do_not_wait_for_each_other() -> None:
+    slow = _BranchEngine({}, block={"config_change", "capacity", "dependency"})
+    fast = _BranchEngine(
+        {
+            "config_change": _verdict("refuted", 0.9),
+            "capacity": _verdict("supported", 0.8),
+            "dependency": _verdict("refuted", 0.5),
+        }
+    )
+    investigator = HypothesisInvestigator(max_parallel=3, timeout_seconds=5)
+    first = threading.Thread(
+        target=investigator.investigate, args=(slow, "base prompt", "alert:slow")
+    )
+    first.start()
+    deadline = time.monotonic() + 5
+    while len(slow.session_ids) < 3 and time.monotonic() < deadline:
+        time.sleep(0.01)
+    try:
+        ranked = investigator.investigate(fast, "base prompt", "alert:fast", timeout_seconds=1)
+    finally:
+        slow.release.set()
+        first.join(5)
+
+    assert [item["verdict"] for item in ranked] == ["supported", "refuted", "refuted"]
+
+
+def test_investigator_cancels_branches_not_started_within_the_timeout() -> None:
+    engine = _BranchEngine({}, block={"config_change", "capacity", "dependency"})
+    investigator = HypothesisInvestigator(max_parallel=1, timeout_seconds=5)
+
+    ranked = investigator.investigate(engine, "base prompt", "alert:abc", timeout_seconds=0.2)
+    engine.release.set()
+    time.sleep(0.2)
+
+    assert engine.session_ids == ["alert:abc:hypothesis:config_change"]
+    assert {item["summary"] for item in ranked} == {"not finished within 0.2s"}
+
+
+def test_parse_verdict_tolerates_malformed_answers() -> None:
+    hypothesis = DEFAULT_HYPOTHESES[2]
+
+    assert parse_verdict(hypothesis, "no json here")["verdict"] == "inconclusive"
+    clamped = parse_verdict(
+        hypothesis,
+        _verdict("SUPPORTED", 3, supporting=["a", "", "b", "c", "d", "e", "f"]),
+    )
+    assert clamped["verdict"] == "supported"
+    assert clamped["confidence"] == 1.0
+    assert clamped["supporting"] == ["a", "b", "c", "d", "e"]
+    unknown = parse_verdict(hypothesis, _verdict("maybe", "high"))
+    assert unknown["verdict"] == "inconclusive"
+    assert unknown["confidence"] == 0.0
+
+
+def test_format_ranked_hypotheses_lists_evidence_for_the_final_analysis() -> None:
+    ranked = [
+        {
+            "rank": 1,
+            "key": "dependency",
+            "title": "A dependency is failing",
+            "verdict": "supported",
+            "confidence": 0.85,
+            "summary": "postgres refuses connections",
+            "supporting": ["connection refused to postgres:5432"],
+            "contradicting": ["no rollout"],
+        }
+    ]
+
+    section = format_ranked_hypotheses(ranked)
+
+    assert "## Ranked hypotheses" in section
+    assert (
+        "1. A dependency is failing — supported (confidence 0.85): postgres refuses connections"
+        in section
+    )
+    assert "   - supporting: connection refused to postgres:5432" in section
+    assert "   - contradicting: no rollout" in section
+    assert format_ranked_hypotheses([]) == ""
@@ -0,0 +1,224 @@
+from __future__ import annotations
+
+from app.models.k8s import K8sContext, PodEventSummary, PodStatusSnapshot
+from app.schemas.analysis import ImagePullAnalysis
+from app.services.image_pull import (
+    build_image_pull_analysis,
+    classify_pull_error,
+    parse_image_reference,
+)
+
+
+def _event(reason: str, message: str) -> PodEventSummary:
+    return PodEventSummary(
+        type="Warning",
+        reason=reason,
+        message=message,
+        count=3,
+        first_timestamp=None,
+        last_timestamp=None,
+        involved_object={"kind": "Pod", "name": "api-0", "namespace": "shop"},
+    )
+
+
+def _waiting(name: str, reason: str, message: str | None = None) -> dict[str, object]:
+    return {
+        "name": name,
+        "ready": False,
+        "restart_count": 0,
+        "state": {"type": "waiting", "reason": reason, "message": message},
+        "last_state": None,
+    }
+
+
+def _context(
+    container_statuses: list[dict[str, object]],
+    *,
+    events: list[PodEventSummary] | None = None,
+    init_container_statuses: list[dict[str, object]] | None = None,
+    pull_secrets: list[str] | None = None,
+) -> K8sContext:
+    return K8sContext(
+        namespace="shop",
+        pod_name="api-0",
+        workload="api",
+        pod_status=PodStatusSnapshot(
+            phase="Pending",
+            node_name="node-1",
+            start_time=None,
+            reason=None,
+            message=None,
+            conditions=[],
+            container_statuses=container_statuses,  # type: ignore[arg-type]
+            init_container_statuses=init_container_statuses or [],
+        ),
+        events=events or [],
+        previous_logs=[],
+        warnings=[],
+        pod_spec={
+            "image_pull_secrets": pull_secrets or [],
+            "init_containers": [
+                {
+                    "name": "migrate",
+                    "image": "ghcr.io/acme/migrate@sha256:0f3c",
+                    "image_pull_policy": "IfNotPresent",
+                }
+            ],
+            "containers": [
+                {
+                    "name": "api",
+                    "image": "registry.acme.io:5000/shop/api:v1.4.1",
+                    "image_pull_policy": "IfNotPresent",
+                }
+            ],
+        },
+    )
+
+
+def test_image_pull_analysis_reports_auth_failure_with_reference_and_pull_secrets() -> None:
+    denied = (
+        'Failed to pull image "registry.acme.io:5000/shop/api:v1.4.1": rpc error: code = '
+        "Unknown desc = failed to authorize: failed to fetch oauth token: unexpected status: "
+        "401 Unauthorized"
+    )
+    context = _context(
+        [
+            _waiting(
+                "api",
+                "ImagePullBackOff",
+                'Back-off pulling image "registry.acme.io:5000/shop/api:v1.4.1"',
+            )
+        ],
+        events=[
+            _event("BackOff", 'Back-off pulling image "registry.acme.io:5000/shop/api:v1.4.1"'),
+            _event("Failed", denied),
+        ],
+        pull_secrets=["acme-registry"],
+    )
+
+    analysis = build_image_pull_analysis(context)
+
+    assert analysis is not None
+    assert analysis["pull_secrets"] == ["acme-registry"]
+    assert analysis["containers"] == [
+        {
+            "container": "api",
+            "init": False,
+            "image": "registry.acme.io:5000/shop/api:v1.4.1",
+            "registry": "registry.acme.io:5000",
+            "repository": "shop/api",
+            "tag": "v1.4.1",
+            "digest": None,
+            "image_pull_policy": "IfNotPresent",
+            "reason": "ImagePullBackOff",
+            "cause": "auth_failed",
+            "message": denied,
+        }
+    ]
+    assert analysis["findings"] == [
+        "container api cannot pull image registry.acme.io:5000/shop/api:v1.4.1: registry "
+        "registry.acme.io:5000 denied access to shop/api with imagePullSecrets acme-registry "
+        f"({denied})"
+    ]
+    assert ImagePullAnalysis.model_validate(analysis).containers[0].cause == "auth_failed"
+
+
+def test_image_pull_analysis_distinguishes_missing_digests_and_registry_timeouts() -> None:
+    not_found = (
+        'Failed to pull image "ghcr.io/acme/migrate@sha256:0f3c": rpc error: code = NotFound '
+        'desc = failed to resolve reference "ghcr.io/acme/migrate@sha256:0f3c": not found'
+    )
+    timeout = (
+        'Failed to pull image "registry.acme.io:5000/shop/api:v1.4.1": rpc error: code = '
+        'Unknown desc = failed to do request: Head "https://registry.acme.io:5000/v2/shop/api/'
+        'manifests/v1.4.1": dial tcp 10.20.0.4:5000: i/o timeout'
+    )
+    context = _context(
+        [_waiting("api", "ErrImagePull", timeout)],
+        init_container_statuses=[_waiting("migrate", "ErrImagePull", not_found)],
+        events=[
+            _event(
+                "FailedToRetrieveImagePullSecret",
+                "Unable to retrieve some image pull secrets (acme-registry, ghcr); attempting "
+                "to pull the image may not succeed.",
+            )
+        ],
+        pull_secrets=["acme-registry", "ghcr"],
+    )
+
+    analysis = build_image_pull_analysis(context)
+
+    assert analysis is not None
+    containers = analysis["containers"]
+    assert [(item["container"], item["init"], item["cause"]) for item in containers] == [
+        ("migrate", True, "tag_not_found"),
+        ("api", False, "registry_timeout"),
+    ]
+    assert analysis["missing_pull_secrets"] == ["acme-registry", "ghcr"]
+    findings = analysis["findings"]
+    assert findings[0].startswith(
+        "init container migrate cannot pull image ghcr.io/acme/migrate@sha256:0f3c: digest "
+        "sha256:0f3c does not exist in ghcr.io/acme/migrate"
+    )
+    assert findings[1].startswith(
+        "container api cannot pull image registry.acme.io:5000/shop/api:v1.4.1: pulling from "
+        "registry registry.acme.io:5000 timed out"
+    )
+    assert findings[2] == (
+        "imagePullSecrets acme-registry, ghcr of pod api-0 do not exist in namespace shop; "
+        "pulls cannot use their credentials"
+    )
+
+
+def test_image_pull_analysis_falls_back_to_events_without_failing_statuses() -> None:
+    message = (
+        'Failed to pull image "nginx:typo": rpc error: code = NotFound desc = failed to pull and '
+        'unpack image "docker.io/library/nginx:typo": not found'
+    )
+    healthy = _context([], events=[_event("Normal", "Pulled container image")])
+    recovered = _context([], events=[_event("Failed", message)])
+
+    analysis = build_image_pull_analysis(recovered)
+
+    assert build_image_pull_analysis(healthy) is None
+    assert analysis is not None
+    assert analysis["containers"][0]["container"] is None
+    assert analysis["containers"][0]["repository"] == "library/nginx"
+    assert analysis["findings"] == [
+        "pod cannot pull image nginx:typo: tag typo does not exist in docker.io/library/nginx "
+        f"({message})"
+    ]
+
+
+def test_classify_pull_error_and_parse_image_reference() -> None:
+    assert classify_pull_error(
+        "pull access denied for acme/api, repository does not exist or may require "
+        "'docker login': denied: requested access to the resource is denied"
+    ) == "auth_failed"
+    assert classify_pull_error(
+        "manifest for gcr.io/acme/api:v2 not found: manifest unknown: Failed to fetch \"v2\""
+    ) == "tag_not_found"
+    assert classify_pull_error("name unknown: repository name not known to registry") == (
+        "repository_not_found"
+    )
+    assert classify_pull_error(
+        "toomanyrequests: You have reached your pull rate limit."
+    ) == "rate_limited"
+    assert classify_pull_error("dial tcp: lookup registry.acme.io: no such host") == (
+        "registry_unreachable"
+    )
+    assert classify_pull_error("x509: certificate signed by unknown authority") == "tls_error"
+    assert classify_pull_error(None, "InvalidImageName") == "invalid_reference"
+    assert classify_pull_error('Back-off pulling image "nginx:1.27"') == "unknown"
+    assert parse_image_reference("nginx") == {
+        "registry": "docker.io",
+        "repository": "library/nginx",
+        "tag": "latest",
+        "digest": None,
+    }
+    assert parse_image_reference("localhost/acme/api@sha256:abc") == {
+        "registry": "localhost",
+        "repository": "acme/api",
+        "tag": None,
+        "digest": "sha256:abc",
+    }
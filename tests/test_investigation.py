@@ -0,0 +1,86 @@
+from __future__ import annotations
+
+import asyncio
+import threading
+
+from app.api import investigation
+from app.core.concurrency import init_concurrency, run_in_thread_limited
+from app.core.masking import RegexMasker
+from app.services.investigation import MaskedLineStream, tool_result_text
+
+
+def test_masked_line_stream_masks_secrets_split_across_chunks() -> None:
+    events: list[dict[str, object]] = []
+    stream = MaskedLineStream(RegexMasker.from_patterns([r"token-\d+"]), events.append)
+
+    for event in (
+        {"type": "chunk", "text": "found tok"},
+        {"type": "tool_call", "tool": "get_pod_logs"},
+        {"type": "chunk", "text": "en-42 in\nlogs and "},
+        {"type": "chunk", "text": "token-7"},
+    ):
+        stream.feed(event)
+    assert stream.streamed is True
+    stream.flush()
+
+    assert events == [
+        {"type": "tool_call", "tool": "get_pod_logs"},
+        {"type": "chunk", "text": "found [MASKED] in\n"},
+        {"type": "chunk", "text": "logs and [MASKED]"},
+    ]
+
+
+def test_tool_result_text_joins_text_and_json_blocks_and_truncates() -> None:
+    result = {
+        "status": "success",
+        "content": [{"text": "pod ready"}, {"json": {"restarts": 3}}, "ignored"],
+    }
+
+    assert tool_result_text(result) == 'pod ready\n{\n  "restarts": 3\n}'
+    assert tool_result_text({"content": None}) == ""
+    long_text = tool_result_text({"content": [{"text": "x" * 30_000}]})
+    assert long_text.endswith("\n... (truncated)")
+    assert len(long_text) == 20_000 + len("\n... (truncated)")
+
+
+class _RecordingSocket:
+    def __init__(self) -> None:
+        self.sent: list[dict[str, object]] = []
+
+    async def send_json(self, event: dict[str, object]) -> None:
+        self.sent.append(event)
+
+
+def test_run_tool_waits_for_a_slot_and_answers_busy_with_an_error_frame(monkeypatch) -> None:
+    monkeypatch.setattr(investigation, "_SLOT_WAIT_SECONDS", 0.05)
+    init_concurrency(max_concurrent=1)
+    release = threading.Event()
+    calls: list[str] = []
+
+    class _Service:
+        def run_investigation_tool(self, analysis_id, tool, arguments):
+            calls.append(tool)
+            return {"tool": tool, "status": "success", "output": "ok"}
+
+    async def _run() -> _RecordingSocket:
+        socket = _RecordingSocket()
+        busy = asyncio.ensure_future(run_in_thread_limited(release.wait))
+        await asyncio.sleep(0.05)
+        message = {"type": "run_tool", "tool": "get_pods", "arguments": {}}
+        await investigation._handle_message(socket, _Service(), "a-1", message, print)
+        release.set()
+        await busy
+        await investigation._handle_message(socket, _Service(), "a-1", message, print)
+        return socket
+
+    socket = asyncio.run(_run())
+
+    assert socket.sent[0]["type"] == "error"
+    assert "analysis slots are busy" in str(socket.sent[0]["detail"])
+    assert socket.sent[1] == {
+        "type": "tool_result",
+        "tool": "get_pods",
+        "status": "success",
+        "output": "ok",
+    }
+    assert calls == ["get_pods"]
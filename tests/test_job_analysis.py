@@ -0,0 +1,175 @@
+from __future__ import annotations
+
+from app.models.k8s import K8sContext
+from app.schemas.analysis import JobFailureAnalysis
+from app.services.job_analysis import build_job_failure_analysis, job_target
+
+
+def _pod(name: str, *containers: dict[str, object], phase: str = "Failed") -> dict[str, object]:
+    return {
+        "name": name,
+        "phase": phase,
+        "reason": None,
+        "message": None,
+        "node": "node-1",
+        "start_time": "2026-10-14T02:00:00+00:00",
+        "failed": True,
+        "containers": list(containers),
+    }
+
+
+def _container(
+    state: dict[str, object], last_state: dict[str, object] | None = None
+) -> dict[str, object]:
+    return {
+        "name": "backup",
+        "image": "ops/backup:1.8",
+        "restart_count": 0,
+        "state": state,
+        "last_state": last_state,
+    }
+
+
+def _context(**job_run: object) -> K8sContext:
+    return K8sContext(
+        namespace="ops",
+        pod_name=None,
+        workload=None,
+        pod_status=None,
+        events=[],
+        previous_logs=[],
+        warnings=[],
+        job_run={
+            "namespace": "ops",
+            "job": "backup-28301234",
+            "cron_job": "backup",
+            "found": True,
+            "recent_runs": [],
+            "spec": {"backoff_limit": 1, "active_deadline_seconds": None},
+            "status": {"name": "backup-28301234", "active": 0, "succeeded": 0, "failed": 2},
+            "pods": [],
+            "logs": [],
+            **job_run,
+        },
+    )
+
+
+def _failed(reason: str) -> dict[str, object]:
+    return {
+        "name": "backup-28301234",
+        "active": 0,
+        "failed": 2,
+        "conditions": [{"type": "Failed", "status": "True", "reason": reason}],
+    }
+
+
+def test_job_failure_analysis_reports_backoff_limit_exhaustion_with_exit_codes() -> None:
+    oom = {"type": "terminated", "reason": "OOMKilled", "exit_code": "137"}
+    analysis = build_job_failure_analysis(
+        _context(
+            status=_failed("BackoffLimitExceeded"),
+            pods=[
+                _pod("backup-28301234-b2k9x", _container(oom)),
+                _pod(
+                    "backup-28301234-a1j8w",
+                    _container({"type": "terminated", "reason": "Error", "exit_code": "1"}),
+                ),
+            ],
+            logs=[
+                {"container": "backup", "previous": False, "logs": [f"line {i}" for i in range(30)]}
+            ],
+            recent_runs=[
+                _failed("BackoffLimitExceeded"),
+                {
+                    "name": "backup-28300000",
+                    "conditions": [{"type": "Complete", "status": "True"}],
+                },
+            ],
+        )
+    )
+
+    assert analysis is not None
+    assert (analysis["outcome"], analysis["cause"]) == ("failed", "backoff_limit_exceeded")
+    assert analysis["findings"] == [
+        "job ops/backup-28301234 failed 2 pods and reached its backoffLimit of 1; last failures: "
+        "backup-28301234-b2k9x: container backup exited with code 137 (OOMKilled); "
+        "backup-28301234-a1j8w: container backup exited with code 1 (Error)",
+        "1 of the last 2 runs of cronjob ops/backup failed",
+    ]
+    assert analysis["last_run_logs"][0]["logs"] == [f"line {i}" for i in range(20, 30)]
+    parsed = JobFailureAnalysis.model_validate(analysis)
+    assert [pod.reason for pod in parsed.failed_pods] == ["oom_killed", "exit_code"]
+    assert parsed.failed_pods[0].exit_code == 137
+    assert [run["outcome"] for run in parsed.recent_runs] == ["failed", "succeeded"]
+
+
+def test_job_failure_analysis_puts_image_errors_before_the_job_condition() -> None:
+    waiting = {"type": "waiting", "reason": "ImagePullBackOff", "message": "not found"}
+    analysis = build_job_failure_analysis(
+        _context(
+            status=_failed("DeadlineExceeded"),
+            pods=[_pod("backup-28301234-c3l0y", _container(waiting), phase="Pending")],
+        )
+    )
+    running = build_job_failure_analysis(
+        _context(
+            status={"name": "backup-28301234", "active": 1, "failed": 1, "conditions": []},
+            pods=[
+                _pod(
+                    "backup-28301234-d4m1z",
+                    _container({"type": "terminated", "reason": "Error", "exit_code": "3"}),
+                )
+            ],
+            spec={"backoff_limit": 4},
+        )
+    )
+
+    assert analysis is not None
+    assert analysis["cause"] == "image_error"
+    assert analysis["findings"] == [
+        "job ops/backup-28301234 pods cannot start: container backup image ops/backup:1.8 "
+        "ImagePullBackOff: not found"
+    ]
+    assert running is not None
+    assert (running["outcome"], running["cause"]) == ("running", "pods_failing")
+    assert running["findings"][0].startswith(
+        "job ops/backup-28301234 is still running after 1 failed pods; 3 retries left"
+    )
+
+
+def test_job_failure_analysis_reports_removed_jobs() -> None:
+    analysis = build_job_failure_analysis(_context(found=False, job=None))
+
+    assert analysis is not None
+    assert analysis["cause"] == "job_not_found"
+    assert analysis["findings"] == [
+        "cronjob ops/backup has no Jobs left; failed runs were removed by its history limits "
+        "or ttlSecondsAfterFinished"
+    ]
+
+
+def test_job_target_reads_job_alert_labels_and_owning_workloads() -> None:
+    context = _context()
+
+    assert job_target({"alertname": "KubeJobFailed", "job_name": "backup-1"}, context) == {
+        "namespace": "ops",
+        "job": "backup-1",
+    }
+    assert job_target(
+        {"alertname": "KubeCronJobRunning", "namespace": "ops", "cronjob": "backup"}, context
+    ) == {"namespace": "ops", "cron_job": "backup"}
+    assert job_target({"alertname": "HighLatency", "job_name": "backup-1"}, context) is None
+    owned = K8sContext(
+        namespace="ops",
+        pod_name="backup-1-x",
+        workload=None,
+        pod_status=None,
+        events=[],
+        previous_logs=[],
+        warnings=[],
+        workload_status={"kind": "CronJob", "name": "backup"},
+    )
+    assert job_target({"alertname": "KubePodCrashLooping"}, owned) == {
+        "namespace": "ops",
+        "cron_job": "backup",
+    }
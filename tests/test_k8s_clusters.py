@@ -0,0 +1,115 @@
+from __future__ import annotations
+
+import pytest
+
+from app.core import k8s_clusters, k8s_credentials
+from app.core.k8s_clusters import (
+    UnknownCluster,
+    current_cluster,
+    init_clusters,
+    resolve_cluster,
+    use_cluster,
+)
+from app.core.k8s_credentials import (
+    init_credential_passthrough,
+    use_cluster_token,
+    wrap_with_credentials,
+)
+
+
+_loaded: list[tuple[str, str | None]] = []
+
+
+class _FakeConfiguration:
+    def __init__(self, host: str) -> None:
+        self.host = host
+        self.api_key = {"authorization": "Bearer remote-admin"}
+        self.api_key_prefix: dict[str, str] = {}
+        self.refresh_api_key_hook = None
+        self.cert_file = self.key_file = self.username = self.password = None
+
+
+class _FakeApiClient:
+    def __init__(self, configuration: _FakeConfiguration | None = None) -> None:
+        self.configuration = configuration or _FakeConfiguration("https://local")
+
+    def close(self) -> None:
+        pass
+
+
+class _FakeCoreApi:
+    def __init__(self, api_client: _FakeApiClient | None = None) -> None:
+        self.api_client = api_client or _FakeApiClient()
+
+    def read_namespaced_pod(self, name: str, namespace: str) -> str:
+        return self.api_client.configuration.host
+
+
+@pytest.fixture(autouse=True)
+def _reset_clusters(monkeypatch: pytest.MonkeyPatch):
+    def fake_new_client(
+        config_file: str, context: str | None = None, persist_config: bool = True
+    ) -> _FakeApiClient:
+        _loaded.append((config_file, context))
+        return _FakeApiClient(_FakeConfiguration(f"https://{context or 'staging'}"))
+
+    _loaded.clear()
+    monkeypatch.setattr(k8s_clusters.config, "new_client_from_config", fake_new_client)
+    monkeypatch.setattr(k8s_credentials.client, "ApiClient", _FakeApiClient)
+    init_clusters(
+        [("prod-eu", "/etc/kube-rca/prod-eu", "eu-admin"), ("staging", "/etc/staging", "")],
+        local_name="prod-us",
+    )
+    yield
+    init_clusters(())
+    init_credential_passthrough(False)
+
+
+def test_resolve_cluster_maps_names_to_remote_clusters() -> None:
+    assert resolve_cluster(None) is None
+    assert resolve_cluster("prod-us") is None
+    assert resolve_cluster(" prod-eu ") == "prod-eu"
+    with pytest.raises(UnknownCluster, match="K8S_CLUSTERS_JSON"):
+        resolve_cluster("dev")
+
+    init_clusters(())
+    assert resolve_cluster("dev") is None
+
+
+def test_wrapped_api_sends_calls_to_the_selected_cluster() -> None:
+    proxy = wrap_with_credentials(_FakeCoreApi())
+
+    assert proxy.read_namespaced_pod("api-0", "shop") == "https://local"
+    with use_cluster("prod-eu"):
+        assert current_cluster() == "prod-eu"
+        assert proxy.read_namespaced_pod("api-0", "shop") == "https://eu-admin"
+        assert proxy.api_client.configuration.host == "https://eu-admin"
+    with use_cluster("prod-eu"):
+        proxy.read_namespaced_pod("api-0", "shop")
+    with use_cluster("staging"):
+        assert proxy.read_namespaced_pod("api-0", "shop") == "https://staging"
+
+    assert current_cluster() is None
+    assert proxy.read_namespaced_pod("api-0", "shop") == "https://local"
+    # One API client per cluster, created on first use.
+    assert _loaded == [
+        ("/etc/kube-rca/prod-eu", "eu-admin"),
+        ("/etc/staging", None),
+    ]
+
+
+def test_caller_token_is_sent_to_the_selected_cluster() -> None:
+    init_credential_passthrough(True)
+    proxy = wrap_with_credentials(_FakeCoreApi())
+
+    with use_cluster("prod-eu"), use_cluster_token("caller-token"):
+        configuration = proxy.api_client.configuration
+        host = proxy.read_namespaced_pod("api-0", "shop")
+
+    assert host == "https://eu-admin"
+    assert configuration.api_key == {"authorization": "caller-token"}
+    # The cluster's own credentials are left untouched.
+    with use_cluster("prod-eu"):
+        assert proxy.api_client.configuration.api_key == {
+            "authorization": "Bearer remote-admin"
+        }
@@ -0,0 +1,140 @@
+from __future__ import annotations
+
+import asyncio
+
+import pytest
+
+from app.core import k8s_credentials
+from app.core.k8s_credentials import (
+    ClusterCredentialError,
+    caller_credentials_active,
+    init_credential_passthrough,
+    resolve_cluster_token,
+    use_cluster_token,
+    validate_cluster_credentials,
+    wrap_with_credentials,
+)
+from app.schemas.alert import Alert
+from app.schemas.analysis import AlertAnalysisRequest, ClusterCredentials
+
+
+class _FakeConfiguration:
+    def __init__(self) -> None:
+        self.api_key = {"authorization": "Bearer agent-sa-token"}
+        self.api_key_prefix: dict[str, str] = {}
+        self.refresh_api_key_hook = object()
+        self.cert_file = self.key_file = self.username = self.password = None
+
+    @classmethod
+    def get_default_copy(cls) -> _FakeConfiguration:
+        return cls()
+
+
+class _FakeApiClient:
+    def __init__(self, configuration: _FakeConfiguration | None = None) -> None:
+        self.configuration = configuration
+        self.closed = False
+
+    def close(self) -> None:
+        self.closed = True
+
+
+class _FakeCoreApi:
+    def __init__(self, api_client: _FakeApiClient | None = None) -> None:
+        self.api_client = api_client or _FakeApiClient()
+
+    def read_namespaced_pod(self, name: str, namespace: str) -> object:
+        """read_namespaced_pod -> V1Pod"""
+        return self.api_client
+
+
+@pytest.fixture(autouse=True)
+def _reset_passthrough(monkeypatch: pytest.MonkeyPatch):
+    monkeypatch.setattr(k8s_credentials.client, "Configuration", _FakeConfiguration)
+    monkeypatch.setattr(k8s_credentials.client, "ApiClient", _FakeApiClient)
+    yield
+    init_credential_passthrough(False)
+
+
+def test_credentials_are_rejected_unless_passthrough_accepts_them() -> None:
+    token = ClusterCredentials(token="caller-token")
+
+    validate_cluster_credentials(None)
+    with pytest.raises(ClusterCredentialError, match="K8S_CREDENTIAL_PASSTHROUGH"):
+        validate_cluster_credentials(token)
+
+    init_credential_passthrough(True, vault_prefix="secret/data/teams")
+    validate_cluster_credentials(token)
+    validate_cluster_credentials(ClusterCredentials(token_ref="vault:secret/data/teams/shop#t"))
+    with pytest.raises(ClusterCredentialError, match="exactly one"):
+        validate_cluster_credentials(ClusterCredentials())
+    with pytest.raises(ClusterCredentialError, match="exactly one"):
+        validate_cluster_credentials(ClusterCredentials(token="a", token_ref="vault:x#y"))
+    with pytest.raises(ClusterCredentialError, match="K8S_CREDENTIAL_VAULT_PREFIX"):
+        validate_cluster_credentials(
+            ClusterCredentials(token_ref="vault:secret/data/teams-admin#token")
+        )
+    with pytest.raises(ClusterCredentialError, match="vault:<path>#<key>"):
+        validate_cluster_credentials(ClusterCredentials(token_ref="/var/run/token"))
+
+
+def test_resolve_cluster_token_reads_vault_references(monkeypatch: pytest.MonkeyPatch) -> None:
+    reads: list[tuple[str, str]] = []
+
+    def fake_read(name: str, reference: str) -> str:
+        reads.append((name, reference))
+        return "vault-token\n"
+
+    monkeypatch.setattr(k8s_credentials, "read_vault_secret", fake_read)
+    init_credential_passthrough(True, vault_prefix="/secret/data/teams/")
+
+    assert resolve_cluster_token(None) is None
+    assert resolve_cluster_token(ClusterCredentials(token=" caller-token ")) == "caller-token"
+    assert (
+        resolve_cluster_token(ClusterCredentials(token_ref="vault:secret/data/teams/shop#token"))
+        == "vault-token"
+    )
+    assert reads == [("cluster_credentials.token_ref", "secret/data/teams/shop#token")]
+
+
+def test_wrapped_api_uses_the_caller_token_only_inside_the_context() -> None:
+    init_credential_passthrough(True)
+    api = _FakeCoreApi()
+    proxy = wrap_with_credentials(api)
+
+    assert proxy.read_namespaced_pod("api-0", "shop") is api.api_client
+    with use_cluster_token("caller-token"):
+        caller_client = proxy.read_namespaced_pod("api-0", "shop")
+        threaded_client = asyncio.run(
+            asyncio.to_thread(proxy.read_namespaced_pod, "api-0", "shop")
+        )
+        assert proxy.api_client is caller_client
+        assert caller_credentials_active()
+    configuration = caller_client.configuration
+
+    assert caller_client is not api.api_client
+    assert threaded_client is caller_client
+    assert configuration.api_key == {"authorization": "caller-token"}
+    assert configuration.api_key_prefix == {"authorization": "Bearer"}
+    assert configuration.refresh_api_key_hook is None
+    assert caller_client.closed
+    assert not caller_credentials_active()
+    assert proxy.api_client is api.api_client
+    assert proxy.read_namespaced_pod.__doc__ == "read_namespaced_pod -> V1Pod"
+
+
+def test_disabled_passthrough_leaves_the_api_unwrapped() -> None:
+    api = _FakeCoreApi()
+
+    assert wrap_with_credentials(api) is api
+
+
+def test_request_credentials_are_never_serialized() -> None:
+    request = AlertAnalysisRequest(
+        alert=Alert(status="firing", labels={"alertname": "KubePodCrashLooping"}),
+        thread_ts="1",
+        cluster_credentials=ClusterCredentials(token="caller-token"),
+    )
+
+    assert "cluster_credentials" not in request.model_dump(mode="json")
+    assert "caller-token" not in repr(request)
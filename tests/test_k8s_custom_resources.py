@@ -0,0 +1,1990 @@
+from __future__ import annotations
+
+import base64
+import gzip
+import json
+import logging
+from datetime import datetime, timedelta, timezone
+from types import SimpleNamespace
+
+import pytest
+
+import app.clients.k8s as k8s_module
+from app.clients.k8s import KubernetesClient, _kind_to_plural
+from app.core.deadline import DeadlineExceeded, use_deadline
+
+
+class _FakeCustomApi:
+    def __init__(
+        self,
+        objects: dict[tuple[str, str | None, str], dict[str, object]],
+        lists: dict[tuple[str, str | None], object] | None = None,
+    ) -> None:
+        # Objects are keyed by (plural, namespace or None, name), lists by (plural, namespace).
+        self._objects = objects
+        self._lists = lists or {}
+        self.calls: list[dict[str, object]] = []
+
+    def list_namespaced_custom_object(self, **kwargs: object) -> object:
+        self.calls.append(kwargs)
+        return self._list(str(kwargs["plural"]), str(kwargs["namespace"]))
+
+    def list_cluster_custom_object(self, **kwargs: object) -> object:
+        self.calls.append(kwargs)
+        return self._list(str(kwargs["plural"]), None)
+
+    def _list(self, plural: str, namespace: str | None) -> object:
+        response = self._lists.get((plural, namespace), {"items": []})
+        if isinstance(response, Exception):
+            raise response
+        return response
+
+    def get_namespaced_custom_object(self, **kwargs: object) -> object:
+        self.calls.append(kwargs)
+        return self._lookup(str(kwargs["plural"]), str(kwargs["namespace"]), str(kwargs["name"]))
+
+    def get_cluster_custom_object(self, **kwargs: object) -> object:
+        self.calls.append(kwargs)
+        return self._lookup(str(kwargs["plural"]), None, str(kwargs["name"]))
+
+    def _lookup(self, plural: str, namespace: str | None, name: str) -> object:
+        try:
+            return self._objects[(plural, namespace, name)]
+        except KeyError as exc:
+            raise RuntimeError(f"{plural} {namespace}/{name} not found") from exc
+
+
+class _FakeCoreApi:
+    def __init__(self, pods: dict[str, list[str]], logs: dict[str, str]) -> None:
+        # Pods are keyed by label selector; logs by pod name.
+        self._pods = pods
+        self._logs = logs
+        self.log_calls: list[dict[str, object]] = []
+        self.nodes: list[SimpleNamespace] = []
+        self.events: list[SimpleNamespace] = []
+        self.event_calls: list[dict[str, object]] = []
+        self.secrets: list[SimpleNamespace] = []
+        self.secret_calls: list[dict[str, object]] = []
+
+    def list_node(self, **kwargs: object) -> object:
+        return SimpleNamespace(items=self.nodes)
+
+    def read_node(self, **kwargs: object) -> object:
+        for node in self.nodes:
+            if node.metadata.name == kwargs["name"]:
+                return node
+        raise RuntimeError(f"node {kwargs['name']} not found")
+
+    def list_event_for_all_namespaces(self, **kwargs: object) -> object:
+        self.event_calls.append(kwargs)
+        return SimpleNamespace(items=self.events)
+
+    def list_namespaced_event(self, **kwargs: object) -> object:
+        self.event_calls.append(kwargs)
+        return SimpleNamespace(items=self.events)
+
+    def list_namespaced_secret(self, **kwargs: object) -> object:
+        self.secret_calls.append(kwargs)
+        return SimpleNamespace(items=self.secrets)
+
+    def list_namespaced_pod(self, **kwargs: object) -> object:
+        names = self._pods.get(str(kwargs.get("label_selector")), [])
+        return SimpleNamespace(
+            items=[
+                SimpleNamespace(
+                    metadata=SimpleNamespace(name=name, namespace=kwargs["namespace"], labels={}),
+                    status=SimpleNamespace(phase="Running", container_statuses=[], start_time=None),
+                    spec=SimpleNamespace(node_name="node-1"),
+                )
+                for name in names
+            ]
+        )
+
+    def read_namespaced_pod_log(self, **kwargs: object) -> str:
+        self.log_calls.append(kwargs)
+        return self._logs[str(kwargs["name"])]
+
+
+def _build_k8s_client(
+    custom_api: _FakeCustomApi, core_api: _FakeCoreApi | None = None
+) -> KubernetesClient:
+    client = KubernetesClient.__new__(KubernetesClient)
+    client._logger = logging.getLogger(__name__)
+    client._timeout_seconds = 5
+    client._event_limit = 25
+    client._log_tail_lines = 25
+    client._log_since_seconds = 0
+    client._helm_release_scan = True
+    client._core_api = core_api
+    client._apps_api = None
+    client._batch_api = None
+    client._custom_api = custom_api
+    client._events_api = None
+    client._version_api = None
+    client._authorization_api = None
+    client._storage_api = None
+    client._autoscaling_api = None
+    client._networking_api = None
+    client._discovery_api = None
+    return client
+
+
+def _condition(type_: str, status: str, reason: str, message: str = "") -> dict[str, object]:
+    return {"type": type_, "status": status, "reason": reason, "message": message}
+
+
+def test_kind_to_plural_handles_common_suffixes() -> None:
+    assert _kind_to_plural("Bucket") == "buckets"
+    assert _kind_to_plural("RDSInstance") == "rdsinstances"
+    assert _kind_to_plural("Policy") == "policies"
+    assert _kind_to_plural("Gateway") == "gateways"
+    assert _kind_to_plural("Address") == "addresses"
+
+
+def test_crossplane_tree_follows_claim_to_managed_resources() -> None:
+    custom_api = _FakeCustomApi(
+        {
+            ("postgresqlinstances", "shop", "orders-db"): {
+                "apiVersion": "database.example.org/v1alpha1",
+                "kind": "PostgreSQLInstance",
+                "metadata": {"name": "orders-db", "namespace": "shop"},
+                "spec": {
+                    "resourceRef": {
+                        "apiVersion": "database.example.org/v1alpha1",
+                        "kind": "XPostgreSQLInstance",
+                        "name": "orders-db-x7k2p",
+                    }
+                },
+                "status": {"conditions": [_condition("Ready", "False", "Creating")]},
+            },
+            ("xpostgresqlinstances", None, "orders-db-x7k2p"): {
+                "apiVersion": "database.example.org/v1alpha1",
+                "kind": "XPostgreSQLInstance",
+                "metadata": {"name": "orders-db-x7k2p"},
+                "spec": {
+                    "resourceRefs": [
+                        {
+                            "apiVersion": "rds.aws.upbound.io/v1beta1",
+                            "kind": "Instance",
+                            "name": "orders-db-x7k2p-rds",
+                        },
+                        {
+                            "apiVersion": "ec2.aws.upbound.io/v1beta1",
+                            "kind": "SecurityGroup",
+                            "name": "orders-db-x7k2p-sg",
+                        },
+                    ]
+                },
+                "status": {"conditions": [_condition("Synced", "True", "ReconcileSuccess")]},
+            },
+            ("instances", None, "orders-db-x7k2p-rds"): {
+                "apiVersion": "rds.aws.upbound.io/v1beta1",
+                "kind": "Instance",
+                "metadata": {"name": "orders-db-x7k2p-rds"},
+                "status": {
+                    "conditions": [
+                        _condition("Ready", "False", "Unavailable"),
+                        _condition(
+                            "Synced",
+                            "False",
+                            "ReconcileError",
+                            "InvalidParameterCombination: db.t2.micro not supported",
+                        ),
+                    ]
+                },
+            },
+        }
+    )
+
+    tree = _build_k8s_client(custom_api).get_crossplane_resource_tree(
+        api_version="database.example.org/v1alpha1",
+        resource="postgresqlinstances",
+        name="orders-db",
+        namespace="shop",
+    )
+
+    assert tree is not None
+    assert tree["root"]["kind"] == "PostgreSQLInstance"
+    assert tree["composite"]["name"] == "orders-db-x7k2p"
+    assert [item["name"] for item in tree["managed_resources"]] == ["orders-db-x7k2p-rds"]
+    assert tree["warnings"] == ["SecurityGroup orders-db-x7k2p-sg could not be read"]
+    unhealthy = {item["name"]: item for item in tree["unhealthy"]}
+    assert set(unhealthy) == {"orders-db", "orders-db-x7k2p-rds"}
+    messages = [c["message"] for c in unhealthy["orders-db-x7k2p-rds"]["failing_conditions"]]
+    assert "InvalidParameterCombination: db.t2.micro not supported" in messages
+
+
+def test_crossplane_tree_reads_v2_namespaced_composite() -> None:
+    custom_api = _FakeCustomApi(
+        {
+            ("xbuckets", "media", "uploads"): {
+                "apiVersion": "storage.example.org/v1",
+                "kind": "XBucket",
+                "metadata": {"name": "uploads", "namespace": "media"},
+                "spec": {
+                    "crossplane": {
+                        "resourceRefs": [
+                            {
+                                "apiVersion": "s3.aws.m.upbound.io/v1beta1",
+                                "kind": "Bucket",
+                                "name": "uploads-abc12",
+                            }
+                        ]
+                    }
+                },
+                "status": {"conditions": [_condition("Ready", "True", "Available")]},
+            },
+            ("buckets", "media", "uploads-abc12"): {
+                "apiVersion": "s3.aws.m.upbound.io/v1beta1",
+                "kind": "Bucket",
+                "metadata": {"name": "uploads-abc12", "namespace": "media"},
+                "status": {"conditions": [_condition("Ready", "True", "Available")]},
+            },
+        }
+    )
+
+    tree = _build_k8s_client(custom_api).get_crossplane_resource_tree(
+        api_version="storage.example.org/v1",
+        resource="xbuckets",
+        name="uploads",
+        namespace="media",
+    )
+
+    assert tree is not None
+    assert tree["composite"] is None
+    assert [item["namespace"] for item in tree["managed_resources"]] == ["media"]
+    assert tree["unhealthy"] == []
+    assert "warnings" not in tree
+
+
+def test_crossplane_tree_returns_none_for_core_api_version() -> None:
+    client = _build_k8s_client(_FakeCustomApi({}))
+
+    assert client.get_crossplane_resource_tree("v1", "pods", "web", namespace="shop") is None
+
+
+def _scaled_object(
+    name: str, target: str, *, conditions: list[dict[str, object]], **status: object
+) -> dict[str, object]:
+    return {
+        "apiVersion": "keda.sh/v1alpha1",
+        "kind": "ScaledObject",
+        "metadata": {"name": name, "namespace": "shop"},
+        "spec": {
+            "scaleTargetRef": {"name": target},
+            "minReplicaCount": 1,
+            "maxReplicaCount": 20,
+            "triggers": [
+                {
+                    "type": "kafka",
+                    "metadata": {"topic": "orders", "lagThreshold": "50"},
+                    "authenticationRef": {"name": "kafka-auth"},
+                }
+            ],
+        },
+        "status": {"conditions": conditions, **status},
+    }
+
+
+def test_keda_scaling_status_reports_failing_trigger_and_missing_auth() -> None:
+    custom_api = _FakeCustomApi(
+        {},
+        lists={
+            ("scaledobjects", "shop"): {
+                "items": [
+                    _scaled_object(
+                        "orders-consumer",
+                        "orders-consumer",
+                        conditions=[
+                            _condition("Ready", "False", "ScaledObjectCheckFailed", "auth error"),
+                            _condition("Active", "False", "ScalerNotActive"),
+                        ],
+                        externalMetricNames=["s0-kafka-orders"],
+                        health={"s0-kafka-orders": {"numberOfFailures": 7, "status": "Failing"}},
+                        hpaName="keda-hpa-orders-consumer",
+                    ),
+                    _scaled_object("payments", "payments", conditions=[]),
+                ]
+            },
+            ("s0-kafka-orders", "shop"): RuntimeError("SASL authentication failed"),
+        },
+    )
+
+    statuses = _build_k8s_client(custom_api).get_keda_scaling_status(
+        "shop", workload="orders-consumer"
+    )
+
+    assert len(statuses) == 1
+    status = statuses[0]
+    assert status["hpa_name"] == "keda-hpa-orders-consumer"
+    assert status["triggers"][0]["authentication_ref"] == {
+        "kind": "TriggerAuthentication",
+        "name": "kafka-auth",
+        "found": False,
+    }
+    assert status["external_metrics"] == [
+        {"metric": "s0-kafka-orders", "error": "SASL authentication failed"}
+    ]
+    findings = status["findings"]
+    assert "ScaledObject not ready: ScaledObjectCheckFailed auth error" in findings
+    assert "no trigger is active; workload is held at minReplicaCount" in findings
+    assert "trigger s0-kafka-orders failing (7 consecutive failures)" in findings
+    assert "TriggerAuthentication kafka-auth referenced by trigger kafka was not found" in findings
+    metric_call = next(call for call in custom_api.calls if call["plural"] == "s0-kafka-orders")
+    assert metric_call["label_selector"] == "scaledobject.keda.sh/name=orders-consumer"
+
+
+def test_keda_scaling_status_reads_external_metric_values() -> None:
+    custom_api = _FakeCustomApi(
+        {
+            ("triggerauthentications", "shop", "kafka-auth"): {
+                "kind": "TriggerAuthentication",
+                "metadata": {"name": "kafka-auth"},
+            }
+        },
+        lists={
+            ("scaledobjects", "shop"): {
+                "items": [
+                    _scaled_object(
+                        "orders-consumer",
+                        "orders-consumer",
+                        conditions=[
+                            _condition("Ready", "True", "ScaledObjectReady"),
+                            _condition("Active", "True", "ScalerActive"),
+                        ],
+                        externalMetricNames=["s0-kafka-orders"],
+                    )
+                ]
+            },
+            ("s0-kafka-orders", "shop"): {
+                "items": [{"metricName": "s0-kafka-orders", "value": "1250"}]
+            },
+        },
+    )
+
+    statuses = _build_k8s_client(custom_api).get_keda_scaling_status("shop")
+
+    assert statuses[0]["findings"] == []
+    assert statuses[0]["triggers"][0]["authentication_ref"]["found"] is True
+    assert statuses[0]["external_metrics"] == [{"metric": "s0-kafka-orders", "values": ["1250"]}]
+
+
+def test_knative_service_status_reports_unready_revision_and_cold_start() -> None:
+    custom_api = _FakeCustomApi(
+        {
+            ("services", "web", "checkout"): {
+                "apiVersion": "serving.knative.dev/v1",
+                "kind": "Service",
+                "metadata": {"name": "checkout", "namespace": "web"},
+                "status": {
+                    "conditions": [
+                        _condition("Ready", "False", "RevisionMissing"),
+                        _condition("RoutesReady", "True", "Ready"),
+                    ],
+                    "latestCreatedRevisionName": "checkout-00004",
+                    "latestReadyRevisionName": "checkout-00003",
+                    "traffic": [{"revisionName": "checkout-00003", "percent": 100}],
+                },
+            },
+            ("serverlessservices", "web", "checkout-00003"): {"spec": {"mode": "Proxy"}},
+        },
+        lists={
+            ("revisions", "web"): {
+                "items": [
+                    {
+                        "metadata": {"name": "checkout-00004", "namespace": "web"},
+                        "status": {
+                            "conditions": [
+                                _condition(
+                                    "ContainerHealthy",
+                                    "False",
+                                    "ExitCode1",
+                                    "Container failed with: panic: missing DB_URL",
+                                )
+                            ],
+                            "actualReplicas": 1,
+                        },
+                    },
+                    {
+                        "metadata": {"name": "checkout-00003", "namespace": "web"},
+                        "spec": {"containerConcurrency": 10},
+                        "status": {
+                            "conditions": [_condition("Active", "False", "NoTraffic")],
+                            "actualReplicas": 0,
+                        },
+                    },
+                ]
+            }
+        },
+    )
+
+    status = _build_k8s_client(custom_api).get_knative_service_status("web", "checkout")
+
+    assert status is not None
+    assert [revision["name"] for revision in status["revisions"]] == [
+        "checkout-00004",
+        "checkout-00003",
+    ]
+    assert status["revisions"][1]["sks_mode"] == "Proxy"
+    assert status["revisions"][1]["container_concurrency"] == 10
+    findings = status["findings"]
+    assert "Service condition Ready=False: RevisionMissing" in findings
+    assert (
+        "latest revision checkout-00004 is not ready; traffic still served by checkout-00003"
+        in findings
+    )
+    assert (
+        "revision checkout-00004 ContainerHealthy=False: ExitCode1 "
+        "Container failed with: panic: missing DB_URL" in findings
+    )
+    assert (
+        "revision checkout-00003 is scaled to zero; the first request waits for a cold start "
+        "(set autoscaling.knative.dev/min-scale to keep pods warm)" in findings
+    )
+    assert "revision checkout-00003 traffic is routed through the activator (queueing)" in findings
+    revision_call = next(call for call in custom_api.calls if call["plural"] == "revisions")
+    assert revision_call["label_selector"] == "serving.knative.dev/service=checkout"
+
+
+def test_knative_service_status_returns_none_when_service_missing() -> None:
+    assert _build_k8s_client(_FakeCustomApi({})).get_knative_service_status("web", "x") is None
+
+
+def test_passed_deadline_is_raised_instead_of_logged_as_a_failed_read() -> None:
+    custom_api = _FakeCustomApi({})
+
+    with use_deadline(0.0), pytest.raises(DeadlineExceeded):
+        _build_k8s_client(custom_api).get_knative_service_status("web", "checkout")
+
+    assert custom_api.calls == []
+
+
+def test_velero_status_lists_recent_failures_with_volumes_and_logs() -> None:
+    def backup(name: str, phase: str, started: str) -> dict[str, object]:
+        return {
+            "metadata": {"name": name, "namespace": "velero"},
+            "spec": {"storageLocation": "default", "includedNamespaces": ["shop"]},
+            "status": {"phase": phase, "startTimestamp": started, "errors": 2},
+        }
+
+    custom_api = _FakeCustomApi(
+        {},
+        lists={
+            ("backups", "velero"): {
+                "items": [
+                    backup("daily-0101", "PartiallyFailed", "2026-01-01T01:00:00Z"),
+                    backup("daily-0102", "Completed", "2026-01-02T01:00:00Z"),
+                    backup("daily-0103", "Failed", "2026-01-03T01:00:00Z"),
+                ]
+            },
+            ("podvolumebackups", "velero"): {
+                "items": [
+                    {
+                        "kind": "PodVolumeBackup",
+                        "metadata": {"name": "daily-0103-x1"},
+                        "spec": {"pod": {"namespace": "shop", "name": "db-0"}, "volume": "data"},
+                        "status": {"phase": "Failed", "message": "error to initialize data path"},
+                    },
+                    {
+                        "kind": "PodVolumeBackup",
+                        "metadata": {"name": "daily-0103-x2"},
+                        "spec": {"pod": {"namespace": "shop", "name": "web-0"}, "volume": "tmp"},
+                        "status": {"phase": "Completed"},
+                    },
+                ]
+            },
+        },
+    )
+    core_api = _FakeCoreApi(
+        pods={"component=velero": ["velero-6d9f"]},
+        logs={
+            "velero-6d9f": "\n".join(
+                [
+                    'level=info msg="Backup starting" backup=velero/daily-0103',
+                    'level=error msg="Error uploading" backup=velero/daily-0103 '
+                    'error="AccessDenied" cmd=/plugins/velero-plugin-for-aws',
+                    'level=error msg="unrelated" backup=velero/other',
+                ]
+            )
+        },
+    )
+
+    result = _build_k8s_client(custom_api, core_api).get_velero_operation_status()
+
+    assert [op["name"] for op in result["operations"]] == ["daily-0103", "daily-0101"]
+    latest = result["operations"][0]
+    assert latest["storage_location"] == "default"
+    # The fake ignores selectors, so both backups see the same volume list.
+    assert latest["failed_volumes"] == [
+        {
+            "kind": "PodVolumeBackup",
+            "name": "daily-0103-x1",
+            "pod": "shop/db-0",
+            "volume": "data",
+            "phase": "Failed",
+            "message": "error to initialize data path",
+        }
+    ]
+    assert len(result["server_error_logs"]) == 1
+    assert "AccessDenied" in result["server_error_logs"][0]
+    volume_call = next(call for call in custom_api.calls if call["plural"] == "podvolumebackups")
+    assert volume_call["label_selector"] == "velero.io/backup-name=daily-0103"
+    assert core_api.log_calls[0]["container"] == "velero"
+
+
+def test_velero_status_rejects_unknown_operation() -> None:
+    result = _build_k8s_client(_FakeCustomApi({})).get_velero_operation_status(
+        operation="schedule"
+    )
+
+    assert "error" in result
+
+
+def test_external_dns_status_classifies_provider_errors(monkeypatch: pytest.MonkeyPatch) -> None:
+    core_api = _FakeCoreApi(
+        pods={"app.kubernetes.io/name=external-dns": ["external-dns-7c9b"]},
+        logs={
+            "external-dns-7c9b": "\n".join(
+                [
+                    'level=info msg="All records are already up to date"',
+                    'level=error msg="Throttling: Rate exceeded status code: 400"',
+                    'level=error msg="AccessDenied: not authorized to perform: '
+                    'route53:ChangeResourceRecordSets"',
+                    'level=error msg="failed to create record api.shop.example.com"',
+                    'level=error msg="failed to create record other.example.com"',
+                ]
+            )
+        },
+    )
+    monkeypatch.setattr(
+        k8s_module.socket,
+        "getaddrinfo",
+        lambda host, port: [(2, 1, 6, "", ("203.0.113.10", 0))],
+    )
+
+    result = _build_k8s_client(_FakeCustomApi({}), core_api).get_external_dns_status(
+        "api.shop.example.com"
+    )
+
+    assert result["error_counts"] == {
+        "rate_limited": 1,
+        "permission_denied": 1,
+        "other_errors": 1,
+    }
+    assert "api.shop.example.com" in result["errors"]["other_errors"][0]
+    assert result["record"] == {
+        "hostname": "api.shop.example.com",
+        "resolved": True,
+        "addresses": ["203.0.113.10"],
+    }
+
+
+def test_external_dns_status_reports_unresolved_record(monkeypatch: pytest.MonkeyPatch) -> None:
+    def fail(host, port):  # type: ignore[no-untyped-def]
+        raise OSError("Name or service not known")
+
+    monkeypatch.setattr(k8s_module.socket, "getaddrinfo", fail)
+
+    client = _build_k8s_client(_FakeCustomApi({}), _FakeCoreApi({}, {}))
+    result = client.get_external_dns_status("missing.example.com")
+
+    assert result["warning"] == "external-dns pod or logs not found"
+    assert result["record"]["resolved"] is False
+
+
+def _node(
+    name: str, kubelet_version: str, *, created: datetime, unschedulable: bool = False
+) -> SimpleNamespace:
+    return SimpleNamespace(
+        metadata=SimpleNamespace(name=name, creation_timestamp=created),
+        spec=SimpleNamespace(
+            unschedulable=unschedulable,
+            taints=[SimpleNamespace(key="node.kubernetes.io/unschedulable")]
+            if unschedulable
+            else [],
+        ),
+        status=SimpleNamespace(node_info=SimpleNamespace(kubelet_version=kubelet_version)),
+    )
+
+
+def _node_event(reason: str, node: str, when: datetime) -> SimpleNamespace:
+    return SimpleNamespace(
+        type="Normal",
+        reason=reason,
+        message=f"Node {node} status is now: {reason}",
+        count=1,
+        first_timestamp=when,
+        last_timestamp=when,
+        event_time=None,
+        involved_object=SimpleNamespace(kind="Node", name=node, namespace=None, uid=None),
+    )
+
+
+def test_cluster_upgrade_status_detects_rolling_node_upgrade() -> None:
+    now = datetime.now(timezone.utc)
+    old = now - timedelta(days=30)
+    core_api = _FakeCoreApi({}, {})
+    core_api.nodes = [
+        _node("node-a", "v1.29.6", created=old, unschedulable=True),
+        _node("node-b", "v1.29.6", created=old),
+        _node("node-c", "v1.30.2", created=now - timedelta(minutes=20)),
+        _node("node-legacy", "v1.25.9", created=old),
+    ]
+    core_api.events = [
+        _node_event("NodeNotSchedulable", "node-a", now - timedelta(minutes=10)),
+        _node_event("RegisteredNode", "node-c", now - timedelta(minutes=20)),
+        _node_event("NodeNotSchedulable", "node-x", now - timedelta(days=2)),
+        _node_event("ImageGCFailed", "node-b", now - timedelta(minutes=5)),
+    ]
+    client = _build_k8s_client(_FakeCustomApi({}), core_api)
+    client._version_api = SimpleNamespace(
+        get_code=lambda **kwargs: SimpleNamespace(git_version="v1.30.2")
+    )
+
+    status = client.get_cluster_upgrade_status(lookback_minutes=60)
+
+    assert status["control_plane_version"] == "v1.30.2"
+    assert status["kubelet_versions"] == {"v1.29.6": 2, "v1.30.2": 1, "v1.25.9": 1}
+    assert status["upgrade_in_progress"] is True
+    assert [node["node"] for node in status["cordoned_nodes"]] == ["node-a"]
+    assert [node["node"] for node in status["recently_created_nodes"]] == ["node-c"]
+    assert status["version_skew"] == [
+        {"node": "node-legacy", "kubelet_version": "v1.25.9", "minor_skew": 5}
+    ]
+    assert [event["reason"] for event in status["node_events"]] == [
+        "NodeNotSchedulable",
+        "RegisteredNode",
+    ]
+    assert "1 node(s) cordoned: drain in progress" in status["findings"]
+    assert core_api.event_calls[0]["field_selector"] == "involvedObject.kind=Node"
+
+
+def _maintenance_node(
+    name: str,
+    *,
+    unschedulable: bool = False,
+    taints: tuple[str, ...] = (),
+    conditions: tuple[tuple[str, str], ...] = (),
+    annotations: dict[str, str] | None = None,
+) -> SimpleNamespace:
+    return SimpleNamespace(
+        metadata=SimpleNamespace(name=name, annotations=annotations or {}),
+        spec=SimpleNamespace(
+            unschedulable=unschedulable, taints=[SimpleNamespace(key=key) for key in taints]
+        ),
+        status=SimpleNamespace(
+            conditions=[
+                SimpleNamespace(type=type_, status=status, message="Freeze event at 03:00")
+                for type_, status in conditions
+            ]
+        ),
+    )
+
+
+def test_node_maintenance_status_reports_drain_and_cloud_signals() -> None:
+    core_api = _FakeCoreApi({}, {})
+    core_api.nodes = [
+        _maintenance_node(
+            "node-a",
+            unschedulable=True,
+            taints=("karpenter.sh/disrupted", "example.com/dedicated"),
+            conditions=(("VMEventScheduled", "True"), ("Ready", "True")),
+        ),
+        _maintenance_node("node-b", conditions=(("VMEventScheduled", "False"),)),
+    ]
+    client = _build_k8s_client(_FakeCustomApi({}), core_api)
+
+    status = client.get_node_maintenance_status("node-a")
+
+    assert status is not None
+    assert status["in_maintenance"] is True
+    assert status["cordoned"] is True
+    assert status["signals"] == [
+        "node is cordoned (unschedulable)",
+        "taint karpenter.sh/disrupted: Karpenter disruption (consolidation, drift or expiry)",
+        "condition VMEventScheduled: cloud maintenance scheduled (Freeze event at 03:00)",
+    ]
+    healthy = client.get_node_maintenance_status("node-b")
+    assert healthy is not None
+    assert healthy["in_maintenance"] is False
+    assert client.get_node_maintenance_status("node-missing") is None
+
+
+def test_node_maintenance_status_detects_cluster_api_machine_deletion() -> None:
+    core_api = _FakeCoreApi({}, {})
+    core_api.nodes = [
+        _maintenance_node(
+            "node-a",
+            annotations={
+                "cluster.x-k8s.io/machine": "md-0-abc",
+                "cluster.x-k8s.io/cluster-namespace": "capi-clusters",
+            },
+        )
+    ]
+    custom_api = _FakeCustomApi(
+        {
+            ("machines", "capi-clusters", "md-0-abc"): {
+                "metadata": {"name": "md-0-abc", "deletionTimestamp": "2026-10-14T03:00:00Z"},
+                "status": {"phase": "Deleting"},
+            }
+        }
+    )
+    client = _build_k8s_client(custom_api, core_api)
+
+    status = client.get_node_maintenance_status("node-a")
+
+    assert status is not None
+    assert status["in_maintenance"] is True
+    assert status["machine"] == {
+        "name": "md-0-abc",
+        "namespace": "capi-clusters",
+        "phase": "Deleting",
+        "deletion_timestamp": "2026-10-14T03:00:00Z",
+    }
+    assert status["signals"] == ["Cluster API machine md-0-abc is being deleted"]
+    assert custom_api.calls[0]["group"] == "cluster.x-k8s.io"
+
+
+def test_pod_images_report_pulled_digests_and_build_annotations() -> None:
+    pod = SimpleNamespace(
+        metadata=SimpleNamespace(
+            annotations={
+                "org.opencontainers.image.revision": "3f2c1ab",
+                "kubectl.kubernetes.io/restartedAt": "2026-10-14T01:00:00Z",
+            },
+            labels={"app.kubernetes.io/version": "1.4.2"},
+        ),
+        spec=SimpleNamespace(
+            init_containers=[SimpleNamespace(name="migrate", image="ghcr.io/acme/api:1.4.2")],
+            containers=[SimpleNamespace(name="api", image="ghcr.io/acme/api:1.4.2")],
+        ),
+        status=SimpleNamespace(
+            init_container_statuses=[],
+            container_statuses=[
+                SimpleNamespace(name="api", image_id="ghcr.io/acme/api@sha256:abc")
+            ],
+        ),
+    )
+    core_api = SimpleNamespace(read_namespaced_pod=lambda **kwargs: pod)
+    client = _build_k8s_client(_FakeCustomApi({}), core_api)  # type: ignore[arg-type]
+
+    images = client.get_pod_images("shop", "api-0")
+
+    assert images == {
+        "namespace": "shop",
+        "pod": "api-0",
+        "containers": [
+            {"name": "migrate", "init": True, "image": "ghcr.io/acme/api:1.4.2", "image_id": None},
+            {
+                "name": "api",
+                "init": False,
+                "image": "ghcr.io/acme/api:1.4.2",
+                "image_id": "ghcr.io/acme/api@sha256:abc",
+            },
+        ],
+        "build": {
+            "org.opencontainers.image.revision": "3f2c1ab",
+            "app.kubernetes.io/version": "1.4.2",
+        },
+    }
+
+
+def test_collect_context_limits_current_logs_to_since_window(
+    monkeypatch: pytest.MonkeyPatch,
+) -> None:
+    core_api = _FakeCoreApi({}, {"api-0": "2026-10-14T01:00:00Z boom"})
+    client = _build_k8s_client(_FakeCustomApi({}), core_api)
+    client._log_since_seconds = 600
+    pod = SimpleNamespace(
+        metadata=SimpleNamespace(name="api-0"),
+        spec=SimpleNamespace(containers=[SimpleNamespace(name="api")]),
+    )
+    monkeypatch.setattr(client, "_read_pod", lambda namespace, name, warnings: pod)
+    monkeypatch.setattr(client, "_extract_pod_status", lambda pod: None)
+    monkeypatch.setattr(client, "_list_pod_events", lambda namespace, name, warnings: [])
+    monkeypatch.setattr(client, "_get_previous_logs", lambda namespace, pod, warnings: [])
+    monkeypatch.setattr(client, "_summarize_pod_spec", lambda pod: {})
+
+    context = client.collect_context("shop", "api-0")
+
+    assert [snippet.logs for snippet in context.current_logs] == [["2026-10-14T01:00:00Z boom"]]
+    assert core_api.log_calls[0]["since_seconds"] == 600
+
+
+class _RotatedLogsCoreApi(_FakeCoreApi):
+    def read_namespaced_pod_log(self, **kwargs: object) -> str:
+        self.log_calls.append(kwargs)
+        if kwargs["previous"] and kwargs["container"] == "api":
+            error = RuntimeError('previous terminated container "api" in pod "api-0" not found')
+            error.status = 400  # type: ignore[attr-defined]
+            raise error
+        if kwargs["previous"]:
+            return "2026-10-14T00:59:00Z worker exited"
+        return f"2026-10-14T01:00:00Z {kwargs['container']} started"
+
+
+def test_previous_logs_fall_back_to_the_current_stream_when_rotated() -> None:
+    core_api = _RotatedLogsCoreApi({}, {})
+    client = _build_k8s_client(_FakeCustomApi({}), core_api)
+    pod = SimpleNamespace(
+        metadata=SimpleNamespace(name="api-0"),
+        spec=SimpleNamespace(
+            containers=[
+                SimpleNamespace(name="api"),
+                SimpleNamespace(name="worker"),
+                SimpleNamespace(name="proxy"),
+            ]
+        ),
+        status=SimpleNamespace(
+            container_statuses=[
+                SimpleNamespace(name="api", restart_count=4),
+                SimpleNamespace(name="worker", restart_count=1),
+                SimpleNamespace(name="proxy", restart_count=0),
+            ]
+        ),
+    )
+    warnings: list[str] = []
+
+    snippets = client._get_previous_logs("shop", pod, warnings)
+
+    assert [snippet.to_dict() for snippet in snippets] == [
+        {
+            "container": "api",
+            "previous": False,
+            "logs": ["2026-10-14T01:00:00Z api started"],
+            "error": None,
+            "note": "previous container logs empty or rotated by the kubelet",
+            "stream": "current",
+        },
+        {
+            "container": "worker",
+            "previous": True,
+            "logs": ["2026-10-14T00:59:00Z worker exited"],
+            "error": None,
+            "note": None,
+            "stream": "previous",
+        },
+    ]
+    # Containers that never restarted have no previous instance to read.
+    assert [(call["container"], call["previous"]) for call in core_api.log_calls] == [
+        ("api", True),
+        ("api", False),
+        ("worker", True),
+    ]
+    assert warnings == [
+        "previous logs of container api are empty or rotated; using the current container logs"
+    ]
+    assert core_api.log_calls[0]["tail_lines"] == 25
+
+
+def _core_event(type_: str, reason: str, last_timestamp: datetime | None) -> SimpleNamespace:
+    return SimpleNamespace(
+        type=type_,
+        reason=reason,
+        message=reason,
+        count=1,
+        first_timestamp=None,
+        last_timestamp=last_timestamp,
+        event_time=None,
+        involved_object=None,
+    )
+
+
+def test_collect_context_without_pod_attaches_warning_first_namespace_events() -> None:
+    core_api = _FakeCoreApi({}, {})
+    core_api.events = [
+        _core_event("Normal", "Pulled", datetime(2026, 10, 14, 1, 5, tzinfo=timezone.utc)),
+        _core_event("Warning", "BackOff", datetime(2026, 10, 14, 1, 0, tzinfo=timezone.utc)),
+        _core_event(
+            "Warning", "FailedScheduling", datetime(2026, 10, 14, 1, 3, tzinfo=timezone.utc)
+        ),
+    ]
+    client = _build_k8s_client(_FakeCustomApi({}), core_api)
+
+    context = client.collect_context("shop", None, workload="api")
+
+    assert [event.reason for event in context.events] == ["FailedScheduling", "BackOff", "Pulled"]
+    assert core_api.event_calls[0]["namespace"] == "shop"
+    assert context.warnings[0].startswith("pod_name missing from alert labels.")
+
+
+def _replica_set(name: str, revision: str, image: str, owner: str = "api") -> SimpleNamespace:
+    return SimpleNamespace(
+        metadata=SimpleNamespace(
+            name=name,
+            annotations={"deployment.kubernetes.io/revision": revision},
+            owner_references=[SimpleNamespace(kind="Deployment", name=owner)],
+            creation_timestamp=None,
+        ),
+        spec=SimpleNamespace(
+            template=SimpleNamespace(
+                metadata=SimpleNamespace(
+                    annotations={"org.opencontainers.image.revision": f"sha-{revision}"}
+                ),
+                spec=SimpleNamespace(containers=[SimpleNamespace(name="api", image=image)]),
+            )
+        ),
+    )
+
+
+def test_deployment_revisions_are_sorted_newest_first() -> None:
+    replica_sets = [
+        _replica_set("api-1", "1", "api:1.0"),
+        _replica_set("api-10", "10", "api:1.9"),
+        _replica_set("api-9", "9", "api:1.8"),
+        _replica_set("worker-11", "11", "worker:2.0", owner="worker"),
+    ]
+    client = _build_k8s_client(_FakeCustomApi({}))
+    client._apps_api = SimpleNamespace(
+        list_namespaced_replica_set=lambda **kwargs: SimpleNamespace(items=replica_sets)
+    )
+
+    revisions = client.get_deployment_revisions("shop", "api")
+
+    assert revisions is not None
+    assert [item["replica_set"] for item in revisions] == ["api-10", "api-9"]
+    assert revisions[0]["containers"] == [{"name": "api", "image": "api:1.9"}]
+    assert revisions[0]["build"] == {"org.opencontainers.image.revision": "sha-10"}
+
+
+def test_pod_deployment_is_resolved_through_the_replica_set() -> None:
+    pod = SimpleNamespace(
+        metadata=SimpleNamespace(
+            owner_references=[
+                SimpleNamespace(kind="ReplicaSet", name="api-7d9f8c", controller=True)
+            ]
+        )
+    )
+    core_api = SimpleNamespace(read_namespaced_pod=lambda **kwargs: pod)
+    client = _build_k8s_client(_FakeCustomApi({}), core_api)  # type: ignore[arg-type]
+    client._apps_api = SimpleNamespace(
+        read_namespaced_replica_set=lambda **kwargs: SimpleNamespace(
+            metadata=SimpleNamespace(
+                owner_references=[SimpleNamespace(kind="Deployment", name="api", controller=True)]
+            )
+        )
+    )
+
+    assert client.get_pod_deployment("shop", "api-7d9f8c-x2v9q") == "api"
+
+
+def _owner(kind: str, name: str, api_version: str) -> SimpleNamespace:
+    return SimpleNamespace(
+        kind=kind, name=name, api_version=api_version, uid=f"uid-{name}", controller=True
+    )
+
+
+def test_owner_chain_follows_the_replica_set_to_an_argo_rollout(
+    monkeypatch: pytest.MonkeyPatch,
+) -> None:
+    pod = SimpleNamespace(
+        metadata=SimpleNamespace(
+            name="api-6c9f7d-x2v9q",
+            uid="uid-pod",
+            owner_references=[_owner("ReplicaSet", "api-6c9f7d", "apps/v1")],
+        ),
+        spec=SimpleNamespace(containers=[]),
+    )
+    custom_api = _FakeCustomApi(
+        {("rollouts", "shop", "api"): {"kind": "Rollout", "metadata": {"name": "api"}}}
+    )
+    client = _build_k8s_client(custom_api, _FakeCoreApi({}, {}))
+    client._apps_api = SimpleNamespace(
+        read_namespaced_replica_set=lambda **kwargs: SimpleNamespace(
+            metadata=SimpleNamespace(
+                owner_references=[_owner("Rollout", "api", "argoproj.io/v1alpha1")]
+            )
+        )
+    )
+    monkeypatch.setattr(client, "_read_pod", lambda namespace, name, warnings: pod)
+    monkeypatch.setattr(client, "_extract_pod_status", lambda pod: None)
+    monkeypatch.setattr(client, "_list_pod_events", lambda namespace, name, warnings: [])
+    monkeypatch.setattr(client, "_get_previous_logs", lambda namespace, pod, warnings: [])
+    monkeypatch.setattr(client, "_summarize_pod_spec", lambda pod: {})
+
+    context = client.collect_context("shop", "api-6c9f7d-x2v9q")
+
+    assert context.workload == "api"
+    assert context.target is not None and context.target.workload is None
+    assert [(link["kind"], link["name"]) for link in context.owner_chain] == [
+        ("Pod", "api-6c9f7d-x2v9q"),
+        ("ReplicaSet", "api-6c9f7d"),
+        ("Rollout", "api"),
+    ]
+    assert context.owner_chain[-1] == {
+        "kind": "Rollout",
+        "name": "api",
+        "api_version": "argoproj.io/v1alpha1",
+        "uid": "uid-api",
+        "controller": True,
+    }
+    assert custom_api.calls[0]["group"] == "argoproj.io"
+    assert context.warnings == []
+
+
+def test_owner_chain_stops_with_a_warning_at_an_unreadable_owner() -> None:
+    pod = SimpleNamespace(
+        metadata=SimpleNamespace(
+            name="nightly-29301-abcde",
+            owner_references=[_owner("Job", "nightly-29301", "batch/v1")],
+        )
+    )
+
+    def read_job(**kwargs: object) -> object:
+        raise RuntimeError("forbidden")
+
+    client = _build_k8s_client(_FakeCustomApi({}))
+    client._batch_api = SimpleNamespace(read_namespaced_job=read_job)
+    warnings: list[str] = []
+
+    chain = client._owner_chain("batch", pod, warnings)  # type: ignore[arg-type]
+
+    assert [link["kind"] for link in chain] == ["Pod", "Job"]
+    assert warnings == ["failed to read job batch/nightly-29301"]
+    static = SimpleNamespace(
+        metadata=SimpleNamespace(name="etcd-cp-1", owner_references=[_owner("Node", "cp-1", "v1")])
+    )
+    assert [link["kind"] for link in client._owner_chain("kube-system", static, [])] == [
+        "Pod",
+        "Node",
+    ]
+
+
+def _helm_release_secret(name: str, manifest: str) -> SimpleNamespace:
+    release = {
+        "name": name,
+        "version": 7,
+        "chart": {"metadata": {"name": "legacy-app", "version": "1.2.0"}},
+        "manifest": manifest,
+    }
+    helm_encoded = base64.b64encode(gzip.compress(json.dumps(release).encode("utf-8")))
+    return SimpleNamespace(data={"release": base64.b64encode(helm_encoded).decode("ascii")})
+
+
+def test_find_removed_api_usage_flags_helm_release_manifests() -> None:
+    core_api = _FakeCoreApi({}, {})
+    core_api.secrets = [
+        _helm_release_secret(
+            "legacy",
+            "\n".join(
+                [
+                    "---",
+                    "# Source: legacy-app/templates/cronjob.yaml",
+                    "apiVersion: batch/v1beta1",
+                    "kind: CronJob",
+                    "metadata:",
+                    "  name: cleanup",
+                    "---",
+                    "apiVersion: apps/v1",
+                    "kind: Deployment",
+                    "---",
+                    "apiVersion: flowcontrol.apiserver.k8s.io/v1beta3",
+                    "kind: FlowSchema",
+                ]
+            ),
+        )
+    ]
+    core_api.events = [
+        _node_event("ReconcileError", "legacy", datetime.now(timezone.utc)),
+        SimpleNamespace(
+            type="Warning",
+            reason="UpgradeFailed",
+            message='no matches for kind "CronJob" in version "batch/v1beta1"',
+            count=1,
+            first_timestamp=None,
+            last_timestamp=None,
+            event_time=None,
+            involved_object=None,
+        ),
+    ]
+    client = _build_k8s_client(_FakeCustomApi({}), core_api)
+    client._version_api = SimpleNamespace(
+        get_code=lambda **kwargs: SimpleNamespace(git_version="v1.29.4")
+    )
+
+    result = client.find_removed_api_usage("jobs")
+
+    assert result["cluster_version"] == "v1.29.4"
+    assert result["removed_in_use"] is True
+    assert result["helm_releases"] == [
+        {
+            "release": "legacy",
+            "revision": 7,
+            "chart": "legacy-app-1.2.0",
+            "apis": [
+                {
+                    "api_version": "batch/v1beta1",
+                    "kind": "CronJob",
+                    "status": "removed",
+                    "removed_in": "1.25",
+                    "replacement": "batch/v1",
+                },
+                {
+                    "api_version": "flowcontrol.apiserver.k8s.io/v1beta3",
+                    "kind": "FlowSchema",
+                    "status": "deprecated",
+                    "removed_in": "1.32",
+                    "replacement": "flowcontrol.apiserver.k8s.io/v1",
+                },
+            ],
+        }
+    ]
+    assert [event["reason"] for event in result["api_error_events"]] == ["UpgradeFailed"]
+    assert core_api.secret_calls[0]["label_selector"] == "owner=helm,status=deployed"
+
+
+def test_find_removed_api_usage_lists_no_secrets_without_helm_release_scan() -> None:
+    core_api = _FakeCoreApi({}, {})
+    core_api.secrets = [_helm_release_secret("legacy", "apiVersion: batch/v1beta1\nkind: CronJob")]
+    client = _build_k8s_client(_FakeCustomApi({}), core_api)
+    client._helm_release_scan = False
+    client._version_api = SimpleNamespace(
+        get_code=lambda **kwargs: SimpleNamespace(git_version="v1.29.4")
+    )
+
+    result = client.find_removed_api_usage("jobs")
+
+    assert result["helm_releases"] is None
+    assert result["removed_in_use"] is False
+    assert core_api.secret_calls == []
+
+
+def test_certificates_and_quota_usage_for_health_scans() -> None:
+    custom_api = _FakeCustomApi(
+        {},
+        {
+            ("certificates", "shop"): {
+                "items": [
+                    {
+                        "metadata": {"name": "shop-tls"},
+                        "spec": {"secretName": "shop-tls"},
+                        "status": {
+                            "notAfter": "2026-03-01T00:00:00Z",
+                            "conditions": [_condition("Ready", "False", "Expired")],
+                        },
+                    }
+                ]
+            }
+        },
+    )
+    core_api = _FakeCoreApi({}, {})
+    quota = SimpleNamespace(
+        metadata=SimpleNamespace(name="compute"),
+        status=SimpleNamespace(
+            hard={"limits.memory": "4Gi", "requests.cpu": "2"},
+            used={"limits.memory": "3584Mi", "requests.cpu": "500m"},
+        ),
+    )
+    core_api.list_namespaced_resource_quota = (  # type: ignore[attr-defined]
+        lambda **kwargs: SimpleNamespace(items=[quota])
+    )
+    client = _build_k8s_client(custom_api, core_api)
+
+    certificates = client.list_certificates("shop")
+    usage = client.list_resource_quota_usage("shop")
+
+    assert certificates == [
+        {
+            "name": "shop-tls",
+            "secret_name": "shop-tls",
+            "not_after": "2026-03-01T00:00:00Z",
+            "renewal_time": None,
+            "ready": "False",
+            "reason": "Expired",
+            "message": "",
+        }
+    ]
+    assert [(item["resource"], item["ratio"]) for item in usage] == [
+        ("limits.memory", 0.875),
+        ("requests.cpu", 0.25),
+    ]
+
+
+def test_get_namespace_quotas_reads_quota_usage_and_limit_ranges() -> None:
+    core_api = _FakeCoreApi({}, {})
+    quota = SimpleNamespace(
+        metadata=SimpleNamespace(name="compute"),
+        status=SimpleNamespace(hard={"pods": "20"}, used={"pods": "20"}),
+    )
+    limit_range = SimpleNamespace(
+        metadata=SimpleNamespace(name="defaults"),
+        spec=SimpleNamespace(
+            limits=[
+                SimpleNamespace(
+                    type="Container",
+                    max={"memory": "1Gi"},
+                    min=None,
+                    default={"memory": "512Mi"},
+                    default_request={"memory": "256Mi"},
+                    max_limit_request_ratio={"cpu": "4"},
+                )
+            ]
+        ),
+    )
+    core_api.list_namespaced_resource_quota = (  # type: ignore[attr-defined]
+        lambda **kwargs: SimpleNamespace(items=[quota])
+    )
+    core_api.list_namespaced_limit_range = (  # type: ignore[attr-defined]
+        lambda **kwargs: SimpleNamespace(items=[limit_range])
+    )
+    client = _build_k8s_client(_FakeCustomApi({}), core_api)
+
+    quotas = client.get_namespace_quotas("shop")
+
+    assert quotas == {
+        "namespace": "shop",
+        "quotas": [
+            {"quota": "compute", "resource": "pods", "used": "20", "hard": "20", "ratio": 1.0}
+        ],
+        "limit_ranges": [
+            {
+                "name": "defaults",
+                "limits": [
+                    {
+                        "type": "Container",
+                        "max": {"memory": "1Gi"},
+                        "min": None,
+                        "default": {"memory": "512Mi"},
+                        "default_request": {"memory": "256Mi"},
+                        "max_limit_request_ratio": {"cpu": "4"},
+                    }
+                ],
+            }
+        ],
+    }
+
+
+class _FakeAuthorizationApi:
+    def __init__(self, denied: set[str]) -> None:
+        self._denied = denied
+        self.reviews: list[object] = []
+
+    def create_self_subject_access_review(self, **kwargs: object) -> object:
+        body = kwargs["body"]
+        self.reviews.append(body)
+        attributes = body.spec.resource_attributes  # type: ignore[attr-defined]
+        resource = attributes.resource
+        if attributes.subresource:
+            resource = f"{resource}/{attributes.subresource}"
+        allowed = resource not in self._denied
+        return SimpleNamespace(
+            status=SimpleNamespace(allowed=allowed, reason=None if allowed else "no RBAC rule")
+        )
+
+
+def test_check_permissions_reviews_agent_access() -> None:
+    client = _build_k8s_client(_FakeCustomApi({}))
+    authorization_api = _FakeAuthorizationApi({"pods/log", "nodes"})
+    client._authorization_api = authorization_api
+
+    results = client.check_permissions("payments")
+
+    by_resource = {check["resource"]: check for check in results}
+    assert by_resource["pods"]["allowed"] is True
+    assert by_resource["pods"]["namespace"] == "payments"
+    assert by_resource["pods/log"] == {
+        "collector": "analysis",
+        "verb": "get",
+        "resource": "pods/log",
+        "group": "core",
+        "namespace": "payments",
+        "optional": False,
+        "allowed": False,
+        "reason": "no RBAC rule",
+    }
+    assert by_resource["nodes"]["namespace"] is None
+    assert by_resource["nodes"]["allowed"] is False
+    assert by_resource["deployments"]["group"] == "apps"
+
+
+def test_volume_claim_status_reads_volume_storage_class_and_attachments() -> None:
+    core_api = _FakeCoreApi({}, {})
+    core_api.events = [
+        SimpleNamespace(
+            type="Warning",
+            reason="ProvisioningFailed",
+            message="VolumeLimitExceeded",
+            count=2,
+            first_timestamp=None,
+            last_timestamp=None,
+            event_time=None,
+            involved_object=None,
+        )
+    ]
+    core_api.read_namespaced_persistent_volume_claim = lambda **kwargs: SimpleNamespace(
+        metadata=SimpleNamespace(
+            annotations={"volume.kubernetes.io/selected-node": "node-1"}
+        ),
+        spec=SimpleNamespace(
+            storage_class_name="gp3",
+            access_modes=["ReadWriteOnce"],
+            volume_mode="Filesystem",
+            volume_name="pvc-1234",
+            resources=SimpleNamespace(requests={"storage": "20Gi"}),
+        ),
+        status=SimpleNamespace(phase="Bound", capacity={"storage": "20Gi"}, conditions=[]),
+    )
+    core_api.read_persistent_volume = lambda **kwargs: SimpleNamespace(
+        spec=SimpleNamespace(
+            capacity={"storage": "20Gi"},
+            persistent_volume_reclaim_policy="Delete",
+            csi=SimpleNamespace(driver="ebs.csi.aws.com", volume_handle="vol-0abc"),
+        ),
+        status=SimpleNamespace(phase="Bound", reason=None, message=None),
+    )
+    stats = {
+        "pods": [
+            {
+                "volume": [
+                    {"name": "tmp", "usedBytes": 10},
+                    {
+                        "name": "data",
+                        "pvcRef": {"name": "data-postgres-0", "namespace": "db"},
+                        "usedBytes": 19,
+                        "capacityBytes": 20,
+                        "availableBytes": 1,
+                        "inodesUsed": 5,
+                        "inodes": 100,
+                    },
+                ]
+            }
+        ]
+    }
+    core_api.connect_get_node_proxy_with_path = lambda **kwargs: SimpleNamespace(
+        data=json.dumps(stats).encode()
+    )
+
+    def attachment(name: str, volume: str, error: str | None) -> SimpleNamespace:
+        return SimpleNamespace(
+            metadata=SimpleNamespace(name=name),
+            spec=SimpleNamespace(
+                source=SimpleNamespace(persistent_volume_name=volume),
+                node_name="node-1",
+                attacher="ebs.csi.aws.com",
+            ),
+            status=SimpleNamespace(
+                attached=error is None,
+                attach_error=SimpleNamespace(message=error) if error else None,
+                detach_error=None,
+            ),
+        )
+
+    client = _build_k8s_client(_FakeCustomApi({}), core_api)
+    client._storage_api = SimpleNamespace(
+        read_storage_class=lambda **kwargs: SimpleNamespace(
+            provisioner="ebs.csi.aws.com",
+            volume_binding_mode="WaitForFirstConsumer",
+            allow_volume_expansion=True,
+            reclaim_policy="Delete",
+        ),
+        list_volume_attachment=lambda **kwargs: SimpleNamespace(
+            items=[
+                attachment("csi-1", "pvc-1234", "volume in use"),
+                attachment("csi-2", "pvc-other", None),
+            ]
+        ),
+    )
+
+    claim = client.get_volume_claim_status("db", "data-postgres-0")
+
+    assert claim is not None
+    assert claim["phase"] == "Bound"
+    assert claim["requested"] == "20Gi"
+    assert claim["selected_node"] == "node-1"
+    assert claim["persistent_volume"]["csi_driver"] == "ebs.csi.aws.com"  # type: ignore[index]
+    assert claim["storage_class_detail"]["allow_volume_expansion"] is True  # type: ignore[index]
+    assert claim["attachments"] == [
+        {
+            "name": "csi-1",
+            "node": "node-1",
+            "attacher": "ebs.csi.aws.com",
+            "attached": False,
+            "attach_error": "volume in use",
+            "detach_error": None,
+        }
+    ]
+    events = claim["events"]
+    assert isinstance(events, list) and events[0]["reason"] == "ProvisioningFailed"
+    assert core_api.event_calls[0]["field_selector"] == (
+        "involvedObject.kind=PersistentVolumeClaim,involvedObject.name=data-postgres-0"
+    )
+    assert client.get_volume_stats("node-1") == [
+        {
+            "namespace": "db",
+            "claim": "data-postgres-0",
+            "node": "node-1",
+            "used_bytes": 19,
+            "capacity_bytes": 20,
+            "available_bytes": 1,
+            "inodes_used": 5,
+            "inodes": 100,
+        }
+    ]
+
+
+def test_hpa_status_matches_the_pod_owner_and_reads_hpa_events() -> None:
+    core_api = _FakeCoreApi({}, {})
+    core_api.read_namespaced_pod = lambda **kwargs: SimpleNamespace(
+        metadata=SimpleNamespace(
+            owner_references=[SimpleNamespace(kind="StatefulSet", name="db", controller=True)]
+        )
+    )
+    core_api.events = [
+        SimpleNamespace(
+            type="Normal",
+            reason="SuccessfulRescale",
+            message="New size: 5; reason: cpu resource utilization above target",
+            count=1,
+            first_timestamp=None,
+            last_timestamp=None,
+            event_time=None,
+            involved_object=None,
+        )
+    ]
+
+    def autoscaler(name: str, target: str) -> SimpleNamespace:
+        metric = SimpleNamespace(to_dict=lambda: {"type": "Resource", "resource": {"name": "cpu"}})
+        return SimpleNamespace(
+            metadata=SimpleNamespace(name=name),
+            spec=SimpleNamespace(
+                scale_target_ref=SimpleNamespace(kind="StatefulSet", name=target),
+                min_replicas=1,
+                max_replicas=5,
+                metrics=[metric],
+            ),
+            status=SimpleNamespace(
+                current_replicas=5,
+                desired_replicas=5,
+                last_scale_time=datetime(2026, 10, 14, 1, 50, tzinfo=timezone.utc),
+                current_metrics=[],
+                conditions=[
+                    SimpleNamespace(
+                        type="ScalingLimited",
+                        status="True",
+                        reason="TooManyReplicas",
+                        message="desired more than maximum",
+                    )
+                ],
+            ),
+        )
+
+    client = _build_k8s_client(_FakeCustomApi({}), core_api)
+    client._autoscaling_api = SimpleNamespace(
+        list_namespaced_horizontal_pod_autoscaler=lambda **kwargs: SimpleNamespace(
+            items=[autoscaler("web", "web"), autoscaler("db", "db")]
+        )
+    )
+
+    status = client.get_hpa_status("shop", pod_name="db-0")
+
+    assert status is not None
+    assert status["name"] == "db"
+    assert status["target"] == {"kind": "StatefulSet", "name": "db"}
+    assert status["max_replicas"] == 5
+    assert status["last_scale_time"] == "2026-10-14T01:50:00+00:00"
+    assert status["metrics"] == [{"type": "Resource", "resource": {"name": "cpu"}}]
+    assert status["conditions"][0]["reason"] == "TooManyReplicas"  # type: ignore[index]
+    assert core_api.event_calls[0]["field_selector"] == (
+        "involvedObject.kind=HorizontalPodAutoscaler,involvedObject.name=db"
+    )
+    assert client.get_hpa_status("shop", workload="checkout") is None
+
+
+def test_node_resource_usage_adds_allocatable_to_metrics_server_usage() -> None:
+    core_api = _FakeCoreApi({}, {})
+    core_api.nodes = [
+        SimpleNamespace(
+            metadata=SimpleNamespace(name="node-1"),
+            status=SimpleNamespace(allocatable={"cpu": "3920m", "memory": "15Gi"}),
+        )
+    ]
+    custom_api = _FakeCustomApi(
+        {
+            ("nodes", None, "node-1"): {
+                "metadata": {"name": "node-1"},
+                "timestamp": "2026-10-14T02:00:00Z",
+                "window": "20s",
+                "usage": {"cpu": "3700000000n", "memory": "9Gi"},
+            }
+        }
+    )
+    client = _build_k8s_client(custom_api, core_api)
+
+    usage = client.get_node_resource_usage("node-1")
+
+    assert usage == {
+        "name": "node-1",
+        "timestamp": "2026-10-14T02:00:00Z",
+        "window": "20s",
+        "usage": {"cpu": "3700000000n", "memory": "9Gi"},
+        "allocatable": {"cpu": "3920m", "memory": "15Gi"},
+    }
+    assert custom_api.calls[0]["group"] == "metrics.k8s.io"
+    assert client.get_node_resource_usage("node-2") is None
+
+
+def test_network_connectivity_summarizes_policies_of_both_namespaces() -> None:
+    core_api = _FakeCoreApi({}, {})
+    core_api.read_namespaced_pod = lambda **kwargs: SimpleNamespace(
+        metadata=SimpleNamespace(labels={"app": "checkout"})
+    )
+    core_api.read_namespace = lambda **kwargs: SimpleNamespace(
+        metadata=SimpleNamespace(labels={"team": kwargs["name"]})
+    )
+    core_api.read_namespaced_service = lambda **kwargs: SimpleNamespace(
+        spec=SimpleNamespace(
+            selector={"app": "payments"},
+            ports=[SimpleNamespace(name="http", port=80, target_port="http", protocol=None)],
+        )
+    )
+    deny_all = SimpleNamespace(
+        metadata=SimpleNamespace(name="default-deny"),
+        spec=SimpleNamespace(
+            pod_selector=SimpleNamespace(match_labels=None, match_expressions=None),
+            policy_types=None,
+            ingress=None,
+            egress=[
+                SimpleNamespace(
+                    to=[
+                        SimpleNamespace(
+                            pod_selector=None,
+                            namespace_selector=SimpleNamespace(
+                                match_labels=None,
+                                match_expressions=[
+                                    SimpleNamespace(key="team", operator="In", values=["db"])
+                                ],
+                            ),
+                            ip_block=None,
+                        ),
+                        SimpleNamespace(
+                            pod_selector=None,
+                            namespace_selector=None,
+                            ip_block=SimpleNamespace(cidr="10.0.0.0/8", _except=["10.1.0.0/16"]),
+                        ),
+                    ],
+                    ports=[SimpleNamespace(protocol=None, port=5432, end_port=None)],
+                )
+            ],
+        ),
+    )
+    calls: list[str] = []
+
+    def list_namespaced_network_policy(**kwargs: object) -> SimpleNamespace:
+        calls.append(str(kwargs["namespace"]))
+        return SimpleNamespace(items=[deny_all] if kwargs["namespace"] == "shop" else [])
+
+    client = _build_k8s_client(_FakeCustomApi({}), core_api)
+    client._networking_api = SimpleNamespace(
+        list_namespaced_network_policy=list_namespaced_network_policy
+    )
+
+    connectivity = client.get_network_connectivity(
+        "shop", "checkout-0", destination_namespace="payments", destination_service="payments"
+    )
+
+    assert connectivity is not None
+    assert calls == ["shop", "payments"]
+    source = connectivity["source"]
+    assert source["labels"] == {"app": "checkout"}  # type: ignore[index]
+    assert source["namespace_labels"] == {  # type: ignore[index]
+        "kubernetes.io/metadata.name": "shop",
+        "team": "shop",
+    }
+    assert source["policies"] == [  # type: ignore[index]
+        {
+            "name": "default-deny",
+            "namespace": "shop",
+            "pod_selector": {"match_labels": {}, "match_expressions": []},
+            "policy_types": ["Ingress", "Egress"],
+            "ingress": [],
+            "egress": [
+                {
+                    "to": [
+                        {
+                            "pod_selector": None,
+                            "namespace_selector": {
+                                "match_labels": {},
+                                "match_expressions": [
+                                    {"key": "team", "operator": "In", "values": ["db"]}
+                                ],
+                            },
+                            "ip_block": None,
+                        },
+                        {
+                            "pod_selector": None,
+                            "namespace_selector": None,
+                            "ip_block": {"cidr": "10.0.0.0/8", "except": ["10.1.0.0/16"]},
+                        },
+                    ],
+                    "ports": [{"protocol": "TCP", "port": 5432, "end_port": None}],
+                }
+            ],
+        }
+    ]
+    assert connectivity["destination"] == {
+        "namespace": "payments",
+        "service": "payments",
+        "found": True,
+        "selector": {"app": "payments"},
+        "ports": [{"name": "http", "port": 80, "target_port": "http", "protocol": "TCP"}],
+        "namespace_labels": {"kubernetes.io/metadata.name": "payments", "team": "payments"},
+        "policies": [],
+    }
+
+
+def test_service_endpoints_reports_slices_pod_readiness_and_near_misses() -> None:
+    core_api = _FakeCoreApi({}, {})
+    core_api.read_namespaced_service = lambda **kwargs: SimpleNamespace(
+        spec=SimpleNamespace(
+            selector={"app": "payments"},
+            ports=[SimpleNamespace(name="http", port=80, target_port="http", protocol="TCP")],
+        )
+    )
+    probe = SimpleNamespace(
+        period_seconds=10,
+        timeout_seconds=1,
+        failure_threshold=3,
+        http_get=SimpleNamespace(path="/ready", port="http"),
+        tcp_socket=None,
+        grpc=None,
+        _exec=None,
+    )
+    pod = SimpleNamespace(
+        metadata=SimpleNamespace(
+            name="payments-a", labels={"app": "payments"}, deletion_timestamp=None
+        ),
+        spec=SimpleNamespace(
+            node_name="node-1",
+            containers=[
+                SimpleNamespace(
+                    name="app",
+                    ports=[SimpleNamespace(name="http", container_port=8080)],
+                    readiness_probe=probe,
+                )
+            ],
+        ),
+        status=SimpleNamespace(
+            phase="Running",
+            conditions=[
+                SimpleNamespace(type="Ready", status="False", message="containers not ready")
+            ],
+            container_statuses=[
+                SimpleNamespace(
+                    name="app",
+                    ready=False,
+                    restart_count=0,
+                    state=SimpleNamespace(
+                        waiting=None, terminated=None, running=SimpleNamespace(started_at=None)
+                    ),
+                )
+            ],
+        ),
+    )
+    selectors: list[str] = []
+
+    def list_namespaced_pod(**kwargs: object) -> SimpleNamespace:
+        selectors.append(str(kwargs["label_selector"]))
+        return SimpleNamespace(items=[pod])
+
+    core_api.list_namespaced_pod = list_namespaced_pod
+    core_api.events = [
+        SimpleNamespace(
+            type="Warning",
+            reason="Unhealthy",
+            message="Readiness probe failed: HTTP probe failed with statuscode: 503",
+            count=4,
+            first_timestamp=None,
+            last_timestamp=None,
+            event_time=None,
+            involved_object=None,
+        )
+    ]
+    slice_calls: list[dict[str, object]] = []
+
+    def list_namespaced_endpoint_slice(**kwargs: object) -> SimpleNamespace:
+        slice_calls.append(kwargs)
+        return SimpleNamespace(
+            items=[
+                SimpleNamespace(
+                    endpoints=[
+                        SimpleNamespace(
+                            addresses=["10.0.0.7"],
+                            conditions=SimpleNamespace(
+                                ready=False, serving=False, terminating=None
+                            ),
+                            target_ref=SimpleNamespace(kind="Pod", name="payments-a"),
+                            node_name="node-1",
+                            zone="eu-west-1a",
+                        )
+                    ]
+                )
+            ]
+        )
+
+    client = _build_k8s_client(_FakeCustomApi({}), core_api)
+    client._discovery_api = SimpleNamespace(
+        list_namespaced_endpoint_slice=list_namespaced_endpoint_slice
+    )
+
+    endpoints = client.get_service_endpoints("shop", "payments")
+
+    assert endpoints is not None
+    assert slice_calls[0]["label_selector"] == "kubernetes.io/service-name=payments"
+    assert selectors == ["app=payments"]
+    assert endpoints["endpoints"] == [
+        {
+            "addresses": ["10.0.0.7"],
+            "ready": False,
+            "serving": False,
+            "terminating": False,
+            "pod": "payments-a",
+            "node": "node-1",
+            "zone": "eu-west-1a",
+        }
+    ]
+    [summary] = endpoints["pods"]  # type: ignore[misc]
+    assert summary["ready"] is False
+    assert summary["containers"][0]["readiness_probe"] == {
+        "period_seconds": 10,
+        "timeout_seconds": 1,
+        "failure_threshold": 3,
+        "type": "httpGet",
+        "path": "/ready",
+        "port": "http",
+    }
+    assert summary["probe_failures"] == [
+        "Readiness probe failed: HTTP probe failed with statuscode: 503"
+    ]
+    assert endpoints["near_misses"] == []
+
+
+def test_get_cluster_dns_status_scans_coredns_logs_for_errors(
+    monkeypatch: pytest.MonkeyPatch,
+) -> None:
+    core_api = _FakeCoreApi(
+        {},
+        {
+            "coredns-a": "\n".join(
+                [
+                    "[INFO] 10.0.0.5:41234 - 1 \"A IN payments.shop.svc. udp 40\" NOERROR",
+                    "[INFO] 10.0.0.5:41235 - 2 \"A IN example.com. udp 30\" SERVFAIL",
+                    "[ERROR] plugin/errors: 2 example.com. A: read udp 10.0.0.9:53->1.1.1.1:53: "
+                    "i/o timeout",
+                ]
+            )
+        },
+    )
+
+    def failing_log(**kwargs: object) -> str:
+        if kwargs["name"] == "coredns-b":
+            raise RuntimeError("forbidden")
+        return _FakeCoreApi.read_namespaced_pod_log(core_api, **kwargs)
+
+    core_api.read_namespaced_pod_log = failing_log  # type: ignore[method-assign]
+    client = _build_k8s_client(_FakeCustomApi({}), core_api)
+    monkeypatch.setattr(
+        client,
+        "get_service_endpoints",
+        lambda namespace, service: {
+            "namespace": namespace,
+            "service": service,
+            "found": True,
+            "pods": [
+                {"name": "coredns-a", "containers": [{"name": "coredns"}]},
+                {"name": "coredns-b", "containers": [{"name": "coredns"}]},
+            ],
+        },
+    )
+
+    status = client.get_cluster_dns_status("kube-system", "kube-dns", since_seconds=900)
+
+    assert status is not None
+    assert core_api.log_calls[0]["container"] == "coredns"
+    assert core_api.log_calls[0]["since_seconds"] == 900
+    assert status["logs"] == [
+        {
+            "pod": "coredns-a",
+            "container": "coredns",
+            "lines": 3,
+            "servfail": 1,
+            "errors": 1,
+            "upstream_timeouts": 1,
+            "samples": [
+                "[INFO] 10.0.0.5:41235 - 2 \"A IN example.com. udp 30\" SERVFAIL",
+                "[ERROR] plugin/errors: 2 example.com. A: read udp 10.0.0.9:53->1.1.1.1:53: "
+                "i/o timeout",
+            ],
+        },
+        {"pod": "coredns-b", "container": "coredns", "error": "forbidden"},
+    ]
+
+
+
+def test_watch_cluster_events_yields_added_and_modified_events_by_uid(
+    monkeypatch: pytest.MonkeyPatch,
+) -> None:
+    def _event(uid: str | None, reason: str) -> SimpleNamespace:
+        return SimpleNamespace(
+            metadata=SimpleNamespace(uid=uid),
+            type="Warning",
+            reason=reason,
+            message=f"{reason} message",
+            count=2,
+            first_timestamp=datetime(2026, 10, 14, 9, 0, tzinfo=timezone.utc),
+            last_timestamp=None,
+            event_time=None,
+            involved_object=SimpleNamespace(
+                kind="Pod", name="checkout-7d9f8c-abcde", namespace="shop", uid="pod-uid"
+            ),
+        )
+
+    streams: list[dict[str, object]] = []
+
+    class _FakeWatch:
+        def stream(self, func: object, **kwargs: object) -> list[dict[str, object]]:
+            streams.append({"func": func, **kwargs})
+            return [
+                {"type": "ADDED", "object": _event("uid-1", "BackOff")},
+                {"type": "DELETED", "object": _event("uid-2", "Pulled")},
+                {"type": "MODIFIED", "object": _event("uid-1", "BackOff")},
+                {"type": "ADDED", "object": _event(None, "Scheduled")},
+            ]
+
+    monkeypatch.setattr(k8s_module.watch, "Watch", _FakeWatch)
+    core_api = _FakeCoreApi({}, {})
+    client = _build_k8s_client(_FakeCustomApi({}), core_api)
+
+    events = list(client.watch_cluster_events(60))
+
+    assert [uid for uid, _ in events] == ["uid-1", "uid-1"]
+    assert events[0][1].first_timestamp == "2026-10-14T09:00:00+00:00"
+    assert events[0][1].involved_object == {
+        "kind": "Pod",
+        "name": "checkout-7d9f8c-abcde",
+        "namespace": "shop",
+        "uid": "pod-uid",
+    }
+    assert streams[0]["timeout_seconds"] == 60
+    assert streams[0]["_request_timeout"] == 65
+
+
+def test_job_run_reads_the_latest_cron_job_run_with_failed_pod_logs() -> None:
+    def _job(name: str, created: int, reason: str | None) -> SimpleNamespace:
+        return SimpleNamespace(
+            metadata=SimpleNamespace(
+                name=name,
+                creation_timestamp=datetime(2026, 10, 14, created, 0, tzinfo=timezone.utc),
+                owner_references=[SimpleNamespace(kind="CronJob", name="backup")],
+            ),
+            spec=SimpleNamespace(
+                backoff_limit=1,
+                active_deadline_seconds=None,
+                completions=1,
+                parallelism=1,
+                pod_failure_policy=None,
+            ),
+            status=SimpleNamespace(
+                start_time=None,
+                completion_time=None,
+                active=None,
+                succeeded=None if reason else 1,
+                failed=2 if reason else None,
+                conditions=[
+                    SimpleNamespace(
+                        type="Failed" if reason else "Complete",
+                        status="True",
+                        reason=reason,
+                        message=None,
+                        last_transition_time=None,
+                    )
+                ],
+            ),
+        )
+
+    jobs = [_job("backup-1", 1, None), _job("backup-2", 2, "BackoffLimitExceeded")]
+    unrelated = _job("other-1", 3, None)
+    unrelated.metadata.owner_references = [SimpleNamespace(kind="CronJob", name="other")]
+    batch_calls: list[dict[str, object]] = []
+
+    def list_namespaced_job(**kwargs: object) -> SimpleNamespace:
+        batch_calls.append(kwargs)
+        return SimpleNamespace(items=[*jobs, unrelated])
+
+    terminated = SimpleNamespace(
+        waiting=None,
+        running=None,
+        terminated=SimpleNamespace(reason="Error", message=None, exit_code=2, finished_at=None),
+    )
+    pod = SimpleNamespace(
+        metadata=SimpleNamespace(name="backup-2-x7k2p", creation_timestamp=None),
+        spec=SimpleNamespace(node_name="node-1", containers=[SimpleNamespace(name="backup")]),
+        status=SimpleNamespace(
+            phase="Failed",
+            reason=None,
+            message=None,
+            start_time=None,
+            init_container_statuses=None,
+            container_statuses=[
+                SimpleNamespace(
+                    name="backup",
+                    image="ops/backup:1.8",
+                    restart_count=0,
+                    state=terminated,
+                    last_state=None,
+                )
+            ],
+        ),
+    )
+    selectors: list[str] = []
+
+    def list_namespaced_pod(**kwargs: object) -> SimpleNamespace:
+        selectors.append(str(kwargs["label_selector"]))
+        return SimpleNamespace(items=[pod])
+
+    core_api = _FakeCoreApi({}, {"backup-2-x7k2p": "dump failed\npg_dump: error 42"})
+    core_api.list_namespaced_pod = list_namespaced_pod
+    client = _build_k8s_client(_FakeCustomApi({}), core_api)
+    client._batch_api = SimpleNamespace(list_namespaced_job=list_namespaced_job)
+
+    run = client.get_job_run("ops", cron_job="backup")
+
+    assert run is not None
+    assert run["job"] == "backup-2"
+    assert selectors == ["job-name=backup-2"]
+    assert [item["name"] for item in run["recent_runs"]] == ["backup-2", "backup-1"]
+    assert run["spec"]["backoff_limit"] == 1  # type: ignore[index]
+    [summary] = run["pods"]  # type: ignore[misc]
+    assert summary["failed"] is True
+    assert summary["containers"][0]["state"]["exit_code"] == "2"
+    assert run["logs"] == [
+        {
+            "container": "backup",
+            "previous": False,
+            "logs": ["dump failed", "pg_dump: error 42"],
+            "error": None,
+            "note": None,
+            "stream": "current",
+        }
+    ]
+    assert core_api.event_calls[-1]["field_selector"] == (
+        "involvedObject.kind=Job,involvedObject.name=backup-2"
+    )
+
+
+def test_job_run_reports_removed_jobs_as_not_found() -> None:
+    class _NotFound(Exception):
+        status = 404
+
+    def read_namespaced_job(**kwargs: object) -> object:
+        raise _NotFound("jobs.batch not found")
+
+    client = _build_k8s_client(_FakeCustomApi({}), _FakeCoreApi({}, {}))
+    client._batch_api = SimpleNamespace(read_namespaced_job=read_namespaced_job)
+
+    assert client.get_job_run("ops", job="backup-1") == {
+        "namespace": "ops",
+        "job": "backup-1",
+        "cron_job": None,
+        "found": False,
+        "recent_runs": [],
+    }
@@ -0,0 +1,151 @@
+from __future__ import annotations
+
+import pytest
+
+from app.core.k8s_scope import (
+    AccessDenied,
+    AccessScope,
+    current_access_scope,
+    describe_call,
+    init_access_scope,
+    record_denied_permissions,
+    scope_permissions,
+    wrap_with_scope,
+)
+from app.core.rbac import Permission, render_rbac_manifest
+
+
+class _FakeCoreApi:
+    """list_namespaced_pod(namespace, ...), with the docstring kubernetes.watch reads."""
+
+    def __init__(self) -> None:
+        self.calls: list[str] = []
+
+    def list_namespaced_pod(self, **kwargs: object) -> str:
+        self.calls.append(f"pods {kwargs['namespace']}")
+        return "pods"
+
+    def list_node(self, **kwargs: object) -> str:
+        self.calls.append("nodes")
+        return "nodes"
+
+
+@pytest.fixture(autouse=True)
+def _reset_scope():
+    yield
+    init_access_scope()
+
+
+def test_describe_call_maps_client_methods_to_rbac_attributes() -> None:
+    assert describe_call("read_namespaced_pod_log", "", {"namespace": "shop"}) == (
+        "get",
+        "",
+        "pods/log",
+        "shop",
+    )
+    assert describe_call("list_pod_for_all_namespaces", "", {}) == ("list", "", "pods", "*")
+    assert describe_call("list_namespaced_event", "", {"namespace": "shop", "watch": True}) == (
+        "watch",
+        "",
+        "events",
+        "shop",
+    )
+    assert describe_call(
+        "list_namespaced_network_policy", "networking.k8s.io", {"namespace": "shop"}
+    ) == ("list", "networking.k8s.io", "networkpolicies", "shop")
+    assert describe_call(
+        "get_namespaced_custom_object",
+        None,
+        {"group": "metrics.k8s.io", "plural": "pods", "namespace": "shop"},
+    ) == ("get", "metrics.k8s.io", "pods", "shop")
+    assert describe_call("connect_get_node_proxy_with_path", "", {}) == (
+        "get",
+        "",
+        "nodes/proxy",
+        None,
+    )
+    assert describe_call("create_self_subject_access_review", "authorization.k8s.io", {}) is None
+
+
+def test_access_scope_denies_calls_outside_namespaces_groups_and_verbs() -> None:
+    scope = AccessScope(
+        namespaces=frozenset({"shop"}),
+        api_groups=frozenset({"core", "apps"}),
+        verbs=frozenset({"get", "list"}),
+        cluster_scoped=False,
+    )
+
+    assert scope.denial("list", "", "pods", "shop") is None
+    assert scope.denial("get", "apps", "deployments", "shop") is None
+    assert "K8S_ALLOWED_NAMESPACES_JSON" in str(scope.denial("list", "", "pods", "billing"))
+    assert "all namespaces" in str(scope.denial("list", "", "pods", "*"))
+    assert "K8S_CLUSTER_SCOPED_ACCESS" in str(scope.denial("get", "", "nodes", None))
+    assert "K8S_ALLOWED_API_GROUPS_JSON" in str(scope.denial("list", "batch", "jobs", "shop"))
+    assert "K8S_ALLOWED_VERBS_JSON" in str(scope.denial("watch", "", "events", "shop"))
+
+
+def test_wrapped_api_raises_access_denied_before_the_request() -> None:
+    init_access_scope(["shop"], cluster_scoped=False)
+    api = _FakeCoreApi()
+    proxy = wrap_with_scope(api, "")
+
+    assert proxy.list_namespaced_pod(namespace="shop") == "pods"
+    with pytest.raises(AccessDenied, match="list pods in billing"):
+        proxy.list_namespaced_pod(namespace="billing")
+    with pytest.raises(AccessDenied, match="list nodes cluster-wide"):
+        proxy.list_node()
+    assert api.calls == ["pods shop"]
+    assert proxy.list_namespaced_pod.__doc__ == api.list_namespaced_pod.__doc__
+
+
+def test_unrestricted_scope_leaves_the_api_unwrapped() -> None:
+    init_access_scope()
+    api = _FakeCoreApi()
+
+    assert wrap_with_scope(api, "") is api
+    assert current_access_scope() == AccessScope()
+
+
+def test_scope_permissions_review_only_allowed_namespaces_and_render_roles() -> None:
+    permissions = [
+        Permission("analysis", "list", "", "pods"),
+        Permission("analysis", "get", "", "nodes", cluster_scoped=True),
+        Permission("analysis", "list", "batch", "jobs"),
+        Permission("health_scan", "list", "", "resourcequotas", namespace="billing"),
+    ]
+    scope = AccessScope(
+        namespaces=frozenset({"shop", "web"}),
+        api_groups=frozenset({"core"}),
+        cluster_scoped=False,
+    )
+
+    scoped = scope_permissions(permissions, scope)
+    manifest = render_rbac_manifest(scoped, service_account="rca", namespace="ops")
+
+    assert scoped == [
+        Permission("analysis", "list", "", "pods", namespace="shop"),
+        Permission("analysis", "list", "", "pods", namespace="web"),
+    ]
+    assert "ClusterRole" not in manifest
+    assert manifest.count("\nkind: Role\n") == 2
+    assert "  namespace: web\n" in manifest
+
+
+def test_least_privilege_skips_calls_the_self_check_denied() -> None:
+    checks = [
+        {"verb": "list", "resource": "pods", "group": "core", "namespace": "*", "allowed": False},
+        {"verb": "get", "resource": "nodes", "group": "core", "namespace": None, "allowed": True},
+    ]
+    init_access_scope(verbs=["get", "list"])
+    assert record_denied_permissions(checks) == 0
+
+    init_access_scope(least_privilege=True)
+    api = _FakeCoreApi()
+    proxy = wrap_with_scope(api, "")
+    before = current_access_scope()
+
+    assert record_denied_permissions(checks) == 1
+    assert before.denied == frozenset()
+    assert "K8S_LEAST_PRIVILEGE" in str(current_access_scope().denial("list", "", "pods", "*"))
+    assert proxy.list_namespaced_pod(namespace="shop") == "pods"
+    assert current_access_scope().describe()["skipped_calls"] == ["list pods in all namespaces"]
@@ -0,0 +1,94 @@
+from __future__ import annotations
+
+from app.services.kafka_lag import KafkaLagAnalyzer
+
+
+class FakePrometheus:
+    def __init__(self, results: dict[str, list[tuple[dict[str, str], float]]]) -> None:
+        # Results are keyed by a fragment of the PromQL query; first match wins.
+        self._results = results
+        self.queries: list[str] = []
+
+    def query(self, query: str, *, time: str | None = None) -> dict[str, object]:
+        self.queries.append(query)
+        samples = next(
+            (value for fragment, value in self._results.items() if fragment in query), []
+        )
+        return {
+            "endpoint": {},
+            "data": {
+                "status": "success",
+                "data": {
+                    "resultType": "vector",
+                    "result": [
+                        {"metric": labels, "value": [0, str(value)]} for labels, value in samples
+                    ],
+                },
+            },
+        }
+
+
+def _partition(partition: str, topic: str = "orders.v1") -> dict[str, str]:
+    return {"consumergroup": "billing", "topic": topic, "partition": partition}
+
+
+def test_analyze_blames_consumers_slower_than_producers() -> None:
+    prometheus = FakePrometheus(
+        {
+            "delta(kafka_consumergroup_lag": [(_partition("0"), 4000), (_partition("1"), 3500)],
+            "kafka_consumergroup_lag{": [(_partition("0"), 12000), (_partition("1"), 9000)],
+            "kafka_consumergroup_current_offset": [({"topic": "orders.v1"}, 40.0)],
+            "kafka_topic_partition_current_offset": [({"topic": "orders.v1"}, 120.0)],
+            "kafka_consumergroup_members": [({}, 3)],
+            "kafka_brokers": [({}, 3)],
+        }
+    )
+
+    result = KafkaLagAnalyzer(prometheus).analyze("billing")
+
+    assert result["total_lag"] == 21000
+    assert [item["partition"] for item in result["lagging_partitions"]] == ["0", "1"]
+    assert result["lagging_partitions"][0]["growth"] == 4000.0
+    assert result["bottleneck"] == "consumers"
+    assert result["findings"] == [
+        "producers write 120.0 msg/s but the group consumes only 40.0 msg/s; lag is growing"
+    ]
+    assert 'kafka_consumergroup_lag{consumergroup="billing"}' in prometheus.queries
+    assert any('topic=~"orders\\\\.v1"' in query for query in prometheus.queries)
+
+
+def test_analyze_blames_brokers_for_under_replicated_partitions() -> None:
+    prometheus = FakePrometheus(
+        {
+            "kafka_consumergroup_lag": [(_partition("0"), 5000)],
+            "kafka_topic_partition_under_replicated_partition": [({"topic": "orders.v1"}, 2)],
+            "kafka_consumergroup_members": [({}, 0)],
+        }
+    )
+
+    result = KafkaLagAnalyzer(prometheus).analyze("billing", "orders.v1")
+
+    assert result["bottleneck"] == "brokers"
+    assert result["findings"] == ["under-replicated partitions: orders.v1=2"]
+
+
+def test_analyze_detects_partition_skew_and_missing_metrics() -> None:
+    skewed = FakePrometheus(
+        {
+            "kafka_consumergroup_lag": [
+                (_partition("0"), 50000),
+                *[(_partition(str(index)), 10) for index in range(1, 8)],
+            ],
+            "kafka_consumergroup_current_offset": [({"topic": "orders.v1"}, 200.0)],
+            "kafka_consumergroup_members": [({}, 4)],
+        }
+    )
+
+    result = KafkaLagAnalyzer(skewed).analyze("billing")
+
+    assert result["bottleneck"] == "partition_skew"
+    assert result["lagging_partition_count"] == 1
+    assert "orders.v1/0 with 50000" in result["findings"][0]
+
+    missing = KafkaLagAnalyzer(FakePrometheus({})).analyze("billing")
+    assert "no kafka_consumergroup_lag series" in missing["warning"]
@@ -0,0 +1,181 @@
+from __future__ import annotations
+
+from datetime import datetime, timezone
+
+import pytest
+
+import app.clients.kube_state_metrics as kube_state_metrics_module
+from app.clients.kube_state_metrics import KubeStateMetricsClient, parse_sample
+from app.core.config import load_settings
+from app.services.kube_state import KubeStateCollector, PrometheusStateSource
+
+
+def _sample(name: str, value: float, **labels: str) -> dict[str, object]:
+    return {"metric": {"__name__": name, **labels}, "value": [1792058400, str(value)]}
+
+
+class FakePrometheus:
+    def __init__(self, result: list[dict[str, object]]) -> None:
+        self.result = result
+        self.queries: list[tuple[str, str | None]] = []
+
+    def query(self, query: str, *, time: str | None = None) -> dict[str, object]:
+        self.queries.append((query, time))
+        return {"data": {"status": "success", "data": {"result": self.result}}}
+
+
+def test_prometheus_snapshot_summarizes_pod_and_deployment_state() -> None:
+    pod = {"namespace": "shop", "pod": "checkout-7d9f-abc12"}
+    deployment = {"namespace": "shop", "deployment": "checkout"}
+    prometheus = FakePrometheus(
+        [
+            _sample("kube_pod_status_phase", 1, phase="Running", **pod),
+            _sample("kube_pod_status_phase", 0, phase="Pending", **pod),
+            _sample("kube_pod_status_ready", 1, condition="false", **pod),
+            _sample("kube_pod_container_status_ready", 0, container="app", **pod),
+            _sample("kube_pod_container_status_restarts_total", 5, container="app", **pod),
+            _sample(
+                "kube_pod_container_status_waiting_reason",
+                1,
+                container="app",
+                reason="CrashLoopBackOff",
+                **pod,
+            ),
+            _sample(
+                "kube_pod_container_status_last_terminated_reason",
+                1,
+                container="app",
+                reason="OOMKilled",
+                **pod,
+            ),
+            _sample("kube_deployment_spec_replicas", 3, **deployment),
+            _sample("kube_deployment_status_replicas_available", 1, **deployment),
+            _sample("kube_deployment_status_replicas_unavailable", 2, **deployment),
+            _sample("kube_deployment_status_replicas_updated", 3, **deployment),
+            _sample(
+                "kube_deployment_status_condition",
+                1,
+                condition="Available",
+                status="false",
+                **deployment,
+            ),
+            _sample(
+                "kube_deployment_status_condition",
+                0,
+                condition="Available",
+                status="true",
+                **deployment,
+            ),
+        ]
+    )
+    collector = KubeStateCollector(PrometheusStateSource(prometheus))
+
+    snapshot = collector.snapshot(
+        "shop",
+        pod_name="checkout-7d9f-abc12",
+        workload="checkout",
+        at=datetime(2026, 10, 14, 2, 0, tzinfo=timezone.utc),
+    )
+
+    query, time = prometheus.queries[0]
+    assert len(prometheus.queries) == 1
+    assert time == "2026-10-14T02:00:00Z"
+    assert 'namespace="shop",pod="checkout-7d9f-abc12"' in query
+    assert 'namespace="shop",daemonset="checkout"' in query
+    assert snapshot is not None
+    assert snapshot["pod"] == {
+        "name": "checkout-7d9f-abc12",
+        "phase": "Running",
+        "ready": False,
+        "reason": None,
+        "containers": [
+            {
+                "container": "app",
+                "ready": False,
+                "restarts": 5,
+                "waiting_reason": "CrashLoopBackOff",
+                "last_terminated_reason": "OOMKilled",
+            }
+        ],
+    }
+    assert snapshot["workload"]["kind"] == "deployment"  # type: ignore[index]
+    assert snapshot["findings"] == [
+        "pod checkout-7d9f-abc12 is not ready",
+        "container app is waiting: CrashLoopBackOff",
+        "container app restarted 5 times (last termination: OOMKilled)",
+        "deployment checkout has 2 of 3 replicas unavailable",
+        "deployment checkout condition Available=false",
+    ]
+
+
+def test_snapshot_is_none_without_series_and_errors_raise() -> None:
+    collector = KubeStateCollector(PrometheusStateSource(FakePrometheus([])))
+    assert collector.snapshot("shop", pod_name="checkout-1", workload=None) is None
+
+    class FailingPrometheus:
+        def query(self, query: str, *, time: str | None = None) -> dict[str, object]:
+            return {"error": "failed to query Prometheus", "detail": "connection refused"}
+
+    collector = KubeStateCollector(PrometheusStateSource(FailingPrometheus()))
+    with pytest.raises(RuntimeError, match="connection refused"):
+        collector.snapshot("shop", pod_name=None, workload="checkout")
+
+
+class _FakeHTTPResponse:
+    def __init__(self, body: str) -> None:
+        self._body = body.encode("utf-8")
+
+    def read(self) -> bytes:
+        return self._body
+
+    def __enter__(self) -> _FakeHTTPResponse:
+        return self
+
+    def __exit__(self, exc_type, exc, tb) -> None:  # type: ignore[no-untyped-def]
+        return None
+
+
+def test_direct_scrape_filters_the_exposition_text(monkeypatch: pytest.MonkeyPatch) -> None:
+    monkeypatch.setenv("KUBE_STATE_METRICS_URL", "http://kube-state-metrics:8080/metrics")
+    body = "\n".join(
+        [
+            "# HELP kube_statefulset_replicas Number of desired pods for a StatefulSet.",
+            "# TYPE kube_statefulset_replicas gauge",
+            'kube_statefulset_replicas{namespace="shop",statefulset="orders-db"} 3',
+            'kube_statefulset_replicas{namespace="shop",statefulset="cache"} 1',
+            'kube_statefulset_status_replicas_ready{namespace="shop",statefulset="orders-db"} 2',
+            'kube_pod_info{namespace="shop",pod="orders-db-0"} 1',
+        ]
+    )
+    urls: list[str] = []
+
+    def fake_urlopen(request, timeout=0):  # type: ignore[no-untyped-def]
+        urls.append(request.full_url)
+        return _FakeHTTPResponse(body)
+
+    monkeypatch.setattr(kube_state_metrics_module.urllib.request, "urlopen", fake_urlopen)
+    collector = KubeStateCollector(KubeStateMetricsClient(load_settings()))
+
+    snapshot = collector.snapshot("shop", pod_name=None, workload="orders-db")
+
+    assert urls == ["http://kube-state-metrics:8080/metrics"]
+    assert snapshot is not None
+    assert snapshot["source"] == "kube-state-metrics"
+    assert snapshot["workload"] == {
+        "kind": "statefulset",
+        "name": "orders-db",
+        "desired": 3,
+        "available": 2,
+        "unavailable": 1,
+        "updated": None,
+    }
+    assert snapshot["findings"] == ["statefulset orders-db has 1 of 3 replicas unavailable"]
+
+
+def test_parse_sample_unescapes_label_values() -> None:
+    sample = parse_sample('kube_pod_status_reason{pod="a",reason="say \\"hi\\"\\\\"} 1 1700000000')
+
+    assert sample is not None
+    assert sample.labels == {"pod": "a", "reason": 'say "hi"\\'}
+    assert sample.value == 1.0
+    assert parse_sample("kube_pod_status_phase NaN") is None
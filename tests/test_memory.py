@@ -0,0 +1,77 @@
+from __future__ import annotations
+
+from pathlib import Path
+
+from app.core.memory import (
+    MEMORY_LEVEL_CRITICAL,
+    MEMORY_LEVEL_ELEVATED,
+    MEMORY_LEVEL_NORMAL,
+    MemoryPressureMonitor,
+    scale_limit,
+)
+
+
+def _write_cgroup_v2(root: Path, *, current: int, limit: str) -> None:
+    (root / "memory.current").write_text(f"{current}\n", encoding="utf-8")
+    (root / "memory.max").write_text(f"{limit}\n", encoding="utf-8")
+
+
+def test_monitor_reads_cgroup_v2_limit_and_usage(tmp_path: Path) -> None:
+    _write_cgroup_v2(tmp_path, current=500, limit="1000")
+
+    monitor = MemoryPressureMonitor(cgroup_root=str(tmp_path))
+
+    assert monitor.enabled
+    assert monitor.limit_bytes == 1000
+    assert monitor.pressure() == 0.5
+    assert monitor.level() == MEMORY_LEVEL_NORMAL
+    assert monitor.scale() == 1.0
+
+
+def test_monitor_levels_follow_ratios(tmp_path: Path) -> None:
+    _write_cgroup_v2(tmp_path, current=800, limit="1000")
+    monitor = MemoryPressureMonitor(cgroup_root=str(tmp_path), soft_ratio=0.75, hard_ratio=0.9)
+    assert monitor.level() == MEMORY_LEVEL_ELEVATED
+    assert monitor.scale() == 0.5
+
+    (tmp_path / "memory.current").write_text("950", encoding="utf-8")
+    assert monitor.level() == MEMORY_LEVEL_CRITICAL
+    assert monitor.scale() == 0.25
+
+
+def test_monitor_disabled_without_cgroup_limit(tmp_path: Path) -> None:
+    _write_cgroup_v2(tmp_path, current=500, limit="max")
+
+    monitor = MemoryPressureMonitor(cgroup_root=str(tmp_path))
+
+    assert not monitor.enabled
+    assert monitor.pressure() == 0.0
+    assert monitor.level() == MEMORY_LEVEL_NORMAL
+
+
+def test_monitor_explicit_limit_overrides_cgroup(tmp_path: Path) -> None:
+    _write_cgroup_v2(tmp_path, current=900, limit="max")
+
+    monitor = MemoryPressureMonitor(limit_bytes=1000, cgroup_root=str(tmp_path))
+
+    assert monitor.enabled
+    assert monitor.level() == MEMORY_LEVEL_CRITICAL
+
+
+def test_monitor_reads_cgroup_v1_files(tmp_path: Path) -> None:
+    v1_dir = tmp_path / "memory"
+    v1_dir.mkdir()
+    (v1_dir / "memory.limit_in_bytes").write_text("2000", encoding="utf-8")
+    (v1_dir / "memory.usage_in_bytes").write_text("1600", encoding="utf-8")
+
+    monitor = MemoryPressureMonitor(cgroup_root=str(tmp_path))
+
+    assert monitor.limit_bytes == 2000
+    assert monitor.level() == MEMORY_LEVEL_ELEVATED
+
+
+def test_scale_limit_keeps_minimum_of_one() -> None:
+    assert scale_limit(25, 1.0) == 25
+    assert scale_limit(25, 0.5) == 12
+    assert scale_limit(2, 0.25) == 1
+    assert scale_limit(0, 0.25) == 0
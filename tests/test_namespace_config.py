@@ -0,0 +1,84 @@
+from __future__ import annotations
+
+from app.services.namespace_config import format_namespace_config, resolve_namespace_config
+
+
+def _rca_config(name: str, **spec: object) -> dict[str, object]:
+    return {
+        "apiVersion": "kube-rca.io/v1alpha1",
+        "kind": "RCAConfig",
+        "metadata": {"name": name, "namespace": "shop"},
+        "spec": spec,
+    }
+
+
+def test_matching_rca_configs_are_merged_in_name_order() -> None:
+    runbook = {"title": "Checkout on-call", "url": "https://runbooks.example.com/checkout"}
+    dashboard = "https://grafana.example.com/d/shop"
+    items = [
+        _rca_config(
+            "shop-defaults",
+            analysisDepth="quick",
+            runbooks=[runbook],
+            dashboards=[{"url": dashboard}],
+            promptInstructions="Ask #shop-oncall before restarting anything.",
+        ),
+        _rca_config(
+            "checkout",
+            alertSelector={"app": "checkout"},
+            analysisDepth="Deep",
+            logSources=[{"name": "checkout", "query": '{app="checkout"}'}],
+            runbooks=[runbook],
+            promptInstructions="Checkout pods restart nightly at 03:00 UTC on purpose.",
+        ),
+        _rca_config("payments", alertSelector={"app": "payments"}, analysisDepth="deep"),
+    ]
+
+    config, warnings = resolve_namespace_config(items, {"app": "checkout", "namespace": "shop"})
+
+    assert warnings == []
+    assert config == {
+        "configs": ["shop/checkout", "shop/shop-defaults"],
+        "analysis_depth": "deep",
+        "log_sources": [
+            {"name": "checkout", "type": "loki", "query": '{app="checkout"}', "url": None}
+        ],
+        "runbooks": [runbook],
+        "dashboards": [{"title": dashboard, "url": dashboard}],
+        "prompt_instructions": (
+            "Checkout pods restart nightly at 03:00 UTC on purpose.\n"
+            "Ask #shop-oncall before restarting anything."
+        ),
+    }
+    section = format_namespace_config(config)
+    assert '- log source checkout (loki): {app="checkout"}' in section
+    assert "- runbook: Checkout on-call <https://runbooks.example.com/checkout>" in section
+    assert section.endswith("Ask #shop-oncall before restarting anything.\n\n")
+
+
+def test_invalid_rca_config_fields_are_reported_and_skipped() -> None:
+    items = [
+        _rca_config("broken", alertSelector=["app=checkout"]),
+        _rca_config(
+            "checkout",
+            analysisDepth="exhaustive",
+            logSources={"query": '{app="checkout"}'},
+            runbooks=[{"title": "no link"}],
+        ),
+    ]
+
+    config, warnings = resolve_namespace_config(items, {"app": "checkout"})
+
+    assert config is not None and config["analysis_depth"] == "standard"
+    assert config["log_sources"] == [] and config["runbooks"] == []
+    assert warnings == [
+        "RCAConfig shop/broken: alertSelector must be a map of labels",
+        "RCAConfig shop/checkout: unknown analysisDepth 'exhaustive'; "
+        "use one of ['deep', 'quick', 'standard']",
+        "RCAConfig shop/checkout: logSources must be a list",
+        "RCAConfig shop/checkout: runbooks entry without url",
+    ]
+    assert resolve_namespace_config([_rca_config("other", alertSelector={"app": "x"})], {}) == (
+        None,
+        [],
+    )
@@ -0,0 +1,181 @@
+from __future__ import annotations
+
+import logging
+from dataclasses import replace
+from types import SimpleNamespace
+
+from app.clients.k8s import KubernetesClient
+from app.models.k8s import K8sContext, PodEventSummary
+from app.schemas.analysis import NamespaceSnapshot
+from app.services.namespace_snapshot import build_namespace_snapshot
+from app.services.rules import run_rule_analyzers
+
+
+def _container(name: str, *, ready: bool, waiting: str | None = None, restarts: int = 0) -> object:
+    return SimpleNamespace(
+        name=name,
+        ready=ready,
+        restart_count=restarts,
+        state=SimpleNamespace(
+            waiting=SimpleNamespace(reason=waiting) if waiting else None, terminated=None
+        ),
+    )
+
+
+def _pod(name: str, phase: str, containers: list[object], reason: str | None = None) -> object:
+    return SimpleNamespace(
+        metadata=SimpleNamespace(name=name),
+        status=SimpleNamespace(phase=phase, reason=reason, container_statuses=containers),
+        spec=SimpleNamespace(node_name="node-1"),
+    )
+
+
+def _deployment(name: str, replicas: int, ready: int) -> object:
+    return SimpleNamespace(
+        metadata=SimpleNamespace(name=name),
+        spec=SimpleNamespace(replicas=replicas),
+        status=SimpleNamespace(
+            ready_replicas=ready, available_replicas=ready, updated_replicas=replicas
+        ),
+    )
+
+
+def _client(pods: list[object], deployments: list[object]) -> KubernetesClient:
+    client = KubernetesClient.__new__(KubernetesClient)
+    client._logger = logging.getLogger(__name__)
+    client._timeout_seconds = 5
+    client._core_api = SimpleNamespace(
+        list_namespaced_pod=lambda **kwargs: SimpleNamespace(items=pods)
+    )
+
+    def _failing_list(**kwargs: object) -> object:
+        raise RuntimeError("forbidden")
+
+    client._apps_api = SimpleNamespace(
+        list_namespaced_deployment=lambda **kwargs: SimpleNamespace(items=deployments),
+        list_namespaced_stateful_set=lambda **kwargs: SimpleNamespace(items=[]),
+        list_namespaced_daemon_set=_failing_list,
+    )
+    return client
+
+
+def test_namespace_snapshot_lists_unhealthy_objects_first_within_the_limits() -> None:
+    pods = [
+        _pod("web-1", "Running", [_container("web", ready=True)]),
+        _pod(
+            "checkout-b",
+            "Running",
+            [_container("api", ready=False, waiting="CrashLoopBackOff", restarts=7)],
+        ),
+        _pod("checkout-a", "Pending", []),
+        _pod("migrate-x", "Succeeded", [_container("job", ready=False)]),
+    ]
+    deployments = [
+        _deployment("web", 2, 2),
+        _deployment("checkout", 3, 1),
+        _deployment("cart", 2, 0),
+    ]
+
+    snapshot = _client(pods, deployments).get_namespace_snapshot(
+        "shop", max_workloads=2, max_pods=1
+    )
+
+    assert snapshot is not None
+    assert [item["name"] for item in snapshot["workloads"]] == ["cart", "checkout"]
+    assert (snapshot["workload_count"], snapshot["unhealthy_workload_count"]) == (3, 2)
+    assert snapshot["failing_pods"] == [
+        {
+            "name": "checkout-a",
+            "phase": "Pending",
+            "reason": None,
+            "ready": "0/0",
+            "restarts": 0,
+            "container_reasons": [],
+            "node": "node-1",
+        }
+    ]
+    assert (snapshot["pod_count"], snapshot["failing_pod_count"]) == (4, 2)
+    assert snapshot["warnings"] == ["failed to list daemonsets in shop"]
+
+
+def _event(reason: str, name: str, timestamp: str, count: int = 1) -> PodEventSummary:
+    return PodEventSummary(
+        type="Warning",
+        reason=reason,
+        message=f"{reason} for {name}",
+        count=count,
+        first_timestamp=None,
+        last_timestamp=timestamp,
+        involved_object={"kind": "Pod", "name": name, "namespace": "shop"},
+    )
+
+
+def test_namespace_snapshot_findings_feed_the_namespace_degraded_rule() -> None:
+    context = K8sContext(
+        namespace="shop",
+        pod_name=None,
+        workload=None,
+        pod_status=None,
+        events=[
+            _event("BackOff", "checkout-b", "2026-10-14T08:00:00Z", count=12),
+            _event("FailedScheduling", "checkout-a", "2026-10-14T08:05:00Z", count=3),
+            _event("BackOff", "checkout-c", "2026-10-14T07:00:00Z"),
+        ],
+        previous_logs=[],
+        warnings=[],
+    )
+    context = replace(
+        context,
+        namespace_snapshot={
+            "namespace": "shop",
+            "workloads": [
+                {
+                    "kind": "Deployment",
+                    "name": "checkout",
+                    "desired": 3,
+                    "ready": 1,
+                    "available": 1,
+                    "updated": 3,
+                    "healthy": False,
+                }
+            ],
+            "workload_count": 5,
+            "unhealthy_workload_count": 2,
+            "failing_pods": [
+                {
+                    "name": "checkout-b",
+                    "phase": "Running",
+                    "reason": None,
+                    "ready": "0/1",
+                    "restarts": 7,
+                    "container_reasons": ["api: CrashLoopBackOff"],
+                    "node": "node-1",
+                }
+            ],
+            "pod_count": 12,
+            "failing_pod_count": 3,
+            "max_events": 2,
+        },
+    )
+
+    snapshot = build_namespace_snapshot(context)
+
+    assert snapshot is not None
+    assert snapshot["findings"] == [
+        "namespace shop: 2/5 workload(s) below their desired replicas, 3/12 pod(s) failing",
+        "Deployment checkout: 1/3 ready, 1 available, 3 updated",
+        "pod checkout-b Running (0/1 ready, 7 restart(s)): api: CrashLoopBackOff",
+        "1 more unhealthy workload(s) not listed",
+        "2 more failing pod(s) not listed",
+        "warning events: BackOff x13, FailedScheduling x3",
+    ]
+    assert [item["object"] for item in snapshot["recent_events"]] == [
+        "Pod/checkout-a",
+        "Pod/checkout-b",
+    ]
+    assert snapshot["truncated"] is True
+    assert NamespaceSnapshot.model_validate(snapshot).failing_pods[0].restarts == 7
+    finding = next(
+        item for item in run_rule_analyzers(context) if item.rule == "namespace_degraded"
+    )
+    assert finding.evidence == snapshot["findings"]
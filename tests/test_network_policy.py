@@ -0,0 +1,226 @@
+from __future__ import annotations
+
+from app.models.k8s import K8sContext
+from app.schemas.analysis import NetworkPolicyAnalysis
+from app.services.network_policy import build_network_policy_analysis, connectivity_target
+
+
+def _selector(**labels: str) -> dict[str, object]:
+    return {"match_labels": labels, "match_expressions": []}
+
+
+def _policy(
+    name: str,
+    namespace: str,
+    *,
+    policy_types: list[str],
+    ingress: list[dict[str, object]] | None = None,
+    egress: list[dict[str, object]] | None = None,
+    pod_selector: dict[str, object] | None = None,
+) -> dict[str, object]:
+    return {
+        "name": name,
+        "namespace": namespace,
+        "pod_selector": pod_selector or _selector(),
+        "policy_types": policy_types,
+        "ingress": ingress or [],
+        "egress": egress or [],
+    }
+
+
+def _peer(
+    *,
+    pods: dict[str, object] | None = None,
+    namespaces: dict[str, object] | None = None,
+    cidr: str | None = None,
+) -> dict[str, object]:
+    return {
+        "pod_selector": pods,
+        "namespace_selector": namespaces,
+        "ip_block": {"cidr": cidr, "except": []} if cidr else None,
+    }
+
+
+def _context(
+    *,
+    source_policies: list[dict[str, object]] | None = None,
+    destination_policies: list[dict[str, object]] | None = None,
+    port: object = None,
+) -> K8sContext:
+    return K8sContext(
+        namespace="shop",
+        pod_name="checkout-7d9f8c-abcde",
+        workload="checkout",
+        pod_status=None,
+        events=[],
+        previous_logs=[],
+        warnings=[],
+        network_connectivity={
+            "source": {
+                "namespace": "shop",
+                "pod": "checkout-7d9f8c-abcde",
+                "labels": {"app": "checkout", "pod-template-hash": "7d9f8c"},
+                "namespace_labels": {"kubernetes.io/metadata.name": "shop", "team": "web"},
+                "policies": source_policies or [],
+            },
+            "destination": {
+                "namespace": "payments",
+                "service": "payments",
+                "found": True,
+                "selector": {"app": "payments"},
+                "ports": [{"name": "http", "port": 80, "target_port": 8080, "protocol": "TCP"}],
+                "namespace_labels": {"kubernetes.io/metadata.name": "payments"},
+                "policies": destination_policies or [],
+            },
+            "port": port,
+        },
+    )
+
+
+def test_network_policy_analysis_reports_blocked_egress_with_the_missing_rule() -> None:
+    deny_egress = _policy("default-deny-egress", "shop", policy_types=["Egress"])
+
+    analysis = build_network_policy_analysis(_context(source_policies=[deny_egress]))
+
+    assert analysis is not None
+    assert analysis["verdict"] == "blocked"
+    assert analysis["destination"] == {
+        "namespace": "payments",
+        "service": "payments",
+        "ports": [8080],
+    }
+    egress = analysis["egress"]
+    assert egress["isolated"] is True
+    assert egress["policies"] == ["shop/default-deny-egress"]
+    assert egress["missing_rule"] == {
+        "to": [
+            {
+                "podSelector": {"matchLabels": {"app": "payments"}},
+                "namespaceSelector": {"matchLabels": {"kubernetes.io/metadata.name": "payments"}},
+            }
+        ],
+        "ports": [{"protocol": "TCP", "port": 8080}],
+    }
+    assert analysis["ingress"]["verdict"] == "allowed"
+    assert analysis["findings"] == [
+        "egress from shop/checkout-7d9f8c-abcde to payments/payments:8080 is blocked: "
+        "NetworkPolicy shop/default-deny-egress selects the pod and none of its egress rules "
+        'allow the destination; missing allow rule: {"to": [{"podSelector": {"matchLabels": '
+        '{"app": "payments"}}, "namespaceSelector": {"matchLabels": '
+        '{"kubernetes.io/metadata.name": "payments"}}}], "ports": [{"protocol": "TCP", '
+        '"port": 8080}]}',
+        "egress of shop/checkout-7d9f8c-abcde is restricted by NetworkPolicy "
+        "shop/default-deny-egress and no egress rule allows DNS (port 53); service names do "
+        "not resolve",
+    ]
+    assert NetworkPolicyAnalysis.model_validate(analysis).egress.verdict == "blocked"
+
+
+def test_network_policy_analysis_reports_ingress_not_allowing_the_source() -> None:
+    only_gateway = _policy(
+        "payments-from-gateway",
+        "payments",
+        policy_types=["Ingress"],
+        pod_selector=_selector(app="payments"),
+        ingress=[
+            {
+                "from": [_peer(namespaces=_selector(team="edge"), pods=_selector(app="gateway"))],
+                "ports": [{"protocol": "TCP", "port": 8080, "end_port": None}],
+            }
+        ],
+    )
+    allow_dns = _policy(
+        "egress-dns-and-payments",
+        "shop",
+        policy_types=["Egress"],
+        egress=[
+            {"to": [], "ports": [{"protocol": "UDP", "port": 53, "end_port": None}]},
+            {"to": [_peer(namespaces=_selector(**{"kubernetes.io/metadata.name": "payments"}))]},
+        ],
+    )
+
+    analysis = build_network_policy_analysis(
+        _context(source_policies=[allow_dns], destination_policies=[only_gateway])
+    )
+
+    assert analysis is not None
+    assert analysis["verdict"] == "blocked"
+    assert analysis["egress"]["verdict"] == "allowed"
+    assert analysis["egress"]["allowed_by"] == ["shop/egress-dns-and-payments"]
+    assert analysis["egress"]["dns_allowed"] is True
+    assert analysis["ingress"]["missing_rule"] == {
+        "from": [
+            {
+                "podSelector": {"matchLabels": {"app": "checkout"}},
+                "namespaceSelector": {"matchLabels": {"kubernetes.io/metadata.name": "shop"}},
+            }
+        ],
+        "ports": [{"protocol": "TCP", "port": 8080}],
+    }
+    [finding] = analysis["findings"]
+    assert finding.startswith(
+        "ingress to payments/payments:8080 from shop/checkout-7d9f8c-abcde is blocked: "
+        "NetworkPolicy payments/payments-from-gateway selects the destination pods"
+    )
+
+
+def test_network_policy_analysis_allows_matching_rules_and_defers_ip_blocks() -> None:
+    allow_shop = _policy(
+        "payments-from-shop",
+        "payments",
+        policy_types=["Ingress"],
+        ingress=[
+            {
+                "from": [
+                    _peer(
+                        namespaces={
+                            "match_labels": {},
+                            "match_expressions": [
+                                {"key": "team", "operator": "In", "values": ["web", "edge"]}
+                            ],
+                        }
+                    )
+                ],
+                "ports": [{"protocol": "TCP", "port": 8000, "end_port": 8100}],
+            }
+        ],
+    )
+    ip_only = _policy(
+        "egress-to-vpc",
+        "shop",
+        policy_types=["Egress"],
+        egress=[{"to": [_peer(cidr="10.0.0.0/8")], "ports": []}],
+    )
+
+    allowed = build_network_policy_analysis(_context(destination_policies=[allow_shop]))
+    unknown = build_network_policy_analysis(
+        _context(source_policies=[ip_only], destination_policies=[allow_shop], port=80)
+    )
+
+    assert allowed is not None
+    assert allowed["verdict"] == "allowed"
+    assert allowed["ingress"]["allowed_by"] == ["payments/payments-from-shop"]
+    assert allowed["findings"] == []
+    assert unknown is not None
+    assert unknown["verdict"] == "unknown"
+    assert unknown["egress"]["verdict"] == "unknown"
+    assert unknown["findings"] == [
+        "egress from shop/checkout-7d9f8c-abcde to payments/payments:8080 could not be "
+        "evaluated: the rules of NetworkPolicy shop/egress-to-vpc use ipBlock peers or ports "
+        "that do not resolve from the API"
+    ]
+
+
+def test_connectivity_target_reads_destination_labels_of_connection_failure_alerts() -> None:
+    context = _context()
+
+    assert connectivity_target(
+        {"alertname": "HighErrorRate", "destination_service": "reviews.bookinfo.svc.cluster.local"},
+        context,
+    ) == {"namespace": "bookinfo", "service": "reviews", "port": None}
+    assert connectivity_target(
+        {"alertname": "UpstreamConnectFailures", "service": "payments", "destination_port": "80"},
+        context,
+    ) == {"namespace": "shop", "service": "payments", "port": 80}
+    crash_loop = {"alertname": "KubePodCrashLooping", "service": "api"}
+    assert connectivity_target(crash_loop, context) is None
@@ -0,0 +1,88 @@
+from __future__ import annotations
+
+from app.services.node_health import resolve_alert_node, summarize_node_health
+
+
+def _node(**overrides: object) -> dict[str, object]:
+    node: dict[str, object] = {
+        "name": "node-1",
+        "unschedulable": False,
+        "taints": [],
+        "capacity": {"cpu": "4", "memory": "16Gi", "pods": "110"},
+        "allocatable": {"cpu": "3800m", "memory": "15Gi", "pods": "110"},
+        "conditions": [
+            {"type": "Ready", "status": "True", "reason": "KubeletReady", "message": None},
+            {"type": "MemoryPressure", "status": "False", "reason": None, "message": None},
+        ],
+    }
+    node.update(overrides)
+    return node
+
+
+def test_resolve_alert_node_prefers_node_label_over_instance() -> None:
+    assert resolve_alert_node({"node": "node-1", "instance": "10.0.0.5:9100"}) == ("node-1", True)
+    assert resolve_alert_node({"instance": "node-2:9100"}) == ("node-2", False)
+    assert resolve_alert_node({"instance": "[fd00::1]"}) == ("fd00::1", False)
+    assert resolve_alert_node({"pod": "api-0"}) == (None, False)
+
+
+def test_summarize_node_health_reports_healthy_node() -> None:
+    health = summarize_node_health(_node())
+
+    assert health is not None
+    assert health["healthy"] is True
+    assert health["ready"] == "True"
+    assert health["resources"]["cpu"] == {  # type: ignore[index]
+        "capacity": "4",
+        "allocatable": "3800m",
+        "reserved_percent": 5.0,
+    }
+    assert summarize_node_health(None) is None
+
+
+def test_summarize_node_health_flags_not_ready_pressure_and_taints() -> None:
+    health = summarize_node_health(
+        _node(
+            unschedulable=True,
+            taints=[
+                {"key": "node.kubernetes.io/unreachable", "effect": "NoExecute"},
+                {"key": "dedicated", "effect": "NoSchedule"},
+            ],
+            allocatable={"cpu": "2", "memory": "15Gi", "pods": "110"},
+            conditions=[
+                {
+                    "type": "Ready",
+                    "status": "Unknown",
+                    "reason": "NodeStatusUnknown",
+                    "message": "Kubelet stopped posting node status.",
+                },
+                {
+                    "type": "DiskPressure",
+                    "status": "True",
+                    "reason": "KubeletHasDiskPressure",
+                    "message": None,
+                    "last_transition_time": "2026-10-14T01:00:00+00:00",
+                },
+            ],
+        )
+    )
+
+    assert health is not None
+    assert health["healthy"] is False
+    assert health["pressure"] == [
+        {
+            "type": "DiskPressure",
+            "reason": "KubeletHasDiskPressure",
+            "message": None,
+            "since": "2026-10-14T01:00:00+00:00",
+        }
+    ]
+    assert health["taints"] == ["node.kubernetes.io/unreachable:NoExecute", "dedicated:NoSchedule"]
+    assert health["findings"] == [
+        "node NotReady: kubelet stopped posting status (node down, kubelet crashed or network "
+        "partition) - Kubelet stopped posting node status.",
+        "DiskPressure: disk or image filesystem is nearly full; the kubelet evicts pods",
+        "node is cordoned (unschedulable)",
+        "health taints: node.kubernetes.io/unreachable:NoExecute",
+        "50.0% of cpu capacity is reserved away from pods",
+    ]
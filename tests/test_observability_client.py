@@ -0,0 +1,152 @@
+from __future__ import annotations
+
+import io
+import json
+import urllib.error
+
+import pytest
+
+import app.clients.observability as observability_module
+from app.clients.observability import ObservabilityClient, build_query_spec
+from app.core.config import load_settings
+
+
+class _FakeHTTPResponse:
+    def __init__(self, payload: object) -> None:
+        self._body = json.dumps(payload).encode("utf-8")
+
+    def read(self) -> bytes:
+        return self._body
+
+    def __enter__(self) -> _FakeHTTPResponse:
+        return self
+
+    def __exit__(self, exc_type, exc, tb) -> None:  # type: ignore[no-untyped-def]
+        return None
+
+
+def _client(monkeypatch: pytest.MonkeyPatch, provider: str, **env: str) -> ObservabilityClient:
+    monkeypatch.setenv("OBSERVABILITY_PROVIDER", provider)
+    for name, value in env.items():
+        monkeypatch.setenv(name, value)
+    return ObservabilityClient(load_settings())
+
+
+def test_honeycomb_query_creates_and_polls_results(monkeypatch: pytest.MonkeyPatch) -> None:
+    client = _client(
+        monkeypatch,
+        "honeycomb",
+        OBSERVABILITY_API_KEY="hc-key",
+        OBSERVABILITY_DATASET="checkout",
+    )
+    calls: list[tuple[str, str, object, str | None]] = []
+    responses = [
+        {"id": "q-1"},
+        {"id": "r-1", "complete": False},
+        {
+            "id": "r-1",
+            "complete": True,
+            "data": {"results": [{"data": {"user.id": "u-42", "P99(duration_ms)": 812.5}}]},
+            "links": {"query_url": "https://ui.honeycomb.io/shop/datasets/checkout/result/r-1"},
+        },
+    ]
+
+    def fake_urlopen(request, timeout=0):  # type: ignore[no-untyped-def]
+        body = json.loads(request.data) if request.data else None
+        calls.append(
+            (request.get_method(), request.full_url, body, request.get_header("X-honeycomb-team"))
+        )
+        return _FakeHTTPResponse(responses.pop(0))
+
+    monkeypatch.setattr(observability_module.urllib.request, "urlopen", fake_urlopen)
+    monkeypatch.setattr(observability_module.time, "sleep", lambda _seconds: None)
+
+    result = client.query(
+        service_name="checkout",
+        calculations=["P99(duration_ms)"],
+        filters=["http.status_code >= 500"],
+        breakdowns=["user.id"],
+    )
+
+    assert [(method, url) for method, url, _, _ in calls] == [
+        ("POST", "https://api.honeycomb.io/1/queries/checkout"),
+        ("POST", "https://api.honeycomb.io/1/query_results/checkout"),
+        ("GET", "https://api.honeycomb.io/1/query_results/checkout/r-1"),
+    ]
+    assert {key for _, _, _, key in calls} == {"hc-key"}
+    assert calls[0][2]["filters"] == [  # type: ignore[index]
+        {"column": "service.name", "op": "=", "value": "checkout"},
+        {"column": "http.status_code", "op": ">=", "value": 500},
+    ]
+    assert calls[1][2] == {"query_id": "q-1", "disable_series": True, "limit": 100}
+    assert result["complete"] is True
+    assert result["result_count"] == 1
+    assert result["results"] == [{"user.id": "u-42", "P99(duration_ms)": 812.5}]
+    assert str(result["query_url"]).endswith("/result/r-1")
+
+
+def test_build_query_spec_parses_calculations_filters_and_time() -> None:
+    spec = build_query_spec(
+        calculations=["COUNT", "HEATMAP(duration_ms)"],
+        filters=["error exists", "db.system = \"postgresql\"", "route starts-with /api"],
+        breakdowns=["k8s.pod.name", " "],
+        start="2026-10-14T10:00:00Z",
+        end="1791979200",
+        limit=20,
+    )
+
+    assert spec == {
+        "calculations": [{"op": "COUNT"}, {"op": "HEATMAP", "column": "duration_ms"}],
+        "filters": [
+            {"column": "error", "op": "exists"},
+            {"column": "db.system", "op": "=", "value": "postgresql"},
+            {"column": "route", "op": "starts-with", "value": "/api"},
+        ],
+        "filter_combination": "AND",
+        "breakdowns": ["k8s.pod.name"],
+        "limit": 20,
+        "orders": [{"op": "COUNT", "order": "descending"}],
+        "start_time": 1791972000,
+        "end_time": 1791979200,
+    }
+    with pytest.raises(ValueError, match="needs a column"):
+        build_query_spec(
+            calculations=["P99"], filters=None, breakdowns=None, start=None, end=None, limit=1
+        )
+
+
+def test_http_adapter_query_and_errors(monkeypatch: pytest.MonkeyPatch) -> None:
+    client = _client(
+        monkeypatch, "http", OBSERVABILITY_URL="https://otel-query.example.com/query"
+    )
+    bodies: list[dict[str, object]] = []
+
+    def fake_urlopen(request, timeout=0):  # type: ignore[no-untyped-def]
+        bodies.append(json.loads(request.data))
+        return _FakeHTTPResponse({"results": [{"COUNT": 3}], "complete": True})
+
+    monkeypatch.setattr(observability_module.urllib.request, "urlopen", fake_urlopen)
+
+    result = client.query(dataset="spans", filters=["error = true"])
+
+    assert bodies[0]["dataset"] == "spans"
+    assert bodies[0]["query"]["filters"] == [  # type: ignore[index]
+        {"column": "error", "op": "=", "value": True}
+    ]
+    assert result["provider"] == "http"
+    assert result["results"] == [{"COUNT": 3}]
+    assert "error" in client.query(filters=["error"])
+
+    def unavailable(request, timeout=0):  # type: ignore[no-untyped-def]
+        raise urllib.error.HTTPError(request.full_url, 503, "Unavailable", {}, io.BytesIO())
+
+    monkeypatch.setattr(observability_module.urllib.request, "urlopen", unavailable)
+    failed = client.query()
+    assert failed["status_code"] == 503
+    assert failed["error"] == "http query failed"
+
+
+def test_client_is_disabled_without_credentials(monkeypatch: pytest.MonkeyPatch) -> None:
+    monkeypatch.delenv("OBSERVABILITY_API_KEY", raising=False)
+    assert _client(monkeypatch, "honeycomb").enabled is False
+    assert _client(monkeypatch, "http").enabled is False
@@ -0,0 +1,133 @@
+from __future__ import annotations
+
+from app.models.k8s import K8sContext, PodStatusSnapshot
+from app.schemas.analysis import OomAnalysis
+from app.services.oom_analysis import build_oom_analysis, has_oom_kill
+
+
+def _context(
+    container_statuses: list[dict[str, object]],
+    *,
+    pod_spec: dict[str, object] | None = None,
+    pod_metrics: dict[str, object] | None = None,
+) -> K8sContext:
+    return K8sContext(
+        namespace="shop",
+        pod_name="api-0",
+        workload="api",
+        pod_status=PodStatusSnapshot(
+            phase="Running",
+            node_name="node-1",
+            start_time=None,
+            reason=None,
+            message=None,
+            conditions=[],
+            container_statuses=container_statuses,  # type: ignore[arg-type]
+        ),
+        events=[],
+        previous_logs=[],
+        warnings=[],
+        pod_spec=pod_spec,
+        pod_metrics=pod_metrics,
+    )
+
+
+def _oom_status(name: str) -> dict[str, object]:
+    return {
+        "name": name,
+        "restart_count": 3,
+        "state": {"type": "running"},
+        "last_state": {
+            "type": "terminated",
+            "reason": "OOMKilled",
+            "exit_code": 137,
+            "finished_at": "2026-10-14T01:58:00+00:00",
+        },
+    }
+
+
+def test_build_oom_analysis_compares_usage_with_limit() -> None:
+    context = _context(
+        [_oom_status("api"), {"name": "sidecar", "restart_count": 0, "state": {}}],
+        pod_spec={
+            "containers": [
+                {
+                    "name": "api",
+                    "resources": {
+                        "limits": {"memory": "512Mi"},
+                        "requests": {"memory": "256Mi"},
+                    },
+                },
+                {"name": "sidecar", "resources": {"limits": {"memory": "64Mi"}}},
+            ]
+        },
+        pod_metrics={
+            "containers": [
+                {"name": "api", "usage": {"cpu": "120m", "memory": "496Mi"}},
+                {"name": "sidecar", "usage": {"cpu": "1m", "memory": "12Mi"}},
+            ]
+        },
+    )
+
+    analysis = build_oom_analysis(context)
+
+    assert analysis is not None
+    assert analysis["pod"] == "api-0"
+    assert analysis["containers"] == [
+        {
+            "name": "api",
+            "restart_count": 3,
+            "last_oom_at": "2026-10-14T01:58:00+00:00",
+            "memory_request": "256Mi",
+            "memory_limit": "512Mi",
+            "memory_usage": "496Mi",
+            "usage_percent_of_limit": 96.9,
+            "suggested_limit": "768Mi",
+        }
+    ]
+    assert analysis["findings"] == [
+        "container api was OOMKilled at its 512Mi memory limit "
+        "(current usage 496Mi, 96.9% of the limit); "
+        "raise resources.limits.memory to 768Mi or reduce its memory use"
+    ]
+    assert OomAnalysis.model_validate(analysis).containers[0].suggested_limit == "768Mi"
+
+
+def test_build_oom_analysis_flags_node_oom_without_limit() -> None:
+    context = _context(
+        [_oom_status("api")],
+        pod_spec={"containers": [{"name": "api", "resources": {"requests": {"memory": "1Gi"}}}]},
+    )
+
+    analysis = build_oom_analysis(context)
+
+    assert analysis is not None
+    assert analysis["containers"][0]["suggested_limit"] == "1536Mi"
+    assert analysis["findings"][0].startswith(
+        "container api was OOMKilled without a memory limit (node out of memory)"
+    )
+
+
+def test_build_oom_analysis_without_spec_or_metrics() -> None:
+    analysis = build_oom_analysis(_context([_oom_status("api")]))
+
+    assert analysis is not None
+    assert analysis["containers"][0]["memory_limit"] is None
+    assert analysis["containers"][0]["suggested_limit"] is None
+    assert analysis["findings"] == ["container api was OOMKilled (memory limit unknown)"]
+
+
+def test_build_oom_analysis_ignores_pods_without_oom_kill() -> None:
+    context = _context(
+        [
+            {
+                "name": "api",
+                "restart_count": 1,
+                "state": {"type": "running"},
+                "last_state": {"type": "terminated", "reason": "Error", "exit_code": 1},
+            }
+        ]
+    )
+
+    assert has_oom_kill(context) is False
+    assert build_oom_analysis(context) is None
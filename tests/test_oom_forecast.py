@@ -0,0 +1,127 @@
+from __future__ import annotations
+
+from datetime import datetime, timedelta, timezone
+
+from app.services.oom_forecast import OomForecastService
+
+_MIB = 1024 * 1024
+_NOW = datetime(2026, 10, 14, 8, 0, tzinfo=timezone.utc)
+
+
+def _series(pod: str, container: str, value: float) -> dict[str, object]:
+    return {
+        "metric": {"namespace": "shop", "pod": pod, "container": container},
+        "value": [1760428800, str(value)],
+    }
+
+
+class _FakePrometheus:
+    def __init__(self) -> None:
+        self.queries: list[str] = []
+        self.usage = [
+            _series("api-0", "api", 1700 * _MIB),
+            _series("worker-0", "worker", 900 * _MIB),
+            _series("cache-0", "redis", 200 * _MIB),
+            _series("batch-0", "job", 1000 * _MIB),
+        ]
+        self.growth = [
+            _series("api-0", "api", 20 * _MIB / 3600),
+            _series("worker-0", "worker", 1 * _MIB / 3600),
+            _series("cache-0", "redis", 100 * _MIB / 3600),
+            _series("batch-0", "job", 50 * _MIB / 3600),
+        ]
+        self.limits = [
+            _series("api-0", "api", 2048 * _MIB),
+            _series("worker-0", "worker", 1024 * _MIB),
+            _series("cache-0", "redis", 1024 * _MIB),
+        ]
+
+    def query(self, query: str, *, time: str | None = None) -> dict[str, object]:
+        self.queries.append(query)
+        if query.startswith("max by (namespace, pod, container) (deriv("):
+            result = self.growth
+        elif "kube_pod_container_resource_limits" in query:
+            result = self.limits
+        else:
+            result = self.usage
+        return {"data": {"status": "success", "data": {"result": result}}}
+
+
+class _FakeSink:
+    def __init__(self) -> None:
+        self.reports: list[tuple[str, dict[str, object]]] = []
+
+    def send(self, report_type: str, report: dict[str, object]) -> dict[str, object]:
+        self.reports.append((report_type, report))
+        return {"delivered": True, "status_code": 200}
+
+
+class _Clock:
+    def __init__(self) -> None:
+        self.now = _NOW
+
+    def __call__(self) -> datetime:
+        return self.now
+
+
+def test_oom_forecast_reports_growing_containers_close_to_their_limit() -> None:
+    prometheus = _FakePrometheus()
+    sink = _FakeSink()
+    service = OomForecastService(prometheus, ["shop"], sink=sink, clock=_Clock())
+
+    report = service.scan()
+
+    # worker grows too slowly for the horizon, redis is far below its limit,
+    # and the batch job has no memory limit.
+    assert report["forecasts"] == [
+        {
+            "namespace": "shop",
+            "pod": "api-0",
+            "container": "api",
+            "usage_bytes": 1700 * _MIB,
+            "limit_bytes": 2048 * _MIB,
+            "usage_ratio": 0.83,
+            "growth_bytes_per_hour": 20 * _MIB,
+            "hours_to_limit": 17.4,
+            "summary": "container api of pod shop/api-0 will OOM in ~17 hours: 1700Mi of its "
+            "2048Mi limit, growing 20.0Mi/h",
+        }
+    ]
+    assert report["delivery"] == {"delivered": True, "status_code": 200}
+    assert [report_type for report_type, _ in sink.reports] == ["oom_forecast"]
+    assert 'namespace=~"shop"' in prometheus.queries[0]
+    assert "[6h]" in prometheus.queries[1]
+    assert service.latest_report is report
+
+
+def test_oom_forecast_renotifies_after_cooldown_or_when_the_forecast_halves() -> None:
+    prometheus = _FakePrometheus()
+    sink = _FakeSink()
+    clock = _Clock()
+    service = OomForecastService(prometheus, sink=sink, notify_cooldown_hours=12, clock=clock)
+
+    service.scan()
+    clock.now = _NOW + timedelta(hours=1)
+    second = service.scan()
+    prometheus.growth[0] = _series("api-0", "api", 60 * _MIB / 3600)
+    third = service.scan()
+    clock.now = _NOW + timedelta(hours=14)
+    fourth = service.scan()
+
+    assert (second["notified"], third["notified"], fourth["notified"]) == (0, 1, 1)
+    assert second["forecast_count"] == 1
+    assert len(sink.reports) == 3
+    assert "namespace=~" not in prometheus.queries[0]
+
+
+def test_oom_forecast_records_query_errors_without_forecasts() -> None:
+    class _Unavailable:
+        def query(self, query: str, *, time: str | None = None) -> dict[str, object]:
+            return {"error": "failed to query Prometheus", "detail": "connection refused"}
+
+    sink = _FakeSink()
+    report = OomForecastService(_Unavailable(), sink=sink, clock=_Clock()).scan()
+
+    assert report["forecasts"] == []
+    assert len(report["errors"]) == 1  # type: ignore[arg-type]
+    assert sink.reports == []
@@ -0,0 +1,106 @@
+from __future__ import annotations
+
+import json
+import os
+from collections.abc import Iterator
+from pathlib import Path
+
+import pytest
+
+from app.core.overrides import (
+    current_overrides,
+    init_analysis_overrides,
+    overrides_status,
+    parse_analysis_overrides,
+    reload_analysis_overrides,
+)
+from app.models.k8s import K8sContext, PodStatusSnapshot
+from app.services.rules import run_rule_analyzers
+
+
+@pytest.fixture(autouse=True)
+def _reset_overrides() -> Iterator[None]:
+    yield
+    init_analysis_overrides("")
+
+
+def _write(path: Path, payload: object, *, mtime: float) -> None:
+    path.write_text(json.dumps(payload) if not isinstance(payload, str) else payload)
+    os.utime(path, (mtime, mtime))
+
+
+def _oom_context() -> K8sContext:
+    return K8sContext(
+        namespace="default",
+        pod_name="demo-pod",
+        workload=None,
+        pod_status=PodStatusSnapshot(
+            phase="Running",
+            node_name="node-a",
+            start_time=None,
+            reason=None,
+            message=None,
+            conditions=[],
+            container_statuses=[
+                {
+                    "name": "app",
+                    "restart_count": 3,
+                    "last_state": {"type": "terminated", "reason": "OOMKilled", "exit_code": 137},
+                }
+            ],
+        ),
+        events=[],
+        previous_logs=[],
+        warnings=[],
+    )
+
+
+def test_overrides_reload_when_file_changes(tmp_path: Path) -> None:
+    path = tmp_path / "overrides.json"
+    _write(path, {"prompt_instructions": "  Check the Redis sidecar first. "}, mtime=1000)
+
+    init_analysis_overrides(str(path))
+
+    assert current_overrides().prompt_instructions == "Check the Redis sidecar first."
+    assert reload_analysis_overrides() is False
+
+    _write(path, {"disabled_rules": ["oom_killed"]}, mtime=2000)
+
+    assert reload_analysis_overrides() is True
+    assert current_overrides().disabled_rules == frozenset({"oom_killed"})
+    assert current_overrides().prompt_instructions == ""
+
+
+def test_invalid_overrides_keep_previous_values(tmp_path: Path) -> None:
+    path = tmp_path / "overrides.json"
+    _write(path, {"rule_severities": {"oom_killed": "info"}}, mtime=1000)
+    init_analysis_overrides(str(path))
+
+    _write(path, "{not json", mtime=2000)
+
+    assert reload_analysis_overrides() is False
+    assert current_overrides().rule_severities == {"oom_killed": "info"}
+    status = overrides_status()
+    assert status["last_error"]
+    assert status["rule_severities"] == {"oom_killed": "info"}
+
+
+def test_parse_rejects_unknown_keys_and_severities() -> None:
+    with pytest.raises(ValueError, match="unknown override keys: thresholds"):
+        parse_analysis_overrides({"thresholds": {}})
+    with pytest.raises(ValueError, match="rule_severities.oom_killed"):
+        parse_analysis_overrides({"rule_severities": {"oom_killed": "urgent"}})
+
+
+def test_rule_analyzers_apply_overrides(tmp_path: Path) -> None:
+    assert [finding.rule for finding in run_rule_analyzers(_oom_context())] == ["oom_killed"]
+
+    path = tmp_path / "overrides.json"
+    _write(path, {"rule_severities": {"oom_killed": "info"}}, mtime=1000)
+    init_analysis_overrides(str(path))
+    [finding] = run_rule_analyzers(_oom_context())
+    assert finding.severity == "info"
+
+    _write(path, {"disabled_rules": ["oom_killed"]}, mtime=2000)
+    reload_analysis_overrides()
+    assert run_rule_analyzers(_oom_context()) == []
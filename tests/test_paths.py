@@ -0,0 +1,47 @@
+from __future__ import annotations
+
+import os
+import tempfile
+from pathlib import Path
+
+import pytest
+
+from app.core.config import load_settings
+from app.core.paths import configure_data_dir
+
+
+def test_configure_data_dir_routes_temp_files(
+    tmp_path: Path, monkeypatch: pytest.MonkeyPatch
+) -> None:
+    monkeypatch.delenv("XDG_CACHE_HOME", raising=False)
+    monkeypatch.setenv("TMPDIR", "/tmp")
+    monkeypatch.setattr(tempfile, "tempdir", None)
+
+    root = configure_data_dir(str(tmp_path / "data"))
+
+    assert root == tmp_path / "data"
+    assert os.environ["TMPDIR"] == str(tmp_path / "data" / "tmp")
+    assert os.environ["XDG_CACHE_HOME"] == str(tmp_path / "data" / "cache")
+    with tempfile.NamedTemporaryFile() as handle:
+        assert handle.name.startswith(str(tmp_path / "data" / "tmp"))
+
+
+def test_configure_data_dir_keeps_defaults_when_not_writable(
+    tmp_path: Path, monkeypatch: pytest.MonkeyPatch
+) -> None:
+    monkeypatch.setenv("TMPDIR", "/tmp")
+    blocker = tmp_path / "file"
+    blocker.write_text("not a directory", encoding="utf-8")
+
+    assert configure_data_dir(str(blocker / "data")) is None
+    assert os.environ["TMPDIR"] == "/tmp"
+
+
+def test_configure_data_dir_disabled_when_empty() -> None:
+    assert configure_data_dir("") is None
+
+
+def test_data_dir_from_env(monkeypatch: pytest.MonkeyPatch) -> None:
+    monkeypatch.setenv("DATA_DIR", "/var/lib/kube-rca")
+
+    assert load_settings().data_dir == "/var/lib/kube-rca"
@@ -0,0 +1,128 @@
+from __future__ import annotations
+
+import asyncio
+import json
+from pathlib import Path
+
+import pytest
+
+from app.clients import report_sink
+from app.clients.report_sink import WebhookReportSink
+from app.core.payload_log import PayloadLoggingMiddleware, init_payload_logging
+
+
+class _EchoApp:
+    async def __call__(self, scope, receive, send) -> None:  # type: ignore[no-untyped-def]
+        body = b""
+        while True:
+            message = await receive()
+            body += message.get("body", b"")
+            if not message.get("more_body"):
+                break
+        await send({"type": "http.response.start", "status": 422, "headers": []})
+        await send({"type": "http.response.body", "body": b'{"detail": "bad alert"}'})
+
+
+def _post(path: str, chunks: list[bytes]) -> None:
+    messages = [
+        {"type": "http.request", "body": chunk, "more_body": index < len(chunks) - 1}
+        for index, chunk in enumerate(chunks)
+    ]
+
+    async def receive() -> dict[str, object]:
+        return messages.pop(0) if messages else {"type": "http.disconnect"}
+
+    async def send(message: dict[str, object]) -> None:
+        pass
+
+    scope = {"type": "http", "method": "POST", "path": path, "query_string": b"", "headers": []}
+    asyncio.run(PayloadLoggingMiddleware(_EchoApp())(scope, receive, send))
+
+
+def _entries(path: Path) -> list[dict[str, object]]:
+    if not path.exists():
+        return []
+    return [json.loads(line) for line in path.read_text(encoding="utf-8").splitlines()]
+
+
+@pytest.fixture(autouse=True)
+def _reset_payload_logging():
+    yield
+    init_payload_logging(0.0)
+
+
+def test_sampled_request_is_logged_with_secrets_redacted(tmp_path: Path) -> None:
+    sink = tmp_path / "payloads.log"
+    init_payload_logging(1.0, path=str(sink))
+    body = json.dumps(
+        {
+            "alert": {"status": "firing", "labels": {"alertname": "KubePodCrashLooping"}},
+            "cluster_credentials": {"token": "caller-token"},
+        }
+    ).encode("utf-8")
+
+    _post("/analyze", [body[:20], body[20:]])
+
+    [entry] = _entries(sink)
+    assert entry["kind"] == "http"
+    assert entry["method"] == "POST"
+    assert entry["path"] == "/analyze"
+    assert entry["status"] == 422
+    assert entry["request"]["bytes"] == len(body)
+    assert entry["request"]["body"]["alert"]["labels"] == {"alertname": "KubePodCrashLooping"}
+    assert "caller-token" not in sink.read_text(encoding="utf-8")
+    assert entry["response"]["body"] == {"detail": "bad alert"}
+
+
+def test_unsampled_and_health_requests_are_not_logged(tmp_path: Path) -> None:
+    sink = tmp_path / "payloads.log"
+    init_payload_logging(1.0, path=str(sink))
+    _post("/healthz", [b"{}"])
+    assert _entries(sink) == []
+
+    init_payload_logging(0.0, path=str(sink))
+    _post("/analyze", [b"{}"])
+    assert _entries(sink) == []
+
+
+def test_large_payloads_are_cut_and_still_redacted(tmp_path: Path) -> None:
+    sink = tmp_path / "payloads.log"
+    init_payload_logging(1.0, path=str(sink), max_bytes=64)
+    body = b'{"api_token": "abc123", "alert": {"annotations": {"summary": "' + b"x" * 200
+
+    _post("/analyze", [body])
+
+    [entry] = _entries(sink)
+    assert entry["request"]["truncated"] is True
+    assert entry["request"]["bytes"] == len(body)
+    assert entry["request"]["body"].startswith('{"api_token": "<redacted>"')
+    assert "abc123" not in entry["request"]["body"]
+
+
+def test_report_callbacks_are_logged_without_the_webhook_url(
+    tmp_path: Path, monkeypatch: pytest.MonkeyPatch
+) -> None:
+    sink = tmp_path / "payloads.log"
+    init_payload_logging(1.0, path=str(sink))
+
+    class _Response:
+        status = 204
+
+        def __enter__(self) -> _Response:
+            return self
+
+        def __exit__(self, *exc: object) -> None:
+            return None
+
+    monkeypatch.setattr(report_sink, "open_url", lambda request, timeout: _Response())
+    webhook = WebhookReportSink("https://hooks.example.com/services/T000/B000/s3cr3t")
+
+    result = webhook.send("digest", {"summary": "3 incidents"})
+
+    [entry] = _entries(sink)
+    assert result == {"delivered": True, "status_code": 204}
+    assert entry["kind"] == "callback"
+    assert entry["report_type"] == "digest"
+    assert entry["status"] == 204
+    assert entry["request"]["body"] == {"type": "digest", "report": {"summary": "3 incidents"}}
+    assert "s3cr3t" not in sink.read_text(encoding="utf-8")
@@ -0,0 +1,64 @@
+from __future__ import annotations
+
+import pytest
+
+from app.core.pipeline import (
+    DEFAULT_PIPELINE,
+    AnalysisPipeline,
+    PipelineRegistry,
+    parse_pipeline_profiles,
+)
+
+
+def test_resolve_prefers_alertname_then_severity_then_default() -> None:
+    registry = PipelineRegistry(
+        (
+            ("Watchdog", ("collect", "format")),
+            ("severity:info", ("collect", "rules", "format", "deliver")),
+            ("default", ("collect", "correlate", "rules", "llm", "format", "deliver")),
+        )
+    )
+
+    watchdog = registry.resolve({"alertname": "Watchdog", "severity": "info"})
+    info = registry.resolve({"alertname": "CPUThrottlingHigh", "severity": "Info"})
+    other = registry.resolve({"alertname": "HighLatency", "severity": "critical"})
+
+    assert watchdog == AnalysisPipeline("Watchdog", ("collect", "format"))
+    assert info.profile == "severity:info"
+    assert other.profile == "default"
+    assert PipelineRegistry().resolve({"alertname": "HighLatency"}) is DEFAULT_PIPELINE
+
+
+def test_runs_before_treats_skipped_stages_as_later() -> None:
+    pipeline = AnalysisPipeline("p", ("collect", "rules", "llm", "correlate", "format"))
+
+    assert pipeline.runs_before("rules", "llm") is True
+    assert pipeline.runs_before("correlate", "llm") is False
+    assert pipeline.runs_before("llm", "deliver") is True
+    assert pipeline.runs_before("deliver", "llm") is False
+    assert DEFAULT_PIPELINE.runs_before("rules", "llm") is False
+
+
+def test_parse_pipeline_profiles_normalizes_stage_names() -> None:
+    profiles = parse_pipeline_profiles(
+        '{"default": ["collect", " LLM ", "format"], " ": ["collect"]}', "PIPELINES"
+    )
+
+    assert profiles == (("default", ("collect", "llm", "format")),)
+    assert parse_pipeline_profiles("", "PIPELINES") == ()
+
+
+@pytest.mark.parametrize(
+    "stages,message",
+    [
+        ('["collect", "enrich"]', "unknown stages"),
+        ('["llm", "collect"]', "must start with 'collect'"),
+        ('["collect", "llm", "llm"]', "more than once"),
+        ('["collect", "format", "llm"]', "'llm' must run before 'format'"),
+        ('["collect", "deliver", "format"]', "'deliver' must be the last stage"),
+        ('"collect"', "must be an array of stage names"),
+    ],
+)
+def test_parse_pipeline_profiles_rejects_invalid_definitions(stages: str, message: str) -> None:
+    with pytest.raises(ValueError, match=message):
+        parse_pipeline_profiles(f'{{"default": {stages}}}', "PIPELINES")
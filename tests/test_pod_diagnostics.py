@@ -0,0 +1,89 @@
+from __future__ import annotations
+
+from app.models.k8s import K8sContext, PodStatusSnapshot
+from app.schemas.analysis import PodDiagnostics
+from app.services.pod_diagnostics import build_pod_diagnostics
+
+
+def _context(pod_status: PodStatusSnapshot | None) -> K8sContext:
+    return K8sContext(
+        namespace="shop",
+        pod_name="api-0",
+        workload="api",
+        pod_status=pod_status,
+        events=[],
+        previous_logs=[],
+        warnings=[],
+    )
+
+
+def test_build_pod_diagnostics_summarizes_container_states() -> None:
+    status = PodStatusSnapshot(
+        phase="Running",
+        node_name="node-1",
+        start_time=None,
+        reason=None,
+        message=None,
+        conditions=[
+            {"type": "Ready", "status": "False", "reason": "ContainersNotReady", "message": None},
+            {"type": "PodScheduled", "status": "True", "reason": None, "message": None},
+        ],
+        container_statuses=[
+            {
+                "name": "api",
+                "ready": False,
+                "restart_count": 5,
+                "state": {"type": "waiting", "reason": "CrashLoopBackOff", "message": None},
+                "last_state": {
+                    "type": "terminated",
+                    "reason": "OOMKilled",
+                    "message": None,
+                    "exit_code": "137",
+                    "finished_at": "2026-10-14T01:00:00+00:00",
+                },
+            },
+            {
+                "name": "proxy",
+                "ready": False,
+                "restart_count": 0,
+                "state": {"type": "running", "started_at": None},
+                "last_state": None,
+            },
+        ],
+        init_container_statuses=[
+            {
+                "name": "migrate",
+                "ready": True,
+                "restart_count": 0,
+                "state": {"type": "terminated", "reason": "Completed", "exit_code": "0"},
+                "last_state": None,
+            }
+        ],
+    )
+
+    diagnostics = build_pod_diagnostics(_context(status))
+
+    assert diagnostics is not None
+    assert diagnostics["total_restarts"] == 5
+    init, api, proxy = diagnostics["containers"]
+    assert init["init"] is True and init["exit_code"] == 0
+    assert api["last_termination"] == {
+        "reason": "OOMKilled",
+        "exit_code": 137,
+        "finished_at": "2026-10-14T01:00:00+00:00",
+    }
+    assert diagnostics["failing_conditions"] == [
+        {"type": "Ready", "status": "False", "reason": "ContainersNotReady", "message": None}
+    ]
+    assert diagnostics["findings"] == [
+        "container api is waiting: CrashLoopBackOff",
+        "container api restarted 5 time(s), last termination: OOMKilled "
+        "(exit code 137, SIGKILL: OOM kill or failed liveness probe)",
+        "container proxy is running but not ready",
+        "condition Ready=False (ContainersNotReady)",
+    ]
+    assert PodDiagnostics.model_validate(diagnostics).containers[1].restart_count == 5
+
+
+def test_build_pod_diagnostics_needs_pod_status() -> None:
+    assert build_pod_diagnostics(_context(None)) is None
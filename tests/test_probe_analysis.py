@@ -0,0 +1,151 @@
+from __future__ import annotations
+
+from app.models.k8s import K8sContext, PodEventSummary, PodStatusSnapshot
+from app.schemas.analysis import ProbeAnalysis
+from app.services.probe_analysis import build_probe_analysis, summarize_probe
+from app.services.rules import run_rule_analyzers
+
+
+def _event(reason: str, message: str, count: int = 1) -> PodEventSummary:
+    return PodEventSummary(
+        type="Warning",
+        reason=reason,
+        message=message,
+        count=count,
+        first_timestamp=None,
+        last_timestamp=None,
+        involved_object={"kind": "Pod", "name": "api-0", "namespace": "shop"},
+    )
+
+
+def _http_probe(path: str, port: object, **timings: int) -> dict[str, object]:
+    return {"http_get": {"path": path, "port": port, "scheme": "HTTP"}, **timings}
+
+
+def _context(
+    events: list[PodEventSummary],
+    *,
+    liveness: dict[str, object] | None = None,
+    readiness: dict[str, object] | None = None,
+    restart_count: int = 0,
+) -> K8sContext:
+    return K8sContext(
+        namespace="shop",
+        pod_name="api-0",
+        workload="api",
+        pod_status=PodStatusSnapshot(
+            phase="Running",
+            node_name="node-1",
+            start_time=None,
+            reason=None,
+            message=None,
+            conditions=[],
+            container_statuses=[
+                {"name": "api", "ready": False, "restart_count": restart_count}
+            ],
+        ),
+        events=events,
+        previous_logs=[],
+        warnings=[],
+        pod_spec={
+            "containers": [
+                {
+                    "name": "api",
+                    "ports": [{"name": "http", "container_port": 8080, "protocol": "TCP"}],
+                    "liveness_probe": liveness,
+                    "readiness_probe": readiness,
+                    "startup_probe": None,
+                }
+            ]
+        },
+    )
+
+
+def test_probe_analysis_flags_wrong_path_and_slow_startup_as_misconfigured() -> None:
+    context = _context(
+        [
+            _event(
+                "Unhealthy",
+                'Readiness probe failed: Get "http://10.1.2.3:8080/healthz": HTTP probe '
+                "failed with statuscode: 404",
+                count=12,
+            ),
+            _event(
+                "Unhealthy",
+                'Liveness probe failed: Get "http://10.1.2.3:8080/live": dial tcp '
+                "10.1.2.3:8080: connect: connection refused",
+                count=6,
+            ),
+            _event("Killing", "Container api failed liveness probe, will be restarted", 2),
+        ],
+        liveness=_http_probe("/live", "http", period_seconds=5, failure_threshold=3),
+        readiness=_http_probe("/healthz", 8080),
+        restart_count=2,
+    )
+
+    analysis = build_probe_analysis(context)
+
+    assert analysis is not None
+    assert analysis["root_cause"] == "misconfigured_probe"
+    assert [
+        (entry["probe"], entry["verdict"], entry["cause"], entry["issues"])
+        for entry in analysis["probes"]  # type: ignore[attr-defined]
+    ] == [
+        ("readiness", "misconfigured", "wrong_path", ["wrong_path"]),
+        ("liveness", "misconfigured", "connection_refused", ["liveness_before_startup"]),
+    ]
+    assert analysis["findings"] == [
+        "readiness probe of container api (HTTP /healthz on port 8080, period 10s, timeout 1s, "
+        "failureThreshold 3) failed 12 times: misconfigured probe, path /healthz returns 404",
+        "liveness probe of container api (HTTP /live on port http, period 5s, timeout 1s, "
+        "failureThreshold 3) failed 6 times, restarting the container 2 times: misconfigured "
+        "probe, it gives up after 15s without a startup probe; add a startupProbe or raise "
+        "initialDelaySeconds/failureThreshold",
+    ]
+    parsed = ProbeAnalysis.model_validate(analysis)
+    assert parsed.probes[1].restarts_by_probe == 2
+
+
+def test_probe_analysis_reports_server_errors_as_unhealthy_app() -> None:
+    context = _context(
+        [
+            _event(
+                "Unhealthy",
+                'Readiness probe failed: Get "http://10.1.2.3:8080/ready": context deadline '
+                "exceeded (Client.Timeout exceeded while awaiting headers)",
+                count=4,
+            ),
+            _event("Unhealthy", "Liveness probe failed: HTTP probe failed with statuscode: 503"),
+        ],
+        liveness=_http_probe("/live", 8080, initial_delay_seconds=60),
+        readiness=_http_probe("/ready", 8080, timeout_seconds=5),
+    )
+
+    analysis = build_probe_analysis(context)
+
+    assert analysis is not None
+    assert analysis["root_cause"] == "unhealthy_app"
+    assert analysis["findings"] == [
+        "readiness probe of container api (HTTP /ready on port 8080, period 10s, timeout 5s, "
+        "failureThreshold 3) failed 4 times: app unhealthy, the app does not answer within 5s "
+        '(Get "http://10.1.2.3:8080/ready": context deadline exceeded (Client.Timeout '
+        "exceeded while awaiting headers))",
+        "liveness probe of container api (HTTP /live on port 8080, period 10s, timeout 1s, "
+        "failureThreshold 3) failed 1 times: app unhealthy, the app answers HTTP 503 "
+        "(HTTP probe failed with statuscode: 503)",
+    ]
+    finding = next(item for item in run_rule_analyzers(context) if item.rule == "probe_failure")
+    assert finding.title == "Application fails its health probes"
+    assert finding.evidence[-2:] == analysis["findings"]
+
+
+def test_summarize_probe_reads_exec_probes_and_applies_defaults() -> None:
+    assert summarize_probe({"_exec": {"command": ["cat", "/tmp/healthy"]}}) == {
+        "type": "exec",
+        "command": ["cat", "/tmp/healthy"],
+        "initial_delay_seconds": 0,
+        "period_seconds": 10,
+        "timeout_seconds": 1,
+        "failure_threshold": 3,
+    }
+    assert build_probe_analysis(_context([_event("Killing", "Stopping container api")])) is None
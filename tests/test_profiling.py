@@ -0,0 +1,47 @@
+from __future__ import annotations
+
+import sys
+import types
+
+import pytest
+
+from app.core.config import load_settings
+from app.core.profiling import configure_profiling, parse_profiling_tags
+
+
+def test_parse_profiling_tags_skips_malformed_entries() -> None:
+    tags = parse_profiling_tags("env=prod, cluster = kind ,broken,=empty,key=")
+
+    assert tags == {"env": "prod", "cluster": "kind"}
+
+
+def test_configure_profiling_disabled_without_server(monkeypatch: pytest.MonkeyPatch) -> None:
+    monkeypatch.delenv("PYROSCOPE_SERVER_ADDRESS", raising=False)
+
+    assert configure_profiling(load_settings()) is False
+
+
+def test_configure_profiling_skips_when_package_missing(monkeypatch: pytest.MonkeyPatch) -> None:
+    monkeypatch.setenv("PYROSCOPE_SERVER_ADDRESS", "http://pyroscope:4040")
+    monkeypatch.setitem(sys.modules, "pyroscope", None)
+
+    assert configure_profiling(load_settings()) is False
+
+
+def test_configure_profiling_passes_settings(monkeypatch: pytest.MonkeyPatch) -> None:
+    captured: dict[str, object] = {}
+    fake_module = types.ModuleType("pyroscope")
+    fake_module.configure = lambda **kwargs: captured.update(kwargs)  # type: ignore[attr-defined]
+    monkeypatch.setitem(sys.modules, "pyroscope", fake_module)
+    monkeypatch.setenv("PYROSCOPE_SERVER_ADDRESS", "http://pyroscope:4040")
+    monkeypatch.setenv("PYROSCOPE_TAGS", "env=prod")
+    monkeypatch.setenv("PYROSCOPE_TENANT_ID", "team-a")
+    monkeypatch.setenv("HOSTNAME", "agent-0")
+
+    assert configure_profiling(load_settings()) is True
+    assert captured["server_address"] == "http://pyroscope:4040"
+    assert captured["application_name"] == "kube-rca-agent"
+    assert captured["sample_rate"] == 100
+    assert captured["tags"] == {"env": "prod", "hostname": "agent-0"}
+    assert captured["tenant_id"] == "team-a"
+    assert "auth_token" not in captured
@@ -0,0 +1,129 @@
+from __future__ import annotations
+
+from dataclasses import replace
+
+from app.models.k8s import K8sContext, PodEventSummary
+from app.schemas.analysis import QuotaAnalysis
+from app.services.quota_analysis import build_quota_analysis, quota_alert
+from app.services.rules import run_rule_analyzers
+
+
+def _event(message: str, *, kind: str = "ReplicaSet", name: str = "api-7d9f") -> PodEventSummary:
+    return PodEventSummary(
+        type="Warning",
+        reason="FailedCreate",
+        message=message,
+        count=5,
+        first_timestamp=None,
+        last_timestamp="2026-10-14T08:00:00Z",
+        involved_object={"kind": kind, "name": name, "namespace": "shop"},
+    )
+
+
+def _usage(resource: str, used: str, hard: str, ratio: float) -> dict[str, object]:
+    return {"quota": "compute", "resource": resource, "used": used, "hard": hard, "ratio": ratio}
+
+
+def _context(events: list[PodEventSummary]) -> K8sContext:
+    return K8sContext(
+        namespace="shop",
+        pod_name=None,
+        workload="api",
+        pod_status=None,
+        events=events,
+        previous_logs=[],
+        warnings=[],
+    )
+
+
+def test_quota_analysis_reports_exhausted_quota_and_missing_requests() -> None:
+    context = _context(
+        [
+            _event(
+                'Error creating: pods "api-7d9f-x2k" is forbidden: exceeded quota: compute, '
+                "requested: requests.cpu=500m, used: requests.cpu=3800m, limited: requests.cpu=4"
+            ),
+            _event(
+                'Error creating: pods "worker-6c5b-q1" is forbidden: failed quota: compute: '
+                "must specify limits.cpu for: sidecar,worker; limits.memory for: worker",
+                name="worker-6c5b",
+            ),
+        ]
+    )
+    context = replace(
+        context,
+        resource_quotas={
+            "namespace": "shop",
+            "trigger": "events",
+            "quotas": [
+                _usage("requests.cpu", "3800m", "4", 0.95),
+                _usage("pods", "19", "20", 0.95),
+                _usage("limits.memory", "1Gi", "8Gi", 0.125),
+            ],
+            "limit_ranges": [],
+        },
+    )
+
+    assert quota_alert({"alertname": "KubePodNotReady"}, context) == "events"
+    analysis = build_quota_analysis(context)
+
+    assert analysis is not None
+    failures = analysis["failures"]
+    assert [(item["kind"], item["owner"]) for item in failures] == [  # type: ignore[attr-defined]
+        ("quota_exceeded", "ReplicaSet api-7d9f"),
+        ("quota_requires_resources", "ReplicaSet worker-6c5b"),
+        ("quota_requires_resources", "ReplicaSet worker-6c5b"),
+    ]
+    assert analysis["findings"] == [
+        "ReplicaSet api-7d9f cannot create pods: ResourceQuota compute requests.cpu is "
+        "exhausted (pod requests 500m, 3800m of 4 already used)",
+        "ReplicaSet worker-6c5b cannot create pods: ResourceQuota compute requires limits.cpu "
+        "but containers sidecar, worker do not set it; set it or add a LimitRange default",
+        "ReplicaSet worker-6c5b cannot create pods: ResourceQuota compute requires "
+        "limits.memory but containers worker do not set it; set it or add a LimitRange default",
+        "ResourceQuota compute pods at 95% (19/20)",
+    ]
+    parsed = QuotaAnalysis.model_validate(analysis)
+    assert parsed.failures[1].containers == ["sidecar", "worker"]
+    assert parsed.failures[0].count == 5
+
+
+def test_quota_analysis_parses_limit_range_bounds_and_ratios() -> None:
+    context = _context(
+        [
+            _event(
+                'Error creating: pods "etl-x" is forbidden: [maximum memory usage per Container '
+                "is 1Gi, but limit is 2Gi, cpu max limit to request ratio per Container is 4, "
+                "but provided ratio is 10.000000]",
+                kind="Job",
+                name="etl",
+            )
+        ]
+    )
+
+    analysis = build_quota_analysis(context)
+
+    assert analysis is not None
+    assert analysis["findings"] == [
+        "Job etl cannot create pods: LimitRange maximum memory per Container is 1Gi, but the "
+        "limit is 2Gi",
+        "Job etl cannot create pods: LimitRange allows a cpu limit/request ratio of 4 per "
+        "Container, the pod has 10.000000",
+    ]
+    finding = next(item for item in run_rule_analyzers(context) if item.rule == "quota_exhausted")
+    assert finding.severity == "critical"
+    assert finding.evidence == analysis["findings"]
+    assert finding.recommendation == (
+        "Keep the pod's requests/limits within the namespace LimitRange."
+    )
+
+
+def test_quota_alert_ignores_unrelated_failed_create_events() -> None:
+    context = _context(
+        [_event('Error creating: pods "api-x" is forbidden: error looking up service account')]
+    )
+
+    assert quota_alert({"alertname": "KubeQuotaAlmostFull"}, context) == "alert"
+    assert quota_alert({"alertname": "KubePodNotReady"}, context) is None
+    assert build_quota_analysis(context) is None
+    assert all(item.rule != "quota_exhausted" for item in run_rule_analyzers(context))
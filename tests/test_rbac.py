@@ -0,0 +1,124 @@
+from __future__ import annotations
+
+import dataclasses
+
+from app.core.config import load_settings
+from app.core.rbac import (
+    ANALYSIS_PERMISSIONS,
+    Permission,
+    render_rbac_manifest,
+    required_permissions,
+)
+
+
+def test_required_permissions_follow_enabled_collectors() -> None:
+    defaults = required_permissions(
+        dataclasses.replace(
+            load_settings(),
+            event_archive_enabled=False,
+            helm_release_scan_enabled=False,
+            health_scan_namespaces=(),
+            namespace_rca_config_enabled=False,
+            namespace_snapshot_enabled=False,
+        )
+    )
+    enabled = required_permissions(
+        dataclasses.replace(
+            load_settings(),
+            event_archive_enabled=True,
+            helm_release_scan_enabled=True,
+            health_scan_namespaces=("payments",),
+            namespace_rca_config_enabled=True,
+            namespace_snapshot_enabled=True,
+        )
+    )
+
+    assert defaults == list(ANALYSIS_PERMISSIONS)
+    assert Permission("event_archive", "watch", "", "events", cluster_scoped=True) in enabled
+    assert Permission("health_scan", "list", "", "resourcequotas", namespace="payments") in enabled
+    assert Permission("rca_config", "list", "kube-rca.io", "rcaconfigs") in enabled
+    assert Permission("namespace_snapshot", "list", "apps", "statefulsets") in enabled
+    assert Permission("helm_releases", "list", "", "secrets") in enabled
+    assert not any(item.verb == "watch" for item in defaults)
+    assert not any(item.resource == "secrets" for item in defaults)
+
+
+def test_render_rbac_manifest_merges_verbs_and_scopes_single_namespace_reads() -> None:
+    permissions = [
+        Permission("analysis", "get", "", "pods"),
+        Permission("analysis", "list", "", "pods"),
+        Permission("analysis", "list", "", "services"),
+        Permission("analysis", "get", "", "pods", "log"),
+        Permission("analysis", "list", "", "nodes", cluster_scoped=True),
+        Permission("event_archive", "watch", "", "events", cluster_scoped=True),
+        Permission("event_archive", "list", "", "events", cluster_scoped=True),
+        Permission("analysis", "list", "batch", "jobs"),
+        Permission("health_scan", "list", "", "pods", namespace="payments"),
+        Permission(
+            "health_scan", "list", "cert-manager.io", "certificates", namespace="payments"
+        ),
+    ]
+
+    manifest = render_rbac_manifest(permissions, service_account="rca", namespace="ops")
+
+    assert manifest == (
+        "# Least-privilege RBAC generated by kube-rca-agent for: analysis, event_archive, "
+        "health_scan\n"
+        "apiVersion: rbac.authorization.k8s.io/v1\n"
+        "kind: ClusterRole\n"
+        "metadata:\n"
+        "  name: rca\n"
+        "rules:\n"
+        '  - apiGroups: [""]\n'
+        '    resources: ["pods"]\n'
+        '    verbs: ["get", "list"]\n'
+        '  - apiGroups: [""]\n'
+        '    resources: ["services", "nodes"]\n'
+        '    verbs: ["list"]\n'
+        '  - apiGroups: [""]\n'
+        '    resources: ["pods/log"]\n'
+        '    verbs: ["get"]\n'
+        '  - apiGroups: [""]\n'
+        '    resources: ["events"]\n'
+        '    verbs: ["list", "watch"]\n'
+        '  - apiGroups: ["batch"]\n'
+        '    resources: ["jobs"]\n'
+        '    verbs: ["list"]\n'
+        "---\n"
+        "apiVersion: rbac.authorization.k8s.io/v1\n"
+        "kind: ClusterRoleBinding\n"
+        "metadata:\n"
+        "  name: rca\n"
+        "roleRef:\n"
+        "  apiGroup: rbac.authorization.k8s.io\n"
+        "  kind: ClusterRole\n"
+        "  name: rca\n"
+        "subjects:\n"
+        "  - kind: ServiceAccount\n"
+        "    name: rca\n"
+        "    namespace: ops\n"
+        "---\n"
+        "apiVersion: rbac.authorization.k8s.io/v1\n"
+        "kind: Role\n"
+        "metadata:\n"
+        "  name: rca\n"
+        "  namespace: payments\n"
+        "rules:\n"
+        '  - apiGroups: ["cert-manager.io"]\n'
+        '    resources: ["certificates"]\n'
+        '    verbs: ["list"]\n'
+        "---\n"
+        "apiVersion: rbac.authorization.k8s.io/v1\n"
+        "kind: RoleBinding\n"
+        "metadata:\n"
+        "  name: rca\n"
+        "  namespace: payments\n"
+        "roleRef:\n"
+        "  apiGroup: rbac.authorization.k8s.io\n"
+        "  kind: Role\n"
+        "  name: rca\n"
+        "subjects:\n"
+        "  - kind: ServiceAccount\n"
+        "    name: rca\n"
+        "    namespace: ops\n"
+    )
@@ -0,0 +1,146 @@
+from __future__ import annotations
+
+import email.message
+import json
+import urllib.error
+
+import pytest
+
+import app.clients.registry as registry_module
+from app.clients.registry import RegistryClient, parse_image_reference
+from app.core.config import load_settings
+
+_DIGEST = "sha256:" + "a" * 64
+_AMD64_DIGEST = "sha256:" + "b" * 64
+_CONFIG_DIGEST = "sha256:" + "c" * 64
+
+
+class _FakeHTTPResponse:
+    def __init__(self, payload: dict[str, object], headers: dict[str, str] | None = None) -> None:
+        self._body = json.dumps(payload).encode("utf-8")
+        self.headers = email.message.Message()
+        for key, value in (headers or {}).items():
+            self.headers[key] = value
+
+    def read(self) -> bytes:
+        return self._body
+
+    def __enter__(self) -> _FakeHTTPResponse:
+        return self
+
+    def __exit__(self, exc_type, exc, tb) -> None:  # type: ignore[no-untyped-def]
+        return None
+
+
+def _unauthorized(url: str) -> urllib.error.HTTPError:
+    headers = email.message.Message()
+    headers["WWW-Authenticate"] = (
+        'Bearer realm="https://ghcr.io/token",service="ghcr.io",scope="repository:acme/api:pull"'
+    )
+    return urllib.error.HTTPError(url, 401, "Unauthorized", headers, None)
+
+
+_RESPONSES: dict[str, dict[str, object]] = {
+    f"/manifests/{_DIGEST}": {
+        "mediaType": "application/vnd.oci.image.index.v1+json",
+        "manifests": [
+            {"digest": "sha256:arm", "platform": {"os": "linux", "architecture": "arm64"}},
+            {"digest": _AMD64_DIGEST, "platform": {"os": "linux", "architecture": "amd64"}},
+            {
+                "digest": "sha256:att",
+                "platform": {"os": "unknown", "architecture": "unknown"},
+                "annotations": {"vnd.docker.reference.type": "attestation-manifest"},
+            },
+        ],
+    },
+    f"/manifests/{_AMD64_DIGEST}": {
+        "mediaType": "application/vnd.oci.image.manifest.v1+json",
+        "config": {"digest": _CONFIG_DIGEST},
+    },
+    f"/blobs/{_CONFIG_DIGEST}": {
+        "created": "2026-10-13T08:00:00Z",
+        "config": {
+            "Labels": {
+                "org.opencontainers.image.revision": "3f2c1ab",
+                "org.opencontainers.image.source": "https://github.com/acme/api",
+                "org.opencontainers.image.version": "1.4.2",
+            }
+        },
+    },
+    f"/referrers/{_DIGEST}": {
+        "manifests": [
+            {"digest": "sha256:sbom", "artifactType": "application/spdx+json"},
+            {"digest": "sha256:sig", "artifactType": "application/vnd.dev.cosign.signature"},
+        ]
+    },
+}
+
+
+def test_parse_image_reference_normalizes_docker_hub() -> None:
+    assert parse_image_reference("nginx") == ("registry-1.docker.io", "library/nginx", "latest")
+    assert parse_image_reference("docker.io/bitnami/redis:7.2") == (
+        "registry-1.docker.io",
+        "bitnami/redis",
+        "7.2",
+    )
+    assert parse_image_reference("localhost:5000/api@sha256:abc") == (
+        "localhost:5000",
+        "api",
+        "sha256:abc",
+    )
+
+
+def test_describe_image_reads_build_labels_and_attestations(
+    monkeypatch: pytest.MonkeyPatch,
+) -> None:
+    monkeypatch.setenv("IMAGE_METADATA_ENABLED", "true")
+    monkeypatch.setenv("IMAGE_METADATA_SBOM_ENABLED", "true")
+    requests: list[tuple[str, str | None]] = []
+
+    def fake_urlopen(request, timeout=0):  # type: ignore[no-untyped-def]
+        url = request.full_url
+        requests.append((url, request.get_header("Authorization")))
+        if url.startswith("https://ghcr.io/token?"):
+            return _FakeHTTPResponse({"token": "pull-token"})
+        if request.get_header("Authorization") != "Bearer pull-token":
+            raise _unauthorized(url)
+        return _FakeHTTPResponse(_RESPONSES[url.split("/v2/acme/api", 1)[1]])
+
+    monkeypatch.setattr(registry_module.urllib.request, "urlopen", fake_urlopen)
+
+    result = RegistryClient(load_settings()).describe_image(
+        "ghcr.io/acme/api:1.4.2", f"ghcr.io/acme/api@{_DIGEST}"
+    )
+
+    assert result["digest"] == _DIGEST
+    assert result["build"] == {
+        "revision": "3f2c1ab",
+        "source": "https://github.com/acme/api",
+        "version": "1.4.2",
+        "created": "2026-10-13T08:00:00Z",
+    }
+    assert result["commit_url"] == "https://github.com/acme/api/commit/3f2c1ab"
+    assert result["attestations"] == [
+        {"source": "image_index", "artifact_type": "attestation-manifest", "digest": "sha256:att"},
+        {"source": "referrers", "artifact_type": "application/spdx+json", "digest": "sha256:sbom"},
+    ]
+    token_url = requests[1][0]
+    assert "scope=repository%3Aacme%2Fapi%3Apull" in token_url
+    # The token is cached per repository: one challenge for the whole lookup.
+    assert sum(1 for url, _ in requests if "/token?" in url) == 1
+
+
+def test_describe_image_reports_registry_errors(monkeypatch: pytest.MonkeyPatch) -> None:
+    monkeypatch.setenv("IMAGE_METADATA_ENABLED", "true")
+
+    def fake_urlopen(request, timeout=0):  # type: ignore[no-untyped-def]
+        headers = email.message.Message()
+        raise urllib.error.HTTPError(request.full_url, 404, "Not Found", headers, None)
+
+    monkeypatch.setattr(registry_module.urllib.request, "urlopen", fake_urlopen)
+
+    result = RegistryClient(load_settings()).describe_image("registry.example.com/api:v1")
+
+    assert result["repository"] == "api"
+    assert result["error"] == "registry HTTP error 404 for /manifests/v1"
+    assert "build" not in result
@@ -0,0 +1,101 @@
+from __future__ import annotations
+
+from app.models.k8s import K8sContext
+from app.schemas.analysis import ResourcePressure
+from app.services.resource_pressure import build_resource_pressure
+
+
+def _context(
+    *,
+    usage: dict[str, str] | None = None,
+    resources: dict[str, object] | None = None,
+    node_metrics: dict[str, object] | None = None,
+) -> K8sContext:
+    return K8sContext(
+        namespace="shop",
+        pod_name="api-7d9f8c-abcde",
+        workload="api",
+        pod_status=None,
+        events=[],
+        previous_logs=[],
+        warnings=[],
+        pod_spec={"containers": [{"name": "api", "resources": resources or {}}]},
+        pod_metrics=(
+            {"window": "30s", "containers": [{"name": "api", "usage": usage}]}
+            if usage is not None
+            else None
+        ),
+        node_metrics=node_metrics,
+    )
+
+
+def test_resource_pressure_flags_memory_near_limit_as_critical() -> None:
+    pressure = build_resource_pressure(
+        _context(
+            usage={"cpu": "120000000n", "memory": "490Mi"},
+            resources={
+                "requests": {"cpu": "100m", "memory": "256Mi"},
+                "limits": {"cpu": "500m", "memory": "512Mi"},
+            },
+        )
+    )
+
+    assert pressure is not None
+    assert pressure["verdict"] == "critical"
+    assert pressure["window"] == "30s"
+    [container] = pressure["containers"]
+    assert container["cpu_usage"] == "120m"
+    assert container["cpu_percent_of_request"] == 120.0
+    assert container["memory_percent_of_limit"] == 95.7
+    assert pressure["findings"] == [
+        "container api uses 120.0% of its cpu request (120m of 100m); it depends on spare "
+        "CPU on the node",
+        "container api uses 95.7% of its memory limit (490Mi of 512Mi); the next allocation "
+        "spike gets it OOMKilled",
+    ]
+    assert ResourcePressure.model_validate(pressure).containers[0].memory_limit == "512Mi"
+
+
+def test_resource_pressure_compares_node_usage_with_allocatable() -> None:
+    node_metrics = {
+        "name": "node-1",
+        "window": "20s",
+        "usage": {"cpu": "3100m", "memory": "14Gi"},
+        "allocatable": {"cpu": "4", "memory": "15Gi"},
+    }
+
+    pressure = build_resource_pressure(
+        _context(usage={"cpu": "50m", "memory": "100Mi"}, node_metrics=node_metrics)
+    )
+
+    assert pressure is not None
+    assert pressure["verdict"] == "critical"
+    assert pressure["node"] == {
+        "name": "node-1",
+        "cpu_usage": "3100m",
+        "cpu_allocatable": "4000m",
+        "cpu_percent_of_allocatable": 77.5,
+        "memory_usage": "14.0Gi",
+        "memory_allocatable": "15.0Gi",
+        "memory_percent_of_allocatable": 93.3,
+    }
+    assert pressure["findings"] == [
+        "node node-1 uses 77.5% of its allocatable cpu (3100m of 4000m)",
+        "node node-1 uses 93.3% of its allocatable memory (14.0Gi of 15.0Gi); pods on it "
+        "compete for CPU and may be evicted under memory pressure",
+    ]
+
+
+def test_resource_pressure_ok_within_requests_and_none_without_metrics() -> None:
+    pressure = build_resource_pressure(
+        _context(
+            usage={"cpu": "50m", "memory": "100Mi"},
+            resources={"requests": {"cpu": "100m", "memory": "256Mi"}},
+        )
+    )
+
+    assert pressure is not None
+    assert pressure["verdict"] == "ok"
+    assert pressure["findings"] == []
+    assert pressure["containers"][0]["memory_percent_of_limit"] is None
+    assert build_resource_pressure(_context()) is None
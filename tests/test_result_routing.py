@@ -0,0 +1,74 @@
+from __future__ import annotations
+
+import pytest
+
+from app.core.config import load_settings
+from app.services.result_routing import ROUTE_CALLBACK, ROUTE_REVIEW, ResultRouter
+
+
+class FakeSink:
+    def __init__(self, delivered: bool = True) -> None:
+        self._delivered = delivered
+        self.sent: list[tuple[str, dict[str, object]]] = []
+
+    def send(self, report_type: str, report: dict[str, object]) -> dict[str, object]:
+        self.sent.append((report_type, report))
+        return {"delivered": self._delivered, "reason": None if self._delivered else "down"}
+
+
+def _result(quality: str | None) -> dict[str, object]:
+    return {
+        "analysis": "## Summary\nOOM",
+        "analysis_quality": quality,
+        "context": {"namespace": "default"},
+        "artifacts": [{"type": "rule_finding"}],
+    }
+
+
+def test_results_at_or_above_threshold_go_to_callback() -> None:
+    sink = FakeSink()
+    router = ResultRouter(sink, min_quality="medium")
+
+    assert router.route(_result("high")) == ROUTE_CALLBACK
+    assert router.route(_result("medium")) == ROUTE_CALLBACK
+    assert sink.sent == []
+
+
+def test_low_confidence_result_is_sent_to_review_with_evidence() -> None:
+    sink = FakeSink()
+    router = ResultRouter(sink, min_quality="high")
+
+    assert router.route(_result("medium")) == ROUTE_REVIEW
+    report_type, report = sink.sent[0]
+    assert report_type == "analysis_review"
+    result = report["result"]
+    assert isinstance(result, dict)
+    assert result["context"] == {"namespace": "default"}
+    assert result["artifacts"] == [{"type": "rule_finding"}]
+    assert "below high" in str(report["reason"])
+
+
+def test_missing_quality_counts_as_low() -> None:
+    sink = FakeSink()
+
+    assert ResultRouter(sink).route(_result(None)) == ROUTE_REVIEW
+
+
+def test_unreachable_review_sink_falls_back_to_callback() -> None:
+    router = ResultRouter(FakeSink(delivered=False), min_quality="high")
+
+    assert router.route(_result("low")) == ROUTE_CALLBACK
+
+
+def test_unknown_minimum_quality_is_rejected() -> None:
+    with pytest.raises(ValueError, match="unknown minimum analysis quality"):
+        ResultRouter(FakeSink(), min_quality="hihg")
+
+
+def test_settings_reject_unknown_minimum_quality(monkeypatch: pytest.MonkeyPatch) -> None:
+    monkeypatch.setenv("REVIEW_MIN_ANALYSIS_QUALITY", " High ")
+    assert load_settings().review_min_analysis_quality == "high"
+
+    monkeypatch.setenv("REVIEW_MIN_ANALYSIS_QUALITY", "hihg")
+    with pytest.raises(ValueError, match="REVIEW_MIN_ANALYSIS_QUALITY must be one of"):
+        load_settings()
@@ -0,0 +1,301 @@
+from __future__ import annotations
+
+import asyncio
+from datetime import datetime, timezone
+
+import pytest
+
+from app.core.config import load_settings
+from app.core.k8s_clusters import init_clusters
+from app.services.retention import RetentionService, run_retention_janitor
+
+
+class _FakeSessionRepository:
+    def __init__(self, deleted: int = 3) -> None:
+        self.calls: list[int] = []
+        self._deleted = deleted
+
+    def purge_inactive_sessions(self, older_than_days: int) -> int:
+        self.calls.append(older_than_days)
+        return self._deleted
+
+
+class _FakeSummaryStore:
+    def __init__(self, deleted: int = 5) -> None:
+        self.calls: list[int] = []
+        self._deleted = deleted
+
+    def purge_older_than(self, older_than_days: int) -> int:
+        self.calls.append(older_than_days)
+        return self._deleted
+
+
+def test_purge_uses_configured_retention_windows() -> None:
+    sessions = _FakeSessionRepository()
+    summaries = _FakeSummaryStore()
+    service = RetentionService(
+        sessions, summaries, session_retention_days=7, summary_retention_days=90
+    )
+
+    result = service.purge()
+
+    assert service.enabled is True
+    assert sessions.calls == [7]
+    assert summaries.calls == [90]
+    assert result == {"sessions_deleted": 3, "summaries_deleted": 5}
+
+
+def test_purge_skips_disabled_or_missing_stores() -> None:
+    summaries = _FakeSummaryStore()
+    service = RetentionService(None, summaries, session_retention_days=7)
+
+    result = service.purge()
+
+    assert service.enabled is False
+    assert summaries.calls == []
+    assert result == {"sessions_deleted": None, "summaries_deleted": None}
+
+
+def test_purge_overrides_take_precedence() -> None:
+    sessions = _FakeSessionRepository()
+    summaries = _FakeSummaryStore()
+    service = RetentionService(sessions, summaries, session_retention_days=7)
+
+    service.purge(session_retention_days=1, summary_retention_days=30)
+
+    assert sessions.calls == [1]
+    assert summaries.calls == [30]
+
+
+def test_janitor_survives_purge_failures() -> None:
+    class _FailingRepository:
+        def __init__(self) -> None:
+            self.calls = 0
+
+        def purge_inactive_sessions(self, older_than_days: int) -> int:
+            self.calls += 1
+            raise RuntimeError("database unavailable")
+
+    repository = _FailingRepository()
+    service = RetentionService(repository, None, session_retention_days=7)
+
+    async def _run() -> None:
+        task = asyncio.create_task(run_retention_janitor(service, interval_seconds=0))
+        while repository.calls < 2:
+            await asyncio.sleep(0)
+        task.cancel()
+        with pytest.raises(asyncio.CancelledError):
+            await task
+
+    asyncio.run(_run())
+
+    assert repository.calls >= 2
+
+
+def test_retention_settings_from_env(monkeypatch: pytest.MonkeyPatch) -> None:
+    monkeypatch.setenv("SESSION_RETENTION_DAYS", "7")
+    monkeypatch.setenv("SUMMARY_RETENTION_DAYS", "90")
+    monkeypatch.setenv("RETENTION_JANITOR_INTERVAL_SECONDS", "600")
+
+    settings = load_settings()
+
+    assert settings.session_retention_days == 7
+    assert settings.summary_retention_days == 90
+    assert settings.retention_janitor_interval_seconds == 600
+
+
+class _DeletingSessionRepository(_FakeSessionRepository):
+    def __init__(self) -> None:
+        super().__init__()
+        self.deletions: list[dict[str, object]] = []
+
+    def delete_sessions(self, **filters: object) -> int:
+        self.deletions.append(filters)
+        return 2
+
+
+class _DeletingSummaryStore(_FakeSummaryStore):
+    def __init__(self, keys: list[str]) -> None:
+        super().__init__()
+        self._keys = keys
+        self.deletions: list[dict[str, object]] = []
+
+    def delete_summaries(self, **filters: object) -> list[str]:
+        self.deletions.append(filters)
+        return self._keys
+
+
+def test_delete_analyses_by_namespace_applies_every_filter_to_sessions() -> None:
+    sessions = _DeletingSessionRepository()
+    summaries = _DeletingSummaryStore(["inc-1:fp-a", "inc-1:fp-a", "inc-2:fp-b"])
+    service = RetentionService(sessions, summaries)
+    until = datetime(2026, 1, 1, tzinfo=timezone.utc)
+
+    result = service.delete_analyses(namespace="payments", until=until)
+
+    assert summaries.deletions == [
+        {"namespace": "payments", "session_prefix": None, "since": None, "until": until}
+    ]
+    assert sessions.deletions == [
+        {
+            "namespace": "payments",
+            "session_keys": ["inc-1:fp-a", "inc-2:fp-b"],
+            "session_prefix": None,
+            "since": None,
+            "until": until,
+        }
+    ]
+    assert result == {"sessions_deleted": 2, "summaries_deleted": 3}
+
+
+def test_delete_analyses_by_incident_and_time_range() -> None:
+    sessions = _DeletingSessionRepository()
+    summaries = _DeletingSummaryStore([])
+    service = RetentionService(sessions, summaries)
+    since = datetime(2026, 1, 1, tzinfo=timezone.utc)
+
+    service.delete_analyses(incident_id="inc-1", since=since)
+
+    assert summaries.deletions[0]["session_prefix"] == "inc-1:"
+    assert sessions.deletions == [{"session_prefix": "inc-1:", "since": since, "until": None}]
+
+
+def test_delete_analyses_namespace_without_summaries_still_deletes_tagged_sessions() -> None:
+    sessions = _DeletingSessionRepository()
+    service = RetentionService(sessions, None)
+
+    result = service.delete_analyses(namespace="payments")
+
+    assert sessions.deletions[0]["namespace"] == "payments"
+    assert sessions.deletions[0]["session_keys"] == []
+    assert result == {"sessions_deleted": 2, "summaries_deleted": None}
+
+
+def test_delete_analyses_rejects_namespace_filters_across_clusters() -> None:
+    service = RetentionService(_DeletingSessionRepository(), _DeletingSummaryStore([]))
+
+    init_clusters([("prod-eu", "/etc/kube-rca/prod-eu", "")], local_name="prod-us")
+    try:
+        with pytest.raises(ValueError, match="K8S_CLUSTERS_JSON"):
+            service.delete_analyses(namespace="payments")
+        service.delete_analyses(incident_id="inc-1")
+    finally:
+        init_clusters(())
+
+
+def test_delete_analyses_requires_a_filter() -> None:
+    service = RetentionService(_DeletingSessionRepository(), _DeletingSummaryStore([]))
+
+    with pytest.raises(ValueError):
+        service.delete_analyses()
+
+
+class _FakeShadowStore(_FakeSummaryStore):
+    def __init__(self, deleted: int = 2) -> None:
+        super().__init__(deleted)
+        self.deletions: list[dict[str, object]] = []
+
+    def delete_results(self, **filters: object) -> int:
+        self.deletions.append(filters)
+        return self._deleted
+
+
+def test_shadow_results_follow_the_session_retention_window() -> None:
+    shadow = _FakeShadowStore()
+    service = RetentionService(
+        _FakeSessionRepository(),
+        _FakeSummaryStore(),
+        session_retention_days=7,
+        summary_retention_days=90,
+        shadow_store=shadow,
+    )
+
+    result = service.purge()
+
+    assert shadow.calls == [7]
+    assert result["shadow_results_deleted"] == 2
+
+
+def test_analysis_history_follows_the_summary_retention_window() -> None:
+    history = _FakeShadowStore()
+    service = RetentionService(
+        _FakeSessionRepository(),
+        _FakeSummaryStore(),
+        session_retention_days=7,
+        summary_retention_days=90,
+        analysis_store=history,
+    )
+
+    result = service.purge()
+
+    assert history.calls == [90]
+    assert result["analysis_results_deleted"] == 2
+
+
+class _FakeArchive:
+    def __init__(self) -> None:
+        self.calls: list[int] = []
+
+    def purge_older_than(self, older_than_days: int) -> int:
+        self.calls.append(older_than_days)
+        return 4
+
+
+def test_archive_objects_use_their_own_retention_window() -> None:
+    archive = _FakeArchive()
+    service = RetentionService(None, None, archive=archive, archive_retention_days=365)
+
+    assert service.enabled
+    result = service.purge()
+
+    assert archive.calls == [365]
+    assert result["archives_deleted"] == 4
+
+
+def test_archived_events_use_their_own_retention_window() -> None:
+    events = _FakeArchive()
+    service = RetentionService(None, None, event_archive=events, event_archive_retention_days=7)
+
+    assert service.enabled
+    result = service.purge()
+
+    assert events.calls == [7]
+    assert result["archived_events_deleted"] == 4
+    assert not RetentionService(None, None, event_archive=events).enabled
+
+
+class _DeletingArchive(_FakeArchive):
+    def __init__(self) -> None:
+        super().__init__()
+        self.deletions: list[dict[str, object]] = []
+
+    def delete_analyses(self, **filters: object) -> int:
+        self.deletions.append(filters)
+        return 2
+
+    def delete_events(self, **filters: object) -> int:
+        self.deletions.append(filters)
+        return 5
+
+
+def test_delete_analyses_covers_archive_objects_artifacts_and_archived_events() -> None:
+    archive, artifacts, events = _DeletingArchive(), _DeletingArchive(), _DeletingArchive()
+    service = RetentionService(
+        None, None, archive=archive, artifact_store=artifacts, event_archive=events
+    )
+    since = datetime(2026, 1, 1, tzinfo=timezone.utc)
+
+    result = service.delete_analyses(namespace="payments", since=since)
+
+    filters = {"namespace": "payments", "incident_id": None, "since": since, "until": None}
+    assert archive.deletions == [filters]
+    assert artifacts.deletions == [filters]
+    assert events.deletions == [{"namespace": "payments", "since": since, "until": None}]
+    assert result["archives_deleted"] == 2
+    assert result["artifacts_deleted"] == 2
+    assert result["archived_events_deleted"] == 5
+
+    # Events carry no incident, so an incident filter leaves them alone.
+    result = service.delete_analyses(incident_id="inc-1")
+    assert result["archived_events_deleted"] is None
+    assert len(events.deletions) == 1
@@ -8,9 +8,16 @@ from unittest.mock import MagicMock, patch
 
 import httpx
 import pytest
+from strands.hooks import BeforeModelCallEvent, BeforeToolCallEvent, HookRegistry
 from tenacity import RetryError
 
-from app.clients.strands_agent import StrandsAnalysisEngine, _has_transport_error, _is_retryable
+from app.clients.strands_agent import (
+    StrandsAnalysisEngine,
+    _DeadlineHook,
+    _has_transport_error,
+    _is_retryable,
+)
+from app.core.deadline import DeadlineExceeded, bounded_timeout, use_deadline
 
 
 class _FakeServerError(Exception):
@@ -177,3 +184,53 @@ def test_succeeds_on_retry() -> None:
 
     assert result == "success"
     assert call_count == 3
+
+
+def test_tool_hitting_the_deadline_stops_the_agent_loop() -> None:
+    engine = _build_engine(max_attempts=5, total_timeout=10)
+    registry = HookRegistry()
+    _DeadlineHook().register_hooks(registry)
+    model_calls = 0
+    tool_errors: list[Exception] = []
+
+    def _tool() -> str:
+        time.sleep(0.1)
+        # Every client bounds its request timeout by the deadline.
+        return f"read with timeout {bounded_timeout(5)}"
+
+    def _fake_agent_loop(prompt: str) -> str:
+        nonlocal model_calls
+        try:
+            for _ in range(5):
+                registry.invoke(BeforeModelCallEvent())
+                model_calls += 1
+                try:
+                    _tool()
+                except Exception as exc:  # Strands returns tool errors to the model.
+                    tool_errors.append(exc)
+        except Exception as exc:
+            raise RuntimeError("event loop failed") from exc
+        return "unbounded"
+
+    agent = MagicMock()
+    agent.side_effect = _fake_agent_loop
+
+    with use_deadline(time.perf_counter() + 0.05), pytest.raises(DeadlineExceeded):
+        engine._invoke_with_retry(agent, "test")
+
+    assert model_calls == 1
+    assert len(tool_errors) == 1 and isinstance(tool_errors[0], DeadlineExceeded)
+    assert agent.call_count == 1
+
+
+def test_deadline_hook_cancels_tool_calls_past_the_deadline() -> None:
+    registry = HookRegistry()
+    _DeadlineHook().register_hooks(registry)
+    before, after = BeforeToolCallEvent(), BeforeToolCallEvent()
+
+    registry.invoke(before)
+    with use_deadline(0.0):
+        registry.invoke(after)
+
+    assert before.cancel_tool is False
+    assert after.cancel_tool == "analysis deadline passed"
@@ -0,0 +1,55 @@
+from __future__ import annotations
+
+from datetime import datetime, timezone
+
+from app.services.rollout_correlation import find_recent_rollouts
+
+
+def _revision(revision: int, created: str | None) -> dict[str, object]:
+    return {
+        "revision": revision,
+        "replica_set": f"api-{revision}",
+        "created": created,
+        "containers": [{"name": "api", "image": f"api:1.{revision}"}],
+        "build": {},
+    }
+
+
+def test_find_recent_rollouts_keeps_revisions_inside_the_window() -> None:
+    revisions = [
+        _revision(7, "2026-10-14T01:56:00+00:00"),
+        _revision(6, "2026-10-14T01:20:00+00:00"),
+        _revision(5, "2026-10-13T09:00:00+00:00"),
+        _revision(4, None),
+    ]
+
+    rollouts = find_recent_rollouts(
+        "api", revisions, datetime(2026, 10, 14, 2, 0, tzinfo=timezone.utc), 60
+    )
+
+    assert rollouts == [
+        {
+            "deployment": "api",
+            "revision": 7,
+            "replica_set": "api-7",
+            "created": "2026-10-14T01:56:00+00:00",
+            "minutes_before_alert": 4.0,
+            "images": ["api:1.7"],
+        },
+        {
+            "deployment": "api",
+            "revision": 6,
+            "replica_set": "api-6",
+            "created": "2026-10-14T01:20:00+00:00",
+            "minutes_before_alert": 40.0,
+            "images": ["api:1.6"],
+        },
+    ]
+
+
+def test_find_recent_rollouts_ignores_rollouts_after_the_alert() -> None:
+    revisions = [_revision(8, "2026-10-14T02:10:00Z"), _revision(7, "2026-10-14T01:50:00Z")]
+
+    rollouts = find_recent_rollouts("api", revisions, datetime(2026, 10, 14, 2, 0), 30)
+
+    assert [item["revision"] for item in rollouts] == [7]
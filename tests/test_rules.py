@@ -0,0 +1,487 @@
+from __future__ import annotations
+
+from dataclasses import replace
+
+from app.models.k8s import K8sContext, PodEventSummary, PodLogSnippet, PodStatusSnapshot
+from app.services.rules import run_rule_analyzers
+
+
+def _event(reason: str, message: str, event_type: str = "Warning") -> PodEventSummary:
+    return PodEventSummary(
+        type=event_type,
+        reason=reason,
+        message=message,
+        count=2,
+        first_timestamp=None,
+        last_timestamp=None,
+        involved_object=None,
+    )
+
+
+def _context(
+    *,
+    container_statuses: list[dict[str, object]] | None = None,
+    events: list[PodEventSummary] | None = None,
+    phase: str = "Running",
+    reason: str | None = None,
+    conditions: list[dict[str, str | None]] | None = None,
+) -> K8sContext:
+    return K8sContext(
+        namespace="default",
+        pod_name="demo-pod",
+        workload=None,
+        pod_status=PodStatusSnapshot(
+            phase=phase,
+            node_name="node-1",
+            start_time=None,
+            reason=reason,
+            message=None,
+            conditions=conditions or [],
+            container_statuses=container_statuses or [],  # type: ignore[arg-type]
+        ),
+        events=events or [],
+        previous_logs=[],
+        warnings=[],
+    )
+
+
+def test_oom_killed_last_state_is_critical_finding() -> None:
+    context = _context(
+        container_statuses=[
+            {
+                "name": "app",
+                "ready": False,
+                "restart_count": 4,
+                "state": {"type": "waiting", "reason": "CrashLoopBackOff", "message": None},
+                "last_state": {
+                    "type": "terminated",
+                    "reason": "OOMKilled",
+                    "message": None,
+                    "exit_code": "137",
+                },
+            }
+        ]
+    )
+
+    findings = run_rule_analyzers(context)
+    rules = [finding.rule for finding in findings]
+
+    assert rules[:2] == ["oom_killed", "crash_loop_back_off"]
+    assert "non_zero_exit" not in rules
+    assert "exit_code=137" in findings[0].evidence[0]
+
+
+def test_image_pull_failure_from_events() -> None:
+    context = _context(
+        events=[_event("Failed", 'Failed to pull image "nginx:typo": not found')],
+    )
+
+    findings = run_rule_analyzers(context)
+
+    assert [finding.rule for finding in findings] == ["image_pull_failure"]
+
+
+def test_failed_scheduling_from_condition_and_event() -> None:
+    context = _context(
+        phase="Pending",
+        conditions=[
+            {
+                "type": "PodScheduled",
+                "status": "False",
+                "reason": "Unschedulable",
+                "message": "0/3 nodes are available: 3 Insufficient memory.",
+            }
+        ],
+        events=[_event("FailedScheduling", "0/3 nodes are available")],
+    )
+
+    findings = run_rule_analyzers(context)
+
+    assert findings[0].rule == "failed_scheduling"
+    assert len(findings[0].evidence) == 4
+    assert findings[0].evidence[2:] == [
+        "pod demo-pod fits 0 of 3 nodes: 3 rejected by insufficient_resource",
+        "3 of 3 nodes lack allocatable memory for the pod's requests; lower the requests, "
+        "free capacity or add nodes",
+    ]
+    assert findings[0].recommendation.startswith("Lower the pod's requests")
+
+
+def test_warning_findings_follow_critical_ones() -> None:
+    context = _context(
+        events=[
+            _event("Unhealthy", "Readiness probe failed: HTTP probe failed with statuscode: 503"),
+            _event("BackOff", "Back-off restarting failed container app"),
+        ],
+    )
+
+    findings = run_rule_analyzers(context)
+
+    assert [finding.severity for finding in findings] == ["critical", "warning"]
+
+
+def test_no_findings_for_healthy_pod() -> None:
+    context = _context(
+        container_statuses=[
+            {
+                "name": "app",
+                "ready": True,
+                "restart_count": 0,
+                "state": {"type": "running", "started_at": None},
+                "last_state": None,
+            }
+        ],
+        events=[_event("Pulled", "Successfully pulled image", event_type="Normal")],
+    )
+
+    assert run_rule_analyzers(context) == []
+
+
+def test_node_not_ready_is_critical_and_pressure_is_warning() -> None:
+    context = _context()
+    not_ready = replace(
+        context,
+        node_status={
+            "name": "node-1",
+            "conditions": [{"type": "Ready", "status": "False", "reason": "KubeletNotReady"}],
+        },
+    )
+    pressure = replace(
+        context,
+        node_status={
+            "name": "node-1",
+            "conditions": [
+                {"type": "Ready", "status": "True"},
+                {"type": "MemoryPressure", "status": "True"},
+            ],
+        },
+    )
+
+    [critical] = run_rule_analyzers(not_ready)
+    [warning] = run_rule_analyzers(pressure)
+
+    assert (critical.rule, critical.severity) == ("node_unhealthy", "critical")
+    assert critical.evidence == [
+        "node NotReady: kubelet reports the node not ready - KubeletNotReady"
+    ]
+    assert (warning.severity, warning.title) == ("warning", "Node node-1 reports resource pressure")
+
+
+def test_recent_rollout_is_a_warning_finding() -> None:
+    context = replace(
+        _context(),
+        recent_rollouts=[
+            {
+                "deployment": "api",
+                "revision": 7,
+                "replica_set": "api-7d9f8c",
+                "created": "2026-10-14T01:56:00+00:00",
+                "minutes_before_alert": 4.0,
+                "images": ["api:1.7"],
+            }
+        ],
+    )
+
+    [finding] = run_rule_analyzers(context)
+
+    assert finding.rule == "recent_rollout"
+    assert finding.severity == "warning"
+    assert finding.title == "Deployment api rolled out revision 7 4.0 minutes before the alert"
+    assert finding.evidence == [
+        "revision 7 (api-7d9f8c) created 2026-10-14T01:56:00+00:00: api:1.7"
+    ]
+
+
+def test_oom_killed_recommendation_names_container_and_suggested_limit() -> None:
+    context = replace(
+        _context(
+            container_statuses=[
+                {
+                    "name": "worker",
+                    "restart_count": 1,
+                    "state": {"type": "running"},
+                    "last_state": {"type": "terminated", "reason": "OOMKilled"},
+                }
+            ]
+        ),
+        pod_spec={
+            "containers": [{"name": "worker", "resources": {"limits": {"memory": "1Gi"}}}]
+        },
+    )
+
+    finding = run_rule_analyzers(context)[0]
+
+    assert finding.rule == "oom_killed"
+    assert "container worker was OOMKilled at its 1Gi memory limit" in finding.recommendation
+    assert "raise resources.limits.memory to 1536Mi" in finding.recommendation
+
+
+def test_crash_loop_recommendation_includes_crash_cause_from_previous_logs() -> None:
+    context = replace(
+        _context(
+            container_statuses=[
+                {
+                    "name": "api",
+                    "restart_count": 6,
+                    "state": {"type": "waiting", "reason": "CrashLoopBackOff", "message": None},
+                    "last_state": {"type": "terminated", "reason": "Error", "exit_code": "1"},
+                }
+            ]
+        ),
+        previous_logs=[
+            PodLogSnippet(
+                container="api",
+                previous=True,
+                logs=["2026-10-14T02:00:00Z FATAL: config file /etc/api/config.yaml not found"],
+            )
+        ],
+    )
+
+    finding = next(
+        item for item in run_rule_analyzers(context) if item.rule == "crash_loop_back_off"
+    )
+
+    assert any("back-off 300s" in item for item in finding.evidence)
+    assert finding.recommendation.startswith(
+        "Fix the crash in the previous container logs: "
+        "FATAL: config file /etc/api/config.yaml not found."
+    )
+    assert (
+        "container api crash classified as configuration: a file the process needs is missing "
+        "(signature missing_file: FATAL: config file /etc/api/config.yaml not found)"
+    ) in finding.evidence
+    assert "Mount the missing file (ConfigMap, Secret or volume)" in finding.recommendation
+
+
+def test_non_zero_exit_recommendation_follows_the_exit_code_meaning() -> None:
+    context = _context(
+        container_statuses=[
+            {
+                "name": "api",
+                "restart_count": 1,
+                "state": {"type": "running"},
+                "last_state": {"type": "terminated", "reason": "Error", "exit_code": "127"},
+            }
+        ]
+    )
+
+    finding = next(item for item in run_rule_analyzers(context) if item.rule == "non_zero_exit")
+
+    assert finding.evidence == [
+        "container api last_state=terminated reason=Error exit_code=127",
+        "container api crash classified as command_not_found: command not found",
+    ]
+    assert finding.recommendation.startswith(
+        "Fix command/args or add the binary to the image (entrypoint, PATH)."
+    )
+
+
+def test_image_pull_recommendation_follows_the_registry_error() -> None:
+    context = _context(
+        container_statuses=[
+            {
+                "name": "api",
+                "restart_count": 0,
+                "state": {
+                    "type": "waiting",
+                    "reason": "ErrImagePull",
+                    "message": "rpc error: code = Unknown desc = failed to pull and unpack image "
+                    '"docker.io/acme/api:v2": failed to resolve reference: pull access denied, '
+                    "repository does not exist or may require authorization: server message: "
+                    "insufficient_scope: authorization failed",
+                },
+                "last_state": None,
+            }
+        ]
+    )
+
+    finding = next(
+        item for item in run_rule_analyzers(context) if item.rule == "image_pull_failure"
+    )
+
+    assert (
+        "container api cannot pull image docker.io/acme/api:v2: registry docker.io denied "
+        "access to acme/api and the pod has no imagePullSecrets (or the repository does not "
+        "exist)"
+    ) in finding.evidence[-1]
+    assert finding.recommendation.startswith(
+        "Check that the imagePullSecrets hold valid credentials for the registry"
+    )
+    assert finding.recommendation.endswith(
+        "Verify the image name/tag exists, registry reachability and imagePullSecrets."
+    )
+
+
+def test_volume_rule_reports_nearly_full_claim_without_mount_events() -> None:
+    context = replace(
+        _context(),
+        volume_claims=[
+            {
+                "name": "data",
+                "phase": "Bound",
+                "storage_class": "standard",
+                "storage_class_detail": {"allow_volume_expansion": False},
+                "usage": {"used_bytes": 9 * 1024**3, "capacity_bytes": 10 * 1024**3},
+            }
+        ],
+    )
+
+    [finding] = run_rule_analyzers(context)
+
+    assert finding.rule == "volume_mount_failure"
+    assert finding.title == "Persistent volume claim is unbound, failing or nearly full"
+    assert finding.evidence == [
+        "claim data is 90.0% full (9.0Gi of 10.0Gi); StorageClass standard does not allow "
+        "expansion, free space or migrate the data to a larger volume"
+    ]
+
+
+def test_hpa_saturation_rule_reports_pinned_autoscaler() -> None:
+    context = replace(
+        _context(),
+        hpa_status={
+            "name": "api",
+            "target": {"kind": "Deployment", "name": "api"},
+            "max_replicas": 6,
+            "current_replicas": 6,
+            "desired_replicas": 6,
+            "events": [{"reason": "SuccessfulRescale", "message": "New size: 6"}],
+        },
+    )
+
+    [finding] = run_rule_analyzers(context)
+
+    assert finding.rule == "hpa_saturation"
+    assert finding.title == "HPA api is pinned at maxReplicas"
+    assert finding.evidence[0].startswith("HPA api is pinned at maxReplicas (6/6)")
+    assert finding.evidence[1] == "New size: 6"
+
+
+def test_resource_pressure_rule_reports_container_at_its_cpu_limit() -> None:
+    context = replace(
+        _context(),
+        pod_spec={
+            "containers": [
+                {"name": "app", "resources": {"requests": {"cpu": "250m"}, "limits": {"cpu": "1"}}}
+            ]
+        },
+        pod_metrics={"containers": [{"name": "app", "usage": {"cpu": "980m"}}]},
+    )
+
+    [finding] = run_rule_analyzers(context)
+
+    assert finding.rule == "resource_pressure"
+    assert finding.evidence == [
+        "container app uses 98.0% of its cpu limit (980m of 1000m); it is throttled by the "
+        "CFS quota, which shows up as latency"
+    ]
+
+
+def test_network_policy_rule_reports_blocked_egress() -> None:
+    context = replace(
+        _context(),
+        network_connectivity={
+            "source": {
+                "namespace": "default",
+                "pod": "demo-pod",
+                "labels": {"app": "demo"},
+                "namespace_labels": {"kubernetes.io/metadata.name": "default"},
+                "policies": [
+                    {
+                        "name": "deny-egress",
+                        "namespace": "default",
+                        "pod_selector": {"match_labels": {"app": "demo"}},
+                        "policy_types": ["Egress"],
+                        "egress": [
+                            {"to": [], "ports": [{"protocol": "UDP", "port": 53}]},
+                        ],
+                    }
+                ],
+            },
+            "destination": {
+                "namespace": "default",
+                "service": "redis",
+                "selector": {"app": "redis"},
+                "ports": [{"port": 6379, "target_port": 6379, "protocol": "TCP"}],
+                "namespace_labels": {"kubernetes.io/metadata.name": "default"},
+                "policies": [],
+            },
+        },
+    )
+
+    [finding] = run_rule_analyzers(context)
+
+    assert finding.rule == "network_policy_blocked"
+    assert finding.severity == "critical"
+    assert finding.title == "NetworkPolicy blocks traffic to default/redis"
+    assert finding.evidence == [
+        "egress from default/demo-pod to default/redis:6379 is blocked: NetworkPolicy "
+        "default/deny-egress selects the pod and none of its egress rules allow the "
+        'destination; missing allow rule: {"to": [{"podSelector": {"matchLabels": '
+        '{"app": "redis"}}}], "ports": [{"protocol": "TCP", "port": 6379}]}'
+    ]
+
+
+def test_endpoints_rule_reports_services_without_ready_endpoints() -> None:
+    context = replace(
+        _context(),
+        service_endpoints={
+            "namespace": "default",
+            "service": "redis",
+            "found": True,
+            "selector": {"app": "redis"},
+            "ports": [],
+            "endpoints": [],
+            "pods": [],
+            "near_misses": [],
+        },
+    )
+
+    [finding] = run_rule_analyzers(context)
+
+    assert finding.rule == "endpoints_unavailable"
+    assert finding.severity == "critical"
+    assert finding.title == "Service default/redis has no ready endpoints"
+    assert finding.evidence == [
+        "service default/redis has no endpoints: its selector app=redis matches no pod in the "
+        "namespace, and no pod carries all of its label keys"
+    ]
+
+
+def test_job_rule_reports_jobs_stopped_at_their_deadline() -> None:
+    context = replace(
+        _context(),
+        job_run={
+            "namespace": "default",
+            "job": "reindex",
+            "cron_job": None,
+            "found": True,
+            "recent_runs": [],
+            "spec": {"backoff_limit": 6, "active_deadline_seconds": 600},
+            "status": {
+                "name": "reindex",
+                "start_time": "2026-10-14T09:00:00+00:00",
+                "active": 0,
+                "failed": 1,
+                "conditions": [
+                    {
+                        "type": "Failed",
+                        "status": "True",
+                        "reason": "DeadlineExceeded",
+                        "last_transition_time": "2026-10-14T09:10:00+00:00",
+                    }
+                ],
+            },
+            "pods": [],
+            "logs": [],
+        },
+    )
+
+    [finding] = run_rule_analyzers(context)
+
+    assert finding.rule == "job_failed"
+    assert finding.severity == "critical"
+    assert finding.title == "Job default/reindex failed"
+    assert finding.evidence == [
+        "job default/reindex was stopped after exceeding activeDeadlineSeconds=600 (ran 600s)"
+    ]
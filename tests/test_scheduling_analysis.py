@@ -0,0 +1,143 @@
+from __future__ import annotations
+
+from app.models.k8s import K8sContext, PodEventSummary, PodStatusSnapshot
+from app.schemas.analysis import SchedulingAnalysis
+from app.services.scheduling_analysis import build_scheduling_analysis
+
+
+def _event(message: str, last_timestamp: str | None = None) -> PodEventSummary:
+    return PodEventSummary(
+        type="Warning",
+        reason="FailedScheduling",
+        message=message,
+        count=4,
+        first_timestamp=None,
+        last_timestamp=last_timestamp,
+        involved_object={"kind": "Pod", "name": "worker-0", "namespace": "batch"},
+    )
+
+
+def _context(
+    *,
+    phase: str = "Pending",
+    events: list[PodEventSummary] | None = None,
+    conditions: list[dict[str, str | None]] | None = None,
+    tolerations: list[dict[str, object]] | None = None,
+) -> K8sContext:
+    return K8sContext(
+        namespace="batch",
+        pod_name="worker-0",
+        workload="worker",
+        pod_status=PodStatusSnapshot(
+            phase=phase,
+            node_name=None,
+            start_time=None,
+            reason=None,
+            message=None,
+            conditions=conditions or [],
+            container_statuses=[],
+        ),
+        events=events or [],
+        previous_logs=[],
+        warnings=[],
+        pod_spec={
+            "tolerations": tolerations or [],
+            "containers": [
+                {"name": "worker", "resources": {"requests": {"cpu": "4", "memory": "8Gi"}}},
+                {"name": "sidecar", "resources": {"requests": {"cpu": "100m"}}},
+            ],
+        },
+    )
+
+
+def test_scheduling_analysis_counts_rejected_nodes_per_constraint() -> None:
+    message = (
+        "0/6 nodes are available: 2 Insufficient cpu, 1 node(s) had untolerated taint "
+        "{node-role.kubernetes.io/control-plane: }, 3 node(s) had volume node affinity "
+        "conflict. preemption: 0/6 nodes are available: 6 Preemption is not helpful for "
+        "scheduling."
+    )
+    context = _context(
+        events=[
+            _event("0/6 nodes are available: 6 Insufficient cpu.", "2026-10-14T09:00:00Z"),
+            _event(message, "2026-10-14T09:05:00Z"),
+        ],
+        tolerations=[
+            {"key": "node-role.kubernetes.io/control-plane", "effect": "PreferNoSchedule"}
+        ],
+    )
+
+    analysis = build_scheduling_analysis(context)
+
+    assert analysis is not None
+    assert (analysis["available_nodes"], analysis["total_nodes"]) == (0, 6)
+    assert analysis["constraints"] == [
+        {
+            "kind": "volume_node_affinity_conflict",
+            "nodes": 3,
+            "message": "node(s) had volume node affinity conflict",
+        },
+        {
+            "kind": "insufficient_resource",
+            "nodes": 2,
+            "message": "Insufficient cpu",
+            "resource": "cpu",
+        },
+        {
+            "kind": "untolerated_taint",
+            "nodes": 1,
+            "message": "node(s) had untolerated taint {node-role.kubernetes.io/control-plane: }",
+            "taints": [{"key": "node-role.kubernetes.io/control-plane", "value": None}],
+            "mismatched_tolerations": ["node-role.kubernetes.io/control-plane"],
+        },
+    ]
+    assert analysis["findings"] == [
+        "pod worker-0 fits 0 of 6 nodes: 3 rejected by volume_node_affinity_conflict; "
+        "2 rejected by insufficient_resource; 1 rejected by untolerated_taint",
+        "3 of 6 nodes are outside the node affinity (usually the zone) of a bound "
+        "PersistentVolume; the pod can only run where its volume is",
+        "2 of 6 nodes lack allocatable cpu for the pod's requests (worker 4, sidecar 100m); "
+        "lower the requests, free capacity or add nodes",
+        "1 of 6 nodes have taints the pod does not tolerate: "
+        "node-role.kubernetes.io/control-plane; the pod tolerates "
+        "node-role.kubernetes.io/control-plane only with another value or effect",
+        "preemption cannot help: evicting lower-priority pods frees no fitting node",
+    ]
+    parsed = SchedulingAnalysis.model_validate(analysis)
+    assert parsed.constraints[1].resource == "cpu"
+
+
+def test_scheduling_analysis_reports_unbound_claims_checked_before_nodes() -> None:
+    context = _context(
+        conditions=[
+            {
+                "type": "PodScheduled",
+                "status": "False",
+                "reason": "Unschedulable",
+                "message": "pod has unbound immediate PersistentVolumeClaims. preemption: "
+                "0/3 nodes are available: 3 Preemption is not helpful for scheduling.",
+            }
+        ],
+    )
+
+    analysis = build_scheduling_analysis(context)
+
+    assert analysis is not None
+    assert analysis["total_nodes"] is None
+    assert analysis["pod_constraints"] == [
+        {"kind": "unbound_pvc", "message": "pod has unbound immediate PersistentVolumeClaims."}
+    ]
+    assert analysis["findings"] == [
+        "pod worker-0 cannot be scheduled on any node: pod has unbound immediate "
+        "PersistentVolumeClaims."
+    ]
+
+
+def test_scheduling_analysis_skips_scheduled_pods() -> None:
+    context = _context(
+        phase="Running",
+        events=[_event("0/3 nodes are available: 3 Insufficient memory.")],
+    )
+
+    assert build_scheduling_analysis(context) is None
+    assert build_scheduling_analysis(_context()) is None
@@ -0,0 +1,122 @@
+from __future__ import annotations
+
+import json
+import re
+from pathlib import Path
+
+import pytest
+
+import app.core.config as config
+import app.core.secret_sources as secret_sources
+from app.core.config import SECRET_SETTING_NAMES, load_settings
+from app.core.secret_sources import get_secret_env, secret_fingerprint
+
+
+class _FakeHTTPResponse:
+    def __init__(self, payload: dict[str, object]) -> None:
+        self._body = json.dumps(payload).encode("utf-8")
+
+    def read(self) -> bytes:
+        return self._body
+
+    def __enter__(self) -> _FakeHTTPResponse:
+        return self
+
+    def __exit__(self, exc_type, exc, tb) -> None:  # type: ignore[no-untyped-def]
+        return None
+
+
+@pytest.fixture(autouse=True)
+def _reset_vault_token():
+    secret_sources.reset_vault_token()
+    yield
+    secret_sources.reset_vault_token()
+
+
+def test_file_source_takes_precedence_over_env(
+    monkeypatch: pytest.MonkeyPatch, tmp_path: Path
+) -> None:
+    secret_file = tmp_path / "gemini"
+    secret_file.write_text("file-key\n", encoding="utf-8")
+    monkeypatch.setenv("GEMINI_API_KEY", "env-key")
+    monkeypatch.setenv("GEMINI_API_KEY_FILE", str(secret_file))
+
+    assert get_secret_env("GEMINI_API_KEY") == "file-key"
+    assert load_settings().gemini_api_key == "file-key"
+
+
+def test_missing_secret_file_raises(monkeypatch: pytest.MonkeyPatch, tmp_path: Path) -> None:
+    monkeypatch.setenv("OPENAI_API_KEY_FILE", str(tmp_path / "missing"))
+
+    with pytest.raises(ValueError, match="OPENAI_API_KEY_FILE"):
+        get_secret_env("OPENAI_API_KEY")
+
+
+def test_vault_reference_reads_kv_v2(monkeypatch: pytest.MonkeyPatch) -> None:
+    captured: dict[str, object] = {}
+
+    def fake_urlopen(request, timeout=0):  # type: ignore[no-untyped-def]
+        captured["url"] = request.full_url
+        captured["headers"] = dict(request.headers)
+        return _FakeHTTPResponse({"data": {"data": {"gemini_api_key": "vault-key"}}})
+
+    monkeypatch.setattr(secret_sources.urllib.request, "urlopen", fake_urlopen)
+    monkeypatch.setenv("VAULT_ADDR", "http://vault:8200/")
+    monkeypatch.setenv("VAULT_TOKEN", "root-token")
+    monkeypatch.setenv("GEMINI_API_KEY", "vault:secret/data/kube-rca#gemini_api_key")
+
+    assert get_secret_env("GEMINI_API_KEY") == "vault-key"
+    assert captured["url"] == "http://vault:8200/v1/secret/data/kube-rca"
+    assert captured["headers"]["X-vault-token"] == "root-token"
+
+
+def test_vault_kubernetes_login_is_cached(
+    monkeypatch: pytest.MonkeyPatch, tmp_path: Path
+) -> None:
+    urls: list[str] = []
+
+    def fake_urlopen(request, timeout=0):  # type: ignore[no-untyped-def]
+        urls.append(request.full_url)
+        if request.full_url.endswith("/auth/kubernetes/login"):
+            return _FakeHTTPResponse({"auth": {"client_token": "k8s-token"}})
+        return _FakeHTTPResponse({"data": {"data": {"password": "db-secret"}}})
+
+    sa_token = tmp_path / "token"
+    sa_token.write_text("jwt", encoding="utf-8")
+    monkeypatch.setattr(secret_sources.urllib.request, "urlopen", fake_urlopen)
+    monkeypatch.setenv("VAULT_ADDR", "http://vault:8200")
+    monkeypatch.delenv("VAULT_TOKEN", raising=False)
+    monkeypatch.setenv("VAULT_K8S_ROLE", "kube-rca-agent")
+    monkeypatch.setenv("VAULT_K8S_TOKEN_PATH", str(sa_token))
+    monkeypatch.setenv("SESSION_DB_PASSWORD", "vault:secret/data/db#password")
+
+    assert get_secret_env("SESSION_DB_PASSWORD") == "db-secret"
+    assert get_secret_env("SESSION_DB_PASSWORD") == "db-secret"
+    assert urls.count("http://vault:8200/v1/auth/kubernetes/login") == 1
+
+
+def test_vault_reference_requires_key(monkeypatch: pytest.MonkeyPatch) -> None:
+    monkeypatch.setenv("ANTHROPIC_API_KEY", "vault:secret/data/kube-rca")
+
+    with pytest.raises(ValueError, match="vault:<path>#<key>"):
+        get_secret_env("ANTHROPIC_API_KEY")
+
+
+def test_fingerprint_changes_when_file_rotates(
+    monkeypatch: pytest.MonkeyPatch, tmp_path: Path
+) -> None:
+    secret_file = tmp_path / "anthropic"
+    secret_file.write_text("old", encoding="utf-8")
+    monkeypatch.setenv("ANTHROPIC_API_KEY_FILE", str(secret_file))
+
+    before = secret_fingerprint(SECRET_SETTING_NAMES)
+    secret_file.write_text("new", encoding="utf-8")
+
+    assert secret_fingerprint(SECRET_SETTING_NAMES) != before
+
+
+def test_every_secret_read_by_the_settings_loader_is_marked_secret() -> None:
+    source = Path(config.__file__).read_text(encoding="utf-8")
+    read = set(re.findall(r'get_secret_env\(\s*"([A-Z0-9_]+)"', source))
+
+    assert read == set(SECRET_SETTING_NAMES)
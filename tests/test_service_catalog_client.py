@@ -0,0 +1,138 @@
+from __future__ import annotations
+
+import io
+import json
+import urllib.error
+
+import pytest
+
+import app.clients.service_catalog as service_catalog_module
+from app.clients.service_catalog import ServiceCatalogClient
+from app.core.config import load_settings
+
+
+class _FakeHTTPResponse:
+    def __init__(self, payload: object) -> None:
+        self._body = json.dumps(payload).encode("utf-8")
+
+    def read(self) -> bytes:
+        return self._body
+
+    def __enter__(self) -> _FakeHTTPResponse:
+        return self
+
+    def __exit__(self, exc_type, exc, tb) -> None:  # type: ignore[no-untyped-def]
+        return None
+
+
+def _client(monkeypatch: pytest.MonkeyPatch, provider: str, url: str) -> ServiceCatalogClient:
+    monkeypatch.setenv("SERVICE_CATALOG_PROVIDER", provider)
+    monkeypatch.setenv("SERVICE_CATALOG_URL", url)
+    return ServiceCatalogClient(load_settings())
+
+
+def test_backstage_component_is_normalized(monkeypatch: pytest.MonkeyPatch) -> None:
+    monkeypatch.setenv("SERVICE_CATALOG_TOKEN", "backstage-token")
+    client = _client(monkeypatch, "backstage", "https://backstage.example.com/")
+    entity = {
+        "metadata": {
+            "name": "checkout",
+            "description": "Checkout API",
+            "labels": {"tier": "tier-1"},
+            "annotations": {"example.com/runbook-url": "https://wiki.example.com/checkout"},
+            "links": [
+                {"url": "https://grafana.example.com/d/checkout", "title": "Dashboard"},
+                {"url": "https://wiki.example.com/checkout/oncall", "title": "On-call runbook"},
+            ],
+        },
+        "spec": {
+            "type": "service",
+            "owner": "group:payments",
+            "lifecycle": "production",
+            "system": "shop",
+            "dependsOn": ["resource:payments-db", "component:fraud-check"],
+        },
+    }
+    requests: list[tuple[str, str | None]] = []
+
+    def fake_urlopen(request, timeout=0):  # type: ignore[no-untyped-def]
+        requests.append((request.full_url, request.get_header("Authorization")))
+        return _FakeHTTPResponse(entity)
+
+    monkeypatch.setattr(service_catalog_module.urllib.request, "urlopen", fake_urlopen)
+
+    entry = client.lookup("checkout", "shop")
+    client.lookup("checkout", "shop")
+
+    assert requests == [
+        (
+            "https://backstage.example.com/api/catalog/entities/by-name/component/default/checkout",
+            "Bearer backstage-token",
+        )
+    ]
+    assert entry == {
+        "source": "backstage",
+        "service": "checkout",
+        "tier": "tier-1",
+        "owner": "group:payments",
+        "lifecycle": "production",
+        "system": "shop",
+        "description": "Checkout API",
+        "dependencies": ["resource:payments-db", "component:fraud-check"],
+        "runbooks": [
+            {"title": "On-call runbook", "url": "https://wiki.example.com/checkout/oncall"},
+            {"title": "example.com/runbook-url", "url": "https://wiki.example.com/checkout"},
+        ],
+    }
+
+
+def test_http_lookup_maps_configured_fields(monkeypatch: pytest.MonkeyPatch) -> None:
+    monkeypatch.setenv(
+        "SERVICE_CATALOG_FIELDS_JSON",
+        '{"tier": "attributes.tier", "owner": "support_group.name", '
+        '"dependencies": "relations.depends_on", "runbooks": "attributes.runbook"}',
+    )
+    client = _client(
+        monkeypatch, "http", "https://cmdb.example.com/api/ci/{service}?environment={namespace}"
+    )
+    payload = {
+        "attributes": {"tier": "gold", "runbook": "https://wiki.example.com/checkout"},
+        "support_group": {"name": "Payments"},
+        "relations": {"depends_on": [{"name": "payments-db"}]},
+    }
+    urls: list[str] = []
+
+    def fake_urlopen(request, timeout=0):  # type: ignore[no-untyped-def]
+        urls.append(request.full_url)
+        return _FakeHTTPResponse(payload)
+
+    monkeypatch.setattr(service_catalog_module.urllib.request, "urlopen", fake_urlopen)
+
+    entry = client.lookup("checkout api", "shop")
+
+    assert urls == ["https://cmdb.example.com/api/ci/checkout%20api?environment=shop"]
+    assert entry is not None
+    assert entry["tier"] == "gold"
+    assert entry["owner"] == "Payments"
+    assert entry["dependencies"] == ["payments-db"]
+    assert entry["runbooks"] == [{"title": None, "url": "https://wiki.example.com/checkout"}]
+
+
+def test_unknown_services_are_none_and_errors_are_raised(
+    monkeypatch: pytest.MonkeyPatch,
+) -> None:
+    client = _client(monkeypatch, "http", "https://cmdb.example.com/services/{service}")
+
+    def not_found(request, timeout=0):  # type: ignore[no-untyped-def]
+        raise urllib.error.HTTPError(request.full_url, 404, "Not Found", {}, io.BytesIO())
+
+    monkeypatch.setattr(service_catalog_module.urllib.request, "urlopen", not_found)
+    assert client.lookup("checkout") is None
+
+    def unavailable(request, timeout=0):  # type: ignore[no-untyped-def]
+        raise urllib.error.HTTPError(request.full_url, 503, "Unavailable", {}, io.BytesIO())
+
+    monkeypatch.setattr(service_catalog_module.urllib.request, "urlopen", unavailable)
+    with pytest.raises(urllib.error.HTTPError):
+        client.lookup("cart")
+    assert _client(monkeypatch, "servicenow", "https://cmdb.example.com").enabled is False
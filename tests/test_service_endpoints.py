@@ -0,0 +1,168 @@
+from __future__ import annotations
+
+from app.models.k8s import K8sContext
+from app.schemas.analysis import EndpointReadiness
+from app.services.service_endpoints import build_endpoint_readiness, endpoints_target
+
+
+def _pod(
+    name: str,
+    *,
+    ready: bool,
+    state: dict[str, object] | None = None,
+    probe_failures: list[str] | None = None,
+    ports: list[dict[str, object]] | None = None,
+) -> dict[str, object]:
+    return {
+        "name": name,
+        "phase": "Running",
+        "ready": ready,
+        "ready_message": None if ready else "containers with unready status: [app]",
+        "terminating": False,
+        "node": "node-1",
+        "containers": [
+            {
+                "name": "app",
+                "ready": ready,
+                "restart_count": 0,
+                "state": state or {"type": "running", "started_at": "2026-10-14T09:00:00+00:00"},
+                "ports": ports if ports is not None else [{"name": "http", "port": 8080}],
+                "readiness_probe": {"type": "httpGet", "path": "/ready", "port": "http"},
+            }
+        ],
+        "probe_failures": probe_failures or [],
+    }
+
+
+def _endpoint(pod: str, *, ready: bool) -> dict[str, object]:
+    return {
+        "addresses": ["10.0.0.1"],
+        "ready": ready,
+        "serving": ready,
+        "terminating": False,
+        "pod": pod,
+        "node": "node-1",
+        "zone": None,
+    }
+
+
+def _context(**service_endpoints: object) -> K8sContext:
+    return K8sContext(
+        namespace="shop",
+        pod_name="checkout-7d9f8c-abcde",
+        workload="checkout",
+        pod_status=None,
+        events=[],
+        previous_logs=[],
+        warnings=[],
+        service_endpoints={
+            "namespace": "shop",
+            "service": "payments",
+            "found": True,
+            "selector": {"app": "payments"},
+            "ports": [{"name": "http", "port": 80, "target_port": "http", "protocol": "TCP"}],
+            "endpoints": [],
+            "pods": [],
+            "near_misses": [],
+            **service_endpoints,
+        },
+    )
+
+
+def test_endpoint_readiness_reports_a_selector_matching_no_pod() -> None:
+    readiness = build_endpoint_readiness(
+        _context(
+            selector={"app": "payments", "track": "stable"},
+            near_misses=[{"name": "payments-5c8d-x2k4p", "mismatched": {"track": "canary"}}],
+        )
+    )
+
+    assert readiness is not None
+    assert readiness["verdict"] == "unavailable"
+    assert readiness["cause"] == "selector_mismatch"
+    assert readiness["findings"] == [
+        "service shop/payments has no endpoints: its selector app=payments,track=stable "
+        "matches no pod in the namespace; pods with the same label keys differ in "
+        "payments-5c8d-x2k4p (track=canary)"
+    ]
+
+
+def test_endpoint_readiness_reports_pods_failing_their_readiness_probes() -> None:
+    failure = "Readiness probe failed: HTTP probe failed with statuscode: 503"
+    readiness = build_endpoint_readiness(
+        _context(
+            endpoints=[_endpoint("payments-a", ready=False), _endpoint("payments-b", ready=False)],
+            pods=[
+                _pod("payments-a", ready=False, probe_failures=[failure]),
+                _pod("payments-b", ready=False),
+            ],
+        )
+    )
+
+    assert readiness is not None
+    assert readiness["verdict"] == "unavailable"
+    assert readiness["cause"] == "readiness_probe_failing"
+    assert (readiness["ready"], readiness["not_ready"], readiness["selected_pods"]) == (0, 2, 2)
+    assert readiness["findings"] == [
+        "service shop/payments has 0 of 2 endpoints ready: all 2 selected pods fail their "
+        "readiness probes (payments-a: container app fails its readiness probe (httpGet /ready "
+        f"on port http): {failure}; payments-b: container app fails its readiness probe "
+        "(httpGet /ready on port http))"
+    ]
+    assert EndpointReadiness.model_validate(readiness).not_ready_pods[0].pod == "payments-a"
+
+
+def test_endpoint_readiness_reports_degraded_services_and_undeclared_target_ports() -> None:
+    crashing = {"type": "waiting", "reason": "CrashLoopBackOff", "message": None}
+    degraded = build_endpoint_readiness(
+        _context(
+            endpoints=[_endpoint("payments-a", ready=True), _endpoint("payments-b", ready=False)],
+            pods=[
+                _pod("payments-a", ready=True),
+                _pod("payments-b", ready=False, state=crashing),
+            ],
+        )
+    )
+    port_mismatch = build_endpoint_readiness(
+        _context(pods=[_pod("payments-a", ready=True, ports=[{"name": "web", "port": 8080}])])
+    )
+    healthy = build_endpoint_readiness(
+        _context(
+            endpoints=[_endpoint("payments-a", ready=True)],
+            pods=[_pod("payments-a", ready=True)],
+        )
+    )
+
+    assert degraded is not None
+    assert degraded["verdict"] == "degraded"
+    assert degraded["cause"] == "pods_crashing"
+    assert degraded["not_ready_pods"] == [
+        {
+            "pod": "payments-b",
+            "reason": "container_not_running",
+            "detail": "container app waiting: CrashLoopBackOff",
+        }
+    ]
+    assert port_mismatch is not None
+    assert port_mismatch["cause"] == "target_port_mismatch"
+    assert port_mismatch["findings"] == [
+        "service shop/payments port 80 targets the named port http, which no container of the "
+        "selected pods declares; that port has no endpoints"
+    ]
+    assert healthy is not None
+    assert healthy["verdict"] == "healthy"
+    assert healthy["findings"] == []
+
+
+def test_endpoints_target_reads_service_labels_of_error_and_unavailability_alerts() -> None:
+    context = _context()
+
+    assert endpoints_target(
+        {"alertname": "HighHTTP5xxRate", "service": "payments", "namespace": "shop"}, context
+    ) == {"namespace": "shop", "service": "payments"}
+    assert endpoints_target(
+        {"alertname": "HighLatency", "destination_service": "reviews.bookinfo.svc.cluster.local"},
+        context,
+    ) == {"namespace": "bookinfo", "service": "reviews"}
+    assert endpoints_target({"alertname": "KubePodCrashLooping", "service": "api"}, context) is None
+    assert endpoints_target({"alertname": "ServiceDown"}, context) is None
@@ -0,0 +1,96 @@
+from __future__ import annotations
+
+import threading
+
+from app.clients.shadow_store import ShadowResult
+from app.services.shadow import ShadowAnalysisRunner
+
+
+class FakeShadowStore:
+    def __init__(self) -> None:
+        self.results: list[ShadowResult] = []
+        self.recorded = threading.Event()
+
+    def record(self, result: ShadowResult) -> None:
+        self.results.append(result)
+        self.recorded.set()
+
+    def list_results(
+        self, *, limit: int = 50, alertname: str | None = None
+    ) -> list[dict[str, object]]:
+        return []
+
+
+class BlockingEngine:
+    def __init__(self, answer: str = "shadow answer", error: Exception | None = None) -> None:
+        self.release = threading.Event()
+        self.session_ids: list[str | None] = []
+        self._answer = answer
+        self._error = error
+
+    def analyze(self, prompt: str, incident_id: str | None = None) -> str:
+        self.session_ids.append(incident_id)
+        self.release.wait(5)
+        if self._error is not None:
+            raise self._error
+        return self._answer
+
+
+def _submit(runner: ShadowAnalysisRunner) -> bool:
+    return runner.submit(
+        "prompt",
+        analysis_id="alert:abc:run:1234abcd",
+        session_key="alert:abc",
+        alertname="KubePodCrashLooping",
+        namespace="default",
+        primary_analysis="primary answer",
+        primary_ms=1234.56,
+    )
+
+
+def test_shadow_run_stores_both_answers_in_a_separate_session() -> None:
+    engine = BlockingEngine()
+    store = FakeShadowStore()
+    runner = ShadowAnalysisRunner(
+        engine, store, primary_model="gemini/a", shadow_model="openai/b"
+    )
+
+    assert _submit(runner) is True
+    engine.release.set()
+    assert store.recorded.wait(5)
+
+    result = store.results[0]
+    assert engine.session_ids == ["alert:abc:run:1234abcd:shadow"]
+    assert result.primary_analysis == "primary answer"
+    assert result.shadow_analysis == "shadow answer"
+    assert result.shadow_model == "openai/b"
+    assert result.primary_ms == 1234.6
+    assert result.shadow_error is None
+
+
+def test_shadow_run_is_skipped_when_all_slots_are_busy() -> None:
+    engine = BlockingEngine()
+    store = FakeShadowStore()
+    runner = ShadowAnalysisRunner(
+        engine, store, primary_model="gemini/a", shadow_model="openai/b", max_concurrency=1
+    )
+
+    assert _submit(runner) is True
+    assert _submit(runner) is False
+    engine.release.set()
+    assert store.recorded.wait(5)
+
+
+def test_shadow_engine_failure_is_recorded_as_error() -> None:
+    engine = BlockingEngine(error=RuntimeError("quota exceeded"))
+    engine.release.set()
+    store = FakeShadowStore()
+    runner = ShadowAnalysisRunner(
+        engine, store, primary_model="gemini/a", shadow_model="openai/b"
+    )
+
+    _submit(runner)
+    assert store.recorded.wait(5)
+
+    assert store.results[0].shadow_error == "RuntimeError: quota exceeded"
+    assert store.results[0].shadow_analysis == ""
@@ -0,0 +1,186 @@
+from __future__ import annotations
+
+import hashlib
+from datetime import datetime
+from typing import cast
+
+import pytest
+
+from app.clients.analysis_store import PostgresAnalysisStore, StoredAnalysis
+from app.core.config import load_settings
+from app.core.signing import RecordSigner, build_record_signer, canonical_json, key_id_for
+from app.schemas.analysis import AlertAnalysisResponse
+
+
+def _record() -> dict[str, object]:
+    return {
+        "status": "ok",
+        "thread_ts": "1700000000.000100",
+        "analysis": "OOMKilled due to memory limit",
+        "context": {"namespace": "default", "warnings": ["a", "b"]},
+    }
+
+
+def test_signed_record_verifies() -> None:
+    signer = RecordSigner(["secret-key"])
+
+    signature = signer.sign(_record())
+
+    assert signature["algorithm"] == "hmac-sha256"
+    assert signer.verify(_record(), signature) is True
+
+
+def test_modified_record_fails_verification() -> None:
+    signer = RecordSigner(["secret-key"])
+    signature = signer.sign(_record())
+
+    tampered = _record()
+    tampered["analysis"] = "Network policy blocked traffic"
+
+    assert signer.verify(tampered, signature) is False
+
+
+def test_modified_signature_metadata_fails_verification() -> None:
+    signer = RecordSigner(["secret-key"])
+    signature = signer.sign(_record())
+
+    backdated = {**signature, "signed_at": "2020-01-01T00:00:00+00:00"}
+
+    assert signer.verify(_record(), backdated) is False
+    assert signer.verify(_record(), {**signature, "key_id": "unknown"}) is False
+    assert signer.verify(_record(), {**signature, "algorithm": "none"}) is False
+
+
+def test_rotated_keys_still_verify_old_records() -> None:
+    old_signer = RecordSigner(["old-key"])
+    signature = old_signer.sign(_record())
+
+    rotated = RecordSigner(["new-key", "old-key"])
+
+    assert rotated.verify(_record(), signature) is True
+    assert rotated.sign(_record())["key_id"] != signature["key_id"]
+
+
+def test_key_id_does_not_reveal_a_hash_of_the_key() -> None:
+    signature = RecordSigner(["secret-key"]).sign(_record())
+
+    assert signature["key_id"] == key_id_for("secret-key")
+    assert signature["key_id"] != hashlib.sha256(b"secret-key").hexdigest()[:12]
+
+
+class _FakeCursor:
+    def __init__(self, rows: list[dict[str, object]]) -> None:
+        self._rows = rows
+        self._row: dict[str, object] | None = None
+
+    def __enter__(self) -> _FakeCursor:
+        return self
+
+    def __exit__(self, *args: object) -> None:
+        return None
+
+    def execute(self, query: str, params: tuple[object, ...]) -> None:
+        if query.lstrip().startswith("INSERT"):
+            request, result, signature = params[-3:]
+            row = {"result_id": 1, "request": request, "result": result, "signature": signature}
+            self._rows.append(row)
+        self._row = self._rows[-1]
+
+    def fetchone(self) -> dict[str, object] | None:
+        return self._row
+
+
+class _FakeConnection:
+    def __init__(self, rows: list[dict[str, object]]) -> None:
+        self._rows = rows
+
+    def __enter__(self) -> _FakeConnection:
+        return self
+
+    def __exit__(self, *args: object) -> None:
+        return None
+
+    def cursor(self) -> _FakeCursor:
+        return _FakeCursor(self._rows)
+
+
+def _stored_analysis(result: dict[str, object]) -> StoredAnalysis:
+    return StoredAnalysis(
+        session_key="alert:abc",
+        analysis_id="alert:abc:run:1",
+        alertname="PodOOMKilled",
+        namespace="default",
+        fingerprint="abc",
+        incident_id=None,
+        alert_status="firing",
+        pipeline_version="v1",
+        source="live",
+        request={"alert": {"status": "firing"}},
+        result=result,
+    )
+
+
+def test_stored_results_are_signed_as_written() -> None:
+    signer = RecordSigner(["secret-key"])
+    rows: list[dict[str, object]] = []
+    store = PostgresAnalysisStore.__new__(PostgresAnalysisStore)
+    store._cipher = None
+    store._signer = signer
+    store._connect = lambda: _FakeConnection(rows)  # type: ignore[method-assign]
+
+    store.record(_stored_analysis({"analysis": "done", "context": {"at": datetime(2026, 1, 1)}}))
+    row = store.get_result(1)
+
+    assert row is not None
+    assert row["result"] == {"analysis": "done", "context": {"at": "2026-01-01 00:00:00"}}
+    signature = cast(dict[str, object], row["signature"])
+    assert signer.verify(cast(dict[str, object], row["result"]), signature) is True
+    assert signer.verify({"analysis": "edited", "context": {}}, signature) is False
+
+
+def test_stored_results_are_unsigned_without_keys() -> None:
+    rows: list[dict[str, object]] = []
+    store = PostgresAnalysisStore.__new__(PostgresAnalysisStore)
+    store._cipher = None
+    store._signer = None
+    store._connect = lambda: _FakeConnection(rows)  # type: ignore[method-assign]
+
+    store.record(_stored_analysis({"analysis": "done"}))
+
+    assert rows[0]["signature"] is None
+
+
+def test_key_ids_of_earlier_releases_are_not_accepted() -> None:
+    signer = RecordSigner(["secret-key"])
+    legacy_id = hashlib.sha256(b"secret-key").hexdigest()[:12]
+    signature = {**signer.sign(_record()), "key_id": legacy_id}
+
+    assert signer.verify(_record(), signature) is False
+
+
+def test_canonical_json_ignores_key_order() -> None:
+    assert canonical_json({"b": 1, "a": [1, 2]}) == canonical_json({"a": [1, 2], "b": 1})
+
+
+def test_signature_covers_serialized_response() -> None:
+    signer = RecordSigner(["secret-key"])
+    response = AlertAnalysisResponse(status="ok", thread_ts="1", analysis="done")
+    record = response.model_dump(mode="json", exclude={"signature"})
+
+    signature = signer.sign(record)
+
+    assert signer.verify(response.model_dump(mode="json", exclude={"signature"}), signature)
+
+
+def test_build_record_signer_disabled_without_keys() -> None:
+    assert build_record_signer(()) is None
+    with pytest.raises(ValueError):
+        RecordSigner([])
+
+
+def test_signing_keys_from_env(monkeypatch: pytest.MonkeyPatch) -> None:
+    monkeypatch.setenv("ANALYSIS_SIGNING_KEYS", "new-key, old-key")
+
+    settings = load_settings()
+
+    assert settings.analysis_signing_keys == ("new-key", "old-key")
@@ -0,0 +1,177 @@
+from __future__ import annotations
+
+import asyncio
+import dataclasses
+import hashlib
+import hmac
+import json
+import time
+from types import SimpleNamespace
+from typing import Any
+
+import pytest
+from fastapi import HTTPException
+
+from app.api.slack import verify_slack_request
+from app.core.config import load_settings
+from app.schemas.analysis import AnalysisFollowupRequest
+from app.services.slack_interactions import SlackInteractionService
+
+
+class FakeAnalysisService:
+    def __init__(self) -> None:
+        self.calls: list[tuple[str, AnalysisFollowupRequest]] = []
+
+    def follow_up(self, analysis_id: str, request: AnalysisFollowupRequest) -> str:
+        self.calls.append((analysis_id, request))
+        return "answer"
+
+
+def _block_action(action_id: str, value: str) -> dict[str, object]:
+    return {
+        "type": "block_actions",
+        "user": {"id": "U123"},
+        "actions": [{"action_id": action_id, "value": value}],
+        "message": {"ts": "200.000", "thread_ts": "100.000"},
+    }
+
+
+def test_dig_deeper_button_runs_follow_up_in_the_message_thread() -> None:
+    analysis = FakeAnalysisService()
+    service = SlackInteractionService(analysis)
+
+    result = service.handle(_block_action("dig_deeper", "alert:abc:run:1234abcd"))
+
+    assert result["status"] == "ok"
+    assert result["thread_ts"] == "100.000"
+    assert result["text"] == "answer"
+    analysis_id, request = analysis.calls[0]
+    assert analysis_id == "alert:abc:run:1234abcd"
+    assert request.thread_ts == "100.000"
+    assert "root cause" in request.question
+
+
+def test_form_encoded_payload_and_json_button_value_are_decoded() -> None:
+    analysis = FakeAnalysisService()
+    service = SlackInteractionService(analysis)
+    payload = _block_action(
+        "fetch_more_logs", json.dumps({"analysis_id": "alert:abc:run:1234abcd"})
+    )
+
+    result = service.handle({"payload": json.dumps(payload)})
+
+    assert result["action"] == "fetch_more_logs"
+    assert analysis.calls[0][0] == "alert:abc:run:1234abcd"
+    assert "logs" in analysis.calls[0][1].question
+
+
+def test_slash_command_text_carries_analysis_id_and_question() -> None:
+    analysis = FakeAnalysisService()
+    service = SlackInteractionService(analysis)
+
+    result = service.handle(
+        {
+            "command": "/rca",
+            "text": "alert:abc:run:1234abcd was the node under memory pressure?",
+            "thread_ts": "100.000",
+        }
+    )
+
+    assert result["action"] == "ask"
+    assert analysis.calls[0][1].question == "was the node under memory pressure?"
+
+
+def test_remediation_approval_is_reported_unsupported() -> None:
+    analysis = FakeAnalysisService()
+    service = SlackInteractionService(analysis)
+
+    result = service.handle(_block_action("approve_remediation", "alert:abc:run:1234abcd"))
+
+    assert result["status"] == "unsupported"
+    assert analysis.calls == []
+
+
+def test_unknown_actions_and_payloads_are_rejected() -> None:
+    service = SlackInteractionService(FakeAnalysisService())
+
+    with pytest.raises(ValueError):
+        service.handle(_block_action("open_dashboard", "alert:abc:run:1234abcd"))
+    with pytest.raises(ValueError):
+        service.handle({"type": "view_submission"})
+    with pytest.raises(ValueError):
+        service.handle(_block_action("dig_deeper", ""))
+
+
+class _SlackRequest:
+    def __init__(self, body: bytes, headers: dict[str, str]) -> None:
+        self._body = body
+        self.headers = headers
+        self.url = SimpleNamespace(path="/slack/interactions")
+
+    async def body(self) -> bytes:
+        return self._body
+
+
+def _sign(secret: str, timestamp: str, body: bytes) -> str:
+    base = f"v0:{timestamp}:".encode() + body
+    return "v0=" + hmac.new(secret.encode(), base, hashlib.sha256).hexdigest()
+
+
+def _verify(request: _SlackRequest, secret: str = "signing-secret") -> None:
+    settings = dataclasses.replace(load_settings(), slack_signing_secret=secret)
+    request_arg: Any = request
+    asyncio.run(verify_slack_request(request_arg, settings=settings, verifier=None))
+
+
+def test_unsigned_slack_request_is_rejected() -> None:
+    body = b"payload=%7B%22type%22%3A%22block_actions%22%7D"
+
+    with pytest.raises(HTTPException) as unsigned:
+        _verify(_SlackRequest(body, {}))
+    timestamp = str(int(time.time()))
+    forged = {
+        "x-slack-request-timestamp": timestamp,
+        "x-slack-signature": _sign("other-secret", timestamp, body),
+    }
+    with pytest.raises(HTTPException) as invalid:
+        _verify(_SlackRequest(body, forged))
+
+    assert unsigned.value.status_code == 401
+    assert invalid.value.detail == "invalid Slack request signature"
+
+
+def test_signed_slack_request_is_accepted_within_the_replay_window() -> None:
+    body = b"payload=%7B%22type%22%3A%22block_actions%22%7D"
+    timestamp = str(int(time.time()))
+    headers = {
+        "x-slack-request-timestamp": timestamp,
+        "x-slack-signature": _sign("signing-secret", timestamp, body),
+    }
+
+    _verify(_SlackRequest(body, headers))
+
+    stale = str(int(time.time()) - 600)
+    replayed = {
+        "x-slack-request-timestamp": stale,
+        "x-slack-signature": _sign("signing-secret", stale, body),
+    }
+    with pytest.raises(HTTPException) as stale_error:
+        _verify(_SlackRequest(body, replayed))
+
+    assert "replay window" in str(stale_error.value.detail)
+
+
+def test_slack_interactions_require_an_admin_token_without_signing_secret() -> None:
+    verifier = SimpleNamespace(authenticate=lambda token: None)
+    settings = dataclasses.replace(load_settings(), slack_signing_secret="")
+
+    with pytest.raises(HTTPException) as missing:
+        asyncio.run(
+            verify_slack_request(
+                _SlackRequest(b"{}", {}),  # type: ignore[arg-type]
+                settings=settings,
+                verifier=verifier,  # type: ignore[arg-type]
+            )
+        )
+
+    assert missing.value.status_code == 401
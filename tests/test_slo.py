@@ -0,0 +1,40 @@
+from __future__ import annotations
+
+from app.core.slo import LatencySlo, LatencySloTracker
+
+
+def test_resolve_prefers_alertname_then_severity_then_default() -> None:
+    tracker = LatencySloTracker(
+        {"KubePodCrashLooping": 60, "severity:critical": 30, "default": 120}
+    )
+
+    crash = tracker.resolve({"alertname": "KubePodCrashLooping", "severity": "critical"})
+    critical = tracker.resolve({"alertname": "HighLatency", "severity": "Critical"})
+    other = tracker.resolve({"alertname": "HighLatency", "severity": "warning"})
+
+    assert crash == LatencySlo("KubePodCrashLooping", 60.0)
+    assert critical == LatencySlo("severity:critical", 30.0)
+    assert other == LatencySlo("default", 120.0)
+
+
+def test_resolve_without_matching_target_returns_none() -> None:
+    tracker = LatencySloTracker({"KubePodCrashLooping": 60})
+
+    assert tracker.resolve({"alertname": "HighLatency"}) is None
+    assert LatencySloTracker().enabled is False
+
+
+def test_observe_counts_outcomes_and_renders_prometheus_text() -> None:
+    tracker = LatencySloTracker({"default": 10})
+    slo = LatencySlo("default", 10.0)
+
+    assert tracker.observe(slo, 4.0, time_boxed=False) == "met"
+    assert tracker.observe(slo, 12.0, time_boxed=False) == "missed"
+    assert tracker.observe(slo, 9.5, time_boxed=True) == "time_boxed"
+
+    text = tracker.render_prometheus()
+    assert 'kube_rca_analysis_slo_target_seconds{slo="default"} 10.0' in text
+    assert 'kube_rca_analysis_slo_total{slo="default",outcome="met"} 1' in text
+    assert 'kube_rca_analysis_slo_total{slo="default",outcome="missed"} 1' in text
+    assert 'kube_rca_analysis_slo_total{slo="default",outcome="time_boxed"} 1' in text
+    assert 'kube_rca_analysis_slo_duration_seconds_sum{slo="default"} 25.5' in text
@@ -0,0 +1,198 @@
+from __future__ import annotations
+
+from app.models.k8s import K8sContext, PodEventSummary, PodStatusSnapshot
+from app.schemas.analysis import StorageAnalysis
+from app.services.storage_analysis import (
+    build_storage_analysis,
+    storage_claim_names,
+    volume_stats_nodes,
+)
+
+_GI = 1024**3
+
+
+def _context(
+    *,
+    claims: list[dict[str, object]] | None = None,
+    events: list[PodEventSummary] | None = None,
+    phase: str = "Running",
+    waiting_reason: str | None = None,
+) -> K8sContext:
+    state: dict[str, object] = (
+        {"type": "waiting", "reason": waiting_reason} if waiting_reason else {"type": "running"}
+    )
+    return K8sContext(
+        namespace="db",
+        pod_name="postgres-0",
+        workload="postgres",
+        pod_status=PodStatusSnapshot(
+            phase=phase,
+            node_name="node-1",
+            start_time=None,
+            reason=None,
+            message=None,
+            conditions=[],
+            container_statuses=[{"name": "postgres", "restart_count": 0, "state": state}],
+        ),
+        events=events or [],
+        previous_logs=[],
+        warnings=[],
+        pod_spec={
+            "volumes": [
+                {"name": "data", "persistent_volume_claim": {"claim_name": "data-postgres-0"}},
+                {"name": "config", "config_map": {"name": "postgres"}},
+            ]
+        },
+        volume_claims=claims or [],
+    )
+
+
+def _claim(**overrides: object) -> dict[str, object]:
+    claim: dict[str, object] = {
+        "name": "data-postgres-0",
+        "namespace": "db",
+        "phase": "Bound",
+        "storage_class": "gp3",
+        "access_modes": ["ReadWriteOnce"],
+        "requested": "20Gi",
+        "capacity": "20Gi",
+        "volume_name": "pvc-1234",
+        "selected_node": None,
+        "conditions": [],
+        "persistent_volume": {"name": "pvc-1234", "found": True, "phase": "Bound"},
+        "storage_class_detail": {
+            "name": "gp3",
+            "found": True,
+            "provisioner": "ebs.csi.aws.com",
+            "volume_binding_mode": "WaitForFirstConsumer",
+            "allow_volume_expansion": True,
+        },
+        "attachments": [{"node": "node-1", "attached": True, "attach_error": None}],
+        "events": [],
+        "usage": None,
+    }
+    claim.update(overrides)
+    return claim
+
+
+def _event(reason: str, message: str) -> PodEventSummary:
+    return PodEventSummary(
+        type="Warning",
+        reason=reason,
+        message=message,
+        count=3,
+        first_timestamp=None,
+        last_timestamp=None,
+        involved_object=None,
+    )
+
+
+def test_storage_analysis_reports_nearly_full_claim_with_expansion_hint() -> None:
+    usage = {
+        "used_bytes": 19 * _GI,
+        "capacity_bytes": 20 * _GI,
+        "inodes_used": 1000,
+        "inodes": 100000,
+    }
+    analysis = build_storage_analysis(_context(claims=[_claim(usage=usage)]))
+
+    assert analysis is not None
+    [claim] = analysis["claims"]
+    assert claim["usage_percent"] == 95.0
+    assert claim["inodes_usage_percent"] == 1.0
+    assert claim["attached_nodes"] == ["node-1"]
+    assert analysis["findings"] == [
+        "claim data-postgres-0 is 95.0% full (19.0Gi of 20.0Gi); expand it by raising "
+        "spec.resources.requests.storage"
+    ]
+    assert StorageAnalysis.model_validate(analysis).claims[0].provisioner == "ebs.csi.aws.com"
+
+
+def test_storage_analysis_explains_pending_claims() -> None:
+    missing_class = _claim(
+        phase="Pending",
+        volume_name=None,
+        persistent_volume=None,
+        attachments=[],
+        storage_class="fast",
+        storage_class_detail={"name": "fast", "found": False},
+    )
+    first_consumer = _claim(phase="Pending", volume_name=None, attachments=[])
+    failed = _claim(
+        phase="Pending",
+        volume_name=None,
+        attachments=[],
+        selected_node="node-1",
+        events=[{"reason": "ProvisioningFailed", "message": "VolumeLimitExceeded"}],
+    )
+
+    analysis = build_storage_analysis(_context(claims=[missing_class, first_consumer, failed]))
+
+    assert analysis is not None
+    assert analysis["findings"] == [
+        "claim data-postgres-0 is Pending: StorageClass fast does not exist",
+        "claim data-postgres-0 is Pending: StorageClass gp3 binds on first consumer and no "
+        "pod using the claim has been scheduled yet",
+        "claim data-postgres-0 is Pending: provisioning failed: VolumeLimitExceeded",
+    ]
+
+
+def test_storage_analysis_reports_attach_errors_and_stuck_container_creating() -> None:
+    claim = _claim(
+        attachments=[
+            {"node": "node-2", "attached": False, "attach_error": "rpc error: volume in use"}
+        ],
+        conditions=[{"type": "FileSystemResizePending", "status": "True", "message": None}],
+    )
+    events = [
+        _event(
+            "FailedAttachVolume",
+            'Multi-Attach error for volume "pvc-1234" Volume is already exclusively attached '
+            "to one node and can't be attached to another",
+        ),
+        _event("FailedMount", "Unable to attach or mount volumes: timed out waiting"),
+    ]
+
+    analysis = build_storage_analysis(
+        _context(claims=[claim], events=events, phase="Pending", waiting_reason="ContainerCreating")
+    )
+
+    assert analysis is not None
+    assert analysis["findings"][0] == (
+        "volume pvc-1234 failed to attach to node node-2: rpc error: volume in use"
+    )
+    assert analysis["findings"][1].startswith("claim data-postgres-0 was resized")
+    assert "Multi-Attach error" in analysis["findings"][2]
+    assert analysis["findings"][3] == (
+        "pod is stuck in ContainerCreating until its volumes attach and mount"
+    )
+    assert len(analysis["mount_events"]) == 2
+
+
+def test_storage_analysis_ignores_pods_without_storage_signals() -> None:
+    assert build_storage_analysis(_context()) is None
+
+
+def test_storage_claim_names_for_storage_alerts_and_waiting_pods() -> None:
+    healthy = _context()
+    filling_up = {"alertname": "KubePersistentVolumeFillingUp", "persistentvolumeclaim": "wal"}
+
+    assert storage_claim_names({"alertname": "HighLatency"}, healthy) == []
+    assert storage_claim_names(filling_up, healthy) == ["wal", "data-postgres-0"]
+    assert storage_claim_names(
+        {"alertname": "KubePodNotReady"}, _context(waiting_reason="ContainerCreating")
+    ) == ["data-postgres-0"]
+    assert storage_claim_names(
+        {"alertname": "KubePodNotReady"},
+        _context(events=[_event("FailedMount", "MountVolume.SetUp failed")]),
+    ) == ["data-postgres-0"]
+
+
+def test_volume_stats_nodes_prefers_pod_node_then_attachments() -> None:
+    claims = [
+        _claim(attachments=[{"node": "node-1", "attached": True}]),
+        _claim(attachments=[{"node": "node-3", "attached": True}]),
+        _claim(attachments=[{"node": "node-4", "attached": False}]),
+    ]
+
+    assert volume_stats_nodes(_context(), claims) == ["node-1", "node-3"]
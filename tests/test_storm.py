@@ -0,0 +1,245 @@
+from __future__ import annotations
+
+import asyncio
+import json
+import urllib.request
+from contextlib import contextmanager
+from types import SimpleNamespace
+from typing import Any
+
+import pytest
+from pydantic import SecretStr
+
+from app.api.analysis import _deferred_response
+from app.clients.report_sink import WebhookReportSink
+from app.schemas.alert import Alert
+from app.schemas.analysis import AlertAnalysisRequest, ClusterCredentials
+from app.services.storm import AlertStormGuard, replay_deferred_analyses
+
+
+class _FakeSink:
+    def __init__(self) -> None:
+        self.reports: list[tuple[str, dict[str, object]]] = []
+
+    def send(self, report_type: str, report: dict[str, object]) -> dict[str, object]:
+        self.reports.append((report_type, report))
+        return {"delivered": True, "status_code": 200}
+
+
+class _FakeAnalyzer:
+    def __init__(self) -> None:
+        self.analyzed: list[str | None] = []
+
+    def analyze(
+        self, request: AlertAnalysisRequest
+    ) -> tuple[str, str, str, dict[str, object], list[dict[str, object]]]:
+        self.analyzed.append(request.alert.fingerprint)
+        return (
+            "analysis",
+            f"summary of {request.alert.fingerprint}",
+            "detail",
+            {"analysis_id": f"alert:{request.alert.fingerprint}"},
+            [],
+        )
+
+
+class _Clock:
+    def __init__(self) -> None:
+        self.now = 1000.0
+
+    def __call__(self) -> float:
+        return self.now
+
+
+def _request(
+    fingerprint: str, *, namespace: str = "payments", alertname: str = "PodCrashLooping"
+) -> AlertAnalysisRequest:
+    return AlertAnalysisRequest(
+        alert=Alert(
+            status="firing",
+            labels={
+                "alertname": alertname,
+                "namespace": namespace,
+                "cluster": "prod-1",
+                "workload": "api",
+            },
+            fingerprint=fingerprint,
+        ),
+        thread_ts=f"ts-{fingerprint}",
+    )
+
+
+def _guard(
+    clock: _Clock, *, max_deferred: int = 500
+) -> tuple[AlertStormGuard, _FakeSink, _FakeAnalyzer]:
+    sink = _FakeSink()
+    analyzer = _FakeAnalyzer()
+    guard = AlertStormGuard(
+        analyzer,
+        sink,
+        alerts_per_minute=3,
+        quiet_seconds=120,
+        max_deferred=max_deferred,
+        clock=clock,
+    )
+    return guard, sink, analyzer
+
+
+def test_storm_defers_analyses_above_rate() -> None:
+    clock = _Clock()
+    guard, sink, _ = _guard(clock)
+
+    assert guard.admit(_request("a")) is None
+    assert guard.admit(_request("b")) is None
+    storm = guard.admit(_request("c"))
+    deferred = guard.admit(_request("d", namespace="checkout"))
+
+    assert storm is not None
+    assert storm["alerts_per_minute"] == 3
+    assert storm["cluster"] == "prod-1"
+    assert storm["namespace"] == "payments"
+    assert storm["queued"] is True
+    assert deferred is not None and deferred["namespace"] == "checkout"
+    assert guard.status()["deferred"] == 2
+    assert sink.reports == []
+
+
+def test_storm_tick_sends_one_summary_per_namespace_with_new_alerts() -> None:
+    clock = _Clock()
+    guard, sink, analyzer = _guard(clock)
+    for fingerprint in ("a", "b", "c", "d"):
+        guard.admit(_request(fingerprint))
+    guard.admit(_request("e", namespace="checkout", alertname="HighLatency"))
+    guard.admit(_request("c"))
+
+    result = guard.tick()
+
+    assert result == {"summaries": 2, "deferred": 0, "dropped": 0}
+    assert analyzer.analyzed == []
+    summaries = {report["namespace"]: report for _, report in sink.reports}
+    assert {report_type for report_type, _ in sink.reports} == {"alert_storm_summary"}
+    payments = summaries["payments"]
+    assert payments["alert_count"] == 2
+    assert payments["received"] == 3
+    assert payments["shared"] == {
+        "alertname": "PodCrashLooping",
+        "namespace": "payments",
+        "workload": "api",
+    }
+    assert payments["final"] is False
+    assert summaries["checkout"]["alertnames"] == {"HighLatency": 1}
+
+    sink.reports.clear()
+    assert guard.tick()["summaries"] == 0
+
+
+def test_storm_replays_deferred_analyses_after_quiet_period() -> None:
+    clock = _Clock()
+    guard, sink, analyzer = _guard(clock, max_deferred=2)
+    for fingerprint in ("a", "b", "c", "d", "e"):
+        guard.admit(_request(fingerprint))
+    guard.tick()
+    sink.reports.clear()
+
+    clock.now += 90
+    assert guard.tick()["deferred"] == 0
+    assert guard.status()["active"] is True
+
+    clock.now += 60
+    result = guard.tick()
+
+    assert result == {"summaries": 0, "deferred": 2, "dropped": 1}
+    assert analyzer.analyzed == []
+    assert asyncio.run(replay_deferred_analyses(guard)) == 2
+    assert analyzer.analyzed == ["c", "d"]
+    assert [report_type for report_type, _ in sink.reports] == [
+        "deferred_analysis",
+        "deferred_analysis",
+    ]
+    assert sink.reports[0][1]["thread_ts"] == "ts-c"
+    assert sink.reports[0][1]["analysis_summary"] == "summary of c"
+    assert guard.status()["active"] is False
+    assert guard.admit(_request("f")) is None
+
+
+def test_storm_replay_stops_when_a_new_storm_starts() -> None:
+    clock = _Clock()
+    guard, _, analyzer = _guard(clock)
+    for fingerprint in ("a", "b", "c", "d"):
+        guard.admit(_request(fingerprint))
+    clock.now += 200
+    assert guard.tick()["deferred"] == 2
+
+    first = guard.next_deferred()
+    for fingerprint in ("e", "f", "g"):
+        guard.admit(_request(fingerprint))
+
+    assert first is not None and first.alert.fingerprint == "c"
+    assert guard.next_deferred() is None
+    assert guard.status()["deferred"] == 2
+    assert guard.status()["replaying"] == 0
+    assert analyzer.analyzed == []
+
+
+def test_storm_does_not_keep_requests_with_an_inline_caller_token() -> None:
+    clock = _Clock()
+    guard, _, _ = _guard(clock)
+    for fingerprint in ("a", "b"):
+        guard.admit(_request(fingerprint))
+    request = _request("c")
+    request.cluster_credentials = ClusterCredentials(token=SecretStr("caller-token"))
+    referenced = _request("d")
+    referenced.cluster_credentials = ClusterCredentials(token_ref="vault:kube-rca/team#token")
+
+    storm = guard.admit(request)
+    kept = guard.admit(referenced)
+
+    assert storm is not None and storm["queued"] is False
+    assert kept is not None and kept["queued"] is True
+    assert guard.status()["deferred"] == 1
+    assert guard.status()["dropped"] == 1
+
+
+def test_deferred_analysis_report_carries_the_correlation_id_of_the_deferral(
+    monkeypatch: pytest.MonkeyPatch,
+) -> None:
+    sent: list[urllib.request.Request] = []
+
+    @contextmanager
+    def fake_open_url(request: urllib.request.Request, **kwargs: Any) -> Any:
+        sent.append(request)
+        yield SimpleNamespace(status=200)
+
+    monkeypatch.setattr("app.clients.report_sink.open_url", fake_open_url)
+    clock = _Clock()
+    guard = AlertStormGuard(
+        _FakeAnalyzer(),
+        WebhookReportSink("https://backend.example.com/reports"),
+        alerts_per_minute=3,
+        quiet_seconds=120,
+        clock=clock,
+    )
+    for fingerprint in ("a", "b"):
+        guard.admit(_request(fingerprint))
+    storm = guard.admit(_request("c"))
+    repeat = guard.admit(_request("c"))
+    from_thread = _request("d")
+    from_thread.correlation_id = "slack-thread-42"
+    threaded = guard.admit(from_thread)
+    clock.now += 200
+    guard.tick()
+    sent.clear()
+
+    asyncio.run(replay_deferred_analyses(guard))
+
+    assert storm is not None and repeat is not None and threaded is not None
+    assert _deferred_response(_request("c"), storm).correlation_id == storm["correlation_id"]
+    assert repeat["correlation_id"] == storm["correlation_id"]
+    assert threaded["correlation_id"] == "slack-thread-42"
+    assert [request.get_header("X-correlation-id") for request in sent] == [
+        storm["correlation_id"],
+        "slack-thread-42",
+    ]
+    reports = [json.loads(request.data) for request in sent]  # type: ignore[arg-type]
+    assert [report["type"] for report in reports] == ["deferred_analysis"] * 2
+    assert reports[0]["report"]["correlation_id"] == storm["correlation_id"]
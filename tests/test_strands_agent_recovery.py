@@ -10,6 +10,7 @@ from app.clients.strands_agent import (
     StrandsAnalysisEngine,
     _has_user_text,
     _sanitize_message_order,
+    _stream_handler,
     _strip_tool_blocks,
 )
 
@@ -102,6 +103,30 @@ def test_non_session_error_does_not_reset_state() -> None:
     assert repo.deleted_sessions == []
 
 
+def test_stream_analyze_forwards_text_deltas_and_each_tool_call_once() -> None:
+    engine, _, _ = _build_engine(["Hello"])
+    events: list[dict[str, object]] = []
+
+    def _analyze_once(prompt: str, session_id: str) -> str:
+        handler = _stream_handler.get()
+        assert handler is not None
+        handler(data="Hel")
+        for _ in range(2):
+            handler(current_tool_use={"toolUseId": "tool-1", "name": "get_pod"})
+        handler(data="lo", delta={"text": "lo"})
+        return "Hello"
+
+    engine._analyze_once = _analyze_once  # type: ignore[attr-defined]
+
+    assert engine.stream_analyze("prompt", "incident-4", events.append) == "Hello"
+    assert events == [
+        {"type": "chunk", "text": "Hel"},
+        {"type": "tool_call", "tool": "get_pod"},
+        {"type": "chunk", "text": "lo"},
+    ]
+    assert _stream_handler.get() is None
+
+
 # ---------------------------------------------------------------------------
 # Gemini turn-order recovery (session reset via analyze)
 # ---------------------------------------------------------------------------
@@ -39,3 +39,114 @@ def test_build_tools_omits_optional_observability_tools_when_clients_are_missing
     assert "query_prometheus" not in names
     assert "search_tempo_traces" not in names
     assert "query_loki" not in names
+
+
+def test_build_tools_registers_infrastructure_changes_only_with_terraform_client() -> None:
+    without = _build_tools(
+        k8s_client=object(),
+        prometheus_client=None,
+        tempo_client=None,
+        loki_client=None,
+        masker=RegexMasker(),
+    )
+    with_terraform = _build_tools(
+        k8s_client=object(),
+        prometheus_client=None,
+        tempo_client=None,
+        loki_client=None,
+        masker=RegexMasker(),
+        terraform_client=object(),
+    )
+
+    assert "list_infrastructure_changes" not in {tool.tool_name for tool in without}
+    assert "list_infrastructure_changes" in {tool.tool_name for tool in with_terraform}
+
+
+def test_build_tools_registers_image_metadata_only_with_registry_client() -> None:
+    without = _tool_names(prometheus=None, tempo=None, loki=None)
+    with_registry = _build_tools(
+        k8s_client=object(),
+        prometheus_client=None,
+        tempo_client=None,
+        loki_client=None,
+        masker=RegexMasker(),
+        registry_client=object(),
+    )
+
+    assert "get_workload_image_metadata" not in without
+    assert "get_workload_image_metadata" in {tool.tool_name for tool in with_registry}
+
+
+def test_build_tools_registers_rollout_code_changes_only_with_correlator() -> None:
+    without = _tool_names(prometheus=None, tempo=None, loki=None)
+    with_correlator = _build_tools(
+        k8s_client=object(),
+        prometheus_client=None,
+        tempo_client=None,
+        loki_client=None,
+        masker=RegexMasker(),
+        code_changes=object(),
+    )
+
+    assert "get_rollout_code_changes" not in without
+    assert "get_rollout_code_changes" in {tool.tool_name for tool in with_correlator}
+
+
+def test_build_tools_registers_datastore_health_only_with_client() -> None:
+    without = _tool_names(prometheus=None, tempo=None, loki=None)
+    with_datastores = _build_tools(
+        k8s_client=object(),
+        prometheus_client=None,
+        tempo_client=None,
+        loki_client=None,
+        masker=RegexMasker(),
+        datastore_client=object(),
+    )
+
+    assert "check_datastore_health" not in without
+    assert "check_datastore_health" in {tool.tool_name for tool in with_datastores}
+
+
+def test_build_tools_registers_kafka_lag_only_with_analyzer() -> None:
+    without = _tool_names(prometheus=None, tempo=None, loki=None)
+    with_kafka = _build_tools(
+        k8s_client=object(),
+        prometheus_client=None,
+        tempo_client=None,
+        loki_client=None,
+        masker=RegexMasker(),
+        kafka_lag=object(),
+    )
+
+    assert "analyze_kafka_consumer_lag" not in without
+    assert "analyze_kafka_consumer_lag" in {tool.tool_name for tool in with_kafka}
+
+
+def test_build_tools_registers_endpoint_probe_only_with_client() -> None:
+    without = _tool_names(prometheus=None, tempo=None, loki=None)
+    with_probe = _build_tools(
+        k8s_client=object(),
+        prometheus_client=None,
+        tempo_client=None,
+        loki_client=None,
+        masker=RegexMasker(),
+        endpoint_probe=object(),
+    )
+
+    assert "probe_external_endpoint" not in without
+    assert "probe_external_endpoint" in {tool.tool_name for tool in with_probe}
+
+
+def test_build_tools_registers_observability_query_only_with_client() -> None:
+    without = _tool_names(prometheus=None, tempo=None, loki=None)
+    with_observability = _build_tools(
+        k8s_client=object(),
+        prometheus_client=None,
+        tempo_client=None,
+        loki_client=None,
+        masker=RegexMasker(),
+        observability=object(),
+    )
+
+    assert "query_observability_events" not in without
+    assert "query_observability_events" in {tool.tool_name for tool in with_observability}
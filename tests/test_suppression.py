@@ -0,0 +1,196 @@
+from __future__ import annotations
+
+from dataclasses import replace
+from datetime import datetime, timedelta, timezone
+
+import pytest
+
+from app.clients.suppression_store import Suppression
+from app.schemas.alert import Alert
+from app.schemas.analysis import AlertAnalysisRequest
+from app.services.suppression import SuppressionService, matches, validate_matchers
+
+_NOW = datetime(2026, 10, 14, 10, 0, tzinfo=timezone.utc)
+
+
+class _FakeStore:
+    def __init__(self) -> None:
+        self.suppressions: dict[int, Suppression] = {}
+        self.hits: list[dict[str, object]] = []
+
+    def create(
+        self,
+        matchers: list[dict[str, str]],
+        *,
+        reason: str,
+        created_by: str | None,
+        starts_at: datetime,
+        ends_at: datetime,
+    ) -> Suppression:
+        suppression = Suppression(
+            suppression_id=len(self.suppressions) + 1,
+            matchers=matchers,
+            reason=reason,
+            created_by=created_by,
+            created_at=_NOW,
+            starts_at=starts_at,
+            ends_at=ends_at,
+        )
+        self.suppressions[suppression.suppression_id] = suppression
+        return suppression
+
+    def get(self, suppression_id: int) -> Suppression | None:
+        return self.suppressions.get(suppression_id)
+
+    def list_suppressions(
+        self, *, active_at: datetime | None = None, limit: int = 100
+    ) -> list[Suppression]:
+        items = sorted(self.suppressions.values(), key=lambda item: -item.suppression_id)
+        if active_at is not None:
+            items = [item for item in items if item.active(active_at)]
+        return items[:limit]
+
+    def expire(
+        self, suppression_id: int, *, expired_by: str | None, at: datetime
+    ) -> Suppression | None:
+        suppression = self.suppressions.get(suppression_id)
+        if suppression is None or not suppression.active(at):
+            return None
+        self.suppressions[suppression_id] = replace(
+            suppression, expired_at=at, expired_by=expired_by
+        )
+        return self.suppressions[suppression_id]
+
+    def record_hit(self, suppression_id: int, **hit: object) -> None:
+        self.hits.append({"suppression_id": suppression_id, **hit})
+
+    def list_hits(self, suppression_id: int, *, limit: int = 50) -> list[dict[str, object]]:
+        return [hit for hit in self.hits if hit["suppression_id"] == suppression_id][:limit]
+
+
+class _Clock:
+    def __init__(self) -> None:
+        self.now = _NOW
+
+    def __call__(self) -> datetime:
+        return self.now
+
+
+def _request(namespace: str, alertname: str = "KubePodCrashLooping") -> AlertAnalysisRequest:
+    return AlertAnalysisRequest(
+        alert=Alert(
+            status="firing",
+            labels={"alertname": alertname, "namespace": namespace},
+            fingerprint=f"fp-{namespace}",
+        ),
+        thread_ts="ts-1",
+    )
+
+
+def test_matchers_follow_alertmanager_semantics() -> None:
+    labels = {"alertname": "KubePodCrashLooping", "namespace": "billing"}
+
+    assert matches([{"name": "namespace", "operator": "=", "value": "billing"}], labels)
+    assert matches([{"name": "alertname", "operator": "=~", "value": "KubePod.*"}], labels)
+    assert not matches([{"name": "alertname", "operator": "=~", "value": "Pod"}], labels)
+    assert matches([{"name": "team", "operator": "!=", "value": "db"}], labels)
+    assert not matches(
+        [
+            {"name": "namespace", "operator": "=", "value": "billing"},
+            {"name": "alertname", "operator": "!~", "value": "KubePod.*"},
+        ],
+        labels,
+    )
+    with pytest.raises(ValueError, match="must not match an empty label"):
+        validate_matchers([{"name": "namespace", "operator": "=~", "value": ".*"}])
+    with pytest.raises(ValueError, match="invalid regex"):
+        validate_matchers([{"name": "namespace", "operator": "=~", "value": "("}])
+
+
+def test_active_suppression_matches_alerts_and_records_hits() -> None:
+    store = _FakeStore()
+    clock = _Clock()
+    service = SuppressionService(store, max_duration_minutes=240, clock=clock)
+
+    created = service.create(
+        [{"name": "namespace", "value": "billing"}],
+        duration_minutes=120,
+        reason="database migration",
+        created_by="alice@example.com",
+    )
+
+    assert created["ends_at"] == "2026-10-14T12:00:00+00:00"
+    assert service.match(_request("payments")) is None
+    assert service.match(_request("billing")) == {
+        "suppression_id": 1,
+        "reason": "database migration",
+        "created_by": "alice@example.com",
+        "ends_at": "2026-10-14T12:00:00+00:00",
+        "matchers": [{"name": "namespace", "operator": "=", "value": "billing"}],
+    }
+    assert store.hits == [
+        {
+            "suppression_id": 1,
+            "alertname": "KubePodCrashLooping",
+            "namespace": "billing",
+            "fingerprint": "fp-billing",
+            "at": _NOW,
+        }
+    ]
+    clock.now = _NOW + timedelta(hours=2)
+    assert service.match(_request("billing")) is None
+
+
+def test_scheduled_and_expired_suppressions_stay_listed_for_audit() -> None:
+    store = _FakeStore()
+    clock = _Clock()
+    service = SuppressionService(store, max_duration_minutes=240, clock=clock)
+    scheduled = service.create(
+        [{"name": "namespace", "value": "billing"}],
+        duration_minutes=60,
+        reason="planned failover",
+        created_by=None,
+        starts_at=_NOW + timedelta(hours=1),
+    )
+    service.create(
+        [{"name": "alertname", "value": "KubeNodeNotReady"}],
+        duration_minutes=30,
+        reason="node upgrade",
+        created_by="bob",
+    )
+
+    assert service.match(_request("billing")) is None
+    assert service.expire(2, expired_by="carol") is not None
+    assert service.expire(2, expired_by="carol") is None
+    assert service.list_suppressions() == []
+    history = service.list_suppressions(include_expired=True)
+    assert [(item["suppression_id"], item["active"]) for item in history] == [
+        (2, False),
+        (1, False),
+    ]
+    assert history[0]["expired_by"] == "carol"
+    clock.now = _NOW + timedelta(hours=1, minutes=5)
+    assert service.match(_request("billing")) is not None
+    detail = service.get(int(str(scheduled["suppression_id"])))
+    assert detail is not None
+    assert detail["recent_hits"] == [
+        {
+            "suppression_id": 1,
+            "alertname": "KubePodCrashLooping",
+            "namespace": "billing",
+            "fingerprint": "fp-billing",
+            "at": clock.now,
+        }
+    ]
+
+
+def test_create_rejects_windows_longer_than_the_maximum() -> None:
+    service = SuppressionService(_FakeStore(), max_duration_minutes=60, clock=_Clock())
+
+    with pytest.raises(ValueError, match="maximum of 60 minutes"):
+        service.create(
+            [{"name": "namespace", "value": "billing"}],
+            duration_minutes=61,
+            reason="migration",
+            created_by=None,
+        )
@@ -0,0 +1,138 @@
+from __future__ import annotations
+
+import json
+import urllib.error
+
+import pytest
+
+import app.clients.terraform as terraform_module
+from app.clients.terraform import TerraformCloudClient
+from app.core.config import load_settings
+
+_RUNS_PAYLOAD = {
+    "data": [
+        {
+            "id": "run-apply",
+            "type": "runs",
+            "attributes": {
+                "status": "applied",
+                "message": "Resize default node pool",
+                "source": "tfe-api",
+                "is-destroy": False,
+                "created-at": "2026-02-06T18:20:00Z",
+                "status-timestamps": {"applied-at": "2026-02-06T18:25:00Z"},
+            },
+            "relationships": {"plan": {"data": {"id": "plan-1", "type": "plans"}}},
+        },
+        {
+            "id": "run-old",
+            "type": "runs",
+            "attributes": {
+                "status": "applied",
+                "message": "Old change",
+                "created-at": "2026-02-05T10:00:00Z",
+                "status-timestamps": {"applied-at": "2026-02-05T10:05:00Z"},
+            },
+        },
+        {
+            "id": "run-planned",
+            "type": "runs",
+            "attributes": {
+                "status": "planned",
+                "message": "Pending DNS change",
+                "created-at": "2026-02-06T18:35:00Z",
+                "status-timestamps": {},
+            },
+        },
+    ],
+    "included": [
+        {
+            "id": "plan-1",
+            "type": "plans",
+            "attributes": {
+                "resource-additions": 0,
+                "resource-changes": 1,
+                "resource-destructions": 0,
+            },
+        }
+    ],
+}
+
+
+class _FakeHTTPResponse:
+    def __init__(self, body: str) -> None:
+        self._body = body.encode("utf-8")
+
+    def read(self) -> bytes:
+        return self._body
+
+    def __enter__(self) -> _FakeHTTPResponse:
+        return self
+
+    def __exit__(self, exc_type, exc, tb) -> None:  # type: ignore[no-untyped-def]
+        return None
+
+
+def _configure(monkeypatch: pytest.MonkeyPatch, workspaces: list[str]) -> None:
+    monkeypatch.setenv("TERRAFORM_CLOUD_TOKEN", "test-token")
+    monkeypatch.setenv("TERRAFORM_WORKSPACE_IDS_JSON", json.dumps(workspaces))
+
+
+def test_terraform_client_disabled_without_workspaces(monkeypatch: pytest.MonkeyPatch) -> None:
+    monkeypatch.setenv("TERRAFORM_CLOUD_TOKEN", "test-token")
+    monkeypatch.delenv("TERRAFORM_WORKSPACE_IDS_JSON", raising=False)
+
+    client = TerraformCloudClient(load_settings())
+
+    assert client.enabled is False
+    assert client.list_runs() == {"warning": "terraform cloud not configured"}
+
+
+def test_list_runs_filters_window_and_attaches_plan_counts(
+    monkeypatch: pytest.MonkeyPatch,
+) -> None:
+    _configure(monkeypatch, ["ws-network"])
+    captured: dict[str, object] = {}
+
+    def fake_urlopen(request, timeout=0):  # type: ignore[no-untyped-def]
+        captured["url"] = request.full_url
+        captured["authorization"] = request.get_header("Authorization")
+        return _FakeHTTPResponse(json.dumps(_RUNS_PAYLOAD))
+
+    monkeypatch.setattr(terraform_module.urllib.request, "urlopen", fake_urlopen)
+
+    result = TerraformCloudClient(load_settings()).list_runs(
+        start="2026-02-06T18:00:00Z", end="2026-02-06T18:40:00Z"
+    )
+
+    assert str(captured["url"]).startswith(
+        "https://app.terraform.io/api/v2/workspaces/ws-network/runs?"
+    )
+    assert captured["authorization"] == "Bearer test-token"
+    assert result["run_count"] == 2
+    assert result["applied_run_count"] == 1
+    runs = result["runs"]
+    assert isinstance(runs, list)
+    assert [run["run_id"] for run in runs] == ["run-apply", "run-planned"]
+    assert runs[0]["resource_changes"] == {"add": 0, "change": 1, "destroy": 0}
+    assert "errors" not in result
+
+
+def test_list_runs_reports_workspace_errors(monkeypatch: pytest.MonkeyPatch) -> None:
+    _configure(monkeypatch, ["ws-missing", "ws-network"])
+
+    def fake_urlopen(request, timeout=0):  # type: ignore[no-untyped-def]
+        if "ws-missing" in request.full_url:
+            raise urllib.error.HTTPError(request.full_url, 404, "Not Found", {}, None)
+        return _FakeHTTPResponse(json.dumps(_RUNS_PAYLOAD))
+
+    monkeypatch.setattr(terraform_module.urllib.request, "urlopen", fake_urlopen)
+
+    result = TerraformCloudClient(load_settings()).list_runs(
+        start="2026-02-06T18:00:00Z", end="2026-02-06T18:30:00Z"
+    )
+
+    assert result["run_count"] == 1
+    assert result["errors"] == [
+        {"workspace_id": "ws-missing", "status_code": 404, "reason": "Not Found"}
+    ]
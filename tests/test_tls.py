@@ -0,0 +1,135 @@
+from __future__ import annotations
+
+import datetime
+import os
+import ssl
+import urllib.request
+from pathlib import Path
+
+import pytest
+from cryptography import x509
+from cryptography.hazmat.primitives import hashes, serialization
+from cryptography.hazmat.primitives.asymmetric import ec
+from cryptography.x509.oid import NameOID
+
+from app.core.config import load_settings
+from app.core.tls import client_ssl_context, client_ssl_context_for, init_client_tls, open_url
+
+
+@pytest.fixture(autouse=True)
+def _reset_tls():
+    yield
+    init_client_tls("")
+
+
+def _write_svid(directory: Path) -> tuple[Path, Path]:
+    key = ec.generate_private_key(ec.SECP256R1())
+    name = x509.Name([x509.NameAttribute(NameOID.COMMON_NAME, "kube-rca-agent")])
+    now = datetime.datetime.now(datetime.timezone.utc)
+    cert = (
+        x509.CertificateBuilder()
+        .subject_name(name)
+        .issuer_name(name)
+        .public_key(key.public_key())
+        .serial_number(x509.random_serial_number())
+        .not_valid_before(now)
+        .not_valid_after(now + datetime.timedelta(hours=1))
+        .add_extension(
+            x509.SubjectAlternativeName(
+                [x509.UniformResourceIdentifier("spiffe://cluster.local/ns/kube-rca/sa/agent")]
+            ),
+            critical=False,
+        )
+        .sign(key, hashes.SHA256())
+    )
+    cert_path = directory / "svid.pem"
+    key_path = directory / "svid_key.pem"
+    cert_path.write_bytes(cert.public_bytes(serialization.Encoding.PEM))
+    key_path.write_bytes(
+        key.private_bytes(
+            serialization.Encoding.PEM,
+            serialization.PrivateFormat.PKCS8,
+            serialization.NoEncryption(),
+        )
+    )
+    return cert_path, key_path
+
+
+def test_client_tls_disabled_by_default() -> None:
+    init_client_tls("")
+
+    assert client_ssl_context() is None
+
+
+def test_client_context_reloads_rotated_svid(tmp_path: Path) -> None:
+    cert_path, key_path = _write_svid(tmp_path)
+    init_client_tls(str(cert_path), str(key_path))
+
+    first = client_ssl_context()
+    assert isinstance(first, ssl.SSLContext)
+    assert client_ssl_context() is first
+
+    _write_svid(tmp_path)
+    rotated_at = os.stat(cert_path).st_mtime + 10
+    os.utime(cert_path, (rotated_at, rotated_at))
+
+    assert client_ssl_context() is not first
+
+
+def test_init_client_tls_fails_fast_on_missing_files(tmp_path: Path) -> None:
+    with pytest.raises(OSError):
+        init_client_tls(str(tmp_path / "missing.pem"))
+
+
+def test_open_url_passes_client_context(
+    tmp_path: Path, monkeypatch: pytest.MonkeyPatch
+) -> None:
+    captured: dict[str, object] = {}
+
+    def fake_urlopen(request, timeout=0, **kwargs):  # type: ignore[no-untyped-def]
+        captured.update(kwargs)
+        return None
+
+    monkeypatch.setattr(urllib.request, "urlopen", fake_urlopen)
+    open_url("https://prometheus:9090/api/v1/query", timeout=1)
+    assert "context" not in captured
+
+    cert_path, key_path = _write_svid(tmp_path)
+    init_client_tls(str(cert_path), str(key_path), hosts=["prometheus", ".svc.cluster.local"])
+    open_url("https://prometheus:9090/api/v1/query", timeout=1)
+    assert isinstance(captured["context"], ssl.SSLContext)
+    captured.clear()
+    open_url(urllib.request.Request("https://loki.monitoring.svc.cluster.local/"), timeout=1)
+    assert isinstance(captured["context"], ssl.SSLContext)
+    captured.clear()
+
+    # Public endpoints keep the system CAs and never get the client certificate.
+    open_url("https://api.github.com/repos/acme/app/compare/a...b", timeout=1)
+    assert "context" not in captured
+    assert client_ssl_context_for("prometheus.evil.example") is None
+
+
+def test_client_tls_settings_from_env(monkeypatch: pytest.MonkeyPatch) -> None:
+    monkeypatch.setenv("CLIENT_TLS_CERT_FILE", "/run/spiffe/svid.pem")
+    monkeypatch.setenv("CLIENT_TLS_KEY_FILE", "/run/spiffe/svid_key.pem")
+    monkeypatch.setenv("CLIENT_TLS_CA_FILE", "/run/spiffe/bundle.pem")
+
+    settings = load_settings()
+
+    assert settings.client_tls_cert_file == "/run/spiffe/svid.pem"
+    assert settings.client_tls_key_file == "/run/spiffe/svid_key.pem"
+    assert settings.client_tls_ca_file == "/run/spiffe/bundle.pem"
+
+
+def test_client_tls_hosts_default_to_the_data_source_hosts(
+    monkeypatch: pytest.MonkeyPatch,
+) -> None:
+    monkeypatch.delenv("CLIENT_TLS_HOSTS_JSON", raising=False)
+    monkeypatch.setenv("PROMETHEUS_URL", "https://prometheus.monitoring:9090")
+    monkeypatch.setenv("LOKI_URL", "https://loki.monitoring:3100")
+    monkeypatch.delenv("TEMPO_URL", raising=False)
+
+    assert load_settings().client_tls_hosts == ("prometheus.monitoring", "loki.monitoring")
+
+    monkeypatch.setenv("CLIENT_TLS_HOSTS_JSON", '[".svc.cluster.local"]')
+    assert load_settings().client_tls_hosts == (".svc.cluster.local",)
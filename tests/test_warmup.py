@@ -0,0 +1,86 @@
+from __future__ import annotations
+
+import asyncio
+import threading
+
+from app.core.warmup import WarmupStep, init_warmup, readiness, run_warmup
+from app.models.k8s import K8sContext
+from app.services.analysis import AnalysisService
+
+
+def test_readiness_flips_after_the_steps_and_records_failures() -> None:
+    init_warmup(True)
+    assert readiness()[0] is False
+    calls: list[str] = []
+
+    def failing() -> None:
+        raise RuntimeError("kubeconfig missing")
+
+    asyncio.run(
+        run_warmup(
+            [
+                WarmupStep("clients", lambda: calls.append("clients")),
+                WarmupStep("kubernetes", failing),
+                WarmupStep("prompt", lambda: calls.append("prompt")),
+            ],
+            timeout_seconds=10,
+        )
+    )
+
+    ready, state = readiness()
+    assert ready is True
+    assert calls == ["clients", "prompt"]
+    steps = state["steps"]
+    assert [(step["name"], step["status"]) for step in steps] == [  # type: ignore[union-attr]
+        ("clients", "ok"),
+        ("kubernetes", "failed"),
+        ("prompt", "ok"),
+    ]
+    assert state["steps"][1]["detail"] == "kubeconfig missing"  # type: ignore[index]
+    assert state["status"] == "ready"
+
+
+def test_timeout_cuts_off_the_slow_step_and_skips_the_rest() -> None:
+    init_warmup(True)
+    release = threading.Event()
+    try:
+        asyncio.run(
+            run_warmup(
+                [
+                    WarmupStep("llm_client", lambda: release.wait(5)),
+                    WarmupStep("prompt", lambda: None),
+                ],
+                timeout_seconds=0.05,
+            )
+        )
+    finally:
+        release.set()
+
+    ready, state = readiness()
+    assert ready is True
+    steps = state["steps"]
+    assert [(step["name"], step["status"]) for step in steps] == [  # type: ignore[union-attr]
+        ("llm_client", "timeout"),
+        ("prompt", "skipped"),
+    ]
+
+
+def test_disabled_prewarming_is_ready_immediately() -> None:
+    init_warmup(False)
+
+    assert readiness() == (True, {"status": "ready", "steps": []})
+
+
+class _UnusedKubernetesClient:
+    def collect_context(self, *args: object, **kwargs: object) -> K8sContext:
+        raise AssertionError("warm_up must not call Kubernetes")
+
+
+def test_analysis_service_warm_up_renders_a_prompt_offline() -> None:
+    service = AnalysisService(
+        _UnusedKubernetesClient(),  # type: ignore[arg-type]
+        analysis_engine=None,
+        prometheus_enabled=True,
+    )
+
+    assert service.warm_up() > 0